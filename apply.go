@@ -0,0 +1,32 @@
+package geom
+
+// Apply calls fn with each coordinate of g, including those of the members of
+// nested GeometryCollections. Each coordinate is a view of g's flat
+// coordinates, so fn transforms g in place by modifying its argument, for
+// example to reproject or offset g without rebuilding it with SetCoords. fn
+// must not change the length of its argument.
+func Apply(g T, fn func(Coord)) {
+	var it CoordsIter
+	if gc, ok := g.(*GeometryCollection); ok {
+		it = gc.CoordsIter()
+	} else {
+		it = newCoordsIter(g.FlatCoords(), g.Stride())
+	}
+	for it.Next() {
+		fn(it.Coord())
+	}
+}
+
+// TransformInPlace calls fn with each coordinate of g, which fn may modify to
+// transform g in place. See Apply.
+func (g *geom0) TransformInPlace(fn func(Coord)) {
+	for i := 0; g.stride != 0 && i < len(g.flatCoords); i += g.stride {
+		fn(g.flatCoords[i : i+g.stride : i+g.stride])
+	}
+}
+
+// TransformInPlace calls fn with each coordinate of each geometry in g, which
+// fn may modify to transform g in place. See Apply.
+func (g *GeometryCollection) TransformInPlace(fn func(Coord)) {
+	Apply(g, fn)
+}
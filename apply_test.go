@@ -0,0 +1,69 @@
+package geom
+
+import (
+	"reflect"
+	"testing"
+)
+
+func translate(dx, dy float64) func(Coord) {
+	return func(c Coord) {
+		c[0] += dx
+		c[1] += dy
+	}
+}
+
+func TestApply(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		g    T
+		want T
+	}{
+		{
+			name: "empty_point",
+			g:    NewPointEmpty(XY),
+			want: NewPointEmpty(XY),
+		},
+		{
+			name: "linestring",
+			g:    NewLineStringFlat(XYZ, []float64{1, 2, 3, 4, 5, 6}).SetSRID(4326),
+			want: NewLineStringFlat(XYZ, []float64{11, 22, 3, 14, 25, 6}).SetSRID(4326),
+		},
+		{
+			name: "multipolygon",
+			g:    NewMultiPolygonFlat(XY, []float64{0, 0, 1, 0, 0, 1, 0, 0}, [][]int{{8}, {}}),
+			want: NewMultiPolygonFlat(XY, []float64{10, 20, 11, 20, 10, 21, 10, 20}, [][]int{{8}, {}}),
+		},
+		{
+			name: "geometrycollection",
+			g: NewGeometryCollection().MustPush(
+				NewPointFlat(XY, []float64{1, 2}),
+				NewGeometryCollection().MustPush(NewLineStringFlat(XYM, []float64{3, 4, 5, 6, 7, 8})),
+			),
+			want: NewGeometryCollection().MustPush(
+				NewPointFlat(XY, []float64{11, 22}),
+				NewGeometryCollection().MustPush(NewLineStringFlat(XYM, []float64{13, 24, 5, 16, 27, 8})),
+			),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g := Clone(tc.g)
+			Apply(g, translate(10, 20))
+			if !reflect.DeepEqual(g, tc.want) {
+				t.Errorf("Apply(%v, ...) transformed to %v, want %v", tc.g, g, tc.want)
+			}
+		})
+	}
+}
+
+func TestTransformInPlace(t *testing.T) {
+	p := NewPolygonFlat(XY, []float64{0, 0, 1, 0, 0, 1, 0, 0}, []int{8})
+	p.TransformInPlace(translate(1, 1))
+	if want := []float64{1, 1, 2, 1, 1, 2, 1, 1}; !reflect.DeepEqual(p.FlatCoords(), want) {
+		t.Errorf("TransformInPlace(...) transformed to %v, want %v", p.FlatCoords(), want)
+	}
+	gc := NewGeometryCollection().MustPush(p)
+	gc.TransformInPlace(translate(-1, -1))
+	if want := []float64{0, 0, 1, 0, 0, 1, 0, 0}; !reflect.DeepEqual(p.FlatCoords(), want) {
+		t.Errorf("TransformInPlace(...) transformed to %v, want %v", p.FlatCoords(), want)
+	}
+}
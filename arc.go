@@ -0,0 +1,164 @@
+package geom
+
+import "math"
+
+// DefaultSegmentsPerQuadrant is the default number of line segments per
+// quarter circle when linearizing arcs, as used by PostGIS.
+const DefaultSegmentsPerQuadrant = 32
+
+// An arc is a circular arc through three points in the XY plane.
+type arc struct {
+	cx, cy, r float64
+	a0        float64 // angle of the start point
+	midSweep  float64 // signed angle from the start point to the mid point
+	sweep     float64 // signed angle from the start point to the end point
+	linear    bool    // the points are collinear, so the arc is two segments
+}
+
+// newArc returns the arc starting at p0, passing through p1, and ending at
+// p2. If p0 and p2 are equal, the arc is a full circle with diameter p0 p1.
+func newArc(p0, p1, p2 []float64) arc {
+	x0, y0, x1, y1, x2, y2 := p0[0], p0[1], p1[0], p1[1], p2[0], p2[1]
+	if x0 == x2 && y0 == y2 {
+		cx, cy := (x0+x1)/2, (y0+y1)/2
+		r := math.Hypot(x0-cx, y0-cy)
+		if r == 0 {
+			return arc{linear: true}
+		}
+		return arc{
+			cx:       cx,
+			cy:       cy,
+			r:        r,
+			a0:       math.Atan2(y0-cy, x0-cx),
+			midSweep: math.Pi,
+			sweep:    2 * math.Pi,
+		}
+	}
+	// d is twice the signed area of the triangle p0 p1 p2, which is positive
+	// if the arc is counterclockwise.
+	d := 2 * (x0*(y1-y2) + x1*(y2-y0) + x2*(y0-y1))
+	if d == 0 {
+		return arc{linear: true}
+	}
+	s0, s1, s2 := x0*x0+y0*y0, x1*x1+y1*y1, x2*x2+y2*y2
+	cx := (s0*(y1-y2) + s1*(y2-y0) + s2*(y0-y1)) / d
+	cy := (s0*(x2-x1) + s1*(x0-x2) + s2*(x1-x0)) / d
+	a := arc{
+		cx: cx,
+		cy: cy,
+		r:  math.Hypot(x0-cx, y0-cy),
+		a0: math.Atan2(y0-cy, x0-cx),
+	}
+	a1 := math.Atan2(y1-cy, x1-cx)
+	a2 := math.Atan2(y2-cy, x2-cx)
+	if d > 0 {
+		a.midSweep = ccwAngle(a.a0, a1)
+		a.sweep = ccwAngle(a.a0, a2)
+	} else {
+		a.midSweep = -ccwAngle(a1, a.a0)
+		a.sweep = -ccwAngle(a2, a.a0)
+	}
+	return a
+}
+
+// contains returns whether the arc passes through the point of the circle at
+// angle theta.
+func (a arc) contains(theta float64) bool {
+	if a.sweep > 0 {
+		return ccwAngle(a.a0, theta) <= a.sweep
+	}
+	return ccwAngle(theta, a.a0) <= -a.sweep
+}
+
+// ccwAngle returns the counterclockwise angle from angle from to angle to, in
+// the range [0, 2π).
+func ccwAngle(from, to float64) float64 {
+	angle := math.Mod(to-from, 2*math.Pi)
+	if angle < 0 {
+		angle += 2 * math.Pi
+	}
+	return angle
+}
+
+// arcsLength returns the length of the arcs of the circular string
+// flatCoords.
+func arcsLength(flatCoords []float64, stride int) float64 {
+	var length float64
+	for i := 0; i+3*stride <= len(flatCoords); i += 2 * stride {
+		p0, p1, p2 := flatCoords[i:], flatCoords[i+stride:], flatCoords[i+2*stride:]
+		if a := newArc(p0, p1, p2); a.linear {
+			length += math.Hypot(p1[0]-p0[0], p1[1]-p0[1]) + math.Hypot(p2[0]-p1[0], p2[1]-p1[1])
+		} else {
+			length += a.r * math.Abs(a.sweep)
+		}
+	}
+	return length
+}
+
+// extendArcs extends b to include the arcs of the circular string
+// flatCoords, which extend beyond their control points where they cross the
+// axes through their centers.
+func (b *Bounds) extendArcs(flatCoords []float64, stride int) *Bounds {
+	b.extendFlatCoords(flatCoords, 0, len(flatCoords), stride)
+	for i := 0; i+3*stride <= len(flatCoords); i += 2 * stride {
+		a := newArc(flatCoords[i:], flatCoords[i+stride:], flatCoords[i+2*stride:])
+		if a.linear {
+			continue
+		}
+		if a.contains(0) {
+			b.max[0] = math.Max(b.max[0], a.cx+a.r)
+		}
+		if a.contains(math.Pi / 2) {
+			b.max[1] = math.Max(b.max[1], a.cy+a.r)
+		}
+		if a.contains(math.Pi) {
+			b.min[0] = math.Min(b.min[0], a.cx-a.r)
+		}
+		if a.contains(3 * math.Pi / 2) {
+			b.min[1] = math.Min(b.min[1], a.cy-a.r)
+		}
+	}
+	return b
+}
+
+// appendLinearizedArcs appends the vertices of the linearization of the arcs
+// of the circular string flatCoords to dst, with segmentsPerQuadrant segments
+// per quarter circle. Ordinates other than X and Y are interpolated linearly
+// between the control points.
+func appendLinearizedArcs(dst, flatCoords []float64, stride, segmentsPerQuadrant int) []float64 {
+	if len(flatCoords) == 0 {
+		return dst
+	}
+	if segmentsPerQuadrant < 1 {
+		segmentsPerQuadrant = 1
+	}
+	dst = append(dst, flatCoords[:stride]...)
+	for i := 0; i+3*stride <= len(flatCoords); i += 2 * stride {
+		p0, p1, p2 := flatCoords[i:i+stride], flatCoords[i+stride:i+2*stride], flatCoords[i+2*stride:i+3*stride]
+		a := newArc(p0, p1, p2)
+		if a.linear {
+			dst = append(dst, p1...)
+			dst = append(dst, p2...)
+			continue
+		}
+		n := int(math.Ceil(math.Abs(a.sweep) / (math.Pi / 2) * float64(segmentsPerQuadrant)))
+		for j := 1; j < n; j++ {
+			sweep := a.sweep * float64(j) / float64(n)
+			theta := a.a0 + sweep
+			dst = append(dst, a.cx+a.r*math.Cos(theta), a.cy+a.r*math.Sin(theta))
+			// Interpolate the other ordinates between p0 and p1 before the
+			// mid point and between p1 and p2 after it.
+			for k := 2; k < stride; k++ {
+				var v float64
+				if math.Abs(sweep) <= math.Abs(a.midSweep) {
+					v = p0[k] + (p1[k]-p0[k])*sweep/a.midSweep
+				} else {
+					v = p1[k] + (p2[k]-p1[k])*(sweep-a.midSweep)/(a.sweep-a.midSweep)
+				}
+				dst = append(dst, v)
+			}
+		}
+		dst = append(dst, p2...)
+	}
+	return dst
+}
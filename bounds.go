@@ -31,10 +31,20 @@ func (b *Bounds) Clone() *Bounds {
 // Extend extends b to include geometry g.
 func (b *Bounds) Extend(g T) *Bounds {
 	b.extendLayout(g.Layout())
+	flatCoords := g.FlatCoords()
+	switch g.(type) {
+	case *CircularString, *CompoundCurve, *CurvePolygon:
+		// Arcs extend beyond their control points, so extend b by the
+		// corners of g's bounds instead.
+		flatCoords = nil
+		if gb := g.Bounds(); !gb.IsEmpty() {
+			flatCoords = append(append(flatCoords, gb.min...), gb.max...)
+		}
+	}
 	if b.layout == XYZM && g.Layout() == XYM {
-		return b.extendXYZMFlatCoordsWithXYM(g.FlatCoords(), 0, len(g.FlatCoords()))
+		return b.extendXYZMFlatCoordsWithXYM(flatCoords, 0, len(flatCoords))
 	}
-	return b.extendFlatCoords(g.FlatCoords(), 0, len(g.FlatCoords()), g.Stride())
+	return b.extendFlatCoords(flatCoords, 0, len(flatCoords), g.Stride())
 }
 
 // IsEmpty returns true if b is empty.
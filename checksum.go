@@ -0,0 +1,136 @@
+package geom
+
+import (
+	"encoding/binary"
+	"hash/crc64"
+	"math"
+)
+
+var checksumTable = crc64.MakeTable(crc64.ECMA)
+
+// Checksum returns a CRC-64 checksum of g's type, layout, SRID, structure,
+// and coordinates rounded to precision decimal places. It is computed
+// incrementally, without encoding g, so it is a cheap way to detect changes
+// to geometries or to compare replicas of them. Geometries whose coordinates
+// differ by less than the precision usually have the same checksum, although
+// coordinates that round differently do not. NaN coordinates, such as those
+// of empty points in some encodings, all have the same checksum.
+func Checksum(g T, precision int) uint64 {
+	c := checksummer{scale: math.Pow10(precision)}
+	c.geom(g)
+	return c.crc
+}
+
+// A checksummer accumulates a checksum.
+type checksummer struct {
+	crc   uint64
+	scale float64
+	buf   [8]byte
+}
+
+func (c *checksummer) int(i int) {
+	binary.LittleEndian.PutUint64(c.buf[:], uint64(int64(i)))
+	c.crc = crc64.Update(c.crc, checksumTable, c.buf[:])
+}
+
+func (c *checksummer) float(f float64) {
+	var q uint64
+	switch {
+	case math.IsNaN(f):
+		q = math.Float64bits(math.NaN())
+	case math.IsInf(f, 0):
+		q = math.Float64bits(f)
+	default:
+		// Converting to an integer also gives -0 and 0 the same checksum.
+		q = uint64(int64(math.Round(f * c.scale)))
+	}
+	binary.LittleEndian.PutUint64(c.buf[:], q)
+	c.crc = crc64.Update(c.crc, checksumTable, c.buf[:])
+}
+
+func (c *checksummer) geom(g T) {
+	var typ int
+	switch g.(type) {
+	case *Point:
+		typ = 1
+	case *LineString:
+		typ = 2
+	case *Polygon:
+		typ = 3
+	case *MultiPoint:
+		typ = 4
+	case *MultiLineString:
+		typ = 5
+	case *MultiPolygon:
+		typ = 6
+	case *GeometryCollection:
+		typ = 7
+	case *CircularString:
+		typ = 8
+	case *CompoundCurve:
+		typ = 9
+	case *CurvePolygon:
+		typ = 10
+	case *PolyhedralSurface:
+		typ = 15
+	case *TIN:
+		typ = 16
+	case *Triangle:
+		typ = 17
+	case *LinearRing:
+		typ = 101
+	}
+	c.int(typ)
+	c.int(int(g.Layout()))
+	c.int(g.SRID())
+	if gc, ok := g.(*GeometryCollection); ok {
+		c.int(gc.NumGeoms())
+		for _, member := range gc.Geoms() {
+			c.geom(member)
+		}
+		return
+	}
+	switch g := g.(type) {
+	case *Polygon, *MultiLineString, *Triangle:
+		c.ints(g.Ends())
+	case *CompoundCurve:
+		c.ints(g.Ends())
+		c.bools(g.circular)
+	case *CurvePolygon:
+		endss := g.Endss()
+		c.int(len(endss))
+		for i, ends := range endss {
+			c.ints(ends)
+			c.bools(g.circular[i])
+		}
+	case *MultiPolygon, *PolyhedralSurface, *TIN:
+		endss := g.Endss()
+		c.int(len(endss))
+		for _, ends := range endss {
+			c.ints(ends)
+		}
+	}
+	flatCoords := g.FlatCoords()
+	c.int(len(flatCoords))
+	for _, f := range flatCoords {
+		c.float(f)
+	}
+}
+
+func (c *checksummer) ints(is []int) {
+	c.int(len(is))
+	for _, i := range is {
+		c.int(i)
+	}
+}
+
+func (c *checksummer) bools(bs []bool) {
+	c.int(len(bs))
+	for _, b := range bs {
+		if b {
+			c.int(1)
+		} else {
+			c.int(0)
+		}
+	}
+}
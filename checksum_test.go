@@ -0,0 +1,143 @@
+package geom
+
+import "testing"
+
+func TestChecksum(t *testing.T) {
+	polygon := NewPolygonFlat(XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}, []int{8})
+	for _, tc := range []struct {
+		name  string
+		g1    T
+		g2    T
+		equal bool
+	}{
+		{
+			name:  "identical",
+			g1:    polygon,
+			g2:    NewPolygonFlat(XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}, []int{8}),
+			equal: true,
+		},
+		{
+			name:  "within_precision",
+			g1:    polygon,
+			g2:    NewPolygonFlat(XY, []float64{0, -0.0000001, 1.0000001, 0, 1, 1, 0, 0}, []int{8}),
+			equal: true,
+		},
+		{
+			name: "beyond_precision",
+			g1:   polygon,
+			g2:   NewPolygonFlat(XY, []float64{0, 0, 1.00001, 0, 1, 1, 0, 0}, []int{8}),
+		},
+		{
+			name: "type",
+			g1:   NewLineStringFlat(XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}),
+			g2:   NewMultiPointFlat(XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}),
+		},
+		{
+			name: "layout",
+			g1:   NewMultiPointFlat(XYZ, []float64{0, 0, 1, 0, 1, 1}),
+			g2:   NewMultiPointFlat(XYM, []float64{0, 0, 1, 0, 1, 1}),
+		},
+		{
+			name: "srid",
+			g1:   polygon,
+			g2:   NewPolygonFlat(XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}, []int{8}).SetSRID(4326),
+		},
+		{
+			name: "structure",
+			g1:   NewMultiLineStringFlat(XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}, []int{4, 8}),
+			g2:   NewMultiLineStringFlat(XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}, []int{6, 8}),
+		},
+		{
+			name: "multipolygon_structure",
+			g1:   NewMultiPolygonFlat(XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}, [][]int{{8}, {}}),
+			g2:   NewMultiPolygonFlat(XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}, [][]int{{}, {8}}),
+		},
+		{
+			name: "circular_string",
+			g1:   NewLineStringFlat(XY, []float64{0, 0, 1, 1, 2, 0}),
+			g2:   NewCircularStringFlat(XY, []float64{0, 0, 1, 1, 2, 0}),
+		},
+		{
+			name: "compound_curve_circular",
+			g1: NewCompoundCurve(XY).MustPush(
+				NewLineStringFlat(XY, []float64{0, 0, 1, 1}),
+				NewLineStringFlat(XY, []float64{1, 1, 2, 0, 3, 1}),
+			),
+			g2: NewCompoundCurve(XY).MustPush(
+				NewLineStringFlat(XY, []float64{0, 0, 1, 1}),
+				NewCircularStringFlat(XY, []float64{1, 1, 2, 0, 3, 1}),
+			),
+		},
+		{
+			name: "compound_curve_ends",
+			g1: NewCompoundCurve(XY).MustPush(
+				NewLineStringFlat(XY, []float64{0, 0, 0, 0}),
+				NewLineStringFlat(XY, []float64{0, 0, 0, 0, 0, 0}),
+			),
+			g2: NewCompoundCurve(XY).MustPush(
+				NewLineStringFlat(XY, []float64{0, 0, 0, 0, 0, 0}),
+				NewLineStringFlat(XY, []float64{0, 0, 0, 0}),
+			),
+		},
+		{
+			name: "curve_polygon_circular",
+			g1:   NewCurvePolygon(XY).MustPush(NewLineStringFlat(XY, []float64{0, 0, 1, 1, 0, 0})),
+			g2:   NewCurvePolygon(XY).MustPush(NewCircularStringFlat(XY, []float64{0, 0, 1, 1, 0, 0})),
+		},
+		{
+			name: "curve_polygon_vs_polygon",
+			g1:   NewCurvePolygon(XY).MustPush(NewLineStringFlat(XY, []float64{0, 0, 1, 0, 1, 1, 0, 0})),
+			g2:   polygon,
+		},
+		{
+			name: "triangle_vs_polygon",
+			g1:   NewTriangleFlat(XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}, []int{8}),
+			g2:   polygon,
+		},
+		{
+			name: "polyhedral_surface_vs_multipolygon",
+			g1:   NewPolyhedralSurfaceFlat(XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}, [][]int{{8}}),
+			g2:   NewMultiPolygonFlat(XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}, [][]int{{8}}),
+		},
+		{
+			name: "tin_vs_polyhedral_surface",
+			g1:   NewTINFlat(XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}, [][]int{{8}}),
+			g2:   NewPolyhedralSurfaceFlat(XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}, [][]int{{8}}),
+		},
+		{
+			name: "tin_structure",
+			g1:   NewTINFlat(XY, []float64{0, 0, 1, 0, 1, 1, 0, 0, 0, 0, 1, 0, 1, 1, 0, 0}, [][]int{{8}, {16}}),
+			g2:   NewTINFlat(XY, []float64{0, 0, 1, 0, 1, 1, 0, 0, 0, 0, 1, 0, 1, 1, 0, 0}, [][]int{{8, 16}}),
+		},
+		{
+			name:  "geometry_collection",
+			g1:    NewGeometryCollection().MustPush(polygon, NewPointFlat(XY, []float64{1, 2})),
+			g2:    NewGeometryCollection().MustPush(polygon, NewPointFlat(XY, []float64{1, 2})),
+			equal: true,
+		},
+		{
+			name: "geometry_collection_order",
+			g1:   NewGeometryCollection().MustPush(polygon, NewPointFlat(XY, []float64{1, 2})),
+			g2:   NewGeometryCollection().MustPush(NewPointFlat(XY, []float64{1, 2}), polygon),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c1, c2 := Checksum(tc.g1, 6), Checksum(tc.g2, 6)
+			if (c1 == c2) != tc.equal {
+				t.Errorf("Checksum(g1, 6) == %#x, Checksum(g2, 6) == %#x, want equal %t", c1, c2, tc.equal)
+			}
+		})
+	}
+}
+
+func BenchmarkChecksum(b *testing.B) {
+	flatCoords := make([]float64, 2*1024)
+	for i := range flatCoords {
+		flatCoords[i] = float64(i) / 3
+	}
+	g := NewLineStringFlat(XY, flatCoords)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = Checksum(g, 6)
+	}
+}
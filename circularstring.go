@@ -0,0 +1,100 @@
+package geom
+
+// A CircularString represents a curve of circular arcs. Each arc is defined
+// by three control points, its start point, a point on the arc, and its end
+// point, and each arc starts at the end point of the previous arc, so a
+// non-empty CircularString has an odd number of at least three control
+// points. An arc whose start and end points are equal is a full circle.
+type CircularString struct {
+	geom1
+}
+
+// NewCircularString returns a new CircularString with layout l and no
+// control points.
+func NewCircularString(l Layout) *CircularString {
+	return NewCircularStringFlat(l, nil)
+}
+
+// NewCircularStringFlat returns a new CircularString with layout l and
+// control points flatCoords.
+func NewCircularStringFlat(layout Layout, flatCoords []float64) *CircularString {
+	g := new(CircularString)
+	g.layout = layout
+	g.stride = layout.Stride()
+	g.flatCoords = flatCoords
+	return g
+}
+
+// Area returns the area of g, i.e. zero.
+func (g *CircularString) Area() float64 {
+	return 0
+}
+
+// Bounds returns the bounds of g, including the parts of its arcs that
+// extend beyond its control points.
+func (g *CircularString) Bounds() *Bounds {
+	return NewBounds(g.layout).extendArcs(g.flatCoords, g.stride)
+}
+
+// Clone returns a copy of g that does not alias g.
+func (g *CircularString) Clone() *CircularString {
+	return NewCircularStringFlat(g.layout, append([]float64(nil), g.flatCoords...)).SetSRID(g.srid)
+}
+
+// Empty returns true if the geometry has no coordinate.
+func (g *CircularString) Empty() bool {
+	return len(g.FlatCoords()) == 0
+}
+
+// Length returns the length of the arcs of g.
+func (g *CircularString) Length() float64 {
+	return arcsLength(g.flatCoords, g.stride)
+}
+
+// Linearize returns a LineString approximating g with segmentsPerQuadrant
+// line segments per quarter circle, for example
+// DefaultSegmentsPerQuadrant.
+func (g *CircularString) Linearize(segmentsPerQuadrant int) *LineString {
+	flatCoords := appendLinearizedArcs(nil, g.flatCoords, g.stride, segmentsPerQuadrant)
+	return NewLineStringFlat(g.layout, flatCoords).SetSRID(g.srid)
+}
+
+// MustSetCoords is like SetCoords but it panics on any error.
+func (g *CircularString) MustSetCoords(coords []Coord) *CircularString {
+	Must(g.SetCoords(coords))
+	return g
+}
+
+// NumArcs returns the number of arcs in g.
+func (g *CircularString) NumArcs() int {
+	if n := g.NumCoords(); n >= 3 {
+		return (n - 1) / 2
+	}
+	return 0
+}
+
+// SetCoords sets the control points of g.
+func (g *CircularString) SetCoords(coords []Coord) (*CircularString, error) {
+	if err := g.setCoords(coords); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// SetSRID sets the SRID of g.
+func (g *CircularString) SetSRID(srid int) *CircularString {
+	g.srid = srid
+	return g
+}
+
+// Swap swaps the values of g and g2.
+func (g *CircularString) Swap(g2 *CircularString) {
+	*g, *g2 = *g2, *g
+}
+
+// validCircularString returns whether the circular string flatCoords has an
+// odd number of at least three control points.
+func validCircularString(flatCoords []float64, stride int) bool {
+	n := len(flatCoords) / stride
+	return n >= 3 && n%2 == 1
+}
@@ -0,0 +1,127 @@
+package geom
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+// floatsNear returns whether all of got are within epsilon of want.
+func floatsNear(got, want []float64, epsilon float64) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if math.Abs(got[i]-want[i]) > epsilon {
+			return false
+		}
+	}
+	return true
+}
+
+func TestCircularString(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		cs         *CircularString
+		wantBounds *Bounds
+		wantLength float64
+		wantArcs   int
+	}{
+		{
+			name:       "empty",
+			cs:         NewCircularString(XY),
+			wantBounds: NewBounds(XY),
+		},
+		{
+			name:       "clockwise_semicircle",
+			cs:         NewCircularStringFlat(XY, []float64{-1, 0, 0, 1, 1, 0}),
+			wantBounds: NewBounds(XY).Set(-1, 0, 1, 1),
+			wantLength: math.Pi,
+			wantArcs:   1,
+		},
+		{
+			name:       "counterclockwise_semicircle",
+			cs:         NewCircularStringFlat(XY, []float64{1, 0, 0, -1, -1, 0}),
+			wantBounds: NewBounds(XY).Set(-1, -1, 1, 0),
+			wantLength: math.Pi,
+			wantArcs:   1,
+		},
+		{
+			name:       "quarter_circle",
+			cs:         NewCircularStringFlat(XY, []float64{1, 0, math.Sqrt2 / 2, math.Sqrt2 / 2, 0, 1}),
+			wantBounds: NewBounds(XY).Set(0, 0, 1, 1),
+			wantLength: math.Pi / 2,
+			wantArcs:   1,
+		},
+		{
+			name:       "circle",
+			cs:         NewCircularStringFlat(XYZ, []float64{1, 0, 5, -1, 0, 6, 1, 0, 7}),
+			wantBounds: NewBounds(XYZ).Set(-1, -1, 5, 1, 1, 7),
+			wantLength: 2 * math.Pi,
+			wantArcs:   1,
+		},
+		{
+			name:       "collinear",
+			cs:         NewCircularStringFlat(XY, []float64{0, 0, 1, 1, 2, 2}),
+			wantBounds: NewBounds(XY).Set(0, 0, 2, 2),
+			wantLength: 2 * math.Sqrt2,
+			wantArcs:   1,
+		},
+		{
+			name:       "two_arcs",
+			cs:         NewCircularStringFlat(XY, []float64{0, 0, 1, 1, 2, 0, 3, -1, 4, 0}),
+			wantBounds: NewBounds(XY).Set(0, -1, 4, 1),
+			wantLength: 2 * math.Pi,
+			wantArcs:   2,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.cs.Bounds(); !reflect.DeepEqual(got, tc.wantBounds) {
+				t.Errorf("Bounds() == %v, want %v", got, tc.wantBounds)
+			}
+			if got := tc.cs.Length(); math.Abs(got-tc.wantLength) > 1e-12 {
+				t.Errorf("Length() == %v, want %v", got, tc.wantLength)
+			}
+			if got := tc.cs.NumArcs(); got != tc.wantArcs {
+				t.Errorf("NumArcs() == %d, want %d", got, tc.wantArcs)
+			}
+			if got := tc.cs.Area(); got != 0 {
+				t.Errorf("Area() == %v, want 0", got)
+			}
+			ls := tc.cs.Linearize(DefaultSegmentsPerQuadrant)
+			if got := ls.Length(); math.Abs(got-tc.wantLength) > 1e-3 {
+				t.Errorf("Linearize(...).Length() == %v, want %v", got, tc.wantLength)
+			}
+			if got := NewBounds(XY).Extend(tc.cs); !tc.cs.Empty() && !reflect.DeepEqual(got, tc.wantBounds) {
+				t.Errorf("NewBounds(XY).Extend(cs) == %v, want %v", got, tc.wantBounds)
+			}
+		})
+	}
+}
+
+func TestCircularStringLinearize(t *testing.T) {
+	cs := NewCircularStringFlat(XYZ, []float64{-1, 0, 0, 0, 1, 1, 1, 0, 2}).SetSRID(4326)
+	got := cs.Linearize(1)
+	want := []float64{-1, 0, 0, 0, 1, 1, 1, 0, 2}
+	if !floatsNear(got.FlatCoords(), want, 1e-15) || got.Layout() != XYZ || got.SRID() != 4326 {
+		t.Errorf("Linearize(1) == %v, want %v", got.FlatCoords(), want)
+	}
+	got = cs.Linearize(2)
+	h := math.Sqrt2 / 2
+	want = []float64{-1, 0, 0, -h, h, 0.5, 0, 1, 1, h, h, 1.5, 1, 0, 2}
+	if !floatsNear(got.FlatCoords(), want, 1e-15) {
+		t.Errorf("Linearize(2) == %v, want %v", got.FlatCoords(), want)
+	}
+}
+
+func TestCircularStringClone(t *testing.T) {
+	cs := NewCircularStringFlat(XY, []float64{0, 0, 1, 1, 2, 0}).SetSRID(4326)
+	clone := cs.Clone()
+	if !reflect.DeepEqual(clone, cs) {
+		t.Errorf("Clone() == %v, want %v", clone, cs)
+	}
+	clone.FlatCoords()[0] = 1
+	if cs.FlatCoords()[0] != 0 {
+		t.Error("Clone() aliases the original")
+	}
+}
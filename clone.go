@@ -0,0 +1,54 @@
+package geom
+
+// Clone returns a deep copy of g that shares no flat coordinates, ends, or
+// members with g, so that modifying either does not affect the other. It
+// returns nil if g is nil and panics if g is not one of this package's
+// geometry types.
+func Clone(g T) T {
+	switch g := g.(type) {
+	case nil:
+		return nil
+	case *Point:
+		return g.Clone()
+	case *LineString:
+		return g.Clone()
+	case *LinearRing:
+		return g.Clone()
+	case *Polygon:
+		return g.Clone()
+	case *MultiPoint:
+		return g.Clone()
+	case *MultiLineString:
+		return g.Clone()
+	case *MultiPolygon:
+		return g.Clone()
+	case *GeometryCollection:
+		return g.Clone()
+	case *CircularString:
+		return g.Clone()
+	case *CompoundCurve:
+		return g.Clone()
+	case *CurvePolygon:
+		return g.Clone()
+	case *Triangle:
+		return g.Clone()
+	case *PolyhedralSurface:
+		return g.Clone()
+	case *TIN:
+		return g.Clone()
+	default:
+		panic(ErrUnsupportedType{Value: g})
+	}
+}
+
+// Clone returns a deep copy of g, cloning each of its geometries.
+func (g *GeometryCollection) Clone() *GeometryCollection {
+	g2 := NewGeometryCollection().SetSRID(g.srid)
+	if g.geoms != nil {
+		g2.geoms = make([]T, len(g.geoms))
+		for i, member := range g.geoms {
+			g2.geoms[i] = Clone(member)
+		}
+	}
+	return g2
+}
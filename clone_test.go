@@ -0,0 +1,52 @@
+package geom
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestClone(t *testing.T) {
+	for _, g := range []T{
+		NewPointFlat(XY, []float64{1, 2}).SetSRID(4326),
+		NewLineStringFlat(XYZ, []float64{1, 2, 3, 4, 5, 6}),
+		NewLinearRingFlat(XY, []float64{0, 0, 1, 0, 0, 1, 0, 0}),
+		NewPolygonFlat(XY, []float64{0, 0, 1, 0, 0, 1, 0, 0}, []int{8}),
+		NewMultiPointFlat(XYM, []float64{1, 2, 3}),
+		NewMultiLineStringFlat(XY, []float64{1, 2, 3, 4}, []int{4}),
+		NewMultiPolygonFlat(XY, []float64{0, 0, 1, 0, 0, 1, 0, 0}, [][]int{{8}}),
+		NewCircularStringFlat(XY, []float64{0, 0, 1, 1, 2, 0}),
+		NewCompoundCurve(XY).MustPush(NewLineStringFlat(XY, []float64{0, 0, 1, 0})),
+		NewCurvePolygon(XY).MustPush(NewCircularStringFlat(XY, []float64{0, 0, 1, 0, 0, 0})),
+		NewTriangleFlat(XY, []float64{0, 0, 1, 0, 0, 1, 0, 0}, []int{8}),
+		NewPolyhedralSurfaceFlat(XY, []float64{0, 0, 1, 0, 0, 1, 0, 0}, [][]int{{8}}),
+		NewTINFlat(XY, []float64{0, 0, 1, 0, 0, 1, 0, 0}, [][]int{{8}}),
+	} {
+		clone := Clone(g)
+		if !reflect.DeepEqual(clone, g) {
+			t.Errorf("Clone(%v) == %v, want %v", g, clone, g)
+		}
+		clone.FlatCoords()[0] = 9
+		if g.FlatCoords()[0] == 9 {
+			t.Errorf("Clone(%v) aliases its flat coordinates", g)
+		}
+	}
+}
+
+func TestCloneGeometryCollection(t *testing.T) {
+	ls := NewLineStringFlat(XY, []float64{1, 2, 3, 4})
+	gc := NewGeometryCollection().MustPush(
+		NewPointFlat(XY, []float64{1, 2}),
+		NewGeometryCollection().MustPush(ls),
+	).SetSRID(4326)
+	clone := Clone(gc).(*GeometryCollection)
+	if !reflect.DeepEqual(clone, gc) {
+		t.Errorf("Clone(%v) == %v, want %v", gc, clone, gc)
+	}
+	clone.Geom(1).(*GeometryCollection).Geom(0).FlatCoords()[0] = 9
+	if ls.FlatCoords()[0] != 1 {
+		t.Error("Clone() aliases the members of nested GeometryCollections")
+	}
+	if got := Clone(nil); got != nil {
+		t.Errorf("Clone(nil) == %v, want <nil>", got)
+	}
+}
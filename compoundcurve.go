@@ -0,0 +1,191 @@
+package geom
+
+// A CompoundCurve represents a curve composed of segments that are
+// LineStrings or CircularStrings, each starting at the end point of the
+// previous segment. The shared end points are stored in both segments.
+type CompoundCurve struct {
+	geom2
+	circular []bool // whether each segment is a CircularString
+}
+
+// NewCompoundCurve returns a new, empty, CompoundCurve with layout l.
+func NewCompoundCurve(l Layout) *CompoundCurve {
+	g := new(CompoundCurve)
+	g.layout = l
+	g.stride = l.Stride()
+	return g
+}
+
+// Area returns the area of g, i.e. zero.
+func (g *CompoundCurve) Area() float64 {
+	return 0
+}
+
+// Bounds returns the bounds of g, including the parts of its arcs that
+// extend beyond its control points.
+func (g *CompoundCurve) Bounds() *Bounds {
+	return extendSegments(NewBounds(g.layout), g.flatCoords, 0, g.ends, g.circular, g.stride)
+}
+
+// Clone returns a copy of g that does not alias g.
+func (g *CompoundCurve) Clone() *CompoundCurve {
+	g2 := NewCompoundCurve(g.layout).SetSRID(g.srid)
+	g2.flatCoords = append([]float64(nil), g.flatCoords...)
+	g2.ends = append([]int(nil), g.ends...)
+	g2.circular = append([]bool(nil), g.circular...)
+	return g2
+}
+
+// Empty returns true if the geometry has no coordinate.
+func (g *CompoundCurve) Empty() bool {
+	return len(g.FlatCoords()) == 0
+}
+
+// Length returns the length of g.
+func (g *CompoundCurve) Length() float64 {
+	return segmentsLength(g.flatCoords, 0, g.ends, g.circular, g.stride)
+}
+
+// Linearize returns a LineString approximating g with segmentsPerQuadrant
+// line segments per quarter circle of its arcs, for example
+// DefaultSegmentsPerQuadrant.
+func (g *CompoundCurve) Linearize(segmentsPerQuadrant int) *LineString {
+	flatCoords := appendLinearizedSegments(nil, g.flatCoords, 0, g.ends, g.circular, g.stride, segmentsPerQuadrant)
+	return NewLineStringFlat(g.layout, flatCoords).SetSRID(g.srid)
+}
+
+// MustPush pushes segments to g. It panics on any error.
+func (g *CompoundCurve) MustPush(segments ...T) *CompoundCurve {
+	if err := g.Push(segments...); err != nil {
+		panic(err)
+	}
+	return g
+}
+
+// NumSegments returns the number of segments in g.
+func (g *CompoundCurve) NumSegments() int {
+	return len(g.ends)
+}
+
+// Push appends segments, which must be LineStrings or CircularStrings, to g.
+func (g *CompoundCurve) Push(segments ...T) error {
+	for _, segment := range segments {
+		flatCoords, circular, err := g.checkSegment(len(g.ends), segment)
+		if err != nil {
+			return err
+		}
+		g.flatCoords = append(g.flatCoords, flatCoords...)
+		g.ends = append(g.ends, len(g.flatCoords))
+		g.circular = append(g.circular, circular)
+	}
+	return nil
+}
+
+// Segment returns the ith segment of g, which is a LineString or a
+// CircularString that aliases g.
+func (g *CompoundCurve) Segment(i int) T {
+	return newSegment(g.layout, g.flatCoords[g.offset(i):g.ends[i]], g.circular[i], g.srid)
+}
+
+// SetSRID sets the SRID of g.
+func (g *CompoundCurve) SetSRID(srid int) *CompoundCurve {
+	g.srid = srid
+	return g
+}
+
+// Swap swaps the values of g and g2.
+func (g *CompoundCurve) Swap(g2 *CompoundCurve) {
+	*g, *g2 = *g2, *g
+}
+
+// checkSegment returns the flat coordinates of segment and whether it is a
+// CircularString if it can be appended to g as its indexth segment.
+func (g *CompoundCurve) checkSegment(index int, segment T) ([]float64, bool, error) {
+	var circular bool
+	switch s := segment.(type) {
+	case *LineString:
+		if s.NumCoords() < 2 {
+			return nil, false, ErrInvalidSegment{Index: index, Reason: "fewer than two points"}
+		}
+	case *CircularString:
+		if !validCircularString(s.flatCoords, s.stride) {
+			return nil, false, ErrInvalidSegment{Index: index, Reason: "not an odd number of at least three points"}
+		}
+		circular = true
+	default:
+		return nil, false, ErrUnsupportedType{Value: segment}
+	}
+	if segment.Layout() != g.layout {
+		return nil, false, ErrLayoutMismatch{Got: segment.Layout(), Want: g.layout}
+	}
+	flatCoords := segment.FlatCoords()
+	if n := len(g.flatCoords); n > 0 && !equalCoords(g.flatCoords[n-g.stride:], flatCoords[:g.stride]) {
+		return nil, false, ErrInvalidSegment{Index: index, Reason: "does not start at the end of the previous segment"}
+	}
+	return flatCoords, circular, nil
+}
+
+// newSegment returns a LineString or, if circular is true, a CircularString
+// with layout, flatCoords, and srid.
+func newSegment(layout Layout, flatCoords []float64, circular bool, srid int) T {
+	if circular {
+		return NewCircularStringFlat(layout, flatCoords).SetSRID(srid)
+	}
+	return NewLineStringFlat(layout, flatCoords).SetSRID(srid)
+}
+
+// extendSegments extends b to include the segments of a curve.
+func extendSegments(b *Bounds, flatCoords []float64, offset int, ends []int, circular []bool, stride int) *Bounds {
+	for i, end := range ends {
+		if circular[i] {
+			b.extendArcs(flatCoords[offset:end], stride)
+		} else {
+			b.extendFlatCoords(flatCoords, offset, end, stride)
+		}
+		offset = end
+	}
+	return b
+}
+
+// segmentsLength returns the length of the segments of a curve.
+func segmentsLength(flatCoords []float64, offset int, ends []int, circular []bool, stride int) float64 {
+	var length float64
+	for i, end := range ends {
+		if circular[i] {
+			length += arcsLength(flatCoords[offset:end], stride)
+		} else {
+			length += length1(flatCoords, offset, end, stride)
+		}
+		offset = end
+	}
+	return length
+}
+
+// appendLinearizedSegments appends the vertices of the linearization of the
+// segments of a curve to dst, without repeating the end points that
+// consecutive segments share.
+func appendLinearizedSegments(dst, flatCoords []float64, offset int, ends []int, circular []bool, stride, segmentsPerQuadrant int) []float64 {
+	for i, end := range ends {
+		n := len(dst)
+		if circular[i] {
+			dst = appendLinearizedArcs(dst, flatCoords[offset:end], stride, segmentsPerQuadrant)
+		} else {
+			dst = append(dst, flatCoords[offset:end]...)
+		}
+		if i > 0 {
+			dst = append(dst[:n], dst[n+stride:]...)
+		}
+		offset = end
+	}
+	return dst
+}
+
+// equalCoords returns whether the coordinates c1 and c2 are equal.
+func equalCoords(c1, c2 []float64) bool {
+	for i := range c1 {
+		if c1[i] != c2[i] {
+			return false
+		}
+	}
+	return true
+}
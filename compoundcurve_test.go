@@ -0,0 +1,89 @@
+package geom
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestCompoundCurve(t *testing.T) {
+	arc := NewCircularStringFlat(XY, []float64{0, 0, 1, 1, 2, 0})
+	line := NewLineStringFlat(XY, []float64{2, 0, 0, 0})
+	cc := NewCompoundCurve(XY).MustPush(arc, line)
+	if got := cc.NumSegments(); got != 2 {
+		t.Errorf("NumSegments() == %d, want 2", got)
+	}
+	if got := cc.Segment(0); !reflect.DeepEqual(got, arc) {
+		t.Errorf("Segment(0) == %v, want %v", got, arc)
+	}
+	if got := cc.Segment(1); !reflect.DeepEqual(got, line) {
+		t.Errorf("Segment(1) == %v, want %v", got, line)
+	}
+	if got, want := cc.Bounds(), NewBounds(XY).Set(0, 0, 2, 1); !reflect.DeepEqual(got, want) {
+		t.Errorf("Bounds() == %v, want %v", got, want)
+	}
+	if got, want := cc.Length(), math.Pi+2; math.Abs(got-want) > 1e-12 {
+		t.Errorf("Length() == %v, want %v", got, want)
+	}
+	if got, want := cc.Linearize(1).FlatCoords(), []float64{0, 0, 1, 1, 2, 0, 0, 0}; !floatsNear(got, want, 1e-15) {
+		t.Errorf("Linearize(1) == %v, want %v", got, want)
+	}
+	if got, want := cc.Coords(), [][]Coord{{{0, 0}, {1, 1}, {2, 0}}, {{2, 0}, {0, 0}}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Coords() == %v, want %v", got, want)
+	}
+	if err := cc.verify(); err != nil {
+		t.Errorf("verify() == %v, want <nil>", err)
+	}
+	clone := cc.Clone()
+	if !reflect.DeepEqual(clone, cc) {
+		t.Errorf("Clone() == %v, want %v", clone, cc)
+	}
+	clone.FlatCoords()[0] = 1
+	if cc.FlatCoords()[0] != 0 {
+		t.Error("Clone() aliases the original")
+	}
+}
+
+func TestCompoundCurvePushErrors(t *testing.T) {
+	cc := NewCompoundCurve(XY).MustPush(NewLineStringFlat(XY, []float64{0, 0, 1, 0}))
+	for _, tc := range []struct {
+		name    string
+		segment T
+		want    error
+	}{
+		{
+			name:    "discontinuous",
+			segment: NewLineStringFlat(XY, []float64{2, 0, 3, 0}),
+			want:    ErrInvalidSegment{Index: 1, Reason: "does not start at the end of the previous segment"},
+		},
+		{
+			name:    "short_line_string",
+			segment: NewLineStringFlat(XY, []float64{1, 0}),
+			want:    ErrInvalidSegment{Index: 1, Reason: "fewer than two points"},
+		},
+		{
+			name:    "even_circular_string",
+			segment: NewCircularStringFlat(XY, []float64{1, 0, 2, 1, 3, 0, 4, 1}),
+			want:    ErrInvalidSegment{Index: 1, Reason: "not an odd number of at least three points"},
+		},
+		{
+			name:    "layout_mismatch",
+			segment: NewLineStringFlat(XYZ, []float64{1, 0, 0, 2, 0, 0}),
+			want:    ErrLayoutMismatch{Got: XYZ, Want: XY},
+		},
+		{
+			name:    "unsupported_type",
+			segment: NewPointFlat(XY, []float64{1, 0}),
+			want:    ErrUnsupportedType{Value: NewPointFlat(XY, []float64{1, 0})},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := cc.Push(tc.segment); !reflect.DeepEqual(err, tc.want) {
+				t.Errorf("Push(%v) == %v, want %v", tc.segment, err, tc.want)
+			}
+			if got := cc.NumSegments(); got != 1 {
+				t.Errorf("NumSegments() == %d, want 1", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package geom
+
+// Iterators over coordinates and ends. They are the preferred way to read the
+// coordinates of a geometry: unlike Coords, which copies the coordinates into
+// newly allocated nested slices, they return views of the geometry's flat
+// coordinates and do not allocate. The views are only valid until the
+// geometry is modified and must not be modified themselves.
+//
+// Iterators are used like bufio.Scanner:
+//
+//	it := g.CoordsIter()
+//	for it.Next() {
+//		c := it.Coord()
+//		...
+//	}
+
+// A CoordsIter iterates over the coordinates of a geometry.
+type CoordsIter struct {
+	flatCoords []float64
+	stride     int
+	offset     int
+	coord      Coord
+	index      int
+	geoms      [][]T // remaining members of enclosing geometry collections
+}
+
+// Next advances the iterator to the next coordinate and reports whether there
+// is one.
+func (it *CoordsIter) Next() bool {
+	for it.stride == 0 || it.offset >= len(it.flatCoords) {
+		if !it.nextGeom() {
+			it.coord = nil
+			return false
+		}
+	}
+	it.coord = it.flatCoords[it.offset : it.offset+it.stride : it.offset+it.stride]
+	it.offset += it.stride
+	it.index++
+	return true
+}
+
+// nextGeom moves the iterator to the flat coordinates of the next member of
+// the enclosing geometry collections, if any.
+func (it *CoordsIter) nextGeom() bool {
+	for len(it.geoms) > 0 {
+		geoms := it.geoms[len(it.geoms)-1]
+		if len(geoms) == 0 {
+			it.geoms = it.geoms[:len(it.geoms)-1]
+			continue
+		}
+		g := geoms[0]
+		it.geoms[len(it.geoms)-1] = geoms[1:]
+		if gc, ok := g.(*GeometryCollection); ok {
+			it.geoms = append(it.geoms, gc.geoms)
+			continue
+		}
+		it.flatCoords, it.stride, it.offset = g.FlatCoords(), g.Stride(), 0
+		return true
+	}
+	return false
+}
+
+// Coord returns the current coordinate. The members of a GeometryCollection
+// may have different layouts, so coordinates may have different lengths.
+func (it *CoordsIter) Coord() Coord {
+	return it.coord
+}
+
+// Index returns the index of the current coordinate, counting from zero.
+func (it *CoordsIter) Index() int {
+	return it.index - 1
+}
+
+func newCoordsIter(flatCoords []float64, stride int) CoordsIter {
+	return CoordsIter{
+		flatCoords: flatCoords,
+		stride:     stride,
+	}
+}
+
+// An EndsIter iterates over the lines or rings of a geometry.
+type EndsIter struct {
+	flatCoords []float64
+	stride     int
+	ends       []int
+	offset     int
+	end        int
+	index      int
+}
+
+// Next advances the iterator to the next line or ring and reports whether
+// there is one.
+func (it *EndsIter) Next() bool {
+	if it.index >= len(it.ends) {
+		return false
+	}
+	it.offset, it.end = it.end, it.ends[it.index]
+	it.index++
+	return true
+}
+
+// Index returns the index of the current line or ring, counting from zero.
+func (it *EndsIter) Index() int {
+	return it.index - 1
+}
+
+// Offset returns the offset of the current line or ring in the geometry's
+// flat coordinates.
+func (it *EndsIter) Offset() int {
+	return it.offset
+}
+
+// End returns the end of the current line or ring in the geometry's flat
+// coordinates.
+func (it *EndsIter) End() int {
+	return it.end
+}
+
+// NumCoords returns the number of coordinates in the current line or ring.
+func (it *EndsIter) NumCoords() int {
+	if it.stride == 0 {
+		return 0
+	}
+	return (it.end - it.offset) / it.stride
+}
+
+// FlatCoords returns the flat coordinates of the current line or ring.
+func (it *EndsIter) FlatCoords() []float64 {
+	return it.flatCoords[it.offset:it.end:it.end]
+}
+
+// CoordsIter returns an iterator over the coordinates of the current line or
+// ring.
+func (it *EndsIter) CoordsIter() CoordsIter {
+	return newCoordsIter(it.FlatCoords(), it.stride)
+}
+
+func newEndsIter(flatCoords []float64, offset int, ends []int, stride int) EndsIter {
+	return EndsIter{
+		flatCoords: flatCoords,
+		stride:     stride,
+		ends:       ends,
+		end:        offset,
+	}
+}
+
+// An EndssIter iterates over the polygons of a MultiPolygon.
+type EndssIter struct {
+	flatCoords []float64
+	stride     int
+	endss      [][]int
+	offset     int
+	index      int
+}
+
+// Next advances the iterator to the next polygon and reports whether there is
+// one.
+func (it *EndssIter) Next() bool {
+	if it.index > 0 {
+		if ends := it.endss[it.index-1]; len(ends) > 0 {
+			it.offset = ends[len(ends)-1]
+		}
+	}
+	if it.index >= len(it.endss) {
+		return false
+	}
+	it.index++
+	return true
+}
+
+// Index returns the index of the current polygon, counting from zero.
+func (it *EndssIter) Index() int {
+	return it.index - 1
+}
+
+// Ends returns the ends of the current polygon.
+func (it *EndssIter) Ends() []int {
+	return it.endss[it.index-1]
+}
+
+// EndsIter returns an iterator over the rings of the current polygon.
+func (it *EndssIter) EndsIter() EndsIter {
+	return newEndsIter(it.flatCoords, it.offset, it.Ends(), it.stride)
+}
+
+// CoordsIter returns an iterator over all the coordinates in g.
+func (g *geom0) CoordsIter() CoordsIter {
+	return newCoordsIter(g.flatCoords, g.stride)
+}
+
+// EndsIter returns an iterator over the lines or rings of g.
+func (g *geom2) EndsIter() EndsIter {
+	return newEndsIter(g.flatCoords, 0, g.ends, g.stride)
+}
+
+// EndssIter returns an iterator over the polygons of g.
+func (g *geom3) EndssIter() EndssIter {
+	return EndssIter{
+		flatCoords: g.flatCoords,
+		stride:     g.stride,
+		endss:      g.endss,
+	}
+}
+
+// CoordsIter returns an iterator over all the coordinates of all the
+// geometries in g, in order, including those of nested collections.
+func (g *GeometryCollection) CoordsIter() CoordsIter {
+	return CoordsIter{
+		geoms: [][]T{g.geoms},
+	}
+}
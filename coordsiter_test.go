@@ -0,0 +1,140 @@
+package geom
+
+import (
+	"reflect"
+	"testing"
+)
+
+func collectCoords(it *CoordsIter) []Coord {
+	var coords []Coord
+	for it.Next() {
+		if it.Index() != len(coords) {
+			panic("unexpected index")
+		}
+		coords = append(coords, it.Coord())
+	}
+	return coords
+}
+
+func TestCoordsIter(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		it   CoordsIter
+		want []Coord
+	}{
+		{
+			name: "point",
+			it:   NewPointFlat(XY, []float64{1, 2}).CoordsIter(),
+			want: []Coord{{1, 2}},
+		},
+		{
+			name: "empty_point",
+			it:   NewPointEmpty(XY).CoordsIter(),
+		},
+		{
+			name: "no_layout",
+			it:   NewPoint(NoLayout).CoordsIter(),
+		},
+		{
+			name: "linestring",
+			it:   NewLineStringFlat(XYZ, []float64{1, 2, 3, 4, 5, 6}).CoordsIter(),
+			want: []Coord{{1, 2, 3}, {4, 5, 6}},
+		},
+		{
+			name: "multipolygon",
+			it:   NewMultiPolygonFlat(XY, []float64{0, 0, 1, 0, 0, 1, 0, 0, 5, 5, 6, 5, 5, 6, 5, 5}, [][]int{{8}, {}, {16}}).CoordsIter(),
+			want: []Coord{{0, 0}, {1, 0}, {0, 1}, {0, 0}, {5, 5}, {6, 5}, {5, 6}, {5, 5}},
+		},
+		{
+			name: "geometrycollection",
+			it: NewGeometryCollection().MustPush(
+				NewPointFlat(XY, []float64{1, 2}),
+				NewGeometryCollection(),
+				NewGeometryCollection().MustPush(
+					NewPointEmpty(XY),
+					NewLineStringFlat(XYM, []float64{3, 4, 5, 6, 7, 8}),
+				),
+				NewMultiPointFlat(XY, []float64{9, 10}),
+			).CoordsIter(),
+			want: []Coord{{1, 2}, {3, 4, 5}, {6, 7, 8}, {9, 10}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := collectCoords(&tc.it)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+			if tc.it.Next() {
+				t.Errorf("Next() == true after end")
+			}
+		})
+	}
+}
+
+func TestCoordsIterAliases(t *testing.T) {
+	g := NewLineStringFlat(XY, []float64{1, 2, 3, 4})
+	it := g.CoordsIter()
+	it.Next()
+	c := it.Coord()
+	c[0] = 0
+	if g.FlatCoords()[0] != 0 {
+		t.Errorf("Coord() does not alias flat coordinates")
+	}
+	if cap(c) != 2 {
+		t.Errorf("cap(Coord()) == %d, want 2", cap(c))
+	}
+}
+
+func TestEndsIter(t *testing.T) {
+	g := NewPolygonFlat(XY, []float64{0, 0, 4, 0, 0, 4, 0, 0, 1, 1, 2, 1, 1, 2, 1, 1}, []int{8, 16})
+	want := g.Coords()
+	var got [][]Coord
+	it := g.EndsIter()
+	for it.Next() {
+		if it.Index() != len(got) || it.NumCoords() != len(want[it.Index()]) || it.End()-it.Offset() != len(it.FlatCoords()) {
+			t.Errorf("ring %d: Index() == %d, NumCoords() == %d, Offset() == %d, End() == %d", len(got), it.Index(), it.NumCoords(), it.Offset(), it.End())
+		}
+		coordsIt := it.CoordsIter()
+		got = append(got, collectCoords(&coordsIt))
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestEndssIter(t *testing.T) {
+	g := NewMultiPolygonFlat(XY, []float64{0, 0, 1, 0, 0, 1, 0, 0, 5, 5, 6, 5, 5, 6, 5, 5}, [][]int{{8}, {}, {16}})
+	want := [][][]Coord{
+		{{{0, 0}, {1, 0}, {0, 1}, {0, 0}}},
+		{},
+		{{{5, 5}, {6, 5}, {5, 6}, {5, 5}}},
+	}
+	var got [][][]Coord
+	it := g.EndssIter()
+	for it.Next() {
+		if it.Index() != len(got) || !reflect.DeepEqual(it.Ends(), g.Endss()[it.Index()]) {
+			t.Errorf("polygon %d: Index() == %d, Ends() == %v", len(got), it.Index(), it.Ends())
+		}
+		polygon := [][]Coord{}
+		ringsIt := it.EndsIter()
+		for ringsIt.Next() {
+			coordsIt := ringsIt.CoordsIter()
+			polygon = append(polygon, collectCoords(&coordsIt))
+		}
+		got = append(got, polygon)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func BenchmarkCoordsIter(b *testing.B) {
+	g := NewLineStringFlat(XY, make([]float64, 2048))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		it := g.CoordsIter()
+		for it.Next() {
+			_ = it.Coord()
+		}
+	}
+}
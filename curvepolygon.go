@@ -0,0 +1,169 @@
+package geom
+
+// A CurvePolygon represents a polygon whose rings are curves. The first ring
+// is the outer boundary and subsequent rings are inner boundaries (holes).
+// Each ring is a closed LineString, CircularString, or CompoundCurve, stored
+// as the segments of a CompoundCurve.
+type CurvePolygon struct {
+	geom3
+	circular [][]bool // whether each segment of each ring is a CircularString
+}
+
+// NewCurvePolygon returns a new, empty, CurvePolygon with layout l.
+func NewCurvePolygon(l Layout) *CurvePolygon {
+	g := new(CurvePolygon)
+	g.layout = l
+	g.stride = l.Stride()
+	return g
+}
+
+// Area returns the area of g, computed from its linearization with
+// DefaultSegmentsPerQuadrant segments per quarter circle.
+func (g *CurvePolygon) Area() float64 {
+	return g.Linearize(DefaultSegmentsPerQuadrant).Area()
+}
+
+// Bounds returns the bounds of g, including the parts of its arcs that
+// extend beyond its control points.
+func (g *CurvePolygon) Bounds() *Bounds {
+	b := NewBounds(g.layout)
+	offset := 0
+	for i, ends := range g.endss {
+		extendSegments(b, g.flatCoords, offset, ends, g.circular[i], g.stride)
+		offset = ends[len(ends)-1]
+	}
+	return b
+}
+
+// Clone returns a copy of g that does not alias g.
+func (g *CurvePolygon) Clone() *CurvePolygon {
+	g2 := NewCurvePolygon(g.layout).SetSRID(g.srid)
+	g2.flatCoords = append([]float64(nil), g.flatCoords...)
+	if g.endss != nil {
+		g2.endss = make([][]int, len(g.endss))
+		g2.circular = make([][]bool, len(g.circular))
+		for i := range g.endss {
+			g2.endss[i] = append([]int(nil), g.endss[i]...)
+			g2.circular[i] = append([]bool(nil), g.circular[i]...)
+		}
+	}
+	return g2
+}
+
+// Empty returns true if the geometry has no coordinate.
+func (g *CurvePolygon) Empty() bool {
+	return len(g.FlatCoords()) == 0
+}
+
+// Length returns the perimeter of g.
+func (g *CurvePolygon) Length() float64 {
+	var length float64
+	offset := 0
+	for i, ends := range g.endss {
+		length += segmentsLength(g.flatCoords, offset, ends, g.circular[i], g.stride)
+		offset = ends[len(ends)-1]
+	}
+	return length
+}
+
+// Linearize returns a Polygon approximating g with segmentsPerQuadrant line
+// segments per quarter circle of its arcs, for example
+// DefaultSegmentsPerQuadrant.
+func (g *CurvePolygon) Linearize(segmentsPerQuadrant int) *Polygon {
+	var flatCoords []float64
+	ends := make([]int, 0, len(g.endss))
+	offset := 0
+	for i, ringEnds := range g.endss {
+		flatCoords = appendLinearizedSegments(flatCoords, g.flatCoords, offset, ringEnds, g.circular[i], g.stride, segmentsPerQuadrant)
+		ends = append(ends, len(flatCoords))
+		offset = ringEnds[len(ringEnds)-1]
+	}
+	return NewPolygonFlat(g.layout, flatCoords, ends).SetSRID(g.srid)
+}
+
+// MustPush pushes rings to g. It panics on any error.
+func (g *CurvePolygon) MustPush(rings ...T) *CurvePolygon {
+	if err := g.Push(rings...); err != nil {
+		panic(err)
+	}
+	return g
+}
+
+// NumRings returns the number of rings in g.
+func (g *CurvePolygon) NumRings() int {
+	return len(g.endss)
+}
+
+// Push appends rings, which must be closed LinearRings, LineStrings,
+// CircularStrings, or CompoundCurves, to g.
+func (g *CurvePolygon) Push(rings ...T) error {
+	for _, ring := range rings {
+		cc := NewCompoundCurve(g.layout)
+		switch r := ring.(type) {
+		case *LinearRing:
+			if err := cc.Push(NewLineStringFlat(r.layout, r.flatCoords)); err != nil {
+				return err
+			}
+		case *LineString, *CircularString:
+			if err := cc.Push(r); err != nil {
+				return err
+			}
+		case *CompoundCurve:
+			if r.layout != g.layout {
+				return ErrLayoutMismatch{Got: r.layout, Want: g.layout}
+			}
+			cc = r
+		default:
+			return ErrUnsupportedType{Value: ring}
+		}
+		path := Path{len(g.endss)}
+		switch n := len(cc.flatCoords); {
+		case n == 0:
+			return ErrInvalidRing{Path: path, Reason: "empty"}
+		case !equalCoords(cc.flatCoords[:g.stride], cc.flatCoords[n-g.stride:]):
+			return ErrInvalidRing{Path: path, Reason: "not closed"}
+		}
+		offset := len(g.flatCoords)
+		g.flatCoords = append(g.flatCoords, cc.flatCoords...)
+		ends := make([]int, len(cc.ends))
+		for i, end := range cc.ends {
+			ends[i] = offset + end
+		}
+		g.endss = append(g.endss, ends)
+		g.circular = append(g.circular, append([]bool(nil), cc.circular...))
+	}
+	return nil
+}
+
+// Ring returns the ith ring of g, which is a LineString or CircularString if
+// it has a single segment and a CompoundCurve otherwise, and aliases g.
+func (g *CurvePolygon) Ring(i int) T {
+	offset := 0
+	if i > 0 {
+		offset = g.endss[i-1][len(g.endss[i-1])-1]
+	}
+	ringEnds := g.endss[i]
+	flatCoords := g.flatCoords[offset:ringEnds[len(ringEnds)-1]]
+	if len(ringEnds) == 1 {
+		return newSegment(g.layout, flatCoords, g.circular[i][0], g.srid)
+	}
+	cc := NewCompoundCurve(g.layout).SetSRID(g.srid)
+	cc.flatCoords = flatCoords
+	cc.ends = make([]int, len(ringEnds))
+	for j, end := range ringEnds {
+		cc.ends[j] = end - offset
+	}
+	cc.circular = g.circular[i]
+	return cc
+}
+
+// SetSRID sets the SRID of g.
+func (g *CurvePolygon) SetSRID(srid int) *CurvePolygon {
+	g.srid = srid
+	return g
+}
+
+// Swap swaps the values of g and g2.
+func (g *CurvePolygon) Swap(g2 *CurvePolygon) {
+	*g, *g2 = *g2, *g
+}
@@ -0,0 +1,88 @@
+package geom
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestCurvePolygon(t *testing.T) {
+	shell := NewCompoundCurve(XY).MustPush(
+		NewCircularStringFlat(XY, []float64{2, 0, 0, 2, -2, 0}),
+		NewLineStringFlat(XY, []float64{-2, 0, 2, 0}),
+	)
+	hole := NewCircularStringFlat(XY, []float64{-0.5, 1, 0.5, 1, -0.5, 1})
+	cp := NewCurvePolygon(XY).MustPush(shell, hole).SetSRID(4326)
+	if got := cp.NumRings(); got != 2 {
+		t.Errorf("NumRings() == %d, want 2", got)
+	}
+	if got, want := cp.Ring(0), shell.Clone().SetSRID(4326); !reflect.DeepEqual(got, want) {
+		t.Errorf("Ring(0) == %v, want %v", got, want)
+	}
+	if got, want := cp.Ring(1), hole.Clone().SetSRID(4326); !reflect.DeepEqual(got, want) {
+		t.Errorf("Ring(1) == %v, want %v", got, want)
+	}
+	if err := cp.verify(); err != nil {
+		t.Errorf("verify() == %v, want <nil>", err)
+	}
+	if got, want := cp.Bounds(), NewBounds(XY).Set(-2, 0, 2, 2); !reflect.DeepEqual(got, want) {
+		t.Errorf("Bounds() == %v, want %v", got, want)
+	}
+	if got, want := cp.Length(), 2*math.Pi+4+math.Pi; math.Abs(got-want) > 1e-12 {
+		t.Errorf("Length() == %v, want %v", got, want)
+	}
+	if got, want := cp.Area(), 2*math.Pi-math.Pi/4; math.Abs(got-want) > 1e-2 {
+		t.Errorf("Area() == %v, want %v", got, want)
+	}
+	p := cp.Linearize(DefaultSegmentsPerQuadrant)
+	if p.NumLinearRings() != 2 || p.SRID() != 4326 {
+		t.Errorf("Linearize(...) == %v, want a Polygon with two rings and SRID 4326", p)
+	}
+	clone := cp.Clone()
+	if !reflect.DeepEqual(clone, cp) {
+		t.Errorf("Clone() == %v, want %v", clone, cp)
+	}
+	clone.FlatCoords()[0] = 1
+	if cp.FlatCoords()[0] != 2 {
+		t.Error("Clone() aliases the original")
+	}
+}
+
+func TestCurvePolygonPushErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		ring T
+		want error
+	}{
+		{
+			name: "not_closed",
+			ring: NewLineStringFlat(XY, []float64{0, 0, 1, 0, 1, 1}),
+			want: ErrInvalidRing{Path: Path{0}, Reason: "not closed"},
+		},
+		{
+			name: "empty",
+			ring: NewCompoundCurve(XY),
+			want: ErrInvalidRing{Path: Path{0}, Reason: "empty"},
+		},
+		{
+			name: "layout_mismatch",
+			ring: NewCompoundCurve(XYM),
+			want: ErrLayoutMismatch{Got: XYM, Want: XY},
+		},
+		{
+			name: "unsupported_type",
+			ring: NewPolygon(XY),
+			want: ErrUnsupportedType{Value: NewPolygon(XY)},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := NewCurvePolygon(XY).Push(tc.ring); !reflect.DeepEqual(err, tc.want) {
+				t.Errorf("Push(%v) == %v, want %v", tc.ring, err, tc.want)
+			}
+		})
+	}
+	cp := NewCurvePolygon(XY).MustPush(NewLinearRingFlat(XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}))
+	if got, want := cp.Ring(0), NewLineStringFlat(XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Ring(0) == %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,282 @@
+// Package cbor implements CBOR encoding and decoding of geometries.
+//
+// Geometries are encoded as CBOR byte strings containing their EWKB encoding,
+// optionally enclosed in a CBOR tag, so that they can be embedded in
+// CBOR-based payloads. See https://www.rfc-editor.org/rfc/rfc8949.
+package cbor
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/ewkb"
+)
+
+// CBOR major types.
+const (
+	majorTypeByteString = 2
+	majorTypeTag        = 6
+	majorTypeSimple     = 7
+)
+
+// Additional information values.
+const (
+	additionalUint8      = 24
+	additionalUint16     = 25
+	additionalUint32     = 26
+	additionalUint64     = 27
+	additionalIndefinite = 31
+)
+
+const breakCode = majorTypeSimple<<5 | additionalIndefinite
+
+var errTrailingData = errors.New("cbor: trailing data")
+
+// An ErrUnexpectedMajorType is returned when a data item of an unexpected
+// major type is encountered.
+type ErrUnexpectedMajorType byte
+
+func (e ErrUnexpectedMajorType) Error() string {
+	return fmt.Sprintf("cbor: unexpected major type: %d", byte(e))
+}
+
+// An ErrUnexpectedTag is returned when a tag other than the configured tag is
+// encountered.
+type ErrUnexpectedTag uint64
+
+func (e ErrUnexpectedTag) Error() string {
+	return fmt.Sprintf("cbor: unexpected tag: %d", uint64(e))
+}
+
+// An ErrInvalidAdditionalInfo is returned when the additional information of
+// a data item is reserved or invalid for its major type.
+type ErrInvalidAdditionalInfo byte
+
+func (e ErrInvalidAdditionalInfo) Error() string {
+	return fmt.Sprintf("cbor: invalid additional information: %d", byte(e))
+}
+
+// An Option configures encoding or decoding. Options that do not apply to an
+// operation are ignored.
+type Option func(*options)
+
+type options struct {
+	ewkbOptions []ewkb.Option
+	tag         uint64
+	tagged      bool
+}
+
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithEWKBOptions sets the options used to encode and decode the enclosed
+// EWKB, for example ewkb.WithSRIDPolicy.
+func WithEWKBOptions(opts ...ewkb.Option) Option {
+	return func(o *options) {
+		o.ewkbOptions = opts
+	}
+}
+
+// WithTag sets the CBOR tag that encloses encoded geometries. When decoding,
+// geometries enclosed in tag and geometries without a tag are accepted, and
+// other tags are rejected. By default, geometries are not tagged and tagged
+// geometries are rejected.
+func WithTag(tag uint64) Option {
+	return func(o *options) {
+		o.tag = tag
+		o.tagged = true
+	}
+}
+
+// Append appends the CBOR encoding of g to dst and returns the extended
+// buffer. The EWKB is little endian.
+func Append(dst []byte, g geom.T, opts ...Option) ([]byte, error) {
+	o := newOptions(opts)
+	data, err := ewkb.Marshal(g, ewkb.NDR, o.ewkbOptions...)
+	if err != nil {
+		return nil, err
+	}
+	if o.tagged {
+		dst = appendHead(dst, majorTypeTag, o.tag)
+	}
+	dst = appendHead(dst, majorTypeByteString, uint64(len(data)))
+	return append(dst, data...), nil
+}
+
+// Marshal returns the CBOR encoding of g.
+func Marshal(g geom.T, opts ...Option) ([]byte, error) {
+	return Append(nil, g, opts...)
+}
+
+// Unmarshal decodes a geometry from the CBOR data item data.
+func Unmarshal(data []byte, opts ...Option) (geom.T, error) {
+	o := newOptions(opts)
+	d := decoder{data: data}
+	ewkbData, err := d.geometry(o)
+	if err != nil {
+		return nil, err
+	}
+	if d.pos != len(d.data) {
+		return nil, errTrailingData
+	}
+	return ewkb.Unmarshal(ewkbData, o.ewkbOptions...)
+}
+
+// UnmarshalStub returns the Stub of the geometry in the CBOR data item data.
+func UnmarshalStub(data []byte, opts ...Option) (*geom.Stub, error) {
+	g, err := Unmarshal(data, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return geom.NewStub(g)
+}
+
+// appendHead appends the head of a data item with the given major type and
+// argument, using the shortest encoding of the argument.
+func appendHead(dst []byte, majorType byte, n uint64) []byte {
+	switch {
+	case n < additionalUint8:
+		return append(dst, majorType<<5|byte(n))
+	case n <= 0xff:
+		return append(dst, majorType<<5|additionalUint8, byte(n))
+	case n <= 0xffff:
+		dst = append(dst, majorType<<5|additionalUint16, 0, 0)
+		binary.BigEndian.PutUint16(dst[len(dst)-2:], uint16(n))
+		return dst
+	case n <= 0xffffffff:
+		dst = append(dst, majorType<<5|additionalUint32, 0, 0, 0, 0)
+		binary.BigEndian.PutUint32(dst[len(dst)-4:], uint32(n))
+		return dst
+	default:
+		dst = append(dst, majorType<<5|additionalUint64, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(dst[len(dst)-8:], n)
+		return dst
+	}
+}
+
+type decoder struct {
+	data []byte
+	pos  int
+}
+
+// head decodes the head of a data item. indefinite is true if the data item
+// has an indefinite length.
+func (d *decoder) head() (majorType byte, n uint64, indefinite bool, err error) {
+	if d.pos >= len(d.data) {
+		return 0, 0, false, io.ErrUnexpectedEOF
+	}
+	b := d.data[d.pos]
+	d.pos++
+	majorType, info := b>>5, b&0x1f
+	var size int
+	switch {
+	case info < additionalUint8:
+		return majorType, uint64(info), false, nil
+	case info == additionalUint8:
+		size = 1
+	case info == additionalUint16:
+		size = 2
+	case info == additionalUint32:
+		size = 4
+	case info == additionalUint64:
+		size = 8
+	case info == additionalIndefinite:
+		return majorType, 0, true, nil
+	default:
+		return 0, 0, false, ErrInvalidAdditionalInfo(info)
+	}
+	if len(d.data)-d.pos < size {
+		return 0, 0, false, io.ErrUnexpectedEOF
+	}
+	for _, c := range d.data[d.pos : d.pos+size] {
+		n = n<<8 | uint64(c)
+	}
+	d.pos += size
+	return majorType, n, false, nil
+}
+
+// byteString decodes the contents of a definite length byte string of length
+// n.
+func (d *decoder) byteString(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.pos) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	data := d.data[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+	return data, nil
+}
+
+// geometry decodes an optionally tagged byte string and returns its
+// contents.
+func (d *decoder) geometry(o options) ([]byte, error) {
+	majorType, n, indefinite, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+	if majorType == majorTypeTag && !indefinite {
+		if !o.tagged || n != o.tag {
+			return nil, ErrUnexpectedTag(n)
+		}
+		if majorType, n, indefinite, err = d.head(); err != nil {
+			return nil, err
+		}
+	}
+	if majorType != majorTypeByteString {
+		return nil, ErrUnexpectedMajorType(majorType)
+	}
+	if !indefinite {
+		return d.byteString(n)
+	}
+	// An indefinite length byte string is a sequence of definite length
+	// byte strings terminated by a break.
+	var data []byte
+	for {
+		if d.pos < len(d.data) && d.data[d.pos] == breakCode {
+			d.pos++
+			return data, nil
+		}
+		majorType, n, indefinite, err := d.head()
+		switch {
+		case err != nil:
+			return nil, err
+		case majorType != majorTypeByteString:
+			return nil, ErrUnexpectedMajorType(majorType)
+		case indefinite:
+			return nil, ErrInvalidAdditionalInfo(additionalIndefinite)
+		}
+		chunk, err := d.byteString(n)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, chunk...)
+	}
+}
+
+// A Geometry is a geom.T that implements the MarshalCBOR and UnmarshalCBOR
+// methods used by third-party CBOR libraries.
+type Geometry struct {
+	geom.T
+}
+
+// MarshalCBOR returns the untagged CBOR encoding of g.
+func (g Geometry) MarshalCBOR() ([]byte, error) {
+	return Marshal(g.T)
+}
+
+// UnmarshalCBOR decodes an untagged geometry from data.
+func (g *Geometry) UnmarshalCBOR(data []byte) error {
+	t, err := Unmarshal(data)
+	if err != nil {
+		return err
+	}
+	g.T = t
+	return nil
+}
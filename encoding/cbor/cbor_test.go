@@ -0,0 +1,190 @@
+package cbor
+
+import (
+	"encoding/hex"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/ewkb"
+)
+
+const pointEWKB = "0101000000000000000000f03f0000000000000040"
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+	data, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestMarshalAndUnmarshal(t *testing.T) {
+	point := geom.NewPointFlat(geom.XY, []float64{1, 2})
+	for _, tc := range []struct {
+		name    string
+		g       geom.T
+		opts    []Option
+		want    string
+		decoded geom.T
+	}{
+		{
+			name: "point",
+			g:    point,
+			want: "55" + pointEWKB,
+		},
+		{
+			name: "tag_uint8",
+			g:    point,
+			opts: []Option{WithTag(103)},
+			want: "d86755" + pointEWKB,
+		},
+		{
+			name: "tag_uint32",
+			g:    point,
+			opts: []Option{WithTag(0x10000)},
+			want: "da0001000055" + pointEWKB,
+		},
+		{
+			name: "srid",
+			g:    geom.NewPointFlat(geom.XY, []float64{1, 2}).SetSRID(4326),
+			want: "5819" + "0101000020e6100000000000000000f03f0000000000000040",
+		},
+		{
+			name:    "srid_never",
+			g:       geom.NewPointFlat(geom.XY, []float64{1, 2}).SetSRID(4326),
+			opts:    []Option{WithEWKBOptions(ewkb.WithSRIDPolicy(ewkb.SRIDNever))},
+			want:    "55" + pointEWKB,
+			decoded: point,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Marshal(tc.g, tc.opts...)
+			if err != nil || hex.EncodeToString(got) != tc.want {
+				t.Errorf("Marshal(%v, ...) == %s, %v, want %s, <nil>", tc.g, hex.EncodeToString(got), err, tc.want)
+			}
+			decoded := tc.decoded
+			if decoded == nil {
+				decoded = tc.g
+			}
+			if g, err := Unmarshal(got, tc.opts...); err != nil || !reflect.DeepEqual(g, decoded) {
+				t.Errorf("Unmarshal(%s, ...) == %v, %v, want %v, <nil>", hex.EncodeToString(got), g, err, decoded)
+			}
+		})
+	}
+}
+
+func TestLongByteStrings(t *testing.T) {
+	for _, n := range []int{16, 4096} {
+		g := geom.NewLineStringFlat(geom.XY, make([]float64, 2*n))
+		data, err := Marshal(g)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ewkbData, err := ewkb.Marshal(g, ewkb.NDR)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := len(data)-len(ewkbData), map[int]int{16: 3, 4096: 5}[n]; got != want {
+			t.Errorf("n=%d: head length %d, want %d", n, got, want)
+		}
+		if got, err := Unmarshal(data); err != nil || !reflect.DeepEqual(got, g) {
+			t.Errorf("n=%d: Unmarshal(...) == %v, %v, want %v, <nil>", n, got, err, g)
+		}
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	point := geom.NewPointFlat(geom.XY, []float64{1, 2})
+	for _, tc := range []struct {
+		name    string
+		s       string
+		opts    []Option
+		want    geom.T
+		wantErr error
+	}{
+		{
+			name: "untagged_with_tag",
+			s:    "55" + pointEWKB,
+			opts: []Option{WithTag(103)},
+			want: point,
+		},
+		{
+			name: "indefinite",
+			s:    "5f45" + pointEWKB[:10] + "50" + pointEWKB[10:] + "40ff",
+			want: point,
+		},
+		{
+			name:    "unexpected_tag",
+			s:       "d86755" + pointEWKB,
+			wantErr: ErrUnexpectedTag(103),
+		},
+		{
+			name:    "other_tag",
+			s:       "d86855" + pointEWKB,
+			opts:    []Option{WithTag(103)},
+			wantErr: ErrUnexpectedTag(104),
+		},
+		{
+			name:    "text_string",
+			s:       "6161",
+			wantErr: ErrUnexpectedMajorType(3),
+		},
+		{
+			name:    "reserved",
+			s:       "5c",
+			wantErr: ErrInvalidAdditionalInfo(28),
+		},
+		{
+			name:    "nested_indefinite",
+			s:       "5f5fffff",
+			wantErr: ErrInvalidAdditionalInfo(additionalIndefinite),
+		},
+		{
+			name:    "indefinite_text_chunk",
+			s:       "5f6161ff",
+			wantErr: ErrUnexpectedMajorType(3),
+		},
+		{
+			name:    "truncated_head",
+			s:       "59ff",
+			wantErr: io.ErrUnexpectedEOF,
+		},
+		{
+			name:    "truncated_contents",
+			s:       "56" + pointEWKB,
+			wantErr: io.ErrUnexpectedEOF,
+		},
+		{
+			name:    "unterminated",
+			s:       "5f55" + pointEWKB,
+			wantErr: io.ErrUnexpectedEOF,
+		},
+		{
+			name:    "trailing_data",
+			s:       "55" + pointEWKB + "00",
+			wantErr: errTrailingData,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Unmarshal(mustDecodeHex(t, tc.s), tc.opts...)
+			if err != tc.wantErr || !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Unmarshal(%s, ...) == %v, %v, want %v, %v", tc.s, got, err, tc.want, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestGeometry(t *testing.T) {
+	want := Geometry{geom.NewPolygonFlat(geom.XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}, []int{8}).SetSRID(4326)}
+	data, err := want.MarshalCBOR()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Geometry
+	if err := got.UnmarshalCBOR(data); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("UnmarshalCBOR(%s) == %v, got %v, want %v", hex.EncodeToString(data), err, got, want)
+	}
+}
@@ -0,0 +1,188 @@
+// Package conformance provides a corpus of tricky geometries and helpers for
+// checking that geometry encodings are stable, so that the codecs in this
+// module and third-party codecs share one correctness bar.
+//
+// A codec is stable for a geometry if encoding the geometry, decoding the
+// result, and encoding the decoded geometry again gives the same bytes.
+// Stability does not require that codecs are lossless: a codec that drops M
+// values or rounds ordinates is stable as long as it does so consistently.
+package conformance
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+)
+
+// A Codec is an encoding of geometries.
+type Codec interface {
+	Marshal(g geom.T) ([]byte, error)
+	Unmarshal(data []byte) (geom.T, error)
+}
+
+// CodecFuncs is a Codec implemented by a pair of functions.
+type CodecFuncs struct {
+	MarshalFunc   func(g geom.T) ([]byte, error)
+	UnmarshalFunc func(data []byte) (geom.T, error)
+}
+
+// Marshal calls c.MarshalFunc.
+func (c CodecFuncs) Marshal(g geom.T) ([]byte, error) {
+	return c.MarshalFunc(g)
+}
+
+// Unmarshal calls c.UnmarshalFunc.
+func (c CodecFuncs) Unmarshal(data []byte) (geom.T, error) {
+	return c.UnmarshalFunc(data)
+}
+
+// An ErrUnstable is returned when a geometry's encoding changes after a
+// round trip.
+type ErrUnstable struct {
+	First  []byte // first encoding
+	Second []byte // encoding of the decoded first encoding
+}
+
+func (e ErrUnstable) Error() string {
+	return fmt.Sprintf("conformance: unstable encoding, first %q, second %q", e.First, e.Second)
+}
+
+// An ErrPanic is returned when a codec panics.
+type ErrPanic struct {
+	Op    string // "marshal" or "unmarshal"
+	Value interface{}
+}
+
+func (e ErrPanic) Error() string {
+	return fmt.Sprintf("conformance: %s panicked: %v", e.Op, e.Value)
+}
+
+// An ErrRoundTrip is returned when a codec fails to decode its own encoding
+// or to encode the result.
+type ErrRoundTrip struct {
+	Op  string // "marshal" or "unmarshal"
+	Err error
+}
+
+func (e ErrRoundTrip) Error() string {
+	return fmt.Sprintf("conformance: %s: %v", e.Op, e.Err)
+}
+
+// Unwrap returns e.Err.
+func (e ErrRoundTrip) Unwrap() error {
+	return e.Err
+}
+
+// Check checks that c's encoding of g is stable. It returns the first
+// encoding of g and, if the encoding is not stable, an ErrUnstable,
+// ErrRoundTrip, or ErrPanic. If c cannot encode g then Check returns c's
+// error unwrapped, so that callers can distinguish unsupported geometries
+// from failures.
+func Check(c Codec, g geom.T) ([]byte, error) {
+	first, err := marshal(c, g)
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := unmarshal(c, first)
+	if err != nil {
+		return first, roundTripError("unmarshal", err)
+	}
+	second, err := marshal(c, decoded)
+	if err != nil {
+		return first, roundTripError("marshal", err)
+	}
+	if !bytes.Equal(first, second) {
+		return first, ErrUnstable{
+			First:  first,
+			Second: second,
+		}
+	}
+	return first, nil
+}
+
+// roundTripError returns err as an ErrRoundTrip unless it is an ErrPanic.
+func roundTripError(op string, err error) error {
+	if _, ok := err.(ErrPanic); ok {
+		return err
+	}
+	return ErrRoundTrip{
+		Op:  op,
+		Err: err,
+	}
+}
+
+// marshal calls c.Marshal, converting panics into ErrPanics.
+func marshal(c Codec, g geom.T) (data []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = ErrPanic{Op: "marshal", Value: r}
+		}
+	}()
+	return c.Marshal(g)
+}
+
+// unmarshal calls c.Unmarshal, converting panics into ErrPanics.
+func unmarshal(c Codec, data []byte) (g geom.T, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = ErrPanic{Op: "unmarshal", Value: r}
+		}
+	}()
+	return c.Unmarshal(data)
+}
+
+// An Option sets an option for Run.
+type Option func(*options)
+
+type options struct {
+	skip          map[string]string
+	requireEncode bool
+}
+
+// WithSkip skips the case called name, for example because of a known bug,
+// giving reason.
+func WithSkip(name, reason string) Option {
+	return func(o *options) {
+		o.skip[name] = reason
+	}
+}
+
+// WithRequireEncode sets whether cases that the codec cannot encode fail. By
+// default they are skipped, as most codecs do not support every geometry
+// type and layout.
+func WithRequireEncode(requireEncode bool) Option {
+	return func(o *options) {
+		o.requireEncode = requireEncode
+	}
+}
+
+// Run checks c against each case in Corpus in a subtest of t.
+func Run(t *testing.T, c Codec, opts ...Option) {
+	t.Helper()
+	o := options{
+		skip: make(map[string]string),
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	for _, tc := range Corpus() {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			if reason, ok := o.skip[tc.Name]; ok {
+				t.Skip(reason)
+			}
+			switch _, err := Check(c, tc.Geom); err.(type) {
+			case nil:
+			case ErrUnstable, ErrRoundTrip, ErrPanic:
+				t.Error(err)
+			default:
+				if !o.requireEncode {
+					t.Skipf("cannot encode: %v", err)
+				}
+				t.Error(err)
+			}
+		})
+	}
+}
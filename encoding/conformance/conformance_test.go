@@ -0,0 +1,228 @@
+package conformance
+
+import (
+	"encoding/binary"
+	"encoding/xml"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/cbor"
+	"github.com/twpayne/go-geom/encoding/ewkb"
+	"github.com/twpayne/go-geom/encoding/geojson"
+	"github.com/twpayne/go-geom/encoding/gml"
+	"github.com/twpayne/go-geom/encoding/kml"
+	"github.com/twpayne/go-geom/encoding/twkb"
+	"github.com/twpayne/go-geom/encoding/wkb"
+	"github.com/twpayne/go-geom/encoding/wkt"
+)
+
+func TestCodecs(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		codec Codec
+		opts  []Option
+	}{
+		{
+			name: "wkb",
+			opts: []Option{
+				WithSkip("point_empty", "empty points are encoded without coordinates"),
+				WithSkip("point_empty_xyzm", "empty points are encoded without coordinates"),
+			},
+			codec: CodecFuncs{
+				MarshalFunc: func(g geom.T) ([]byte, error) {
+					return wkb.Marshal(g, binary.LittleEndian)
+				},
+				UnmarshalFunc: func(data []byte) (geom.T, error) {
+					return wkb.Unmarshal(data)
+				},
+			},
+		},
+		{
+			name: "ewkb",
+			opts: []Option{
+				WithSkip("point_empty", "empty points are encoded without coordinates"),
+				WithSkip("point_empty_xyzm", "empty points are encoded without coordinates"),
+			},
+			codec: CodecFuncs{
+				MarshalFunc: func(g geom.T) ([]byte, error) {
+					return ewkb.Marshal(g, binary.BigEndian)
+				},
+				UnmarshalFunc: func(data []byte) (geom.T, error) {
+					return ewkb.Unmarshal(data)
+				},
+			},
+		},
+		{
+			name: "twkb",
+			codec: CodecFuncs{
+				MarshalFunc: func(g geom.T) ([]byte, error) {
+					return twkb.Marshal(g, twkb.WithPrecision(6))
+				},
+				UnmarshalFunc: twkb.Unmarshal,
+			},
+		},
+		{
+			name: "wkt",
+			opts: []Option{
+				WithSkip("multi_line_string_empty_member", "empty members are encoded as ()"),
+			},
+			codec: CodecFuncs{
+				MarshalFunc: func(g geom.T) ([]byte, error) {
+					s, err := wkt.Marshal(g)
+					return []byte(s), err
+				},
+				UnmarshalFunc: func(data []byte) (geom.T, error) {
+					return wkt.Unmarshal(string(data))
+				},
+			},
+		},
+		{
+			name: "geojson",
+			opts: []Option{
+				WithSkip("point_empty", "encoding empty points panics"),
+				WithSkip("point_empty_xyzm", "encoding empty points panics"),
+			},
+			codec: CodecFuncs{
+				MarshalFunc: func(g geom.T) ([]byte, error) {
+					return geojson.Marshal(g)
+				},
+				UnmarshalFunc: func(data []byte) (geom.T, error) {
+					var g geom.T
+					err := geojson.Unmarshal(data, &g)
+					return g, err
+				},
+			},
+		},
+		{
+			name: "cbor",
+			opts: []Option{
+				WithSkip("point_empty", "empty points are encoded without coordinates"),
+				WithSkip("point_empty_xyzm", "empty points are encoded without coordinates"),
+			},
+			codec: CodecFuncs{
+				MarshalFunc: func(g geom.T) ([]byte, error) {
+					return cbor.Marshal(g)
+				},
+				UnmarshalFunc: func(data []byte) (geom.T, error) {
+					return cbor.Unmarshal(data)
+				},
+			},
+		},
+		{
+			name: "gml",
+			codec: CodecFuncs{
+				MarshalFunc: func(g geom.T) ([]byte, error) {
+					return gml.Marshal(g)
+				},
+				UnmarshalFunc: func(data []byte) (geom.T, error) {
+					return gml.Unmarshal(data)
+				},
+			},
+		},
+		{
+			name: "kml",
+			codec: CodecFuncs{
+				MarshalFunc: func(g geom.T) ([]byte, error) {
+					e, err := kml.Encode(g)
+					if err != nil {
+						return nil, err
+					}
+					return xml.Marshal(e)
+				},
+				UnmarshalFunc: func(data []byte) (geom.T, error) {
+					return kml.Unmarshal(data)
+				},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			Run(t, tc.codec, tc.opts...)
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	g := geom.NewPointFlat(geom.XY, []float64{1, 2})
+	for _, tc := range []struct {
+		name  string
+		codec Codec
+		check func(error) bool
+	}{
+		{
+			name: "stable",
+			codec: CodecFuncs{
+				MarshalFunc: func(g geom.T) ([]byte, error) {
+					return wkb.Marshal(g, binary.LittleEndian)
+				},
+				UnmarshalFunc: func(data []byte) (geom.T, error) {
+					return wkb.Unmarshal(data)
+				},
+			},
+			check: func(err error) bool {
+				return err == nil
+			},
+		},
+		{
+			name: "unstable",
+			codec: CodecFuncs{
+				MarshalFunc: func(g geom.T) ([]byte, error) {
+					return wkb.Marshal(g, binary.LittleEndian)
+				},
+				UnmarshalFunc: func(data []byte) (geom.T, error) {
+					return geom.NewPointFlat(geom.XY, []float64{3, 4}), nil
+				},
+			},
+			check: func(err error) bool {
+				_, ok := err.(ErrUnstable)
+				return ok
+			},
+		},
+		{
+			name: "round_trip",
+			codec: CodecFuncs{
+				MarshalFunc: func(g geom.T) ([]byte, error) {
+					return wkb.Marshal(g, binary.LittleEndian)
+				},
+				UnmarshalFunc: func(data []byte) (geom.T, error) {
+					return wkb.Unmarshal(data[1:])
+				},
+			},
+			check: func(err error) bool {
+				e, ok := err.(ErrRoundTrip)
+				return ok && e.Op == "unmarshal"
+			},
+		},
+		{
+			name: "panic",
+			codec: CodecFuncs{
+				MarshalFunc: func(g geom.T) ([]byte, error) {
+					return wkb.Marshal(g, binary.LittleEndian)
+				},
+				UnmarshalFunc: func(data []byte) (geom.T, error) {
+					panic("boom")
+				},
+			},
+			check: func(err error) bool {
+				return err == ErrPanic{Op: "unmarshal", Value: "boom"}
+			},
+		},
+		{
+			name: "unsupported",
+			codec: CodecFuncs{
+				MarshalFunc: func(g geom.T) ([]byte, error) {
+					return nil, geom.ErrUnsupportedType{Value: g}
+				},
+			},
+			check: func(err error) bool {
+				_, ok := err.(geom.ErrUnsupportedType)
+				return ok
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := Check(tc.codec, g); !tc.check(err) {
+				t.Errorf("Check(...) == _, %v", err)
+			}
+		})
+	}
+}
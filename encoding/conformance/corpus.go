@@ -0,0 +1,90 @@
+package conformance
+
+import (
+	"math"
+
+	"github.com/twpayne/go-geom"
+)
+
+// A Case is a named geometry in the corpus.
+type Case struct {
+	Name string
+	Geom geom.T
+}
+
+// hugeRingVertices is the number of vertices in the huge_ring cases.
+const hugeRingVertices = 100000
+
+// Corpus returns the corpus of tricky geometries: empty geometries, Z, M, and
+// ZM variants, geometries with SRIDs, nested collections, geometries that
+// cross the antimeridian, geometries with extreme ordinates, and huge rings.
+// Each call returns new geometries, so callers may modify them.
+func Corpus() []Case {
+	return []Case{
+		{Name: "point", Geom: geom.NewPointFlat(geom.XY, []float64{1, 2})},
+		{Name: "point_xyz", Geom: geom.NewPointFlat(geom.XYZ, []float64{1, 2, 3})},
+		{Name: "point_xym", Geom: geom.NewPointFlat(geom.XYM, []float64{1, 2, 3})},
+		{Name: "point_xyzm", Geom: geom.NewPointFlat(geom.XYZM, []float64{1, 2, 3, 4})},
+		{Name: "point_empty", Geom: geom.NewPointEmpty(geom.XY)},
+		{Name: "point_empty_xyzm", Geom: geom.NewPointEmpty(geom.XYZM)},
+		{Name: "point_srid", Geom: geom.NewPointFlat(geom.XY, []float64{-122.4, 37.8}).SetSRID(4326)},
+		{Name: "point_negative_zero", Geom: geom.NewPointFlat(geom.XY, []float64{math.Copysign(0, -1), 0})},
+		{Name: "point_extreme", Geom: geom.NewPointFlat(geom.XY, []float64{math.MaxFloat64, math.SmallestNonzeroFloat64})},
+		{Name: "point_precision", Geom: geom.NewPointFlat(geom.XY, []float64{0.1 + 0.2, 1.0 / 3})},
+		{Name: "line_string", Geom: geom.NewLineStringFlat(geom.XY, []float64{0, 0, 1, 1, 2, 0})},
+		{Name: "line_string_xyzm", Geom: geom.NewLineStringFlat(geom.XYZM, []float64{0, 0, 1, 10, 1, 1, 2, 20})},
+		{Name: "line_string_empty", Geom: geom.NewLineString(geom.XY)},
+		{Name: "line_string_repeated", Geom: geom.NewLineStringFlat(geom.XY, []float64{1, 1, 1, 1})},
+		{Name: "line_string_antimeridian", Geom: geom.NewLineStringFlat(geom.XY, []float64{179, 10, -179, 11}).SetSRID(4326)},
+		{Name: "polygon", Geom: geom.NewPolygonFlat(geom.XY, []float64{0, 0, 4, 0, 4, 4, 0, 4, 0, 0}, []int{10})},
+		{Name: "polygon_hole", Geom: geom.NewPolygonFlat(geom.XY, []float64{
+			0, 0, 4, 0, 4, 4, 0, 4, 0, 0,
+			1, 1, 1, 2, 2, 2, 2, 1, 1, 1,
+		}, []int{10, 20})},
+		{Name: "polygon_xyz", Geom: geom.NewPolygonFlat(geom.XYZ, []float64{0, 0, 1, 1, 0, 2, 1, 1, 3, 0, 0, 1}, []int{12})},
+		{Name: "polygon_empty", Geom: geom.NewPolygon(geom.XY)},
+		{Name: "polygon_antimeridian", Geom: geom.NewPolygonFlat(geom.XY, []float64{
+			179, -1, -179, -1, -179, 1, 179, 1, 179, -1,
+		}, []int{10}).SetSRID(4326)},
+		{Name: "polygon_huge_ring", Geom: hugeRingPolygon()},
+		{Name: "multi_point", Geom: geom.NewMultiPointFlat(geom.XY, []float64{1, 2, 3, 4})},
+		{Name: "multi_point_xym", Geom: geom.NewMultiPointFlat(geom.XYM, []float64{1, 2, 3, 4, 5, 6})},
+		{Name: "multi_point_empty", Geom: geom.NewMultiPoint(geom.XY)},
+		{Name: "multi_line_string", Geom: geom.NewMultiLineStringFlat(geom.XY, []float64{0, 0, 1, 1, 2, 2, 3, 3}, []int{4, 8})},
+		{Name: "multi_line_string_empty_member", Geom: geom.NewMultiLineStringFlat(geom.XY, []float64{0, 0, 1, 1}, []int{4, 4})},
+		{Name: "multi_line_string_empty", Geom: geom.NewMultiLineString(geom.XY)},
+		{Name: "multi_polygon", Geom: geom.NewMultiPolygonFlat(geom.XY, []float64{
+			0, 0, 1, 0, 1, 1, 0, 0,
+			2, 2, 3, 2, 3, 3, 2, 2,
+		}, [][]int{{8}, {16}})},
+		{Name: "multi_polygon_xyzm", Geom: geom.NewMultiPolygonFlat(geom.XYZM, []float64{
+			0, 0, 1, 2, 1, 0, 1, 2, 1, 1, 1, 2, 0, 0, 1, 2,
+		}, [][]int{{16}})},
+		{Name: "multi_polygon_empty", Geom: geom.NewMultiPolygon(geom.XY)},
+		{Name: "geometry_collection", Geom: geom.NewGeometryCollection().MustPush(
+			geom.NewPointFlat(geom.XY, []float64{1, 2}),
+			geom.NewLineStringFlat(geom.XY, []float64{0, 0, 1, 1}),
+			geom.NewPolygonFlat(geom.XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}, []int{8}),
+		)},
+		{Name: "geometry_collection_empty", Geom: geom.NewGeometryCollection()},
+		{Name: "geometry_collection_nested", Geom: geom.NewGeometryCollection().MustPush(
+			geom.NewPointFlat(geom.XY, []float64{1, 2}),
+			geom.NewGeometryCollection().MustPush(
+				geom.NewMultiPointFlat(geom.XY, []float64{3, 4, 5, 6}),
+				geom.NewGeometryCollection(),
+			),
+		)},
+	}
+}
+
+// hugeRingPolygon returns a Polygon whose exterior ring is an approximate
+// circle with hugeRingVertices vertices.
+func hugeRingPolygon() *geom.Polygon {
+	flatCoords := make([]float64, 0, 2*(hugeRingVertices+1))
+	for i := 0; i < hugeRingVertices; i++ {
+		theta := 2 * math.Pi * float64(i) / hugeRingVertices
+		flatCoords = append(flatCoords, 1000*math.Cos(theta), 1000*math.Sin(theta))
+	}
+	flatCoords = append(flatCoords, flatCoords[0], flatCoords[1])
+	return geom.NewPolygonFlat(geom.XY, flatCoords, []int{len(flatCoords)})
+}
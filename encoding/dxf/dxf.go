@@ -0,0 +1,82 @@
+// Package dxf implements encoding of geometries as entities of AutoCAD DXF
+// files, for interchange with CAD applications. See the AutoCAD DXF
+// Reference.
+//
+// Points are encoded as POINT entities, LineStrings as LWPOLYLINE entities,
+// and Polygons as solid HATCH entities with a boundary path for each ring,
+// optionally with an LWPOLYLINE outline for each ring. Multi geometries and
+// GeometryCollections are encoded as their members. LWPOLYLINEs and HATCHes
+// are two-dimensional, so only the Z values of points are encoded. Empty
+// geometries are skipped.
+//
+// Files are written in ASCII with only a header and an entities section,
+// which is accepted by most applications. Layers are created implicitly
+// from the layer names of entities.
+package dxf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultLayer is the default layer of entities.
+const DefaultLayer = "0"
+
+// version is the AutoCAD version written in the header, AutoCAD 2000, which
+// is the first to support LWPOLYLINE and HATCH entities in DXF.
+const version = "AC1015"
+
+// invalidLayerChars are the characters that are not allowed in layer names.
+const invalidLayerChars = "<>/\\\":;?*|=`"
+
+// An ErrInvalidLayer is returned when a layer name is empty or contains
+// invalid characters.
+type ErrInvalidLayer string
+
+func (e ErrInvalidLayer) Error() string {
+	return fmt.Sprintf("dxf: invalid layer name %q", string(e))
+}
+
+// An Option sets an option for encoding.
+type Option func(*options)
+
+type options struct {
+	layer    string
+	outlines bool
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		layer: DefaultLayer,
+	}
+	return o.apply(opts)
+}
+
+// WithLayer sets the layer of entities. The default is DefaultLayer.
+func WithLayer(layer string) Option {
+	return func(o *options) {
+		o.layer = layer
+	}
+}
+
+// WithOutlines sets whether the rings of Polygons are also encoded as closed
+// LWPOLYLINEs, so that they are visible in applications that do not display
+// hatches.
+func WithOutlines(outlines bool) Option {
+	return func(o *options) {
+		o.outlines = outlines
+	}
+}
+
+// apply returns o with opts applied.
+func (o options) apply(opts []Option) options {
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// validLayer returns whether layer is a valid layer name.
+func validLayer(layer string) bool {
+	return layer != "" && !strings.ContainsAny(layer, invalidLayerChars)
+}
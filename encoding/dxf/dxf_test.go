@@ -0,0 +1,133 @@
+package dxf
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+)
+
+const (
+	testHeader = "  0\nSECTION\n  2\nHEADER\n  9\n$ACADVER\n  1\nAC1015\n  0\nENDSEC\n" +
+		"  0\nSECTION\n  2\nENTITIES\n"
+	testFooter = "  0\nENDSEC\n  0\nEOF\n"
+)
+
+func TestMarshal(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		gs   []geom.T
+		opts []Option
+		want string
+	}{
+		{
+			name: "empty",
+			want: testHeader + testFooter,
+		},
+		{
+			name: "point",
+			gs: []geom.T{
+				geom.NewPointFlat(geom.XYZ, []float64{1, 2.5, 3}),
+				geom.NewPointEmpty(geom.XY),
+			},
+			opts: []Option{WithLayer("points")},
+			want: testHeader +
+				"  0\nPOINT\n100\nAcDbEntity\n  8\npoints\n100\nAcDbPoint\n 10\n1\n 20\n2.5\n 30\n3\n" +
+				testFooter,
+		},
+		{
+			name: "line_string",
+			gs: []geom.T{
+				geom.NewMultiLineStringFlat(geom.XYM, []float64{1, 2, 3, 4, 5, 6}, []int{6}),
+			},
+			want: testHeader +
+				"  0\nLWPOLYLINE\n100\nAcDbEntity\n  8\n0\n100\nAcDbPolyline\n 90\n2\n 70\n0\n" +
+				" 10\n1\n 20\n2\n 10\n4\n 20\n5\n" +
+				testFooter,
+		},
+		{
+			name: "polygon",
+			gs: []geom.T{
+				geom.NewPolygonFlat(geom.XY, []float64{0, 0, 1, 0, 0, 1, 0, 0}, []int{8}),
+			},
+			opts: []Option{WithOutlines(true)},
+			want: testHeader +
+				"  0\nHATCH\n100\nAcDbEntity\n  8\n0\n100\nAcDbHatch\n" +
+				" 10\n0\n 20\n0\n 30\n0\n210\n0\n220\n0\n230\n1\n  2\nSOLID\n 70\n1\n 71\n0\n 91\n1\n" +
+				" 92\n3\n 72\n0\n 73\n1\n 93\n3\n 10\n0\n 20\n0\n 10\n1\n 20\n0\n 10\n0\n 20\n1\n 97\n0\n" +
+				" 75\n0\n 76\n1\n 98\n0\n" +
+				"  0\nLWPOLYLINE\n100\nAcDbEntity\n  8\n0\n100\nAcDbPolyline\n 90\n3\n 70\n1\n" +
+				" 10\n0\n 20\n0\n 10\n1\n 20\n0\n 10\n0\n 20\n1\n" +
+				testFooter,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Marshal(tc.gs, tc.opts...)
+			if err != nil {
+				t.Fatalf("Marshal(...) == _, %v, want _, <nil>", err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("Marshal(...) == %q, _, want %q, _", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestEncoderLayers(t *testing.T) {
+	var b bytes.Buffer
+	e := NewEncoder(&b, WithLayer("default"))
+	p := geom.NewPointFlat(geom.XY, []float64{1, 2})
+	if err := e.Encode(p); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Encode(p, WithLayer("other")); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Encode(p, WithLayer("a/b")); err != ErrInvalidLayer("a/b") {
+		t.Errorf("e.Encode(p, WithLayer(\"a/b\")) == %v, want %v", err, ErrInvalidLayer("a/b"))
+	}
+	hatch := geom.NewPolygonFlat(geom.XY, []float64{0, 0, 1, 0, 0, 1, 0, 0, 0.2, 0.2, 0.2, 0.3, 0.3, 0.2, 0.2, 0.2}, []int{8, 16})
+	if err := e.Encode(geom.NewGeometryCollection().MustPush(hatch)); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+	got := b.String()
+	if n := strings.Count(got, "  8\ndefault\n"); n != 2 {
+		t.Errorf("got %d entities on layer default, want 2", n)
+	}
+	if n := strings.Count(got, "  8\nother\n"); n != 1 {
+		t.Errorf("got %d entities on layer other, want 1", n)
+	}
+	if !strings.Contains(got, " 91\n2\n 92\n3\n") || !strings.Contains(got, " 92\n2\n") {
+		t.Errorf("got %q, want a hatch with an external and an internal boundary path", got)
+	}
+}
+
+type errWriter struct {
+	err error
+}
+
+func (w errWriter) Write([]byte) (int, error) {
+	return 0, w.err
+}
+
+func TestErrors(t *testing.T) {
+	errWrite := errors.New("write error")
+	e := NewEncoder(errWriter{err: errWrite})
+	p := geom.NewPointFlat(geom.XY, []float64{1, 2})
+	if err := e.Encode(p); err != errWrite {
+		t.Errorf("e.Encode(p) == %v, want %v", err, errWrite)
+	}
+	if err := e.Close(); err != errWrite {
+		t.Errorf("e.Close() == %v, want %v", err, errWrite)
+	}
+	g := geom.NewLinearRing(geom.XY)
+	if _, err := Marshal([]geom.T{g}); !reflect.DeepEqual(err, geom.ErrUnsupportedType{Value: g}) {
+		t.Errorf("Marshal(...) == _, %v, want _, %v", err, geom.ErrUnsupportedType{Value: g})
+	}
+}
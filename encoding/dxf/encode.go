@@ -0,0 +1,276 @@
+package dxf
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+
+	"github.com/twpayne/go-geom"
+)
+
+// Hatch boundary path type flags.
+const (
+	pathExternal = 1
+	pathPolyline = 2
+)
+
+// An Encoder writes geometries as the entities of a DXF file.
+type Encoder struct {
+	w             io.Writer
+	options       options
+	buf           []byte
+	headerWritten bool
+	err           error
+}
+
+// NewEncoder returns a new Encoder that writes to w.
+func NewEncoder(w io.Writer, opts ...Option) *Encoder {
+	return &Encoder{
+		w:       w,
+		options: newOptions(opts),
+	}
+}
+
+// Encode writes the entities of g, preceded by the header of the file if it
+// has not been written. opts override the options of e for g only, for
+// example to write g to a different layer. Errors writing to the underlying
+// io.Writer are sticky.
+func (e *Encoder) Encode(g geom.T, opts ...Option) error {
+	if e.err != nil {
+		return e.err
+	}
+	o := e.options.apply(opts)
+	if !validLayer(o.layer) {
+		return ErrInvalidLayer(o.layer)
+	}
+	e.buf = e.buf[:0]
+	if !e.headerWritten {
+		e.header()
+	}
+	if err := e.encode(g, o); err != nil {
+		return err
+	}
+	e.headerWritten = true
+	return e.flush()
+}
+
+// Close writes the end of the file, preceded by the header if it has not
+// been written. It does not close the underlying io.Writer.
+func (e *Encoder) Close() error {
+	if e.err != nil {
+		return e.err
+	}
+	e.buf = e.buf[:0]
+	if !e.headerWritten {
+		e.header()
+		e.headerWritten = true
+	}
+	e.group(0, "ENDSEC")
+	e.group(0, "EOF")
+	return e.flush()
+}
+
+// Marshal returns a DXF file containing the entities of gs.
+func Marshal(gs []geom.T, opts ...Option) ([]byte, error) {
+	var b bytes.Buffer
+	e := NewEncoder(&b, opts...)
+	for _, g := range gs {
+		if err := e.Encode(g); err != nil {
+			return nil, err
+		}
+	}
+	if err := e.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// header appends the header section and the start of the entities section.
+func (e *Encoder) header() {
+	e.group(0, "SECTION")
+	e.group(2, "HEADER")
+	e.group(9, "$ACADVER")
+	e.group(1, version)
+	e.group(0, "ENDSEC")
+	e.group(0, "SECTION")
+	e.group(2, "ENTITIES")
+}
+
+// encode appends the entities of g.
+func (e *Encoder) encode(g geom.T, o options) error {
+	switch g := g.(type) {
+	case *geom.Point:
+		if !g.Empty() {
+			e.point(g, o)
+		}
+	case *geom.LineString:
+		if !g.Empty() {
+			e.lwPolyline(g.FlatCoords(), g.Stride(), false, o)
+		}
+	case *geom.Polygon:
+		if !g.Empty() {
+			e.polygon(g, o)
+		}
+	case *geom.MultiPoint:
+		for i := 0; i < g.NumPoints(); i++ {
+			if p := g.Point(i); !p.Empty() {
+				e.point(p, o)
+			}
+		}
+	case *geom.MultiLineString:
+		for i := 0; i < g.NumLineStrings(); i++ {
+			if ls := g.LineString(i); !ls.Empty() {
+				e.lwPolyline(ls.FlatCoords(), ls.Stride(), false, o)
+			}
+		}
+	case *geom.MultiPolygon:
+		for i := 0; i < g.NumPolygons(); i++ {
+			if p := g.Polygon(i); !p.Empty() {
+				e.polygon(p, o)
+			}
+		}
+	case *geom.GeometryCollection:
+		for _, member := range g.Geoms() {
+			if err := e.encode(member, o); err != nil {
+				return err
+			}
+		}
+	default:
+		return geom.ErrUnsupportedType{Value: g}
+	}
+	return nil
+}
+
+// point appends a POINT entity.
+func (e *Encoder) point(p *geom.Point, o options) {
+	e.entity("POINT", o)
+	e.group(100, "AcDbPoint")
+	z := 0.0
+	if zIndex := p.Layout().ZIndex(); zIndex != -1 {
+		z = p.FlatCoords()[zIndex]
+	}
+	e.floatGroup(10, p.X())
+	e.floatGroup(20, p.Y())
+	e.floatGroup(30, z)
+}
+
+// lwPolyline appends an LWPOLYLINE entity with the vertices in flatCoords.
+func (e *Encoder) lwPolyline(flatCoords []float64, stride int, closed bool, o options) {
+	e.entity("LWPOLYLINE", o)
+	e.group(100, "AcDbPolyline")
+	e.intGroup(90, len(flatCoords)/stride)
+	if closed {
+		e.intGroup(70, 1)
+	} else {
+		e.intGroup(70, 0)
+	}
+	e.vertices(flatCoords, stride)
+}
+
+// polygon appends a solid HATCH entity with a boundary path for each ring of
+// p and, if o.outlines is set, an LWPOLYLINE for each ring.
+func (e *Encoder) polygon(p *geom.Polygon, o options) {
+	stride := p.Stride()
+	rings := make([][]float64, p.NumLinearRings())
+	for i := range rings {
+		rings[i] = openRing(p.LinearRing(i).FlatCoords(), stride)
+	}
+	e.entity("HATCH", o)
+	e.group(100, "AcDbHatch")
+	e.floatGroup(10, 0)
+	e.floatGroup(20, 0)
+	e.floatGroup(30, 0)
+	e.floatGroup(210, 0)
+	e.floatGroup(220, 0)
+	e.floatGroup(230, 1)
+	e.group(2, "SOLID")
+	e.intGroup(70, 1) // solid fill
+	e.intGroup(71, 0) // not associative
+	e.intGroup(91, len(rings))
+	for i, ring := range rings {
+		pathType := pathPolyline
+		if i == 0 {
+			pathType |= pathExternal
+		}
+		e.intGroup(92, pathType)
+		e.intGroup(72, 0) // no bulges
+		e.intGroup(73, 1) // closed
+		e.intGroup(93, len(ring)/stride)
+		e.vertices(ring, stride)
+		e.intGroup(97, 0) // no source boundary objects
+	}
+	e.intGroup(75, 0) // odd parity hatch style
+	e.intGroup(76, 1) // predefined pattern
+	e.intGroup(98, 0) // no seed points
+	if o.outlines {
+		for _, ring := range rings {
+			e.lwPolyline(ring, stride, true, o)
+		}
+	}
+}
+
+// entity appends the start of an entity of type entityType.
+func (e *Encoder) entity(entityType string, o options) {
+	e.group(0, entityType)
+	e.group(100, "AcDbEntity")
+	e.group(8, o.layer)
+}
+
+// vertices appends the X and Y coordinates of the vertices in flatCoords.
+func (e *Encoder) vertices(flatCoords []float64, stride int) {
+	for i := 0; i < len(flatCoords); i += stride {
+		e.floatGroup(10, flatCoords[i])
+		e.floatGroup(20, flatCoords[i+1])
+	}
+}
+
+// group appends a group with code and value.
+func (e *Encoder) group(code int, value string) {
+	e.appendCode(code)
+	e.buf = append(e.buf, value...)
+	e.buf = append(e.buf, '\n')
+}
+
+func (e *Encoder) intGroup(code, value int) {
+	e.appendCode(code)
+	e.buf = strconv.AppendInt(e.buf, int64(value), 10)
+	e.buf = append(e.buf, '\n')
+}
+
+func (e *Encoder) floatGroup(code int, value float64) {
+	e.appendCode(code)
+	e.buf = strconv.AppendFloat(e.buf, value, 'f', -1, 64)
+	e.buf = append(e.buf, '\n')
+}
+
+// appendCode appends code, right-aligned in three characters as is
+// conventional.
+func (e *Encoder) appendCode(code int) {
+	switch {
+	case code < 10:
+		e.buf = append(e.buf, ' ', ' ')
+	case code < 100:
+		e.buf = append(e.buf, ' ')
+	}
+	e.buf = strconv.AppendInt(e.buf, int64(code), 10)
+	e.buf = append(e.buf, '\n')
+}
+
+func (e *Encoder) flush() error {
+	_, e.err = e.w.Write(e.buf)
+	return e.err
+}
+
+// openRing returns the vertices of the ring flatCoords without the last
+// vertex if its X and Y are equal to those of the first, as boundaries are
+// closed implicitly.
+func openRing(flatCoords []float64, stride int) []float64 {
+	n := len(flatCoords)
+	if n < 2*stride {
+		return flatCoords
+	}
+	if flatCoords[0] != flatCoords[n-stride] || flatCoords[1] != flatCoords[n-stride+1] {
+		return flatCoords
+	}
+	return flatCoords[:n-stride]
+}
@@ -0,0 +1,231 @@
+package ewkb
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/wkbcommon"
+)
+
+// An Encoder writes geometries to an output stream, reusing an internal
+// buffer between geometries. Each geometry is written with a single call to
+// Write, so an Encoder can write directly into a larger binary frame, for
+// example the rows of a PostgreSQL COPY in binary format.
+type Encoder struct {
+	w       io.Writer
+	buf     []byte
+	options options
+}
+
+// NewEncoder returns a new Encoder that writes to w.
+func NewEncoder(w io.Writer, opts ...Option) *Encoder {
+	return &Encoder{
+		w:       w,
+		options: newOptions(opts),
+	}
+}
+
+// Encode writes the EWKB encoding of g.
+func (e *Encoder) Encode(g geom.T) error {
+	buf, err := appendGeom(e.buf[:0], e.options.byteOrder, g, e.options, false, 0)
+	if err != nil {
+		return err
+	}
+	e.buf = buf
+	_, err = e.w.Write(buf)
+	return err
+}
+
+// Append appends the EWKB encoding of g to dst and returns the extended
+// buffer. Without options, it does not allocate if dst has sufficient
+// capacity, except for the segments of CompoundCurves and the rings of
+// CurvePolygons.
+func Append(dst []byte, g geom.T, opts ...Option) ([]byte, error) {
+	o := defaultOptions
+	if len(opts) != 0 {
+		o = newOptions(opts)
+	}
+	return appendGeom(dst, o.byteOrder, g, o, false, 0)
+}
+
+// appendGeom appends the EWKB encoding of g to dst. nested is true if the
+// geometry is a member of a geometry with parentSRID.
+func appendGeom(dst []byte, byteOrder binary.ByteOrder, g geom.T, o options, nested bool, parentSRID int) ([]byte, error) {
+	ewkbGeometryType, err := geometryType(g)
+	if err != nil {
+		return nil, err
+	}
+	srid := g.SRID()
+	switch {
+	case o.sridPolicy == SRIDNever:
+	case nested:
+		if srid != 0 && srid != parentSRID {
+			ewkbGeometryType |= ewkbSRID
+		}
+	default:
+		if srid == 0 {
+			srid = o.defaultSRID
+		}
+		switch {
+		case o.sridPolicy == SRIDAlways || srid != 0:
+			ewkbGeometryType |= ewkbSRID
+		case o.sridPolicy == SRIDRequired:
+			return nil, ErrMissingSRID{}
+		}
+	}
+	if dst, err = appendHeader(dst, byteOrder, ewkbGeometryType); err != nil {
+		return nil, err
+	}
+	if ewkbGeometryType&ewkbSRID != 0 {
+		dst = wkbcommon.AppendUInt32(dst, byteOrder, uint32(srid))
+	}
+
+	if gc, ok := g.(*geom.GeometryCollection); ok {
+		dst = wkbcommon.AppendUInt32(dst, byteOrder, uint32(gc.NumGeoms()))
+		for _, member := range gc.Geoms() {
+			if dst, err = appendGeom(dst, byteOrder, member, o, true, srid); err != nil {
+				return nil, err
+			}
+		}
+		return dst, nil
+	}
+	// The members of multi-geometries and surfaces are written directly from
+	// the flat coordinates, without SRIDs.
+	layoutFlags := ewkbGeometryType & (ewkbZ | ewkbM)
+	flatCoords, stride := g.FlatCoords(), g.Stride()
+	switch g := g.(type) {
+	case *geom.Point:
+		return wkbcommon.AppendFloatArray(dst, byteOrder, flatCoords), nil
+	case *geom.LineString:
+		return wkbcommon.AppendFlatCoords1(dst, byteOrder, flatCoords, stride), nil
+	case *geom.Polygon:
+		return wkbcommon.AppendFlatCoords2(dst, byteOrder, flatCoords, 0, g.Ends(), stride), nil
+	case *geom.MultiPoint:
+		dst = wkbcommon.AppendUInt32(dst, byteOrder, uint32(len(flatCoords)/stride))
+		for i := 0; i < len(flatCoords); i += stride {
+			if dst, err = appendHeader(dst, byteOrder, layoutFlags|wkbcommon.PointID); err != nil {
+				return nil, err
+			}
+			dst = wkbcommon.AppendFloatArray(dst, byteOrder, flatCoords[i:i+stride])
+		}
+		return dst, nil
+	case *geom.MultiLineString:
+		dst = wkbcommon.AppendUInt32(dst, byteOrder, uint32(len(g.Ends())))
+		offset := 0
+		for _, end := range g.Ends() {
+			if dst, err = appendHeader(dst, byteOrder, layoutFlags|wkbcommon.LineStringID); err != nil {
+				return nil, err
+			}
+			dst = wkbcommon.AppendFlatCoords1(dst, byteOrder, flatCoords[offset:end], stride)
+			offset = end
+		}
+		return dst, nil
+	case *geom.MultiPolygon:
+		return appendPolygons(dst, byteOrder, layoutFlags|wkbcommon.PolygonID, flatCoords, g.Endss(), stride)
+	case *geom.CircularString:
+		return wkbcommon.AppendFlatCoords1(dst, byteOrder, flatCoords, stride), nil
+	case *geom.CompoundCurve:
+		n := g.NumSegments()
+		dst = wkbcommon.AppendUInt32(dst, byteOrder, uint32(n))
+		for i := 0; i < n; i++ {
+			if dst, err = appendGeom(dst, byteOrder, g.Segment(i), o, true, srid); err != nil {
+				return nil, err
+			}
+		}
+		return dst, nil
+	case *geom.CurvePolygon:
+		n := g.NumRings()
+		dst = wkbcommon.AppendUInt32(dst, byteOrder, uint32(n))
+		for i := 0; i < n; i++ {
+			if dst, err = appendGeom(dst, byteOrder, g.Ring(i), o, true, srid); err != nil {
+				return nil, err
+			}
+		}
+		return dst, nil
+	case *geom.PolyhedralSurface:
+		return appendPolygons(dst, byteOrder, layoutFlags|wkbcommon.PolygonID, flatCoords, g.Endss(), stride)
+	case *geom.TIN:
+		return appendPolygons(dst, byteOrder, layoutFlags|wkbcommon.TriangleID, flatCoords, g.Endss(), stride)
+	case *geom.Triangle:
+		return wkbcommon.AppendFlatCoords2(dst, byteOrder, flatCoords, 0, g.Ends(), stride), nil
+	default:
+		return nil, geom.ErrUnsupportedType{Value: g}
+	}
+}
+
+func geometryType(g geom.T) (uint32, error) {
+	var ewkbGeometryType uint32
+	switch g.(type) {
+	case *geom.Point:
+		ewkbGeometryType = wkbcommon.PointID
+	case *geom.LineString:
+		ewkbGeometryType = wkbcommon.LineStringID
+	case *geom.Polygon:
+		ewkbGeometryType = wkbcommon.PolygonID
+	case *geom.MultiPoint:
+		ewkbGeometryType = wkbcommon.MultiPointID
+	case *geom.MultiLineString:
+		ewkbGeometryType = wkbcommon.MultiLineStringID
+	case *geom.MultiPolygon:
+		ewkbGeometryType = wkbcommon.MultiPolygonID
+	case *geom.GeometryCollection:
+		ewkbGeometryType = wkbcommon.GeometryCollectionID
+	case *geom.CircularString:
+		ewkbGeometryType = wkbcommon.CircularStringID
+	case *geom.CompoundCurve:
+		ewkbGeometryType = wkbcommon.CompoundCurveID
+	case *geom.CurvePolygon:
+		ewkbGeometryType = wkbcommon.CurvePolygonID
+	case *geom.PolyhedralSurface:
+		ewkbGeometryType = wkbcommon.PolyhedralSurfaceID
+	case *geom.TIN:
+		ewkbGeometryType = wkbcommon.TINID
+	case *geom.Triangle:
+		ewkbGeometryType = wkbcommon.TriangleID
+	default:
+		return 0, geom.ErrUnsupportedType{Value: g}
+	}
+	switch g.Layout() {
+	case geom.XY:
+	case geom.XYZ:
+		ewkbGeometryType |= ewkbZ
+	case geom.XYM:
+		ewkbGeometryType |= ewkbM
+	case geom.XYZM:
+		ewkbGeometryType |= ewkbZ | ewkbM
+	default:
+		return 0, geom.ErrUnsupportedLayout(g.Layout())
+	}
+	return ewkbGeometryType, nil
+}
+
+func appendHeader(dst []byte, byteOrder binary.ByteOrder, ewkbGeometryType uint32) ([]byte, error) {
+	switch byteOrder {
+	case XDR:
+		dst = append(dst, wkbcommon.XDRID)
+	case NDR:
+		dst = append(dst, wkbcommon.NDRID)
+	default:
+		return nil, wkbcommon.ErrUnsupportedByteOrder{}
+	}
+	return wkbcommon.AppendUInt32(dst, byteOrder, ewkbGeometryType), nil
+}
+
+// appendPolygons appends the number of polygons with endss and the polygons,
+// each with a header of polygonType, to dst.
+func appendPolygons(dst []byte, byteOrder binary.ByteOrder, polygonType uint32, flatCoords []float64, endss [][]int, stride int) ([]byte, error) {
+	dst = wkbcommon.AppendUInt32(dst, byteOrder, uint32(len(endss)))
+	offset := 0
+	for _, ends := range endss {
+		var err error
+		if dst, err = appendHeader(dst, byteOrder, polygonType); err != nil {
+			return nil, err
+		}
+		dst = wkbcommon.AppendFlatCoords2(dst, byteOrder, flatCoords, offset, ends, stride)
+		if len(ends) > 0 {
+			offset = ends[len(ends)-1]
+		}
+	}
+	return dst, nil
+}
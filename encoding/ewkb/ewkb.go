@@ -28,8 +28,36 @@ const (
 	ewkbSRID = 0x20000000
 )
 
-// Read reads an arbitrary geometry from r.
-func Read(r io.Reader) (geom.T, error) {
+const (
+	wkbXYZID  = 1000
+	wkbXYMID  = 2000
+	wkbXYZMID = 3000
+)
+
+// An ErrMissingSRID is returned when the SRIDPolicy is SRIDRequired and a
+// geometry does not have an SRID.
+type ErrMissingSRID struct{}
+
+func (e ErrMissingSRID) Error() string {
+	return "ewkb: missing SRID"
+}
+
+// Read reads an arbitrary geometry from r. Each member of a multi-geometry or
+// geometry collection is read with its own byte order, which may differ from
+// that of its container.
+func Read(r io.Reader, opts ...Option) (geom.T, error) {
+	o := newOptions(opts)
+	return read(r, o, wkbcommon.NewLimiter(o.limits), false)
+}
+
+// read reads an arbitrary geometry from r, enforcing the limits of l. nested
+// is true if the geometry is a member of another geometry.
+func read(r io.Reader, o options, l *wkbcommon.Limiter, nested bool) (geom.T, error) {
+	if err := l.Enter(); err != nil {
+		return nil, err
+	}
+	defer l.Leave()
+
 	ewkbByteOrder, err := wkbcommon.ReadByte(r)
 	if err != nil {
 		return nil, err
@@ -63,6 +91,19 @@ func Read(r io.Reader) (geom.T, error) {
 	default:
 		return nil, wkbcommon.ErrUnknownType(t)
 	}
+	if o.acceptWKB && t&(ewkbZ|ewkbM|ewkbSRID) == 0 && t >= 1000 {
+		switch 1000 * (t / 1000) {
+		case wkbXYZID:
+			layout = geom.XYZ
+		case wkbXYMID:
+			layout = geom.XYM
+		case wkbXYZMID:
+			layout = geom.XYZM
+		default:
+			return nil, wkbcommon.ErrUnknownType(t)
+		}
+		t %= 1000
+	}
 
 	var srid uint32
 	if ewkbGeometryType&ewkbSRID != 0 {
@@ -71,22 +112,40 @@ func Read(r io.Reader) (geom.T, error) {
 			return nil, err
 		}
 	}
+	if o.sridPolicy == SRIDNever {
+		srid = 0
+	}
+	if !nested {
+		if o.sridPolicy == SRIDRequired && ewkbGeometryType&ewkbSRID == 0 {
+			return nil, ErrMissingSRID{}
+		}
+		if srid == 0 {
+			srid = uint32(o.defaultSRID)
+		}
+	}
+	if o.sridFunc != nil && (!nested || srid != 0) {
+		newSRID, err := o.sridFunc(int(srid))
+		if err != nil {
+			return nil, err
+		}
+		srid = uint32(newSRID)
+	}
 
 	switch t &^ (ewkbZ | ewkbM | ewkbSRID) {
 	case wkbcommon.PointID:
-		flatCoords, err := wkbcommon.ReadFlatCoords0(r, byteOrder, layout.Stride())
+		flatCoords, err := l.ReadFlatCoords0(r, byteOrder, layout.Stride())
 		if err != nil {
 			return nil, err
 		}
 		return geom.NewPointFlat(layout, flatCoords).SetSRID(int(srid)), nil
 	case wkbcommon.LineStringID:
-		flatCoords, err := wkbcommon.ReadFlatCoords1(r, byteOrder, layout.Stride())
+		flatCoords, err := l.ReadFlatCoords1(r, byteOrder, layout.Stride())
 		if err != nil {
 			return nil, err
 		}
 		return geom.NewLineStringFlat(layout, flatCoords).SetSRID(int(srid)), nil
 	case wkbcommon.PolygonID:
-		flatCoords, ends, err := wkbcommon.ReadFlatCoords2(r, byteOrder, layout.Stride())
+		flatCoords, ends, err := l.ReadFlatCoords2(r, byteOrder, layout.Stride())
 		if err != nil {
 			return nil, err
 		}
@@ -96,12 +155,12 @@ func Read(r io.Reader) (geom.T, error) {
 		if err != nil {
 			return nil, err
 		}
-		if limit := wkbcommon.MaxGeometryElements[1]; limit >= 0 && int(n) > limit {
-			return nil, wkbcommon.ErrGeometryTooLarge{Level: 1, N: int(n), Limit: limit}
+		if err := l.CheckElements(1, n); err != nil {
+			return nil, err
 		}
 		mp := geom.NewMultiPoint(layout).SetSRID(int(srid))
 		for i := uint32(0); i < n; i++ {
-			g, err := Read(r)
+			g, err := read(r, o, l, true)
 			if err != nil {
 				return nil, err
 			}
@@ -119,12 +178,12 @@ func Read(r io.Reader) (geom.T, error) {
 		if err != nil {
 			return nil, err
 		}
-		if limit := wkbcommon.MaxGeometryElements[2]; limit >= 0 && int(n) > limit {
-			return nil, wkbcommon.ErrGeometryTooLarge{Level: 2, N: int(n), Limit: limit}
+		if err := l.CheckElements(2, n); err != nil {
+			return nil, err
 		}
 		mls := geom.NewMultiLineString(layout).SetSRID(int(srid))
 		for i := uint32(0); i < n; i++ {
-			g, err := Read(r)
+			g, err := read(r, o, l, true)
 			if err != nil {
 				return nil, err
 			}
@@ -142,12 +201,12 @@ func Read(r io.Reader) (geom.T, error) {
 		if err != nil {
 			return nil, err
 		}
-		if limit := wkbcommon.MaxGeometryElements[3]; limit >= 0 && int(n) > limit {
-			return nil, wkbcommon.ErrGeometryTooLarge{Level: 3, N: int(n), Limit: limit}
+		if err := l.CheckElements(3, n); err != nil {
+			return nil, err
 		}
 		mp := geom.NewMultiPolygon(layout).SetSRID(int(srid))
 		for i := uint32(0); i < n; i++ {
-			g, err := Read(r)
+			g, err := read(r, o, l, true)
 			if err != nil {
 				return nil, err
 			}
@@ -165,12 +224,12 @@ func Read(r io.Reader) (geom.T, error) {
 		if err != nil {
 			return nil, err
 		}
-		if limit := wkbcommon.MaxGeometryElements[1]; limit >= 0 && int(n) > limit {
-			return nil, wkbcommon.ErrGeometryTooLarge{Level: 1, N: int(n), Limit: limit}
+		if err := l.CheckElements(1, n); err != nil {
+			return nil, err
 		}
 		gc := geom.NewGeometryCollection().SetSRID(int(srid))
 		for i := uint32(0); i < n; i++ {
-			g, err := Read(r)
+			g, err := read(r, o, l, true)
 			if err != nil {
 				return nil, err
 			}
@@ -179,135 +238,134 @@ func Read(r io.Reader) (geom.T, error) {
 			}
 		}
 		return gc, nil
-	default:
-		return nil, wkbcommon.ErrUnsupportedType(ewkbGeometryType)
-	}
-}
-
-// Unmarshal unmrshals an arbitrary geometry from a []byte.
-func Unmarshal(data []byte) (geom.T, error) {
-	return Read(bytes.NewBuffer(data))
-}
-
-// Write writes an arbitrary geometry to w.
-func Write(w io.Writer, byteOrder binary.ByteOrder, g geom.T) error {
-	var ewkbByteOrder byte
-	switch byteOrder {
-	case XDR:
-		ewkbByteOrder = wkbcommon.XDRID
-	case NDR:
-		ewkbByteOrder = wkbcommon.NDRID
-	default:
-		return wkbcommon.ErrUnsupportedByteOrder{}
-	}
-	if err := binary.Write(w, byteOrder, ewkbByteOrder); err != nil {
-		return err
-	}
-
-	var ewkbGeometryType uint32
-	switch g.(type) {
-	case *geom.Point:
-		ewkbGeometryType = wkbcommon.PointID
-	case *geom.LineString:
-		ewkbGeometryType = wkbcommon.LineStringID
-	case *geom.Polygon:
-		ewkbGeometryType = wkbcommon.PolygonID
-	case *geom.MultiPoint:
-		ewkbGeometryType = wkbcommon.MultiPointID
-	case *geom.MultiLineString:
-		ewkbGeometryType = wkbcommon.MultiLineStringID
-	case *geom.MultiPolygon:
-		ewkbGeometryType = wkbcommon.MultiPolygonID
-	case *geom.GeometryCollection:
-		ewkbGeometryType = wkbcommon.GeometryCollectionID
-	default:
-		return geom.ErrUnsupportedType{Value: g}
-	}
-	switch g.Layout() {
-	case geom.XY:
-	case geom.XYZ:
-		ewkbGeometryType |= ewkbZ
-	case geom.XYM:
-		ewkbGeometryType |= ewkbM
-	case geom.XYZM:
-		ewkbGeometryType |= ewkbZ | ewkbM
-	default:
-		return geom.ErrUnsupportedLayout(g.Layout())
-	}
-	srid := g.SRID()
-	if srid != 0 {
-		ewkbGeometryType |= ewkbSRID
-	}
-	if err := binary.Write(w, byteOrder, ewkbGeometryType); err != nil {
-		return err
-	}
-	if ewkbGeometryType&ewkbSRID != 0 {
-		if err := binary.Write(w, byteOrder, uint32(srid)); err != nil {
-			return err
+	case wkbcommon.CircularStringID:
+		flatCoords, err := l.ReadFlatCoords1(r, byteOrder, layout.Stride())
+		if err != nil {
+			return nil, err
 		}
-	}
-
-	switch g := g.(type) {
-	case *geom.Point:
-		return wkbcommon.WriteFlatCoords0(w, byteOrder, g.FlatCoords())
-	case *geom.LineString:
-		return wkbcommon.WriteFlatCoords1(w, byteOrder, g.FlatCoords(), g.Stride())
-	case *geom.Polygon:
-		return wkbcommon.WriteFlatCoords2(w, byteOrder, g.FlatCoords(), g.Ends(), g.Stride())
-	case *geom.MultiPoint:
-		n := g.NumPoints()
-		if err := binary.Write(w, byteOrder, uint32(n)); err != nil {
-			return err
-		}
-		for i := 0; i < n; i++ {
-			if err := Write(w, byteOrder, g.Point(i)); err != nil {
-				return err
+		return geom.NewCircularStringFlat(layout, flatCoords).SetSRID(int(srid)), nil
+	case wkbcommon.CompoundCurveID:
+		n, err := wkbcommon.ReadUInt32(r, byteOrder)
+		if err != nil {
+			return nil, err
+		}
+		if err := l.CheckElements(2, n); err != nil {
+			return nil, err
+		}
+		cc := geom.NewCompoundCurve(layout).SetSRID(int(srid))
+		for i := uint32(0); i < n; i++ {
+			g, err := read(r, o, l, true)
+			if err != nil {
+				return nil, err
+			}
+			if err = cc.Push(g); err != nil {
+				return nil, err
 			}
 		}
-		return nil
-	case *geom.MultiLineString:
-		n := g.NumLineStrings()
-		if err := binary.Write(w, byteOrder, uint32(n)); err != nil {
-			return err
+		return cc, nil
+	case wkbcommon.CurvePolygonID:
+		n, err := wkbcommon.ReadUInt32(r, byteOrder)
+		if err != nil {
+			return nil, err
 		}
-		for i := 0; i < n; i++ {
-			if err := Write(w, byteOrder, g.LineString(i)); err != nil {
-				return err
+		if err := l.CheckElements(2, n); err != nil {
+			return nil, err
+		}
+		cp := geom.NewCurvePolygon(layout).SetSRID(int(srid))
+		for i := uint32(0); i < n; i++ {
+			g, err := read(r, o, l, true)
+			if err != nil {
+				return nil, err
+			}
+			if err = cp.Push(g); err != nil {
+				return nil, err
 			}
 		}
-		return nil
-	case *geom.MultiPolygon:
-		n := g.NumPolygons()
-		if err := binary.Write(w, byteOrder, uint32(n)); err != nil {
-			return err
+		return cp, nil
+	case wkbcommon.PolyhedralSurfaceID:
+		n, err := wkbcommon.ReadUInt32(r, byteOrder)
+		if err != nil {
+			return nil, err
+		}
+		if err := l.CheckElements(3, n); err != nil {
+			return nil, err
 		}
-		for i := 0; i < n; i++ {
-			if err := Write(w, byteOrder, g.Polygon(i)); err != nil {
-				return err
+		ps := geom.NewPolyhedralSurface(layout).SetSRID(int(srid))
+		for i := uint32(0); i < n; i++ {
+			g, err := read(r, o, l, true)
+			if err != nil {
+				return nil, err
+			}
+			p, ok := g.(*geom.Polygon)
+			if !ok {
+				return nil, wkbcommon.ErrUnexpectedType{Got: g, Want: &geom.Polygon{}}
+			}
+			if err = ps.Push(p); err != nil {
+				return nil, err
 			}
 		}
-		return nil
-	case *geom.GeometryCollection:
-		n := g.NumGeoms()
-		if err := binary.Write(w, byteOrder, uint32(n)); err != nil {
-			return err
+		return ps, nil
+	case wkbcommon.TINID:
+		n, err := wkbcommon.ReadUInt32(r, byteOrder)
+		if err != nil {
+			return nil, err
+		}
+		if err := l.CheckElements(3, n); err != nil {
+			return nil, err
 		}
-		for i := 0; i < n; i++ {
-			if err := Write(w, byteOrder, g.Geom(i)); err != nil {
-				return err
+		tin := geom.NewTIN(layout).SetSRID(int(srid))
+		for i := uint32(0); i < n; i++ {
+			g, err := read(r, o, l, true)
+			if err != nil {
+				return nil, err
 			}
+			t, ok := g.(*geom.Triangle)
+			if !ok {
+				return nil, wkbcommon.ErrUnexpectedType{Got: g, Want: &geom.Triangle{}}
+			}
+			if err = tin.Push(t); err != nil {
+				return nil, err
+			}
+		}
+		return tin, nil
+	case wkbcommon.TriangleID:
+		flatCoords, ends, err := l.ReadFlatCoords2(r, byteOrder, layout.Stride())
+		if err != nil {
+			return nil, err
 		}
-		return nil
+		return geom.NewTriangleFlat(layout, flatCoords, ends).SetSRID(int(srid)), nil
 	default:
-		return geom.ErrUnsupportedType{Value: g}
+		return nil, wkbcommon.ErrUnsupportedType(ewkbGeometryType)
 	}
 }
 
-// Marshal marshals an arbitrary geometry to a []byte.
-func Marshal(g geom.T, byteOrder binary.ByteOrder) ([]byte, error) {
-	w := bytes.NewBuffer(nil)
-	if err := Write(w, byteOrder, g); err != nil {
+// Unmarshal unmrshals an arbitrary geometry from a []byte.
+func Unmarshal(data []byte, opts ...Option) (geom.T, error) {
+	return Read(bytes.NewBuffer(data), opts...)
+}
+
+// UnmarshalStub returns the Stub of the geometry in data. Unlike
+// wkb.UnmarshalStub, it decodes the geometry.
+func UnmarshalStub(data []byte, opts ...Option) (*geom.Stub, error) {
+	g, err := Unmarshal(data, opts...)
+	if err != nil {
 		return nil, err
 	}
-	return w.Bytes(), nil
+	return geom.NewStub(g)
+}
+
+// Write writes an arbitrary geometry to w with a single call to w.Write. To
+// encode many geometries, use an Encoder, which reuses its buffer.
+func Write(w io.Writer, byteOrder binary.ByteOrder, g geom.T, opts ...Option) error {
+	data, err := appendGeom(nil, byteOrder, g, newOptions(opts), false, 0)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// Marshal marshals an arbitrary geometry to a []byte.
+func Marshal(g geom.T, byteOrder binary.ByteOrder, opts ...Option) ([]byte, error) {
+	return appendGeom(nil, byteOrder, g, newOptions(opts), false, 0)
 }
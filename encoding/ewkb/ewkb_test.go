@@ -1,11 +1,16 @@
 package ewkb
 
 import (
+	"bytes"
+	"encoding/binary"
 	"encoding/hex"
+	"errors"
 	"reflect"
 	"testing"
 
 	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/wkbcommon"
+	"github.com/twpayne/go-geom/internal/testdata"
 )
 
 func test(t *testing.T, g geom.T, xdr []byte, ndr []byte) {
@@ -16,6 +21,9 @@ func test(t *testing.T, g geom.T, xdr []byte, ndr []byte) {
 		if got, err := Marshal(g, XDR); err != nil || !reflect.DeepEqual(got, xdr) {
 			t.Errorf("Marshal(%#v, XDR) == %s, %#v, want %s, nil", g, hex.EncodeToString(got), err, hex.EncodeToString(xdr))
 		}
+		if got, err := Append([]byte{0xff}, g, WithByteOrder(XDR)); err != nil || !bytes.Equal(got, append([]byte{0xff}, xdr...)) {
+			t.Errorf("Append(ff, %#v, WithByteOrder(XDR)) == %s, %v, want ff%s, nil", g, hex.EncodeToString(got), err, hex.EncodeToString(xdr))
+		}
 	}
 	if ndr != nil {
 		if got, err := Unmarshal(ndr); err != nil || !reflect.DeepEqual(got, g) {
@@ -24,6 +32,9 @@ func test(t *testing.T, g geom.T, xdr []byte, ndr []byte) {
 		if got, err := Marshal(g, NDR); err != nil || !reflect.DeepEqual(got, ndr) {
 			t.Errorf("Marshal(%#v, NDR) == %s, %#v, want %#v, nil", g, hex.EncodeToString(got), err, hex.EncodeToString(ndr))
 		}
+		if got, err := Append([]byte{0xff}, g, WithByteOrder(NDR)); err != nil || !bytes.Equal(got, append([]byte{0xff}, ndr...)) {
+			t.Errorf("Append(ff, %#v, WithByteOrder(NDR)) == %s, %v, want ff%s, nil", g, hex.EncodeToString(got), err, hex.EncodeToString(ndr))
+		}
 	}
 	switch g := g.(type) {
 	case *geom.Point:
@@ -221,3 +232,350 @@ func Test(t *testing.T) {
 		test(t, tc.g, tc.xdr, tc.ndr)
 	}
 }
+
+func TestCurvesAndSurfaces(t *testing.T) {
+	ids := []uint32{
+		wkbcommon.CircularStringID,
+		wkbcommon.CompoundCurveID,
+		wkbcommon.CurvePolygonID,
+		wkbcommon.PolyhedralSurfaceID,
+		wkbcommon.TINID,
+		wkbcommon.TriangleID,
+	}
+	for _, tc := range []struct {
+		layout geom.Layout
+		flags  uint32
+	}{
+		{layout: geom.XY},
+		{layout: geom.XYZ, flags: ewkbZ},
+		{layout: geom.XYM, flags: ewkbM},
+		{layout: geom.XYZM, flags: ewkbZ | ewkbM},
+	} {
+		for i, g := range testdata.CurvesAndSurfaces(tc.layout) {
+			for _, srid := range []int{0, 4326} {
+				g := geom.SetSRIDRecursive(g, srid)
+				flags := tc.flags
+				if srid != 0 {
+					flags |= ewkbSRID
+				}
+				for _, byteOrder := range []binary.ByteOrder{XDR, NDR} {
+					data, err := Marshal(g, byteOrder)
+					if err != nil {
+						t.Fatalf("Marshal(%#v, %v) == _, %v, want _, <nil>", g, byteOrder, err)
+					}
+					if got, want := byteOrder.Uint32(data[1:]), ids[i]|flags; got != want {
+						t.Errorf("Marshal(%#v, %v) type == %#x, want %#x", g, byteOrder, got, want)
+					}
+					if got, err := Unmarshal(data); err != nil || !reflect.DeepEqual(got, g) {
+						t.Errorf("Unmarshal(%s) == %#v, %v, want %#v, <nil>", hex.EncodeToString(data), got, err, g)
+					}
+					if got, err := Append(nil, g, WithByteOrder(byteOrder)); err != nil || !bytes.Equal(got, data) {
+						t.Errorf("Append(nil, %#v, WithByteOrder(%v)) == %s, %v, want %s, <nil>", g, byteOrder, hex.EncodeToString(got), err, hex.EncodeToString(data))
+					}
+					want, err := geom.NewStub(g)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if got, err := UnmarshalStub(data); err != nil || !reflect.DeepEqual(got, want) {
+						t.Errorf("UnmarshalStub(%s) == %+v, %v, want %+v, <nil>", hex.EncodeToString(data), got, err, want)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestOptions(t *testing.T) {
+	errUnknownSRID := errors.New("unknown SRID")
+	remapSRID := func(srid int) (int, error) {
+		switch srid {
+		case 900913:
+			return 3857, nil
+		case 3857, 4326:
+			return srid, nil
+		default:
+			return 0, errUnknownSRID
+		}
+	}
+	point := geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1, 2})
+	pointWithSRID := geom.NewPoint(geom.XY).SetSRID(4326).MustSetCoords(geom.Coord{1, 2})
+	for _, tc := range []struct {
+		name string
+		g    geom.T
+		opts []Option
+		ndr  string
+		err  error
+	}{
+		{
+			name: "default",
+			g:    point,
+			ndr:  "0101000000000000000000f03f0000000000000040",
+		},
+		{
+			name: "always",
+			g:    point,
+			opts: []Option{WithSRIDPolicy(SRIDAlways)},
+			ndr:  "010100002000000000000000000000f03f0000000000000040",
+		},
+		{
+			name: "default_srid",
+			g:    point,
+			opts: []Option{WithDefaultSRID(4326)},
+			ndr:  "0101000020e6100000000000000000f03f0000000000000040",
+		},
+		{
+			name: "default_srid_not_used",
+			g:    geom.NewPoint(geom.XY).SetSRID(3857).MustSetCoords(geom.Coord{1, 2}),
+			opts: []Option{WithDefaultSRID(4326)},
+			ndr:  "0101000020110f0000000000000000f03f0000000000000040",
+		},
+		{
+			name: "never",
+			g:    pointWithSRID,
+			opts: []Option{WithSRIDPolicy(SRIDNever)},
+			ndr:  "0101000000000000000000f03f0000000000000040",
+		},
+		{
+			name: "never_nested",
+			g:    geom.NewGeometryCollection().MustPush(pointWithSRID),
+			opts: []Option{WithSRIDPolicy(SRIDNever)},
+			ndr:  "0107000000010000000101000000000000000000f03f0000000000000040",
+		},
+		{
+			name: "required",
+			g:    pointWithSRID,
+			opts: []Option{WithSRIDPolicy(SRIDRequired)},
+			ndr:  "0101000020e6100000000000000000f03f0000000000000040",
+		},
+		{
+			name: "required_missing",
+			g:    point,
+			opts: []Option{WithSRIDPolicy(SRIDRequired)},
+			err:  ErrMissingSRID{},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Marshal(tc.g, NDR, tc.opts...)
+			if err != tc.err || hex.EncodeToString(got) != tc.ndr {
+				t.Errorf("Marshal(%v, NDR, ...) == %s, %v, want %s, %v", tc.g, hex.EncodeToString(got), err, tc.ndr, tc.err)
+			}
+		})
+	}
+
+	for _, tc := range []struct {
+		name string
+		ndr  string
+		opts []Option
+		want geom.T
+		err  error
+	}{
+		{
+			name: "default_srid",
+			ndr:  "0101000000000000000000f03f0000000000000040",
+			opts: []Option{WithDefaultSRID(4326)},
+			want: pointWithSRID,
+		},
+		{
+			name: "never",
+			ndr:  "0101000020e6100000000000000000f03f0000000000000040",
+			opts: []Option{WithSRIDPolicy(SRIDNever)},
+			want: point,
+		},
+		{
+			name: "required",
+			ndr:  "0101000020e6100000000000000000f03f0000000000000040",
+			opts: []Option{WithSRIDPolicy(SRIDRequired)},
+			want: pointWithSRID,
+		},
+		{
+			name: "required_missing",
+			ndr:  "0101000000000000000000f03f0000000000000040",
+			opts: []Option{WithSRIDPolicy(SRIDRequired)},
+			err:  ErrMissingSRID{},
+		},
+		{
+			name: "wkb_rejected",
+			ndr:  "01e9030000000000000000f03f00000000000000400000000000000840",
+			err:  wkbcommon.ErrUnsupportedType(1001),
+		},
+		{
+			name: "wkb_accepted",
+			ndr:  "01e9030000000000000000f03f00000000000000400000000000000840",
+			opts: []Option{WithAcceptWKB(true)},
+			want: geom.NewPoint(geom.XYZ).MustSetCoords(geom.Coord{1, 2, 3}),
+		},
+		{
+			name: "wkb_accepted_nested",
+			ndr:  "01d40700000100000001d1070000000000000000f03f00000000000000400000000000000840",
+			opts: []Option{WithAcceptWKB(true), WithDefaultSRID(4326)},
+			want: geom.NewMultiPoint(geom.XYM).SetSRID(4326).MustSetCoords([]geom.Coord{{1, 2, 3}}),
+		},
+		{
+			name: "srid_func",
+			ndr:  "010100002031bf0d00000000000000f03f0000000000000040",
+			opts: []Option{WithSRIDFunc(remapSRID)},
+			want: geom.NewPoint(geom.XY).SetSRID(3857).MustSetCoords(geom.Coord{1, 2}),
+		},
+		{
+			name: "srid_func_default_srid",
+			ndr:  "0101000000000000000000f03f0000000000000040",
+			opts: []Option{WithDefaultSRID(900913), WithSRIDFunc(remapSRID)},
+			want: geom.NewPoint(geom.XY).SetSRID(3857).MustSetCoords(geom.Coord{1, 2}),
+		},
+		{
+			name: "srid_func_rejected",
+			ndr:  "0101000000000000000000f03f0000000000000040",
+			opts: []Option{WithSRIDFunc(remapSRID)},
+			err:  errUnknownSRID,
+		},
+		{
+			name: "srid_func_nested",
+			ndr:  "0107000020e6100000010000000101000020110f0000000000000000f03f0000000000000040",
+			opts: []Option{WithSRIDFunc(remapSRID)},
+			want: geom.NewGeometryCollection().SetSRID(4326).MustPush(geom.NewPoint(geom.XY).SetSRID(3857).MustSetCoords(geom.Coord{1, 2})),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := hex.DecodeString(tc.ndr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := Unmarshal(data, tc.opts...)
+			if err != tc.err || !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Unmarshal(%s, ...) == %v, %v, want %v, %v", tc.ndr, got, err, tc.want, tc.err)
+			}
+		})
+	}
+}
+
+func TestMixedByteOrders(t *testing.T) {
+	// A little endian MultiPoint with SRID 4326 whose members are big endian,
+	// as produced by some PostGIS functions.
+	data := []byte{0x01, 0x04, 0x00, 0x00, 0x20, 0xe6, 0x10, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00}
+	for _, coords := range [][]float64{{1, 2}, {3, 4}} {
+		member, err := Marshal(geom.NewPointFlat(geom.XY, coords), XDR)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data = append(data, member...)
+	}
+	want := geom.NewMultiPointFlat(geom.XY, []float64{1, 2, 3, 4}).SetSRID(4326)
+	if got, err := Unmarshal(data); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal(%s) == %v, %v, want %v, <nil>", hex.EncodeToString(data), got, err, want)
+	}
+}
+
+func TestMaxDepth(t *testing.T) {
+	// GeometryCollections nested 2^20 deep.
+	deep := append(bytes.Repeat([]byte{0x01, 0x07, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00}, 1<<20), 0x01, 0x07, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00)
+	for _, tc := range []struct {
+		opts []Option
+		err  error
+	}{
+		{
+			err: wkbcommon.ErrTooDeep{Limit: wkbcommon.DefaultLimits.MaxDepth},
+		},
+		{
+			opts: []Option{WithLimits(wkbcommon.Limits{MaxDepth: 5})},
+			err:  wkbcommon.ErrTooDeep{Limit: 5},
+		},
+		{
+			opts: []Option{WithLimits(wkbcommon.NoLimits)},
+			err:  wkbcommon.ErrTooDeep{Limit: wkbcommon.HardMaxDepth},
+		},
+	} {
+		if _, err := Unmarshal(deep, tc.opts...); !reflect.DeepEqual(err, tc.err) {
+			t.Errorf("Unmarshal(<%d nested GeometryCollections>, ...) == _, %v, want %v", 1<<20, err, tc.err)
+		}
+	}
+}
+
+func TestEncoder(t *testing.T) {
+	var b bytes.Buffer
+	b.WriteString("frame")
+	e := NewEncoder(&b, WithByteOrder(XDR), WithDefaultSRID(4326))
+	want := []byte("frame")
+	for _, g := range []geom.T{
+		geom.NewPointFlat(geom.XY, []float64{1, 2}),
+		geom.NewLineStringFlat(geom.XYZ, []float64{1, 2, 3, 4, 5, 6}).SetSRID(3857),
+		geom.NewGeometryCollection().MustPush(geom.NewPointFlat(geom.XYM, []float64{1, 2, 3})),
+	} {
+		if err := e.Encode(g); err != nil {
+			t.Fatal(err)
+		}
+		data, err := Marshal(g, XDR, WithDefaultSRID(4326))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want = append(want, data...)
+	}
+	if !bytes.Equal(b.Bytes(), want) {
+		t.Errorf("got %s, want %s", hex.EncodeToString(b.Bytes()), hex.EncodeToString(want))
+	}
+	n := b.Len()
+	if err := NewEncoder(&b, WithSRIDPolicy(SRIDRequired)).Encode(geom.NewPointFlat(geom.XY, []float64{1, 2})); err != (ErrMissingSRID{}) {
+		t.Errorf("Encode(...) == %v, want %v", err, ErrMissingSRID{})
+	}
+	if b.Len() != n {
+		t.Errorf("Encode(...) wrote %d bytes on error, want 0", b.Len()-n)
+	}
+}
+
+func TestAppendNestedSRIDs(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		g    geom.T
+		want string
+	}{
+		{
+			name: "multi_polygon",
+			g:    geom.NewMultiPolygonFlat(geom.XY, []float64{0, 0, 1, 0, 0, 1, 0, 0}, [][]int{{8}}).SetSRID(4326),
+			want: "0106000020e6100000010000000103000000010000000400000000000000000000000000000000000000000000000000f03f00000000000000000000000000000000000000000000f03f00000000000000000000000000000000",
+		},
+		{
+			name: "geometry_collection_same_srid",
+			g:    geom.NewGeometryCollection().MustPush(geom.NewPointFlat(geom.XY, []float64{1, 2}).SetSRID(4326)).SetSRID(4326),
+			want: "0107000020e6100000010000000101000000000000000000f03f0000000000000040",
+		},
+		{
+			name: "geometry_collection_different_srid",
+			g:    geom.NewGeometryCollection().MustPush(geom.NewPointFlat(geom.XY, []float64{1, 2}).SetSRID(3857)).SetSRID(4326),
+			want: "0107000020e6100000010000000101000020110f0000000000000000f03f0000000000000040",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Append(nil, tc.g)
+			if err != nil || hex.EncodeToString(got) != tc.want {
+				t.Errorf("Append(nil, %v) == %s, %v, want %s, <nil>", tc.g, hex.EncodeToString(got), err, tc.want)
+			}
+		})
+	}
+}
+
+func TestAppendAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocations are not counted accurately with the race detector")
+	}
+	for _, g := range []geom.T{
+		geom.NewPointFlat(geom.XY, []float64{1, 2}).SetSRID(4326),
+		geom.NewLineStringFlat(geom.XYZ, []float64{1, 2, 3, 4, 5, 6}),
+		geom.NewMultiPointFlat(geom.XY, []float64{1, 2, 3, 4}),
+		geom.NewMultiLineStringFlat(geom.XY, []float64{1, 2, 3, 4, 5, 6, 7, 8}, []int{4, 8}),
+		geom.NewMultiPolygonFlat(geom.XY, []float64{0, 0, 1, 0, 0, 1, 0, 0, 2, 2, 3, 2, 2, 3, 2, 2}, [][]int{{8}, {16}}),
+		geom.NewGeometryCollection().MustPush(
+			geom.NewPointFlat(geom.XY, []float64{1, 2}),
+			geom.NewMultiPolygonFlat(geom.XY, []float64{0, 0, 1, 0, 0, 1, 0, 0}, [][]int{{8}}),
+		),
+		testdata.CurvesAndSurfaces(geom.XYZ)[3],
+		testdata.CurvesAndSurfaces(geom.XYZ)[4],
+	} {
+		dst := make([]byte, 0, 1024)
+		if allocs := testing.AllocsPerRun(100, func() {
+			if _, err := Append(dst, g); err != nil {
+				t.Fatal(err)
+			}
+		}); allocs != 0 {
+			t.Errorf("Append(dst, %v) made %v allocations, want 0", g, allocs)
+		}
+	}
+}
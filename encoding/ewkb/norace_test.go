@@ -0,0 +1,8 @@
+//go:build !race
+// +build !race
+
+package ewkb
+
+// raceEnabled is true if the race detector is enabled. The race detector
+// causes extra allocations.
+const raceEnabled = false
@@ -0,0 +1,106 @@
+package ewkb
+
+import (
+	"encoding/binary"
+
+	"github.com/twpayne/go-geom/encoding/wkbcommon"
+)
+
+// An Option configures encoding or decoding. Options that do not apply to an
+// operation are ignored, so the same options can be shared between Marshal
+// and Unmarshal.
+type Option func(*options)
+
+type options struct {
+	acceptWKB   bool
+	byteOrder   binary.ByteOrder
+	defaultSRID int
+	limits      wkbcommon.Limits
+	sridFunc    func(int) (int, error)
+	sridPolicy  SRIDPolicy
+}
+
+// An SRIDPolicy determines whether SRIDs are encoded and required. It only
+// applies to the outermost geometry: the members of multi-geometries and
+// surfaces are encoded without SRIDs, and the members of geometry collections
+// are encoded with an SRID only if it is non-zero and differs from the SRID
+// of the collection, unless the policy is SRIDNever.
+type SRIDPolicy int
+
+const (
+	// SRIDIfNonZero encodes the SRID only if it is non-zero, and decodes
+	// geometries with or without SRIDs. This is the default.
+	SRIDIfNonZero SRIDPolicy = iota
+	// SRIDAlways always encodes the SRID, even if it is zero.
+	SRIDAlways
+	// SRIDNever never encodes SRIDs, and ignores SRIDs when decoding.
+	SRIDNever
+	// SRIDRequired returns an ErrMissingSRID when encoding a geometry with a
+	// zero SRID or decoding a geometry without an SRID.
+	SRIDRequired
+)
+
+var defaultOptions = newOptions(nil)
+
+func newOptions(opts []Option) options {
+	o := options{
+		byteOrder: NDR,
+		limits:    wkbcommon.DefaultLimits,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithAcceptWKB sets whether plain ISO WKB, which encodes Z and M ordinates
+// by adding 1000, 2000, or 3000 to the geometry type, is accepted when
+// decoding. 2D WKB is always accepted as it is also valid EWKB.
+func WithAcceptWKB(accept bool) Option {
+	return func(o *options) {
+		o.acceptWKB = accept
+	}
+}
+
+// WithByteOrder sets the byte order used by Append and Encoder. The default is
+// NDR. Marshal and Write take the byte order as an argument and ignore it.
+func WithByteOrder(byteOrder binary.ByteOrder) Option {
+	return func(o *options) {
+		o.byteOrder = byteOrder
+	}
+}
+
+// WithDefaultSRID sets the SRID that is used in place of a zero SRID when
+// encoding, and that is assigned to geometries without an SRID when decoding.
+func WithDefaultSRID(srid int) Option {
+	return func(o *options) {
+		o.defaultSRID = srid
+	}
+}
+
+// WithLimits sets the limits on the size of decoded geometries. The default
+// is wkbcommon.DefaultLimits.
+func WithLimits(limits wkbcommon.Limits) Option {
+	return func(o *options) {
+		o.limits = limits
+	}
+}
+
+// WithSRIDFunc sets a function that is called with the SRID of each decoded
+// geometry and returns the SRID to assign to it, or an error to reject it. It
+// allows SRIDs to be normalized, for example mapping 900913 to 3857, or
+// validated in one place. f is called with the SRID of the outermost geometry,
+// after WithDefaultSRID is applied, and with the SRID of each member that has
+// its own SRID.
+func WithSRIDFunc(f func(srid int) (int, error)) Option {
+	return func(o *options) {
+		o.sridFunc = f
+	}
+}
+
+// WithSRIDPolicy sets the policy for encoding and decoding SRIDs.
+func WithSRIDPolicy(policy SRIDPolicy) Option {
+	return func(o *options) {
+		o.sridPolicy = policy
+	}
+}
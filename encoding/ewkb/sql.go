@@ -60,13 +60,23 @@ type GeometryCollection struct {
 	*geom.GeometryCollection
 }
 
-// Scan scans from a []byte.
+// A Geometry is an EWKB-encoded geometry of any type that implements the
+// sql.Scanner and driver.Valuer interfaces. It is useful for columns that may
+// contain geometries of different types.
+type Geometry struct {
+	geom.T
+}
+
+// Scan scans from a []byte or string containing binary or hex EWKB.
 func (p *Point) Scan(src interface{}) error {
 	if src == nil {
 		p.Point = nil
 		return nil
 	}
-	b, ok := src.([]byte)
+	b, ok, err := wkbcommon.SQLBytes(src)
+	if err != nil {
+		return err
+	}
 	if !ok {
 		return ErrExpectedByteSlice{Value: src}
 	}
@@ -95,13 +105,16 @@ func (p *Point) Value() (driver.Value, error) {
 	return value(p.Point)
 }
 
-// Scan scans from a []byte.
+// Scan scans from a []byte or string containing binary or hex EWKB.
 func (ls *LineString) Scan(src interface{}) error {
 	if src == nil {
 		ls.LineString = nil
 		return nil
 	}
-	b, ok := src.([]byte)
+	b, ok, err := wkbcommon.SQLBytes(src)
+	if err != nil {
+		return err
+	}
 	if !ok {
 		return ErrExpectedByteSlice{Value: src}
 	}
@@ -130,13 +143,16 @@ func (ls *LineString) Value() (driver.Value, error) {
 	return value(ls.LineString)
 }
 
-// Scan scans from a []byte.
+// Scan scans from a []byte or string containing binary or hex EWKB.
 func (p *Polygon) Scan(src interface{}) error {
 	if src == nil {
 		p.Polygon = nil
 		return nil
 	}
-	b, ok := src.([]byte)
+	b, ok, err := wkbcommon.SQLBytes(src)
+	if err != nil {
+		return err
+	}
 	if !ok {
 		return ErrExpectedByteSlice{Value: src}
 	}
@@ -165,13 +181,16 @@ func (p *Polygon) Value() (driver.Value, error) {
 	return value(p.Polygon)
 }
 
-// Scan scans from a []byte.
+// Scan scans from a []byte or string containing binary or hex EWKB.
 func (mp *MultiPoint) Scan(src interface{}) error {
 	if src == nil {
 		mp.MultiPoint = nil
 		return nil
 	}
-	b, ok := src.([]byte)
+	b, ok, err := wkbcommon.SQLBytes(src)
+	if err != nil {
+		return err
+	}
 	if !ok {
 		return ErrExpectedByteSlice{Value: src}
 	}
@@ -200,13 +219,16 @@ func (mp *MultiPoint) Value() (driver.Value, error) {
 	return value(mp.MultiPoint)
 }
 
-// Scan scans from a []byte.
+// Scan scans from a []byte or string containing binary or hex EWKB.
 func (mls *MultiLineString) Scan(src interface{}) error {
 	if src == nil {
 		mls.MultiLineString = nil
 		return nil
 	}
-	b, ok := src.([]byte)
+	b, ok, err := wkbcommon.SQLBytes(src)
+	if err != nil {
+		return err
+	}
 	if !ok {
 		return ErrExpectedByteSlice{Value: src}
 	}
@@ -235,13 +257,16 @@ func (mls *MultiLineString) Value() (driver.Value, error) {
 	return value(mls.MultiLineString)
 }
 
-// Scan scans from a []byte.
+// Scan scans from a []byte or string containing binary or hex EWKB.
 func (mp *MultiPolygon) Scan(src interface{}) error {
 	if src == nil {
 		mp.MultiPolygon = nil
 		return nil
 	}
-	b, ok := src.([]byte)
+	b, ok, err := wkbcommon.SQLBytes(src)
+	if err != nil {
+		return err
+	}
 	if !ok {
 		return ErrExpectedByteSlice{Value: src}
 	}
@@ -270,13 +295,16 @@ func (mp *MultiPolygon) Value() (driver.Value, error) {
 	return value(mp.MultiPolygon)
 }
 
-// Scan scans from a []byte.
+// Scan scans from a []byte or string containing binary or hex EWKB.
 func (gc *GeometryCollection) Scan(src interface{}) error {
 	if src == nil {
 		gc.GeometryCollection = nil
 		return nil
 	}
-	b, ok := src.([]byte)
+	b, ok, err := wkbcommon.SQLBytes(src)
+	if err != nil {
+		return err
+	}
 	if !ok {
 		return ErrExpectedByteSlice{Value: src}
 	}
@@ -305,6 +333,38 @@ func (gc *GeometryCollection) Value() (driver.Value, error) {
 	return value(gc.GeometryCollection)
 }
 
+// Scan scans from a []byte or string containing binary or hex EWKB.
+func (g *Geometry) Scan(src interface{}) error {
+	if src == nil {
+		g.T = nil
+		return nil
+	}
+	b, ok, err := wkbcommon.SQLBytes(src)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrExpectedByteSlice{Value: src}
+	}
+	if g.T, err = Unmarshal(b); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Valid returns true if g has a value.
+func (g *Geometry) Valid() bool {
+	return g != nil && g.T != nil
+}
+
+// Value returns the EWKB encoding of g.
+func (g *Geometry) Value() (driver.Value, error) {
+	if g.T == nil {
+		return nil, nil
+	}
+	return value(g.T)
+}
+
 func value(g geom.T) (driver.Value, error) {
 	b := &bytes.Buffer{}
 	if err := Write(b, NDR, g); err != nil {
@@ -0,0 +1,46 @@
+//go:build go1.18
+// +build go1.18
+
+package ewkb
+
+import (
+	"database/sql/driver"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/wkbcommon"
+)
+
+// A Geom is a EWKB-encoded geometry of type T that implements the sql.Scanner
+// and driver.Valuer interfaces. Scanning a geometry of any other type
+// returns a wkbcommon.ErrUnexpectedType.
+type Geom[T geom.T] struct {
+	Geom T
+}
+
+// Scan scans from a []byte or string containing binary or hex EWKB.
+func (g *Geom[T]) Scan(src interface{}) error {
+	got, ok, err := wkbcommon.SQLGeom[T](src, func(data []byte) (geom.T, error) {
+		return Unmarshal(data)
+	})
+	if !ok {
+		return ErrExpectedByteSlice{Value: src}
+	}
+	if err != nil {
+		return err
+	}
+	g.Geom = got
+	return nil
+}
+
+// Valid returns true if g has a value.
+func (g *Geom[T]) Valid() bool {
+	return g != nil && !wkbcommon.IsZero(g.Geom)
+}
+
+// Value returns the EWKB encoding of g.
+func (g *Geom[T]) Value() (driver.Value, error) {
+	if wkbcommon.IsZero(g.Geom) {
+		return nil, nil
+	}
+	return value(g.Geom)
+}
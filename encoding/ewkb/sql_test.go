@@ -22,6 +22,7 @@ var _ = []interface {
 	&MultiLineString{},
 	&MultiPolygon{},
 	&GeometryCollection{},
+	&Geometry{},
 }
 
 func TestPointScanAndValue(t *testing.T) {
@@ -57,3 +58,62 @@ func TestPointScanAndValue(t *testing.T) {
 		}
 	}
 }
+
+func TestGeometryScanAndValue(t *testing.T) {
+	point := geom.NewPoint(geom.XY).SetSRID(4326).MustSetCoords(geom.Coord{1, 2})
+	for _, tc := range []struct {
+		name string
+		src  interface{}
+		want geom.T
+	}{
+		{
+			name: "null",
+			src:  nil,
+			want: nil,
+		},
+		{
+			name: "binary",
+			src:  geomtest.MustHexDecode("0101000020e6100000000000000000f03f0000000000000040"),
+			want: point,
+		},
+		{
+			name: "hex_bytes",
+			src:  []byte("0101000020E6100000000000000000F03F0000000000000040"),
+			want: point,
+		},
+		{
+			name: "hex_string",
+			src:  "0101000020e6100000000000000000f03f0000000000000040",
+			want: point,
+		},
+		{
+			name: "bytea_hex",
+			src:  `\x0101000020e6100000000000000000f03f0000000000000040`,
+			want: point,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var g Geometry
+			if err := g.Scan(tc.src); err != nil || !reflect.DeepEqual(g.T, tc.want) {
+				t.Errorf("g.Scan(%v) == %v, g.T == %v, want <nil>, %v", tc.src, err, g.T, tc.want)
+			}
+			if got := g.Valid(); got != (tc.want != nil) {
+				t.Errorf("g.Valid() == %t, want %t", got, tc.want != nil)
+			}
+		})
+	}
+
+	var g Geometry
+	for _, src := range []interface{}{1, "0z", []byte{0x02}} {
+		if err := g.Scan(src); err == nil {
+			t.Errorf("g.Scan(%v) == <nil>, want !<nil>", src)
+		}
+	}
+	if got, err := (&Geometry{}).Value(); err != nil || got != nil {
+		t.Errorf("(&Geometry{}).Value() == %v, %v, want <nil>, <nil>", got, err)
+	}
+	want := geomtest.MustHexDecode("0101000020e6100000000000000000f03f0000000000000040")
+	if got, err := (&Geometry{T: point}).Value(); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("(&Geometry{T: %v}).Value() == %v, %v, want %v, <nil>", point, got, err, want)
+	}
+}
@@ -5,6 +5,7 @@ package ewkbhex
 import (
 	"encoding/binary"
 	"encoding/hex"
+	"strings"
 
 	"github.com/twpayne/go-geom"
 	"github.com/twpayne/go-geom/encoding/ewkb"
@@ -17,20 +18,41 @@ var (
 	NDR = ewkb.NDR
 )
 
-// Encode encodes an arbitrary geometry to a string.
-func Encode(g geom.T, byteOrder binary.ByteOrder) (string, error) {
-	ewkb, err := ewkb.Marshal(g, byteOrder)
+// Encode encodes an arbitrary geometry to a string of lower case hexadecimal
+// digits.
+func Encode(g geom.T, byteOrder binary.ByteOrder, opts ...ewkb.Option) (string, error) {
+	ewkb, err := ewkb.Marshal(g, byteOrder, opts...)
 	if err != nil {
 		return "", err
 	}
 	return hex.EncodeToString(ewkb), nil
 }
 
-// Decode decodes an arbitrary geometry from a string.
-func Decode(s string) (geom.T, error) {
+// EncodeUpper encodes an arbitrary geometry to a string of upper case
+// hexadecimal digits, as used by PostGIS.
+func EncodeUpper(g geom.T, byteOrder binary.ByteOrder, opts ...ewkb.Option) (string, error) {
+	s, err := Encode(g, byteOrder, opts...)
+	if err != nil {
+		return "", err
+	}
+	return strings.ToUpper(s), nil
+}
+
+// Decode decodes an arbitrary geometry from a string of upper or lower case
+// hexadecimal digits.
+func Decode(s string, opts ...ewkb.Option) (geom.T, error) {
+	data, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return ewkb.Unmarshal(data, opts...)
+}
+
+// DecodeStub returns the Stub of the geometry in s. See ewkb.UnmarshalStub.
+func DecodeStub(s string, opts ...ewkb.Option) (*geom.Stub, error) {
 	data, err := hex.DecodeString(s)
 	if err != nil {
 		return nil, err
 	}
-	return ewkb.Unmarshal(data)
+	return ewkb.UnmarshalStub(data, opts...)
 }
@@ -139,6 +139,24 @@ func test(t *testing.T, g geom.T, xdr string, ndr string) {
 	}
 }
 
+func TestOptionsAndCase(t *testing.T) {
+	g := geom.NewPoint(geom.XY).SetSRID(4326).MustSetCoords(geom.Coord{1, 2})
+	upper := "0101000020E6100000000000000000F03F0000000000000040"
+	if got, err := EncodeUpper(g, wkbcommon.NDR); err != nil || got != upper {
+		t.Errorf("EncodeUpper(%#v, NDR) == %s, %v, want %s, nil", g, got, err, upper)
+	}
+	if got, err := Decode(upper); err != nil || !reflect.DeepEqual(got, g) {
+		t.Errorf("Decode(%s) == %#v, %v, want %#v, nil", upper, got, err, g)
+	}
+	want := "0101000000000000000000f03f0000000000000040"
+	if got, err := Encode(g, wkbcommon.NDR, ewkb.WithSRIDPolicy(ewkb.SRIDNever)); err != nil || got != want {
+		t.Errorf("Encode(%#v, NDR, WithSRIDPolicy(SRIDNever)) == %s, %v, want %s, nil", g, got, err, want)
+	}
+	if got, err := Decode(want, ewkb.WithDefaultSRID(4326)); err != nil || !reflect.DeepEqual(got, g) {
+		t.Errorf("Decode(%s, WithDefaultSRID(4326)) == %#v, %v, want %#v, nil", want, got, err, g)
+	}
+}
+
 func decodeString(s string) []byte {
 	data, err := hex.DecodeString(s)
 	if err != nil {
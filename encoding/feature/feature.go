@@ -0,0 +1,11 @@
+// Package feature contains types that are shared by the readers of formats
+// that contain features, i.e. geometries with identifiers and properties.
+package feature
+
+// A Filter reports whether the feature with id and properties should be read.
+// Readers call it before decoding the feature's geometry, and skip the
+// features that it rejects, so rejecting a feature avoids the cost of
+// decoding its geometry. id is the identifier of the feature in its format,
+// formatted as a string, or empty if the format does not identify features.
+// properties must not be modified.
+type Filter func(id string, properties map[string]interface{}) bool
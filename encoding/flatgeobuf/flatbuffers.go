@@ -0,0 +1,382 @@
+package flatgeobuf
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// This file implements the subset of FlatBuffers needed to encode and decode
+// FlatGeobuf headers and features. See https://flatbuffers.dev/internals/.
+
+var errInvalidFlatBuffer = errors.New("flatgeobuf: invalid flatbuffer")
+
+// A fbBuilder builds a flatbuffer front to back. Tables are preceded by their
+// vtables and followed by the strings, vectors, and tables that they
+// reference, so that all offsets are positive. Values are aligned relative to
+// the start of the buffer, as required by FlatBuffers verifiers.
+type fbBuilder struct {
+	buf []byte
+}
+
+// A fbField is a field of a table under construction. Scalar fields have a
+// size of 1, 2, 4, or 8 bytes and a value. Offset fields refer to the
+// string, vector, or table written by child.
+type fbField struct {
+	id    int
+	size  int
+	value uint64
+	child func(b *fbBuilder) int
+}
+
+func fbBool(id int, value bool) fbField {
+	if value {
+		return fbField{id: id, size: 1, value: 1}
+	}
+	return fbField{id: id, size: 1}
+}
+
+func fbUint8(id int, value uint8) fbField {
+	return fbField{id: id, size: 1, value: uint64(value)}
+}
+
+func fbUint16(id int, value uint16) fbField {
+	return fbField{id: id, size: 2, value: uint64(value)}
+}
+
+func fbInt32(id int, value int32) fbField {
+	return fbField{id: id, size: 4, value: uint64(uint32(value))}
+}
+
+func fbUint64(id int, value uint64) fbField {
+	return fbField{id: id, size: 8, value: value}
+}
+
+func fbOffset(id int, child func(b *fbBuilder) int) fbField {
+	return fbField{id: id, size: 4, child: child}
+}
+
+func fbString(id int, s string) fbField {
+	return fbOffset(id, func(b *fbBuilder) int {
+		return b.string(s)
+	})
+}
+
+// finish writes the root table with fields and returns the buffer.
+func (b *fbBuilder) finish(fields []fbField) []byte {
+	b.buf = append(b.buf[:0], 0, 0, 0, 0)
+	root := b.table(fields)
+	binary.LittleEndian.PutUint32(b.buf, uint32(root))
+	return b.buf
+}
+
+// pad pads b to a multiple of align.
+func (b *fbBuilder) pad(align int) {
+	for len(b.buf)%align != 0 {
+		b.buf = append(b.buf, 0)
+	}
+}
+
+// uint32At writes value at pos.
+func (b *fbBuilder) uint32At(pos int, value uint32) {
+	binary.LittleEndian.PutUint32(b.buf[pos:], value)
+}
+
+// table writes a table with fields and the values that they refer to, and
+// returns the position of the table.
+func (b *fbBuilder) table(fields []fbField) int {
+	numFields := 0
+	for _, f := range fields {
+		if f.id+1 > numFields {
+			numFields = f.id + 1
+		}
+	}
+	b.pad(2)
+	vtablePos := len(b.buf)
+	vtable := make([]uint16, 2+numFields)
+	b.buf = append(b.buf, make([]byte, 2*len(vtable))...)
+	b.pad(4)
+	tablePos := len(b.buf)
+	b.buf = append(b.buf, 0, 0, 0, 0)
+	b.uint32At(tablePos, uint32(int32(tablePos-vtablePos)))
+	fieldPos := make([]int, len(fields))
+	for i, f := range fields {
+		b.pad(f.size)
+		fieldPos[i] = len(b.buf)
+		vtable[2+f.id] = uint16(fieldPos[i] - tablePos)
+		var value [8]byte
+		binary.LittleEndian.PutUint64(value[:], f.value)
+		b.buf = append(b.buf, value[:f.size]...)
+	}
+	vtable[0] = uint16(2 * len(vtable))
+	vtable[1] = uint16(len(b.buf) - tablePos)
+	for i, v := range vtable {
+		binary.LittleEndian.PutUint16(b.buf[vtablePos+2*i:], v)
+	}
+	for i, f := range fields {
+		if f.child != nil {
+			childPos := f.child(b)
+			b.uint32At(fieldPos[i], uint32(childPos-fieldPos[i]))
+		}
+	}
+	return tablePos
+}
+
+// string writes s and returns its position.
+func (b *fbBuilder) string(s string) int {
+	b.pad(4)
+	pos := len(b.buf)
+	b.buf = append(b.buf, 0, 0, 0, 0)
+	b.uint32At(pos, uint32(len(s)))
+	b.buf = append(b.buf, s...)
+	b.buf = append(b.buf, 0)
+	return pos
+}
+
+// bytes writes a vector of bytes and returns its position.
+func (b *fbBuilder) bytes(data []byte) int {
+	b.pad(4)
+	pos := len(b.buf)
+	b.buf = append(b.buf, 0, 0, 0, 0)
+	b.uint32At(pos, uint32(len(data)))
+	b.buf = append(b.buf, data...)
+	return pos
+}
+
+// uint32s writes a vector of uint32s and returns its position.
+func (b *fbBuilder) uint32s(values []uint32) int {
+	b.pad(4)
+	pos := len(b.buf)
+	b.buf = append(b.buf, 0, 0, 0, 0)
+	b.uint32At(pos, uint32(len(values)))
+	for _, v := range values {
+		b.buf = append(b.buf, 0, 0, 0, 0)
+		b.uint32At(len(b.buf)-4, v)
+	}
+	return pos
+}
+
+// float64s writes a vector of float64s and returns its position. The
+// elements are aligned to eight bytes.
+func (b *fbBuilder) float64s(values []float64) int {
+	b.pad(4)
+	if len(b.buf)%8 == 0 {
+		b.buf = append(b.buf, 0, 0, 0, 0)
+	}
+	pos := len(b.buf)
+	b.buf = append(b.buf, 0, 0, 0, 0)
+	b.uint32At(pos, uint32(len(values)))
+	var value [8]byte
+	for _, v := range values {
+		binary.LittleEndian.PutUint64(value[:], math.Float64bits(v))
+		b.buf = append(b.buf, value[:]...)
+	}
+	return pos
+}
+
+// tables writes a vector of tables, each with the fields in fieldss, and
+// returns its position.
+func (b *fbBuilder) tables(fieldss [][]fbField) int {
+	b.pad(4)
+	pos := len(b.buf)
+	b.buf = append(b.buf, make([]byte, 4+4*len(fieldss))...)
+	b.uint32At(pos, uint32(len(fieldss)))
+	for i, fields := range fieldss {
+		elemPos := pos + 4 + 4*i
+		tablePos := b.table(fields)
+		b.uint32At(elemPos, uint32(tablePos-elemPos))
+	}
+	return pos
+}
+
+// A fbDecoder decodes a flatbuffer. Out of range accesses set err, which is
+// sticky, and return zero values.
+type fbDecoder struct {
+	buf []byte
+	err error
+}
+
+// A fbTable is a table in a flatbuffer.
+type fbTable struct {
+	pos        int
+	vtable     int
+	vtableSize int
+}
+
+// check returns whether the n bytes at pos are in range, setting d.err if
+// they are not.
+func (d *fbDecoder) check(pos int, n uint64) bool {
+	if d.err != nil {
+		return false
+	}
+	if pos < 0 || uint64(pos)+n > uint64(len(d.buf)) {
+		d.err = errInvalidFlatBuffer
+		return false
+	}
+	return true
+}
+
+func (d *fbDecoder) uint16(pos int) uint16 {
+	if !d.check(pos, 2) {
+		return 0
+	}
+	return binary.LittleEndian.Uint16(d.buf[pos:])
+}
+
+func (d *fbDecoder) uint32(pos int) uint32 {
+	if !d.check(pos, 4) {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(d.buf[pos:])
+}
+
+func (d *fbDecoder) uint64(pos int) uint64 {
+	if !d.check(pos, 8) {
+		return 0
+	}
+	return binary.LittleEndian.Uint64(d.buf[pos:])
+}
+
+// root returns the root table.
+func (d *fbDecoder) root() fbTable {
+	return d.table(int(d.uint32(0)))
+}
+
+// table returns the table at pos.
+func (d *fbDecoder) table(pos int) fbTable {
+	vtable := pos - int(int32(d.uint32(pos)))
+	vtableSize := int(d.uint16(vtable))
+	if !d.check(vtable, uint64(vtableSize)) {
+		return fbTable{}
+	}
+	return fbTable{
+		pos:        pos,
+		vtable:     vtable,
+		vtableSize: vtableSize,
+	}
+}
+
+// field returns the position of field id of t, or zero if it is absent.
+func (d *fbDecoder) field(t fbTable, id int) int {
+	if d.err != nil || 4+2*id >= t.vtableSize {
+		return 0
+	}
+	offset := int(d.uint16(t.vtable + 4 + 2*id))
+	if offset == 0 {
+		return 0
+	}
+	return t.pos + offset
+}
+
+func (d *fbDecoder) boolField(t fbTable, id int, def bool) bool {
+	pos := d.field(t, id)
+	if pos == 0 || !d.check(pos, 1) {
+		return def
+	}
+	return d.buf[pos] != 0
+}
+
+func (d *fbDecoder) uint8Field(t fbTable, id int, def uint8) uint8 {
+	pos := d.field(t, id)
+	if pos == 0 || !d.check(pos, 1) {
+		return def
+	}
+	return d.buf[pos]
+}
+
+func (d *fbDecoder) uint16Field(t fbTable, id int, def uint16) uint16 {
+	if pos := d.field(t, id); pos != 0 {
+		return d.uint16(pos)
+	}
+	return def
+}
+
+func (d *fbDecoder) int32Field(t fbTable, id int, def int32) int32 {
+	if pos := d.field(t, id); pos != 0 {
+		return int32(d.uint32(pos))
+	}
+	return def
+}
+
+func (d *fbDecoder) uint64Field(t fbTable, id int, def uint64) uint64 {
+	if pos := d.field(t, id); pos != 0 {
+		return d.uint64(pos)
+	}
+	return def
+}
+
+// tableField returns the table referenced by field id of t and whether it is
+// present.
+func (d *fbDecoder) tableField(t fbTable, id int) (fbTable, bool) {
+	pos := d.field(t, id)
+	if pos == 0 {
+		return fbTable{}, false
+	}
+	table := d.table(pos + int(d.uint32(pos)))
+	return table, d.err == nil
+}
+
+// vector returns the position of the first element and the length of the
+// vector of elements of elemSize bytes referenced by field id of t.
+func (d *fbDecoder) vector(t fbTable, id int, elemSize uint64) (int, int) {
+	pos := d.field(t, id)
+	if pos == 0 {
+		return 0, 0
+	}
+	vector := pos + int(d.uint32(pos))
+	n := d.uint32(vector)
+	if !d.check(vector+4, uint64(n)*elemSize) {
+		return 0, 0
+	}
+	return vector + 4, int(n)
+}
+
+func (d *fbDecoder) stringField(t fbTable, id int) string {
+	pos, n := d.vector(t, id, 1)
+	return string(d.buf[pos : pos+n])
+}
+
+func (d *fbDecoder) bytesField(t fbTable, id int) []byte {
+	pos, n := d.vector(t, id, 1)
+	if n == 0 {
+		return nil
+	}
+	return d.buf[pos : pos+n]
+}
+
+func (d *fbDecoder) uint32sField(t fbTable, id int) []uint32 {
+	pos, n := d.vector(t, id, 4)
+	if n == 0 {
+		return nil
+	}
+	values := make([]uint32, n)
+	for i := range values {
+		values[i] = binary.LittleEndian.Uint32(d.buf[pos+4*i:])
+	}
+	return values
+}
+
+func (d *fbDecoder) float64sField(t fbTable, id int) []float64 {
+	pos, n := d.vector(t, id, 8)
+	if n == 0 {
+		return nil
+	}
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = math.Float64frombits(binary.LittleEndian.Uint64(d.buf[pos+8*i:]))
+	}
+	return values
+}
+
+func (d *fbDecoder) tablesField(t fbTable, id int) []fbTable {
+	pos, n := d.vector(t, id, 4)
+	if n == 0 {
+		return nil
+	}
+	tables := make([]fbTable, 0, n)
+	for i := 0; i < n; i++ {
+		elemPos := pos + 4*i
+		tables = append(tables, d.table(elemPos+int(d.uint32(elemPos))))
+	}
+	return tables
+}
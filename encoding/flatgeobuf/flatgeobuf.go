@@ -0,0 +1,300 @@
+// Package flatgeobuf implements FlatGeobuf encoding and decoding of features.
+// See https://flatgeobuf.org/.
+//
+// A FlatGeobuf file consists of magic bytes, a header describing the
+// features, an optional packed Hilbert R-tree index of the features'
+// bounding boxes, and the features. The index allows the features that
+// intersect a bounding box to be read without reading the whole file, for
+// example from object storage using HTTP range requests. See
+// NewIndexedReader and HTTPReaderAt.
+//
+// Geometries are decoded as Points, LineStrings, Polygons, MultiPoints,
+// MultiLineStrings, MultiPolygons, and GeometryCollections. Curves, surfaces,
+// and T and TM values are not supported.
+package flatgeobuf
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/twpayne/go-geom"
+)
+
+// magic is the magic bytes at the start of a FlatGeobuf file. The last byte
+// is the patch version, which is ignored when decoding.
+var magic = [8]byte{'f', 'g', 'b', 3, 'f', 'g', 'b', 0}
+
+// maxHeaderSize is the maximum size of a header, in bytes.
+const maxHeaderSize = 10 << 20
+
+// defaultIndexNodeSize is the default number of children of each node of the
+// index.
+const defaultIndexNodeSize = 16
+
+var (
+	errHeaderTooLarge  = errors.New("flatgeobuf: header too large")
+	errIndexOutOfRange = errors.New("flatgeobuf: feature index out of range")
+	errInvalidMagic    = errors.New("flatgeobuf: invalid magic bytes")
+	errNoIndex         = errors.New("flatgeobuf: no index")
+)
+
+// A GeometryType is a FlatGeobuf geometry type.
+type GeometryType byte
+
+// Geometry types.
+const (
+	Unknown GeometryType = iota
+	Point
+	LineString
+	Polygon
+	MultiPoint
+	MultiLineString
+	MultiPolygon
+	GeometryCollection
+)
+
+// A ColumnType is the type of the values in a column.
+type ColumnType byte
+
+// Column types. Byte, UByte, Bool, Short, UShort, Int, UInt, Long, ULong,
+// Float, and Double values are decoded as int8, uint8, bool, int16, uint16,
+// int32, uint32, int64, uint64, float32, and float64 values respectively,
+// String and JSON values as strings, DateTime values as time.Times, and
+// Binary values as []bytes.
+const (
+	Byte ColumnType = iota
+	UByte
+	Bool
+	Short
+	UShort
+	Int
+	UInt
+	Long
+	ULong
+	Float
+	Double
+	String
+	JSON
+	DateTime
+	Binary
+)
+
+// An ErrUnsupportedGeometryType is returned when decoding a geometry of an
+// unsupported type.
+type ErrUnsupportedGeometryType GeometryType
+
+func (e ErrUnsupportedGeometryType) Error() string {
+	return fmt.Sprintf("flatgeobuf: unsupported geometry type %d", byte(e))
+}
+
+// An ErrUnsupportedColumnType is returned when decoding a value of an
+// unsupported column type.
+type ErrUnsupportedColumnType ColumnType
+
+func (e ErrUnsupportedColumnType) Error() string {
+	return fmt.Sprintf("flatgeobuf: unsupported column type %d", byte(e))
+}
+
+// An ErrInvalidProperty is returned when encoding a property whose value
+// does not have the Go type of its column's type.
+type ErrInvalidProperty struct {
+	Name  string
+	Value interface{}
+}
+
+func (e ErrInvalidProperty) Error() string {
+	return fmt.Sprintf("flatgeobuf: invalid value for property %s: %T", e.Name, e.Value)
+}
+
+// A Column describes the values of a property.
+type Column struct {
+	Name        string
+	Type        ColumnType
+	Title       string
+	Description string
+	Width       int32 // -1 if unknown
+	Precision   int32 // -1 if unknown
+	Scale       int32 // -1 if unknown
+	Nullable    bool
+	Unique      bool
+	PrimaryKey  bool
+	Metadata    string
+}
+
+// A CRS describes a coordinate reference system.
+type CRS struct {
+	Org         string // for example EPSG, or the empty string for EPSG
+	Code        int32
+	Name        string
+	Description string
+	WKT         string
+	CodeString  string
+}
+
+// A Header describes the features in a file.
+type Header struct {
+	Name          string
+	Bounds        *geom.Bounds // nil if unknown
+	GeometryType  GeometryType // Unknown if the features have mixed types
+	Layout        geom.Layout
+	Columns       []Column
+	FeaturesCount uint64
+	IndexNodeSize uint16 // zero if there is no index
+	CRS           *CRS
+	Title         string
+	Description   string
+	Metadata      string
+}
+
+// A Feature is a geometry and its properties.
+type Feature struct {
+	Geom       geom.T // nil if the feature has no geometry
+	Properties map[string]interface{}
+}
+
+// decodeHeader decodes the header flatbuffer data.
+func decodeHeader(data []byte) (*Header, error) {
+	d := &fbDecoder{buf: data}
+	t := d.root()
+	h := &Header{
+		Name:          d.stringField(t, 0),
+		GeometryType:  GeometryType(d.uint8Field(t, 2, 0)),
+		Layout:        layout(d.boolField(t, 3, false), d.boolField(t, 4, false)),
+		FeaturesCount: d.uint64Field(t, 8, 0),
+		IndexNodeSize: d.uint16Field(t, 9, defaultIndexNodeSize),
+		Title:         d.stringField(t, 11),
+		Description:   d.stringField(t, 12),
+		Metadata:      d.stringField(t, 13),
+	}
+	if envelope := d.float64sField(t, 1); len(envelope) >= 4 {
+		h.Bounds = geom.NewBounds(geom.XY).Set(envelope[:4]...)
+	}
+	h.Columns = decodeColumns(d, d.tablesField(t, 7))
+	if crs, ok := d.tableField(t, 10); ok {
+		h.CRS = &CRS{
+			Org:         d.stringField(crs, 0),
+			Code:        d.int32Field(crs, 1, 0),
+			Name:        d.stringField(crs, 2),
+			Description: d.stringField(crs, 3),
+			WKT:         d.stringField(crs, 4),
+			CodeString:  d.stringField(crs, 5),
+		}
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	return h, nil
+}
+
+// decodeColumns decodes the Column tables ts.
+func decodeColumns(d *fbDecoder, ts []fbTable) []Column {
+	if ts == nil {
+		return nil
+	}
+	columns := make([]Column, 0, len(ts))
+	for _, t := range ts {
+		columns = append(columns, Column{
+			Name:        d.stringField(t, 0),
+			Type:        ColumnType(d.uint8Field(t, 1, 0)),
+			Title:       d.stringField(t, 2),
+			Description: d.stringField(t, 3),
+			Width:       d.int32Field(t, 4, -1),
+			Precision:   d.int32Field(t, 5, -1),
+			Scale:       d.int32Field(t, 6, -1),
+			Nullable:    d.boolField(t, 7, true),
+			Unique:      d.boolField(t, 8, false),
+			PrimaryKey:  d.boolField(t, 9, false),
+			Metadata:    d.stringField(t, 10),
+		})
+	}
+	return columns
+}
+
+// encodeHeader returns the header flatbuffer of h.
+func encodeHeader(h *Header) []byte {
+	var fields []fbField
+	if h.Name != "" {
+		fields = append(fields, fbString(0, h.Name))
+	}
+	if h.Bounds != nil {
+		envelope := []float64{h.Bounds.Min(0), h.Bounds.Min(1), h.Bounds.Max(0), h.Bounds.Max(1)}
+		fields = append(fields, fbOffset(1, func(b *fbBuilder) int {
+			return b.float64s(envelope)
+		}))
+	}
+	fields = append(fields, fbUint8(2, uint8(h.GeometryType)))
+	if h.Layout.ZIndex() != -1 {
+		fields = append(fields, fbBool(3, true))
+	}
+	if h.Layout.MIndex() != -1 {
+		fields = append(fields, fbBool(4, true))
+	}
+	if len(h.Columns) > 0 {
+		fields = append(fields, fbOffset(7, func(b *fbBuilder) int {
+			return b.tables(encodeColumns(h.Columns))
+		}))
+	}
+	fields = append(fields,
+		fbUint64(8, h.FeaturesCount),
+		fbUint16(9, h.IndexNodeSize),
+	)
+	if h.CRS != nil {
+		crs := h.CRS
+		fields = append(fields, fbOffset(10, func(b *fbBuilder) int {
+			crsFields := appendStrings(nil, 0, crs.Org)
+			crsFields = append(crsFields, fbInt32(1, crs.Code))
+			crsFields = appendStrings(crsFields, 2, crs.Name, crs.Description, crs.WKT, crs.CodeString)
+			return b.table(crsFields)
+		}))
+	}
+	fields = appendStrings(fields, 11, h.Title, h.Description, h.Metadata)
+	return (&fbBuilder{}).finish(fields)
+}
+
+// encodeColumns returns the fields of the Column tables of columns.
+func encodeColumns(columns []Column) [][]fbField {
+	fieldss := make([][]fbField, 0, len(columns))
+	for _, c := range columns {
+		fields := []fbField{
+			fbString(0, c.Name),
+			fbUint8(1, uint8(c.Type)),
+		}
+		fields = appendStrings(fields, 2, c.Title, c.Description)
+		fields = append(fields,
+			fbInt32(4, c.Width),
+			fbInt32(5, c.Precision),
+			fbInt32(6, c.Scale),
+			fbBool(7, c.Nullable),
+			fbBool(8, c.Unique),
+			fbBool(9, c.PrimaryKey),
+		)
+		fields = appendStrings(fields, 10, c.Metadata)
+		fieldss = append(fieldss, fields)
+	}
+	return fieldss
+}
+
+// appendStrings appends fields for the non-empty strings in ss, which have
+// consecutive ids starting at id, to fields.
+func appendStrings(fields []fbField, id int, ss ...string) []fbField {
+	for i, s := range ss {
+		if s != "" {
+			fields = append(fields, fbString(id+i, s))
+		}
+	}
+	return fields
+}
+
+// layout returns the layout of geometries with hasZ and hasM.
+func layout(hasZ, hasM bool) geom.Layout {
+	switch {
+	case hasZ && hasM:
+		return geom.XYZM
+	case hasZ:
+		return geom.XYZ
+	case hasM:
+		return geom.XYM
+	default:
+		return geom.XY
+	}
+}
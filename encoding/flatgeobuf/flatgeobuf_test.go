@@ -0,0 +1,626 @@
+package flatgeobuf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/conformance"
+)
+
+func TestRoundTrip(t *testing.T) {
+	columns := []Column{
+		{Name: "byte", Type: Byte, Width: -1, Precision: -1, Scale: -1, Nullable: true},
+		{Name: "ubyte", Type: UByte, Width: -1, Precision: -1, Scale: -1},
+		{Name: "bool", Type: Bool, Width: -1, Precision: -1, Scale: -1},
+		{Name: "short", Type: Short, Width: -1, Precision: -1, Scale: -1},
+		{Name: "ushort", Type: UShort, Width: -1, Precision: -1, Scale: -1},
+		{Name: "int", Type: Int, Width: -1, Precision: -1, Scale: -1, PrimaryKey: true, Unique: true},
+		{Name: "uint", Type: UInt, Width: -1, Precision: -1, Scale: -1},
+		{Name: "long", Type: Long, Width: -1, Precision: -1, Scale: -1},
+		{Name: "ulong", Type: ULong, Width: -1, Precision: -1, Scale: -1},
+		{Name: "float", Type: Float, Width: -1, Precision: -1, Scale: -1},
+		{Name: "double", Type: Double, Width: 10, Precision: 5, Scale: 2, Title: "Double", Description: "A double"},
+		{Name: "string", Type: String, Width: -1, Precision: -1, Scale: -1, Metadata: "{}"},
+		{Name: "json", Type: JSON, Width: -1, Precision: -1, Scale: -1},
+		{Name: "datetime", Type: DateTime, Width: -1, Precision: -1, Scale: -1},
+		{Name: "binary", Type: Binary, Width: -1, Precision: -1, Scale: -1},
+	}
+	for _, tc := range []struct {
+		name     string
+		header   *Header
+		features []*Feature
+		want     *Header
+	}{
+		{
+			name: "points",
+			header: &Header{
+				Name:        "points",
+				Columns:     columns,
+				Title:       "Points",
+				Description: "Some points",
+				Metadata:    `{"key":"value"}`,
+			},
+			features: []*Feature{
+				{
+					Geom: geom.NewPointFlat(geom.XY, []float64{1, 2}).SetSRID(4326),
+					Properties: map[string]interface{}{
+						"byte":     int8(-1),
+						"ubyte":    uint8(2),
+						"bool":     true,
+						"short":    int16(-3),
+						"ushort":   uint16(4),
+						"int":      int32(-5),
+						"uint":     uint32(6),
+						"long":     int64(-7),
+						"ulong":    uint64(8),
+						"float":    float32(9.5),
+						"double":   10.25,
+						"string":   "eleven",
+						"json":     `{"twelve":12}`,
+						"datetime": time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+						"binary":   []byte{14},
+					},
+				},
+				{
+					Geom: geom.NewPointEmpty(geom.XY).SetSRID(4326),
+					Properties: map[string]interface{}{
+						"int": int32(1),
+					},
+				},
+				{
+					Properties: map[string]interface{}{
+						"string": "no geometry",
+					},
+				},
+			},
+			want: &Header{
+				Name:          "points",
+				Bounds:        geom.NewBounds(geom.XY).Set(1, 2, 1, 2),
+				GeometryType:  Point,
+				Layout:        geom.XY,
+				Columns:       columns,
+				FeaturesCount: 3,
+				CRS:           &CRS{Org: "EPSG", Code: 4326},
+				Title:         "Points",
+				Description:   "Some points",
+				Metadata:      `{"key":"value"}`,
+			},
+		},
+		{
+			name:   "mixed",
+			header: &Header{},
+			features: []*Feature{
+				{Geom: geom.NewLineStringFlat(geom.XYZM, []float64{0, 0, 1, 2, 3, 4, 5, 6})},
+				{Geom: geom.NewPolygonFlat(geom.XYZM, []float64{0, 0, 0, 0, 4, 0, 0, 0, 4, 4, 0, 0, 0, 0, 0, 0, 1, 1, 0, 0, 1, 2, 0, 0, 2, 2, 0, 0, 1, 1, 0, 0}, []int{16, 32})},
+				{Geom: geom.NewPolygon(geom.XYZM)},
+				{Geom: geom.NewMultiPointFlat(geom.XYZM, []float64{1, 2, 3, 4, 5, 6, 7, 8})},
+				{Geom: geom.NewMultiLineStringFlat(geom.XYZM, []float64{1, 2, 3, 4, 5, 6, 7, 8}, []int{8})},
+				{Geom: geom.NewMultiLineStringFlat(geom.XYZM, []float64{1, 2, 3, 4, 5, 6, 7, 8}, []int{0, 8, 8})},
+				{Geom: geom.NewMultiPolygonFlat(geom.XYZM, []float64{
+					0, 0, 0, 0, 1, 0, 0, 0, 1, 1, 0, 0, 0, 0, 0, 0,
+					2, 2, 0, 0, 3, 2, 0, 0, 3, 3, 0, 0, 2, 2, 0, 0,
+				}, [][]int{{16}, {32}})},
+				{Geom: geom.NewGeometryCollection().MustPush(
+					geom.NewPointFlat(geom.XYZM, []float64{1, 2, 3, 4}),
+					geom.NewGeometryCollection().MustPush(
+						geom.NewLineStringFlat(geom.XYZM, []float64{5, 6, 7, 8, 9, 10, 11, 12}),
+					),
+				)},
+			},
+			want: &Header{
+				Bounds:        geom.NewBounds(geom.XY).Set(0, 0, 9, 10),
+				GeometryType:  Unknown,
+				Layout:        geom.XYZM,
+				FeaturesCount: 8,
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Write(&buf, tc.header, tc.features, WithIndexNodeSize(0)); err != nil {
+				t.Fatalf("Write(...) == %v, want <nil>", err)
+			}
+			r, err := NewReader(bytes.NewReader(buf.Bytes()))
+			if err != nil {
+				t.Fatalf("NewReader(...) == _, %v, want _, <nil>", err)
+			}
+			if got := r.Header(); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("r.Header() == %+v, want %+v", got, tc.want)
+			}
+			for i, want := range tc.features {
+				got, err := r.Read()
+				if err != nil {
+					t.Fatalf("r.Read() == _, %v, want _, <nil>", err)
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("feature %d: r.Read() == %#v, _, want %#v, _", i, got, want)
+				}
+			}
+			if _, err := r.Read(); err != io.EOF {
+				t.Errorf("r.Read() == _, %v, want _, %v", err, io.EOF)
+			}
+		})
+	}
+}
+
+func TestAlignment(t *testing.T) {
+	var buf bytes.Buffer
+	features := []*Feature{
+		{
+			Geom: geom.NewLineStringFlat(geom.XYZ, []float64{1, 2, 3, 4, 5, 6}),
+			Properties: map[string]interface{}{
+				"a": "x",
+			},
+		},
+	}
+	header := &Header{
+		Name:    "abc",
+		Columns: []Column{{Name: "a", Type: String}},
+	}
+	if err := Write(&buf, header, features); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	// The header flatbuffer starts after the magic bytes and its size.
+	headerSize := int(data[8]) | int(data[9])<<8
+	d := &fbDecoder{buf: data[12 : 12+headerSize]}
+	if pos, n := d.vector(d.root(), 1, 8); n != 4 || pos%8 != 0 {
+		t.Errorf("envelope at %d with length %d, want aligned with length 4", pos, n)
+	}
+	featureOffset := 12 + headerSize + int(indexSize(1, defaultIndexNodeSize)) + 4
+	d = &fbDecoder{buf: data[featureOffset:]}
+	g, ok := d.tableField(d.root(), 0)
+	if !ok {
+		t.Fatal("no geometry")
+	}
+	for _, id := range []int{1, 2} {
+		if pos, _ := d.vector(g, id, 8); pos%8 != 0 {
+			t.Errorf("vector %d at %d, want aligned", id, pos)
+		}
+	}
+	if d.err != nil {
+		t.Fatal(d.err)
+	}
+}
+
+// A countingReaderAt counts the bytes read from a ReaderAt.
+type countingReaderAt struct {
+	r     io.ReaderAt
+	n     int
+	calls int
+}
+
+func (r *countingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	n, err := r.r.ReadAt(p, off)
+	r.n += n
+	r.calls++
+	return n, err
+}
+
+func TestBounds(t *testing.T) {
+	var features []*Feature
+	for i := 0; i < 100; i++ {
+		for j := 0; j < 100; j++ {
+			features = append(features, &Feature{
+				Geom: geom.NewPointFlat(geom.XY, []float64{float64(i), float64(j)}),
+				Properties: map[string]interface{}{
+					"id": int32(100*i + j),
+				},
+			})
+		}
+	}
+	var buf bytes.Buffer
+	header := &Header{
+		Columns: []Column{{Name: "id", Type: Int}},
+	}
+	if err := Write(&buf, header, features, WithIndexNodeSize(4)); err != nil {
+		t.Fatal(err)
+	}
+	var noIndexBuf bytes.Buffer
+	if err := Write(&noIndexBuf, header, features, WithIndexNodeSize(0)); err != nil {
+		t.Fatal(err)
+	}
+
+	bounds := geom.NewBounds(geom.XY).Set(10.5, 20, 12, 22.5)
+	var want []int
+	for i := 10; i <= 12; i++ {
+		for j := 20; j <= 22; j++ {
+			if float64(i) >= 10.5 {
+				want = append(want, 100*i+j)
+			}
+		}
+	}
+
+	readIDs := func(t *testing.T, read func() (*Feature, error)) []int {
+		t.Helper()
+		var ids []int
+		for {
+			f, err := read()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			ids = append(ids, int(f.Properties["id"].(int32)))
+		}
+		sort.Ints(ids)
+		return ids
+	}
+
+	t.Run("reader", func(t *testing.T) {
+		r, err := NewReader(bytes.NewReader(buf.Bytes()), WithBounds(bounds))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := readIDs(t, r.Read); !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("reader_no_index", func(t *testing.T) {
+		r, err := NewReader(bytes.NewReader(noIndexBuf.Bytes()), WithBounds(bounds))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := readIDs(t, r.Read); !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("indexed_reader", func(t *testing.T) {
+		cr := &countingReaderAt{r: bytes.NewReader(buf.Bytes())}
+		r, err := NewIndexedReader(cr, WithBounds(bounds))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := readIDs(t, r.Read); !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+		if cr.n >= buf.Len()/10 {
+			t.Errorf("read %d bytes of %d, want less than a tenth", cr.n, buf.Len())
+		}
+	})
+
+	t.Run("indexed_reader_all", func(t *testing.T) {
+		r, err := NewIndexedReader(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := len(readIDs(t, r.Read)); got != len(features) {
+			t.Errorf("read %d features, want %d", got, len(features))
+		}
+		f, err := r.Feature(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The first feature in Hilbert order is at the origin.
+		if got := f.Geom.FlatCoords(); !reflect.DeepEqual(got, []float64{0, 0}) {
+			t.Errorf("r.Feature(0).Geom.FlatCoords() == %v, want [0 0]", got)
+		}
+	})
+
+	t.Run("no_index", func(t *testing.T) {
+		if _, err := NewIndexedReader(bytes.NewReader(noIndexBuf.Bytes())); err != errNoIndex {
+			t.Errorf("NewIndexedReader(...) == _, %v, want _, %v", err, errNoIndex)
+		}
+	})
+
+	t.Run("http", func(t *testing.T) {
+		var requests int
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			requests++
+			http.ServeContent(w, req, "points.fgb", time.Time{}, bytes.NewReader(buf.Bytes()))
+		}))
+		defer server.Close()
+		r, err := NewIndexedReader(&HTTPReaderAt{URL: server.URL}, WithBounds(bounds))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := readIDs(t, r.Read); !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+		if requests == 0 {
+			t.Error("no requests")
+		}
+	})
+}
+
+func TestFeatureFilter(t *testing.T) {
+	var features []*Feature
+	for i := 0; i < 10; i++ {
+		features = append(features, &Feature{
+			Geom: geom.NewPointFlat(geom.XY, []float64{float64(i), float64(i)}),
+			Properties: map[string]interface{}{
+				"id": int32(i),
+			},
+		})
+	}
+	header := &Header{
+		Columns: []Column{{Name: "id", Type: Int}},
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, header, features, WithIndexNodeSize(4)); err != nil {
+		t.Fatal(err)
+	}
+	filter := WithFeatureFilter(func(id string, properties map[string]interface{}) bool {
+		return properties["id"].(int32)%3 == 0
+	})
+	bounds := geom.NewBounds(geom.XY).Set(1, 1, 8, 8)
+
+	readIDs := func(t *testing.T, read func() (*Feature, error)) []int {
+		t.Helper()
+		var ids []int
+		for {
+			f, err := read()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			ids = append(ids, int(f.Properties["id"].(int32)))
+		}
+		sort.Ints(ids)
+		return ids
+	}
+
+	for _, tc := range []struct {
+		name string
+		opts []Option
+		want []int
+	}{
+		{name: "filter", opts: []Option{filter}, want: []int{0, 3, 6, 9}},
+		{name: "bounds_and_filter", opts: []Option{WithBounds(bounds), filter}, want: []int{3, 6}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := NewReader(bytes.NewReader(buf.Bytes()), tc.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := readIDs(t, r.Read); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Reader: got %v, want %v", got, tc.want)
+			}
+			ir, err := NewIndexedReader(bytes.NewReader(buf.Bytes()), tc.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := readIDs(t, ir.Read); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("IndexedReader: got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestLevelBounds(t *testing.T) {
+	for _, tc := range []struct {
+		numItems uint64
+		nodeSize uint16
+		want     []levelBound
+	}{
+		{numItems: 1, nodeSize: 16, want: []levelBound{{1, 2}, {0, 1}}},
+		{numItems: 16, nodeSize: 16, want: []levelBound{{1, 17}, {0, 1}}},
+		{numItems: 17, nodeSize: 16, want: []levelBound{{3, 20}, {1, 3}, {0, 1}}},
+	} {
+		if got := levelBounds(tc.numItems, tc.nodeSize); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("levelBounds(%d, %d) == %v, want %v", tc.numItems, tc.nodeSize, got, tc.want)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		data string
+		want error
+	}{
+		{
+			name: "invalid_magic",
+			data: "fgb\x02fgb\x00",
+			want: errInvalidMagic,
+		},
+		{
+			name: "truncated",
+			data: "fgb\x03fgb\x00\x10",
+			want: io.ErrUnexpectedEOF,
+		},
+		{
+			name: "invalid_flatbuffer",
+			data: "fgb\x03fgb\x00\x04\x00\x00\x00\xff\x00\x00\x00",
+			want: errInvalidFlatBuffer,
+		},
+		{
+			name: "header_too_large",
+			data: "fgb\x03fgb\x00\x00\x00\x00\xff",
+			want: errHeaderTooLarge,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := NewReader(strings.NewReader(tc.data)); err != tc.want {
+				t.Errorf("NewReader(...) == _, %v, want _, %v", err, tc.want)
+			}
+		})
+	}
+	var buf bytes.Buffer
+	for _, tc := range []struct {
+		name     string
+		features []*Feature
+		want     error
+	}{
+		{
+			name: "layout_mismatch",
+			features: []*Feature{
+				{Geom: geom.NewPointFlat(geom.XY, []float64{1, 2})},
+				{Geom: geom.NewPointFlat(geom.XYZ, []float64{1, 2, 3})},
+			},
+			want: geom.ErrLayoutMismatch{Got: geom.XYZ, Want: geom.XY},
+		},
+		{
+			name: "unknown_property",
+			features: []*Feature{
+				{Properties: map[string]interface{}{"a": 1}},
+			},
+			want: ErrUnknownProperty("a"),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := Write(&buf, &Header{}, tc.features); err != tc.want {
+				t.Errorf("Write(...) == %v, want %v", err, tc.want)
+			}
+		})
+	}
+	header := &Header{Columns: []Column{{Name: "a", Type: Int}}}
+	features := []*Feature{{Properties: map[string]interface{}{"a": 1}}}
+	if err := Write(&buf, header, features); !reflect.DeepEqual(err, ErrInvalidProperty{Name: "a", Value: 1}) {
+		t.Errorf("Write(...) == %v, want %v", err, ErrInvalidProperty{Name: "a", Value: 1})
+	}
+}
+
+func TestIndexedReaderErrors(t *testing.T) {
+	features := []*Feature{
+		{Geom: geom.NewPointFlat(geom.XY, []float64{1, 2})},
+		{Geom: geom.NewPointFlat(geom.XY, []float64{3, 4})},
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, &Header{}, features); err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewIndexedReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, i := range []int{-1, len(features)} {
+		if _, err := r.Feature(i); err != errIndexOutOfRange {
+			t.Errorf("r.Feature(%d) == _, %v, want _, %v", i, err, errIndexOutOfRange)
+		}
+	}
+
+	// Corrupt the size prefix of the first feature so that it claims to be
+	// nearly 4GB long.
+	data := append([]byte(nil), buf.Bytes()...)
+	binary.LittleEndian.PutUint32(data[r.featuresOffset:], math.MaxUint32)
+	r, err = NewIndexedReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	_, err = r.Read()
+	runtime.ReadMemStats(&after)
+	if err != io.ErrUnexpectedEOF {
+		t.Errorf("r.Read() == _, %v, want _, %v", err, io.ErrUnexpectedEOF)
+	}
+	if n := after.TotalAlloc - before.TotalAlloc; n > 16*maxAllocSize {
+		t.Errorf("r.Read() allocated %d bytes, want at most %d", n, 16*maxAllocSize)
+	}
+}
+
+func TestCorruptIndex(t *testing.T) {
+	features := []*Feature{
+		{Geom: geom.NewPointFlat(geom.XY, []float64{1, 2})},
+		{Geom: geom.NewPointFlat(geom.XY, []float64{3, 4})},
+		{Geom: geom.NewPointFlat(geom.XY, []float64{5, 6})},
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, &Header{}, features); err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewIndexedReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bounds := geom.NewBounds(geom.XY).Set(0, 0, 10, 10)
+
+	// Corrupt the offset of the first leaf so that it is negative or
+	// overflows when added to the offset of the first feature.
+	leafOffset := r.indexOffset + int64(levelBounds(uint64(len(features)), defaultIndexNodeSize)[0].start)*nodeItemSize + 32
+	for _, offset := range []uint64{math.MaxUint64, 1 << 63, math.MaxInt64 - 1} {
+		data := append([]byte(nil), buf.Bytes()...)
+		binary.LittleEndian.PutUint64(data[leafOffset:], offset)
+		r, err := NewIndexedReader(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := r.Feature(0); err != errInvalidIndex {
+			t.Errorf("r.Feature(0) == _, %v, want _, %v", err, errInvalidIndex)
+		}
+		r, err = NewIndexedReader(bytes.NewReader(data), WithBounds(bounds))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for err == nil {
+			_, err = r.Read()
+		}
+		if err != errInvalidIndex {
+			t.Errorf("r.Read() == _, %v, want _, %v", err, errInvalidIndex)
+		}
+	}
+
+	// Corrupt the number of features in the header.
+	headerSize := int(binary.LittleEndian.Uint32(buf.Bytes()[8:]))
+	header := buf.Bytes()[12 : 12+headerSize]
+	featuresCount := le64(uint64(len(features)))
+	if n := bytes.Count(header, featuresCount); n != 1 {
+		t.Fatalf("found features count %d times in header, want 1", n)
+	}
+	featuresCountOffset := 12 + bytes.Index(header, featuresCount)
+	for _, tc := range []struct {
+		featuresCount uint64
+		want          error
+	}{
+		{featuresCount: 1 << 40, want: io.ErrUnexpectedEOF},
+		{featuresCount: math.MaxUint64, want: errIndexTooLarge},
+	} {
+		data := append([]byte(nil), buf.Bytes()...)
+		binary.LittleEndian.PutUint64(data[featuresCountOffset:], tc.featuresCount)
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+		_, err := NewReader(bytes.NewReader(data), WithBounds(bounds))
+		runtime.ReadMemStats(&after)
+		if err != tc.want {
+			t.Errorf("NewReader(...) with %d features == _, %v, want _, %v", tc.featuresCount, err, tc.want)
+		}
+		if n := after.TotalAlloc - before.TotalAlloc; n > 16*maxAllocSize {
+			t.Errorf("NewReader(...) with %d features allocated %d bytes, want at most %d", tc.featuresCount, n, 16*maxAllocSize)
+		}
+	}
+}
+
+// le64 returns the little endian encoding of x.
+func le64(x uint64) []byte {
+	var data [8]byte
+	binary.LittleEndian.PutUint64(data[:], x)
+	return data[:]
+}
+
+func TestConformance(t *testing.T) {
+	conformance.Run(t, conformance.CodecFuncs{
+		MarshalFunc: func(g geom.T) ([]byte, error) {
+			var buf bytes.Buffer
+			if err := Write(&buf, &Header{}, []*Feature{{Geom: g}}); err != nil {
+				return nil, err
+			}
+			return buf.Bytes(), nil
+		},
+		UnmarshalFunc: func(data []byte) (geom.T, error) {
+			r, err := NewReader(bytes.NewReader(data))
+			if err != nil {
+				return nil, err
+			}
+			f, err := r.Read()
+			if err != nil {
+				return nil, err
+			}
+			return f.Geom, nil
+		},
+	})
+}
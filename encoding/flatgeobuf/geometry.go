@@ -0,0 +1,265 @@
+package flatgeobuf
+
+import (
+	"errors"
+
+	"github.com/twpayne/go-geom"
+)
+
+var errInvalidGeometry = errors.New("flatgeobuf: invalid geometry")
+
+// geometryType returns the geometry type of g.
+func geometryType(g geom.T) (GeometryType, error) {
+	switch g.(type) {
+	case *geom.Point:
+		return Point, nil
+	case *geom.LineString:
+		return LineString, nil
+	case *geom.Polygon:
+		return Polygon, nil
+	case *geom.MultiPoint:
+		return MultiPoint, nil
+	case *geom.MultiLineString:
+		return MultiLineString, nil
+	case *geom.MultiPolygon:
+		return MultiPolygon, nil
+	case *geom.GeometryCollection:
+		return GeometryCollection, nil
+	default:
+		return Unknown, geom.ErrUnsupportedType{Value: g}
+	}
+}
+
+// encodeGeometry returns the fields of the Geometry table of g, which must
+// have layout. The geometry type is included if withType is true.
+func encodeGeometry(g geom.T, layout geom.Layout, withType bool) ([]fbField, error) {
+	t, err := geometryType(g)
+	if err != nil {
+		return nil, err
+	}
+	var fields []fbField
+	switch g := g.(type) {
+	case *geom.MultiPolygon:
+		parts := make([][]fbField, 0, g.NumPolygons())
+		for i, n := 0, g.NumPolygons(); i < n; i++ {
+			part, err := encodeGeometry(g.Polygon(i), layout, true)
+			if err != nil {
+				return nil, err
+			}
+			parts = append(parts, part)
+		}
+		fields = append(fields, fbOffset(7, func(b *fbBuilder) int {
+			return b.tables(parts)
+		}))
+	case *geom.GeometryCollection:
+		parts := make([][]fbField, 0, g.NumGeoms())
+		for _, member := range g.Geoms() {
+			part, err := encodeGeometry(member, layout, true)
+			if err != nil {
+				return nil, err
+			}
+			parts = append(parts, part)
+		}
+		fields = append(fields, fbOffset(7, func(b *fbBuilder) int {
+			return b.tables(parts)
+		}))
+	default:
+		if g.Layout() != layout {
+			return nil, geom.ErrLayoutMismatch{Got: g.Layout(), Want: layout}
+		}
+		if ends := coordEnds(g); ends != nil {
+			fields = append(fields, fbOffset(0, func(b *fbBuilder) int {
+				return b.uint32s(ends)
+			}))
+		}
+		fields = appendFlatCoords(fields, g.FlatCoords(), layout)
+	}
+	if withType {
+		fields = append(fields, fbUint8(6, uint8(t)))
+	}
+	return fields, nil
+}
+
+// coordEnds returns the ends of the parts of g in coordinates, or nil if
+// they can be omitted because g has a single non-empty part.
+func coordEnds(g geom.T) []uint32 {
+	switch g.(type) {
+	case *geom.Polygon, *geom.MultiLineString:
+	default:
+		return nil
+	}
+	ends := g.Ends()
+	if len(ends) == 1 && ends[0] != 0 {
+		return nil
+	}
+	coordEnds := make([]uint32, 0, len(ends))
+	for _, end := range ends {
+		coordEnds = append(coordEnds, uint32(end/g.Stride()))
+	}
+	return coordEnds
+}
+
+// appendFlatCoords appends the xy, z, and m fields of flatCoords in layout to
+// fields.
+func appendFlatCoords(fields []fbField, flatCoords []float64, layout geom.Layout) []fbField {
+	stride := layout.Stride()
+	n := len(flatCoords) / stride
+	if n == 0 {
+		return fields
+	}
+	xy := make([]float64, 0, 2*n)
+	for i := 0; i < len(flatCoords); i += stride {
+		xy = append(xy, flatCoords[i], flatCoords[i+1])
+	}
+	fields = append(fields, fbOffset(1, func(b *fbBuilder) int {
+		return b.float64s(xy)
+	}))
+	if zIndex := layout.ZIndex(); zIndex != -1 {
+		fields = append(fields, ordinatesField(2, flatCoords, zIndex, stride))
+	}
+	if mIndex := layout.MIndex(); mIndex != -1 {
+		fields = append(fields, ordinatesField(3, flatCoords, mIndex, stride))
+	}
+	return fields
+}
+
+// ordinatesField returns the field id containing the ordinates at index of
+// each coordinate in flatCoords.
+func ordinatesField(id int, flatCoords []float64, index, stride int) fbField {
+	ordinates := make([]float64, 0, len(flatCoords)/stride)
+	for i := index; i < len(flatCoords); i += stride {
+		ordinates = append(ordinates, flatCoords[i])
+	}
+	return fbOffset(id, func(b *fbBuilder) int {
+		return b.float64s(ordinates)
+	})
+}
+
+// decodeGeometry decodes the Geometry table t in layout. t's geometry type
+// is geometryType if t does not have one.
+func decodeGeometry(d *fbDecoder, t fbTable, geometryType GeometryType, layout geom.Layout) (geom.T, error) {
+	if typ := GeometryType(d.uint8Field(t, 6, 0)); typ != Unknown {
+		geometryType = typ
+	}
+	switch geometryType {
+	case MultiPolygon:
+		g := geom.NewMultiPolygon(layout)
+		for _, part := range d.tablesField(t, 7) {
+			p, err := decodeGeometry(d, part, Polygon, layout)
+			if err != nil {
+				return nil, err
+			}
+			polygon, ok := p.(*geom.Polygon)
+			if !ok {
+				return nil, errInvalidGeometry
+			}
+			if err := g.Push(polygon); err != nil {
+				return nil, err
+			}
+		}
+		return g, d.err
+	case GeometryCollection:
+		g := geom.NewGeometryCollection()
+		for _, part := range d.tablesField(t, 7) {
+			member, err := decodeGeometry(d, part, Unknown, layout)
+			if err != nil {
+				return nil, err
+			}
+			if err := g.Push(member); err != nil {
+				return nil, err
+			}
+		}
+		return g, d.err
+	}
+	flatCoords, err := decodeFlatCoords(d, t, layout)
+	if err != nil {
+		return nil, err
+	}
+	stride := layout.Stride()
+	switch geometryType {
+	case Point:
+		switch len(flatCoords) {
+		case 0:
+			return geom.NewPointEmpty(layout), nil
+		case stride:
+			return geom.NewPointFlat(layout, flatCoords), nil
+		default:
+			return nil, errInvalidGeometry
+		}
+	case LineString:
+		return geom.NewLineStringFlat(layout, flatCoords), nil
+	case MultiPoint:
+		return geom.NewMultiPointFlat(layout, flatCoords), nil
+	case Polygon, MultiLineString:
+		ends, err := decodeEnds(d.uint32sField(t, 0), len(flatCoords)/stride, stride)
+		if err != nil {
+			return nil, err
+		}
+		if geometryType == Polygon {
+			return geom.NewPolygonFlat(layout, flatCoords, ends), nil
+		}
+		return geom.NewMultiLineStringFlat(layout, flatCoords, ends), nil
+	default:
+		return nil, ErrUnsupportedGeometryType(geometryType)
+	}
+}
+
+// decodeFlatCoords decodes the xy, z, and m fields of t as flat coordinates
+// in layout.
+func decodeFlatCoords(d *fbDecoder, t fbTable, layout geom.Layout) ([]float64, error) {
+	xy := d.float64sField(t, 1)
+	n := len(xy) / 2
+	if 2*n != len(xy) {
+		return nil, errInvalidGeometry
+	}
+	var z, m []float64
+	if layout.ZIndex() != -1 {
+		if z = d.float64sField(t, 2); len(z) != n {
+			return nil, errInvalidGeometry
+		}
+	}
+	if layout.MIndex() != -1 {
+		if m = d.float64sField(t, 3); len(m) != n {
+			return nil, errInvalidGeometry
+		}
+	}
+	if d.err != nil || n == 0 {
+		return nil, d.err
+	}
+	flatCoords := make([]float64, 0, n*layout.Stride())
+	for i := 0; i < n; i++ {
+		flatCoords = append(flatCoords, xy[2*i], xy[2*i+1])
+		if z != nil {
+			flatCoords = append(flatCoords, z[i])
+		}
+		if m != nil {
+			flatCoords = append(flatCoords, m[i])
+		}
+	}
+	return flatCoords, nil
+}
+
+// decodeEnds returns coordEnds, the ends of the parts of a geometry with n
+// coordinates, as ends in flat coordinates. If coordEnds is empty then the
+// geometry has a single part, unless it is empty.
+func decodeEnds(coordEnds []uint32, n, stride int) ([]int, error) {
+	if len(coordEnds) == 0 {
+		if n == 0 {
+			return nil, nil
+		}
+		return []int{n * stride}, nil
+	}
+	ends := make([]int, 0, len(coordEnds))
+	prev := 0
+	for _, end := range coordEnds {
+		if int(end) < prev || int(end) > n {
+			return nil, errInvalidGeometry
+		}
+		prev = int(end)
+		ends = append(ends, prev*stride)
+	}
+	if prev != n {
+		return nil, errInvalidGeometry
+	}
+	return ends, nil
+}
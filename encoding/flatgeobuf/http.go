@@ -0,0 +1,68 @@
+package flatgeobuf
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// An HTTPReaderAt is an io.ReaderAt that reads a remote file using HTTP range
+// requests, so that an IndexedReader can read the features of a remote
+// FlatGeobuf file that intersect a bounding box without downloading the
+// whole file. Each call to ReadAt makes one request.
+type HTTPReaderAt struct {
+	Client *http.Client // http.DefaultClient if nil
+	URL    string
+}
+
+// An ErrHTTPStatus is returned when an HTTP request fails.
+type ErrHTTPStatus struct {
+	URL    string
+	Status string
+}
+
+func (e ErrHTTPStatus) Error() string {
+	return fmt.Sprintf("flatgeobuf: %s: %s", e.URL, e.Status)
+}
+
+// ReadAt implements io.ReaderAt.
+func (r *HTTPReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	req, err := http.NewRequest(http.MethodGet, r.URL, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", off, off+int64(len(p))-1))
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+	case http.StatusOK:
+		// The server ignored the range, so skip to off.
+		if _, err := io.CopyN(ioutil.Discard, resp.Body, off); err != nil {
+			return 0, err
+		}
+	case http.StatusRequestedRangeNotSatisfiable:
+		return 0, io.EOF
+	default:
+		return 0, ErrHTTPStatus{
+			URL:    r.URL,
+			Status: resp.Status,
+		}
+	}
+	n, err := io.ReadFull(resp.Body, p)
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+	return n, err
+}
@@ -0,0 +1,333 @@
+package flatgeobuf
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/twpayne/go-geom"
+)
+
+// This file implements the packed Hilbert R-tree index. The nodes of the tree
+// are stored level by level, starting with the root and ending with the
+// leaves, which are the bounding boxes of the features in the order in which
+// they are stored. The offset of a leaf is the byte offset of its feature
+// relative to the first feature, and the offset of any other node is the
+// index of its first child. See
+// https://github.com/flatgeobuf/flatgeobuf/blob/master/src/cpp/packedrtree.cpp.
+
+// nodeItemSize is the size of an encoded node, in bytes.
+const nodeItemSize = 40
+
+// hilbertMax is the maximum ordinate of the Hilbert curve.
+const hilbertMax = 1<<16 - 1
+
+// maxIndexedFeatures is the maximum number of features of a file with an
+// index, so that the size of the index, which has fewer than twice as many
+// nodes as features, and the offsets of the features after it fit in an
+// int64.
+const maxIndexedFeatures = math.MaxInt64 / nodeItemSize / 4
+
+var (
+	errIndexTooLarge = errors.New("flatgeobuf: index too large")
+	errInvalidIndex  = errors.New("flatgeobuf: invalid index")
+)
+
+// A nodeItem is a node of the index.
+type nodeItem struct {
+	minX, minY, maxX, maxY float64
+	offset                 uint64
+}
+
+// emptyNodeItem returns a node that intersects nothing.
+func emptyNodeItem() nodeItem {
+	return nodeItem{
+		minX: math.Inf(1),
+		minY: math.Inf(1),
+		maxX: math.Inf(-1),
+		maxY: math.Inf(-1),
+	}
+}
+
+// newNodeItem returns the leaf node of g, which is empty if g is nil or
+// empty.
+func newNodeItem(g geom.T) nodeItem {
+	n := emptyNodeItem()
+	if g != nil {
+		n.extendGeom(g)
+	}
+	return n
+}
+
+// extendGeom extends n to include g, recursing into GeometryCollections.
+func (n *nodeItem) extendGeom(g geom.T) {
+	if gc, ok := g.(*geom.GeometryCollection); ok {
+		for _, member := range gc.Geoms() {
+			n.extendGeom(member)
+		}
+		return
+	}
+	flatCoords, stride := g.FlatCoords(), g.Stride()
+	for i := 0; i+1 < len(flatCoords); i += stride {
+		x, y := flatCoords[i], flatCoords[i+1]
+		n.minX, n.minY = math.Min(n.minX, x), math.Min(n.minY, y)
+		n.maxX, n.maxY = math.Max(n.maxX, x), math.Max(n.maxY, y)
+	}
+}
+
+// extend extends n to include other.
+func (n *nodeItem) extend(other nodeItem) {
+	n.minX, n.minY = math.Min(n.minX, other.minX), math.Min(n.minY, other.minY)
+	n.maxX, n.maxY = math.Max(n.maxX, other.maxX), math.Max(n.maxY, other.maxY)
+}
+
+// isEmpty returns whether n contains nothing.
+func (n nodeItem) isEmpty() bool {
+	return !(n.minX <= n.maxX && n.minY <= n.maxY)
+}
+
+// intersects returns whether n intersects other.
+func (n nodeItem) intersects(other nodeItem) bool {
+	return n.minX <= other.maxX && n.minY <= other.maxY && n.maxX >= other.minX && n.maxY >= other.minY
+}
+
+// appendNodeItem appends the encoding of n to data.
+func appendNodeItem(data []byte, n nodeItem) []byte {
+	data = appendUint64(data, math.Float64bits(n.minX))
+	data = appendUint64(data, math.Float64bits(n.minY))
+	data = appendUint64(data, math.Float64bits(n.maxX))
+	data = appendUint64(data, math.Float64bits(n.maxY))
+	return appendUint64(data, n.offset)
+}
+
+// decodeNodeItem decodes the node at the start of data.
+func decodeNodeItem(data []byte) nodeItem {
+	return nodeItem{
+		minX:   math.Float64frombits(binary.LittleEndian.Uint64(data[0:])),
+		minY:   math.Float64frombits(binary.LittleEndian.Uint64(data[8:])),
+		maxX:   math.Float64frombits(binary.LittleEndian.Uint64(data[16:])),
+		maxY:   math.Float64frombits(binary.LittleEndian.Uint64(data[24:])),
+		offset: binary.LittleEndian.Uint64(data[32:]),
+	}
+}
+
+// A levelBound is the range of node indexes of a level of the index.
+type levelBound struct {
+	start, end uint64
+}
+
+// levelBounds returns the bounds of the levels of the index of numItems
+// items with nodeSize children per node, starting with the leaves.
+func levelBounds(numItems uint64, nodeSize uint16) []levelBound {
+	if numItems == 0 || nodeSize < 2 {
+		return nil
+	}
+	n := numItems
+	numNodes := n
+	levelNumNodes := []uint64{n}
+	for {
+		n = (n + uint64(nodeSize) - 1) / uint64(nodeSize)
+		numNodes += n
+		levelNumNodes = append(levelNumNodes, n)
+		if n == 1 {
+			break
+		}
+	}
+	bounds := make([]levelBound, 0, len(levelNumNodes))
+	n = numNodes
+	for _, size := range levelNumNodes {
+		bounds = append(bounds, levelBound{start: n - size, end: n})
+		n -= size
+	}
+	return bounds
+}
+
+// indexSize returns the size in bytes of the index of numItems items with
+// nodeSize children per node.
+func indexSize(numItems uint64, nodeSize uint16) uint64 {
+	bounds := levelBounds(numItems, nodeSize)
+	if bounds == nil {
+		return 0
+	}
+	return bounds[0].end * nodeItemSize
+}
+
+// buildIndex returns the nodes of the index of leaves, which must already be
+// sorted.
+func buildIndex(leaves []nodeItem, nodeSize uint16) []nodeItem {
+	bounds := levelBounds(uint64(len(leaves)), nodeSize)
+	nodes := make([]nodeItem, bounds[0].end)
+	copy(nodes[bounds[0].start:], leaves)
+	for i := 0; i < len(bounds)-1; i++ {
+		pos, end := bounds[i].start, bounds[i].end
+		parent := bounds[i+1].start
+		for pos < end {
+			node := emptyNodeItem()
+			node.offset = pos
+			for j := 0; j < int(nodeSize) && pos < end; j++ {
+				node.extend(nodes[pos])
+				pos++
+			}
+			nodes[parent] = node
+			parent++
+		}
+	}
+	return nodes
+}
+
+// hilbertSort returns the order of leaves sorted by the Hilbert values of
+// the centers of their bounding boxes within extent. Empty leaves are sorted
+// first.
+func hilbertSort(leaves []nodeItem, extent nodeItem) []int {
+	width, height := extent.maxX-extent.minX, extent.maxY-extent.minY
+	values := make([]uint32, len(leaves))
+	order := make([]int, len(leaves))
+	for i, leaf := range leaves {
+		order[i] = i
+		if leaf.isEmpty() {
+			continue
+		}
+		var x, y uint32
+		if width > 0 {
+			x = uint32(hilbertMax * ((leaf.minX+leaf.maxX)/2 - extent.minX) / width)
+		}
+		if height > 0 {
+			y = uint32(hilbertMax * ((leaf.minY+leaf.maxY)/2 - extent.minY) / height)
+		}
+		values[i] = hilbert(x, y)
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return values[order[i]] < values[order[j]]
+	})
+	return order
+}
+
+// hilbert returns the index of (x, y) on the Hilbert curve of order 16. See
+// https://github.com/rawrunprotected/hilbert_curves.
+func hilbert(x, y uint32) uint32 {
+	a := x ^ y
+	b := 0xffff ^ a
+	c := 0xffff ^ (x | y)
+	d := x & (y ^ 0xffff)
+
+	A := a | (b >> 1)
+	B := (a >> 1) ^ a
+	C := ((c >> 1) ^ (b & (d >> 1))) ^ c
+	D := ((a & (c >> 1)) ^ (d >> 1)) ^ d
+
+	a, b, c, d = A, B, C, D
+	A = (a & (a >> 2)) ^ (b & (b >> 2))
+	B = (a & (b >> 2)) ^ (b & ((a ^ b) >> 2))
+	C ^= (a & (c >> 2)) ^ (b & (d >> 2))
+	D ^= (b & (c >> 2)) ^ ((a ^ b) & (d >> 2))
+
+	a, b, c, d = A, B, C, D
+	A = (a & (a >> 4)) ^ (b & (b >> 4))
+	B = (a & (b >> 4)) ^ (b & ((a ^ b) >> 4))
+	C ^= (a & (c >> 4)) ^ (b & (d >> 4))
+	D ^= (b & (c >> 4)) ^ ((a ^ b) & (d >> 4))
+
+	a, b, c, d = A, B, C, D
+	C ^= (a & (c >> 8)) ^ (b & (d >> 8))
+	D ^= (b & (c >> 8)) ^ ((a ^ b) & (d >> 8))
+
+	a = C ^ (C >> 1)
+	b = D ^ (D >> 1)
+
+	i0 := x ^ y
+	i1 := b | (0xffff ^ (i0 | a))
+
+	i0 = (i0 | (i0 << 8)) & 0x00ff00ff
+	i0 = (i0 | (i0 << 4)) & 0x0f0f0f0f
+	i0 = (i0 | (i0 << 2)) & 0x33333333
+	i0 = (i0 | (i0 << 1)) & 0x55555555
+
+	i1 = (i1 | (i1 << 8)) & 0x00ff00ff
+	i1 = (i1 | (i1 << 4)) & 0x0f0f0f0f
+	i1 = (i1 | (i1 << 2)) & 0x33333333
+	i1 = (i1 | (i1 << 1)) & 0x55555555
+
+	return (i1 << 1) | i0
+}
+
+// A searchResult is a feature found by searching the index.
+type searchResult struct {
+	offset uint64 // byte offset of the feature relative to the first feature
+	index  uint64 // index of the feature
+}
+
+// searchIndex returns the features of the index of numItems items with
+// nodeSize children per node whose bounding boxes intersect bounds, in the
+// order in which they are stored. readNodes returns the encoding of the n
+// nodes starting at node index first. Each level is read in as few calls as
+// possible, so that readNodes may be backed by HTTP range requests.
+func searchIndex(numItems uint64, nodeSize uint16, bounds *geom.Bounds, readNodes func(first, n uint64) ([]byte, error)) ([]searchResult, error) {
+	levels := levelBounds(numItems, nodeSize)
+	if levels == nil {
+		return nil, nil
+	}
+	query := nodeItem{
+		minX: bounds.Min(0),
+		minY: bounds.Min(1),
+		maxX: bounds.Max(0),
+		maxY: bounds.Max(1),
+	}
+	leavesStart := levels[0].start
+	var results []searchResult
+	// Search the tree breadth first. nodeIndexes are the first nodes of the
+	// groups of children to visit at level, in increasing order.
+	nodeIndexes := []uint64{0}
+	for level := len(levels) - 1; level >= 0 && len(nodeIndexes) > 0; level-- {
+		levelEnd := levels[level].end
+		var children []uint64
+		for i := 0; i < len(nodeIndexes); {
+			// Merge the reads of adjacent groups of nodes.
+			first := nodeIndexes[i]
+			if first < levels[level].start || first >= levelEnd {
+				return nil, errInvalidIndex
+			}
+			end := minUint64(first+uint64(nodeSize), levelEnd)
+			j := i + 1
+			for ; j < len(nodeIndexes) && nodeIndexes[j] <= end; j++ {
+				end = minUint64(nodeIndexes[j]+uint64(nodeSize), levelEnd)
+			}
+			data, err := readNodes(first, end-first)
+			if err != nil {
+				return nil, err
+			}
+			for ; i < j; i++ {
+				groupEnd := minUint64(nodeIndexes[i]+uint64(nodeSize), levelEnd)
+				for pos := nodeIndexes[i]; pos < groupEnd; pos++ {
+					node := decodeNodeItem(data[(pos-first)*nodeItemSize:])
+					if !node.intersects(query) {
+						continue
+					}
+					if level == 0 {
+						results = append(results, searchResult{
+							offset: node.offset,
+							index:  pos - leavesStart,
+						})
+					} else {
+						children = append(children, node.offset)
+					}
+				}
+			}
+		}
+		sort.Slice(children, func(i, j int) bool {
+			return children[i] < children[j]
+		})
+		nodeIndexes = children
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].offset < results[j].offset
+	})
+	return results, nil
+}
+
+func minUint64(a, b uint64) uint64 {
+	if a < b {
+		return a
+	}
+	return b
+}
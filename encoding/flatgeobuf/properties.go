@@ -0,0 +1,244 @@
+package flatgeobuf
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+var errInvalidProperties = errors.New("flatgeobuf: invalid properties")
+
+// An ErrUnknownProperty is returned when encoding a property that does not
+// have a column.
+type ErrUnknownProperty string
+
+func (e ErrUnknownProperty) Error() string {
+	return fmt.Sprintf("flatgeobuf: unknown property %s", string(e))
+}
+
+// dateTimeLayouts are the layouts with which DateTime values are parsed.
+var dateTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02",
+}
+
+// encodeProperties encodes the properties with values in columns. Properties
+// with nil values are omitted.
+func encodeProperties(columns []Column, properties map[string]interface{}) ([]byte, error) {
+	for name := range properties {
+		if columnIndex(columns, name) == -1 {
+			return nil, ErrUnknownProperty(name)
+		}
+	}
+	var data []byte
+	for i, column := range columns {
+		value, ok := properties[column.Name]
+		if !ok || value == nil {
+			continue
+		}
+		data = appendUint16(data, uint16(i))
+		var valid bool
+		switch column.Type {
+		case Byte:
+			var v int8
+			if v, valid = value.(int8); valid {
+				data = append(data, byte(v))
+			}
+		case UByte:
+			var v uint8
+			if v, valid = value.(uint8); valid {
+				data = append(data, v)
+			}
+		case Bool:
+			var v bool
+			if v, valid = value.(bool); valid {
+				if v {
+					data = append(data, 1)
+				} else {
+					data = append(data, 0)
+				}
+			}
+		case Short:
+			var v int16
+			if v, valid = value.(int16); valid {
+				data = appendUint16(data, uint16(v))
+			}
+		case UShort:
+			var v uint16
+			if v, valid = value.(uint16); valid {
+				data = appendUint16(data, v)
+			}
+		case Int:
+			var v int32
+			if v, valid = value.(int32); valid {
+				data = appendUint32(data, uint32(v))
+			}
+		case UInt:
+			var v uint32
+			if v, valid = value.(uint32); valid {
+				data = appendUint32(data, v)
+			}
+		case Long:
+			var v int64
+			if v, valid = value.(int64); valid {
+				data = appendUint64(data, uint64(v))
+			}
+		case ULong:
+			var v uint64
+			if v, valid = value.(uint64); valid {
+				data = appendUint64(data, v)
+			}
+		case Float:
+			var v float32
+			if v, valid = value.(float32); valid {
+				data = appendUint32(data, math.Float32bits(v))
+			}
+		case Double:
+			var v float64
+			if v, valid = value.(float64); valid {
+				data = appendUint64(data, math.Float64bits(v))
+			}
+		case String, JSON:
+			var v string
+			if v, valid = value.(string); valid {
+				data = appendUint32(data, uint32(len(v)))
+				data = append(data, v...)
+			}
+		case DateTime:
+			var s string
+			switch v := value.(type) {
+			case time.Time:
+				s, valid = v.Format(time.RFC3339Nano), true
+			case string:
+				s, valid = v, true
+			}
+			if valid {
+				data = appendUint32(data, uint32(len(s)))
+				data = append(data, s...)
+			}
+		case Binary:
+			var v []byte
+			if v, valid = value.([]byte); valid {
+				data = appendUint32(data, uint32(len(v)))
+				data = append(data, v...)
+			}
+		default:
+			return nil, ErrUnsupportedColumnType(column.Type)
+		}
+		if !valid {
+			return nil, ErrInvalidProperty{
+				Name:  column.Name,
+				Value: value,
+			}
+		}
+	}
+	return data, nil
+}
+
+// decodeProperties decodes the properties in data with columns. DateTime
+// values that cannot be parsed are returned as strings.
+func decodeProperties(columns []Column, data []byte) (map[string]interface{}, error) {
+	properties := make(map[string]interface{})
+	for len(data) > 0 {
+		if len(data) < 2 {
+			return nil, errInvalidProperties
+		}
+		i := int(binary.LittleEndian.Uint16(data))
+		data = data[2:]
+		if i >= len(columns) {
+			return nil, errInvalidProperties
+		}
+		column := columns[i]
+		size := 0
+		switch column.Type {
+		case Byte, UByte, Bool:
+			size = 1
+		case Short, UShort:
+			size = 2
+		case Int, UInt, Float:
+			size = 4
+		case Long, ULong, Double:
+			size = 8
+		case String, JSON, DateTime, Binary:
+			if len(data) < 4 {
+				return nil, errInvalidProperties
+			}
+			size = int(binary.LittleEndian.Uint32(data))
+			data = data[4:]
+		default:
+			return nil, ErrUnsupportedColumnType(column.Type)
+		}
+		if size < 0 || len(data) < size {
+			return nil, errInvalidProperties
+		}
+		value := data[:size]
+		data = data[size:]
+		switch column.Type {
+		case Byte:
+			properties[column.Name] = int8(value[0])
+		case UByte:
+			properties[column.Name] = value[0]
+		case Bool:
+			properties[column.Name] = value[0] != 0
+		case Short:
+			properties[column.Name] = int16(binary.LittleEndian.Uint16(value))
+		case UShort:
+			properties[column.Name] = binary.LittleEndian.Uint16(value)
+		case Int:
+			properties[column.Name] = int32(binary.LittleEndian.Uint32(value))
+		case UInt:
+			properties[column.Name] = binary.LittleEndian.Uint32(value)
+		case Long:
+			properties[column.Name] = int64(binary.LittleEndian.Uint64(value))
+		case ULong:
+			properties[column.Name] = binary.LittleEndian.Uint64(value)
+		case Float:
+			properties[column.Name] = math.Float32frombits(binary.LittleEndian.Uint32(value))
+		case Double:
+			properties[column.Name] = math.Float64frombits(binary.LittleEndian.Uint64(value))
+		case String, JSON:
+			properties[column.Name] = string(value)
+		case DateTime:
+			properties[column.Name] = parseDateTime(string(value))
+		case Binary:
+			properties[column.Name] = append([]byte(nil), value...)
+		}
+	}
+	return properties, nil
+}
+
+// parseDateTime returns s as a time.Time, or s if it cannot be parsed.
+func parseDateTime(s string) interface{} {
+	for _, layout := range dateTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return s
+}
+
+// columnIndex returns the index of the column called name, or -1 if there is
+// none.
+func columnIndex(columns []Column, name string) int {
+	for i, column := range columns {
+		if column.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func appendUint16(data []byte, v uint16) []byte {
+	return append(data, byte(v), byte(v>>8))
+}
+
+func appendUint32(data []byte, v uint32) []byte {
+	return append(data, byte(v), byte(v>>8), byte(v>>16), byte(v>>24))
+}
+
+func appendUint64(data []byte, v uint64) []byte {
+	return appendUint32(appendUint32(data, uint32(v)), uint32(v>>32))
+}
@@ -0,0 +1,459 @@
+package flatgeobuf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"math"
+	"strings"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/feature"
+)
+
+// An Option sets an option for reading or writing. Options that do not apply
+// to an operation are ignored.
+type Option func(*options)
+
+type options struct {
+	bounds        *geom.Bounds
+	filter        feature.Filter
+	indexNodeSize uint16
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		indexNodeSize: defaultIndexNodeSize,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithBounds sets bounds by which features are filtered when reading.
+// Features whose bounding boxes do not intersect bounds in X and Y, and
+// features without geometries, are skipped. If the file has an index then
+// features are filtered using it, without decoding them, and a Reader seeks
+// past them if the file implements io.Seeker.
+func WithBounds(bounds *geom.Bounds) Option {
+	return func(o *options) {
+		o.bounds = bounds
+	}
+}
+
+// WithFeatureFilter sets a filter by which features are filtered when
+// reading. It is called with an empty id, as FlatGeobuf does not identify
+// features, and the properties of each feature. The geometries of the
+// features that it rejects are not decoded.
+func WithFeatureFilter(filter feature.Filter) Option {
+	return func(o *options) {
+		o.filter = filter
+	}
+}
+
+// WithIndexNodeSize sets the number of children of each node of the index
+// when writing. Zero disables the index. The default is 16.
+func WithIndexNodeSize(indexNodeSize uint16) Option {
+	return func(o *options) {
+		o.indexNodeSize = indexNodeSize
+	}
+}
+
+// maxAllocSize is the maximum size of a feature or index that is allocated
+// before it is read.
+const maxAllocSize = 1 << 20
+
+// A Reader reads the features of a FlatGeobuf file sequentially.
+type Reader struct {
+	r       io.Reader
+	header  *Header
+	bounds  *geom.Bounds
+	filter  feature.Filter
+	results []searchResult // nil if features are not filtered by the index
+	next    int            // index of the next result
+	offset  uint64         // offset of r relative to the first feature
+	count   uint64         // number of features read
+	err     error
+}
+
+// NewReader returns a new Reader that reads the FlatGeobuf file r.
+func NewReader(r io.Reader, opts ...Option) (*Reader, error) {
+	o := newOptions(opts)
+	header, _, err := readHeader(r)
+	if err != nil {
+		return nil, err
+	}
+	fr := &Reader{
+		r:      r,
+		header: header,
+		bounds: o.bounds,
+		filter: o.filter,
+	}
+	if size := indexSize(header.FeaturesCount, header.IndexNodeSize); size > 0 {
+		if o.bounds == nil {
+			if err := skip(r, int64(size)); err != nil {
+				return nil, err
+			}
+			return fr, nil
+		}
+		index, err := readFull(r, size)
+		if err != nil {
+			return nil, err
+		}
+		fr.results, err = searchIndex(header.FeaturesCount, header.IndexNodeSize, o.bounds, func(first, n uint64) ([]byte, error) {
+			return index[first*nodeItemSize : (first+n)*nodeItemSize], nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if fr.results == nil {
+			fr.results = []searchResult{}
+		}
+	}
+	return fr, nil
+}
+
+// Header returns the header of the file.
+func (r *Reader) Header() *Header {
+	return r.header
+}
+
+// Read returns the next feature. It returns io.EOF after the last feature.
+// Errors are sticky.
+func (r *Reader) Read() (*Feature, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	f, err := r.read()
+	if err != nil {
+		r.err = err
+		return nil, err
+	}
+	return f, nil
+}
+
+func (r *Reader) read() (*Feature, error) {
+	for {
+		if r.results != nil {
+			if r.next >= len(r.results) {
+				return nil, io.EOF
+			}
+			result := r.results[r.next]
+			r.next++
+			if result.offset < r.offset || result.offset-r.offset > math.MaxInt64 {
+				return nil, errInvalidIndex
+			}
+			if err := skip(r.r, int64(result.offset-r.offset)); err != nil {
+				return nil, err
+			}
+			r.offset = result.offset
+		} else if r.header.FeaturesCount != 0 && r.count >= r.header.FeaturesCount {
+			return nil, io.EOF
+		}
+		data, err := readSizePrefixed(r.r)
+		if err != nil {
+			return nil, err
+		}
+		r.offset += 4 + uint64(len(data))
+		r.count++
+		f, err := decodeFeature(data, r.header, r.filter)
+		if err != nil {
+			return nil, err
+		}
+		if f == nil || r.results == nil && r.bounds != nil && !intersects(f.Geom, r.bounds) {
+			continue
+		}
+		return f, nil
+	}
+}
+
+// An IndexedReader reads the features of a FlatGeobuf file that has an index
+// in any order. When filtering by bounds, only the nodes of the index that
+// are needed and the features that match are read, so r may be backed by
+// HTTP range requests.
+type IndexedReader struct {
+	r              io.ReaderAt
+	header         *Header
+	indexOffset    int64
+	featuresOffset int64
+	bounds         *geom.Bounds
+	filter         feature.Filter
+	results        []searchResult
+	searched       bool
+	next           int
+	offset         int64
+}
+
+// NewIndexedReader returns a new IndexedReader that reads the FlatGeobuf
+// file r, which must have an index.
+func NewIndexedReader(r io.ReaderAt, opts ...Option) (*IndexedReader, error) {
+	o := newOptions(opts)
+	header, indexOffset, err := readHeader(io.NewSectionReader(r, 0, 8+4+maxHeaderSize))
+	if err != nil {
+		return nil, err
+	}
+	size := indexSize(header.FeaturesCount, header.IndexNodeSize)
+	if size == 0 {
+		return nil, errNoIndex
+	}
+	return &IndexedReader{
+		r:              r,
+		header:         header,
+		indexOffset:    indexOffset,
+		featuresOffset: indexOffset + int64(size),
+		bounds:         o.bounds,
+		filter:         o.filter,
+		offset:         indexOffset + int64(size),
+	}, nil
+}
+
+// Header returns the header of the file.
+func (r *IndexedReader) Header() *Header {
+	return r.header
+}
+
+// NumFeatures returns the number of features.
+func (r *IndexedReader) NumFeatures() int {
+	return int(r.header.FeaturesCount)
+}
+
+// Feature returns the ith feature, regardless of any bounds. It returns an
+// error if i is out of range.
+func (r *IndexedReader) Feature(i int) (*Feature, error) {
+	if i < 0 || uint64(i) >= r.header.FeaturesCount {
+		return nil, errIndexOutOfRange
+	}
+	leavesStart := levelBounds(r.header.FeaturesCount, r.header.IndexNodeSize)[0].start
+	data := make([]byte, nodeItemSize)
+	if _, err := readFullAt(r.r, data, r.indexOffset+int64(leavesStart+uint64(i))*nodeItemSize); err != nil {
+		return nil, err
+	}
+	offset, err := r.featureOffset(decodeNodeItem(data).offset)
+	if err != nil {
+		return nil, err
+	}
+	f, _, err := r.featureAt(offset, nil)
+	return f, err
+}
+
+// Read returns the next feature that intersects the bounds set with
+// WithBounds and is accepted by the filter set with WithFeatureFilter, if
+// any. It returns io.EOF after the last feature.
+func (r *IndexedReader) Read() (*Feature, error) {
+	if r.bounds != nil && !r.searched {
+		results, err := searchIndex(r.header.FeaturesCount, r.header.IndexNodeSize, r.bounds, func(first, n uint64) ([]byte, error) {
+			data := make([]byte, n*nodeItemSize)
+			_, err := readFullAt(r.r, data, r.indexOffset+int64(first)*nodeItemSize)
+			return data, err
+		})
+		if err != nil {
+			return nil, err
+		}
+		r.results = results
+		r.searched = true
+	}
+	for {
+		var offset int64
+		var err error
+		if r.bounds == nil {
+			if r.next >= r.NumFeatures() {
+				return nil, io.EOF
+			}
+			offset = r.offset
+		} else {
+			if r.next >= len(r.results) {
+				return nil, io.EOF
+			}
+			if offset, err = r.featureOffset(r.results[r.next].offset); err != nil {
+				return nil, err
+			}
+		}
+		f, size, err := r.featureAt(offset, r.filter)
+		if err != nil {
+			return nil, err
+		}
+		r.next++
+		if r.bounds == nil {
+			r.offset += size
+		}
+		if f != nil {
+			return f, nil
+		}
+	}
+}
+
+// featureOffset returns the offset in the file of the feature at offset
+// relative to the first feature, as stored in the index.
+func (r *IndexedReader) featureOffset(offset uint64) (int64, error) {
+	if offset > uint64(math.MaxInt64-r.featuresOffset) {
+		return 0, errInvalidIndex
+	}
+	return r.featuresOffset + int64(offset), nil
+}
+
+// featureAt returns the feature at offset, or nil if filter rejects it, and
+// its size, including its size prefix.
+func (r *IndexedReader) featureAt(offset int64, filter feature.Filter) (*Feature, int64, error) {
+	if offset < 0 {
+		return nil, 0, errInvalidIndex
+	}
+	data, err := readSizePrefixed(io.NewSectionReader(r.r, offset, math.MaxInt64-offset))
+	if err != nil {
+		return nil, 0, unexpectedEOF(err)
+	}
+	f, err := decodeFeature(data, r.header, filter)
+	return f, 4 + int64(len(data)), err
+}
+
+// readHeader reads the magic bytes and header from r and returns the header
+// and the offset of the data that follows it.
+func readHeader(r io.Reader) (*Header, int64, error) {
+	var prefix [8]byte
+	if _, err := io.ReadFull(r, prefix[:]); err != nil {
+		return nil, 0, err
+	}
+	if string(prefix[:3]) != string(magic[:3]) || prefix[3] != magic[3] || string(prefix[4:7]) != string(magic[4:7]) {
+		return nil, 0, errInvalidMagic
+	}
+	var sizePrefix [4]byte
+	if _, err := io.ReadFull(r, sizePrefix[:]); err != nil {
+		return nil, 0, unexpectedEOF(err)
+	}
+	size := binary.LittleEndian.Uint32(sizePrefix[:])
+	if size > maxHeaderSize {
+		return nil, 0, errHeaderTooLarge
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, 0, unexpectedEOF(err)
+	}
+	header, err := decodeHeader(data)
+	if err != nil {
+		return nil, 0, err
+	}
+	if header.IndexNodeSize >= 2 && header.FeaturesCount > maxIndexedFeatures {
+		return nil, 0, errIndexTooLarge
+	}
+	return header, int64(len(prefix) + 4 + len(data)), nil
+}
+
+// readSizePrefixed reads a size-prefixed flatbuffer from r. It returns
+// io.EOF if r is at its end.
+func readSizePrefixed(r io.Reader) ([]byte, error) {
+	var prefix [4]byte
+	if _, err := io.ReadFull(r, prefix[:]); err != nil {
+		return nil, err
+	}
+	return readFull(r, uint64(binary.LittleEndian.Uint32(prefix[:])))
+}
+
+// readFull reads size bytes from r. It returns io.ErrUnexpectedEOF if r
+// contains fewer than size bytes.
+func readFull(r io.Reader, size uint64) ([]byte, error) {
+	if size > maxAllocSize {
+		// Read large data incrementally so that a corrupt size does not
+		// cause a huge allocation.
+		var buf bytes.Buffer
+		if _, err := io.CopyN(&buf, r, int64(size)); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		return buf.Bytes(), nil
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	return data, nil
+}
+
+// skip skips n bytes of r, seeking if r implements io.Seeker.
+func skip(r io.Reader, n int64) error {
+	if seeker, ok := r.(io.Seeker); ok {
+		_, err := seeker.Seek(n, io.SeekCurrent)
+		return err
+	}
+	_, err := io.CopyN(ioutil.Discard, r, n)
+	return unexpectedEOF(err)
+}
+
+// readFullAt reads len(data) bytes from r at offset.
+func readFullAt(r io.ReaderAt, data []byte, offset int64) (int, error) {
+	n, err := r.ReadAt(data, offset)
+	if n == len(data) {
+		return n, nil
+	}
+	return n, unexpectedEOF(err)
+}
+
+// unexpectedEOF returns io.ErrUnexpectedEOF if err is io.EOF, and err
+// otherwise.
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// decodeFeature decodes the feature flatbuffer data using header. It returns
+// nil if filter is not nil and rejects the feature, without decoding its
+// geometry.
+func decodeFeature(data []byte, header *Header, filter feature.Filter) (*Feature, error) {
+	d := &fbDecoder{buf: data}
+	t := d.root()
+	f := &Feature{}
+	columns := header.Columns
+	if ts := d.tablesField(t, 2); ts != nil {
+		columns = decodeColumns(d, ts)
+	}
+	if properties := d.bytesField(t, 1); properties != nil {
+		var err error
+		if f.Properties, err = decodeProperties(columns, properties); err != nil {
+			return nil, err
+		}
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	if filter != nil && !filter("", f.Properties) {
+		return nil, nil
+	}
+	if gt, ok := d.tableField(t, 0); ok {
+		g, err := decodeGeometry(d, gt, header.GeometryType, header.Layout)
+		if err != nil {
+			return nil, err
+		}
+		if srid := header.srid(); srid != 0 {
+			geom.SetSRIDRecursive(g, srid)
+		}
+		f.Geom = g
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	return f, nil
+}
+
+// srid returns the SRID of the features described by h, which is the code
+// of its CRS if it is defined by EPSG, or zero otherwise.
+func (h *Header) srid() int {
+	if h.CRS == nil || h.CRS.Org != "" && !strings.EqualFold(h.CRS.Org, "EPSG") {
+		return 0
+	}
+	return int(h.CRS.Code)
+}
+
+// intersects returns whether the bounding box of g intersects bounds in X and
+// Y.
+func intersects(g geom.T, bounds *geom.Bounds) bool {
+	if g == nil {
+		return false
+	}
+	return newNodeItem(g).intersects(nodeItem{
+		minX: bounds.Min(0),
+		minY: bounds.Min(1),
+		maxX: bounds.Max(0),
+		maxY: bounds.Max(1),
+	})
+}
@@ -0,0 +1,153 @@
+package flatgeobuf
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+
+	"github.com/twpayne/go-geom"
+)
+
+// Write writes h and features to w as a FlatGeobuf file. The name, columns,
+// CRS, title, description, and metadata are taken from h. The bounds,
+// features count, index node size, and layout are computed from features,
+// which must all have the same layout, as is the geometry type if h's is
+// Unknown. If h has no CRS then the CRS is EPSG:srid where srid is the SRID
+// of the first geometry, if it is not zero. Unless the index is disabled
+// with WithIndexNodeSize, features are written in the order of the Hilbert
+// curve through the centers of their bounding boxes, not in the order given.
+func Write(w io.Writer, h *Header, features []*Feature, opts ...Option) error {
+	o := newOptions(opts)
+	header := *h
+	header.FeaturesCount = uint64(len(features))
+	header.IndexNodeSize = o.indexNodeSize
+	if len(features) == 0 {
+		header.IndexNodeSize = 0
+	}
+	if header.Layout == geom.NoLayout {
+		header.Layout = geom.XY
+	}
+	var geometryTypes []GeometryType
+	extent := emptyNodeItem()
+	leaves := make([]nodeItem, 0, len(features))
+	haveLayout := false
+	for _, f := range features {
+		leaf := emptyNodeItem()
+		if f.Geom != nil {
+			t, err := geometryType(f.Geom)
+			if err != nil {
+				return err
+			}
+			geometryTypes = append(geometryTypes, t)
+			if layout := f.Geom.Layout(); !haveLayout && layout != geom.NoLayout {
+				header.Layout = layout
+				haveLayout = true
+			}
+			if header.CRS == nil && f.Geom.SRID() != 0 {
+				header.CRS = &CRS{
+					Org:  "EPSG",
+					Code: int32(f.Geom.SRID()),
+				}
+			}
+			leaf = newNodeItem(f.Geom)
+			extent.extend(leaf)
+		}
+		leaves = append(leaves, leaf)
+	}
+	if header.GeometryType == Unknown && len(geometryTypes) > 0 {
+		header.GeometryType = geometryTypes[0]
+		for _, t := range geometryTypes[1:] {
+			if t != header.GeometryType {
+				header.GeometryType = Unknown
+				break
+			}
+		}
+	}
+	if extent.isEmpty() {
+		header.Bounds = nil
+	} else {
+		header.Bounds = geom.NewBounds(geom.XY).Set(extent.minX, extent.minY, extent.maxX, extent.maxY)
+	}
+
+	order := make([]int, len(features))
+	for i := range order {
+		order[i] = i
+	}
+	if header.IndexNodeSize != 0 {
+		order = hilbertSort(leaves, extent)
+	}
+	data := make([][]byte, 0, len(features))
+	sortedLeaves := make([]nodeItem, 0, len(features))
+	offset := uint64(0)
+	for _, i := range order {
+		featureData, err := encodeFeature(features[i], &header)
+		if err != nil {
+			return err
+		}
+		data = append(data, featureData)
+		leaf := leaves[i]
+		leaf.offset = offset
+		sortedLeaves = append(sortedLeaves, leaf)
+		offset += 4 + uint64(len(featureData))
+	}
+
+	bw := bufio.NewWriter(w)
+	if _, err := bw.Write(magic[:]); err != nil {
+		return err
+	}
+	if err := writeSizePrefixed(bw, encodeHeader(&header)); err != nil {
+		return err
+	}
+	if header.IndexNodeSize != 0 {
+		var nodeData []byte
+		for _, node := range buildIndex(sortedLeaves, header.IndexNodeSize) {
+			nodeData = appendNodeItem(nodeData[:0], node)
+			if _, err := bw.Write(nodeData); err != nil {
+				return err
+			}
+		}
+	}
+	for _, featureData := range data {
+		if err := writeSizePrefixed(bw, featureData); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// writeSizePrefixed writes data preceded by its size to w.
+func writeSizePrefixed(w io.Writer, data []byte) error {
+	var prefix [4]byte
+	binary.LittleEndian.PutUint32(prefix[:], uint32(len(data)))
+	if _, err := w.Write(prefix[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// encodeFeature returns the feature flatbuffer of f using header.
+func encodeFeature(f *Feature, header *Header) ([]byte, error) {
+	var fields []fbField
+	if f.Geom != nil {
+		geometryFields, err := encodeGeometry(f.Geom, header.Layout, header.GeometryType == Unknown)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, fbOffset(0, func(b *fbBuilder) int {
+			return b.table(geometryFields)
+		}))
+	}
+	if len(f.Properties) > 0 {
+		properties, err := encodeProperties(header.Columns, f.Properties)
+		if err != nil {
+			return nil, err
+		}
+		if len(properties) > 0 {
+			fields = append(fields, fbOffset(1, func(b *fbBuilder) int {
+				return b.bytes(properties)
+			}))
+		}
+	}
+	return (&fbBuilder{}).finish(fields), nil
+}
@@ -0,0 +1,149 @@
+package geoarrow
+
+import (
+	"math"
+
+	"github.com/twpayne/go-geom"
+)
+
+// Unmarshal returns the geometries of a. Null geometries are returned as
+// nils, and points with only NaN coordinates as empty points. If the
+// coordinates of a are interleaved then the geometries share them.
+func Unmarshal(a *Array) ([]geom.T, error) {
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+	stride := a.Layout.Stride()
+	gs := make([]geom.T, a.Len())
+	for i := range gs {
+		if a.IsNull(i) {
+			continue
+		}
+		switch a.Type {
+		case Point:
+			flatCoords := a.coords(i, i+1)
+			if isNaN(flatCoords) {
+				gs[i] = geom.NewPointEmpty(a.Layout)
+			} else {
+				gs[i] = geom.NewPointFlat(a.Layout, flatCoords)
+			}
+		case LineString:
+			start, end := a.Offsets[0][i], a.Offsets[0][i+1]
+			gs[i] = geom.NewLineStringFlat(a.Layout, a.coords(int(start), int(end)))
+		case MultiPoint:
+			start, end := a.Offsets[0][i], a.Offsets[0][i+1]
+			gs[i] = geom.NewMultiPointFlat(a.Layout, a.coords(int(start), int(end)))
+		case Polygon:
+			flatCoords, ends := a.parts(a.Offsets[1], a.Offsets[0][i], a.Offsets[0][i+1], stride)
+			gs[i] = geom.NewPolygonFlat(a.Layout, flatCoords, ends)
+		case MultiLineString:
+			flatCoords, ends := a.parts(a.Offsets[1], a.Offsets[0][i], a.Offsets[0][i+1], stride)
+			gs[i] = geom.NewMultiLineStringFlat(a.Layout, flatCoords, ends)
+		case MultiPolygon:
+			polygonOffsets, ringOffsets := a.Offsets[1], a.Offsets[2]
+			firstPolygon, lastPolygon := a.Offsets[0][i], a.Offsets[0][i+1]
+			start := ringOffsets[polygonOffsets[firstPolygon]]
+			end := ringOffsets[polygonOffsets[lastPolygon]]
+			endss := make([][]int, 0, lastPolygon-firstPolygon)
+			for polygon := firstPolygon; polygon < lastPolygon; polygon++ {
+				firstRing, lastRing := polygonOffsets[polygon], polygonOffsets[polygon+1]
+				ends := make([]int, 0, lastRing-firstRing)
+				for ring := firstRing; ring < lastRing; ring++ {
+					ends = append(ends, int(ringOffsets[ring+1]-start)*stride)
+				}
+				endss = append(endss, ends)
+			}
+			gs[i] = geom.NewMultiPolygonFlat(a.Layout, a.coords(int(start), int(end)), endss)
+		}
+	}
+	return gs, nil
+}
+
+// parts returns the coordinates and ends of the parts first to last, whose
+// coordinates are delimited by offsets.
+func (a *Array) parts(offsets []int32, first, last int32, stride int) ([]float64, []int) {
+	start, end := offsets[first], offsets[last]
+	ends := make([]int, 0, last-first)
+	for part := first; part < last; part++ {
+		ends = append(ends, int(offsets[part+1]-start)*stride)
+	}
+	return a.coords(int(start), int(end)), ends
+}
+
+// coords returns the flat coordinates of the coordinates start to end.
+func (a *Array) coords(start, end int) []float64 {
+	stride := a.Layout.Stride()
+	if a.Separated == nil {
+		return a.Interleaved[start*stride : end*stride : end*stride]
+	}
+	flatCoords := make([]float64, 0, (end-start)*stride)
+	for i := start; i < end; i++ {
+		for _, dim := range a.Separated {
+			flatCoords = append(flatCoords, dim[i])
+		}
+	}
+	return flatCoords
+}
+
+// validate checks that the buffers of a are consistent.
+func (a *Array) validate() error {
+	if a.Type <= Unknown || a.Type > MultiPolygon {
+		return ErrInvalidArray("invalid type " + a.Type.String())
+	}
+	stride := a.Layout.Stride()
+	if stride < 2 {
+		return geom.ErrUnsupportedLayout(a.Layout)
+	}
+	if a.Separated != nil {
+		if len(a.Separated) != stride {
+			return ErrInvalidArray("wrong number of coordinate buffers")
+		}
+		for _, dim := range a.Separated {
+			if len(dim) != len(a.Separated[0]) {
+				return ErrInvalidArray("coordinate buffers have different lengths")
+			}
+		}
+	} else if len(a.Interleaved)%stride != 0 {
+		return ErrInvalidArray("coordinate buffer length is not a multiple of the stride")
+	}
+	if len(a.Offsets) != numOffsets[a.Type] {
+		return ErrInvalidArray("wrong number of offset buffers")
+	}
+	for _, offsets := range a.Offsets {
+		if len(offsets) == 0 {
+			return ErrInvalidArray("empty offset buffer")
+		}
+	}
+	// The offsets of each level must be increasing and index the elements of
+	// the next level down.
+	for level, offsets := range a.Offsets {
+		limit := a.numCoords()
+		if level+1 < len(a.Offsets) {
+			limit = len(a.Offsets[level+1]) - 1
+		}
+		if offsets[0] < 0 {
+			return ErrInvalidArray("negative offset")
+		}
+		for i := 1; i < len(offsets); i++ {
+			if offsets[i] < offsets[i-1] {
+				return ErrInvalidArray("decreasing offsets")
+			}
+		}
+		if int(offsets[len(offsets)-1]) > limit {
+			return ErrInvalidArray("offset out of range")
+		}
+	}
+	if a.Validity != nil && len(a.Validity) < (a.Len()+7)/8 {
+		return ErrInvalidArray("validity bitmap too short")
+	}
+	return nil
+}
+
+func isNaN(flatCoords []float64) bool {
+	for _, x := range flatCoords {
+		if !math.IsNaN(x) {
+			return false
+		}
+	}
+	return true
+}
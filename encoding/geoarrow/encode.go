@@ -0,0 +1,189 @@
+package geoarrow
+
+import (
+	"errors"
+	"math"
+
+	"github.com/twpayne/go-geom"
+)
+
+var errOffsetOverflow = errors.New("geoarrow: offset overflow")
+
+// An encoder builds an Array.
+type encoder struct {
+	array      *Array
+	stride     int
+	flatCoords []float64
+	hasNulls   bool
+	err        error
+}
+
+// Marshal returns the Array of gs. Nil geometries are encoded as nulls.
+func Marshal(gs []geom.T, opts ...Option) (*Array, error) {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	arrayType, layout := o.geometryType, o.layout
+	for _, g := range gs {
+		if g == nil {
+			continue
+		}
+		t, err := geometryType(g)
+		if err != nil {
+			return nil, err
+		}
+		if arrayType, err = promote(arrayType, t); err != nil {
+			return nil, err
+		}
+		switch {
+		case g.Layout() == geom.NoLayout:
+		case layout == geom.NoLayout:
+			layout = g.Layout()
+		case g.Layout() != layout:
+			return nil, geom.ErrLayoutMismatch{Got: g.Layout(), Want: layout}
+		}
+	}
+	switch {
+	case arrayType == Unknown:
+		arrayType = Point
+	case o.geometryType != Unknown && arrayType != o.geometryType:
+		return nil, ErrTypeMismatch{Got: arrayType, Want: o.geometryType}
+	}
+	if layout == geom.NoLayout {
+		layout = geom.XY
+	}
+
+	e := &encoder{
+		array: &Array{
+			Type:    arrayType,
+			Layout:  layout,
+			Offsets: make([][]int32, numOffsets[arrayType]),
+		},
+		stride: layout.Stride(),
+	}
+	for i := range e.array.Offsets {
+		e.array.Offsets[i] = []int32{0}
+	}
+	for _, g := range gs {
+		e.encode(g)
+	}
+	if e.err != nil {
+		return nil, e.err
+	}
+
+	if e.hasNulls {
+		e.array.Validity = make([]byte, (len(gs)+7)/8)
+		for i, g := range gs {
+			if g != nil {
+				e.array.Validity[i/8] |= 1 << uint(i%8)
+			}
+		}
+	}
+	if o.interleaved {
+		e.array.Interleaved = e.flatCoords
+	} else {
+		numCoords := len(e.flatCoords) / e.stride
+		e.array.Separated = make([][]float64, e.stride)
+		for dim := range e.array.Separated {
+			e.array.Separated[dim] = make([]float64, numCoords)
+			for i := range e.array.Separated[dim] {
+				e.array.Separated[dim][i] = e.flatCoords[i*e.stride+dim]
+			}
+		}
+	}
+	return e.array, nil
+}
+
+// encode appends g to the Array.
+func (e *encoder) encode(g geom.T) {
+	if g == nil {
+		e.hasNulls = true
+	}
+	switch e.array.Type {
+	case Point:
+		if p, ok := g.(*geom.Point); ok && !p.Empty() {
+			e.appendCoords(p.FlatCoords())
+		} else {
+			e.appendEmptyPoint()
+		}
+	case LineString:
+		if ls, ok := g.(*geom.LineString); ok {
+			e.appendCoords(ls.FlatCoords())
+		}
+		e.appendOffset(0, e.numCoords())
+	case Polygon:
+		if p, ok := g.(*geom.Polygon); ok {
+			e.appendPolygon(p, 1)
+		}
+		e.appendOffset(0, len(e.array.Offsets[1])-1)
+	case MultiPoint:
+		switch g := g.(type) {
+		case *geom.Point:
+			if !g.Empty() {
+				e.appendCoords(g.FlatCoords())
+			}
+		case *geom.MultiPoint:
+			e.appendCoords(g.FlatCoords())
+		}
+		e.appendOffset(0, e.numCoords())
+	case MultiLineString:
+		switch g := g.(type) {
+		case *geom.LineString:
+			e.appendCoords(g.FlatCoords())
+			e.appendOffset(1, e.numCoords())
+		case *geom.MultiLineString:
+			for i := 0; i < g.NumLineStrings(); i++ {
+				ls := g.LineString(i)
+				e.appendCoords(ls.FlatCoords())
+				e.appendOffset(1, e.numCoords())
+			}
+		}
+		e.appendOffset(0, len(e.array.Offsets[1])-1)
+	case MultiPolygon:
+		switch g := g.(type) {
+		case *geom.Polygon:
+			e.appendPolygon(g, 2)
+			e.appendOffset(1, len(e.array.Offsets[2])-1)
+		case *geom.MultiPolygon:
+			for i := 0; i < g.NumPolygons(); i++ {
+				e.appendPolygon(g.Polygon(i), 2)
+				e.appendOffset(1, len(e.array.Offsets[2])-1)
+			}
+		}
+		e.appendOffset(0, len(e.array.Offsets[1])-1)
+	}
+}
+
+// appendPolygon appends the rings of p, with their offsets at level.
+func (e *encoder) appendPolygon(p *geom.Polygon, level int) {
+	for i := 0; i < p.NumLinearRings(); i++ {
+		ring := p.LinearRing(i)
+		e.appendCoords(ring.FlatCoords())
+		e.appendOffset(level, e.numCoords())
+	}
+}
+
+func (e *encoder) appendCoords(flatCoords []float64) {
+	e.flatCoords = append(e.flatCoords, flatCoords...)
+}
+
+// appendEmptyPoint appends the coordinates of an empty point, which are NaN.
+func (e *encoder) appendEmptyPoint() {
+	for i := 0; i < e.stride; i++ {
+		e.flatCoords = append(e.flatCoords, math.NaN())
+	}
+}
+
+func (e *encoder) appendOffset(level, offset int) {
+	if offset > math.MaxInt32 {
+		e.err = errOffsetOverflow
+		return
+	}
+	e.array.Offsets[level] = append(e.array.Offsets[level], int32(offset))
+}
+
+func (e *encoder) numCoords() int {
+	return len(e.flatCoords) / e.stride
+}
@@ -0,0 +1,228 @@
+// Package geoarrow implements encoding and decoding of slices of geometries
+// as GeoArrow native arrays. See https://geoarrow.org/format.
+//
+// A GeoArrow array stores the coordinates of all of its geometries in a
+// single buffer, either interleaved (x0, y0, x1, y1, ...) or separated into
+// one buffer per dimension, together with int32 offset buffers that divide
+// the coordinates into rings, lines, polygons, and geometries. An Array holds
+// these buffers as plain slices, which can be wrapped by an Arrow
+// implementation without copying. Decoding interleaved coordinates is also
+// zero-copy: the decoded geometries share the Array's coordinate buffer.
+//
+// All geometries in an Array have the same type and layout. Points are
+// promoted to MultiPoints, LineStrings to MultiLineStrings, and Polygons to
+// MultiPolygons when mixed with their multi types. GeometryCollections are
+// not supported.
+package geoarrow
+
+import (
+	"fmt"
+
+	"github.com/twpayne/go-geom"
+)
+
+// A GeometryType is a GeoArrow geometry type.
+type GeometryType int
+
+// Geometry types.
+const (
+	Unknown GeometryType = iota
+	Point
+	LineString
+	Polygon
+	MultiPoint
+	MultiLineString
+	MultiPolygon
+)
+
+var geometryTypeNames = [...]string{
+	Unknown:         "unknown",
+	Point:           "point",
+	LineString:      "linestring",
+	Polygon:         "polygon",
+	MultiPoint:      "multipoint",
+	MultiLineString: "multilinestring",
+	MultiPolygon:    "multipolygon",
+}
+
+// numOffsets is the number of offset buffers of each geometry type.
+var numOffsets = [...]int{
+	Point:           0,
+	LineString:      1,
+	Polygon:         2,
+	MultiPoint:      1,
+	MultiLineString: 2,
+	MultiPolygon:    3,
+}
+
+func (t GeometryType) String() string {
+	if t < 0 || int(t) >= len(geometryTypeNames) {
+		return fmt.Sprintf("GeometryType(%d)", int(t))
+	}
+	return geometryTypeNames[t]
+}
+
+// ExtensionName returns the name of the Arrow extension type of t, for
+// example geoarrow.point.
+func (t GeometryType) ExtensionName() string {
+	return "geoarrow." + t.String()
+}
+
+// An ErrTypeMismatch is returned when encoding geometries of incompatible
+// types.
+type ErrTypeMismatch struct {
+	Got  GeometryType
+	Want GeometryType
+}
+
+func (e ErrTypeMismatch) Error() string {
+	return fmt.Sprintf("geoarrow: type mismatch, got %s, want %s", e.Got, e.Want)
+}
+
+// An ErrInvalidArray is returned when decoding an Array whose buffers are
+// inconsistent.
+type ErrInvalidArray string
+
+func (e ErrInvalidArray) Error() string {
+	return "geoarrow: invalid array: " + string(e)
+}
+
+// An Array is a GeoArrow native array.
+type Array struct {
+	// Type is the geometry type.
+	Type GeometryType
+	// Layout is the layout of the coordinates, which determines their
+	// dimensions.
+	Layout geom.Layout
+	// Offsets are the offset buffers, from the outermost to the innermost.
+	// Points have none. LineStrings and MultiPoints have one, of coordinates
+	// per geometry. Polygons have two, of rings per geometry and coordinates
+	// per ring. MultiLineStrings have two, of lines per geometry and
+	// coordinates per line. MultiPolygons have three, of polygons per
+	// geometry, rings per polygon, and coordinates per ring.
+	Offsets [][]int32
+	// Interleaved holds the coordinates if they are interleaved.
+	Interleaved []float64
+	// Separated holds the coordinates, one buffer per dimension, if they are
+	// not interleaved.
+	Separated [][]float64
+	// Validity is the validity bitmap, in which the bit for a null geometry is
+	// zero. A nil Validity means that there are no nulls.
+	Validity []byte
+}
+
+// Len returns the number of geometries in a.
+func (a *Array) Len() int {
+	if a.Type == Point {
+		return a.numCoords()
+	}
+	if len(a.Offsets) == 0 || len(a.Offsets[0]) == 0 {
+		return 0
+	}
+	return len(a.Offsets[0]) - 1
+}
+
+// IsNull returns whether the geometry at index i is null.
+func (a *Array) IsNull(i int) bool {
+	return a.Validity != nil && a.Validity[i/8]&(1<<uint(i%8)) == 0
+}
+
+func (a *Array) numCoords() int {
+	if a.Separated != nil {
+		if len(a.Separated) == 0 {
+			return 0
+		}
+		return len(a.Separated[0])
+	}
+	if stride := a.Layout.Stride(); stride > 0 {
+		return len(a.Interleaved) / stride
+	}
+	return 0
+}
+
+// An Option configures encoding.
+type Option func(*options)
+
+type options struct {
+	geometryType GeometryType
+	layout       geom.Layout
+	interleaved  bool
+}
+
+// WithType sets the geometry type of the Array, for example to promote all
+// geometries to multi types or to choose the type of an Array of only nil
+// geometries. By default, the type is that of the geometries, or Point if
+// there are none.
+func WithType(geometryType GeometryType) Option {
+	return func(o *options) {
+		o.geometryType = geometryType
+	}
+}
+
+// WithLayout sets the layout of the Array. Geometries must have this layout,
+// or no layout. By default, the layout is that of the geometries, or XY if
+// there are none.
+func WithLayout(layout geom.Layout) Option {
+	return func(o *options) {
+		o.layout = layout
+	}
+}
+
+// WithInterleaved stores interleaved coordinates, rather than separated
+// coordinates.
+func WithInterleaved() Option {
+	return func(o *options) {
+		o.interleaved = true
+	}
+}
+
+// geometryType returns the type of g.
+func geometryType(g geom.T) (GeometryType, error) {
+	switch g.(type) {
+	case *geom.Point:
+		return Point, nil
+	case *geom.LineString:
+		return LineString, nil
+	case *geom.Polygon:
+		return Polygon, nil
+	case *geom.MultiPoint:
+		return MultiPoint, nil
+	case *geom.MultiLineString:
+		return MultiLineString, nil
+	case *geom.MultiPolygon:
+		return MultiPolygon, nil
+	default:
+		return Unknown, geom.ErrUnsupportedType{Value: g}
+	}
+}
+
+// promote returns the type of an Array containing geometries of types t1
+// and t2.
+func promote(t1, t2 GeometryType) (GeometryType, error) {
+	switch {
+	case t1 == Unknown || t1 == t2:
+		return t2, nil
+	case t2 == Unknown:
+		return t1, nil
+	case t1 == multi(t2):
+		return t1, nil
+	case t2 == multi(t1):
+		return t2, nil
+	default:
+		return Unknown, ErrTypeMismatch{Got: t2, Want: t1}
+	}
+}
+
+// multi returns the multi type of t.
+func multi(t GeometryType) GeometryType {
+	switch t {
+	case Point:
+		return MultiPoint
+	case LineString:
+		return MultiLineString
+	case Polygon:
+		return MultiPolygon
+	default:
+		return t
+	}
+}
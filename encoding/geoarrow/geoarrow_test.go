@@ -0,0 +1,354 @@
+package geoarrow
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+)
+
+func TestMarshal(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		gs   []geom.T
+		opts []Option
+		want *Array
+	}{
+		{
+			name: "point",
+			gs: []geom.T{
+				geom.NewPointFlat(geom.XY, []float64{1, 2}),
+				geom.NewPointFlat(geom.XY, []float64{3, 4}),
+			},
+			opts: []Option{WithInterleaved()},
+			want: &Array{
+				Type:        Point,
+				Layout:      geom.XY,
+				Offsets:     [][]int32{},
+				Interleaved: []float64{1, 2, 3, 4},
+			},
+		},
+		{
+			name: "line_string_separated",
+			gs: []geom.T{
+				geom.NewLineStringFlat(geom.XYZ, []float64{1, 2, 3, 4, 5, 6}),
+				nil,
+				geom.NewLineStringFlat(geom.XYZ, []float64{7, 8, 9}),
+			},
+			want: &Array{
+				Type:      LineString,
+				Layout:    geom.XYZ,
+				Offsets:   [][]int32{{0, 2, 2, 3}},
+				Separated: [][]float64{{1, 4, 7}, {2, 5, 8}, {3, 6, 9}},
+				Validity:  []byte{0x05},
+			},
+		},
+		{
+			name: "polygon",
+			gs: []geom.T{
+				geom.NewPolygonFlat(geom.XY, []float64{
+					0, 0, 3, 0, 3, 3, 0, 0,
+					1, 1, 2, 1, 2, 2, 1, 1,
+				}, []int{8, 16}),
+				geom.NewPolygon(geom.XY),
+			},
+			opts: []Option{WithInterleaved()},
+			want: &Array{
+				Type:    Polygon,
+				Layout:  geom.XY,
+				Offsets: [][]int32{{0, 2, 2}, {0, 4, 8}},
+				Interleaved: []float64{
+					0, 0, 3, 0, 3, 3, 0, 0,
+					1, 1, 2, 1, 2, 2, 1, 1,
+				},
+			},
+		},
+		{
+			name: "multi_polygon_promoted",
+			gs: []geom.T{
+				geom.NewPolygonFlat(geom.XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}, []int{8}),
+				geom.NewMultiPolygonFlat(geom.XY, []float64{
+					2, 2, 3, 2, 3, 3, 2, 2,
+					4, 4, 5, 4, 5, 5, 4, 4,
+				}, [][]int{{8}, {16}}),
+			},
+			opts: []Option{WithInterleaved()},
+			want: &Array{
+				Type:    MultiPolygon,
+				Layout:  geom.XY,
+				Offsets: [][]int32{{0, 1, 3}, {0, 1, 2, 3}, {0, 4, 8, 12}},
+				Interleaved: []float64{
+					0, 0, 1, 0, 1, 1, 0, 0,
+					2, 2, 3, 2, 3, 3, 2, 2,
+					4, 4, 5, 4, 5, 5, 4, 4,
+				},
+			},
+		},
+		{
+			name: "multi_line_string_with_type",
+			gs: []geom.T{
+				geom.NewLineStringFlat(geom.XY, []float64{1, 2, 3, 4}),
+			},
+			opts: []Option{WithType(MultiLineString), WithInterleaved()},
+			want: &Array{
+				Type:        MultiLineString,
+				Layout:      geom.XY,
+				Offsets:     [][]int32{{0, 1}, {0, 2}},
+				Interleaved: []float64{1, 2, 3, 4},
+			},
+		},
+		{
+			name: "empty",
+			want: &Array{
+				Type:      Point,
+				Layout:    geom.XY,
+				Offsets:   [][]int32{},
+				Separated: [][]float64{{}, {}},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Marshal(tc.gs, tc.opts...)
+			if err != nil {
+				t.Fatalf("Marshal(...) == _, %v, want _, <nil>", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Marshal(...) == %+v, _, want %+v, _", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		gs   []geom.T
+		want []geom.T
+	}{
+		{
+			name: "point",
+			gs: []geom.T{
+				geom.NewPointFlat(geom.XYM, []float64{1, 2, 3}),
+				nil,
+				geom.NewPointEmpty(geom.XYM),
+			},
+		},
+		{
+			name: "line_string",
+			gs: []geom.T{
+				geom.NewLineStringFlat(geom.XYZM, []float64{1, 2, 3, 4, 5, 6, 7, 8}),
+				geom.NewLineStringFlat(geom.XYZM, []float64{}),
+			},
+		},
+		{
+			name: "polygon",
+			gs: []geom.T{
+				geom.NewPolygonFlat(geom.XY, []float64{
+					0, 0, 3, 0, 3, 3, 0, 0,
+					1, 1, 2, 1, 2, 2, 1, 1,
+				}, []int{8, 16}),
+				nil,
+				geom.NewPolygonFlat(geom.XY, []float64{5, 5, 6, 5, 6, 6, 5, 5}, []int{8}),
+			},
+		},
+		{
+			name: "multi_point",
+			gs: []geom.T{
+				geom.NewMultiPointFlat(geom.XY, []float64{1, 2, 3, 4}),
+				geom.NewPointFlat(geom.XY, []float64{5, 6}),
+			},
+			want: []geom.T{
+				geom.NewMultiPointFlat(geom.XY, []float64{1, 2, 3, 4}),
+				geom.NewMultiPointFlat(geom.XY, []float64{5, 6}),
+			},
+		},
+		{
+			name: "multi_line_string",
+			gs: []geom.T{
+				geom.NewMultiLineStringFlat(geom.XY, []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, []int{4, 10}),
+				geom.NewMultiLineStringFlat(geom.XY, []float64{}, []int{}),
+			},
+		},
+		{
+			name: "multi_polygon",
+			gs: []geom.T{
+				geom.NewMultiPolygonFlat(geom.XYZ, []float64{
+					0, 0, 1, 3, 0, 1, 3, 3, 1, 0, 0, 1,
+					1, 1, 1, 2, 1, 1, 2, 2, 1, 1, 1, 1,
+					10, 10, 0, 11, 10, 0, 11, 11, 0, 10, 10, 0,
+				}, [][]int{{12, 24}, {36}}),
+				nil,
+				geom.NewMultiPolygonFlat(geom.XYZ, []float64{0, 0, 0, 1, 0, 0, 1, 1, 0, 0, 0, 0}, [][]int{{12}}),
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			want := tc.want
+			if want == nil {
+				want = tc.gs
+			}
+			for _, opts := range [][]Option{nil, {WithInterleaved()}} {
+				a, err := Marshal(tc.gs, opts...)
+				if err != nil {
+					t.Fatalf("Marshal(...) == _, %v, want _, <nil>", err)
+				}
+				got, err := Unmarshal(a)
+				if err != nil {
+					t.Fatalf("Unmarshal(...) == _, %v, want _, <nil>", err)
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("Unmarshal(Marshal(...)) == %v, _, want %v, _", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestUnmarshalZeroCopy(t *testing.T) {
+	a := &Array{
+		Type:        LineString,
+		Layout:      geom.XY,
+		Offsets:     [][]int32{{0, 2, 3}},
+		Interleaved: []float64{1, 2, 3, 4, 5, 6},
+	}
+	gs, err := Unmarshal(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.Interleaved[0] = 10
+	if got := gs[0].FlatCoords()[0]; got != 10 {
+		t.Errorf("gs[0].FlatCoords()[0] == %v, want 10", got)
+	}
+	if got := cap(gs[0].FlatCoords()); got != 4 {
+		t.Errorf("cap(gs[0].FlatCoords()) == %d, want 4", got)
+	}
+}
+
+func TestMarshalErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		gs   []geom.T
+		opts []Option
+		err  error
+	}{
+		{
+			name: "type_mismatch",
+			gs: []geom.T{
+				geom.NewPointFlat(geom.XY, []float64{1, 2}),
+				geom.NewLineStringFlat(geom.XY, []float64{1, 2, 3, 4}),
+			},
+			err: ErrTypeMismatch{Got: LineString, Want: Point},
+		},
+		{
+			name: "with_type_mismatch",
+			gs: []geom.T{
+				geom.NewMultiPointFlat(geom.XY, []float64{1, 2}),
+			},
+			opts: []Option{WithType(Point)},
+			err:  ErrTypeMismatch{Got: MultiPoint, Want: Point},
+		},
+		{
+			name: "layout_mismatch",
+			gs: []geom.T{
+				geom.NewPointFlat(geom.XY, []float64{1, 2}),
+				geom.NewPointFlat(geom.XYZ, []float64{1, 2, 3}),
+			},
+			err: geom.ErrLayoutMismatch{Got: geom.XYZ, Want: geom.XY},
+		},
+		{
+			name: "geometry_collection",
+			gs: []geom.T{
+				geom.NewGeometryCollection(),
+			},
+			err: geom.ErrUnsupportedType{Value: geom.NewGeometryCollection()},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := Marshal(tc.gs, tc.opts...); !reflect.DeepEqual(err, tc.err) {
+				t.Errorf("Marshal(...) == _, %v, want _, %v", err, tc.err)
+			}
+		})
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		a    *Array
+	}{
+		{
+			name: "unknown_type",
+			a:    &Array{Layout: geom.XY},
+		},
+		{
+			name: "separated_length",
+			a: &Array{
+				Type:      Point,
+				Layout:    geom.XY,
+				Separated: [][]float64{{1, 2}, {3}},
+			},
+		},
+		{
+			name: "interleaved_length",
+			a: &Array{
+				Type:        Point,
+				Layout:      geom.XY,
+				Interleaved: []float64{1, 2, 3},
+			},
+		},
+		{
+			name: "missing_offsets",
+			a: &Array{
+				Type:        Polygon,
+				Layout:      geom.XY,
+				Offsets:     [][]int32{{0, 1}},
+				Interleaved: []float64{1, 2},
+			},
+		},
+		{
+			name: "decreasing_offsets",
+			a: &Array{
+				Type:        LineString,
+				Layout:      geom.XY,
+				Offsets:     [][]int32{{0, 2, 1}},
+				Interleaved: []float64{1, 2, 3, 4},
+			},
+		},
+		{
+			name: "offset_out_of_range",
+			a: &Array{
+				Type:        MultiLineString,
+				Layout:      geom.XY,
+				Offsets:     [][]int32{{0, 2}, {0, 1}},
+				Interleaved: []float64{1, 2},
+			},
+		},
+		{
+			name: "validity_too_short",
+			a: &Array{
+				Type:        Point,
+				Layout:      geom.XY,
+				Interleaved: make([]float64, 18),
+				Validity:    []byte{0xff},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := Unmarshal(tc.a); err == nil {
+				t.Errorf("Unmarshal(...) == _, <nil>, want _, !<nil>")
+			} else if _, ok := err.(ErrInvalidArray); !ok {
+				t.Errorf("Unmarshal(...) == _, %v, want _, ErrInvalidArray", err)
+			}
+		})
+	}
+}
+
+func TestGeometryType(t *testing.T) {
+	if got, want := MultiPolygon.ExtensionName(), "geoarrow.multipolygon"; got != want {
+		t.Errorf("MultiPolygon.ExtensionName() == %q, want %q", got, want)
+	}
+	if got, want := GeometryType(math.MaxInt8).String(), "GeometryType(127)"; got != want {
+		t.Errorf("GeometryType(127).String() == %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,435 @@
+package geobuf
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/geojson"
+	"github.com/twpayne/go-geom/internal/protobuf"
+)
+
+// Unmarshal decodes the geometry in data.
+func Unmarshal(data []byte) (geom.T, error) {
+	d, objectData, err := newDecoder(data, fieldGeometry)
+	if err != nil {
+		return nil, err
+	}
+	g, err := d.decodeGeometry(objectData)
+	return g, d.err(err)
+}
+
+// UnmarshalStub returns the Stub of the geometry in data.
+func UnmarshalStub(data []byte) (*geom.Stub, error) {
+	g, err := Unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return geom.NewStub(g)
+}
+
+// UnmarshalFeature decodes the feature in data.
+func UnmarshalFeature(data []byte) (*geojson.Feature, error) {
+	d, objectData, err := newDecoder(data, fieldFeature)
+	if err != nil {
+		return nil, err
+	}
+	f, err := d.decodeFeature(objectData)
+	return f, d.err(err)
+}
+
+// UnmarshalFeatureCollection decodes the feature collection in data.
+func UnmarshalFeatureCollection(data []byte) (*geojson.FeatureCollection, error) {
+	d, objectData, err := newDecoder(data, fieldFeatureCollection)
+	if err != nil {
+		return nil, err
+	}
+	fc, err := d.decodeFeatureCollection(objectData)
+	return fc, d.err(err)
+}
+
+// A decoder decodes the object in a Data message.
+type decoder struct {
+	keys       []string
+	dimensions int
+	scale      float64
+	layout     geom.Layout
+}
+
+// newDecoder returns a new decoder for the Data message in data, which must
+// contain an object with field, and the object's data.
+func newDecoder(data []byte, field int) (*decoder, []byte, error) {
+	d := &decoder{
+		dimensions: 2,
+		scale:      math.Pow10(DefaultPrecision),
+	}
+	objectField := 0
+	var objectData []byte
+	pd := protobuf.NewDecoder(data)
+	for pd.Next() {
+		switch pd.Field() {
+		case fieldKeys:
+			d.keys = append(d.keys, string(pd.Bytes()))
+		case fieldDimensions:
+			d.dimensions = int(pd.Varint())
+		case fieldPrecision:
+			precision := pd.Varint()
+			if precision > maxPrecision {
+				return nil, nil, errInvalidData
+			}
+			d.scale = math.Pow10(int(precision))
+		case fieldFeatureCollection, fieldFeature, fieldGeometry:
+			objectField = pd.Field()
+			objectData = pd.Bytes()
+		default:
+			pd.Skip()
+		}
+	}
+	switch {
+	case pd.Err() != nil || d.dimensions < 2:
+		return nil, nil, errInvalidData
+	case objectField != field:
+		return nil, nil, ErrUnexpectedType(dataTypeName(objectField))
+	}
+	d.layout = layout(d.dimensions)
+	return d, objectData, nil
+}
+
+// err returns err, replacing protocol buffer errors.
+func (d *decoder) err(err error) error {
+	if err == protobuf.ErrInvalid {
+		return errInvalidData
+	}
+	return err
+}
+
+// decodeFeatureCollection decodes the feature collection in data.
+func (d *decoder) decodeFeatureCollection(data []byte) (*geojson.FeatureCollection, error) {
+	fc := &geojson.FeatureCollection{}
+	var values []interface{}
+	pd := protobuf.NewDecoder(data)
+	for pd.Next() {
+		switch pd.Field() {
+		case 1:
+			featureData := pd.Bytes()
+			if pd.Err() != nil {
+				break
+			}
+			f, err := d.decodeFeature(featureData)
+			if err != nil {
+				return nil, err
+			}
+			fc.Features = append(fc.Features, f)
+		case 13:
+			value, err := decodeValue(pd.Bytes())
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		case 15:
+			var err error
+			if fc.BBox, fc.ForeignMembers, err = d.decodeCustomProperties(pd.Varints(nil), values); err != nil {
+				return nil, err
+			}
+			values = nil
+		default:
+			pd.Skip()
+		}
+	}
+	return fc, pd.Err()
+}
+
+// decodeFeature decodes the feature in data.
+func (d *decoder) decodeFeature(data []byte) (*geojson.Feature, error) {
+	f := &geojson.Feature{}
+	var values []interface{}
+	pd := protobuf.NewDecoder(data)
+	for pd.Next() {
+		var err error
+		switch pd.Field() {
+		case 1:
+			geometryData := pd.Bytes()
+			if pd.Err() != nil {
+				break
+			}
+			f.Geometry, err = d.decodeGeometry(geometryData)
+		case 11:
+			f.ID = string(pd.Bytes())
+		case 12:
+			f.ID = strconv.FormatInt(protobuf.Unzigzag(pd.Varint()), 10)
+		case 13:
+			var value interface{}
+			if value, err = decodeValue(pd.Bytes()); err == nil {
+				values = append(values, value)
+			}
+		case 14:
+			f.Properties, err = d.decodeProperties(pd.Varints(nil), values)
+			values = nil
+		case 15:
+			f.BBox, f.ForeignMembers, err = d.decodeCustomProperties(pd.Varints(nil), values)
+			values = nil
+		default:
+			pd.Skip()
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return f, pd.Err()
+}
+
+// decodeProperties decodes the properties with the key and value indexes
+// indexes into values.
+func (d *decoder) decodeProperties(indexes []uint64, values []interface{}) (map[string]interface{}, error) {
+	properties := make(map[string]interface{}, len(indexes)/2)
+	err := d.forEachProperty(indexes, values, func(key string, value interface{}) error {
+		if raw, ok := value.(json.RawMessage); ok {
+			if err := json.Unmarshal(raw, &value); err != nil {
+				return err
+			}
+		}
+		properties[key] = value
+		return nil
+	})
+	return properties, err
+}
+
+// decodeCustomProperties decodes the custom properties with the key and
+// value indexes indexes into values as a bounding box and foreign members.
+func (d *decoder) decodeCustomProperties(indexes []uint64, values []interface{}) (*geom.Bounds, map[string]json.RawMessage, error) {
+	var bbox *geom.Bounds
+	var foreignMembers map[string]json.RawMessage
+	err := d.forEachProperty(indexes, values, func(key string, value interface{}) error {
+		raw, ok := value.(json.RawMessage)
+		if !ok {
+			var err error
+			if raw, err = json.Marshal(value); err != nil {
+				return err
+			}
+		}
+		if key != "bbox" {
+			if foreignMembers == nil {
+				foreignMembers = make(map[string]json.RawMessage)
+			}
+			foreignMembers[key] = raw
+			return nil
+		}
+		var bboxValue []float64
+		if err := json.Unmarshal(raw, &bboxValue); err != nil {
+			return err
+		}
+		layout := bboxLayout(len(bboxValue))
+		if layout == geom.NoLayout {
+			return errInvalidData
+		}
+		bbox = geom.NewBounds(layout).Set(bboxValue...)
+		return nil
+	})
+	return bbox, foreignMembers, err
+}
+
+// forEachProperty calls f for each property with the key and value indexes
+// indexes into values.
+func (d *decoder) forEachProperty(indexes []uint64, values []interface{}, f func(string, interface{}) error) error {
+	if len(indexes)%2 != 0 {
+		return errInvalidData
+	}
+	for i := 0; i < len(indexes); i += 2 {
+		keyIndex, valueIndex := indexes[i], indexes[i+1]
+		if keyIndex >= uint64(len(d.keys)) || valueIndex >= uint64(len(values)) {
+			return errInvalidData
+		}
+		if err := f(d.keys[keyIndex], values[valueIndex]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeValue decodes the property value in data. JSON values are returned
+// as json.RawMessages.
+func decodeValue(data []byte) (interface{}, error) {
+	var value interface{}
+	pd := protobuf.NewDecoder(data)
+	for pd.Next() {
+		switch pd.Field() {
+		case 1:
+			value = string(pd.Bytes())
+		case 2:
+			value = math.Float64frombits(pd.Fixed64())
+		case 3:
+			value = float64(pd.Varint())
+		case 4:
+			value = -float64(pd.Varint())
+		case 5:
+			value = pd.Varint() != 0
+		case 6:
+			value = json.RawMessage(pd.Bytes())
+		default:
+			pd.Skip()
+		}
+	}
+	if pd.Err() != nil {
+		return nil, pd.Err()
+	}
+	if value == nil {
+		return nil, errInvalidData
+	}
+	return value, nil
+}
+
+// decodeGeometry decodes the geometry in data.
+func (d *decoder) decodeGeometry(data []byte) (geom.T, error) {
+	geomType := uint64(geomTypePoint)
+	var lengths, coords []uint64
+	hasLengths := false
+	var members []geom.T
+	pd := protobuf.NewDecoder(data)
+	for pd.Next() {
+		switch pd.Field() {
+		case 1:
+			geomType = pd.Varint()
+		case 2:
+			lengths = pd.Varints(lengths)
+			hasLengths = true
+		case 3:
+			coords = pd.Varints(coords)
+		case 4:
+			memberData := pd.Bytes()
+			if pd.Err() != nil {
+				break
+			}
+			member, err := d.decodeGeometry(memberData)
+			if err != nil {
+				return nil, err
+			}
+			members = append(members, member)
+		default:
+			pd.Skip()
+		}
+	}
+	if pd.Err() != nil {
+		return nil, pd.Err()
+	}
+	c := &coordsDecoder{
+		coords: coords,
+		scale:  d.scale,
+		stride: d.dimensions,
+	}
+	var g geom.T
+	switch geomType {
+	case geomTypePoint:
+		if len(coords) == 0 {
+			return geom.NewPointEmpty(d.layout), nil
+		}
+		g = geom.NewPointFlat(d.layout, c.line(nil, 1, false))
+	case geomTypeMultiPoint:
+		g = geom.NewMultiPointFlat(d.layout, c.line(nil, len(coords)/d.dimensions, false))
+	case geomTypeLineString:
+		g = geom.NewLineStringFlat(d.layout, c.line(nil, len(coords)/d.dimensions, false))
+	case geomTypeMultiLineString:
+		if !hasLengths {
+			lengths = []uint64{uint64(len(coords) / d.dimensions)}
+		}
+		flatCoords, ends := c.lines(nil, nil, lengths, false)
+		g = geom.NewMultiLineStringFlat(d.layout, flatCoords, ends)
+	case geomTypePolygon:
+		if !hasLengths {
+			lengths = []uint64{uint64(len(coords) / d.dimensions)}
+		}
+		flatCoords, ends := c.lines(nil, nil, lengths, true)
+		g = geom.NewPolygonFlat(d.layout, flatCoords, ends)
+	case geomTypeMultiPolygon:
+		if !hasLengths {
+			lengths = []uint64{1, 1, uint64(len(coords) / d.dimensions)}
+		}
+		flatCoords, endss := c.polygons(lengths)
+		g = geom.NewMultiPolygonFlat(d.layout, flatCoords, endss)
+	case geomTypeGeometryCollection:
+		gc := geom.NewGeometryCollection()
+		if err := gc.Push(members...); err != nil {
+			return nil, err
+		}
+		g = gc
+	default:
+		return nil, errInvalidData
+	}
+	if c.invalid || len(c.coords) != 0 {
+		return nil, errInvalidData
+	}
+	return g, nil
+}
+
+// A coordsDecoder decodes delta-encoded coordinates. Errors are sticky.
+type coordsDecoder struct {
+	coords  []uint64
+	scale   float64
+	stride  int
+	invalid bool
+}
+
+// line appends n coordinates to flatCoords. If closed is true then the
+// first coordinate is repeated to close the ring.
+func (c *coordsDecoder) line(flatCoords []float64, n int, closed bool) []float64 {
+	if c.invalid || n > len(c.coords)/c.stride {
+		c.invalid = true
+		return flatCoords
+	}
+	start := len(flatCoords)
+	sum := make([]int64, c.stride)
+	for i := 0; i < n; i++ {
+		for j := 0; j < c.stride; j++ {
+			sum[j] += protobuf.Unzigzag(c.coords[j])
+			flatCoords = append(flatCoords, float64(sum[j])/c.scale)
+		}
+		c.coords = c.coords[c.stride:]
+	}
+	if closed && n > 0 {
+		flatCoords = append(flatCoords, flatCoords[start:start+c.stride]...)
+	}
+	return flatCoords
+}
+
+// lines appends lines with lengths coordinates to flatCoords and their ends
+// to ends.
+func (c *coordsDecoder) lines(flatCoords []float64, ends []int, lengths []uint64, closed bool) ([]float64, []int) {
+	for _, length := range lengths {
+		if length > uint64(len(c.coords)) {
+			c.invalid = true
+			return flatCoords, ends
+		}
+		flatCoords = c.line(flatCoords, int(length), closed)
+		ends = append(ends, len(flatCoords))
+	}
+	return flatCoords, ends
+}
+
+// polygons returns the polygons described by lengths, which contains the
+// number of polygons followed, for each polygon, by its number of rings and
+// their lengths.
+func (c *coordsDecoder) polygons(lengths []uint64) ([]float64, [][]int) {
+	if len(lengths) == 0 {
+		c.invalid = true
+		return nil, nil
+	}
+	var flatCoords []float64
+	var endss [][]int
+	numPolygons := lengths[0]
+	lengths = lengths[1:]
+	for i := uint64(0); i < numPolygons; i++ {
+		if len(lengths) == 0 || lengths[0] >= uint64(len(lengths)) {
+			c.invalid = true
+			return nil, nil
+		}
+		numRings := lengths[0]
+		var ends []int
+		flatCoords, ends = c.lines(flatCoords, make([]int, 0, numRings), lengths[1:1+numRings], true)
+		endss = append(endss, ends)
+		lengths = lengths[1+numRings:]
+	}
+	if len(lengths) != 0 {
+		c.invalid = true
+	}
+	return flatCoords, endss
+}
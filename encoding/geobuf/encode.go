@@ -0,0 +1,376 @@
+package geobuf
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/geojson"
+	"github.com/twpayne/go-geom/internal/protobuf"
+)
+
+// Marshal returns the geobuf encoding of g.
+func Marshal(g geom.T, opts ...Option) ([]byte, error) {
+	e, err := newEncoder(newOptions(opts), g)
+	if err != nil {
+		return nil, err
+	}
+	data, err := e.encodeGeometry(g)
+	if err != nil {
+		return nil, err
+	}
+	return e.data(fieldGeometry, data), nil
+}
+
+// MarshalFeature returns the geobuf encoding of f.
+func MarshalFeature(f *geojson.Feature, opts ...Option) ([]byte, error) {
+	e, err := newEncoder(newOptions(opts), f.Geometry)
+	if err != nil {
+		return nil, err
+	}
+	data, err := e.encodeFeature(f)
+	if err != nil {
+		return nil, err
+	}
+	return e.data(fieldFeature, data), nil
+}
+
+// MarshalFeatureCollection returns the geobuf encoding of fc.
+func MarshalFeatureCollection(fc *geojson.FeatureCollection, opts ...Option) ([]byte, error) {
+	gs := make([]geom.T, 0, len(fc.Features))
+	for _, f := range fc.Features {
+		gs = append(gs, f.Geometry)
+	}
+	e, err := newEncoder(newOptions(opts), gs...)
+	if err != nil {
+		return nil, err
+	}
+	var data []byte
+	for _, f := range fc.Features {
+		featureData, err := e.encodeFeature(f)
+		if err != nil {
+			return nil, err
+		}
+		data = protobuf.AppendBytesField(data, 1, featureData)
+	}
+	if data, err = e.encodeCustomProperties(data, fc.BBox, fc.ForeignMembers); err != nil {
+		return nil, err
+	}
+	return e.data(fieldFeatureCollection, data), nil
+}
+
+// An encoder encodes geometries and properties, accumulating the keys of
+// properties.
+type encoder struct {
+	precision  int
+	scale      float64
+	dimensions int
+	keys       []string
+	keyIndexes map[string]int
+}
+
+// newEncoder returns a new encoder for gs.
+func newEncoder(o options, gs ...geom.T) (*encoder, error) {
+	if o.precision < 0 || o.precision > maxPrecision {
+		return nil, ErrInvalidPrecision(o.precision)
+	}
+	e := &encoder{
+		precision:  o.precision,
+		scale:      math.Pow10(o.precision),
+		dimensions: 2,
+		keyIndexes: make(map[string]int),
+	}
+	for _, g := range gs {
+		e.extendDimensions(g)
+	}
+	return e, nil
+}
+
+// extendDimensions extends the number of dimensions of e to include those
+// of g.
+func (e *encoder) extendDimensions(g geom.T) {
+	if gc, ok := g.(*geom.GeometryCollection); ok {
+		for _, member := range gc.Geoms() {
+			e.extendDimensions(member)
+		}
+		return
+	}
+	if g != nil && g.Stride() > e.dimensions {
+		e.dimensions = g.Stride()
+	}
+}
+
+// data returns the Data message containing the keys, dimensions, precision,
+// and the object with field and value data.
+func (e *encoder) data(field int, data []byte) []byte {
+	var b []byte
+	for _, key := range e.keys {
+		b = protobuf.AppendBytesField(b, fieldKeys, []byte(key))
+	}
+	if e.dimensions != 2 {
+		b = protobuf.AppendVarintField(b, fieldDimensions, uint64(e.dimensions))
+	}
+	if e.precision != DefaultPrecision {
+		b = protobuf.AppendVarintField(b, fieldPrecision, uint64(e.precision))
+	}
+	return protobuf.AppendBytesField(b, field, data)
+}
+
+// encodeFeature encodes f.
+func (e *encoder) encodeFeature(f *geojson.Feature) ([]byte, error) {
+	var data []byte
+	if f.Geometry != nil {
+		geometryData, err := e.encodeGeometry(f.Geometry)
+		if err != nil {
+			return nil, err
+		}
+		data = protobuf.AppendBytesField(data, 1, geometryData)
+	}
+	if f.ID != "" {
+		data = protobuf.AppendBytesField(data, 11, []byte(f.ID))
+	}
+	properties := f.Properties
+	if f.RawProperties != nil {
+		if err := json.Unmarshal(f.RawProperties, &properties); err != nil {
+			return nil, err
+		}
+	}
+	values := make(map[string][]byte, len(properties))
+	for key, value := range properties {
+		valueData, err := encodeValue(value)
+		if err != nil {
+			return nil, err
+		}
+		values[key] = valueData
+	}
+	data = e.encodeProperties(data, 14, values)
+	return e.encodeCustomProperties(data, f.BBox, f.ForeignMembers)
+}
+
+// encodeCustomProperties appends the bounding box bbox and foreignMembers as
+// custom properties to data.
+func (e *encoder) encodeCustomProperties(data []byte, bbox *geom.Bounds, foreignMembers map[string]json.RawMessage) ([]byte, error) {
+	values := make(map[string][]byte, len(foreignMembers)+1)
+	for key, value := range foreignMembers {
+		values[key] = protobuf.AppendBytesField(nil, 6, value)
+	}
+	if bbox != nil {
+		var bboxValue []float64
+		switch bbox.Layout() {
+		case geom.XY, geom.XYM:
+			bboxValue = []float64{bbox.Min(0), bbox.Min(1), bbox.Max(0), bbox.Max(1)}
+		case geom.XYZ, geom.XYZM:
+			bboxValue = []float64{bbox.Min(0), bbox.Min(1), bbox.Min(2), bbox.Max(0), bbox.Max(1), bbox.Max(2)}
+		default:
+			return nil, geom.ErrUnsupportedLayout(bbox.Layout())
+		}
+		bboxData, err := json.Marshal(bboxValue)
+		if err != nil {
+			return nil, err
+		}
+		values["bbox"] = protobuf.AppendBytesField(nil, 6, bboxData)
+	}
+	return e.encodeProperties(data, 15, values), nil
+}
+
+// encodeProperties appends the encoded values, in key order, followed by the
+// indexes of their keys and values in field, to data.
+func (e *encoder) encodeProperties(data []byte, field int, values map[string][]byte) []byte {
+	if len(values) == 0 {
+		return data
+	}
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	indexes := make([]uint64, 0, 2*len(keys))
+	for i, key := range keys {
+		keyIndex, ok := e.keyIndexes[key]
+		if !ok {
+			keyIndex = len(e.keys)
+			e.keys = append(e.keys, key)
+			e.keyIndexes[key] = keyIndex
+		}
+		data = protobuf.AppendBytesField(data, 13, values[key])
+		indexes = append(indexes, uint64(keyIndex), uint64(i))
+	}
+	return protobuf.AppendPackedField(data, field, indexes)
+}
+
+// encodeValue encodes the property value v. Integral numbers are encoded
+// as integers, and values that are not strings, numbers, or bools as JSON.
+func encodeValue(v interface{}) ([]byte, error) {
+	switch v := v.(type) {
+	case string:
+		return protobuf.AppendBytesField(nil, 1, []byte(v)), nil
+	case float64:
+		return encodeFloatValue(v), nil
+	case float32:
+		return encodeFloatValue(float64(v)), nil
+	case int:
+		return encodeIntValue(int64(v)), nil
+	case int8:
+		return encodeIntValue(int64(v)), nil
+	case int16:
+		return encodeIntValue(int64(v)), nil
+	case int32:
+		return encodeIntValue(int64(v)), nil
+	case int64:
+		return encodeIntValue(v), nil
+	case uint:
+		return protobuf.AppendVarintField(nil, 3, uint64(v)), nil
+	case uint8:
+		return protobuf.AppendVarintField(nil, 3, uint64(v)), nil
+	case uint16:
+		return protobuf.AppendVarintField(nil, 3, uint64(v)), nil
+	case uint32:
+		return protobuf.AppendVarintField(nil, 3, uint64(v)), nil
+	case uint64:
+		return protobuf.AppendVarintField(nil, 3, v), nil
+	case bool:
+		if v {
+			return protobuf.AppendVarintField(nil, 5, 1), nil
+		}
+		return protobuf.AppendVarintField(nil, 5, 0), nil
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		return protobuf.AppendBytesField(nil, 6, data), nil
+	}
+}
+
+// encodeFloatValue encodes v as an integer if it is integral and as a double
+// otherwise.
+func encodeFloatValue(v float64) []byte {
+	switch {
+	case v != math.Trunc(v) || math.Abs(v) >= 1<<63:
+		return protobuf.AppendFixed64Field(nil, 2, math.Float64bits(v))
+	case v >= 0:
+		return protobuf.AppendVarintField(nil, 3, uint64(v))
+	default:
+		return protobuf.AppendVarintField(nil, 4, uint64(-v))
+	}
+}
+
+// encodeIntValue encodes v as a positive or negative integer.
+func encodeIntValue(v int64) []byte {
+	if v >= 0 {
+		return protobuf.AppendVarintField(nil, 3, uint64(v))
+	}
+	return protobuf.AppendVarintField(nil, 4, uint64(-v))
+}
+
+// encodeGeometry encodes g.
+func (e *encoder) encodeGeometry(g geom.T) ([]byte, error) {
+	var geomType int
+	var lengths, coords []uint64
+	hasLengths := false
+	var members [][]byte
+	var err error
+	if _, ok := g.(*geom.GeometryCollection); !ok && g != nil && g.Stride() < 2 {
+		return nil, geom.ErrUnsupportedLayout(g.Layout())
+	}
+	switch g := g.(type) {
+	case *geom.Point:
+		geomType = geomTypePoint
+		coords, err = e.appendLine(nil, g.FlatCoords(), g.Stride(), false)
+	case *geom.MultiPoint:
+		geomType = geomTypeMultiPoint
+		coords, err = e.appendLine(nil, g.FlatCoords(), g.Stride(), false)
+	case *geom.LineString:
+		geomType = geomTypeLineString
+		coords, err = e.appendLine(nil, g.FlatCoords(), g.Stride(), false)
+	case *geom.MultiLineString:
+		geomType = geomTypeMultiLineString
+		hasLengths = g.NumLineStrings() != 1
+		for it := g.EndsIter(); it.Next() && err == nil; {
+			lengths = append(lengths, uint64(len(it.FlatCoords())/g.Stride()))
+			coords, err = e.appendLine(coords, it.FlatCoords(), g.Stride(), false)
+		}
+	case *geom.Polygon:
+		geomType = geomTypePolygon
+		hasLengths = g.NumLinearRings() != 1
+		lengths, coords, err = e.appendPolygon(nil, nil, g.EndsIter(), g.Stride())
+	case *geom.MultiPolygon:
+		geomType = geomTypeMultiPolygon
+		hasLengths = g.NumPolygons() != 1 || g.Polygon(0).NumLinearRings() != 1
+		lengths = append(lengths, uint64(g.NumPolygons()))
+		for it := g.EndssIter(); it.Next() && err == nil; {
+			lengths = append(lengths, uint64(len(it.Ends())))
+			lengths, coords, err = e.appendPolygon(lengths, coords, it.EndsIter(), g.Stride())
+		}
+	case *geom.GeometryCollection:
+		geomType = geomTypeGeometryCollection
+		for _, member := range g.Geoms() {
+			memberData, err := e.encodeGeometry(member)
+			if err != nil {
+				return nil, err
+			}
+			members = append(members, memberData)
+		}
+	default:
+		return nil, geom.ErrUnsupportedType{Value: g}
+	}
+	if err != nil {
+		return nil, err
+	}
+	data := protobuf.AppendVarintField(nil, 1, uint64(geomType))
+	if hasLengths {
+		data = protobuf.AppendPackedField(data, 2, lengths)
+	}
+	if len(coords) > 0 {
+		data = protobuf.AppendPackedField(data, 3, coords)
+	}
+	for _, member := range members {
+		data = protobuf.AppendBytesField(data, 4, member)
+	}
+	return data, nil
+}
+
+// appendPolygon appends the numbers of coordinates of the rings of a
+// polygon, without their closing coordinates, to lengths, and their
+// coordinates to coords.
+func (e *encoder) appendPolygon(lengths, coords []uint64, it geom.EndsIter, stride int) ([]uint64, []uint64, error) {
+	for it.Next() {
+		n := len(it.FlatCoords()) / stride
+		if n > 0 {
+			n--
+		}
+		lengths = append(lengths, uint64(n))
+		var err error
+		if coords, err = e.appendLine(coords, it.FlatCoords(), stride, true); err != nil {
+			return nil, nil, err
+		}
+	}
+	return lengths, coords, nil
+}
+
+// appendLine appends the delta-encoded coordinates of the line flatCoords to
+// coords. If closed is true, the closing coordinate of the ring flatCoords is
+// omitted. Missing dimensions are encoded as zeros.
+func (e *encoder) appendLine(coords []uint64, flatCoords []float64, stride int, closed bool) ([]uint64, error) {
+	n := len(flatCoords) / stride
+	if closed && n > 0 {
+		n--
+	}
+	sum := make([]int64, e.dimensions)
+	for i := 0; i < n; i++ {
+		for j := 0; j < e.dimensions; j++ {
+			var ordinate float64
+			if j < stride {
+				ordinate = flatCoords[i*stride+j]
+			}
+			v := math.Round(ordinate * e.scale)
+			if !(math.Abs(v) < 1<<63) {
+				return nil, errCoordinateOutOfRange
+			}
+			coords = append(coords, protobuf.Zigzag(int64(v)-sum[j]))
+			sum[j] = int64(v)
+		}
+	}
+	return coords, nil
+}
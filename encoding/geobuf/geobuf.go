@@ -0,0 +1,135 @@
+// Package geobuf implements geobuf encoding and decoding, a compact protocol
+// buffer encoding of GeoJSON geometries, features, and feature collections.
+// See https://github.com/mapbox/geobuf.
+//
+// Coordinates are stored as integers, delta encoded, with a configurable
+// number of decimal places. Geometries are decoded with the XY, XYZ, or XYZM
+// layout according to the number of dimensions stored, as in GeoJSON, so M
+// values of XYM geometries are decoded as Z values. Features and feature
+// collections are the types of package geojson, of which IDs, properties,
+// bounding boxes, and foreign members are encoded. Property numbers are
+// decoded as float64s, as by encoding/json, and properties that are not
+// strings, numbers, or bools are stored as JSON.
+package geobuf
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/twpayne/go-geom"
+)
+
+// DefaultPrecision is the default number of decimal places of coordinates.
+const DefaultPrecision = 6
+
+// maxPrecision is the maximum number of decimal places of coordinates. Larger
+// precisions would overflow the integer encoding of typical coordinates.
+const maxPrecision = 15
+
+// Geometry types.
+const (
+	geomTypePoint              = 0
+	geomTypeMultiPoint         = 1
+	geomTypeLineString         = 2
+	geomTypeMultiLineString    = 3
+	geomTypePolygon            = 4
+	geomTypeMultiPolygon       = 5
+	geomTypeGeometryCollection = 6
+)
+
+// Fields of the Data message.
+const (
+	fieldKeys              = 1
+	fieldDimensions        = 2
+	fieldPrecision         = 3
+	fieldFeatureCollection = 4
+	fieldFeature           = 5
+	fieldGeometry          = 6
+)
+
+var (
+	errCoordinateOutOfRange = errors.New("geobuf: coordinate out of range")
+	errInvalidData          = errors.New("geobuf: invalid data")
+)
+
+// An ErrInvalidPrecision is returned when encoding with a precision that is
+// negative or too large.
+type ErrInvalidPrecision int
+
+func (e ErrInvalidPrecision) Error() string {
+	return fmt.Sprintf("geobuf: invalid precision %d", int(e))
+}
+
+// An ErrUnexpectedType is returned when decoding data that contain a
+// different type of object than the one requested, for example a Feature
+// when a geometry was expected.
+type ErrUnexpectedType string
+
+func (e ErrUnexpectedType) Error() string {
+	return fmt.Sprintf("geobuf: unexpected type %s", string(e))
+}
+
+// An Option configures encoding.
+type Option func(*options)
+
+type options struct {
+	precision int
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		precision: DefaultPrecision,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithPrecision sets the number of decimal places to which coordinates are
+// encoded. It must be between 0 and 15. The default is DefaultPrecision,
+// about 10cm for longitudes and latitudes.
+func WithPrecision(precision int) Option {
+	return func(o *options) {
+		o.precision = precision
+	}
+}
+
+// dataTypeName returns the GeoJSON name of the type of object stored in the
+// Data field.
+func dataTypeName(field int) string {
+	switch field {
+	case fieldFeatureCollection:
+		return "FeatureCollection"
+	case fieldFeature:
+		return "Feature"
+	default:
+		return "Geometry"
+	}
+}
+
+// layout returns the layout of coordinates with dimensions.
+func layout(dimensions int) geom.Layout {
+	switch dimensions {
+	case 2:
+		return geom.XY
+	case 3:
+		return geom.XYZ
+	case 4:
+		return geom.XYZM
+	default:
+		return geom.Layout(dimensions)
+	}
+}
+
+// bboxLayout returns the layout of a bounding box with n values.
+func bboxLayout(n int) geom.Layout {
+	switch n {
+	case 4:
+		return geom.XY
+	case 6:
+		return geom.XYZ
+	default:
+		return geom.NoLayout
+	}
+}
@@ -0,0 +1,259 @@
+package geobuf
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/conformance"
+	"github.com/twpayne/go-geom/encoding/geojson"
+)
+
+func TestMarshal(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		g    geom.T
+		opts []Option
+		want []byte
+	}{
+		{
+			name: "point",
+			g:    geom.NewPointFlat(geom.XY, []float64{1, 2}),
+			opts: []Option{WithPrecision(0)},
+			want: []byte{0x18, 0x00, 0x32, 0x06, 0x08, 0x00, 0x1a, 0x02, 0x02, 0x04},
+		},
+		{
+			name: "line_string",
+			g:    geom.NewLineStringFlat(geom.XYZ, []float64{1, 2, 3, 2, 1, 3}),
+			opts: []Option{WithPrecision(0)},
+			want: []byte{
+				0x10, 0x03, 0x18, 0x00,
+				0x32, 0x0a, 0x08, 0x02, 0x1a, 0x06, 0x02, 0x04, 0x06, 0x02, 0x01, 0x00,
+			},
+		},
+		{
+			name: "polygon",
+			g:    geom.NewPolygonFlat(geom.XY, []float64{0, 0, 0.1, 0, 0, 0.1, 0, 0}, []int{8}),
+			opts: []Option{WithPrecision(1)},
+			want: []byte{
+				0x18, 0x01,
+				0x32, 0x0a, 0x08, 0x04, 0x1a, 0x06, 0x00, 0x00, 0x02, 0x00, 0x01, 0x02,
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Marshal(tc.g, tc.opts...)
+			if err != nil {
+				t.Fatalf("Marshal(...) == _, %v, want _, <nil>", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Marshal(...) == %#v, _, want %#v, _", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		g    geom.T
+		want geom.T
+	}{
+		{
+			name: "point",
+			g:    geom.NewPointFlat(geom.XY, []float64{-71.064544, 42.28787}),
+		},
+		{
+			name: "point_empty",
+			g:    geom.NewPointEmpty(geom.XY),
+		},
+		{
+			name: "point_xyzm",
+			g:    geom.NewPointFlat(geom.XYZM, []float64{1, 2, 3, 4}),
+		},
+		{
+			name: "point_xym",
+			g:    geom.NewPointFlat(geom.XYM, []float64{1, 2, 3}),
+			want: geom.NewPointFlat(geom.XYZ, []float64{1, 2, 3}),
+		},
+		{
+			name: "point_rounded",
+			g:    geom.NewPointFlat(geom.XY, []float64{0.12345678, -0.12345678}),
+			want: geom.NewPointFlat(geom.XY, []float64{0.123457, -0.123457}),
+		},
+		{
+			name: "multi_point",
+			g:    geom.NewMultiPointFlat(geom.XY, []float64{1, 2, 3, 4}),
+		},
+		{
+			name: "line_string",
+			g:    geom.NewLineStringFlat(geom.XYZ, []float64{1, 2, 3, 4, 5, 6, -7, -8, -9}),
+		},
+		{
+			name: "multi_line_string_one",
+			g:    geom.NewMultiLineStringFlat(geom.XY, []float64{1, 2, 3, 4}, []int{4}),
+		},
+		{
+			name: "multi_line_string",
+			g:    geom.NewMultiLineStringFlat(geom.XY, []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, []int{4, 10}),
+		},
+		{
+			name: "polygon",
+			g: geom.NewPolygonFlat(geom.XY, []float64{
+				0, 0, 3, 0, 3, 3, 0, 3, 0, 0,
+				1, 1, 1, 2, 2, 2, 1, 1,
+			}, []int{10, 18}),
+		},
+		{
+			name: "multi_polygon_one",
+			g:    geom.NewMultiPolygonFlat(geom.XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}, [][]int{{8}}),
+		},
+		{
+			name: "multi_polygon",
+			g: geom.NewMultiPolygonFlat(geom.XYZ, []float64{
+				0, 0, 1, 3, 0, 1, 3, 3, 1, 0, 0, 1,
+				1, 1, 1, 2, 1, 1, 2, 2, 1, 1, 1, 1,
+				10, 10, 0, 11, 10, 0, 11, 11, 0, 10, 10, 0,
+			}, [][]int{{12, 24}, {36}}),
+		},
+		{
+			name: "geometry_collection",
+			g: geom.NewGeometryCollection().MustPush(
+				geom.NewPointFlat(geom.XY, []float64{1, 2}),
+				geom.NewLineStringFlat(geom.XY, []float64{3, 4, 5, 6}),
+			),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := Marshal(tc.g)
+			if err != nil {
+				t.Fatalf("Marshal(...) == _, %v, want _, <nil>", err)
+			}
+			got, err := Unmarshal(data)
+			if err != nil {
+				t.Fatalf("Unmarshal(...) == _, %v, want _, <nil>", err)
+			}
+			want := tc.want
+			if want == nil {
+				want = tc.g
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Unmarshal(Marshal(...)) == %#v, _, want %#v, _", got, want)
+			}
+		})
+	}
+}
+
+func TestFeatureCollectionRoundTrip(t *testing.T) {
+	fc := &geojson.FeatureCollection{
+		BBox: geom.NewBounds(geom.XY).Set(0, 0, 10, 10),
+		Features: []*geojson.Feature{
+			{
+				ID:       "a",
+				Geometry: geom.NewPointFlat(geom.XY, []float64{1, 2}),
+				Properties: map[string]interface{}{
+					"name":    "Zürich",
+					"count":   42.0,
+					"delta":   -7.0,
+					"ratio":   0.5,
+					"visible": true,
+					"tags":    []interface{}{"x", 1.0},
+					"extra":   map[string]interface{}{"a": nil},
+					"none":    nil,
+				},
+				ForeignMembers: map[string]json.RawMessage{
+					"title": json.RawMessage(`"first"`),
+				},
+			},
+			{
+				BBox:     geom.NewBounds(geom.XYZ).Set(0, 0, 0, 1, 1, 1),
+				Geometry: geom.NewLineStringFlat(geom.XYZ, []float64{0, 0, 0, 1, 1, 1}),
+				Properties: map[string]interface{}{
+					"name": "b",
+				},
+			},
+			{
+				ID: "c",
+			},
+		},
+		ForeignMembers: map[string]json.RawMessage{
+			"generator": json.RawMessage(`{"name":"go-geom"}`),
+		},
+	}
+	data, err := MarshalFeatureCollection(fc)
+	if err != nil {
+		t.Fatalf("MarshalFeatureCollection(...) == _, %v, want _, <nil>", err)
+	}
+	got, err := UnmarshalFeatureCollection(data)
+	if err != nil {
+		t.Fatalf("UnmarshalFeatureCollection(...) == _, %v, want _, <nil>", err)
+	}
+	// Geometries are decoded with the dimensions of the collection.
+	fc.Features[0].Geometry = geom.NewPointFlat(geom.XYZ, []float64{1, 2, 0})
+	if !reflect.DeepEqual(got, fc) {
+		t.Errorf("UnmarshalFeatureCollection(MarshalFeatureCollection(fc)) == %#v, _, want %#v, _", got, fc)
+	}
+}
+
+func TestFeatureRoundTrip(t *testing.T) {
+	f := &geojson.Feature{
+		ID:            "1",
+		Geometry:      geom.NewPointFlat(geom.XY, []float64{1, 2}),
+		RawProperties: json.RawMessage(`{"a":1}`),
+	}
+	data, err := MarshalFeature(f)
+	if err != nil {
+		t.Fatalf("MarshalFeature(...) == _, %v, want _, <nil>", err)
+	}
+	got, err := UnmarshalFeature(data)
+	if err != nil {
+		t.Fatalf("UnmarshalFeature(...) == _, %v, want _, <nil>", err)
+	}
+	want := &geojson.Feature{
+		ID:       "1",
+		Geometry: geom.NewPointFlat(geom.XY, []float64{1, 2}),
+		Properties: map[string]interface{}{
+			"a": 1.0,
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("UnmarshalFeature(MarshalFeature(f)) == %#v, _, want %#v, _", got, want)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	g := geom.NewPointFlat(geom.XY, []float64{1, 2})
+	if _, err := Marshal(g, WithPrecision(16)); err != ErrInvalidPrecision(16) {
+		t.Errorf("Marshal(g, WithPrecision(16)) == _, %v, want _, %v", err, ErrInvalidPrecision(16))
+	}
+	if _, err := Marshal(geom.NewPointFlat(geom.XY, []float64{1e300, 0})); err != errCoordinateOutOfRange {
+		t.Errorf("Marshal(...) == _, %v, want _, %v", err, errCoordinateOutOfRange)
+	}
+	data, err := MarshalFeature(&geojson.Feature{Geometry: g})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Unmarshal(data); err != ErrUnexpectedType("Feature") {
+		t.Errorf("Unmarshal(...) == _, %v, want _, %v", err, ErrUnexpectedType("Feature"))
+	}
+	for _, data := range [][]byte{
+		{0x32, 0x06, 0x08, 0x00},
+		{0x32, 0x03, 0x08, 0x09, 0x00},
+		{0x32, 0x04, 0x08, 0x00, 0x1a, 0x01, 0x02},
+		{0x32, 0x06, 0x08, 0x03, 0x12, 0x01, 0x05, 0x00},
+	} {
+		if _, err := Unmarshal(data); err != errInvalidData {
+			t.Errorf("Unmarshal(%v) == _, %v, want _, %v", data, err, errInvalidData)
+		}
+	}
+}
+
+func TestConformance(t *testing.T) {
+	conformance.Run(t, conformance.CodecFuncs{
+		MarshalFunc: func(g geom.T) ([]byte, error) {
+			return Marshal(g)
+		},
+		UnmarshalFunc: Unmarshal,
+	})
+}
@@ -0,0 +1,121 @@
+// Package geocsv implements reading and writing CSV files with geometries,
+// stored either as WKT in a single column or as point coordinates in X and Y
+// columns, and attributes in the other columns.
+//
+// The first line of a file is a header with the names of the columns.
+// Attributes are strings, as CSV is untyped. Empty geometry columns are
+// decoded as nil geometries.
+package geocsv
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/feature"
+)
+
+// DefaultWKTColumn is the default name of the WKT column when writing, as
+// used by GDAL.
+const DefaultWKTColumn = "WKT"
+
+// ErrNoGeometryColumn is returned when a header does not contain the
+// geometry columns.
+var ErrNoGeometryColumn = errors.New("geocsv: no geometry column")
+
+// An ErrInvalidGeometry is returned when the geometry of a record cannot be
+// decoded.
+type ErrInvalidGeometry struct {
+	Record int // record number, starting at 1 after the header
+	Err    error
+}
+
+func (e ErrInvalidGeometry) Error() string {
+	return fmt.Sprintf("geocsv: record %d: %v", e.Record, e.Err)
+}
+
+// A Record is a row of a CSV file.
+type Record struct {
+	Geom       geom.T
+	Attributes map[string]string
+}
+
+// An Option configures a Reader or Writer.
+type Option func(*options)
+
+type options struct {
+	wktColumn string
+	xColumn   string
+	yColumn   string
+	comma     rune
+	filter    feature.Filter
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		comma: ',',
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithWKTColumn sets the name of the column containing geometries as WKT.
+func WithWKTColumn(name string) Option {
+	return func(o *options) {
+		o.wktColumn = name
+		o.xColumn, o.yColumn = "", ""
+	}
+}
+
+// WithXYColumns sets the names of the columns containing the X and Y, or
+// longitude and latitude, coordinates of points.
+func WithXYColumns(x, y string) Option {
+	return func(o *options) {
+		o.wktColumn = ""
+		o.xColumn, o.yColumn = x, y
+	}
+}
+
+// WithComma sets the field delimiter. The default is a comma.
+func WithComma(comma rune) Option {
+	return func(o *options) {
+		o.comma = comma
+	}
+}
+
+// WithFeatureFilter sets a filter by which records are filtered when
+// reading. It is called with the record number, starting at 1 after the
+// header, and the attributes of each record. The geometries of the records
+// that it rejects are not decoded.
+func WithFeatureFilter(filter feature.Filter) Option {
+	return func(o *options) {
+		o.filter = filter
+	}
+}
+
+// autoWKTColumns and autoXYColumns are the names of the geometry columns
+// that are detected when reading, case insensitively, in order of
+// preference.
+var (
+	autoWKTColumns = []string{"wkt", "geometry", "geom", "the_geom"}
+	autoXYColumns  = [][2]string{
+		{"x", "y"},
+		{"lon", "lat"},
+		{"lng", "lat"},
+		{"long", "lat"},
+		{"longitude", "latitude"},
+	}
+)
+
+// columnIndex returns the index of the column name in header, or -1.
+func columnIndex(header []string, name string, foldCase bool) int {
+	for i, column := range header {
+		if column == name || foldCase && strings.EqualFold(column, name) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,217 @@
+package geocsv
+
+import (
+	"bytes"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+)
+
+func TestReader(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		data string
+		opts []Option
+		want []*Record
+	}{
+		{
+			name: "wkt",
+			data: "id,WKT,name\n" +
+				"1,POINT (1 2),a\n" +
+				"2,\"LINESTRING (1 2,3 4)\",b\n" +
+				"3,,c\n",
+			want: []*Record{
+				{
+					Geom:       geom.NewPointFlat(geom.XY, []float64{1, 2}),
+					Attributes: map[string]string{"id": "1", "name": "a"},
+				},
+				{
+					Geom:       geom.NewLineStringFlat(geom.XY, []float64{1, 2, 3, 4}),
+					Attributes: map[string]string{"id": "2", "name": "b"},
+				},
+				{
+					Attributes: map[string]string{"id": "3", "name": "c"},
+				},
+			},
+		},
+		{
+			name: "lon_lat",
+			data: "name,Latitude,Longitude\n" +
+				"a, 47.37 ,8.54\n" +
+				"b,,\n",
+			want: []*Record{
+				{
+					Geom:       geom.NewPointFlat(geom.XY, []float64{8.54, 47.37}),
+					Attributes: map[string]string{"name": "a"},
+				},
+				{
+					Attributes: map[string]string{"name": "b"},
+				},
+			},
+		},
+		{
+			name: "xy_columns",
+			data: "e;n;wkt\n" +
+				"1;2;x\n",
+			opts: []Option{WithXYColumns("e", "n"), WithComma(';')},
+			want: []*Record{
+				{
+					Geom:       geom.NewPointFlat(geom.XY, []float64{1, 2}),
+					Attributes: map[string]string{"wkt": "x"},
+				},
+			},
+		},
+		{
+			name: "wkt_column",
+			data: "shape,x,y\n" +
+				"POINT (5 6),1,2\n",
+			opts: []Option{WithWKTColumn("shape")},
+			want: []*Record{
+				{
+					Geom:       geom.NewPointFlat(geom.XY, []float64{5, 6}),
+					Attributes: map[string]string{"x": "1", "y": "2"},
+				},
+			},
+		},
+		{
+			name: "feature_filter",
+			data: "WKT,name\n" +
+				"POINT (1 2),a\n" +
+				"invalid,b\n" +
+				"POINT (3 4),c\n",
+			opts: []Option{
+				WithFeatureFilter(func(id string, properties map[string]interface{}) bool {
+					return id != "1" && properties["name"] != "b"
+				}),
+			},
+			want: []*Record{
+				{
+					Geom:       geom.NewPointFlat(geom.XY, []float64{3, 4}),
+					Attributes: map[string]string{"name": "c"},
+				},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := NewReader(strings.NewReader(tc.data), tc.opts...)
+			if err != nil {
+				t.Fatalf("NewReader(...) == _, %v, want _, <nil>", err)
+			}
+			got, err := r.ReadAll()
+			if err != nil {
+				t.Fatalf("r.ReadAll() == _, %v, want _, <nil>", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("r.ReadAll() == %v, _, want %v, _", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestReaderErrors(t *testing.T) {
+	if _, err := NewReader(strings.NewReader("a,b\n1,2\n")); err != ErrNoGeometryColumn {
+		t.Errorf("NewReader(...) == _, %v, want _, %v", err, ErrNoGeometryColumn)
+	}
+	if _, err := NewReader(strings.NewReader("WKT\n"), WithXYColumns("x", "y")); err != ErrNoGeometryColumn {
+		t.Errorf("NewReader(..., WithXYColumns(...)) == _, %v, want _, %v", err, ErrNoGeometryColumn)
+	}
+	r, err := NewReader(strings.NewReader("x,y\n1,2\n1,a\n3,4\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Read(); err != nil {
+		t.Fatalf("r.Read() == _, %v, want _, <nil>", err)
+	}
+	_, err = r.Read()
+	if e, ok := err.(ErrInvalidGeometry); !ok || e.Record != 2 {
+		t.Errorf("r.Read() == _, %v, want _, ErrInvalidGeometry{Record: 2}", err)
+	}
+	if _, err2 := r.Read(); err2 != err {
+		t.Errorf("r.Read() == _, %v, want _, %v", err2, err)
+	}
+}
+
+func TestWriter(t *testing.T) {
+	records := []*Record{
+		{
+			Geom:       geom.NewPointFlat(geom.XY, []float64{1.5, 2}),
+			Attributes: map[string]string{"name": "a, b", "extra": "ignored"},
+		},
+		{
+			Attributes: map[string]string{"id": "2"},
+		},
+	}
+	for _, tc := range []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{
+			name: "wkt",
+			want: "WKT,id,name\n" +
+				"POINT (1.5 2),,\"a, b\"\n" +
+				",2,\n",
+		},
+		{
+			name: "xy",
+			opts: []Option{WithXYColumns("lon", "lat"), WithComma('\t')},
+			want: "lon\tlat\tid\tname\n" +
+				"1.5\t2\t\ta, b\n" +
+				"\t\t2\t\n",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var b bytes.Buffer
+			w := NewWriter(&b, []string{"id", "name"}, tc.opts...)
+			for _, record := range records {
+				if err := w.Write(record); err != nil {
+					t.Fatalf("w.Write(...) == %v, want <nil>", err)
+				}
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("w.Flush() == %v, want <nil>", err)
+			}
+			if got := b.String(); got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestWriterErrors(t *testing.T) {
+	w := NewWriter(ioutil.Discard, nil, WithXYColumns("x", "y"))
+	g := geom.NewLineStringFlat(geom.XY, []float64{1, 2, 3, 4})
+	if err := w.Write(&Record{Geom: g}); !reflect.DeepEqual(err, geom.ErrUnsupportedType{Value: g}) {
+		t.Errorf("w.Write(...) == %v, want %v", err, geom.ErrUnsupportedType{Value: g})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	want := []*Record{
+		{
+			Geom:       geom.NewPolygonFlat(geom.XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}, []int{8}),
+			Attributes: map[string]string{"name": "\"quoted\"\nmultiline"},
+		},
+	}
+	var b bytes.Buffer
+	w := NewWriter(&b, []string{"name"})
+	for _, record := range want {
+		if err := w.Write(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewReader(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := r.ReadAll()
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("r.ReadAll() == %v, %v, want %v, <nil>", got, err, want)
+	}
+}
@@ -0,0 +1,166 @@
+package geocsv
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/feature"
+	"github.com/twpayne/go-geom/encoding/wkt"
+)
+
+// A Reader reads records from a CSV file.
+type Reader struct {
+	r         *csv.Reader
+	header    []string
+	wktIndex  int
+	xIndex    int
+	yIndex    int
+	filter    feature.Filter
+	numRecord int
+	err       error
+}
+
+// NewReader returns a new Reader that reads from r and reads its header. If
+// no geometry columns are set with WithWKTColumn or WithXYColumns then they
+// are detected from the header: a WKT column named WKT, geometry, geom, or
+// the_geom, or X and Y columns named x and y, lon and lat, lng and lat, long
+// and lat, or longitude and latitude, case insensitively.
+func NewReader(r io.Reader, opts ...Option) (*Reader, error) {
+	o := newOptions(opts)
+	csvReader := csv.NewReader(r)
+	csvReader.Comma = o.comma
+	header, err := csvReader.Read()
+	if err != nil {
+		return nil, err
+	}
+	reader := &Reader{
+		r:        csvReader,
+		header:   header,
+		wktIndex: -1,
+		xIndex:   -1,
+		yIndex:   -1,
+		filter:   o.filter,
+	}
+	switch {
+	case o.wktColumn != "":
+		reader.wktIndex = columnIndex(header, o.wktColumn, false)
+	case o.xColumn != "":
+		reader.xIndex = columnIndex(header, o.xColumn, false)
+		reader.yIndex = columnIndex(header, o.yColumn, false)
+	default:
+		for _, name := range autoWKTColumns {
+			if reader.wktIndex = columnIndex(header, name, true); reader.wktIndex != -1 {
+				break
+			}
+		}
+		if reader.wktIndex == -1 {
+			for _, names := range autoXYColumns {
+				reader.xIndex = columnIndex(header, names[0], true)
+				reader.yIndex = columnIndex(header, names[1], true)
+				if reader.xIndex != -1 && reader.yIndex != -1 {
+					break
+				}
+			}
+		}
+	}
+	if reader.wktIndex == -1 && (reader.xIndex == -1 || reader.yIndex == -1) {
+		return nil, ErrNoGeometryColumn
+	}
+	return reader, nil
+}
+
+// Header returns the names of all columns, including the geometry columns.
+func (r *Reader) Header() []string {
+	return r.header
+}
+
+// Read returns the next record that is accepted by the filter set with
+// WithFeatureFilter, if any. Its attributes are the values of all columns
+// except the geometry columns. It returns io.EOF after the last record.
+// Errors are sticky.
+func (r *Reader) Read() (*Record, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	record, err := r.read()
+	if err != nil {
+		r.err = err
+		return nil, err
+	}
+	return record, nil
+}
+
+// ReadAll returns all remaining records.
+func (r *Reader) ReadAll() ([]*Record, error) {
+	var records []*Record
+	for {
+		record, err := r.Read()
+		switch {
+		case err == io.EOF:
+			return records, nil
+		case err != nil:
+			return nil, err
+		}
+		records = append(records, record)
+	}
+}
+
+func (r *Reader) read() (*Record, error) {
+	for {
+		fields, err := r.r.Read()
+		if err != nil {
+			return nil, err
+		}
+		r.numRecord++
+		record := &Record{
+			Attributes: make(map[string]string, len(fields)),
+		}
+		for i, field := range fields {
+			if i != r.wktIndex && i != r.xIndex && i != r.yIndex {
+				record.Attributes[r.header[i]] = field
+			}
+		}
+		if r.filter != nil && !r.filter(strconv.Itoa(r.numRecord), properties(record.Attributes)) {
+			continue
+		}
+		if record.Geom, err = r.geom(fields); err != nil {
+			return nil, ErrInvalidGeometry{Record: r.numRecord, Err: err}
+		}
+		return record, nil
+	}
+}
+
+// properties returns attributes as properties for a feature.Filter.
+func properties(attributes map[string]string) map[string]interface{} {
+	properties := make(map[string]interface{}, len(attributes))
+	for key, value := range attributes {
+		properties[key] = value
+	}
+	return properties
+}
+
+// geom returns the geometry of fields.
+func (r *Reader) geom(fields []string) (geom.T, error) {
+	if r.wktIndex != -1 {
+		if strings.TrimSpace(fields[r.wktIndex]) == "" {
+			return nil, nil
+		}
+		return wkt.Unmarshal(fields[r.wktIndex])
+	}
+	xField, yField := strings.TrimSpace(fields[r.xIndex]), strings.TrimSpace(fields[r.yIndex])
+	if xField == "" && yField == "" {
+		return nil, nil
+	}
+	x, err := strconv.ParseFloat(xField, 64)
+	if err != nil {
+		return nil, err
+	}
+	y, err := strconv.ParseFloat(yField, 64)
+	if err != nil {
+		return nil, err
+	}
+	return geom.NewPointFlat(geom.XY, []float64{x, y}), nil
+}
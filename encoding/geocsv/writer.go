@@ -0,0 +1,99 @@
+package geocsv
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/wkt"
+)
+
+// A Writer writes records to a CSV file.
+type Writer struct {
+	w             *csv.Writer
+	columns       []string
+	o             options
+	headerWritten bool
+	fields        []string
+}
+
+// NewWriter returns a new Writer that writes records with the attribute
+// columns columns to w. The geometry columns, DefaultWKTColumn by default,
+// are written first. The header is written with the first record, or by
+// Flush if there are none.
+func NewWriter(w io.Writer, columns []string, opts ...Option) *Writer {
+	o := newOptions(opts)
+	if o.wktColumn == "" && o.xColumn == "" {
+		o.wktColumn = DefaultWKTColumn
+	}
+	csvWriter := csv.NewWriter(w)
+	csvWriter.Comma = o.comma
+	return &Writer{
+		w:       csvWriter,
+		columns: columns,
+		o:       o,
+	}
+}
+
+// Write writes record. Attributes that are not columns of w are ignored and
+// missing attributes are written as empty strings. With X and Y columns, the
+// geometry must be a Point. Nil geometries and empty points are written as
+// empty fields.
+func (w *Writer) Write(record *Record) error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	w.fields = w.fields[:0]
+	if w.o.wktColumn != "" {
+		value := ""
+		if record.Geom != nil {
+			var err error
+			if value, err = wkt.Marshal(record.Geom); err != nil {
+				return err
+			}
+		}
+		w.fields = append(w.fields, value)
+	} else {
+		x, y := "", ""
+		switch g := record.Geom.(type) {
+		case nil:
+		case *geom.Point:
+			if !g.Empty() {
+				x = strconv.FormatFloat(g.X(), 'f', -1, 64)
+				y = strconv.FormatFloat(g.Y(), 'f', -1, 64)
+			}
+		default:
+			return geom.ErrUnsupportedType{Value: g}
+		}
+		w.fields = append(w.fields, x, y)
+	}
+	for _, column := range w.columns {
+		w.fields = append(w.fields, record.Attributes[column])
+	}
+	return w.w.Write(w.fields)
+}
+
+// Flush writes any buffered data to the underlying io.Writer.
+func (w *Writer) Flush() error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	w.w.Flush()
+	return w.w.Error()
+}
+
+func (w *Writer) writeHeader() error {
+	if w.headerWritten {
+		return nil
+	}
+	w.headerWritten = true
+	header := make([]string, 0, 2+len(w.columns))
+	if w.o.wktColumn != "" {
+		header = append(header, w.o.wktColumn)
+	} else {
+		header = append(header, w.o.xColumn, w.o.yColumn)
+	}
+	header = append(header, w.columns...)
+	return w.w.Write(header)
+}
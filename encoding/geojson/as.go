@@ -0,0 +1,88 @@
+package geojson
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	geom "github.com/twpayne/go-geom"
+)
+
+var (
+	errInvalidTarget = errors.New("geojson: target must be a non-nil pointer to a geometry")
+	geomTType        = reflect.TypeOf((*geom.T)(nil)).Elem()
+)
+
+// An ErrUnexpectedType is returned when a geometry does not have the
+// requested type.
+type ErrUnexpectedType struct {
+	Got  interface{}
+	Want interface{}
+}
+
+func (e ErrUnexpectedType) Error() string {
+	return fmt.Sprintf("geojson: got %T, want %T", e.Got, e.Want)
+}
+
+// UnmarshalAs unmarshals data into g, which must be a pointer to a variable
+// of a concrete geometry type, for example **geom.Polygon, and returns an
+// ErrUnexpectedType if data encodes a geometry of a different type. It
+// replaces a call to Unmarshal followed by a type assertion:
+//
+//	var polygon *geom.Polygon
+//	if err := geojson.UnmarshalAs(data, &polygon); err != nil {
+//		return err
+//	}
+//
+// A null geometry sets the variable to nil.
+func UnmarshalAs(data []byte, g interface{}, opts ...Option) error {
+	v, err := asTarget(g)
+	if err != nil {
+		return err
+	}
+	var t geom.T
+	if err := unmarshal(data, &t, newOptions(opts)); err != nil {
+		return err
+	}
+	return assign(v, t)
+}
+
+// DecodeAs decodes g into v, which must be a pointer to a variable of a
+// concrete geometry type, as UnmarshalAs does.
+func (g *Geometry) DecodeAs(v interface{}, opts ...Option) error {
+	target, err := asTarget(v)
+	if err != nil {
+		return err
+	}
+	var t geom.T
+	if g != nil {
+		if t, err = g.decodeWithOptions(newOptions(opts)); err != nil {
+			return err
+		}
+	}
+	return assign(target, t)
+}
+
+// asTarget returns the variable pointed to by g, checking that it can hold a
+// geometry.
+func asTarget(g interface{}) (reflect.Value, error) {
+	v := reflect.ValueOf(g)
+	if v.Kind() != reflect.Ptr || v.IsNil() || !v.Elem().Type().Implements(geomTType) {
+		return reflect.Value{}, errInvalidTarget
+	}
+	return v.Elem(), nil
+}
+
+// assign sets v to t, or to its zero value if t is nil.
+func assign(v reflect.Value, t geom.T) error {
+	if t == nil {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	tv := reflect.ValueOf(t)
+	if !tv.Type().AssignableTo(v.Type()) {
+		return ErrUnexpectedType{Got: t, Want: v.Interface()}
+	}
+	v.Set(tv)
+	return nil
+}
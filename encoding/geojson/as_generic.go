@@ -0,0 +1,25 @@
+//go:build go1.18
+// +build go1.18
+
+package geojson
+
+import (
+	geom "github.com/twpayne/go-geom"
+)
+
+// UnmarshalGeometryAs unmarshals data into a geometry of type T, for example
+// *geom.Polygon, as UnmarshalAs does. A null geometry returns the zero T.
+//
+//	polygon, err := geojson.UnmarshalGeometryAs[*geom.Polygon](data)
+func UnmarshalGeometryAs[T geom.T](data []byte, opts ...Option) (T, error) {
+	var g T
+	err := UnmarshalAs(data, &g, opts...)
+	return g, err
+}
+
+// DecodeGeometryAs decodes g into a geometry of type T, as DecodeAs does.
+func DecodeGeometryAs[T geom.T](g *Geometry, opts ...Option) (T, error) {
+	var t T
+	err := g.DecodeAs(&t, opts...)
+	return t, err
+}
@@ -0,0 +1,47 @@
+//go:build go1.18
+// +build go1.18
+
+package geojson
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	geom "github.com/twpayne/go-geom"
+)
+
+func TestUnmarshalGeometryAs(t *testing.T) {
+	data := []byte(`{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,0]]]}`)
+	want := geom.NewPolygonFlat(geom.XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}, []int{8})
+	if got, err := UnmarshalGeometryAs[*geom.Polygon](data); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("UnmarshalGeometryAs[*geom.Polygon](%s) == %#v, %v, want %#v, <nil>", data, got, err, want)
+	}
+
+	data = []byte(`{"type":"Point","coordinates":[1,2]}`)
+	wantErr := ErrUnexpectedType{Got: geom.NewPointFlat(geom.XY, []float64{1, 2}), Want: (*geom.Polygon)(nil)}
+	var gotErr ErrUnexpectedType
+	if _, err := UnmarshalGeometryAs[*geom.Polygon](data); !errors.As(err, &gotErr) || !reflect.DeepEqual(gotErr, wantErr) {
+		t.Errorf("UnmarshalGeometryAs[*geom.Polygon](%s) == _, %v, want _, %v", data, err, wantErr)
+	}
+	if got, err := UnmarshalGeometryAs[*geom.Polygon](nullGeometry); err != nil || got != nil {
+		t.Errorf("UnmarshalGeometryAs[*geom.Polygon](%s) == %v, %v, want <nil>, <nil>", nullGeometry, got, err)
+	}
+	if got, err := UnmarshalGeometryAs[geom.T](data); err != nil || !reflect.DeepEqual(got, geom.NewPointFlat(geom.XY, []float64{1, 2})) {
+		t.Errorf("UnmarshalGeometryAs[geom.T](%s) == %v, %v", data, got, err)
+	}
+}
+
+func TestDecodeGeometryAs(t *testing.T) {
+	coords := json.RawMessage(`[[0,0],[1,1]]`)
+	gg := &Geometry{Type: "LineString", Coordinates: &coords}
+	want := geom.NewLineStringFlat(geom.XY, []float64{0, 0, 1, 1}).SetSRID(4326)
+	if got, err := DecodeGeometryAs[*geom.LineString](gg, WithSRIDFunc(func(int) (int, error) { return 4326, nil })); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("DecodeGeometryAs[*geom.LineString](gg) == %#v, %v, want %#v, <nil>", got, err, want)
+	}
+	var gotErr ErrUnexpectedType
+	if _, err := DecodeGeometryAs[*geom.MultiPoint](gg); !errors.As(err, &gotErr) || !reflect.DeepEqual(gotErr, ErrUnexpectedType{Got: geom.NewLineStringFlat(geom.XY, []float64{0, 0, 1, 1}), Want: (*geom.MultiPoint)(nil)}) {
+		t.Errorf("DecodeGeometryAs[*geom.MultiPoint](gg) == _, %v, want ErrUnexpectedType", err)
+	}
+}
@@ -0,0 +1,63 @@
+package geojson
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	geom "github.com/twpayne/go-geom"
+)
+
+func TestUnmarshalAs(t *testing.T) {
+	var polygon *geom.Polygon
+	data := []byte(`{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,0]]]}`)
+	if err := UnmarshalAs(data, &polygon); err != nil {
+		t.Fatalf("UnmarshalAs(%s, &polygon) == %v, want <nil>", data, err)
+	}
+	if want := geom.NewPolygonFlat(geom.XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}, []int{8}); !reflect.DeepEqual(polygon, want) {
+		t.Errorf("UnmarshalAs(%s, &polygon) set polygon to %#v, want %#v", data, polygon, want)
+	}
+
+	data = []byte(`{"type":"Point","coordinates":[1,2]}`)
+	wantErr := ErrUnexpectedType{Got: geom.NewPointFlat(geom.XY, []float64{1, 2}), Want: polygon}
+	var gotErr ErrUnexpectedType
+	if err := UnmarshalAs(data, &polygon); !errors.As(err, &gotErr) || !reflect.DeepEqual(gotErr, wantErr) {
+		t.Errorf("UnmarshalAs(%s, &polygon) == %v, want %v", data, err, wantErr)
+	}
+	if got, want := wantErr.Error(), "geojson: got *geom.Point, want *geom.Polygon"; got != want {
+		t.Errorf("wantErr.Error() == %q, want %q", got, want)
+	}
+
+	if err := UnmarshalAs(nullGeometry, &polygon); err != nil || polygon != nil {
+		t.Errorf("UnmarshalAs(%s, &polygon) == %v and set polygon to %v, want <nil> and <nil>", nullGeometry, err, polygon)
+	}
+
+	var g geom.T
+	if err := UnmarshalAs(data, &g); err != nil || !reflect.DeepEqual(g, geom.NewPointFlat(geom.XY, []float64{1, 2})) {
+		t.Errorf("UnmarshalAs(%s, &g) == %v and set g to %v", data, err, g)
+	}
+
+	for _, target := range []interface{}{nil, polygon, (**geom.Polygon)(nil), new(int)} {
+		if err := UnmarshalAs(data, target); !errors.Is(err, errInvalidTarget) {
+			t.Errorf("UnmarshalAs(%s, %#v) == %v, want %v", data, target, err, errInvalidTarget)
+		}
+	}
+}
+
+func TestGeometryDecodeAs(t *testing.T) {
+	var lineString *geom.LineString
+	coords := json.RawMessage(`[[0,0],[1,1]]`)
+	gg := &Geometry{Type: "LineString", Coordinates: &coords}
+	if err := gg.DecodeAs(&lineString, WithSRIDFunc(func(int) (int, error) { return 4326, nil })); err != nil {
+		t.Fatalf("gg.DecodeAs(&lineString) == %v, want <nil>", err)
+	}
+	if want := geom.NewLineStringFlat(geom.XY, []float64{0, 0, 1, 1}).SetSRID(4326); !reflect.DeepEqual(lineString, want) {
+		t.Errorf("gg.DecodeAs(&lineString) set lineString to %#v, want %#v", lineString, want)
+	}
+	var multiPoint *geom.MultiPoint
+	var gotErr ErrUnexpectedType
+	if err := gg.DecodeAs(&multiPoint); !errors.As(err, &gotErr) || !reflect.DeepEqual(gotErr, ErrUnexpectedType{Got: geom.NewLineStringFlat(geom.XY, []float64{0, 0, 1, 1}), Want: multiPoint}) {
+		t.Errorf("gg.DecodeAs(&multiPoint) == %v, want ErrUnexpectedType", err)
+	}
+}
@@ -0,0 +1,183 @@
+package geojson
+
+import (
+	"errors"
+	"strconv"
+
+	geom "github.com/twpayne/go-geom"
+)
+
+// ErrM is returned when M coordinates are rejected with WithM(MReject).
+var ErrM = errors.New("geojson: M coordinates not allowed")
+
+// An MPolicy determines how M coordinates, which GeoJSON does not support,
+// are handled.
+type MPolicy int
+
+// MPolicies.
+const (
+	// MKeep keeps M coordinates. When encoding, they are written after the
+	// X, Y, and any Z coordinates of each position, so M coordinates of
+	// geometries without Z coordinates will be read as Z coordinates. When
+	// decoding, positions with four elements are decoded as XYZM. This is
+	// the default.
+	MKeep MPolicy = iota
+	// MDrop silently drops M coordinates. When decoding, the fourth element
+	// of each position is dropped.
+	MDrop
+	// MReject rejects geometries with M coordinates, and positions with four
+	// elements, with ErrM.
+	MReject
+)
+
+// WithM sets how M coordinates are handled when encoding and how positions
+// with four elements are handled when decoding.
+func WithM(policy MPolicy) Option {
+	return func(o *options) {
+		o.m = policy
+	}
+}
+
+// WithZ sets whether Z coordinates are encoded. The default is true. Dropping
+// Z coordinates reduces the size of the encoding for consumers that only use
+// two dimensions, such as most web maps.
+func WithZ(z bool) Option {
+	return func(o *options) {
+		o.dropZ = !z
+	}
+}
+
+// prepareDims returns g with its Z and M coordinates dropped or rejected for
+// encoding.
+func (o options) prepareDims(g geom.T) (geom.T, error) {
+	if o.m == MReject && hasM(g) {
+		return nil, ErrM
+	}
+	return dropDims(g, o.dropZ, o.m == MDrop), nil
+}
+
+// decodeDims returns the decoded g with its M coordinates dropped or
+// rejected.
+func (o options) decodeDims(g geom.T) (geom.T, error) {
+	switch o.m {
+	case MDrop:
+		return dropDims(g, false, true), nil
+	case MReject:
+		if pointer, ok := findM(g); ok {
+			return nil, &DecodeError{
+				Pointer: pointer,
+				Err:     ErrM,
+			}
+		}
+	}
+	return g, nil
+}
+
+// hasM returns whether g or any of its members has M coordinates.
+func hasM(g geom.T) bool {
+	_, ok := findM(g)
+	return ok
+}
+
+// findM returns the pointer to the coordinates of the first geometry in g
+// with M coordinates.
+func findM(g geom.T) (string, bool) {
+	if gc, ok := g.(*geom.GeometryCollection); ok {
+		for i, member := range gc.Geoms() {
+			if pointer, ok := findM(member); ok {
+				return "/geometries/" + strconv.Itoa(i) + pointer, true
+			}
+		}
+		return "", false
+	}
+	if g == nil || g.Layout().MIndex() == -1 {
+		return "", false
+	}
+	return "/coordinates", true
+}
+
+// dropDims returns g with its Z coordinates dropped if dropZ is true and its
+// M coordinates dropped if dropM is true. g is returned unchanged if it has
+// no coordinates to drop.
+func dropDims(g geom.T, dropZ, dropM bool) geom.T {
+	if gc, ok := g.(*geom.GeometryCollection); ok {
+		geoms := gc.Geoms()
+		var result *geom.GeometryCollection
+		for i, member := range geoms {
+			dropped := dropDims(member, dropZ, dropM)
+			if result == nil && dropped != member {
+				result = geom.NewGeometryCollection().MustPush(geoms[:i]...)
+			}
+			if result != nil {
+				result.MustPush(dropped)
+			}
+		}
+		if result == nil {
+			return g
+		}
+		return result.SetSRID(gc.SRID())
+	}
+	if g == nil || g.Layout() == geom.NoLayout {
+		return g
+	}
+	layout := g.Layout()
+	zIndex, mIndex := layout.ZIndex(), layout.MIndex()
+	keepZ := zIndex != -1 && !dropZ
+	keepM := mIndex != -1 && !dropM
+	var newLayout geom.Layout
+	switch {
+	case keepZ && keepM:
+		newLayout = geom.XYZM
+	case keepZ:
+		newLayout = geom.XYZ
+	case keepM:
+		newLayout = geom.XYM
+	default:
+		newLayout = geom.XY
+	}
+	if newLayout == layout {
+		return g
+	}
+	stride, newStride := layout.Stride(), newLayout.Stride()
+	flatCoords := g.FlatCoords()
+	newFlatCoords := make([]float64, 0, len(flatCoords)/stride*newStride)
+	for i := 0; i < len(flatCoords); i += stride {
+		newFlatCoords = append(newFlatCoords, flatCoords[i], flatCoords[i+1])
+		if keepZ {
+			newFlatCoords = append(newFlatCoords, flatCoords[i+zIndex])
+		}
+		if keepM {
+			newFlatCoords = append(newFlatCoords, flatCoords[i+mIndex])
+		}
+	}
+	scale := func(ends []int) []int {
+		newEnds := make([]int, len(ends))
+		for i, end := range ends {
+			newEnds[i] = end / stride * newStride
+		}
+		return newEnds
+	}
+	switch g := g.(type) {
+	case *geom.Point:
+		if g.Empty() {
+			return geom.NewPointEmpty(newLayout).SetSRID(g.SRID())
+		}
+		return geom.NewPointFlat(newLayout, newFlatCoords).SetSRID(g.SRID())
+	case *geom.LineString:
+		return geom.NewLineStringFlat(newLayout, newFlatCoords).SetSRID(g.SRID())
+	case *geom.Polygon:
+		return geom.NewPolygonFlat(newLayout, newFlatCoords, scale(g.Ends())).SetSRID(g.SRID())
+	case *geom.MultiPoint:
+		return geom.NewMultiPointFlat(newLayout, newFlatCoords).SetSRID(g.SRID())
+	case *geom.MultiLineString:
+		return geom.NewMultiLineStringFlat(newLayout, newFlatCoords, scale(g.Ends())).SetSRID(g.SRID())
+	case *geom.MultiPolygon:
+		endss := make([][]int, len(g.Endss()))
+		for i, ends := range g.Endss() {
+			endss[i] = scale(ends)
+		}
+		return geom.NewMultiPolygonFlat(newLayout, newFlatCoords, endss).SetSRID(g.SRID())
+	default:
+		return g
+	}
+}
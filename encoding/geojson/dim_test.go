@@ -0,0 +1,168 @@
+package geojson
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	geom "github.com/twpayne/go-geom"
+)
+
+func TestMarshalDims(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		g       geom.T
+		opts    []Option
+		want    string
+		wantErr error
+	}{
+		{
+			name: "xyzm_default",
+			g:    geom.NewPointFlat(geom.XYZM, []float64{1, 2, 3, 4}),
+			want: `{"type":"Point","coordinates":[1,2,3,4]}`,
+		},
+		{
+			name: "xyzm_drop_m",
+			g:    geom.NewPointFlat(geom.XYZM, []float64{1, 2, 3, 4}),
+			opts: []Option{WithM(MDrop)},
+			want: `{"type":"Point","coordinates":[1,2,3]}`,
+		},
+		{
+			name: "xyzm_drop_z",
+			g:    geom.NewPointFlat(geom.XYZM, []float64{1, 2, 3, 4}),
+			opts: []Option{WithZ(false)},
+			want: `{"type":"Point","coordinates":[1,2,4]}`,
+		},
+		{
+			name: "xyzm_drop_zm",
+			g:    geom.NewPointFlat(geom.XYZM, []float64{1, 2, 3, 4}),
+			opts: []Option{WithZ(false), WithM(MDrop)},
+			want: `{"type":"Point","coordinates":[1,2]}`,
+		},
+		{
+			name: "xym_drop_m",
+			g:    geom.NewLineStringFlat(geom.XYM, []float64{1, 2, 3, 4, 5, 6}),
+			opts: []Option{WithM(MDrop)},
+			want: `{"type":"LineString","coordinates":[[1,2],[4,5]]}`,
+		},
+		{
+			name: "xyz_drop_z",
+			g:    geom.NewPolygonFlat(geom.XYZ, []float64{0, 0, 1, 1, 0, 2, 1, 1, 3, 0, 0, 1}, []int{12}),
+			opts: []Option{WithZ(false), WithBBox(true)},
+			want: `{"type":"Polygon","bbox":[0,0,1,1],"coordinates":[[[0,0],[1,0],[1,1],[0,0]]]}`,
+		},
+		{
+			name: "xyz_reject_m",
+			g:    geom.NewPointFlat(geom.XYZ, []float64{1, 2, 3}),
+			opts: []Option{WithM(MReject)},
+			want: `{"type":"Point","coordinates":[1,2,3]}`,
+		},
+		{
+			name:    "xym_reject_m",
+			g:       geom.NewPointFlat(geom.XYM, []float64{1, 2, 3}),
+			opts:    []Option{WithM(MReject)},
+			wantErr: ErrM,
+		},
+		{
+			name: "geometry_collection_drop_m",
+			g: geom.NewGeometryCollection().MustPush(
+				geom.NewPointFlat(geom.XY, []float64{1, 2}),
+				geom.NewMultiPolygonFlat(geom.XYM, []float64{0, 0, 9, 1, 0, 9, 1, 1, 9, 0, 0, 9}, [][]int{{12}}),
+			),
+			opts: []Option{WithM(MDrop)},
+			want: `{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[1,2]},{"type":"MultiPolygon","coordinates":[[[[0,0],[1,0],[1,1],[0,0]]]]}]}`,
+		},
+		{
+			name: "geometry_collection_reject_m",
+			g: geom.NewGeometryCollection().MustPush(
+				geom.NewPointFlat(geom.XY, []float64{1, 2}),
+				geom.NewMultiLineStringFlat(geom.XYZM, []float64{0, 0, 1, 2, 1, 1, 1, 2}, []int{8}),
+			),
+			opts:    []Option{WithM(MReject)},
+			wantErr: ErrM,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Marshal(tc.g, tc.opts...)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Marshal(%#v, ...) == _, %v, want _, %v", tc.g, err, tc.wantErr)
+			}
+			if string(got) != tc.want {
+				t.Errorf("Marshal(%#v, ...) == %s, want %s", tc.g, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDropDimsDoesNotModify(t *testing.T) {
+	g := geom.NewMultiPolygonFlat(geom.XYZM, []float64{0, 0, 1, 2, 1, 0, 1, 2, 1, 1, 1, 2, 0, 0, 1, 2}, [][]int{{16}}).SetSRID(4326)
+	original := g.Clone()
+	got := dropDims(g, true, false)
+	want := geom.NewMultiPolygonFlat(geom.XYM, []float64{0, 0, 2, 1, 0, 2, 1, 1, 2, 0, 0, 2}, [][]int{{12}}).SetSRID(4326)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("dropDims(...) == %#v, want %#v", got, want)
+	}
+	if !reflect.DeepEqual(g, original) {
+		t.Errorf("dropDims modified its argument")
+	}
+}
+
+func TestUnmarshalDims(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		data        string
+		opts        []Option
+		want        geom.T
+		wantErr     error
+		wantPointer string
+	}{
+		{
+			name: "xyzm_default",
+			data: `{"type":"Point","coordinates":[1,2,3,4]}`,
+			want: geom.NewPointFlat(geom.XYZM, []float64{1, 2, 3, 4}),
+		},
+		{
+			name: "xyzm_drop_m",
+			data: `{"type":"LineString","coordinates":[[1,2,3,4],[5,6,7,8]]}`,
+			opts: []Option{WithM(MDrop)},
+			want: geom.NewLineStringFlat(geom.XYZ, []float64{1, 2, 3, 5, 6, 7}),
+		},
+		{
+			name: "xyz_drop_m",
+			data: `{"type":"Point","coordinates":[1,2,3]}`,
+			opts: []Option{WithM(MDrop)},
+			want: geom.NewPointFlat(geom.XYZ, []float64{1, 2, 3}),
+		},
+		{
+			name:        "xyzm_reject_m",
+			data:        `{"type":"Point","coordinates":[1,2,3,4]}`,
+			opts:        []Option{WithM(MReject)},
+			wantErr:     ErrM,
+			wantPointer: "/coordinates",
+		},
+		{
+			name:        "geometry_collection_reject_m",
+			data:        `{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[1,2]},{"type":"Point","coordinates":[1,2,3,4]}]}`,
+			opts:        []Option{WithM(MReject)},
+			wantErr:     ErrM,
+			wantPointer: "/geometries/1/coordinates",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var got geom.T
+			err := Unmarshal([]byte(tc.data), &got, tc.opts...)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Unmarshal(%q, ...) == %v, want %v", tc.data, err, tc.wantErr)
+			}
+			if tc.wantPointer != "" {
+				var decodeErr *DecodeError
+				if !errors.As(err, &decodeErr) || decodeErr.Pointer != tc.wantPointer {
+					t.Errorf("Unmarshal(%q, ...) == %v, want pointer %s", tc.data, err, tc.wantPointer)
+				}
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Unmarshal(%q, ...) gives %#v, want %#v", tc.data, got, tc.want)
+			}
+		})
+	}
+}
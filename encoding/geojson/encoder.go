@@ -0,0 +1,217 @@
+package geojson
+
+import (
+	"io"
+
+	geom "github.com/twpayne/go-geom"
+)
+
+// encoderBufferSize is the size at which an Encoder writes its buffer.
+const encoderBufferSize = 32 * 1024
+
+// An Encoder writes geometries to an io.Writer. Unlike Marshal, it writes
+// coordinates directly from the flat coordinates of each geometry, a buffer
+// at a time, without building a [][][]geom.Coord or the complete encoding in
+// memory, so memory use does not grow with the size of the geometry. Its
+// output is identical to that of Marshal with the same options.
+type Encoder struct {
+	w       io.Writer
+	options options
+	buf     []byte
+	coord   []float64 // scratch space for rounding coordinates
+}
+
+// NewEncoder returns a new Encoder that writes to w.
+func NewEncoder(w io.Writer, opts ...Option) *Encoder {
+	return &Encoder{
+		w:       w,
+		options: newOptions(opts),
+	}
+}
+
+// Encode writes the GeoJSON encoding of g, followed by a newline, as
+// json.Encoder does. If Encode returns an error after the start of the
+// encoding has been written, for example because a coordinate is NaN, the
+// output is truncated.
+func (e *Encoder) Encode(g geom.T) error {
+	g, err := e.options.prepare(g)
+	if err != nil {
+		return err
+	}
+	e.buf = e.buf[:0]
+	if err := e.encode(g, e.options.bbox); err != nil {
+		return err
+	}
+	e.buf = append(e.buf, '\n')
+	return e.flush()
+}
+
+// encode writes g, with its bounding box if bbox is true.
+func (e *Encoder) encode(g geom.T, bbox bool) error {
+	if g == nil {
+		e.buf = append(e.buf, "null"...)
+		return nil
+	}
+	var typ string
+	switch g.(type) {
+	case *geom.Point:
+		typ = "Point"
+	case *geom.LineString:
+		typ = "LineString"
+	case *geom.Polygon:
+		typ = "Polygon"
+	case *geom.MultiPoint:
+		typ = "MultiPoint"
+	case *geom.MultiLineString:
+		typ = "MultiLineString"
+	case *geom.MultiPolygon:
+		typ = "MultiPolygon"
+	case *geom.GeometryCollection:
+		typ = "GeometryCollection"
+	default:
+		return geom.ErrUnsupportedType{Value: g}
+	}
+	gc, isCollection := g.(*geom.GeometryCollection)
+	if !isCollection && len(g.FlatCoords()) == 0 {
+		// Empty geometries are small, and are encoded like Marshal encodes
+		// them.
+		return e.encodeEmpty(g, bbox)
+	}
+	e.buf = append(e.buf, `{"type":`...)
+	e.buf = appendString(e.buf, typ)
+	if bbox {
+		if err := e.encodeBBox(g); err != nil {
+			return err
+		}
+	}
+	if isCollection {
+		if gc.NumGeoms() > 0 {
+			e.buf = append(e.buf, `,"geometries":[`...)
+			for i, member := range gc.Geoms() {
+				if i > 0 {
+					e.buf = append(e.buf, ',')
+				}
+				if err := e.encode(member, false); err != nil {
+					return err
+				}
+			}
+			e.buf = append(e.buf, ']')
+		}
+		e.buf = append(e.buf, '}')
+		return nil
+	}
+	e.buf = append(e.buf, `,"coordinates":`...)
+	flatCoords, stride := g.FlatCoords(), g.Stride()
+	var err error
+	switch g := g.(type) {
+	case *geom.Point:
+		err = e.encodePosition(flatCoords)
+	case *geom.LineString, *geom.MultiPoint:
+		err = e.encodePositions(flatCoords, stride)
+	case *geom.Polygon, *geom.MultiLineString:
+		_, err = e.encodeLines(flatCoords, 0, g.Ends(), stride)
+	case *geom.MultiPolygon:
+		e.buf = append(e.buf, '[')
+		offset := 0
+		for i, ends := range g.Endss() {
+			if i > 0 {
+				e.buf = append(e.buf, ',')
+			}
+			if offset, err = e.encodeLines(flatCoords, offset, ends, stride); err != nil {
+				return err
+			}
+		}
+		e.buf = append(e.buf, ']')
+	}
+	if err != nil {
+		return err
+	}
+	e.buf = append(e.buf, '}')
+	return nil
+}
+
+// encodeEmpty writes the empty geometry g.
+func (e *Encoder) encodeEmpty(g geom.T, bbox bool) error {
+	geometry, err := encode(g, e.options)
+	if err != nil {
+		return err
+	}
+	if bbox {
+		if geometry.BBox, err = boundsBBox(g.Bounds()); err != nil {
+			return err
+		}
+		e.options.roundFloats(geometry.BBox)
+	}
+	e.buf, err = appendGeometry(e.buf, geometry)
+	return err
+}
+
+// encodeBBox writes the bbox member of g.
+func (e *Encoder) encodeBBox(g geom.T) error {
+	bbox, err := boundsBBox(g.Bounds())
+	if err != nil || bbox == nil {
+		return err
+	}
+	e.options.roundFloats(bbox)
+	e.buf = append(e.buf, `,"bbox":`...)
+	e.buf, err = appendCoords0(e.buf, bbox)
+	return err
+}
+
+// encodeLines writes the lines of flatCoords starting at offset and ending at
+// ends, and returns the offset of the end of the last line.
+func (e *Encoder) encodeLines(flatCoords []float64, offset int, ends []int, stride int) (int, error) {
+	e.buf = append(e.buf, '[')
+	for i, end := range ends {
+		if i > 0 {
+			e.buf = append(e.buf, ',')
+		}
+		if err := e.encodePositions(flatCoords[offset:end], stride); err != nil {
+			return 0, err
+		}
+		offset = end
+	}
+	e.buf = append(e.buf, ']')
+	return offset, nil
+}
+
+// encodePositions writes the positions of flatCoords, writing the buffer to
+// the underlying io.Writer as it fills.
+func (e *Encoder) encodePositions(flatCoords []float64, stride int) error {
+	e.buf = append(e.buf, '[')
+	for i := 0; i < len(flatCoords); i += stride {
+		if i > 0 {
+			e.buf = append(e.buf, ',')
+		}
+		if err := e.encodePosition(flatCoords[i : i+stride]); err != nil {
+			return err
+		}
+		if len(e.buf) >= encoderBufferSize {
+			if err := e.flush(); err != nil {
+				return err
+			}
+		}
+	}
+	e.buf = append(e.buf, ']')
+	return nil
+}
+
+// encodePosition writes the position coord, rounded to the configured
+// precision.
+func (e *Encoder) encodePosition(coord []float64) error {
+	if e.options.precision != 0 {
+		e.coord = append(e.coord[:0], coord...)
+		e.options.roundFloats(e.coord)
+		coord = e.coord
+	}
+	var err error
+	e.buf, err = appendCoords0(e.buf, coord)
+	return err
+}
+
+// flush writes the buffer to the underlying io.Writer.
+func (e *Encoder) flush() error {
+	_, err := e.w.Write(e.buf)
+	e.buf = e.buf[:0]
+	return err
+}
@@ -0,0 +1,137 @@
+package geojson
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	geom "github.com/twpayne/go-geom"
+)
+
+func TestEncoder(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		g    geom.T
+		opts []Option
+	}{
+		{
+			name: "nil",
+		},
+		{
+			name: "point",
+			g:    geom.NewPointFlat(geom.XY, []float64{1, 2}),
+		},
+		{
+			name: "point_no_layout",
+			g:    geom.NewPoint(geom.NoLayout),
+		},
+		{
+			name: "line_string",
+			g:    geom.NewLineStringFlat(geom.XYZ, []float64{1, 2, 3, 4, 5, 6}),
+			opts: []Option{WithBBox(true)},
+		},
+		{
+			name: "line_string_empty",
+			g:    geom.NewLineString(geom.XY),
+			opts: []Option{WithBBox(true)},
+		},
+		{
+			name: "polygon",
+			g:    geom.NewPolygonFlat(geom.XY, []float64{0, 0, 1, 1, 1, 0, 0, 0, 0.25, 0.125, 0.5, 0.125, 0.5, 0.25, 0.25, 0.125}, []int{8, 16}),
+			opts: []Option{WithOrientation(true)},
+		},
+		{
+			name: "polygon_empty",
+			g:    geom.NewPolygon(geom.XY),
+		},
+		{
+			name: "multi_point",
+			g:    geom.NewMultiPointFlat(geom.XY, []float64{1e-7, 1e21, -0.5, 123456789.123456789}),
+		},
+		{
+			name: "multi_line_string",
+			g:    geom.NewMultiLineStringFlat(geom.XYM, []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}, []int{6, 12}),
+			opts: []Option{WithM(MDrop)},
+		},
+		{
+			name: "multi_polygon",
+			g: geom.NewMultiPolygonFlat(geom.XY, []float64{
+				0.123456789, 0, 1, 0, 1, 1, 0.123456789, 0,
+				2, 0, 3, 0, 3, 1, 2, 0,
+			}, [][]int{{8}, {16}}),
+			opts: []Option{WithPrecision(3), WithBBox(true)},
+		},
+		{
+			name: "multi_polygon_empty",
+			g:    geom.NewMultiPolygon(geom.XY),
+		},
+		{
+			name: "geometry_collection",
+			g: geom.NewGeometryCollection().MustPush(
+				geom.NewPointFlat(geom.XY, []float64{1, 2}),
+				geom.NewLineStringFlat(geom.XY, []float64{1, 2, 3, 4}),
+			),
+			opts: []Option{WithBBox(true)},
+		},
+		{
+			name: "geometry_collection_empty",
+			g:    geom.NewGeometryCollection(),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			want, err := Marshal(tc.g, tc.opts...)
+			if err != nil {
+				t.Fatalf("Marshal(...) == _, %v, want _, <nil>", err)
+			}
+			var buf bytes.Buffer
+			if err := NewEncoder(&buf, tc.opts...).Encode(tc.g); err != nil {
+				t.Fatalf("Encode(...) == %v, want <nil>", err)
+			}
+			if got := buf.String(); got != string(want)+"\n" {
+				t.Errorf("Encode(...) wrote %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+// A countingWriter counts the calls to its Write method.
+type countingWriter struct {
+	bytes.Buffer
+	writes int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return w.Buffer.Write(p)
+}
+
+func TestEncoderLarge(t *testing.T) {
+	flatCoords := make([]float64, 0, 2*100001)
+	for i := 0; i < 100000; i++ {
+		angle := 2 * math.Pi * float64(i) / 100000
+		flatCoords = append(flatCoords, math.Cos(angle), math.Sin(angle))
+	}
+	flatCoords = append(flatCoords, flatCoords[0], flatCoords[1])
+	g := geom.NewMultiPolygonFlat(geom.XY, flatCoords, [][]int{{len(flatCoords)}})
+	want, err := Marshal(g)
+	if err != nil {
+		t.Fatalf("Marshal(...) == _, %v, want _, <nil>", err)
+	}
+	w := &countingWriter{}
+	if err := NewEncoder(w).Encode(g); err != nil {
+		t.Fatalf("Encode(...) == %v, want <nil>", err)
+	}
+	if got := w.String(); got != string(want)+"\n" {
+		t.Errorf("Encode(...) did not write the same as Marshal")
+	}
+	if min := len(want) / encoderBufferSize; w.writes < min {
+		t.Errorf("Encode(...) wrote %d times, want at least %d", w.writes, min)
+	}
+}
+
+func TestEncoderNaN(t *testing.T) {
+	g := geom.NewLineStringFlat(geom.XY, []float64{0, 0, math.NaN(), 1})
+	if err := NewEncoder(&bytes.Buffer{}).Encode(g); err == nil {
+		t.Errorf("Encode(...) == <nil>, want an error")
+	}
+}
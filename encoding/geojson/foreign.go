@@ -0,0 +1,63 @@
+package geojson
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Members defined by RFC 7946, which are never foreign members.
+var (
+	featureMembers = map[string]bool{
+		"type":       true,
+		"id":         true,
+		"bbox":       true,
+		"geometry":   true,
+		"properties": true,
+	}
+	featureCollectionMembers = map[string]bool{
+		"type":     true,
+		"bbox":     true,
+		"features": true,
+	}
+)
+
+// decodeForeignMembers returns the members of the JSON object data that are
+// not in known, or nil if there are none.
+func decodeForeignMembers(data []byte, known map[string]bool) (map[string]json.RawMessage, error) {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil {
+		return nil, err
+	}
+	for name := range members {
+		if known[name] {
+			delete(members, name)
+		}
+	}
+	if len(members) == 0 {
+		return nil, nil
+	}
+	return members, nil
+}
+
+// appendForeignMembers adds the members of foreignMembers that are not in
+// known to the encoded JSON object data, in order of name.
+func appendForeignMembers(data []byte, foreignMembers map[string]json.RawMessage, known map[string]bool) ([]byte, error) {
+	members := make(map[string]json.RawMessage, len(foreignMembers))
+	for name, value := range foreignMembers {
+		if !known[name] {
+			members[name] = value
+		}
+	}
+	if len(members) == 0 {
+		return data, nil
+	}
+	encodedMembers, err := json.Marshal(members)
+	if err != nil {
+		return nil, err
+	}
+	data = data[:len(data)-1]
+	if !bytes.HasSuffix(data, []byte("{")) {
+		data = append(data, ',')
+	}
+	return append(data, encodedMembers[1:]...), nil
+}
@@ -0,0 +1,113 @@
+package geojson
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestForeignMembers(t *testing.T) {
+	featureData := `{"type":"Feature","geometry":null,"properties":{"a":1},"metadata":{"source":"survey"},"name":"feature"}`
+	feature, err := UnmarshalFeature([]byte(featureData))
+	if err != nil {
+		t.Fatalf("UnmarshalFeature(...) == _, %v, want _, <nil>", err)
+	}
+	wantForeignMembers := map[string]json.RawMessage{
+		"metadata": json.RawMessage(`{"source":"survey"}`),
+		"name":     json.RawMessage(`"feature"`),
+	}
+	if !reflect.DeepEqual(feature.ForeignMembers, wantForeignMembers) {
+		t.Errorf("feature.ForeignMembers == %s, want %s", feature.ForeignMembers, wantForeignMembers)
+	}
+	if got, err := json.Marshal(feature); err != nil || string(got) != featureData {
+		t.Errorf("json.Marshal(feature) == %s, %v, want %s, <nil>", got, err, featureData)
+	}
+
+	fcData := `{"type":"FeatureCollection","features":[` + featureData + `],"crs":null,"name":"collection"}`
+	for _, tc := range []struct {
+		name      string
+		unmarshal func() (*FeatureCollection, error)
+	}{
+		{
+			name: "unmarshal_json",
+			unmarshal: func() (*FeatureCollection, error) {
+				fc := &FeatureCollection{}
+				return fc, json.Unmarshal([]byte(fcData), fc)
+			},
+		},
+		{
+			name: "unmarshal_feature_collection",
+			unmarshal: func() (*FeatureCollection, error) {
+				return UnmarshalFeatureCollection([]byte(fcData))
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			fc, err := tc.unmarshal()
+			if err != nil {
+				t.Fatalf("unmarshal == _, %v, want _, <nil>", err)
+			}
+			want := map[string]json.RawMessage{
+				"crs":  json.RawMessage(`null`),
+				"name": json.RawMessage(`"collection"`),
+			}
+			if !reflect.DeepEqual(fc.ForeignMembers, want) {
+				t.Errorf("fc.ForeignMembers == %s, want %s", fc.ForeignMembers, want)
+			}
+			if !reflect.DeepEqual(fc.Features[0].ForeignMembers, wantForeignMembers) {
+				t.Errorf("fc.Features[0].ForeignMembers == %s, want %s", fc.Features[0].ForeignMembers, wantForeignMembers)
+			}
+			if got, err := json.Marshal(fc); err != nil || string(got) != fcData {
+				t.Errorf("json.Marshal(fc) == %s, %v, want %s, <nil>", got, err, fcData)
+			}
+		})
+	}
+
+	f := &Feature{ForeignMembers: map[string]json.RawMessage{"type": json.RawMessage(`"Other"`)}}
+	want := `{"type":"Feature","geometry":null,"properties":null}`
+	if got, err := json.Marshal(f); err != nil || string(got) != want {
+		t.Errorf("json.Marshal(f) == %s, %v, want %s, <nil>", got, err, want)
+	}
+}
+
+func TestForeignMembersStream(t *testing.T) {
+	data := `{"name":"collection","type":"FeatureCollection","features":[{"type":"Feature","geometry":null,"properties":null,"title":"x"}],"metadata":{}}`
+	d := NewFeatureCollectionDecoder(strings.NewReader(data))
+	var features []*Feature
+	for {
+		f, err := d.Decode()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("d.Decode() == _, %v, want _, <nil>", err)
+		}
+		features = append(features, f)
+	}
+	want := map[string]json.RawMessage{
+		"metadata": json.RawMessage(`{}`),
+		"name":     json.RawMessage(`"collection"`),
+	}
+	if !reflect.DeepEqual(d.ForeignMembers(), want) {
+		t.Errorf("d.ForeignMembers() == %s, want %s", d.ForeignMembers(), want)
+	}
+
+	var b bytes.Buffer
+	e := NewFeatureCollectionEncoder(&b)
+	e.SetForeignMembers(d.ForeignMembers())
+	for _, f := range features {
+		if err := e.Encode(f); err != nil {
+			t.Fatalf("e.Encode(...) == %v, want <nil>", err)
+		}
+	}
+	if err := e.Close(); err != nil {
+		t.Fatalf("e.Close() == %v, want <nil>", err)
+	}
+	wantData := `{"type":"FeatureCollection","features":[{"type":"Feature","geometry":null,"properties":null,"title":"x"}],"metadata":{},"name":"collection"}`
+	if got := b.String(); got != wantData {
+		t.Errorf("got %s, want %s", got, wantData)
+	}
+}
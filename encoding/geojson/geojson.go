@@ -1,12 +1,20 @@
 // Package geojson implements GeoJSON encoding and decoding.
+//
+// When built with the tinygo or geojsonlean build tags, geometries are
+// encoded and decoded without reflection, which reduces binary size and
+// allows use with TinyGo and WebAssembly. Feature properties are always
+// encoded and decoded with encoding/json.
 package geojson
 
 import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"strconv"
 
 	geom "github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/feature"
+	"github.com/twpayne/go-geom/geo"
 )
 
 var nullGeometry = []byte("null")
@@ -32,30 +40,78 @@ func (e ErrUnsupportedType) Error() string {
 // A Geometry is a geometry in GeoJSON format.
 type Geometry struct {
 	Type        string           `json:"type"`
+	BBox        []float64        `json:"bbox,omitempty"`
 	Coordinates *json.RawMessage `json:"coordinates,omitempty"`
 	Geometries  []*Geometry      `json:"geometries,omitempty"`
 }
 
-// A Feature is a GeoJSON Feature.
+// A Feature is a GeoJSON Feature. Its properties are either decoded into
+// Properties or, when decoded with WithRawProperties, kept undecoded in
+// RawProperties. When encoding, a non-nil RawProperties is written verbatim
+// in place of Properties.
+//
+// ForeignMembers contains the members that are not defined by RFC 7946, such
+// as those added by other tools, so that they survive a round trip. Foreign
+// members named like members defined by RFC 7946 are not encoded.
 type Feature struct {
-	ID         string
-	BBox       *geom.Bounds
-	Geometry   geom.T
-	Properties map[string]interface{}
+	ID             string
+	BBox           *geom.Bounds
+	Geometry       geom.T
+	Properties     map[string]interface{}
+	RawProperties  json.RawMessage
+	ForeignMembers map[string]json.RawMessage
 }
 
 type geojsonFeature struct {
-	Type       string                 `json:"type"`
-	ID         string                 `json:"id,omitempty"`
-	BBox       []float64              `json:"bbox,omitempty"`
-	Geometry   *Geometry              `json:"geometry"`
-	Properties map[string]interface{} `json:"properties"`
+	Type       string      `json:"type"`
+	ID         string      `json:"id,omitempty"`
+	BBox       []float64   `json:"bbox,omitempty"`
+	Geometry   *Geometry   `json:"geometry"`
+	Properties interface{} `json:"properties"`
 }
 
-// A FeatureCollection is a GeoJSON FeatureCollection.
+// A geojsonFeatureHeader is a geojsonFeature whose members have been split
+// but not yet parsed.
+type geojsonFeatureHeader struct {
+	Type           json.RawMessage
+	ID             json.RawMessage
+	BBox           json.RawMessage
+	Geometry       json.RawMessage
+	Properties     json.RawMessage
+	CRS            json.RawMessage
+	ForeignMembers map[string]json.RawMessage
+}
+
+// decodeFeatureHeader splits the members of the JSON object data, parsing it
+// only once.
+func decodeFeatureHeader(data []byte) (*geojsonFeatureHeader, error) {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil {
+		return nil, err
+	}
+	gf := &geojsonFeatureHeader{
+		Type:       members["type"],
+		ID:         members["id"],
+		BBox:       members["bbox"],
+		Geometry:   members["geometry"],
+		Properties: members["properties"],
+		CRS:        members["crs"],
+	}
+	for name := range featureMembers {
+		delete(members, name)
+	}
+	if len(members) != 0 {
+		gf.ForeignMembers = members
+	}
+	return gf, nil
+}
+
+// A FeatureCollection is a GeoJSON FeatureCollection. Its ForeignMembers are
+// handled like those of a Feature.
 type FeatureCollection struct {
-	BBox     *geom.Bounds
-	Features []*Feature
+	BBox           *geom.Bounds
+	Features       []*Feature
+	ForeignMembers map[string]json.RawMessage
 }
 
 type geojsonFeatureCollection struct {
@@ -64,6 +120,15 @@ type geojsonFeatureCollection struct {
 	Features []*Feature `json:"features"`
 }
 
+// A geojsonFeatureCollectionHeader is a geojsonFeatureCollection whose
+// features have not yet been parsed.
+type geojsonFeatureCollectionHeader struct {
+	Type     string            `json:"type"`
+	BBox     []float64         `json:"bbox,omitempty"`
+	Features []json.RawMessage `json:"features"`
+	CRS      json.RawMessage   `json:"crs,omitempty"`
+}
+
 func guessLayout0(coords0 []float64) (geom.Layout, error) {
 	switch n := len(coords0); n {
 	case 0, 1:
@@ -101,7 +166,34 @@ func guessLayout3(coords3 [][][]geom.Coord) (geom.Layout, error) {
 }
 
 // Decode decodes g to a geometry.
-func (g *Geometry) Decode() (geom.T, error) {
+func (g *Geometry) Decode(opts ...Option) (geom.T, error) {
+	return g.decodeWithOptions(newOptions(opts))
+}
+
+func (g *Geometry) decodeWithOptions(o options) (geom.T, error) {
+	t, err := g.decode()
+	if err != nil {
+		return nil, err
+	}
+	if t, err = o.decodeDims(t); err != nil {
+		return nil, err
+	}
+	if o.strict {
+		if err := checkStrict(t); err != nil {
+			return nil, err
+		}
+	}
+	if o.sridFunc != nil {
+		srid, err := o.sridFunc(0)
+		if err != nil {
+			return nil, err
+		}
+		setSRID(t, srid)
+	}
+	return t, nil
+}
+
+func (g *Geometry) decode() (geom.T, error) {
 	if g == nil {
 		return nil, nil
 	}
@@ -111,86 +203,110 @@ func (g *Geometry) Decode() (geom.T, error) {
 			return geom.NewPoint(geom.NoLayout), nil
 		}
 		var coords geom.Coord
-		if err := json.Unmarshal(*g.Coordinates, &coords); err != nil {
-			return nil, err
+		if err := unmarshalCoords(*g.Coordinates, &coords); err != nil {
+			return nil, g.coordsError(err, 0)
 		}
 		layout, err := guessLayout0(coords)
 		if err != nil {
-			return nil, err
+			return nil, g.coordsError(err, 0)
+		}
+		t, err := geom.NewPoint(layout).SetCoords(coords)
+		if err != nil {
+			return nil, g.coordsError(err, 0)
 		}
-		return geom.NewPoint(layout).SetCoords(coords)
+		return t, nil
 	case "LineString":
 		if g.Coordinates == nil {
 			return geom.NewLineString(geom.NoLayout), nil
 		}
 		var coords []geom.Coord
-		if err := json.Unmarshal(*g.Coordinates, &coords); err != nil {
-			return nil, err
+		if err := unmarshalCoords(*g.Coordinates, &coords); err != nil {
+			return nil, g.coordsError(err, 1)
 		}
 		layout, err := guessLayout1(coords)
 		if err != nil {
-			return nil, err
+			return nil, g.coordsError(err, 1)
 		}
-		return geom.NewLineString(layout).SetCoords(coords)
+		t, err := geom.NewLineString(layout).SetCoords(coords)
+		if err != nil {
+			return nil, g.coordsError(err, 1)
+		}
+		return t, nil
 	case "Polygon":
 		if g.Coordinates == nil {
 			return geom.NewPolygon(geom.NoLayout), nil
 		}
 		var coords [][]geom.Coord
-		if err := json.Unmarshal(*g.Coordinates, &coords); err != nil {
-			return nil, err
+		if err := unmarshalCoords(*g.Coordinates, &coords); err != nil {
+			return nil, g.coordsError(err, 2)
 		}
 		layout, err := guessLayout2(coords)
 		if err != nil {
-			return nil, err
+			return nil, g.coordsError(err, 2)
+		}
+		t, err := geom.NewPolygon(layout).SetCoords(coords)
+		if err != nil {
+			return nil, g.coordsError(err, 2)
 		}
-		return geom.NewPolygon(layout).SetCoords(coords)
+		return t, nil
 	case "MultiPoint":
 		if g.Coordinates == nil {
 			return geom.NewMultiPoint(geom.NoLayout), nil
 		}
 		var coords []geom.Coord
-		if err := json.Unmarshal(*g.Coordinates, &coords); err != nil {
-			return nil, err
+		if err := unmarshalCoords(*g.Coordinates, &coords); err != nil {
+			return nil, g.coordsError(err, 1)
 		}
 		layout, err := guessLayout1(coords)
 		if err != nil {
-			return nil, err
+			return nil, g.coordsError(err, 1)
+		}
+		t, err := geom.NewMultiPoint(layout).SetCoords(coords)
+		if err != nil {
+			return nil, g.coordsError(err, 1)
 		}
-		return geom.NewMultiPoint(layout).SetCoords(coords)
+		return t, nil
 	case "MultiLineString":
 		if g.Coordinates == nil {
 			return geom.NewMultiLineString(geom.NoLayout), nil
 		}
 		var coords [][]geom.Coord
-		if err := json.Unmarshal(*g.Coordinates, &coords); err != nil {
-			return nil, err
+		if err := unmarshalCoords(*g.Coordinates, &coords); err != nil {
+			return nil, g.coordsError(err, 2)
 		}
 		layout, err := guessLayout2(coords)
 		if err != nil {
-			return nil, err
+			return nil, g.coordsError(err, 2)
 		}
-		return geom.NewMultiLineString(layout).SetCoords(coords)
+		t, err := geom.NewMultiLineString(layout).SetCoords(coords)
+		if err != nil {
+			return nil, g.coordsError(err, 2)
+		}
+		return t, nil
 	case "MultiPolygon":
 		if g.Coordinates == nil {
 			return geom.NewMultiPolygon(geom.NoLayout), nil
 		}
 		var coords [][][]geom.Coord
-		if err := json.Unmarshal(*g.Coordinates, &coords); err != nil {
-			return nil, err
+		if err := unmarshalCoords(*g.Coordinates, &coords); err != nil {
+			return nil, g.coordsError(err, 3)
 		}
 		layout, err := guessLayout3(coords)
 		if err != nil {
-			return nil, err
+			return nil, g.coordsError(err, 3)
+		}
+		t, err := geom.NewMultiPolygon(layout).SetCoords(coords)
+		if err != nil {
+			return nil, g.coordsError(err, 3)
 		}
-		return geom.NewMultiPolygon(layout).SetCoords(coords)
+		return t, nil
 	case "GeometryCollection":
 		geoms := make([]geom.T, len(g.Geometries))
 		for i, subGeometry := range g.Geometries {
 			var err error
-			geoms[i], err = subGeometry.Decode()
+			geoms[i], err = subGeometry.decode()
 			if err != nil {
-				return nil, err
+				return nil, atPointer(err, "/geometries/"+strconv.Itoa(i))
 			}
 		}
 		gc := geom.NewGeometryCollection()
@@ -199,19 +315,43 @@ func (g *Geometry) Decode() (geom.T, error) {
 		}
 		return gc, nil
 	default:
-		return nil, ErrUnsupportedType(g.Type)
+		return nil, &DecodeError{
+			Pointer: "/type",
+			Value:   quote(g.Type),
+			Err:     ErrUnsupportedType(g.Type),
+		}
+	}
+}
+
+// Encode encodes g as a GeoJSON geometry. With WithBBox, the bounding box of
+// g is included.
+func Encode(g geom.T, opts ...Option) (*Geometry, error) {
+	o := newOptions(opts)
+	g, err := o.prepare(g)
+	if err != nil {
+		return nil, err
+	}
+	geometry, err := encode(g, o)
+	if err != nil || geometry == nil {
+		return geometry, err
+	}
+	if o.bbox {
+		if geometry.BBox, err = boundsBBox(g.Bounds()); err != nil {
+			return nil, err
+		}
+		o.roundFloats(geometry.BBox)
 	}
+	return geometry, nil
 }
 
-// Encode encodes g as a GeoJSON geometry.
-func Encode(g geom.T) (*Geometry, error) {
+func encode(g geom.T, o options) (*Geometry, error) {
 	if g == nil {
 		return nil, nil
 	}
 	switch g := g.(type) {
 	case *geom.Point:
 		var coords json.RawMessage
-		coords, err := json.Marshal(g.Coords())
+		coords, err := marshalCoords(o.roundCoords(g.Coords()))
 		if err != nil {
 			return nil, err
 		}
@@ -221,7 +361,7 @@ func Encode(g geom.T) (*Geometry, error) {
 		}, nil
 	case *geom.LineString:
 		var coords json.RawMessage
-		coords, err := json.Marshal(g.Coords())
+		coords, err := marshalCoords(o.roundCoords(g.Coords()))
 		if err != nil {
 			return nil, err
 		}
@@ -231,7 +371,7 @@ func Encode(g geom.T) (*Geometry, error) {
 		}, nil
 	case *geom.Polygon:
 		var coords json.RawMessage
-		coords, err := json.Marshal(g.Coords())
+		coords, err := marshalCoords(o.roundCoords(g.Coords()))
 		if err != nil {
 			return nil, err
 		}
@@ -241,7 +381,7 @@ func Encode(g geom.T) (*Geometry, error) {
 		}, nil
 	case *geom.MultiPoint:
 		var coords json.RawMessage
-		coords, err := json.Marshal(g.Coords())
+		coords, err := marshalCoords(o.roundCoords(g.Coords()))
 		if err != nil {
 			return nil, err
 		}
@@ -251,7 +391,7 @@ func Encode(g geom.T) (*Geometry, error) {
 		}, nil
 	case *geom.MultiLineString:
 		var coords json.RawMessage
-		coords, err := json.Marshal(g.Coords())
+		coords, err := marshalCoords(o.roundCoords(g.Coords()))
 		if err != nil {
 			return nil, err
 		}
@@ -261,7 +401,7 @@ func Encode(g geom.T) (*Geometry, error) {
 		}, nil
 	case *geom.MultiPolygon:
 		var coords json.RawMessage
-		coords, err := json.Marshal(g.Coords())
+		coords, err := marshalCoords(o.roundCoords(g.Coords()))
 		if err != nil {
 			return nil, err
 		}
@@ -273,7 +413,7 @@ func Encode(g geom.T) (*Geometry, error) {
 		geometries := make([]*Geometry, len(g.Geoms()))
 		for i, subGeometry := range g.Geoms() {
 			var err error
-			geometries[i], err = Encode(subGeometry)
+			geometries[i], err = encode(subGeometry, o)
 			if err != nil {
 				return nil, err
 			}
@@ -288,25 +428,44 @@ func Encode(g geom.T) (*Geometry, error) {
 }
 
 // Marshal marshals an arbitrary geometry to a []byte.
-func Marshal(g geom.T) ([]byte, error) {
+func Marshal(g geom.T, opts ...Option) ([]byte, error) {
 	if g == nil {
 		return nullGeometry, nil
 	}
-	geojson, err := Encode(g)
+	geojson, err := Encode(g, opts...)
 	if err != nil {
 		return nil, err
 	}
-	return json.Marshal(geojson)
+	return marshalGeometry(geojson)
 }
 
 // Unmarshal unmarshalls a []byte to an arbitrary geometry.
-func Unmarshal(data []byte, g *geom.T) error {
+func Unmarshal(data []byte, g *geom.T, opts ...Option) error {
+	return unmarshal(data, g, newOptions(opts))
+}
+
+// UnmarshalStub returns the Stub of the geometry in data, or nil if data is
+// the null geometry.
+func UnmarshalStub(data []byte, opts ...Option) (*geom.Stub, error) {
+	var g geom.T
+	if err := Unmarshal(data, &g, opts...); err != nil || g == nil {
+		return nil, err
+	}
+	return geom.NewStub(g)
+}
+
+func unmarshal(data []byte, g *geom.T, o options) error {
 	if bytes.Equal(data, nullGeometry) {
 		*g = nil
 		return nil
 	}
+	if o.strict {
+		if err := checkCRS(data); err != nil {
+			return err
+		}
+	}
 	gg := &Geometry{}
-	if err := json.Unmarshal(data, gg); err != nil {
+	if err := unmarshalGeometry(data, gg); err != nil {
 		return err
 	}
 	if gg == nil {
@@ -314,10 +473,58 @@ func Unmarshal(data []byte, g *geom.T) error {
 		return nil
 	}
 	var err error
-	*g, err = gg.Decode()
+	*g, err = gg.decodeWithOptions(o)
 	return err
 }
 
+// prepare returns g prepared for encoding: with its Z and M coordinates
+// handled according to WithZ and WithM, cut at the antimeridian with
+// WithAntimeridianCutting, oriented with WithOrientation, and checked with
+// WithStrict.
+func (o options) prepare(g geom.T) (geom.T, error) {
+	if g == nil {
+		return nil, nil
+	}
+	g, err := o.prepareDims(g)
+	if err != nil {
+		return nil, err
+	}
+	if o.cutAntimeridian {
+		if g, err = geo.CutAntimeridian(g); err != nil {
+			return nil, err
+		}
+	}
+	if o.orient {
+		g = Orient(g)
+	}
+	if o.strict {
+		if err := checkStrict(g); err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+// setSRID sets the SRID of g.
+func setSRID(g geom.T, srid int) {
+	switch g := g.(type) {
+	case *geom.Point:
+		g.SetSRID(srid)
+	case *geom.LineString:
+		g.SetSRID(srid)
+	case *geom.Polygon:
+		g.SetSRID(srid)
+	case *geom.MultiPoint:
+		g.SetSRID(srid)
+	case *geom.MultiLineString:
+		g.SetSRID(srid)
+	case *geom.MultiPolygon:
+		g.SetSRID(srid)
+	case *geom.GeometryCollection:
+		g.SetSRID(srid)
+	}
+}
+
 // decodeBBox decodes bb into a Bounds
 func decodeBBox(bb []float64) (*geom.Bounds, error) {
 	var layout geom.Layout
@@ -333,6 +540,39 @@ func decodeBBox(bb []float64) (*geom.Bounds, error) {
 	return geom.NewBounds(layout).Set(bb...), nil
 }
 
+// boundsBBox encodes b as a GeoJSON bounding box, or returns nil if b is
+// empty.
+func boundsBBox(b *geom.Bounds) ([]float64, error) {
+	if b == nil || b.IsEmpty() {
+		return nil, nil
+	}
+	return encodeBBox(b)
+}
+
+// extendBounds extends b, which may be nil, to include the bounds of f, which
+// are its bounding box if it has one and the bounds of its geometry
+// otherwise.
+func extendBounds(b *geom.Bounds, f *Feature) *geom.Bounds {
+	if f.BBox == nil && f.Geometry == nil {
+		return b
+	}
+	if b == nil {
+		b = geom.NewBounds(geom.NoLayout)
+	}
+	if f.BBox == nil {
+		return b.Extend(f.Geometry)
+	}
+	layout := f.BBox.Layout()
+	flatCoords := make([]float64, 0, 2*layout.Stride())
+	for i := 0; i < layout.Stride(); i++ {
+		flatCoords = append(flatCoords, f.BBox.Min(i))
+	}
+	for i := 0; i < layout.Stride(); i++ {
+		flatCoords = append(flatCoords, f.BBox.Max(i))
+	}
+	return b.Extend(geom.NewMultiPointFlat(layout, flatCoords))
+}
+
 // encodeBBox encodes b as a GeoJson Bounding Box
 func encodeBBox(b *geom.Bounds) ([]float64, error) {
 	switch l := b.Layout(); l {
@@ -344,83 +584,198 @@ func encodeBBox(b *geom.Bounds) ([]float64, error) {
 			b.Max(0), b.Max(1), b.Max(2),
 		}, nil
 	default:
-		return []float64{}, ErrUnsupportedType(l)
+		return []float64{}, geom.ErrUnsupportedLayout(l)
 	}
 }
 
 // MarshalJSON implements json.Marshaler.MarshalJSON.
 func (f *Feature) MarshalJSON() ([]byte, error) {
-	geometry, err := Encode(f.Geometry)
+	return f.marshalJSON(options{})
+}
+
+// MarshalFeature marshals f. Unlike json.Marshal, it accepts options, for
+// example WithBBox.
+func MarshalFeature(f *Feature, opts ...Option) ([]byte, error) {
+	return f.marshalJSON(newOptions(opts))
+}
+
+func (f *Feature) marshalJSON(o options) ([]byte, error) {
+	g, err := o.prepare(f.Geometry)
+	if err != nil {
+		return nil, err
+	}
+	geometry, err := encode(g, o)
 	if err != nil {
 		return nil, err
 	}
 
 	var bounds []float64
-	if f.BBox != nil {
+	switch {
+	case f.BBox != nil:
 		bounds, err = encodeBBox(f.BBox)
-		if err != nil {
-			return nil, err
-		}
+	case o.bbox && g != nil:
+		bounds, err = boundsBBox(g.Bounds())
+	}
+	if err != nil {
+		return nil, err
+	}
+	o.roundFloats(bounds)
+
+	var properties interface{} = f.Properties
+	if f.RawProperties != nil {
+		properties = f.RawProperties
 	}
 
-	return json.Marshal(&geojsonFeature{
+	data, err := json.Marshal(&geojsonFeature{
 		ID:         f.ID,
 		Type:       "Feature",
 		BBox:       bounds,
 		Geometry:   geometry,
-		Properties: f.Properties,
+		Properties: properties,
 	})
+	if err != nil {
+		return nil, err
+	}
+	return appendForeignMembers(data, f.ForeignMembers, featureMembers)
 }
 
 // UnmarshalJSON implements json.Unmarshaler.UnmarshalJSON.
 func (f *Feature) UnmarshalJSON(data []byte) error {
-	var gf geojsonFeature
-	if err := json.Unmarshal(data, &gf); err != nil {
-		return err
+	_, err := f.unmarshalJSON(data, nil, options{})
+	return err
+}
+
+// UnmarshalFeature unmarshals data to a Feature. Unlike json.Unmarshal, it
+// accepts options, for example WithRawProperties.
+func UnmarshalFeature(data []byte, opts ...Option) (*Feature, error) {
+	f := &Feature{}
+	if _, err := f.unmarshalJSON(data, nil, newOptions(opts)); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// unmarshalJSON unmarshals data into f if filter is nil or accepts it, and
+// reports whether it did so.
+func (f *Feature) unmarshalJSON(data []byte, filter feature.Filter, o options) (bool, error) {
+	gf, err := decodeFeatureHeader(data)
+	if err != nil {
+		return false, err
 	}
-	if gf.Type != "Feature" {
-		return ErrUnsupportedType(gf.Type)
+	var featureType string
+	if gf.Type != nil {
+		if err := json.Unmarshal(gf.Type, &featureType); err != nil {
+			return false, atPointer(err, "/type")
+		}
 	}
-	f.ID = gf.ID
-	var err error
+	if featureType != "Feature" {
+		return false, ErrUnsupportedType(featureType)
+	}
+	if o.strict && gf.CRS != nil {
+		return false, ErrCRS
+	}
+	var id string
+	if gf.ID != nil {
+		if err := json.Unmarshal(gf.ID, &id); err != nil {
+			return false, atPointer(err, "/id")
+		}
+	}
+	var properties map[string]interface{}
+	if !o.rawProperties || filter != nil {
+		if gf.Properties != nil {
+			if err := json.Unmarshal(gf.Properties, &properties); err != nil {
+				return false, atPointer(err, "/properties")
+			}
+		}
+	}
+	if filter != nil && !filter(id, properties) {
+		return false, nil
+	}
+	var bbox []float64
 	if gf.BBox != nil {
-		f.BBox, err = decodeBBox(gf.BBox)
+		if err := json.Unmarshal(gf.BBox, &bbox); err != nil {
+			return false, atPointer(err, "/bbox")
+		}
 	}
-	if err != nil {
-		return err
+	f.ID = id
+	f.ForeignMembers = gf.ForeignMembers
+	f.BBox = nil
+	if bbox != nil {
+		f.BBox, err = decodeBBox(bbox)
 	}
-	f.Geometry, err = gf.Geometry.Decode()
 	if err != nil {
-		return err
+		return false, atPointer(err, "/bbox")
+	}
+	f.Geometry = nil
+	if gf.Geometry != nil {
+		if err := unmarshal(gf.Geometry, &f.Geometry, o); err != nil {
+			return false, atPointer(err, "/geometry")
+		}
+	}
+	f.Properties, f.RawProperties = nil, nil
+	switch {
+	case !o.rawProperties:
+		f.Properties = properties
+	case !bytes.Equal(gf.Properties, nullGeometry):
+		f.RawProperties = gf.Properties
 	}
-	f.Properties = gf.Properties
-	return nil
+	return true, nil
 }
 
 // MarshalJSON implements json.Marshaler.MarshalJSON.
 func (fc *FeatureCollection) MarshalJSON() ([]byte, error) {
-	gfc := &geojsonFeatureCollection{
+	return fc.marshalJSON(options{})
+}
+
+// MarshalFeatureCollection marshals fc. Unlike json.Marshal, it accepts
+// options, for example WithBBox.
+func MarshalFeatureCollection(fc *FeatureCollection, opts ...Option) ([]byte, error) {
+	return fc.marshalJSON(newOptions(opts))
+}
+
+func (fc *FeatureCollection) marshalJSON(o options) ([]byte, error) {
+	gfc := &geojsonFeatureCollectionHeader{
 		Type:     "FeatureCollection",
-		Features: fc.Features,
+		Features: make([]json.RawMessage, 0, len(fc.Features)),
 	}
 
-	if fc.BBox != nil {
-		bounds, err := encodeBBox(fc.BBox)
+	var bounds *geom.Bounds
+	for _, f := range fc.Features {
+		if f == nil {
+			gfc.Features = append(gfc.Features, json.RawMessage(nullGeometry))
+			continue
+		}
+		data, err := f.marshalJSON(o)
 		if err != nil {
 			return nil, err
 		}
-		gfc.BBox = bounds
+		gfc.Features = append(gfc.Features, data)
+		if o.bbox && fc.BBox == nil {
+			bounds = extendBounds(bounds, f)
+		}
+	}
+
+	var err error
+	if fc.BBox != nil {
+		gfc.BBox, err = encodeBBox(fc.BBox)
+	} else if o.bbox {
+		gfc.BBox, err = boundsBBox(bounds)
+	}
+	if err != nil {
+		return nil, err
 	}
+	o.roundFloats(gfc.BBox)
 
-	if gfc.Features == nil {
-		gfc.Features = []*Feature{}
+	data, err := json.Marshal(gfc)
+	if err != nil {
+		return nil, err
 	}
-	return json.Marshal(gfc)
+	return appendForeignMembers(data, fc.ForeignMembers, featureCollectionMembers)
 }
 
 // UnmarshalJSON implements json.Unmarshaler.UnmarshalJSON
 func (fc *FeatureCollection) UnmarshalJSON(data []byte) error {
-	var gfc geojsonFeatureCollection
+	var gfc geojsonFeatureCollectionHeader
 	if err := json.Unmarshal(data, &gfc); err != nil {
 		return err
 	}
@@ -428,12 +783,57 @@ func (fc *FeatureCollection) UnmarshalJSON(data []byte) error {
 	if gfc.BBox != nil {
 		fc.BBox, err = decodeBBox(gfc.BBox)
 		if err != nil {
-			return err
+			return atPointer(err, "/bbox")
 		}
 	}
 	if gfc.Type != "FeatureCollection" {
 		return ErrUnsupportedType(gfc.Type)
 	}
-	fc.Features = gfc.Features
-	return nil
+	fc.Features = make([]*Feature, len(gfc.Features))
+	for i, data := range gfc.Features {
+		fc.Features[i] = &Feature{}
+		if _, err := fc.Features[i].unmarshalJSON(data, nil, options{}); err != nil {
+			return atPointer(err, "/features/"+strconv.Itoa(i))
+		}
+	}
+	fc.ForeignMembers, err = decodeForeignMembers(data, featureCollectionMembers)
+	return err
+}
+
+// UnmarshalFeatureCollection unmarshals data to a FeatureCollection. Unlike
+// json.Unmarshal, it accepts options, for example WithFeatureFilter.
+func UnmarshalFeatureCollection(data []byte, opts ...Option) (*FeatureCollection, error) {
+	o := newOptions(opts)
+	var gfc geojsonFeatureCollectionHeader
+	if err := json.Unmarshal(data, &gfc); err != nil {
+		return nil, err
+	}
+	if gfc.Type != "FeatureCollection" {
+		return nil, ErrUnsupportedType(gfc.Type)
+	}
+	if o.strict && gfc.CRS != nil {
+		return nil, ErrCRS
+	}
+	fc := &FeatureCollection{}
+	var err error
+	if gfc.BBox != nil {
+		fc.BBox, err = decodeBBox(gfc.BBox)
+		if err != nil {
+			return nil, atPointer(err, "/bbox")
+		}
+	}
+	if fc.ForeignMembers, err = decodeForeignMembers(data, featureCollectionMembers); err != nil {
+		return nil, err
+	}
+	for i, data := range gfc.Features {
+		f := &Feature{}
+		ok, err := f.unmarshalJSON(data, o.featureFilter, o)
+		if err != nil {
+			return nil, atPointer(err, "/features/"+strconv.Itoa(i))
+		}
+		if ok {
+			fc.Features = append(fc.Features, f)
+		}
+	}
+	return fc, nil
 }
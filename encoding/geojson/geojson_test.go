@@ -1,8 +1,12 @@
 package geojson
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"io"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/d4l3k/messagediff"
@@ -286,3 +290,285 @@ func TestFeatureCollection(t *testing.T) {
 		}
 	}
 }
+
+func TestUnmarshalFeatureCollection(t *testing.T) {
+	// The geometry of the second feature is invalid, but it is never decoded
+	// because the filter rejects the feature.
+	features := []string{
+		`{"type":"Feature","id":"a","geometry":{"type":"LineString","coordinates":[[0,0],[1,1]]},"properties":{"highway":"motorway"}}`,
+		`{"type":"Feature","id":"b","geometry":{"type":"Unknown"},"properties":{"highway":"residential"}}`,
+		`{"type":"Feature","id":"c","geometry":null,"properties":{"highway":"motorway"}}`,
+	}
+	s := `{"type":"FeatureCollection","features":[` + strings.Join(features, ",") + `]}`
+	filter := func(id string, properties map[string]interface{}) bool {
+		return properties["highway"] == "motorway"
+	}
+	want := &FeatureCollection{
+		Features: []*Feature{
+			{
+				ID:       "a",
+				Geometry: geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{{0, 0}, {1, 1}}),
+				Properties: map[string]interface{}{
+					"highway": "motorway",
+				},
+			},
+			{
+				ID: "c",
+				Properties: map[string]interface{}{
+					"highway": "motorway",
+				},
+			},
+		},
+	}
+	got, err := UnmarshalFeatureCollection([]byte(s), WithFeatureFilter(filter))
+	if err != nil {
+		t.Fatalf("UnmarshalFeatureCollection(%v, WithFeatureFilter(filter)) == _, %v, want _, <nil>", s, err)
+	}
+	if diff, equal := messagediff.PrettyDiff(want, got); !equal {
+		t.Errorf("UnmarshalFeatureCollection(%v, WithFeatureFilter(filter)), diff\n%s", s, diff)
+	}
+	if _, err := UnmarshalFeatureCollection([]byte(s)); err == nil {
+		t.Errorf("UnmarshalFeatureCollection(%v) == _, <nil>, want _, !<nil>", s)
+	}
+
+	// Decoders of feature collections and sequences apply the filter too.
+	var ids []string
+	d := NewFeatureCollectionDecoder(strings.NewReader(s), WithFeatureFilter(filter))
+	for {
+		f, err := d.Decode()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, f.ID)
+	}
+	if want := []string{"a", "c"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("FeatureCollectionDecoder decoded features %v, want %v", ids, want)
+	}
+	ids = nil
+	r := NewSeqReader(strings.NewReader(strings.Join(features, "\n")), WithFeatureFilter(filter))
+	for {
+		f, err := r.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, f.ID)
+	}
+	if want := []string{"a", "c"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("SeqReader read features %v, want %v", ids, want)
+	}
+}
+
+func TestUnmarshalSRIDFunc(t *testing.T) {
+	data := []byte(`{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[1,2]}]}`)
+	var got geom.T
+	if err := Unmarshal(data, &got, WithSRIDFunc(func(srid int) (int, error) {
+		return 4326, nil
+	})); err != nil {
+		t.Fatal(err)
+	}
+	want := geom.NewGeometryCollection().SetSRID(4326).MustPush(geom.NewPointFlat(geom.XY, []float64{1, 2}))
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal(%s, ...) == %v, want %v", data, got, want)
+	}
+	errRejected := errors.New("rejected")
+	if err := Unmarshal(data, &got, WithSRIDFunc(func(srid int) (int, error) {
+		return 0, errRejected
+	})); !errors.Is(err, errRejected) {
+		t.Errorf("Unmarshal(%s, ...) == %v, want %v", data, err, errRejected)
+	}
+}
+
+func TestUnmarshalFeature(t *testing.T) {
+	s := `{"type":"Feature","id":"a","geometry":{"type":"Point","coordinates":[1,2]},"properties":{"name":"a","tags":["x","y"]}}`
+	for _, tc := range []struct {
+		name string
+		s    string
+		opts []Option
+		want *Feature
+	}{
+		{
+			name: "properties",
+			s:    s,
+			opts: []Option{WithSRIDFunc(func(int) (int, error) { return 4326, nil })},
+			want: &Feature{
+				ID:       "a",
+				Geometry: geom.NewPointFlat(geom.XY, []float64{1, 2}).SetSRID(4326),
+				Properties: map[string]interface{}{
+					"name": "a",
+					"tags": []interface{}{"x", "y"},
+				},
+			},
+		},
+		{
+			name: "raw_properties",
+			s:    s,
+			opts: []Option{WithRawProperties(true)},
+			want: &Feature{
+				ID:            "a",
+				Geometry:      geom.NewPointFlat(geom.XY, []float64{1, 2}),
+				RawProperties: json.RawMessage(`{"name":"a","tags":["x","y"]}`),
+			},
+		},
+		{
+			name: "raw_null_properties",
+			s:    `{"type":"Feature","geometry":null,"properties":null}`,
+			opts: []Option{WithRawProperties(true)},
+			want: &Feature{},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := UnmarshalFeature([]byte(tc.s), tc.opts...)
+			if err != nil || !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("UnmarshalFeature(%s, ...) == %+v, %v, want %+v, <nil>", tc.s, got, err, tc.want)
+			}
+			if data, err := json.Marshal(got); err != nil || string(data) != tc.s {
+				t.Errorf("json.Marshal(%+v) == %s, %v, want %s, <nil>", got, data, err, tc.s)
+			}
+		})
+	}
+	if _, err := UnmarshalFeature([]byte(`{"type":"Point","coordinates":[1,2]}`)); !errors.Is(err, ErrUnsupportedType("Point")) {
+		t.Errorf("UnmarshalFeature(...) == _, %v, want _, %v", err, ErrUnsupportedType("Point"))
+	}
+}
+
+func TestUnmarshalFeatureCollectionRawProperties(t *testing.T) {
+	s := `{"type":"FeatureCollection","features":[` +
+		`{"type":"Feature","id":"a","geometry":null,"properties":{"highway":"motorway"}},` +
+		`{"type":"Feature","id":"b","geometry":null,"properties":{"highway":"residential"}}]}`
+	filter := func(id string, properties map[string]interface{}) bool {
+		return properties["highway"] == "motorway"
+	}
+	got, err := UnmarshalFeatureCollection([]byte(s), WithFeatureFilter(filter), WithRawProperties(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &FeatureCollection{
+		Features: []*Feature{
+			{ID: "a", RawProperties: json.RawMessage(`{"highway":"motorway"}`)},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("UnmarshalFeatureCollection(%s, WithFeatureFilter(filter), WithRawProperties(true)) == %+v, want %+v", s, got, want)
+	}
+}
+
+func TestBBox(t *testing.T) {
+	point := geom.NewPointFlat(geom.XY, []float64{1, 2})
+	lineString := geom.NewLineStringFlat(geom.XYZ, []float64{1, 2, 3, 4, 5, 6})
+	for _, tc := range []struct {
+		name    string
+		marshal func(...Option) ([]byte, error)
+		want    string
+		wantRaw string
+	}{
+		{
+			name:    "point",
+			marshal: func(opts ...Option) ([]byte, error) { return Marshal(point, opts...) },
+			want:    `{"type":"Point","bbox":[1,2,1,2],"coordinates":[1,2]}`,
+			wantRaw: `{"type":"Point","coordinates":[1,2]}`,
+		},
+		{
+			name:    "empty",
+			marshal: func(opts ...Option) ([]byte, error) { return Marshal(geom.NewLineString(geom.XY), opts...) },
+			want:    `{"type":"LineString","coordinates":[]}`,
+			wantRaw: `{"type":"LineString","coordinates":[]}`,
+		},
+		{
+			name: "geometrycollection",
+			marshal: func(opts ...Option) ([]byte, error) {
+				return Marshal(geom.NewGeometryCollection().MustPush(point, geom.NewPointFlat(geom.XY, []float64{3, 4})), opts...)
+			},
+			want:    `{"type":"GeometryCollection","bbox":[1,2,3,4],"geometries":[{"type":"Point","coordinates":[1,2]},{"type":"Point","coordinates":[3,4]}]}`,
+			wantRaw: `{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[1,2]},{"type":"Point","coordinates":[3,4]}]}`,
+		},
+		{
+			name: "feature",
+			marshal: func(opts ...Option) ([]byte, error) {
+				return MarshalFeature(&Feature{Geometry: lineString}, opts...)
+			},
+			want:    `{"type":"Feature","bbox":[1,2,3,4,5,6],"geometry":{"type":"LineString","coordinates":[[1,2,3],[4,5,6]]},"properties":null}`,
+			wantRaw: `{"type":"Feature","geometry":{"type":"LineString","coordinates":[[1,2,3],[4,5,6]]},"properties":null}`,
+		},
+		{
+			name: "feature_collection",
+			marshal: func(opts ...Option) ([]byte, error) {
+				return MarshalFeatureCollection(&FeatureCollection{
+					Features: []*Feature{
+						{Geometry: point},
+						{BBox: geom.NewBounds(geom.XY).Set(-1, -1, 0, 0)},
+						{},
+					},
+				}, opts...)
+			},
+			want:    `{"type":"FeatureCollection","bbox":[-1,-1,1,2],"features":[{"type":"Feature","bbox":[1,2,1,2],"geometry":{"type":"Point","coordinates":[1,2]},"properties":null},{"type":"Feature","bbox":[-1,-1,0,0],"geometry":null,"properties":null},{"type":"Feature","geometry":null,"properties":null}]}`,
+			wantRaw: `{"type":"FeatureCollection","features":[{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"properties":null},{"type":"Feature","bbox":[-1,-1,0,0],"geometry":null,"properties":null},{"type":"Feature","geometry":null,"properties":null}]}`,
+		},
+		{
+			name: "empty_feature_collection",
+			marshal: func(opts ...Option) ([]byte, error) {
+				return MarshalFeatureCollection(&FeatureCollection{}, opts...)
+			},
+			want:    `{"type":"FeatureCollection","features":[]}`,
+			wantRaw: `{"type":"FeatureCollection","features":[]}`,
+		},
+		{
+			name: "stream",
+			marshal: func(opts ...Option) ([]byte, error) {
+				var b bytes.Buffer
+				e := NewFeatureCollectionEncoder(&b, opts...)
+				for _, f := range []*Feature{{Geometry: point}, {Geometry: lineString}} {
+					if err := e.Encode(f); err != nil {
+						return nil, err
+					}
+				}
+				err := e.Close()
+				return b.Bytes(), err
+			},
+			want:    `{"type":"FeatureCollection","features":[{"type":"Feature","bbox":[1,2,1,2],"geometry":{"type":"Point","coordinates":[1,2]},"properties":null},{"type":"Feature","bbox":[1,2,3,4,5,6],"geometry":{"type":"LineString","coordinates":[[1,2,3],[4,5,6]]},"properties":null}],"bbox":[1,2,3,4,5,6]}`,
+			wantRaw: `{"type":"FeatureCollection","features":[{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"properties":null},{"type":"Feature","geometry":{"type":"LineString","coordinates":[[1,2,3],[4,5,6]]},"properties":null}]}`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got, err := tc.marshal(WithBBox(true)); err != nil || string(got) != tc.want {
+				t.Errorf("got %s, %v, want %s, <nil>", got, err, tc.want)
+			}
+			if got, err := tc.marshal(); err != nil || string(got) != tc.wantRaw {
+				t.Errorf("got %s, %v, want %s, <nil>", got, err, tc.wantRaw)
+			}
+		})
+	}
+}
+
+func TestGeometryBBox(t *testing.T) {
+	s := `{"type":"Point","bbox":[1,2,1,2],"coordinates":[1,2]}`
+	var g Geometry
+	if err := unmarshalGeometry([]byte(s), &g); err != nil {
+		t.Fatal(err)
+	}
+	if want := []float64{1, 2, 1, 2}; !reflect.DeepEqual(g.BBox, want) {
+		t.Errorf("BBox == %v, want %v", g.BBox, want)
+	}
+	if got, err := marshalGeometry(&g); err != nil || string(got) != s {
+		t.Errorf("marshalGeometry(...) == %s, %v, want %s, <nil>", got, err, s)
+	}
+}
+
+func TestAntimeridianCutting(t *testing.T) {
+	ls := geom.NewLineStringFlat(geom.XY, []float64{170, 0, -170, 10})
+	if _, err := Marshal(ls, WithStrict(true)); err != nil {
+		t.Errorf("Marshal(ls, WithStrict(true)) == _, %v, want _, <nil>", err)
+	}
+	want := `{"type":"MultiLineString","bbox":[-180,0,180,10],"coordinates":[[[170,0],[180,5]],[[-180,5],[-170,10]]]}`
+	if got, err := Marshal(ls, WithAntimeridianCutting(true), WithBBox(true)); err != nil || string(got) != want {
+		t.Errorf("Marshal(ls, WithAntimeridianCutting(true), WithBBox(true)) == %s, %v, want %s, <nil>", got, err, want)
+	}
+	polygon := geom.NewPolygonFlat(geom.XY, []float64{170, 0, 170, 10, -170, 10, -170, 0, 170, 0}, []int{10})
+	want = `{"type":"Feature","geometry":{"type":"MultiPolygon","coordinates":[[[[180,10],[170,10],[170,0],[180,0],[180,10]]],[[[-180,0],[-170,0],[-170,10],[-180,10],[-180,0]]]]},"properties":null}`
+	if got, err := MarshalFeature(&Feature{Geometry: polygon}, WithAntimeridianCutting(true), WithStrict(true)); err != nil || string(got) != want {
+		t.Errorf("MarshalFeature(...) == %s, %v, want %s, <nil>", got, err, want)
+	}
+}
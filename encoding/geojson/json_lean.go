@@ -0,0 +1,20 @@
+//go:build tinygo || geojsonlean
+// +build tinygo geojsonlean
+
+package geojson
+
+func marshalCoords(coords interface{}) ([]byte, error) {
+	return leanMarshalCoords(coords)
+}
+
+func unmarshalCoords(data []byte, coords interface{}) error {
+	return leanUnmarshalCoords(data, coords)
+}
+
+func marshalGeometry(g *Geometry) ([]byte, error) {
+	return leanMarshalGeometry(g)
+}
+
+func unmarshalGeometry(data []byte, g *Geometry) error {
+	return leanUnmarshalGeometry(data, g)
+}
@@ -0,0 +1,22 @@
+//go:build !tinygo && !geojsonlean
+// +build !tinygo,!geojsonlean
+
+package geojson
+
+import "encoding/json"
+
+func marshalCoords(coords interface{}) ([]byte, error) {
+	return json.Marshal(coords)
+}
+
+func unmarshalCoords(data []byte, coords interface{}) error {
+	return json.Unmarshal(data, coords)
+}
+
+func marshalGeometry(g *Geometry) ([]byte, error) {
+	return json.Marshal(g)
+}
+
+func unmarshalGeometry(data []byte, g *Geometry) error {
+	return json.Unmarshal(data, g)
+}
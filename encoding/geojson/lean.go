@@ -0,0 +1,694 @@
+package geojson
+
+// This file contains a reflection-free implementation of the JSON encoding
+// and decoding of geometries. It is used instead of encoding/json when
+// building with the tinygo or geojsonlean build tags, for environments such
+// as TinyGo and WebAssembly where reflection is limited and binary size
+// matters. It is always compiled so that it can be tested against
+// encoding/json. Features and FeatureCollections, whose properties are
+// arbitrary, always use encoding/json.
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	geom "github.com/twpayne/go-geom"
+)
+
+// maxLeanDepth is the maximum nesting depth accepted by the lean decoder.
+const maxLeanDepth = 10000
+
+// An ErrSyntax is returned by the reflection-free decoder when its input is
+// not valid JSON or does not have the expected structure.
+type ErrSyntax struct {
+	Offset int
+	Msg    string
+}
+
+func (e ErrSyntax) Error() string {
+	return "geojson: syntax error at offset " + strconv.Itoa(e.Offset) + ": " + e.Msg
+}
+
+// An ErrUnsupportedValue is returned when encoding a value that cannot be
+// represented in JSON, such as NaN or an infinity.
+type ErrUnsupportedValue float64
+
+func (e ErrUnsupportedValue) Error() string {
+	return "geojson: unsupported value: " + strconv.FormatFloat(float64(e), 'g', -1, 64)
+}
+
+func leanMarshalCoords(coords interface{}) ([]byte, error) {
+	return appendCoords(nil, coords)
+}
+
+func leanMarshalGeometry(g *Geometry) ([]byte, error) {
+	return appendGeometry(nil, g)
+}
+
+func leanUnmarshalCoords(data []byte, coords interface{}) error {
+	d := &leanDecoder{data: data}
+	var err error
+	switch coords := coords.(type) {
+	case *geom.Coord:
+		*coords, err = d.coords0()
+	case *[]geom.Coord:
+		*coords, err = d.coords1()
+	case *[][]geom.Coord:
+		*coords, err = d.coords2()
+	case *[][][]geom.Coord:
+		*coords, err = d.coords3()
+	default:
+		return geom.ErrUnsupportedType{Value: coords}
+	}
+	if err != nil {
+		return err
+	}
+	return d.end()
+}
+
+func leanUnmarshalGeometry(data []byte, g *Geometry) error {
+	d := &leanDecoder{data: data}
+	if err := d.geometry(g); err != nil {
+		return err
+	}
+	return d.end()
+}
+
+// appendCoords appends the JSON encoding of coords, which must be a
+// geom.Coord, []geom.Coord, [][]geom.Coord, or [][][]geom.Coord.
+func appendCoords(dst []byte, coords interface{}) ([]byte, error) {
+	var err error
+	switch coords := coords.(type) {
+	case geom.Coord:
+		return appendCoords0(dst, coords)
+	case []geom.Coord:
+		return appendCoords1(dst, coords)
+	case [][]geom.Coord:
+		if coords == nil {
+			return append(dst, "null"...), nil
+		}
+		dst = append(dst, '[')
+		for i, coords1 := range coords {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			if dst, err = appendCoords1(dst, coords1); err != nil {
+				return nil, err
+			}
+		}
+		return append(dst, ']'), nil
+	case [][][]geom.Coord:
+		if coords == nil {
+			return append(dst, "null"...), nil
+		}
+		dst = append(dst, '[')
+		for i, coords2 := range coords {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			if dst, err = appendCoords(dst, coords2); err != nil {
+				return nil, err
+			}
+		}
+		return append(dst, ']'), nil
+	default:
+		return nil, geom.ErrUnsupportedType{Value: coords}
+	}
+}
+
+func appendCoords0(dst []byte, coord geom.Coord) ([]byte, error) {
+	if coord == nil {
+		return append(dst, "null"...), nil
+	}
+	dst = append(dst, '[')
+	for i, f := range coord {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		var err error
+		if dst, err = appendFloat(dst, f); err != nil {
+			return nil, err
+		}
+	}
+	return append(dst, ']'), nil
+}
+
+func appendCoords1(dst []byte, coords []geom.Coord) ([]byte, error) {
+	if coords == nil {
+		return append(dst, "null"...), nil
+	}
+	dst = append(dst, '[')
+	for i, coord := range coords {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		var err error
+		if dst, err = appendCoords0(dst, coord); err != nil {
+			return nil, err
+		}
+	}
+	return append(dst, ']'), nil
+}
+
+// appendFloat appends f formatted as encoding/json formats float64s.
+func appendFloat(dst []byte, f float64) ([]byte, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, ErrUnsupportedValue(f)
+	}
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+	dst = strconv.AppendFloat(dst, f, format, -1, 64)
+	if format == 'e' {
+		// Clean up e-09 to e-9.
+		if n := len(dst); n >= 4 && dst[n-4] == 'e' && dst[n-3] == '-' && dst[n-2] == '0' {
+			dst[n-2] = dst[n-1]
+			dst = dst[:n-1]
+		}
+	}
+	return dst, nil
+}
+
+// appendGeometry appends the JSON encoding of g.
+func appendGeometry(dst []byte, g *Geometry) ([]byte, error) {
+	if g == nil {
+		return append(dst, "null"...), nil
+	}
+	var err error
+	dst = append(dst, `{"type":`...)
+	dst = appendString(dst, g.Type)
+	if len(g.BBox) > 0 {
+		dst = append(dst, `,"bbox":`...)
+		if dst, err = appendCoords0(dst, g.BBox); err != nil {
+			return nil, err
+		}
+	}
+	if g.Coordinates != nil {
+		dst = append(dst, `,"coordinates":`...)
+		dst = append(dst, *g.Coordinates...)
+	}
+	if len(g.Geometries) > 0 {
+		dst = append(dst, `,"geometries":[`...)
+		for i, member := range g.Geometries {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			if dst, err = appendGeometry(dst, member); err != nil {
+				return nil, err
+			}
+		}
+		dst = append(dst, ']')
+	}
+	return append(dst, '}'), nil
+}
+
+// appendString appends s as a JSON string, escaping HTML characters as
+// encoding/json does.
+func appendString(dst []byte, s string) []byte {
+	const hex = "0123456789abcdef"
+	dst = append(dst, '"')
+	for i := 0; i < len(s); {
+		if b := s[i]; b < utf8.RuneSelf {
+			switch {
+			case b == '"' || b == '\\':
+				dst = append(dst, '\\', b)
+			case b == '\n':
+				dst = append(dst, '\\', 'n')
+			case b == '\r':
+				dst = append(dst, '\\', 'r')
+			case b == '\t':
+				dst = append(dst, '\\', 't')
+			case b < 0x20 || b == '<' || b == '>' || b == '&':
+				dst = append(dst, '\\', 'u', '0', '0', hex[b>>4], hex[b&0xf])
+			default:
+				dst = append(dst, b)
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			dst = append(dst, string(utf8.RuneError)...)
+		case r == '\u2028' || r == '\u2029':
+			dst = append(dst, '\\', 'u', '2', '0', '2', hex[r&0xf])
+		default:
+			dst = append(dst, s[i:i+size]...)
+		}
+		i += size
+	}
+	return append(dst, '"')
+}
+
+// A leanDecoder decodes JSON without reflection.
+type leanDecoder struct {
+	data  []byte
+	pos   int
+	depth int
+}
+
+func (d *leanDecoder) errorf(msg string) error {
+	return ErrSyntax{Offset: d.pos, Msg: msg}
+}
+
+func (d *leanDecoder) skipSpace() {
+	for d.pos < len(d.data) {
+		switch d.data[d.pos] {
+		case ' ', '\t', '\n', '\r':
+			d.pos++
+		default:
+			return
+		}
+	}
+}
+
+// consume consumes b, after any whitespace, and returns true if it is next.
+func (d *leanDecoder) consume(b byte) bool {
+	d.skipSpace()
+	if d.pos < len(d.data) && d.data[d.pos] == b {
+		d.pos++
+		return true
+	}
+	return false
+}
+
+// literal consumes lit, after any whitespace, and returns true if it is
+// next.
+func (d *leanDecoder) literal(lit string) bool {
+	d.skipSpace()
+	if strings.HasPrefix(string(d.data[d.pos:]), lit) {
+		d.pos += len(lit)
+		return true
+	}
+	return false
+}
+
+func (d *leanDecoder) end() error {
+	d.skipSpace()
+	if d.pos != len(d.data) {
+		return d.errorf("unexpected data after value")
+	}
+	return nil
+}
+
+func (d *leanDecoder) enter() error {
+	d.depth++
+	if d.depth > maxLeanDepth {
+		return d.errorf("exceeded max depth")
+	}
+	return nil
+}
+
+func (d *leanDecoder) leave() {
+	d.depth--
+}
+
+func (d *leanDecoder) number() (float64, error) {
+	d.skipSpace()
+	start := d.pos
+	digits := func() int {
+		n := 0
+		for d.pos < len(d.data) && '0' <= d.data[d.pos] && d.data[d.pos] <= '9' {
+			d.pos++
+			n++
+		}
+		return n
+	}
+	if d.pos < len(d.data) && d.data[d.pos] == '-' {
+		d.pos++
+	}
+	switch n := digits(); {
+	case n == 0:
+		return 0, d.errorf("expected number")
+	case n > 1 && d.data[d.pos-n] == '0':
+		return 0, d.errorf("invalid number")
+	}
+	if d.pos < len(d.data) && d.data[d.pos] == '.' {
+		d.pos++
+		if digits() == 0 {
+			return 0, d.errorf("invalid number")
+		}
+	}
+	if d.pos < len(d.data) && (d.data[d.pos] == 'e' || d.data[d.pos] == 'E') {
+		d.pos++
+		if d.pos < len(d.data) && (d.data[d.pos] == '+' || d.data[d.pos] == '-') {
+			d.pos++
+		}
+		if digits() == 0 {
+			return 0, d.errorf("invalid number")
+		}
+	}
+	f, err := strconv.ParseFloat(string(d.data[start:d.pos]), 64)
+	if err != nil {
+		return 0, ErrSyntax{Offset: start, Msg: "number out of range"}
+	}
+	return f, nil
+}
+
+func (d *leanDecoder) string() (string, error) {
+	if !d.consume('"') {
+		return "", d.errorf("expected string")
+	}
+	start := d.pos
+	for d.pos < len(d.data) {
+		switch b := d.data[d.pos]; {
+		case b == '"':
+			s := string(d.data[start:d.pos])
+			d.pos++
+			return s, nil
+		case b == '\\':
+			return d.escapedString(start)
+		case b < 0x20:
+			return "", d.errorf("invalid character in string")
+		default:
+			d.pos++
+		}
+	}
+	return "", d.errorf("unterminated string")
+}
+
+// escapedString decodes the remainder of a string containing escape
+// sequences that started at start.
+func (d *leanDecoder) escapedString(start int) (string, error) {
+	buf := append([]byte(nil), d.data[start:d.pos]...)
+	for d.pos < len(d.data) {
+		b := d.data[d.pos]
+		switch {
+		case b == '"':
+			d.pos++
+			return string(buf), nil
+		case b < 0x20:
+			return "", d.errorf("invalid character in string")
+		case b != '\\':
+			buf = append(buf, b)
+			d.pos++
+			continue
+		}
+		d.pos++
+		if d.pos >= len(d.data) {
+			break
+		}
+		switch e := d.data[d.pos]; e {
+		case '"', '\\', '/':
+			buf = append(buf, e)
+		case 'b':
+			buf = append(buf, '\b')
+		case 'f':
+			buf = append(buf, '\f')
+		case 'n':
+			buf = append(buf, '\n')
+		case 'r':
+			buf = append(buf, '\r')
+		case 't':
+			buf = append(buf, '\t')
+		case 'u':
+			r, ok := d.hex4(d.pos + 1)
+			if !ok {
+				return "", d.errorf("invalid escape")
+			}
+			d.pos += 4
+			if utf16.IsSurrogate(r) {
+				if r2, ok := d.hex4(d.pos + 3); ok && d.data[d.pos+1] == '\\' && d.data[d.pos+2] == 'u' {
+					if decoded := utf16.DecodeRune(r, r2); decoded != utf8.RuneError {
+						r = decoded
+						d.pos += 6
+					} else {
+						r = utf8.RuneError
+					}
+				} else {
+					r = utf8.RuneError
+				}
+			}
+			var rbuf [utf8.UTFMax]byte
+			buf = append(buf, rbuf[:utf8.EncodeRune(rbuf[:], r)]...)
+		default:
+			return "", d.errorf("invalid escape")
+		}
+		d.pos++
+	}
+	return "", d.errorf("unterminated string")
+}
+
+// hex4 decodes the four hexadecimal digits at i.
+func (d *leanDecoder) hex4(i int) (rune, bool) {
+	if i+4 > len(d.data) {
+		return 0, false
+	}
+	var r rune
+	for _, b := range d.data[i : i+4] {
+		switch {
+		case '0' <= b && b <= '9':
+			r = r<<4 | rune(b-'0')
+		case 'a' <= b && b <= 'f':
+			r = r<<4 | rune(b-'a'+10)
+		case 'A' <= b && b <= 'F':
+			r = r<<4 | rune(b-'A'+10)
+		default:
+			return 0, false
+		}
+	}
+	return r, true
+}
+
+// skipValue skips over the next value.
+func (d *leanDecoder) skipValue() error {
+	d.skipSpace()
+	if d.pos >= len(d.data) {
+		return d.errorf("unexpected end of input")
+	}
+	switch d.data[d.pos] {
+	case '"':
+		_, err := d.string()
+		return err
+	case '{':
+		if err := d.enter(); err != nil {
+			return err
+		}
+		defer d.leave()
+		d.pos++
+		if d.consume('}') {
+			return nil
+		}
+		for {
+			if _, err := d.string(); err != nil {
+				return err
+			}
+			if !d.consume(':') {
+				return d.errorf("expected colon")
+			}
+			if err := d.skipValue(); err != nil {
+				return err
+			}
+			if d.consume(',') {
+				continue
+			}
+			if d.consume('}') {
+				return nil
+			}
+			return d.errorf("expected comma or closing brace")
+		}
+	case '[':
+		if err := d.enter(); err != nil {
+			return err
+		}
+		defer d.leave()
+		d.pos++
+		if d.consume(']') {
+			return nil
+		}
+		for {
+			if err := d.skipValue(); err != nil {
+				return err
+			}
+			if d.consume(',') {
+				continue
+			}
+			if d.consume(']') {
+				return nil
+			}
+			return d.errorf("expected comma or closing bracket")
+		}
+	case 't':
+		if d.literal("true") {
+			return nil
+		}
+	case 'f':
+		if d.literal("false") {
+			return nil
+		}
+	case 'n':
+		if d.literal("null") {
+			return nil
+		}
+	default:
+		_, err := d.number()
+		return err
+	}
+	return d.errorf("invalid literal")
+}
+
+// array calls f for each element of an array.
+func (d *leanDecoder) array(f func() error) error {
+	if !d.consume('[') {
+		return d.errorf("expected array")
+	}
+	if err := d.enter(); err != nil {
+		return err
+	}
+	defer d.leave()
+	if d.consume(']') {
+		return nil
+	}
+	for {
+		if err := f(); err != nil {
+			return err
+		}
+		if d.consume(',') {
+			continue
+		}
+		if d.consume(']') {
+			return nil
+		}
+		return d.errorf("expected comma or closing bracket")
+	}
+}
+
+func (d *leanDecoder) coords0() (geom.Coord, error) {
+	if d.literal("null") {
+		return nil, nil
+	}
+	coord := geom.Coord{}
+	err := d.array(func() error {
+		// As with encoding/json, null elements decode as zero.
+		if d.literal("null") {
+			coord = append(coord, 0)
+			return nil
+		}
+		f, err := d.number()
+		coord = append(coord, f)
+		return err
+	})
+	return coord, err
+}
+
+func (d *leanDecoder) coords1() ([]geom.Coord, error) {
+	if d.literal("null") {
+		return nil, nil
+	}
+	coords := []geom.Coord{}
+	err := d.array(func() error {
+		coord, err := d.coords0()
+		coords = append(coords, coord)
+		return err
+	})
+	return coords, err
+}
+
+func (d *leanDecoder) coords2() ([][]geom.Coord, error) {
+	if d.literal("null") {
+		return nil, nil
+	}
+	coords := [][]geom.Coord{}
+	err := d.array(func() error {
+		coords1, err := d.coords1()
+		coords = append(coords, coords1)
+		return err
+	})
+	return coords, err
+}
+
+func (d *leanDecoder) coords3() ([][][]geom.Coord, error) {
+	if d.literal("null") {
+		return nil, nil
+	}
+	coords := [][][]geom.Coord{}
+	err := d.array(func() error {
+		coords2, err := d.coords2()
+		coords = append(coords, coords2)
+		return err
+	})
+	return coords, err
+}
+
+// geometry decodes a geometry object into g. As with encoding/json, null
+// leaves g unchanged and object keys are matched case-insensitively.
+func (d *leanDecoder) geometry(g *Geometry) error {
+	if d.literal("null") {
+		return nil
+	}
+	if !d.consume('{') {
+		return d.errorf("expected object")
+	}
+	if err := d.enter(); err != nil {
+		return err
+	}
+	defer d.leave()
+	if d.consume('}') {
+		return nil
+	}
+	for {
+		key, err := d.string()
+		if err != nil {
+			return err
+		}
+		if !d.consume(':') {
+			return d.errorf("expected colon")
+		}
+		switch {
+		case strings.EqualFold(key, "type"):
+			if !d.literal("null") {
+				if g.Type, err = d.string(); err != nil {
+					return err
+				}
+			}
+		case strings.EqualFold(key, "bbox"):
+			if g.BBox, err = d.coords0(); err != nil {
+				return err
+			}
+		case strings.EqualFold(key, "coordinates"):
+			if d.literal("null") {
+				g.Coordinates = nil
+				break
+			}
+			start := d.pos
+			if err := d.skipValue(); err != nil {
+				return err
+			}
+			coordinates := json.RawMessage(append([]byte(nil), d.data[start:d.pos]...))
+			g.Coordinates = &coordinates
+		case strings.EqualFold(key, "geometries"):
+			if d.literal("null") {
+				g.Geometries = nil
+				break
+			}
+			g.Geometries = []*Geometry{}
+			if err := d.array(func() error {
+				if d.literal("null") {
+					g.Geometries = append(g.Geometries, nil)
+					return nil
+				}
+				member := &Geometry{}
+				g.Geometries = append(g.Geometries, member)
+				return d.geometry(member)
+			}); err != nil {
+				return err
+			}
+		default:
+			if err := d.skipValue(); err != nil {
+				return err
+			}
+		}
+		if d.consume(',') {
+			continue
+		}
+		if d.consume('}') {
+			return nil
+		}
+		return d.errorf("expected comma or closing brace")
+	}
+}
@@ -0,0 +1,179 @@
+package geojson
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+)
+
+func TestLeanMarshalMatchesJSON(t *testing.T) {
+	for _, g := range []geom.T{
+		geom.NewPoint(geom.NoLayout),
+		geom.NewPoint(geom.XY),
+		geom.NewPointFlat(geom.XY, []float64{1, 2}),
+		geom.NewPointFlat(geom.XYZ, []float64{-1.5, 1e-7, 1e21}),
+		geom.NewPointFlat(geom.XYZM, []float64{0.1, 123456789, -0.000001, 5e-324}),
+		geom.NewLineString(geom.XY),
+		geom.NewLineStringFlat(geom.XY, []float64{1, 2, 3, 4}),
+		geom.NewPolygon(geom.XY),
+		geom.NewPolygonFlat(geom.XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}, []int{8}),
+		geom.NewMultiPoint(geom.XY),
+		geom.NewMultiPointFlat(geom.XYZ, []float64{1, 2, 3, 4, 5, 6}),
+		geom.NewMultiLineStringFlat(geom.XY, []float64{1, 2, 3, 4, 5, 6, 7, 8}, []int{4, 8}),
+		geom.NewMultiPolygonFlat(geom.XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}, [][]int{{8}}),
+		geom.NewGeometryCollection(),
+		geom.NewGeometryCollection().MustPush(
+			geom.NewPointFlat(geom.XY, []float64{1, 2}),
+			geom.NewGeometryCollection().MustPush(
+				geom.NewLineStringFlat(geom.XY, []float64{1, 2, 3, 4}),
+			),
+		),
+	} {
+		geometry, err := Encode(g)
+		if err != nil {
+			t.Errorf("Encode(%#v) == _, %v, want _, nil", g, err)
+			continue
+		}
+		want, err := json.Marshal(geometry)
+		if err != nil {
+			t.Errorf("json.Marshal(%#v) == _, %v, want _, nil", geometry, err)
+			continue
+		}
+		if got, err := leanMarshalGeometry(geometry); err != nil || string(got) != string(want) {
+			t.Errorf("leanMarshalGeometry(%#v) == %s, %v, want %s, nil", geometry, got, err, want)
+		}
+	}
+}
+
+func TestLeanMarshalCoords(t *testing.T) {
+	for _, f := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		if _, err := leanMarshalCoords(geom.Coord{f, 0}); err == nil {
+			t.Errorf("leanMarshalCoords(geom.Coord{%v, 0}) == _, nil, want _, non-nil", f)
+		}
+	}
+}
+
+func TestLeanMarshalString(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"Point",
+		"<&>",
+		"\"\\\n\r\t\x01",
+		"\u2028\u2029",
+		"é€😀",
+	} {
+		want, err := json.Marshal(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := appendString(nil, s); string(got) != string(want) {
+			t.Errorf("appendString(nil, %q) == %s, want %s", s, got, want)
+		}
+	}
+}
+
+func TestLeanUnmarshalMatchesJSON(t *testing.T) {
+	for _, s := range []string{
+		`null`,
+		`{}`,
+		`{"type":"Point","coordinates":[1,2]}`,
+		`{"type":"Point","coordinates":[]}`,
+		`{"type":"Point","coordinates":null}`,
+		`{"TYPE":"Point","Coordinates":[1,2]}`,
+		`{"type":null,"coordinates":[1,2]}`,
+		` { "type" : "Point" , "coordinates" : [ 1 , -2.5e3 , 0 ] } `,
+		`{"type":"LineString","coordinates":[]}`,
+		`{"type":"LineString","coordinates":[[1,2],[3,4]]}`,
+		`{"type":"LineString","coordinates":[null]}`,
+		`{"type":"MultiPoint","coordinates":[[1,2],[3,4,5]]}`,
+		`{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,0]]]}`,
+		`{"type":"MultiLineString","coordinates":[[[1,2],[3,4]],[[5,6],[7,8]]]}`,
+		`{"type":"MultiPolygon","coordinates":[[[[0,0],[1,0],[1,1],[0,0]]]]}`,
+		`{"type":"GeometryCollection","geometries":[]}`,
+		`{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[1,2]},{"type":"GeometryCollection","geometries":[]}]}`,
+		`{"type":"Point","coordinates":[1,2],"bbox":[1,2,1,2],"properties":{"a":[true,false,null,"é\"",{}]}}`,
+		`{"type":"Point","coordinates":[1,2]}`,
+		`{"type":"Point","coordinates":[1e400,2]}`,
+		`{"type":"Point","coordinates":[01,2]}`,
+		`{"type":"Point","coordinates":["1",2]}`,
+		`{"type":"Point","coordinates":[1,2]`,
+		`{"type":"Point","coordinates":[1,2]} x`,
+		`{"type":"Point" "coordinates":[1,2]}`,
+		`[]`,
+		``,
+	} {
+		var want Geometry
+		wantErr := json.Unmarshal([]byte(s), &want)
+		var wantT geom.T
+		if wantErr == nil && want.Type != "" {
+			wantT, wantErr = want.Decode()
+		}
+		var got Geometry
+		gotErr := leanUnmarshalGeometry([]byte(s), &got)
+		var gotT geom.T
+		if gotErr == nil && got.Type != "" {
+			gotT, gotErr = got.Decode()
+		}
+		if (gotErr == nil) != (wantErr == nil) {
+			t.Errorf("%s: got error %v, want error %v", s, gotErr, wantErr)
+			continue
+		}
+		if gotErr != nil {
+			continue
+		}
+		if got.Type != want.Type || len(got.Geometries) != len(want.Geometries) {
+			t.Errorf("%s: got %+v, want %+v", s, got, want)
+		}
+		if !reflect.DeepEqual(gotT, wantT) {
+			t.Errorf("%s: got %#v, want %#v", s, gotT, wantT)
+		}
+	}
+}
+
+func TestLeanUnmarshalCoords(t *testing.T) {
+	for _, tc := range []struct {
+		s     string
+		want  [][]geom.Coord
+		isErr bool
+	}{
+		{s: `null`, want: nil},
+		{s: `[]`, want: [][]geom.Coord{}},
+		{s: `[null]`, want: [][]geom.Coord{nil}},
+		{s: `[[[1,2],null]]`, want: [][]geom.Coord{{{1, 2}, nil}}},
+		{s: `[[[1,null]]]`, want: [][]geom.Coord{{{1, 0}}}},
+		{s: `[[[1,2]]`, isErr: true},
+		{s: `[[[1,2]]] [`, isErr: true},
+		{s: `[[[1,2,]]]`, isErr: true},
+	} {
+		var got [][]geom.Coord
+		err := leanUnmarshalCoords([]byte(tc.s), &got)
+		if tc.isErr {
+			if err == nil {
+				t.Errorf("leanUnmarshalCoords(%q, ...) == nil, want non-nil", tc.s)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("leanUnmarshalCoords(%q, ...) == %v, got %v, want nil, %v", tc.s, err, got, tc.want)
+		}
+	}
+}
+
+func TestLeanUnmarshalDepth(t *testing.T) {
+	data := make([]byte, 0, 2*maxLeanDepth+64)
+	data = append(data, `{"type":"Point","coordinates":[1,2],"x":`...)
+	for i := 0; i <= maxLeanDepth; i++ {
+		data = append(data, '[')
+	}
+	for i := 0; i <= maxLeanDepth; i++ {
+		data = append(data, ']')
+	}
+	data = append(data, '}')
+	var g Geometry
+	if err := leanUnmarshalGeometry(data, &g); err == nil {
+		t.Errorf("leanUnmarshalGeometry(deeply nested) == nil, want non-nil")
+	}
+}
@@ -0,0 +1,170 @@
+package geojson
+
+import (
+	"math"
+
+	geom "github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/feature"
+)
+
+// An Option configures encoding or decoding. Options that do not apply to an
+// operation are ignored.
+type Option func(*options)
+
+type options struct {
+	bbox            bool
+	cutAntimeridian bool
+	dropZ           bool
+	featureFilter   feature.Filter
+	m               MPolicy
+	orient          bool
+	precision       float64 // scale factor, or zero to disable rounding
+	rawProperties   bool
+	recordSeparator bool
+	sridFunc        func(int) (int, error)
+	strict          bool
+}
+
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithAntimeridianCutting sets whether geometries are cut at the antimeridian
+// with geo.CutAntimeridian before they are encoded, as recommended by RFC
+// 7946, so that lines and polygons that cross it render correctly on web
+// maps.
+func WithAntimeridianCutting(cut bool) Option {
+	return func(o *options) {
+		o.cutAntimeridian = cut
+	}
+}
+
+// WithBBox sets whether the bounding boxes of geometries, features, and
+// feature collections are computed and included when encoding. Existing
+// bounding boxes of features and feature collections are always included.
+func WithBBox(bbox bool) Option {
+	return func(o *options) {
+		o.bbox = bbox
+	}
+}
+
+// WithFeatureFilter sets a filter that decoders of feature collections and
+// sequences apply to each feature before decoding its geometry, skipping the
+// features that it rejects. The id passed to filter is the feature's id, or
+// empty if it has none. Decoders of single features ignore filter.
+func WithFeatureFilter(filter feature.Filter) Option {
+	return func(o *options) {
+		o.featureFilter = filter
+	}
+}
+
+// WithOrientation sets whether the rings of polygons are rewound with Orient
+// to follow the RFC 7946 right-hand rule before they are encoded, instead of
+// being encoded in their existing order.
+func WithOrientation(orient bool) Option {
+	return func(o *options) {
+		o.orient = orient
+	}
+}
+
+// WithPrecision sets the number of decimal places to which coordinates and
+// bounding boxes are rounded when encoding. Six decimal places of longitude
+// and latitude resolve about 10cm, and rounding to them typically halves the
+// size of the encoding. Negative decimals disable rounding, which is the
+// default.
+func WithPrecision(decimals int) Option {
+	return func(o *options) {
+		if decimals < 0 {
+			o.precision = 0
+		} else {
+			o.precision = math.Pow10(decimals)
+		}
+	}
+}
+
+// WithRawProperties sets whether the properties of decoded features are kept
+// undecoded in Feature.RawProperties instead of being decoded into
+// Feature.Properties. This avoids the cost of decoding properties that are
+// only passed through, or allows them to be decoded into a struct later.
+// Null properties leave both fields nil.
+func WithRawProperties(raw bool) Option {
+	return func(o *options) {
+		o.rawProperties = raw
+	}
+}
+
+// WithRecordSeparator sets whether a SeqWriter precedes each feature with a
+// record separator, as required by RFC 8142 GeoJSON text sequences, for
+// example for ogr2ogr's GeoJSONSeq driver. Without record separators, it
+// writes newline-delimited GeoJSON. SeqReaders accept both.
+func WithRecordSeparator(recordSeparator bool) Option {
+	return func(o *options) {
+		o.recordSeparator = recordSeparator
+	}
+}
+
+// WithSRIDFunc sets a function that is called with the SRID of each decoded
+// geometry and returns the SRID to assign to it, or an error to reject it. It
+// allows SRIDs to be normalized or validated in one place. GeoJSON does not
+// encode SRIDs, so f is always called with zero.
+func WithSRIDFunc(f func(srid int) (int, error)) Option {
+	return func(o *options) {
+		o.sridFunc = f
+	}
+}
+
+// WithStrict sets whether RFC 7946 is enforced. When decoding, objects with
+// the legacy crs member are rejected with ErrCRS. When encoding and decoding,
+// coordinates that appear to be in latitude, longitude order are rejected
+// with ErrAxisOrder, other coordinates outside the range of WGS84 longitudes
+// and latitudes with ErrCoordOutOfRange, and polygons that do not follow the
+// right-hand rule with ErrWinding.
+func WithStrict(strict bool) Option {
+	return func(o *options) {
+		o.strict = strict
+	}
+}
+
+// roundFloats rounds fs in place to the configured precision. Halves are
+// rounded away from zero and negative zeros are made positive.
+func (o options) roundFloats(fs []float64) {
+	if o.precision == 0 {
+		return
+	}
+	for i, f := range fs {
+		if r := math.Round(f*o.precision) / o.precision; r != 0 {
+			fs[i] = r
+		} else {
+			fs[i] = 0
+		}
+	}
+}
+
+// roundCoords rounds coords, which must be newly allocated, in place to the
+// configured precision and returns it.
+func (o options) roundCoords(coords interface{}) interface{} {
+	if o.precision == 0 {
+		return coords
+	}
+	switch coords := coords.(type) {
+	case geom.Coord:
+		o.roundFloats(coords)
+	case []geom.Coord:
+		for _, c := range coords {
+			o.roundFloats(c)
+		}
+	case [][]geom.Coord:
+		for _, c := range coords {
+			o.roundCoords(c)
+		}
+	case [][][]geom.Coord:
+		for _, c := range coords {
+			o.roundCoords(c)
+		}
+	}
+	return coords
+}
@@ -0,0 +1,67 @@
+package geojson
+
+import (
+	geom "github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/internal/planar"
+)
+
+// Orient returns g with the rings of its polygons rewound to follow the RFC
+// 7946 right-hand rule, with exterior rings counterclockwise and holes
+// clockwise, which most client libraries assume. g is not modified: if any
+// ring must be rewound, a copy is returned. Degenerate rings with zero area
+// are left as they are.
+func Orient(g geom.T) geom.T {
+	switch g := g.(type) {
+	case *geom.Polygon:
+		if flatCoords, ok := orientEnds(g.FlatCoords(), 0, g.Ends(), g.Stride()); ok {
+			return geom.NewPolygonFlat(g.Layout(), flatCoords, g.Ends()).SetSRID(g.SRID())
+		}
+	case *geom.MultiPolygon:
+		flatCoords, stride := g.FlatCoords(), g.Stride()
+		rewound, offset := false, 0
+		for _, ends := range g.Endss() {
+			if fcs, ok := orientEnds(flatCoords, offset, ends, stride); ok {
+				flatCoords, rewound = fcs, true
+			}
+			if len(ends) > 0 {
+				offset = ends[len(ends)-1]
+			}
+		}
+		if rewound {
+			return geom.NewMultiPolygonFlat(g.Layout(), flatCoords, g.Endss()).SetSRID(g.SRID())
+		}
+	case *geom.GeometryCollection:
+		geoms := make([]geom.T, g.NumGeoms())
+		rewound := false
+		for i := range geoms {
+			geoms[i] = Orient(g.Geom(i))
+			if geoms[i] != g.Geom(i) {
+				rewound = true
+			}
+		}
+		if rewound {
+			return geom.NewGeometryCollection().SetSRID(g.SRID()).MustPush(geoms...)
+		}
+	}
+	return g
+}
+
+// orientEnds rewinds the rings of the polygon with ends starting at offset in
+// flatCoords. It returns a copy of flatCoords with the rings rewound and true
+// if any ring had to be rewound, and flatCoords and false otherwise.
+func orientEnds(flatCoords []float64, offset int, ends []int, stride int) ([]float64, bool) {
+	copied := false
+	for i, end := range ends {
+		ring := flatCoords[offset:end]
+		if area := planar.SignedArea(ring, stride); area != 0 && (area > 0) != (i == 0) {
+			if !copied {
+				flatCoords = append([]float64(nil), flatCoords...)
+				ring = flatCoords[offset:end]
+				copied = true
+			}
+			planar.Reverse(ring, stride)
+		}
+		offset = end
+	}
+	return flatCoords, copied
+}
@@ -0,0 +1,79 @@
+package geojson
+
+import (
+	"reflect"
+	"testing"
+
+	geom "github.com/twpayne/go-geom"
+)
+
+func TestOrient(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		g    geom.T
+		want geom.T
+	}{
+		{
+			name: "point",
+			g:    geom.NewPointFlat(geom.XY, []float64{1, 2}),
+			want: geom.NewPointFlat(geom.XY, []float64{1, 2}),
+		},
+		{
+			name: "oriented_polygon",
+			g:    geom.NewPolygonFlat(geom.XY, []float64{0, 0, 4, 0, 4, 4, 0, 4, 0, 0, 1, 1, 1, 2, 2, 2, 2, 1, 1, 1}, []int{10, 20}),
+			want: geom.NewPolygonFlat(geom.XY, []float64{0, 0, 4, 0, 4, 4, 0, 4, 0, 0, 1, 1, 1, 2, 2, 2, 2, 1, 1, 1}, []int{10, 20}),
+		},
+		{
+			name: "polygon",
+			g:    geom.NewPolygonFlat(geom.XYZ, []float64{0, 0, 1, 0, 4, 2, 4, 4, 3, 4, 0, 4, 0, 0, 1, 1, 1, 5, 2, 1, 6, 2, 2, 7, 1, 1, 5}, []int{15, 27}).SetSRID(4326),
+			want: geom.NewPolygonFlat(geom.XYZ, []float64{0, 0, 1, 4, 0, 4, 4, 4, 3, 0, 4, 2, 0, 0, 1, 1, 1, 5, 2, 2, 7, 2, 1, 6, 1, 1, 5}, []int{15, 27}).SetSRID(4326),
+		},
+		{
+			name: "multipolygon",
+			g: geom.NewMultiPolygonFlat(geom.XY, []float64{
+				0, 0, 1, 0, 1, 1, 0, 0,
+				2, 0, 3, 1, 3, 0, 2, 0,
+			}, [][]int{{8}, {}, {16}}),
+			want: geom.NewMultiPolygonFlat(geom.XY, []float64{
+				0, 0, 1, 0, 1, 1, 0, 0,
+				2, 0, 3, 0, 3, 1, 2, 0,
+			}, [][]int{{8}, {}, {16}}),
+		},
+		{
+			name: "geometry_collection",
+			g: geom.NewGeometryCollection().MustPush(
+				geom.NewPointFlat(geom.XY, []float64{1, 2}),
+				geom.NewPolygonFlat(geom.XY, []float64{0, 0, 1, 1, 1, 0, 0, 0}, []int{8}),
+			),
+			want: geom.NewGeometryCollection().MustPush(
+				geom.NewPointFlat(geom.XY, []float64{1, 2}),
+				geom.NewPolygonFlat(geom.XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}, []int{8}),
+			),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			original := reflect.ValueOf(tc.g).Elem().Interface()
+			got := Orient(tc.g)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Orient(%#v) == %#v, want %#v", tc.g, got, tc.want)
+			}
+			if !reflect.DeepEqual(reflect.ValueOf(tc.g).Elem().Interface(), original) {
+				t.Errorf("Orient(%#v) modified its argument", tc.g)
+			}
+			if reflect.DeepEqual(tc.g, tc.want) && got != tc.g {
+				t.Errorf("Orient(%#v) copied an oriented geometry", tc.g)
+			}
+		})
+	}
+}
+
+func TestOrientation(t *testing.T) {
+	polygon := geom.NewPolygonFlat(geom.XY, []float64{0, 0, 0, 1, 1, 1, 0, 0}, []int{8})
+	if _, err := Marshal(polygon, WithStrict(true)); err != (ErrWinding{}) {
+		t.Errorf("Marshal(polygon, WithStrict(true)) == _, %v, want _, %v", err, ErrWinding{})
+	}
+	want := `{"type":"Polygon","coordinates":[[[0,0],[1,1],[0,1],[0,0]]]}`
+	if got, err := Marshal(polygon, WithOrientation(true), WithStrict(true)); err != nil || string(got) != want {
+		t.Errorf("Marshal(polygon, WithOrientation(true), WithStrict(true)) == %s, %v, want %s, <nil>", got, err, want)
+	}
+}
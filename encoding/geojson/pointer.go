@@ -0,0 +1,116 @@
+package geojson
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// maxDecodeErrorValueLength is the maximum length of the value included in
+// the message of a DecodeError.
+const maxDecodeErrorValueLength = 64
+
+// A DecodeError is returned when a value inside a GeoJSON object cannot be
+// decoded. It records where the value is, so that problems deep inside large
+// documents can be found. The underlying error, for example an
+// ErrUnsupportedType, is available with errors.Is and errors.As, which must
+// be used instead of comparing errors returned by decoding functions
+// directly.
+type DecodeError struct {
+	// Pointer is the RFC 6901 JSON Pointer to the value, relative to the
+	// decoded object, for example /features/1234/geometry/coordinates/5/1.
+	Pointer string
+	// Value is the JSON text of the offending value, or nil if it is not
+	// known.
+	Value json.RawMessage
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	message := strings.TrimPrefix(e.Err.Error(), "geojson: ")
+	if e.Value == nil {
+		return fmt.Sprintf("geojson: %s: %s", e.Pointer, message)
+	}
+	value := string(e.Value)
+	if len(value) > maxDecodeErrorValueLength {
+		value = value[:maxDecodeErrorValueLength] + "..."
+	}
+	return fmt.Sprintf("geojson: %s: %s: %s", e.Pointer, value, message)
+}
+
+// Unwrap returns the underlying error.
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// atPointer returns err located at pointer. If err is already a DecodeError
+// then pointer is prepended to its pointer.
+func atPointer(err error, pointer string) error {
+	if e, ok := err.(*DecodeError); ok {
+		return &DecodeError{
+			Pointer: pointer + e.Pointer,
+			Value:   e.Value,
+			Err:     e.Err,
+		}
+	}
+	return &DecodeError{
+		Pointer: pointer,
+		Err:     err,
+	}
+}
+
+// coordsError returns err located at the first malformed position in the
+// coordinates of g, which are nested depth levels deep, or at the
+// coordinates themselves if no position is malformed.
+func (g *Geometry) coordsError(err error, depth int) error {
+	pointer, value := "", json.RawMessage(*g.Coordinates)
+	if _, p, v := locatePosition(*g.Coordinates, depth, -1); v != nil {
+		pointer, value = p, v
+	}
+	return &DecodeError{
+		Pointer: "/coordinates" + pointer,
+		Value:   value,
+		Err:     err,
+	}
+}
+
+// locatePosition returns the pointer to and value of the first malformed
+// position in data, which is nested depth levels deep, or a nil value if
+// there is none. A position is malformed if it is not an array of at least
+// two numbers, or if it has a different number of elements than stride. A
+// negative stride is set from the first position. The possibly updated stride
+// is also returned.
+func locatePosition(data json.RawMessage, depth, stride int) (int, string, json.RawMessage) {
+	var elems []json.RawMessage
+	if err := json.Unmarshal(data, &elems); err != nil {
+		return stride, "", data
+	}
+	if depth == 0 {
+		if len(elems) < 2 || stride >= 0 && len(elems) != stride {
+			return stride, "", data
+		}
+		for i, elem := range elems {
+			var f float64
+			if err := json.Unmarshal(elem, &f); err != nil {
+				return stride, "/" + strconv.Itoa(i), elem
+			}
+		}
+		return len(elems), "", nil
+	}
+	for i, elem := range elems {
+		var pointer string
+		var value json.RawMessage
+		if stride, pointer, value = locatePosition(elem, depth-1, stride); value != nil {
+			return stride, "/" + strconv.Itoa(i) + pointer, value
+		}
+	}
+	return stride, "", nil
+}
+
+// quote returns the JSON encoding of s.
+func quote(s string) json.RawMessage {
+	data, _ := json.Marshal(s)
+	return data
+}
@@ -0,0 +1,110 @@
+package geojson
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDecodeError(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		s           string
+		wantPointer string
+		wantValue   string
+		wantErr     error
+	}{
+		{
+			name:        "number",
+			s:           `{"type":"LineString","coordinates":[[0,0],[1,"a"]]}`,
+			wantPointer: "/coordinates/1/1",
+			wantValue:   `"a"`,
+		},
+		{
+			name:        "dimensionality",
+			s:           `{"type":"Point","coordinates":[1]}`,
+			wantPointer: "/coordinates",
+			wantValue:   `[1]`,
+			wantErr:     ErrDimensionalityTooLow(1),
+		},
+		{
+			name:        "stride",
+			s:           `{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,0]],[[0,0],[1,0,2],[1,1],[0,0]]]}`,
+			wantPointer: "/coordinates/1/1",
+			wantValue:   `[1,0,2]`,
+		},
+		{
+			name:        "not_a_position",
+			s:           `{"type":"MultiPolygon","coordinates":[[[[0,0],[1,0],{},[0,0]]]]}`,
+			wantPointer: "/coordinates/0/0/2",
+			wantValue:   `{}`,
+		},
+		{
+			name:        "type",
+			s:           `{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[1,2]},{"type":"Circle"}]}`,
+			wantPointer: "/geometries/1/type",
+			wantValue:   `"Circle"`,
+			wantErr:     ErrUnsupportedType("Circle"),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, prefix := range []string{"", "/features/2/geometry"} {
+				data := []byte(tc.s)
+				var err error
+				if prefix == "" {
+					_, err = decodeTestGeometry(data)
+				} else {
+					data = []byte(`{"type":"FeatureCollection","features":[` + strings.Repeat(`{"type":"Feature","geometry":null,"properties":null},`, 2) + `{"type":"Feature","geometry":` + tc.s + `,"properties":null}]}`)
+					_, err = UnmarshalFeatureCollection(data)
+				}
+				var decodeErr *DecodeError
+				if !errors.As(err, &decodeErr) {
+					t.Fatalf("got error %v, want a *DecodeError", err)
+				}
+				if decodeErr.Pointer != prefix+tc.wantPointer || string(decodeErr.Value) != tc.wantValue {
+					t.Errorf("got pointer %s and value %s, want %s and %s", decodeErr.Pointer, decodeErr.Value, prefix+tc.wantPointer, tc.wantValue)
+				}
+				if tc.wantErr != nil && !errors.Is(err, tc.wantErr) {
+					t.Errorf("got error %v, want %v", err, tc.wantErr)
+				}
+			}
+		})
+	}
+}
+
+func decodeTestGeometry(data []byte) (interface{}, error) {
+	var g Geometry
+	if err := unmarshalGeometry(data, &g); err != nil {
+		return nil, err
+	}
+	return g.Decode()
+}
+
+func TestDecodeErrorFeatures(t *testing.T) {
+	s := `{"type":"FeatureCollection","features":[{"type":"Feature","geometry":null,"properties":null},{"type":"Feature","geometry":null,"properties":[]}]}`
+	want := &DecodeError{Pointer: "/features/1/properties"}
+	for _, f := range []func() error{
+		func() error {
+			_, err := UnmarshalFeatureCollection([]byte(s))
+			return err
+		},
+		func() error {
+			var fc FeatureCollection
+			return fc.UnmarshalJSON([]byte(s))
+		},
+		func() error {
+			_, err := decodeAllFeatures(NewFeatureCollectionDecoder(strings.NewReader(s)))
+			return err
+		},
+	} {
+		var decodeErr *DecodeError
+		if err := f(); !errors.As(err, &decodeErr) || decodeErr.Pointer != want.Pointer {
+			t.Errorf("got error %v, want pointer %s", err, want.Pointer)
+		}
+	}
+
+	err := &DecodeError{Pointer: "/features/0/geometry/type", Value: quote("Circle"), Err: ErrUnsupportedType("Circle")}
+	if got, want := err.Error(), `geojson: /features/0/geometry/type: "Circle": unsupported type: Circle`; got != want {
+		t.Errorf("err.Error() == %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,67 @@
+package geojson
+
+import (
+	"testing"
+
+	"github.com/twpayne/go-geom"
+)
+
+func TestPrecision(t *testing.T) {
+	ls := geom.NewLineStringFlat(geom.XYZ, []float64{-0.1234567, 51.98765432, 10.55, 1.00000049, -2.5, 0})
+	for _, tc := range []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{
+			name: "default",
+			want: `{"type":"LineString","coordinates":[[-0.1234567,51.98765432,10.55],[1.00000049,-2.5,0]]}`,
+		},
+		{
+			name: "six",
+			opts: []Option{WithPrecision(6)},
+			want: `{"type":"LineString","coordinates":[[-0.123457,51.987654,10.55],[1,-2.5,0]]}`,
+		},
+		{
+			name: "zero",
+			opts: []Option{WithPrecision(0)},
+			want: `{"type":"LineString","coordinates":[[0,52,11],[1,-3,0]]}`,
+		},
+		{
+			name: "disabled",
+			opts: []Option{WithPrecision(2), WithPrecision(-1)},
+			want: `{"type":"LineString","coordinates":[[-0.1234567,51.98765432,10.55],[1.00000049,-2.5,0]]}`,
+		},
+		{
+			name: "bbox",
+			opts: []Option{WithPrecision(1), WithBBox(true)},
+			want: `{"type":"LineString","bbox":[-0.1,-2.5,0,1,52,10.6],"coordinates":[[-0.1,52,10.6],[1,-2.5,0]]}`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got, err := Marshal(ls, tc.opts...); err != nil || string(got) != tc.want {
+				t.Errorf("Marshal(...) == %s, %v, want %s, <nil>", got, err, tc.want)
+			}
+		})
+	}
+	if got := ls.FlatCoords()[0]; got != -0.1234567 {
+		t.Errorf("Marshal(...) modified coordinates, got %v", got)
+	}
+}
+
+func TestPrecisionFeatureCollection(t *testing.T) {
+	fc := &FeatureCollection{
+		Features: []*Feature{
+			{
+				Geometry: geom.NewGeometryCollection().MustPush(
+					geom.NewPointFlat(geom.XY, []float64{1.23456, 2.34567}),
+					geom.NewPolygonFlat(geom.XY, []float64{0, 0, 1.11111, 0, 0, 1.11111, 0, 0}, []int{8}),
+				),
+			},
+		},
+	}
+	want := `{"type":"FeatureCollection","features":[{"type":"Feature","geometry":{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[1.23,2.35]},{"type":"Polygon","coordinates":[[[0,0],[1.11,0],[0,1.11],[0,0]]]}]},"properties":null}]}`
+	if got, err := MarshalFeatureCollection(fc, WithPrecision(2)); err != nil || string(got) != want {
+		t.Errorf("MarshalFeatureCollection(...) == %s, %v, want %s, <nil>", got, err, want)
+	}
+}
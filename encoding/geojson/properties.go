@@ -0,0 +1,42 @@
+package geojson
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// DecodeProperties decodes the properties of f into v, which is typically a
+// pointer to a struct, as json.Unmarshal does. It decodes RawProperties if
+// they are set, so the most efficient way to decode features into structs is
+// to unmarshal them with WithRawProperties and then call DecodeProperties.
+// Otherwise, Properties are re-encoded and then decoded. v is not modified if
+// f has no properties.
+func (f *Feature) DecodeProperties(v interface{}) error {
+	data := f.RawProperties
+	if data == nil {
+		if f.Properties == nil {
+			return nil
+		}
+		var err error
+		if data, err = json.Marshal(f.Properties); err != nil {
+			return err
+		}
+	}
+	return json.Unmarshal(data, v)
+}
+
+// EncodeProperties sets the properties of f to the encoding of v, which is
+// typically a struct, as json.Marshal does. The encoding is stored in
+// RawProperties, so it is written verbatim when f is marshaled, and
+// Properties is cleared. If v encodes to null then f has no properties.
+func (f *Feature) EncodeProperties(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(data, nullGeometry) {
+		data = nil
+	}
+	f.Properties, f.RawProperties = nil, data
+	return nil
+}
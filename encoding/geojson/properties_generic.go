@@ -0,0 +1,13 @@
+//go:build go1.18
+// +build go1.18
+
+package geojson
+
+// PropertiesAs returns the properties of f decoded into a T, which is
+// typically a struct, as DecodeProperties does. It returns the zero T if f
+// has no properties.
+func PropertiesAs[T any](f *Feature) (T, error) {
+	var v T
+	err := f.DecodeProperties(&v)
+	return v, err
+}
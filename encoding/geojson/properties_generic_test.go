@@ -0,0 +1,32 @@
+//go:build go1.18
+// +build go1.18
+
+package geojson
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestPropertiesAs(t *testing.T) {
+	data := []byte(`{"type":"Feature","geometry":null,"properties":{"name":"Zürich","population":421878,"tags":["city"]}}`)
+	want := testProperties{Name: "Zürich", Population: 421878, Tags: []string{"city"}}
+	for _, opts := range [][]Option{nil, {WithRawProperties(true)}} {
+		f, err := UnmarshalFeature(data, opts...)
+		if err != nil {
+			t.Fatalf("UnmarshalFeature(...) == _, %v, want _, <nil>", err)
+		}
+		if got, err := PropertiesAs[testProperties](f); err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("PropertiesAs[testProperties](f) == %+v, %v, want %+v, <nil>", got, err, want)
+		}
+	}
+
+	if got, err := PropertiesAs[testProperties](&Feature{}); err != nil || !reflect.DeepEqual(got, testProperties{}) {
+		t.Errorf("PropertiesAs[testProperties](&Feature{}) == %+v, %v, want %+v, <nil>", got, err, testProperties{})
+	}
+	f := &Feature{RawProperties: json.RawMessage(`{"population":"many"}`)}
+	if _, err := PropertiesAs[testProperties](f); err == nil {
+		t.Errorf("PropertiesAs[testProperties](f) == _, <nil>, want error")
+	}
+}
@@ -0,0 +1,67 @@
+package geojson
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+)
+
+type testProperties struct {
+	Name       string   `json:"name"`
+	Population int      `json:"population"`
+	Tags       []string `json:"tags,omitempty"`
+}
+
+func TestDecodeProperties(t *testing.T) {
+	data := []byte(`{"type":"Feature","geometry":null,"properties":{"name":"Zürich","population":421878,"tags":["city"]}}`)
+	want := testProperties{Name: "Zürich", Population: 421878, Tags: []string{"city"}}
+	for _, tc := range []struct {
+		name string
+		opts []Option
+	}{
+		{name: "properties"},
+		{name: "raw_properties", opts: []Option{WithRawProperties(true)}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := UnmarshalFeature(data, tc.opts...)
+			if err != nil {
+				t.Fatalf("UnmarshalFeature(...) == _, %v, want _, <nil>", err)
+			}
+			var got testProperties
+			if err := f.DecodeProperties(&got); err != nil || !reflect.DeepEqual(got, want) {
+				t.Errorf("f.DecodeProperties(...) == %v, got %+v, want <nil>, %+v", err, got, want)
+			}
+		})
+	}
+
+	got := testProperties{Name: "unchanged"}
+	if err := (&Feature{}).DecodeProperties(&got); err != nil || got.Name != "unchanged" {
+		t.Errorf("DecodeProperties of feature without properties == %v, got %+v", err, got)
+	}
+	f := &Feature{RawProperties: json.RawMessage(`{"population":"many"}`)}
+	if err := f.DecodeProperties(&got); err == nil {
+		t.Errorf("f.DecodeProperties(...) == <nil>, want error")
+	}
+}
+
+func TestEncodeProperties(t *testing.T) {
+	f := &Feature{
+		Geometry:   geom.NewPointFlat(geom.XY, []float64{8.54, 47.37}),
+		Properties: map[string]interface{}{"stale": true},
+	}
+	if err := f.EncodeProperties(testProperties{Name: "Zürich", Population: 421878}); err != nil {
+		t.Fatalf("f.EncodeProperties(...) == %v, want <nil>", err)
+	}
+	if f.Properties != nil {
+		t.Errorf("f.Properties == %v, want nil", f.Properties)
+	}
+	want := `{"type":"Feature","geometry":{"type":"Point","coordinates":[8.54,47.37]},"properties":{"name":"Zürich","population":421878}}`
+	if got, err := json.Marshal(f); err != nil || string(got) != want {
+		t.Errorf("json.Marshal(f) == %s, %v, want %s, <nil>", got, err, want)
+	}
+	if err := f.EncodeProperties(nil); err != nil || f.RawProperties != nil {
+		t.Errorf("f.EncodeProperties(nil) == %v, RawProperties %s, want <nil>, nil", err, f.RawProperties)
+	}
+}
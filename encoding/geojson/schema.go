@@ -0,0 +1,178 @@
+package geojson
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// Schema reference prefixes for common consumers of Schemas.
+const (
+	// JSONSchemaRefPrefix is the reference prefix for schemas stored in the
+	// definitions of a JSON Schema document or a Swagger 2.0 specification.
+	JSONSchemaRefPrefix = "#/definitions/"
+	// OpenAPIRefPrefix is the reference prefix for schemas stored in the
+	// components of an OpenAPI 3 specification.
+	OpenAPIRefPrefix = "#/components/schemas/"
+)
+
+// schemaGeometryTypes are the names of the GeoJSON geometry types, in the
+// order in which their schemas are listed in the Geometry schema.
+var schemaGeometryTypes = []string{
+	"Point",
+	"LineString",
+	"Polygon",
+	"MultiPoint",
+	"MultiLineString",
+	"MultiPolygon",
+	"GeometryCollection",
+}
+
+// Schemas returns JSON Schema definitions of the GeoJSON types accepted and
+// produced by this package, keyed by name. Names are prefixed with GeoJSON,
+// for example GeoJSONPoint and GeoJSONFeatureCollection, to avoid collisions
+// with an application's own schemas. Schemas refer to each other with
+// references of the form refPrefix followed by the name, so refPrefix must
+// match where the schemas are stored, for example JSONSchemaRefPrefix or
+// OpenAPIRefPrefix.
+//
+// The schemas use JSON Schema draft 2020-12, as does OpenAPI 3.1. Positions
+// have between two and four ordinates, feature IDs are strings, and empty
+// geometries have empty coordinates arrays.
+func Schemas(refPrefix string) map[string]json.RawMessage {
+	ref := func(name string) map[string]interface{} {
+		return map[string]interface{}{"$ref": refPrefix + "GeoJSON" + name}
+	}
+	arrayOf := func(items interface{}, minItems int) map[string]interface{} {
+		schema := map[string]interface{}{
+			"type":  "array",
+			"items": items,
+		}
+		if minItems > 0 {
+			schema["minItems"] = minItems
+		}
+		return schema
+	}
+	object := func(typeName string, required []string, properties map[string]interface{}) map[string]interface{} {
+		properties["type"] = map[string]interface{}{
+			"type": "string",
+			"enum": []string{typeName},
+		}
+		properties["bbox"] = ref("BBox")
+		return map[string]interface{}{
+			"title":      "GeoJSON " + typeName,
+			"type":       "object",
+			"required":   append([]string{"type"}, required...),
+			"properties": properties,
+		}
+	}
+	// orEmpty allows the empty coordinates arrays of empty geometries.
+	orEmpty := func(schema interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"oneOf": []interface{}{
+				map[string]interface{}{"type": "array", "maxItems": 0},
+				schema,
+			},
+		}
+	}
+	nullOr := func(schema interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"oneOf": []interface{}{
+				map[string]interface{}{"type": "null"},
+				schema,
+			},
+		}
+	}
+
+	position := map[string]interface{}{
+		"title":    "GeoJSON Position",
+		"type":     "array",
+		"items":    map[string]interface{}{"type": "number"},
+		"minItems": 2,
+		"maxItems": 4,
+	}
+	bbox := map[string]interface{}{
+		"title": "GeoJSON Bounding Box",
+		"type":  "array",
+		"items": map[string]interface{}{"type": "number"},
+		"oneOf": []interface{}{
+			map[string]interface{}{"minItems": 4, "maxItems": 4},
+			map[string]interface{}{"minItems": 6, "maxItems": 6},
+		},
+	}
+	linearRing := arrayOf(ref("Position"), 4)
+	lineString := arrayOf(ref("Position"), 2)
+
+	geometryRefs := make([]interface{}, 0, len(schemaGeometryTypes))
+	for _, name := range schemaGeometryTypes {
+		geometryRefs = append(geometryRefs, ref(name))
+	}
+
+	schemas := map[string]interface{}{
+		"Position": position,
+		"BBox":     bbox,
+		"Point": object("Point", []string{"coordinates"}, map[string]interface{}{
+			"coordinates": orEmpty(ref("Position")),
+		}),
+		"LineString": object("LineString", []string{"coordinates"}, map[string]interface{}{
+			"coordinates": orEmpty(lineString),
+		}),
+		"Polygon": object("Polygon", []string{"coordinates"}, map[string]interface{}{
+			"coordinates": arrayOf(linearRing, 0),
+		}),
+		"MultiPoint": object("MultiPoint", []string{"coordinates"}, map[string]interface{}{
+			"coordinates": arrayOf(ref("Position"), 0),
+		}),
+		"MultiLineString": object("MultiLineString", []string{"coordinates"}, map[string]interface{}{
+			"coordinates": arrayOf(lineString, 0),
+		}),
+		"MultiPolygon": object("MultiPolygon", []string{"coordinates"}, map[string]interface{}{
+			"coordinates": arrayOf(arrayOf(linearRing, 0), 0),
+		}),
+		"GeometryCollection": object("GeometryCollection", []string{"geometries"}, map[string]interface{}{
+			"geometries": arrayOf(ref("Geometry"), 0),
+		}),
+		"Geometry": map[string]interface{}{
+			"title": "GeoJSON Geometry",
+			"oneOf": geometryRefs,
+		},
+		"Feature": object("Feature", []string{"geometry", "properties"}, map[string]interface{}{
+			"id":         map[string]interface{}{"type": "string"},
+			"geometry":   nullOr(ref("Geometry")),
+			"properties": nullOr(map[string]interface{}{"type": "object"}),
+		}),
+		"FeatureCollection": object("FeatureCollection", []string{"features"}, map[string]interface{}{
+			"features": arrayOf(ref("Feature"), 0),
+		}),
+	}
+
+	result := make(map[string]json.RawMessage, len(schemas))
+	for name, schema := range schemas {
+		data, err := json.Marshal(schema)
+		if err != nil {
+			// The schemas are constructed from maps, slices, strings, and
+			// ints, so they can always be marshaled.
+			panic(err)
+		}
+		result["GeoJSON"+name] = data
+	}
+	return result
+}
+
+// RegisterSchemas calls register with the name and definition of each schema
+// returned by Schemas(refPrefix), in order of name, stopping at the first
+// error. It adapts Schemas to OpenAPI generators that register component
+// schemas one at a time.
+func RegisterSchemas(refPrefix string, register func(name string, schema json.RawMessage) error) error {
+	schemas := Schemas(refPrefix)
+	names := make([]string, 0, len(schemas))
+	for name := range schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := register(name, schemas[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
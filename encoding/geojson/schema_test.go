@@ -0,0 +1,188 @@
+package geojson
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+)
+
+// validateSchema reports whether value is valid against schema, supporting
+// only the JSON Schema keywords used by Schemas.
+func validateSchema(t *testing.T, schemas map[string]interface{}, schema map[string]interface{}, value interface{}) bool {
+	if ref, ok := schema["$ref"].(string); ok {
+		target, ok := schemas[strings.TrimPrefix(ref, OpenAPIRefPrefix)].(map[string]interface{})
+		if !ok {
+			t.Fatalf("unresolved reference %q", ref)
+		}
+		return validateSchema(t, schemas, target, value)
+	}
+	if oneOf, ok := schema["oneOf"].([]interface{}); ok {
+		matches := 0
+		for _, s := range oneOf {
+			if validateSchema(t, schemas, s.(map[string]interface{}), value) {
+				matches++
+			}
+		}
+		if matches != 1 {
+			return false
+		}
+	}
+	switch schema["type"] {
+	case "null":
+		if value != nil {
+			return false
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return false
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			return false
+		}
+	case "object":
+		if _, ok := value.(map[string]interface{}); !ok {
+			return false
+		}
+	case "array":
+		if _, ok := value.([]interface{}); !ok {
+			return false
+		}
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, e := range enum {
+			found = found || e == value
+		}
+		if !found {
+			return false
+		}
+	}
+	if object, ok := value.(map[string]interface{}); ok {
+		if required, ok := schema["required"].([]interface{}); ok {
+			for _, name := range required {
+				if _, ok := object[name.(string)]; !ok {
+					return false
+				}
+			}
+		}
+		if properties, ok := schema["properties"].(map[string]interface{}); ok {
+			for name, s := range properties {
+				if v, ok := object[name]; ok && !validateSchema(t, schemas, s.(map[string]interface{}), v) {
+					return false
+				}
+			}
+		}
+	}
+	if array, ok := value.([]interface{}); ok {
+		if minItems, ok := schema["minItems"].(float64); ok && len(array) < int(minItems) {
+			return false
+		}
+		if maxItems, ok := schema["maxItems"].(float64); ok && len(array) > int(maxItems) {
+			return false
+		}
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for _, item := range array {
+				if !validateSchema(t, schemas, items, item) {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+func TestSchemas(t *testing.T) {
+	schemas := make(map[string]interface{})
+	for name, data := range Schemas(OpenAPIRefPrefix) {
+		var schema map[string]interface{}
+		if err := json.Unmarshal(data, &schema); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		schemas[name] = schema
+	}
+	validate := func(name, s string) bool {
+		var value interface{}
+		if err := json.Unmarshal([]byte(s), &value); err != nil {
+			t.Fatal(err)
+		}
+		return validateSchema(t, schemas, schemas[name].(map[string]interface{}), value)
+	}
+
+	fc := &FeatureCollection{
+		Features: []*Feature{
+			{
+				ID:       "1",
+				Geometry: geom.NewPointFlat(geom.XYZ, []float64{1, 2, 3}),
+			},
+			{
+				BBox:       geom.NewBounds(geom.XY).Set(0, 0, 1, 1),
+				Geometry:   geom.NewPolygonFlat(geom.XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}, []int{8}),
+				Properties: map[string]interface{}{"name": "triangle"},
+			},
+			{
+				Geometry: geom.NewGeometryCollection().MustPush(
+					geom.NewMultiLineStringFlat(geom.XY, []float64{1, 2, 3, 4}, []int{4}),
+					geom.NewMultiPolygonFlat(geom.XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}, [][]int{{8}}),
+					geom.NewMultiPointFlat(geom.XY, []float64{1, 2}),
+				),
+			},
+			{},
+		},
+	}
+	data, err := json.Marshal(fc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !validate("GeoJSONFeatureCollection", string(data)) {
+		t.Errorf("%s is not a valid GeoJSONFeatureCollection", data)
+	}
+
+	for _, tc := range []struct {
+		name  string
+		s     string
+		valid bool
+	}{
+		{name: "GeoJSONGeometry", s: `{"type":"LineString","coordinates":[[1,2],[3,4]]}`, valid: true},
+		{name: "GeoJSONGeometry", s: `{"type":"LineString","coordinates":[[1,2]]}`},
+		{name: "GeoJSONGeometry", s: `{"type":"LineString","coordinates":[]}`, valid: true},
+		{name: "GeoJSONGeometry", s: `{"type":"Point","coordinates":[]}`, valid: true},
+		{name: "GeoJSONGeometry", s: `{"type":"Point","coordinates":[1]}`},
+		{name: "GeoJSONGeometry", s: `{"type":"Point","coordinates":[1,2,3,4,5]}`},
+		{name: "GeoJSONGeometry", s: `{"type":"Polygon","coordinates":[[[0,0],[1,0],[0,0]]]}`},
+		{name: "GeoJSONGeometry", s: `{"type":"Curve","coordinates":[1,2]}`},
+		{name: "GeoJSONGeometry", s: `{"type":"Point","coordinates":[1,2],"bbox":[1,2,1,2,3]}`},
+		{name: "GeoJSONFeature", s: `{"type":"Feature","geometry":null,"properties":null}`, valid: true},
+		{name: "GeoJSONFeature", s: `{"type":"Feature","geometry":null}`},
+		{name: "GeoJSONFeature", s: `{"type":"Feature","id":1,"geometry":null,"properties":null}`},
+	} {
+		if got := validate(tc.name, tc.s); got != tc.valid {
+			t.Errorf("validate(%q, %s) == %t, want %t", tc.name, tc.s, got, tc.valid)
+		}
+	}
+}
+
+func TestRegisterSchemas(t *testing.T) {
+	var names []string
+	if err := RegisterSchemas(JSONSchemaRefPrefix, func(name string, schema json.RawMessage) error {
+		if strings.Contains(string(schema), OpenAPIRefPrefix) {
+			t.Errorf("%s: %s contains %q", name, schema, OpenAPIRefPrefix)
+		}
+		names = append(names, name)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != len(Schemas(JSONSchemaRefPrefix)) || names[0] != "GeoJSONBBox" {
+		t.Errorf("registered %v", names)
+	}
+	errStop := errors.New("stop")
+	if err := RegisterSchemas(JSONSchemaRefPrefix, func(string, json.RawMessage) error {
+		return errStop
+	}); err != errStop {
+		t.Errorf("RegisterSchemas(...) == %v, want %v", err, errStop)
+	}
+}
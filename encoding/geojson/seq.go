@@ -0,0 +1,109 @@
+package geojson
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+// recordSeparator is the character that precedes each JSON text in an RFC
+// 8142 GeoJSON text sequence.
+const recordSeparator = 0x1e
+
+// A SeqReader reads features from an RFC 8142 GeoJSON text sequence or from
+// newline-delimited GeoJSON, in which each feature is on its own line.
+type SeqReader struct {
+	dec     *json.Decoder
+	options options
+	err     error
+}
+
+// NewSeqReader returns a new SeqReader that reads from r. Options, for
+// example WithRawProperties, apply to each feature.
+func NewSeqReader(r io.Reader, opts ...Option) *SeqReader {
+	return &SeqReader{
+		dec:     json.NewDecoder(&rsStripper{r: r}),
+		options: newOptions(opts),
+	}
+}
+
+// Read returns the next feature. It returns io.EOF after the last feature.
+// Errors are sticky.
+func (r *SeqReader) Read() (*Feature, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	for {
+		var data json.RawMessage
+		if err := r.dec.Decode(&data); err != nil {
+			r.err = err
+			return nil, err
+		}
+		f := &Feature{}
+		ok, err := f.unmarshalJSON(data, r.options.featureFilter, r.options)
+		if err != nil {
+			r.err = err
+			return nil, err
+		}
+		if ok {
+			return f, nil
+		}
+	}
+}
+
+// An rsStripper removes record separators from a reader. JSON strings cannot
+// contain unescaped control characters, so record separators only occur
+// between JSON texts, where removing them leaves a stream of JSON texts
+// separated by whitespace.
+type rsStripper struct {
+	r io.Reader
+}
+
+func (s *rsStripper) Read(p []byte) (int, error) {
+	for {
+		n, err := s.r.Read(p)
+		m := 0
+		for _, b := range p[:n] {
+			if b != recordSeparator {
+				p[m] = b
+				m++
+			}
+		}
+		if m > 0 || err != nil {
+			return m, err
+		}
+	}
+}
+
+// A SeqWriter writes features as newline-delimited GeoJSON or, with
+// WithRecordSeparator, as an RFC 8142 GeoJSON text sequence.
+type SeqWriter struct {
+	w       io.Writer
+	options options
+	buf     bytes.Buffer
+}
+
+// NewSeqWriter returns a new SeqWriter that writes to w.
+func NewSeqWriter(w io.Writer, opts ...Option) *SeqWriter {
+	return &SeqWriter{
+		w:       w,
+		options: newOptions(opts),
+	}
+}
+
+// Write writes f, followed by a newline, with a single call to the underlying
+// io.Writer.
+func (w *SeqWriter) Write(f *Feature) error {
+	data, err := f.marshalJSON(w.options)
+	if err != nil {
+		return err
+	}
+	w.buf.Reset()
+	if w.options.recordSeparator {
+		w.buf.WriteByte(recordSeparator)
+	}
+	w.buf.Write(data)
+	w.buf.WriteByte('\n')
+	_, err = w.w.Write(w.buf.Bytes())
+	return err
+}
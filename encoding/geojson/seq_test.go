@@ -0,0 +1,108 @@
+package geojson
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/twpayne/go-geom"
+)
+
+func TestSeqReader(t *testing.T) {
+	want := []*Feature{
+		{ID: "a", Geometry: geom.NewPointFlat(geom.XY, []float64{1, 2})},
+		{ID: "b", Properties: map[string]interface{}{"name": "b"}},
+	}
+	for _, tc := range []struct {
+		name string
+		s    string
+	}{
+		{
+			name: "newline_delimited",
+			s: `{"type":"Feature","id":"a","geometry":{"type":"Point","coordinates":[1,2]},"properties":null}` + "\n" +
+				`{"type":"Feature","id":"b","geometry":null,"properties":{"name":"b"}}` + "\n",
+		},
+		{
+			name: "text_sequence",
+			s: "\x1e" + `{"type":"Feature","id":"a","geometry":{"type":"Point","coordinates":[1,2]},"properties":null}` + "\n" +
+				"\x1e" + `{"type":"Feature","id":"b","geometry":null,"properties":{"name":"b"}}` + "\n",
+		},
+		{
+			name: "multiline_texts",
+			s: "\x1e{\n  \"type\": \"Feature\",\n  \"id\": \"a\",\n  \"geometry\": {\"type\": \"Point\", \"coordinates\": [1, 2]},\n  \"properties\": null\n}\n" +
+				"\r\n\x1e" + `{"type":"Feature","id":"b","geometry":null,"properties":{"name":"b"}}`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewSeqReader(iotest.OneByteReader(strings.NewReader(tc.s)))
+			var got []*Feature
+			for {
+				f, err := r.Read()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("r.Read() == _, %v, want _, <nil>", err)
+				}
+				got = append(got, f)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %v, want %v", got, want)
+			}
+		})
+	}
+
+	r := NewSeqReader(strings.NewReader(`{"type":"Point","coordinates":[1,2]}`))
+	if _, err := r.Read(); err != ErrUnsupportedType("Point") {
+		t.Errorf("r.Read() == _, %v, want _, %v", err, ErrUnsupportedType("Point"))
+	}
+	if _, err := r.Read(); err != ErrUnsupportedType("Point") {
+		t.Errorf("r.Read() == _, %v, want sticky error %v", err, ErrUnsupportedType("Point"))
+	}
+}
+
+func TestSeqWriter(t *testing.T) {
+	features := []*Feature{
+		{Geometry: geom.NewPointFlat(geom.XY, []float64{1, 2})},
+		{ID: "b"},
+	}
+	for _, tc := range []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{
+			name: "newline_delimited",
+			want: `{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"properties":null}` + "\n" +
+				`{"type":"Feature","id":"b","geometry":null,"properties":null}` + "\n",
+		},
+		{
+			name: "text_sequence",
+			opts: []Option{WithRecordSeparator(true), WithBBox(true)},
+			want: "\x1e" + `{"type":"Feature","bbox":[1,2,1,2],"geometry":{"type":"Point","coordinates":[1,2]},"properties":null}` + "\n" +
+				"\x1e" + `{"type":"Feature","id":"b","geometry":null,"properties":null}` + "\n",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var b bytes.Buffer
+			w := NewSeqWriter(&b, tc.opts...)
+			for _, f := range features {
+				if err := w.Write(f); err != nil {
+					t.Fatalf("w.Write(...) == %v, want <nil>", err)
+				}
+			}
+			if got := b.String(); got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+			r := NewSeqReader(&b)
+			for i := range features {
+				if _, err := r.Read(); err != nil {
+					t.Errorf("%d: r.Read() == _, %v, want _, <nil>", i, err)
+				}
+			}
+		})
+	}
+}
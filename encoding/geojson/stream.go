@@ -0,0 +1,304 @@
+package geojson
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/twpayne/go-geom"
+)
+
+var (
+	errDuplicateFeatures = errors.New("geojson: duplicate features")
+	errEncoderClosed     = errors.New("geojson: encoder closed")
+)
+
+// An ErrUnexpectedToken is returned when a streamed FeatureCollection has an
+// unexpected structure.
+type ErrUnexpectedToken struct {
+	Token json.Token
+	Want  string
+}
+
+func (e ErrUnexpectedToken) Error() string {
+	return fmt.Sprintf("geojson: unexpected token %v, want %s", e.Token, e.Want)
+}
+
+// A FeatureCollectionDecoder decodes the features of a FeatureCollection one
+// at a time from an io.Reader, without reading the whole features array into
+// memory.
+type FeatureCollectionDecoder struct {
+	dec            *json.Decoder
+	options        options
+	bbox           *geom.Bounds
+	foreignMembers map[string]json.RawMessage
+	typ            string
+	n              int // number of features read
+	started        bool
+	inFeatures     bool
+	done           bool
+	err            error
+}
+
+// NewFeatureCollectionDecoder returns a new FeatureCollectionDecoder that
+// reads from r. Options, for example WithRawProperties, apply to each
+// feature.
+func NewFeatureCollectionDecoder(r io.Reader, opts ...Option) *FeatureCollectionDecoder {
+	return &FeatureCollectionDecoder{
+		dec:     json.NewDecoder(r),
+		options: newOptions(opts),
+	}
+}
+
+// BBox returns the bounding box of the FeatureCollection, if it has been read.
+// The bounding box is read before the first feature is returned if it
+// precedes the features array, and after the last feature otherwise.
+func (d *FeatureCollectionDecoder) BBox() *geom.Bounds {
+	return d.bbox
+}
+
+// ForeignMembers returns the foreign members of the FeatureCollection that
+// have been read, which, like its bounding box, may precede or follow the
+// features array.
+func (d *FeatureCollectionDecoder) ForeignMembers() map[string]json.RawMessage {
+	return d.foreignMembers
+}
+
+// Decode returns the next feature. It returns io.EOF after the last feature.
+// Errors are sticky.
+func (d *FeatureCollectionDecoder) Decode() (*Feature, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	f, err := d.decode()
+	if err != nil {
+		d.err = err
+		return nil, err
+	}
+	return f, nil
+}
+
+func (d *FeatureCollectionDecoder) decode() (*Feature, error) {
+	if d.done {
+		return nil, io.EOF
+	}
+	if !d.inFeatures {
+		if err := d.readMembers(); err != nil {
+			return nil, err
+		}
+		if d.done {
+			return nil, io.EOF
+		}
+	}
+	for {
+		if !d.dec.More() {
+			// Consume the end of the features array and the remaining members.
+			if err := d.expectDelim(']'); err != nil {
+				return nil, err
+			}
+			d.inFeatures = false
+			if err := d.readMembers(); err != nil {
+				return nil, err
+			}
+			if !d.done {
+				return nil, errDuplicateFeatures
+			}
+			return nil, io.EOF
+		}
+		var data json.RawMessage
+		if err := d.dec.Decode(&data); err != nil {
+			return nil, err
+		}
+		f := &Feature{}
+		ok, err := f.unmarshalJSON(data, d.options.featureFilter, d.options)
+		if err != nil {
+			return nil, atPointer(err, "/features/"+strconv.Itoa(d.n))
+		}
+		d.n++
+		if ok {
+			return f, nil
+		}
+	}
+}
+
+// readMembers reads members of the FeatureCollection object until it reaches
+// the start of the features array or the end of the object.
+func (d *FeatureCollectionDecoder) readMembers() error {
+	if !d.started {
+		if err := d.expectDelim('{'); err != nil {
+			return err
+		}
+		d.started = true
+	}
+	for d.dec.More() {
+		tok, err := d.dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case "type":
+			if err := d.dec.Decode(&d.typ); err != nil {
+				return err
+			}
+			if d.typ != "FeatureCollection" {
+				return ErrUnsupportedType(d.typ)
+			}
+		case "bbox":
+			var bbox []float64
+			if err := d.dec.Decode(&bbox); err != nil {
+				return err
+			}
+			if bbox != nil {
+				if d.bbox, err = decodeBBox(bbox); err != nil {
+					return atPointer(err, "/bbox")
+				}
+			}
+		case "features":
+			if err := d.expectDelim('['); err != nil {
+				return err
+			}
+			d.inFeatures = true
+			return nil
+		case "crs":
+			if d.options.strict {
+				return ErrCRS
+			}
+			fallthrough
+		default:
+			var value json.RawMessage
+			if err := d.dec.Decode(&value); err != nil {
+				return err
+			}
+			name, ok := tok.(string)
+			if !ok || featureCollectionMembers[name] {
+				break
+			}
+			if d.foreignMembers == nil {
+				d.foreignMembers = make(map[string]json.RawMessage)
+			}
+			d.foreignMembers[name] = value
+		}
+	}
+	if err := d.expectDelim('}'); err != nil {
+		return err
+	}
+	if d.typ != "FeatureCollection" {
+		return ErrUnsupportedType(d.typ)
+	}
+	d.done = true
+	return nil
+}
+
+func (d *FeatureCollectionDecoder) expectDelim(delim json.Delim) error {
+	tok, err := d.dec.Token()
+	switch {
+	case err == io.EOF:
+		return io.ErrUnexpectedEOF
+	case err != nil:
+		return err
+	case tok != delim:
+		return ErrUnexpectedToken{Token: tok, Want: delim.String()}
+	}
+	return nil
+}
+
+// A FeatureCollectionEncoder encodes the features of a FeatureCollection one
+// at a time to an io.Writer. Close must be called to finish the
+// FeatureCollection.
+type FeatureCollectionEncoder struct {
+	w              io.Writer
+	options        options
+	bbox           *geom.Bounds
+	bounds         *geom.Bounds
+	foreignMembers map[string]json.RawMessage
+	n              int
+	closed         bool
+}
+
+// NewFeatureCollectionEncoder returns a new FeatureCollectionEncoder that
+// writes to w. With WithBBox, the bounding box of each feature is included
+// and the bounding box of the FeatureCollection is computed from them.
+func NewFeatureCollectionEncoder(w io.Writer, opts ...Option) *FeatureCollectionEncoder {
+	return &FeatureCollectionEncoder{
+		w:       w,
+		options: newOptions(opts),
+	}
+}
+
+// SetBBox sets the bounding box of the FeatureCollection, which is written by
+// Close, so it can be computed while features are encoded. It takes
+// precedence over the bounding box computed with WithBBox.
+func (e *FeatureCollectionEncoder) SetBBox(bbox *geom.Bounds) {
+	e.bbox = bbox
+}
+
+// SetForeignMembers sets the foreign members of the FeatureCollection, which
+// are written by Close.
+func (e *FeatureCollectionEncoder) SetForeignMembers(foreignMembers map[string]json.RawMessage) {
+	e.foreignMembers = foreignMembers
+}
+
+// Encode writes f.
+func (e *FeatureCollectionEncoder) Encode(f *Feature) error {
+	if e.closed {
+		return errEncoderClosed
+	}
+	data, err := f.marshalJSON(e.options)
+	if err != nil {
+		return err
+	}
+	if e.options.bbox {
+		e.bounds = extendBounds(e.bounds, f)
+	}
+	prefix := ","
+	if e.n == 0 {
+		prefix = `{"type":"FeatureCollection","features":[`
+	}
+	if _, err := io.WriteString(e.w, prefix); err != nil {
+		return err
+	}
+	if _, err := e.w.Write(data); err != nil {
+		return err
+	}
+	e.n++
+	return nil
+}
+
+// Close finishes the FeatureCollection. It does not close the underlying
+// io.Writer.
+func (e *FeatureCollectionEncoder) Close() error {
+	if e.closed {
+		return errEncoderClosed
+	}
+	e.closed = true
+	suffix := "]"
+	if e.n == 0 {
+		suffix = `{"type":"FeatureCollection","features":[]`
+	}
+	var bbox []float64
+	var err error
+	if e.bbox != nil {
+		bbox, err = encodeBBox(e.bbox)
+	} else if e.options.bbox {
+		bbox, err = boundsBBox(e.bounds)
+	}
+	if err != nil {
+		return err
+	}
+	if bbox != nil {
+		e.options.roundFloats(bbox)
+		data, err := json.Marshal(bbox)
+		if err != nil {
+			return err
+		}
+		suffix += `,"bbox":` + string(data)
+	}
+	data, err := appendForeignMembers([]byte(suffix+"}"), e.foreignMembers, featureCollectionMembers)
+	if err != nil {
+		return err
+	}
+	_, err = e.w.Write(data)
+	return err
+}
@@ -0,0 +1,187 @@
+package geojson
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+)
+
+func decodeAllFeatures(d *FeatureCollectionDecoder) ([]*Feature, error) {
+	var features []*Feature
+	for {
+		f, err := d.Decode()
+		if err == io.EOF {
+			return features, nil
+		}
+		if err != nil {
+			return features, err
+		}
+		features = append(features, f)
+	}
+}
+
+func TestFeatureCollectionDecoder(t *testing.T) {
+	features := `[` +
+		`{"type":"Feature","id":"a","geometry":{"type":"Point","coordinates":[1,2]},"properties":{"name":"a"}},` +
+		`{"type":"Feature","geometry":null,"properties":null}` +
+		`]`
+	want := []*Feature{
+		{
+			ID:         "a",
+			Geometry:   geom.NewPointFlat(geom.XY, []float64{1, 2}),
+			Properties: map[string]interface{}{"name": "a"},
+		},
+		{},
+	}
+	bbox := geom.NewBounds(geom.XY).Set(1, 2, 1, 2)
+	for _, tc := range []struct {
+		name     string
+		s        string
+		want     []*Feature
+		wantBBox *geom.Bounds
+	}{
+		{
+			name: "type_first",
+			s:    `{"type":"FeatureCollection","bbox":[1,2,1,2],"features":` + features + `}`,
+			want: want,
+		},
+		{
+			name: "type_last",
+			s:    `{"features":` + features + `,"name":{"nested":[1,2]},"bbox":[1,2,1,2],"type":"FeatureCollection"}`,
+			want: want,
+		},
+		{
+			name: "empty",
+			s:    ` { "type" : "FeatureCollection" , "features" : [ ] } `,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			d := NewFeatureCollectionDecoder(strings.NewReader(tc.s))
+			got, err := decodeAllFeatures(d)
+			if err != nil || !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %v, %v, want %v, <nil>", got, err, tc.want)
+			}
+			if tc.want != nil && !reflect.DeepEqual(d.BBox(), bbox) {
+				t.Errorf("BBox() == %v, want %v", d.BBox(), bbox)
+			}
+			if _, err := d.Decode(); err != io.EOF {
+				t.Errorf("Decode() after end == _, %v, want _, %v", err, io.EOF)
+			}
+		})
+	}
+}
+
+func TestFeatureCollectionDecoderErrors(t *testing.T) {
+	feature := `{"type":"Feature","geometry":null,"properties":null}`
+	for _, tc := range []struct {
+		name      string
+		s         string
+		wantCount int
+		wantErr   error
+		anyErr    bool
+	}{
+		{
+			name:    "not_an_object",
+			s:       `[]`,
+			wantErr: ErrUnexpectedToken{Token: json.Delim('['), Want: "{"},
+		},
+		{
+			name:    "type_first",
+			s:       `{"type":"Feature","features":[` + feature + `]}`,
+			wantErr: ErrUnsupportedType("Feature"),
+		},
+		{
+			name:      "type_last",
+			s:         `{"features":[` + feature + `],"type":"Feature"}`,
+			wantCount: 1,
+			wantErr:   ErrUnsupportedType("Feature"),
+		},
+		{
+			name:    "missing_type",
+			s:       `{"features":[]}`,
+			wantErr: ErrUnsupportedType(""),
+		},
+		{
+			name:      "duplicate_features",
+			s:         `{"type":"FeatureCollection","features":[` + feature + `],"features":[]}`,
+			wantCount: 1,
+			wantErr:   errDuplicateFeatures,
+		},
+		{
+			name:    "feature_type",
+			s:       `{"type":"FeatureCollection","features":[{"type":"Point","coordinates":[1,2]}]}`,
+			wantErr: ErrUnsupportedType("Point"),
+		},
+		{
+			name:      "truncated",
+			s:         `{"type":"FeatureCollection","features":[` + feature,
+			wantCount: 1,
+			anyErr:    true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			d := NewFeatureCollectionDecoder(strings.NewReader(tc.s))
+			got, err := decodeAllFeatures(d)
+			if tc.anyErr && err != nil {
+				tc.wantErr = err
+			}
+			if len(got) != tc.wantCount || !errors.Is(err, tc.wantErr) {
+				t.Errorf("got %d features, %v, want %d features, %v", len(got), err, tc.wantCount, tc.wantErr)
+			}
+			if _, err2 := d.Decode(); err2 != err {
+				t.Errorf("Decode() after error == _, %v, want _, %v", err2, err)
+			}
+		})
+	}
+}
+
+func TestFeatureCollectionEncoder(t *testing.T) {
+	fc := &FeatureCollection{
+		BBox: geom.NewBounds(geom.XY).Set(1, 2, 3, 4),
+		Features: []*Feature{
+			{ID: "a", Geometry: geom.NewPointFlat(geom.XY, []float64{1, 2})},
+			{ID: "b", Geometry: geom.NewPointFlat(geom.XY, []float64{3, 4}), Properties: map[string]interface{}{"name": "b"}},
+		},
+	}
+	for _, tc := range []struct {
+		name string
+		fc   *FeatureCollection
+	}{
+		{name: "features", fc: fc},
+		{name: "empty", fc: &FeatureCollection{}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var b bytes.Buffer
+			e := NewFeatureCollectionEncoder(&b)
+			e.SetBBox(tc.fc.BBox)
+			for _, f := range tc.fc.Features {
+				if err := e.Encode(f); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := e.Close(); err != nil {
+				t.Fatal(err)
+			}
+			var got FeatureCollection
+			if err := json.Unmarshal(b.Bytes(), &got); err != nil {
+				t.Fatalf("json.Unmarshal(%s, ...) == %v", b.Bytes(), err)
+			}
+			want, err := json.Marshal(tc.fc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if gotData, err := json.Marshal(&got); err != nil || !bytes.Equal(gotData, want) {
+				t.Errorf("got %s, want %s", b.Bytes(), want)
+			}
+			if err := e.Encode(&Feature{}); err != errEncoderClosed {
+				t.Errorf("Encode(...) after Close() == %v, want %v", err, errEncoderClosed)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package geojson
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	geom "github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/internal/planar"
+)
+
+// ErrCRS is returned in strict mode when an object has a crs member, which
+// was removed by RFC 7946.
+var ErrCRS = errors.New("geojson: crs member not allowed by RFC 7946")
+
+// An ErrAxisOrder is returned in strict mode when a coordinate appears to be
+// in latitude, longitude order instead of the longitude, latitude order
+// required by RFC 7946.
+type ErrAxisOrder struct {
+	X float64
+	Y float64
+}
+
+func (e ErrAxisOrder) Error() string {
+	return fmt.Sprintf("geojson: coordinate (%g, %g) appears to be in latitude, longitude order", e.X, e.Y)
+}
+
+// A crsProbe detects a crs member.
+type crsProbe struct {
+	CRS json.RawMessage `json:"crs"`
+}
+
+// checkCRS returns ErrCRS if data, a JSON object, has a crs member.
+func checkCRS(data []byte) error {
+	var probe crsProbe
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return err
+	}
+	if probe.CRS != nil {
+		return ErrCRS
+	}
+	return nil
+}
+
+// checkStrict returns an error if g does not follow RFC 7946: every
+// coordinate must be a WGS84 longitude and latitude, in that order, and
+// polygons must follow the right-hand rule.
+func checkStrict(g geom.T) error {
+	switch g := g.(type) {
+	case nil:
+		return nil
+	case *geom.Polygon:
+		return checkStrictPolygon(g, 0)
+	case *geom.MultiPolygon:
+		for i, n := 0, g.NumPolygons(); i < n; i++ {
+			if err := checkStrictPolygon(g.Polygon(i), i); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *geom.GeometryCollection:
+		for _, member := range g.Geoms() {
+			if err := checkStrict(member); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return checkStrictCoords(g.FlatCoords(), g.Stride())
+	}
+}
+
+func checkStrictCoords(flatCoords []float64, stride int) error {
+	for i := 0; i+1 < len(flatCoords); i += stride {
+		x, y := flatCoords[i], flatCoords[i+1]
+		switch {
+		case -180 <= x && x <= 180 && -90 <= y && y <= 90:
+		case -90 <= x && x <= 90 && -180 <= y && y <= 180:
+			return ErrAxisOrder{X: x, Y: y}
+		default:
+			return ErrCoordOutOfRange{X: x, Y: y}
+		}
+	}
+	return nil
+}
+
+func checkStrictPolygon(p *geom.Polygon, index int) error {
+	stride := p.Stride()
+	for i, n := 0, p.NumLinearRings(); i < n; i++ {
+		ring := p.LinearRing(i).FlatCoords()
+		if err := checkStrictCoords(ring, stride); err != nil {
+			return err
+		}
+		if area := planar.SignedArea(ring, stride); area != 0 && (area > 0) != (i == 0) {
+			return ErrWinding{Polygon: index, Ring: i}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package geojson
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+)
+
+func TestStrictUnmarshal(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		s    string
+		err  error
+	}{
+		{
+			name: "valid",
+			s:    `{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,0]]]}`,
+		},
+		{
+			name: "crs",
+			s:    `{"type":"Point","coordinates":[1,2],"crs":{"type":"name","properties":{"name":"EPSG:4326"}}}`,
+			err:  ErrCRS,
+		},
+		{
+			name: "axis_order",
+			s:    `{"type":"Point","coordinates":[51.5,-120]}`,
+			err:  ErrAxisOrder{X: 51.5, Y: -120},
+		},
+		{
+			name: "out_of_range",
+			s:    `{"type":"LineString","coordinates":[[0,0],[200,0]]}`,
+			err:  ErrCoordOutOfRange{X: 200, Y: 0},
+		},
+		{
+			name: "exterior_winding",
+			s:    `{"type":"Polygon","coordinates":[[[0,0],[10,10],[10,0],[0,0]]]}`,
+			err:  ErrWinding{Polygon: 0, Ring: 0},
+		},
+		{
+			name: "hole_winding",
+			s:    `{"type":"MultiPolygon","coordinates":[[[[0,0],[1,0],[0,1],[0,0]]],[[[0,0],[10,0],[10,10],[0,0]],[[6,2],[8,2],[8,4],[6,2]]]]}`,
+			err:  ErrWinding{Polygon: 1, Ring: 1},
+		},
+		{
+			name: "geometry_collection",
+			s:    `{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[1,2]},{"type":"Point","coordinates":[1,-91]}]}`,
+			err:  ErrAxisOrder{X: 1, Y: -91},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var g geom.T
+			if err := Unmarshal([]byte(tc.s), &g, WithStrict(true)); !errors.Is(err, tc.err) {
+				t.Errorf("Unmarshal(%q, _, WithStrict(true)) == %v, want %v", tc.s, err, tc.err)
+			}
+			if err := Unmarshal([]byte(tc.s), &g); err != nil {
+				t.Errorf("Unmarshal(%q, _) == %v, want <nil>", tc.s, err)
+			}
+		})
+	}
+}
+
+func TestStrictFeatures(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		s    string
+		err  error
+	}{
+		{
+			name: "valid",
+			s:    `{"type":"FeatureCollection","features":[{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"properties":null}]}`,
+		},
+		{
+			name: "feature_collection_crs",
+			s:    `{"type":"FeatureCollection","crs":{"type":"name","properties":{"name":"EPSG:4326"}},"features":[]}`,
+			err:  ErrCRS,
+		},
+		{
+			name: "feature_crs",
+			s:    `{"type":"FeatureCollection","features":[{"type":"Feature","crs":null,"geometry":null,"properties":null}]}`,
+			err:  ErrCRS,
+		},
+		{
+			name: "geometry_crs",
+			s:    `{"type":"FeatureCollection","features":[{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2],"crs":{}},"properties":null}]}`,
+			err:  ErrCRS,
+		},
+		{
+			name: "out_of_range",
+			s:    `{"type":"FeatureCollection","features":[{"type":"Feature","geometry":{"type":"Point","coordinates":[181,2]},"properties":null}]}`,
+			err:  ErrCoordOutOfRange{X: 181, Y: 2},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := UnmarshalFeatureCollection([]byte(tc.s), WithStrict(true)); !errors.Is(err, tc.err) {
+				t.Errorf("UnmarshalFeatureCollection(%q, WithStrict(true)) == _, %v, want _, %v", tc.s, err, tc.err)
+			}
+			d := NewFeatureCollectionDecoder(strings.NewReader(tc.s), WithStrict(true))
+			var err error
+			for err == nil {
+				_, err = d.Decode()
+			}
+			if want := tc.err; want == nil && err != io.EOF || want != nil && !errors.Is(err, want) {
+				t.Errorf("FeatureCollectionDecoder.Decode() == _, %v, want _, %v", err, tc.err)
+			}
+		})
+	}
+}
+
+func TestStrictMarshal(t *testing.T) {
+	cw := geom.NewPolygonFlat(geom.XY, []float64{0, 0, 10, 10, 10, 0, 0, 0}, []int{8})
+	if _, err := Marshal(cw); err != nil {
+		t.Errorf("Marshal(cw) == _, %v, want _, <nil>", err)
+	}
+	if _, err := Marshal(cw, WithStrict(true)); err != (ErrWinding{}) {
+		t.Errorf("Marshal(cw, WithStrict(true)) == _, %v, want _, %v", err, ErrWinding{})
+	}
+	f := &Feature{Geometry: geom.NewPointFlat(geom.XY, []float64{0, 100})}
+	if _, err := MarshalFeature(f, WithStrict(true)); err != (ErrAxisOrder{X: 0, Y: 100}) {
+		t.Errorf("MarshalFeature(f, WithStrict(true)) == _, %v, want _, %v", err, ErrAxisOrder{X: 0, Y: 100})
+	}
+}
@@ -0,0 +1,213 @@
+package geojson
+
+import (
+	"fmt"
+	"math"
+
+	geom "github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/internal/planar"
+)
+
+// An ErrInvalidGeometry is returned when a geometry is structurally invalid.
+type ErrInvalidGeometry string
+
+func (e ErrInvalidGeometry) Error() string {
+	return "geojson: invalid geometry: " + string(e)
+}
+
+// An ErrWinding is returned when a polygon ring does not follow the RFC 7946
+// right-hand rule, which requires exterior rings to be counterclockwise and
+// holes to be clockwise.
+type ErrWinding struct {
+	Polygon int // index of the polygon in its MultiPolygon, or zero
+	Ring    int // index of the ring in its polygon
+}
+
+func (e ErrWinding) Error() string {
+	return fmt.Sprintf("geojson: ring %d of polygon %d has the wrong winding order", e.Ring, e.Polygon)
+}
+
+// An ErrCoordOutOfRange is returned when a coordinate is outside the range of
+// longitudes and latitudes.
+type ErrCoordOutOfRange struct {
+	X float64
+	Y float64
+}
+
+func (e ErrCoordOutOfRange) Error() string {
+	return fmt.Sprintf("geojson: coordinate (%g, %g) out of range", e.X, e.Y)
+}
+
+// An ErrSRIDMismatch is returned when a geometry's SRID differs from the
+// expected SRID.
+type ErrSRIDMismatch struct {
+	Got  int
+	Want int
+}
+
+func (e ErrSRIDMismatch) Error() string {
+	return fmt.Sprintf("geojson: SRID mismatch, got %d, want %d", e.Got, e.Want)
+}
+
+// A FeatureReport lists the problems found with a single feature.
+type FeatureReport struct {
+	Index int // index of the feature in its FeatureCollection
+	ID    string
+	Errs  []error
+}
+
+// A ValidateOption configures Validate.
+type ValidateOption func(*validateOptions)
+
+type validateOptions struct {
+	srid         int
+	hasSRID      bool
+	checkWinding bool
+	checkRanges  bool
+}
+
+// ValidateSRID requires every geometry to have SRID srid. By default, every
+// geometry must have the same SRID as the first feature's geometry.
+func ValidateSRID(srid int) ValidateOption {
+	return func(o *validateOptions) {
+		o.srid = srid
+		o.hasSRID = true
+	}
+}
+
+// ValidateWinding sets whether polygon winding orders are checked against the
+// RFC 7946 right-hand rule. The default is true.
+func ValidateWinding(check bool) ValidateOption {
+	return func(o *validateOptions) {
+		o.checkWinding = check
+	}
+}
+
+// ValidateRanges sets whether coordinates are checked to be valid longitudes and
+// latitudes. The default is true.
+func ValidateRanges(check bool) ValidateOption {
+	return func(o *validateOptions) {
+		o.checkRanges = check
+	}
+}
+
+// Validate checks every feature in fc and returns a report for each feature
+// with problems, in order, or nil if all features are valid. Features without
+// geometries are valid.
+func Validate(fc *FeatureCollection, opts ...ValidateOption) []FeatureReport {
+	o := validateOptions{
+		checkWinding: true,
+		checkRanges:  true,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	var reports []FeatureReport
+	for i, f := range fc.Features {
+		if f.Geometry == nil {
+			continue
+		}
+		if !o.hasSRID {
+			o.srid, o.hasSRID = f.Geometry.SRID(), true
+		}
+		v := validator{options: o}
+		if srid := f.Geometry.SRID(); srid != o.srid {
+			v.errs = append(v.errs, ErrSRIDMismatch{Got: srid, Want: o.srid})
+		}
+		v.validate(f.Geometry)
+		if len(v.errs) > 0 {
+			reports = append(reports, FeatureReport{
+				Index: i,
+				ID:    f.ID,
+				Errs:  v.errs,
+			})
+		}
+	}
+	return reports
+}
+
+// A validator accumulates the problems with a single geometry.
+type validator struct {
+	options validateOptions
+	errs    []error
+	ranges  bool // whether an out of range coordinate has been reported
+}
+
+func (v *validator) validate(g geom.T) {
+	switch g := g.(type) {
+	case *geom.Point:
+		if !g.Empty() {
+			v.validateCoords(g.FlatCoords(), g.Stride())
+		}
+	case *geom.LineString:
+		v.validateLineString(g.FlatCoords(), g.Stride(), "line string")
+	case *geom.Polygon:
+		v.validatePolygon(g, 0)
+	case *geom.MultiPoint:
+		v.validateCoords(g.FlatCoords(), g.Stride())
+	case *geom.MultiLineString:
+		for i, n := 0, g.NumLineStrings(); i < n; i++ {
+			v.validateLineString(g.LineString(i).FlatCoords(), g.Stride(), fmt.Sprintf("line string %d", i))
+		}
+	case *geom.MultiPolygon:
+		for i, n := 0, g.NumPolygons(); i < n; i++ {
+			v.validatePolygon(g.Polygon(i), i)
+		}
+	case *geom.GeometryCollection:
+		for _, member := range g.Geoms() {
+			v.validate(member)
+		}
+	default:
+		v.errs = append(v.errs, geom.ErrUnsupportedType{Value: g})
+	}
+}
+
+func (v *validator) validateCoords(flatCoords []float64, stride int) {
+	for i := 0; i < len(flatCoords); i += stride {
+		x, y := flatCoords[i], flatCoords[i+1]
+		if math.IsNaN(x) || math.IsInf(x, 0) || math.IsNaN(y) || math.IsInf(y, 0) {
+			v.errs = append(v.errs, ErrInvalidGeometry("non-finite coordinate"))
+			return
+		}
+		if v.options.checkRanges && !v.ranges && (x < -180 || x > 180 || y < -90 || y > 90) {
+			v.errs = append(v.errs, ErrCoordOutOfRange{X: x, Y: y})
+			v.ranges = true
+		}
+	}
+}
+
+func (v *validator) validateLineString(flatCoords []float64, stride int, name string) {
+	if n := len(flatCoords) / stride; n == 1 {
+		v.errs = append(v.errs, ErrInvalidGeometry(name+" has only one position"))
+	}
+	v.validateCoords(flatCoords, stride)
+}
+
+func (v *validator) validatePolygon(p *geom.Polygon, index int) {
+	stride := p.Stride()
+	valid := true
+	for i, n := 0, p.NumLinearRings(); i < n; i++ {
+		ring := p.LinearRing(i).FlatCoords()
+		v.validateCoords(ring, stride)
+		switch {
+		case len(ring) < 4*stride:
+			v.errs = append(v.errs, ErrInvalidGeometry(fmt.Sprintf("ring %d of polygon %d has fewer than four positions", i, index)))
+			valid = false
+			continue
+		case ring[0] != ring[len(ring)-stride] || ring[1] != ring[len(ring)-stride+1]:
+			v.errs = append(v.errs, ErrInvalidGeometry(fmt.Sprintf("ring %d of polygon %d is not closed", i, index)))
+			valid = false
+			continue
+		}
+		if v.options.checkWinding {
+			if area := planar.SignedArea(ring, stride); area != 0 && (area > 0) != (i == 0) {
+				v.errs = append(v.errs, ErrWinding{Polygon: index, Ring: i})
+			}
+		}
+	}
+	if valid {
+		if err := p.ValidateHoles(); err != nil {
+			v.errs = append(v.errs, err)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package geojson
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+)
+
+func TestValidate(t *testing.T) {
+	ccw := []float64{0, 0, 10, 0, 10, 10, 0, 0}
+	cw := []float64{0, 0, 10, 10, 10, 0, 0, 0}
+	hole := []float64{6, 2, 8, 4, 8, 2, 6, 2}
+	for _, tc := range []struct {
+		name string
+		fc   *FeatureCollection
+		opts []ValidateOption
+		want []FeatureReport
+	}{
+		{
+			name: "valid",
+			fc: &FeatureCollection{
+				Features: []*Feature{
+					{Geometry: geom.NewPointFlat(geom.XY, []float64{1, 2})},
+					{},
+					{Geometry: geom.NewPolygonFlat(geom.XY, append(append([]float64{}, ccw...), hole...), []int{8, 16})},
+				},
+			},
+		},
+		{
+			name: "srid_mismatch",
+			fc: &FeatureCollection{
+				Features: []*Feature{
+					{ID: "a", Geometry: geom.NewPointFlat(geom.XY, []float64{1, 2}).SetSRID(4326)},
+					{ID: "b", Geometry: geom.NewPointFlat(geom.XY, []float64{1, 2}).SetSRID(3857)},
+				},
+			},
+			want: []FeatureReport{
+				{Index: 1, ID: "b", Errs: []error{ErrSRIDMismatch{Got: 3857, Want: 4326}}},
+			},
+		},
+		{
+			name: "explicit_srid",
+			fc: &FeatureCollection{
+				Features: []*Feature{
+					{Geometry: geom.NewPointFlat(geom.XY, []float64{1, 2})},
+				},
+			},
+			opts: []ValidateOption{ValidateSRID(4326)},
+			want: []FeatureReport{
+				{Index: 0, Errs: []error{ErrSRIDMismatch{Got: 0, Want: 4326}}},
+			},
+		},
+		{
+			name: "winding",
+			fc: &FeatureCollection{
+				Features: []*Feature{
+					{Geometry: geom.NewMultiPolygonFlat(geom.XY, append(append([]float64{}, ccw...), cw...), [][]int{{8}, {16}})},
+				},
+			},
+			want: []FeatureReport{
+				{Index: 0, Errs: []error{ErrWinding{Polygon: 1, Ring: 0}}},
+			},
+		},
+		{
+			name: "winding_ignored",
+			fc: &FeatureCollection{
+				Features: []*Feature{
+					{Geometry: geom.NewPolygonFlat(geom.XY, cw, []int{8})},
+				},
+			},
+			opts: []ValidateOption{ValidateWinding(false)},
+		},
+		{
+			name: "out_of_range",
+			fc: &FeatureCollection{
+				Features: []*Feature{
+					{Geometry: geom.NewLineStringFlat(geom.XY, []float64{0, 0, 200, 0, 0, 100})},
+				},
+			},
+			want: []FeatureReport{
+				{Index: 0, Errs: []error{ErrCoordOutOfRange{X: 200, Y: 0}}},
+			},
+		},
+		{
+			name: "out_of_range_ignored",
+			fc: &FeatureCollection{
+				Features: []*Feature{
+					{Geometry: geom.NewLineStringFlat(geom.XY, []float64{0, 0, 200, 0})},
+				},
+			},
+			opts: []ValidateOption{ValidateRanges(false)},
+		},
+		{
+			name: "invalid_geometries",
+			fc: &FeatureCollection{
+				Features: []*Feature{
+					{Geometry: geom.NewLineStringFlat(geom.XY, []float64{0, 0})},
+					{Geometry: geom.NewPolygonFlat(geom.XY, []float64{0, 0, 1, 0, 1, 1, 0, 1}, []int{8})},
+					{Geometry: geom.NewPolygonFlat(geom.XY, append(append([]float64{}, ccw...), 20, 20, 20, 30, 30, 30, 20, 20), []int{8, 16})},
+					{Geometry: geom.NewGeometryCollection().MustPush(geom.NewPolygonFlat(geom.XY, []float64{0, 0, 1, 0, 0, 0}, []int{6}))},
+				},
+			},
+			want: []FeatureReport{
+				{Index: 0, Errs: []error{ErrInvalidGeometry("line string has only one position")}},
+				{Index: 1, Errs: []error{ErrInvalidGeometry("ring 0 of polygon 0 is not closed")}},
+				{Index: 2, Errs: []error{geom.ErrInvalidHole{Index: 1, Reason: "not inside exterior ring"}}},
+				{Index: 3, Errs: []error{ErrInvalidGeometry("ring 0 of polygon 0 has fewer than four positions")}},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Validate(tc.fc, tc.opts...); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Validate(...) == %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
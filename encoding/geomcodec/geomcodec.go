@@ -0,0 +1,195 @@
+// Package geomcodec decodes geometries in any of several encodings, detecting
+// the encoding from the data.
+//
+// Ingest services that receive geometries from arbitrary sources can use it
+// to accept WKT, WKB, EWKB, hex-encoded WKB or EWKB, GeoJSON, GeoPackage
+// binary, and TWKB without being told which is used. Detection looks only at
+// the first bytes of the data, so data that are detected as one encoding but
+// are invalid are not retried as another.
+package geomcodec
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/ewkb"
+	"github.com/twpayne/go-geom/encoding/geojson"
+	"github.com/twpayne/go-geom/encoding/gpkg"
+	"github.com/twpayne/go-geom/encoding/twkb"
+	"github.com/twpayne/go-geom/encoding/wkb"
+	"github.com/twpayne/go-geom/encoding/wkt"
+)
+
+// A Format is an encoding of geometries.
+type Format int
+
+// Formats.
+const (
+	Unknown Format = iota
+	WKT
+	WKB
+	EWKB
+	HexEWKB // hex-encoded WKB or EWKB
+	GeoJSON
+	GPB // GeoPackage binary
+	TWKB
+)
+
+func (f Format) String() string {
+	switch f {
+	case Unknown:
+		return "Unknown"
+	case WKT:
+		return "WKT"
+	case WKB:
+		return "WKB"
+	case EWKB:
+		return "EWKB"
+	case HexEWKB:
+		return "HexEWKB"
+	case GeoJSON:
+		return "GeoJSON"
+	case GPB:
+		return "GPB"
+	case TWKB:
+		return "TWKB"
+	default:
+		return fmt.Sprintf("Format(%d)", int(f))
+	}
+}
+
+// ErrUnknownFormat is returned when the encoding of data cannot be detected.
+var ErrUnknownFormat = errors.New("geomcodec: unknown format")
+
+// ewkbFlags are the EWKB Z, M, and SRID flags of the geometry type.
+const ewkbFlags = 0xe0000000
+
+// Detect returns the encoding of data, or Unknown.
+func Detect(data []byte) Format {
+	text := trimSpace(data)
+	switch {
+	case len(data) >= 2 && data[0] == 'G' && data[1] == 'P':
+		return GPB
+	case len(text) > 0 && text[0] == '{':
+		return GeoJSON
+	case isHexWKB(text):
+		return HexEWKB
+	case len(text) > 0 && isLetter(text[0]):
+		return WKT
+	}
+	if t, ok := wkbType(data); ok {
+		if t&ewkbFlags != 0 {
+			return EWKB
+		}
+		return WKB
+	}
+	if len(data) >= 2 {
+		if typeID := data[0] & 0xf; 1 <= typeID && typeID <= 7 {
+			return TWKB
+		}
+	}
+	return Unknown
+}
+
+// Decode decodes data in the encoding detected by Detect and returns the
+// geometry and the encoding.
+func Decode(data []byte) (geom.T, Format, error) {
+	format := Detect(data)
+	var g geom.T
+	var err error
+	switch format {
+	case WKT:
+		g, err = wkt.Unmarshal(string(data))
+	case WKB:
+		g, err = wkb.Unmarshal(data)
+	case EWKB:
+		g, err = ewkb.Unmarshal(data)
+	case HexEWKB:
+		var wkbData []byte
+		if wkbData, err = hex.DecodeString(string(trimSpace(data))); err == nil {
+			g, err = unmarshalWKB(wkbData)
+		}
+	case GeoJSON:
+		err = geojson.Unmarshal(data, &g)
+	case GPB:
+		g, err = gpkg.Unmarshal(data)
+	case TWKB:
+		g, err = twkb.Unmarshal(data)
+	default:
+		err = ErrUnknownFormat
+	}
+	if err != nil {
+		return nil, format, err
+	}
+	return g, format, nil
+}
+
+// unmarshalWKB decodes the WKB or EWKB in data.
+func unmarshalWKB(data []byte) (geom.T, error) {
+	if t, ok := wkbType(data); ok && t&ewkbFlags != 0 {
+		return ewkb.Unmarshal(data)
+	}
+	return wkb.Unmarshal(data)
+}
+
+// wkbType returns the geometry type of the WKB or EWKB in data and whether
+// data start with a valid byte order and geometry type.
+func wkbType(data []byte) (uint32, bool) {
+	if len(data) < 5 {
+		return 0, false
+	}
+	var t uint32
+	switch data[0] {
+	case 0:
+		t = binary.BigEndian.Uint32(data[1:5])
+	case 1:
+		t = binary.LittleEndian.Uint32(data[1:5])
+	default:
+		return 0, false
+	}
+	// Types are 1 to 7, optionally with EWKB flags or an ISO dimension
+	// offset of 1000, 2000, or 3000, but not both.
+	base := t &^ ewkbFlags
+	if t&ewkbFlags != 0 && base > 7 || base%1000 < 1 || base%1000 > 7 || base >= 4000 {
+		return 0, false
+	}
+	return t, true
+}
+
+// isHexWKB returns whether text is hex-encoded WKB or EWKB.
+func isHexWKB(text []byte) bool {
+	if len(text) < 10 || len(text)%2 != 0 {
+		return false
+	}
+	if text[0] != '0' || text[1] != '0' && text[1] != '1' {
+		return false
+	}
+	for _, c := range text {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+func isLetter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// trimSpace returns data without leading and trailing ASCII white space.
+func trimSpace(data []byte) []byte {
+	for len(data) > 0 && isSpace(data[0]) {
+		data = data[1:]
+	}
+	for len(data) > 0 && isSpace(data[len(data)-1]) {
+		data = data[:len(data)-1]
+	}
+	return data
+}
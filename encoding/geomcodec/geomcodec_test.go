@@ -0,0 +1,130 @@
+package geomcodec
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/ewkb"
+	"github.com/twpayne/go-geom/encoding/ewkbhex"
+	"github.com/twpayne/go-geom/encoding/geojson"
+	"github.com/twpayne/go-geom/encoding/gpkg"
+	"github.com/twpayne/go-geom/encoding/twkb"
+	"github.com/twpayne/go-geom/encoding/wkb"
+	"github.com/twpayne/go-geom/encoding/wkt"
+)
+
+func TestDecode(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		g          geom.T
+		encode     func(geom.T) ([]byte, error)
+		wantFormat Format
+	}{
+		{
+			name: "wkt",
+			g:    geom.NewLineStringFlat(geom.XY, []float64{1, 2, 3, 4}),
+			encode: func(g geom.T) ([]byte, error) {
+				s, err := wkt.Marshal(g)
+				return []byte(" " + s + "\n"), err
+			},
+			wantFormat: WKT,
+		},
+		{
+			name: "wkb_ndr",
+			g:    geom.NewPointFlat(geom.XYZ, []float64{1, 2, 3}),
+			encode: func(g geom.T) ([]byte, error) {
+				return wkb.Marshal(g, binary.LittleEndian)
+			},
+			wantFormat: WKB,
+		},
+		{
+			name: "wkb_xdr",
+			g:    geom.NewPolygonFlat(geom.XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}, []int{8}),
+			encode: func(g geom.T) ([]byte, error) {
+				return wkb.Marshal(g, binary.BigEndian)
+			},
+			wantFormat: WKB,
+		},
+		{
+			name: "ewkb",
+			g:    geom.NewPointFlat(geom.XY, []float64{1, 2}).SetSRID(4326),
+			encode: func(g geom.T) ([]byte, error) {
+				return ewkb.Marshal(g, binary.LittleEndian)
+			},
+			wantFormat: EWKB,
+		},
+		{
+			name: "hex_ewkb",
+			g:    geom.NewPointFlat(geom.XYM, []float64{1, 2, 3}).SetSRID(4326),
+			encode: func(g geom.T) ([]byte, error) {
+				s, err := ewkbhex.Encode(g, binary.LittleEndian)
+				return []byte(s), err
+			},
+			wantFormat: HexEWKB,
+		},
+		{
+			name: "geojson",
+			g:    geom.NewMultiPointFlat(geom.XY, []float64{1, 2, 3, 4}),
+			encode: func(g geom.T) ([]byte, error) {
+				return geojson.Marshal(g)
+			},
+			wantFormat: GeoJSON,
+		},
+		{
+			name: "gpb",
+			g:    geom.NewPointFlat(geom.XY, []float64{1, 2}).SetSRID(4326),
+			encode: func(g geom.T) ([]byte, error) {
+				return gpkg.Marshal(g)
+			},
+			wantFormat: GPB,
+		},
+		{
+			name: "twkb",
+			g:    geom.NewLineStringFlat(geom.XY, []float64{1, 2, 3, 4}),
+			encode: func(g geom.T) ([]byte, error) {
+				return twkb.Marshal(g)
+			},
+			wantFormat: TWKB,
+		},
+		{
+			name: "twkb_point",
+			g:    geom.NewPointFlat(geom.XY, []float64{1, 2}),
+			encode: func(g geom.T) ([]byte, error) {
+				return twkb.Marshal(g)
+			},
+			wantFormat: TWKB,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := tc.encode(tc.g)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := Detect(data); got != tc.wantFormat {
+				t.Errorf("Detect(%q) == %v, want %v", data, got, tc.wantFormat)
+			}
+			got, format, err := Decode(data)
+			if err != nil || format != tc.wantFormat || !reflect.DeepEqual(got, tc.g) {
+				t.Errorf("Decode(%q) == %v, %v, %v, want %v, %v, <nil>", data, got, format, err, tc.g, tc.wantFormat)
+			}
+		})
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	for _, data := range [][]byte{
+		nil,
+		[]byte("  "),
+		[]byte("[1, 2]"),
+		{0xff},
+	} {
+		if _, format, err := Decode(data); format != Unknown || err != ErrUnknownFormat {
+			t.Errorf("Decode(%q) == _, %v, %v, want _, %v, %v", data, format, err, Unknown, ErrUnknownFormat)
+		}
+	}
+	if _, format, err := Decode([]byte("POINT (1")); format != WKT || err == nil {
+		t.Errorf("Decode(\"POINT (1\") == _, %v, %v, want _, %v, <non-nil>", format, err, WKT)
+	}
+}
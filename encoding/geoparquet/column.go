@@ -0,0 +1,121 @@
+package geoparquet
+
+import (
+	"encoding/json"
+	"math"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/wkb"
+)
+
+// A WriteFunc writes a value to a Parquet BYTE_ARRAY column. A nil value is
+// a null. The value is only valid for the duration of the call.
+type WriteFunc func(value []byte) error
+
+// An Option configures a ColumnEncoder.
+type Option func(*ColumnEncoder)
+
+// WithCRS sets the PROJJSON CRS of the column. The default is OGC:CRS84.
+func WithCRS(crs json.RawMessage) Option {
+	return func(e *ColumnEncoder) {
+		e.crs = crs
+	}
+}
+
+// WithEdges sets the interpretation of the edges of the column's geometries,
+// EdgesPlanar or EdgesSpherical. The default is planar.
+func WithEdges(edges string) Option {
+	return func(e *ColumnEncoder) {
+		e.edges = edges
+	}
+}
+
+// A ColumnEncoder encodes geometries as WKB values of a GeoParquet geometry
+// column and collects the column's metadata.
+type ColumnEncoder struct {
+	write         WriteFunc
+	buf           []byte
+	crs           json.RawMessage
+	edges         string
+	geometryTypes map[string]struct{}
+	bounds        *geom.Bounds
+}
+
+// NewColumnEncoder returns a new ColumnEncoder that writes values with
+// write.
+func NewColumnEncoder(write WriteFunc, opts ...Option) *ColumnEncoder {
+	e := &ColumnEncoder{
+		write:         write,
+		geometryTypes: make(map[string]struct{}),
+		bounds:        geom.NewBounds(geom.XY),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Encode writes g, or a null if g is nil. Empty points are written with NaN
+// coordinates, as required by GeoParquet.
+func (e *ColumnEncoder) Encode(g geom.T) error {
+	if g == nil {
+		return e.write(nil)
+	}
+	geometryType, err := geometryTypeName(g)
+	if err != nil {
+		return err
+	}
+	b := g.Bounds()
+	if p, ok := g.(*geom.Point); ok && p.Empty() && p.Stride() > 0 {
+		// Empty points are encoded with NaN coordinates.
+		flatCoords := make([]float64, p.Stride())
+		for i := range flatCoords {
+			flatCoords[i] = math.NaN()
+		}
+		g = geom.NewPointFlat(p.Layout(), flatCoords)
+	}
+	buf, err := wkb.Append(e.buf[:0], g)
+	if err != nil {
+		return err
+	}
+	e.buf = buf
+	if err := e.write(buf); err != nil {
+		return err
+	}
+	e.geometryTypes[geometryType] = struct{}{}
+	if !b.IsEmpty() {
+		e.bounds.Set(
+			minFloat(e.bounds.Min(0), b.Min(0)), minFloat(e.bounds.Min(1), b.Min(1)),
+			maxFloat(e.bounds.Max(0), b.Max(0)), maxFloat(e.bounds.Max(1), b.Max(1)),
+		)
+	}
+	return nil
+}
+
+// ColumnMetadata returns the metadata of the geometries encoded so far.
+func (e *ColumnEncoder) ColumnMetadata() *ColumnMetadata {
+	c := &ColumnMetadata{
+		Encoding:      EncodingWKB,
+		GeometryTypes: sortedKeys(e.geometryTypes),
+		CRS:           e.crs,
+		Edges:         e.edges,
+	}
+	if !e.bounds.IsEmpty() {
+		c.BBox = []float64{e.bounds.Min(0), e.bounds.Min(1), e.bounds.Max(0), e.bounds.Max(1)}
+	}
+	return c
+}
+
+func minFloat(a, b float64) float64 {
+	if b < a {
+		return b
+	}
+	return a
+}
+
+func maxFloat(a, b float64) float64 {
+	if b > a {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,161 @@
+// Package geoparquet implements helpers for writing and reading GeoParquet
+// files with a Parquet library. See https://geoparquet.org.
+//
+// GeoParquet stores geometries as WKB in BYTE_ARRAY columns and describes
+// them with JSON metadata stored under the "geo" key of the Parquet file's
+// key/value metadata. The package does not depend on a Parquet library:
+// a ColumnEncoder encodes geometries for any column writer and collects the
+// column's metadata, and Metadata is marshaled and unmarshaled with
+// encoding/json. Values read from geometry columns are decoded with
+// wkb.Unmarshal.
+package geoparquet
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/twpayne/go-geom"
+)
+
+// MetadataKey is the key of the GeoParquet metadata in the key/value metadata
+// of a Parquet file.
+const MetadataKey = "geo"
+
+// Version is the version of the GeoParquet specification that is written.
+const Version = "1.1.0"
+
+// EncodingWKB is the encoding of geometry columns.
+const EncodingWKB = "WKB"
+
+// Orientations and edges.
+const (
+	OrientationCounterClockwise = "counterclockwise"
+	EdgesPlanar                 = "planar"
+	EdgesSpherical              = "spherical"
+)
+
+var (
+	errNoColumns           = errors.New("geoparquet: no columns")
+	errNoPrimaryColumn     = errors.New("geoparquet: no primary column")
+	errUnsupportedEncoding = errors.New("geoparquet: unsupported encoding")
+)
+
+// An ErrUnsupportedVersion is returned when parsing metadata with an
+// unsupported major version.
+type ErrUnsupportedVersion string
+
+func (e ErrUnsupportedVersion) Error() string {
+	return fmt.Sprintf("geoparquet: unsupported version %q", string(e))
+}
+
+// Metadata is the GeoParquet file metadata.
+type Metadata struct {
+	Version       string                     `json:"version"`
+	PrimaryColumn string                     `json:"primary_column"`
+	Columns       map[string]*ColumnMetadata `json:"columns"`
+}
+
+// ColumnMetadata is the metadata of a geometry column.
+type ColumnMetadata struct {
+	Encoding string `json:"encoding"`
+	// GeometryTypes are the types of the geometries in the column, for
+	// example Point or Polygon Z. An empty slice means that the types are
+	// unknown.
+	GeometryTypes []string `json:"geometry_types"`
+	// CRS is the PROJJSON coordinate reference system of the column. A nil
+	// CRS means OGC:CRS84, longitude and latitude on WGS84, and a JSON null
+	// means that the CRS is unknown.
+	CRS         json.RawMessage `json:"crs,omitempty"`
+	Orientation string          `json:"orientation,omitempty"`
+	Edges       string          `json:"edges,omitempty"`
+	// BBox is the bounding box of the column, as minimum X, minimum Y,
+	// maximum X, and maximum Y.
+	BBox  []float64 `json:"bbox,omitempty"`
+	Epoch float64   `json:"epoch,omitempty"`
+}
+
+// NewMetadata returns new Metadata with a single geometry column.
+func NewMetadata(primaryColumn string, column *ColumnMetadata) *Metadata {
+	return &Metadata{
+		Version:       Version,
+		PrimaryColumn: primaryColumn,
+		Columns: map[string]*ColumnMetadata{
+			primaryColumn: column,
+		},
+	}
+}
+
+// ParseMetadata parses and validates the GeoParquet metadata in data.
+func ParseMetadata(data []byte) (*Metadata, error) {
+	var m Metadata
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	if len(m.Version) < 2 || m.Version[:2] != "1." {
+		return nil, ErrUnsupportedVersion(m.Version)
+	}
+	if len(m.Columns) == 0 {
+		return nil, errNoColumns
+	}
+	if _, ok := m.Columns[m.PrimaryColumn]; !ok {
+		return nil, errNoPrimaryColumn
+	}
+	for _, column := range m.Columns {
+		if column == nil || column.Encoding != EncodingWKB {
+			return nil, errUnsupportedEncoding
+		}
+	}
+	return &m, nil
+}
+
+// Bounds returns the bounds of c's BBox, or nil if it has none.
+func (c *ColumnMetadata) Bounds() *geom.Bounds {
+	switch len(c.BBox) {
+	case 4:
+		return geom.NewBounds(geom.XY).Set(c.BBox...)
+	case 6:
+		return geom.NewBounds(geom.XYZ).Set(c.BBox...)
+	default:
+		return nil
+	}
+}
+
+// geometryTypeName returns the GeoParquet name of the type of g. M values
+// cannot be described, so the Z suffix is added only for layouts with Z.
+func geometryTypeName(g geom.T) (string, error) {
+	var name string
+	switch g.(type) {
+	case *geom.Point:
+		name = "Point"
+	case *geom.LineString:
+		name = "LineString"
+	case *geom.Polygon:
+		name = "Polygon"
+	case *geom.MultiPoint:
+		name = "MultiPoint"
+	case *geom.MultiLineString:
+		name = "MultiLineString"
+	case *geom.MultiPolygon:
+		name = "MultiPolygon"
+	case *geom.GeometryCollection:
+		name = "GeometryCollection"
+	default:
+		return "", geom.ErrUnsupportedType{Value: g}
+	}
+	if g.Layout().ZIndex() != -1 {
+		name += " Z"
+	}
+	return name, nil
+}
+
+// sortedKeys returns the keys of m in order.
+func sortedKeys(m map[string]struct{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
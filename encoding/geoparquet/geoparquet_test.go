@@ -0,0 +1,133 @@
+package geoparquet
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/wkb"
+)
+
+func TestColumnEncoder(t *testing.T) {
+	var values [][]byte
+	e := NewColumnEncoder(func(value []byte) error {
+		if value != nil {
+			value = append([]byte(nil), value...)
+		}
+		values = append(values, value)
+		return nil
+	}, WithEdges(EdgesSpherical))
+	gs := []geom.T{
+		geom.NewPointFlat(geom.XY, []float64{1, 2}),
+		nil,
+		geom.NewLineStringFlat(geom.XYZ, []float64{-3, 4, 5, 6, -7, 8}),
+		geom.NewPointEmpty(geom.XY),
+		geom.NewGeometryCollection().MustPush(geom.NewPointFlat(geom.XY, []float64{0, 10})),
+	}
+	for _, g := range gs {
+		if err := e.Encode(g); err != nil {
+			t.Fatalf("e.Encode(%v) == %v, want <nil>", g, err)
+		}
+	}
+	if len(values) != len(gs) {
+		t.Fatalf("len(values) == %d, want %d", len(values), len(gs))
+	}
+	for i, g := range gs {
+		if g == nil {
+			if values[i] != nil {
+				t.Errorf("values[%d] == %v, want <nil>", i, values[i])
+			}
+			continue
+		}
+		got, err := wkb.Unmarshal(values[i])
+		if p, ok := g.(*geom.Point); ok && p.Empty() {
+			if err != nil || !math.IsNaN(got.FlatCoords()[0]) || !math.IsNaN(got.FlatCoords()[1]) {
+				t.Errorf("wkb.Unmarshal(values[%d]) == %v, %v, want POINT(NaN NaN), <nil>", i, got, err)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, g) {
+			t.Errorf("wkb.Unmarshal(values[%d]) == %v, %v, want %v, <nil>", i, got, err, g)
+		}
+	}
+	want := &ColumnMetadata{
+		Encoding:      EncodingWKB,
+		GeometryTypes: []string{"GeometryCollection", "LineString Z", "Point"},
+		Edges:         EdgesSpherical,
+		BBox:          []float64{-3, -7, 6, 10},
+	}
+	if got := e.ColumnMetadata(); !reflect.DeepEqual(got, want) {
+		t.Errorf("e.ColumnMetadata() == %+v, want %+v", got, want)
+	}
+}
+
+func TestColumnEncoderErrors(t *testing.T) {
+	errWrite := errors.New("write error")
+	e := NewColumnEncoder(func([]byte) error {
+		return errWrite
+	})
+	if err := e.Encode(geom.NewPointFlat(geom.XY, []float64{1, 2})); err != errWrite {
+		t.Errorf("e.Encode(...) == %v, want %v", err, errWrite)
+	}
+	if got := e.ColumnMetadata(); len(got.GeometryTypes) != 0 || got.BBox != nil {
+		t.Errorf("e.ColumnMetadata() == %+v, want no geometry types or bbox", got)
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	m := NewMetadata("geometry", &ColumnMetadata{
+		Encoding:      EncodingWKB,
+		GeometryTypes: []string{"Polygon"},
+		CRS:           json.RawMessage(`null`),
+		BBox:          []float64{0, 1, 2, 3},
+	})
+	data, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantData := `{"version":"1.1.0","primary_column":"geometry","columns":{"geometry":{"encoding":"WKB","geometry_types":["Polygon"],"crs":null,"bbox":[0,1,2,3]}}}`
+	if string(data) != wantData {
+		t.Errorf("json.Marshal(m) == %s, _, want %s, _", data, wantData)
+	}
+	got, err := ParseMetadata(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, m) {
+		t.Errorf("ParseMetadata(...) == %+v, _, want %+v, _", got, m)
+	}
+	if b, want := got.Columns["geometry"].Bounds(), geom.NewBounds(geom.XY).Set(0, 1, 2, 3); !reflect.DeepEqual(b, want) {
+		t.Errorf("Bounds() == %v, want %v", b, want)
+	}
+}
+
+func TestParseMetadataErrors(t *testing.T) {
+	for _, tc := range []struct {
+		data string
+		err  error
+	}{
+		{
+			data: `{"version":"2.0.0","primary_column":"g","columns":{"g":{"encoding":"WKB"}}}`,
+			err:  ErrUnsupportedVersion("2.0.0"),
+		},
+		{
+			data: `{"version":"1.0.0","primary_column":"g"}`,
+			err:  errNoColumns,
+		},
+		{
+			data: `{"version":"1.0.0","primary_column":"h","columns":{"g":{"encoding":"WKB"}}}`,
+			err:  errNoPrimaryColumn,
+		},
+		{
+			data: `{"version":"1.0.0","primary_column":"g","columns":{"g":{"encoding":"point"}}}`,
+			err:  errUnsupportedEncoding,
+		},
+	} {
+		if _, err := ParseMetadata([]byte(tc.data)); err != tc.err {
+			t.Errorf("ParseMetadata(%s) == _, %v, want _, %v", tc.data, err, tc.err)
+		}
+	}
+}
@@ -0,0 +1,415 @@
+package gml
+
+import (
+	"encoding/xml"
+	"strconv"
+	"strings"
+
+	"github.com/twpayne/go-geom"
+)
+
+// An element is a generic XML element.
+type element struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr `xml:",any,attr"`
+	Children []element  `xml:",any"`
+	Text     string     `xml:",chardata"`
+}
+
+// attr returns the value of the attribute of e called name.
+func (e *element) attr(name string) string {
+	for _, attr := range e.Attrs {
+		if attr.Name.Local == name {
+			return strings.TrimSpace(attr.Value)
+		}
+	}
+	return ""
+}
+
+// children returns the children of e called any of names.
+func (e *element) children(names ...string) []*element {
+	var children []*element
+	for i := range e.Children {
+		for _, name := range names {
+			if e.Children[i].XMLName.Local == name {
+				children = append(children, &e.Children[i])
+				break
+			}
+		}
+	}
+	return children
+}
+
+// members returns the grandchildren of e that are children of its children
+// called any of names, as in the member and members properties of GML
+// multi-geometries.
+func (e *element) members(names ...string) []*element {
+	var members []*element
+	for _, child := range e.children(names...) {
+		for i := range child.Children {
+			members = append(members, &child.Children[i])
+		}
+	}
+	return members
+}
+
+// Unmarshal decodes the GML geometry element in data, which must be a Point,
+// LineString, LinearRing, Polygon, MultiPoint, MultiCurve, MultiLineString,
+// MultiSurface, MultiPolygon, or MultiGeometry. Coordinates may be encoded
+// with pos, posList, or coordinates elements. The number of ordinates is
+// given by the nearest srsDimension attribute, or by the number of ordinates
+// in each pos or coordinates tuple, and defaults to two for posLists. The
+// SRID is decoded from the srsName attribute of the outermost element, if it
+// names an EPSG code. Elements are matched by their local names, so
+// namespace prefixes are ignored, which means that GML 2 geometries are also
+// decoded.
+func Unmarshal(data []byte, opts ...Option) (geom.T, error) {
+	var e element
+	if err := xml.Unmarshal(data, &e); err != nil {
+		return nil, err
+	}
+	d := &decoder{
+		options: newOptions(opts),
+	}
+	g, err := d.decode(&e, 0)
+	if err != nil {
+		return nil, err
+	}
+	if srid := parseSRSName(e.attr("srsName")); srid != 0 {
+		setSRID(g, srid)
+	}
+	return g, nil
+}
+
+// UnmarshalStub returns the Stub of the GML geometry element in data.
+func UnmarshalStub(data []byte, opts ...Option) (*geom.Stub, error) {
+	g, err := Unmarshal(data, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return geom.NewStub(g)
+}
+
+// A decoder decodes GML geometries.
+type decoder struct {
+	options
+}
+
+// decode decodes the geometry element e. dim is the srsDimension inherited
+// from e's ancestors, or zero if there is none.
+func (d *decoder) decode(e *element, dim int) (geom.T, error) {
+	dim, err := dimension(e, dim)
+	if err != nil {
+		return nil, err
+	}
+	switch e.XMLName.Local {
+	case "Point":
+		flatCoords, layout, err := d.decodeCoordinates(e, dim)
+		switch {
+		case err != nil:
+			return nil, err
+		case len(flatCoords) == 0:
+			return geom.NewPointEmpty(layout), nil
+		case len(flatCoords) != layout.Stride():
+			return nil, ErrInvalidCoordinates(coordinatesText(e))
+		}
+		return geom.NewPointFlat(layout, flatCoords), nil
+	case "LineString":
+		flatCoords, layout, err := d.decodeCoordinates(e, dim)
+		if err != nil {
+			return nil, err
+		}
+		return geom.NewLineStringFlat(layout, flatCoords), nil
+	case "LinearRing":
+		return d.decodeLinearRing(e, dim)
+	case "Polygon":
+		return d.decodePolygon(e, dim)
+	case "MultiPoint":
+		members, layout, err := d.decodeMembers(e, dim, "Point", "pointMember", "pointMembers")
+		if err != nil {
+			return nil, err
+		}
+		g := geom.NewMultiPoint(layout)
+		for _, member := range members {
+			if err := g.Push(member.(*geom.Point)); err != nil {
+				return nil, err
+			}
+		}
+		return g, nil
+	case "MultiCurve", "MultiLineString":
+		members, layout, err := d.decodeMembers(e, dim, "LineString", "curveMember", "curveMembers", "lineStringMember")
+		if err != nil {
+			return nil, err
+		}
+		g := geom.NewMultiLineString(layout)
+		for _, member := range members {
+			if err := g.Push(member.(*geom.LineString)); err != nil {
+				return nil, err
+			}
+		}
+		return g, nil
+	case "MultiSurface", "MultiPolygon":
+		members, layout, err := d.decodeMembers(e, dim, "Polygon", "surfaceMember", "surfaceMembers", "polygonMember")
+		if err != nil {
+			return nil, err
+		}
+		g := geom.NewMultiPolygon(layout)
+		for _, member := range members {
+			if err := g.Push(member.(*geom.Polygon)); err != nil {
+				return nil, err
+			}
+		}
+		return g, nil
+	case "MultiGeometry":
+		g := geom.NewGeometryCollection()
+		for _, member := range e.members("geometryMember", "geometryMembers") {
+			m, err := d.decode(member, dim)
+			if err != nil {
+				return nil, err
+			}
+			if err := g.Push(m); err != nil {
+				return nil, err
+			}
+		}
+		return g, nil
+	default:
+		return nil, ErrUnsupportedElement(e.XMLName.Local)
+	}
+}
+
+// decodeMembers decodes the members of the multi-geometry e, which must all
+// be elements called name. It also returns the layout of the multi-geometry,
+// which is the layout of its first member, or the layout of its srsDimension
+// if it has no members.
+func (d *decoder) decodeMembers(e *element, dim int, name string, memberNames ...string) ([]geom.T, geom.Layout, error) {
+	var members []geom.T
+	for _, member := range e.members(memberNames...) {
+		if member.XMLName.Local != name {
+			return nil, geom.NoLayout, ErrUnsupportedElement(member.XMLName.Local)
+		}
+		g, err := d.decode(member, dim)
+		if err != nil {
+			return nil, geom.NoLayout, err
+		}
+		members = append(members, g)
+	}
+	if len(members) == 0 {
+		return nil, dimensionLayout(dim), nil
+	}
+	return members, members[0].Layout(), nil
+}
+
+// decodeLinearRing decodes the LinearRing element e.
+func (d *decoder) decodeLinearRing(e *element, dim int) (*geom.LinearRing, error) {
+	dim, err := dimension(e, dim)
+	if err != nil {
+		return nil, err
+	}
+	flatCoords, layout, err := d.decodeCoordinates(e, dim)
+	if err != nil {
+		return nil, err
+	}
+	return geom.NewLinearRingFlat(layout, flatCoords), nil
+}
+
+// decodePolygon decodes the Polygon element e. The GML 2 outerBoundaryIs and
+// innerBoundaryIs elements are accepted as well as exterior and interior.
+func (d *decoder) decodePolygon(e *element, dim int) (*geom.Polygon, error) {
+	var rings []*element
+	for _, names := range [][]string{
+		{"exterior", "outerBoundaryIs"},
+		{"interior", "innerBoundaryIs"},
+	} {
+		for _, boundary := range e.children(names...) {
+			rings = append(rings, boundary.children("LinearRing")...)
+		}
+	}
+	if len(rings) == 0 {
+		return geom.NewPolygon(dimensionLayout(dim)), nil
+	}
+	var g *geom.Polygon
+	for _, ring := range rings {
+		lr, err := d.decodeLinearRing(ring, dim)
+		if err != nil {
+			return nil, err
+		}
+		if g == nil {
+			g = geom.NewPolygon(lr.Layout())
+		}
+		if err := g.Push(lr); err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+// decodeCoordinates decodes the posList, pos, or coordinates children of e.
+func (d *decoder) decodeCoordinates(e *element, dim int) ([]float64, geom.Layout, error) {
+	var flatCoords []float64
+	if posLists := e.children("posList"); len(posLists) > 0 {
+		posList := posLists[0]
+		var err error
+		if dim, err = dimension(posList, dim); err != nil {
+			return nil, geom.NoLayout, err
+		}
+		if dim == 0 {
+			dim = 2
+		}
+		if flatCoords, err = parseFloats(posList.Text); err != nil {
+			return nil, geom.NoLayout, err
+		}
+		if len(flatCoords)%dim != 0 {
+			return nil, geom.NoLayout, ErrInvalidCoordinates(posList.Text)
+		}
+	} else if poss := e.children("pos"); len(poss) > 0 {
+		posDim := dim
+		for _, pos := range poss {
+			coord, err := parseFloats(pos.Text)
+			if err != nil {
+				return nil, geom.NoLayout, err
+			}
+			if posDim, err = dimension(pos, posDim); err != nil {
+				return nil, geom.NoLayout, err
+			}
+			if posDim == 0 {
+				posDim = len(coord)
+			}
+			if len(coord) == 0 && len(poss) == 1 {
+				break
+			}
+			if len(coord) != posDim {
+				return nil, geom.NoLayout, ErrInvalidCoordinates(pos.Text)
+			}
+			flatCoords = append(flatCoords, coord...)
+		}
+		dim = posDim
+	} else if coordinatess := e.children("coordinates"); len(coordinatess) > 0 {
+		var err error
+		if flatCoords, dim, err = parseCoordinates(coordinatess[0], dim); err != nil {
+			return nil, geom.NoLayout, err
+		}
+	}
+	if dim == 0 {
+		dim = 2
+	}
+	if dim != 2 && dim != 3 {
+		return nil, geom.NoLayout, ErrUnsupportedDimension(dim)
+	}
+	if d.swapXY {
+		for i := 0; i < len(flatCoords); i += dim {
+			flatCoords[i], flatCoords[i+1] = flatCoords[i+1], flatCoords[i]
+		}
+	}
+	return flatCoords, dimensionLayout(dim), nil
+}
+
+// coordinatesText returns the text of the first posList, pos, or coordinates
+// child of e.
+func coordinatesText(e *element) string {
+	if children := e.children("posList", "pos", "coordinates"); len(children) > 0 {
+		return children[0].Text
+	}
+	return ""
+}
+
+// dimension returns the srsDimension of e, or dim if e does not have one.
+func dimension(e *element, dim int) (int, error) {
+	s := e.attr("srsDimension")
+	if s == "" {
+		return dim, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 2 || n > 3 {
+		return 0, ErrUnsupportedDimension(n)
+	}
+	return n, nil
+}
+
+// dimensionLayout returns the layout of coordinates with dim ordinates.
+func dimensionLayout(dim int) geom.Layout {
+	if dim == 3 {
+		return geom.XYZ
+	}
+	return geom.XY
+}
+
+// parseFloats parses the whitespace-separated floats in s.
+func parseFloats(s string) ([]float64, error) {
+	fields := strings.Fields(s)
+	floats := make([]float64, 0, len(fields))
+	for _, field := range fields {
+		f, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return nil, ErrInvalidCoordinates(s)
+		}
+		floats = append(floats, f)
+	}
+	return floats, nil
+}
+
+// parseCoordinates parses the GML 2 coordinates element e, with the tuple,
+// coordinate, and decimal separators given by its ts, cs, and decimal
+// attributes. It returns the flat coordinates and the number of ordinates in
+// each tuple, which must match dim if it is not zero.
+func parseCoordinates(e *element, dim int) ([]float64, int, error) {
+	cs, ts, decimal := e.attr("cs"), e.attr("ts"), e.attr("decimal")
+	if cs == "" {
+		cs = ","
+	}
+	if decimal == "" {
+		decimal = "."
+	}
+	var tuples []string
+	if ts == "" {
+		tuples = strings.Fields(e.Text)
+	} else {
+		for _, tuple := range strings.Split(strings.TrimSpace(e.Text), ts) {
+			if tuple = strings.TrimSpace(tuple); tuple != "" {
+				tuples = append(tuples, tuple)
+			}
+		}
+	}
+	var flatCoords []float64
+	for _, tuple := range tuples {
+		fields := strings.Split(tuple, cs)
+		if dim == 0 {
+			dim = len(fields)
+		}
+		if len(fields) != dim {
+			return nil, 0, ErrInvalidCoordinates(e.Text)
+		}
+		for _, field := range fields {
+			if decimal != "." {
+				field = strings.Replace(field, decimal, ".", 1)
+			}
+			f, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+			if err != nil {
+				return nil, 0, ErrInvalidCoordinates(e.Text)
+			}
+			flatCoords = append(flatCoords, f)
+		}
+	}
+	return flatCoords, dim, nil
+}
+
+// setSRID sets the SRID of g.
+func setSRID(g geom.T, srid int) {
+	switch g := g.(type) {
+	case *geom.Point:
+		g.SetSRID(srid)
+	case *geom.LineString:
+		g.SetSRID(srid)
+	case *geom.LinearRing:
+		g.SetSRID(srid)
+	case *geom.Polygon:
+		g.SetSRID(srid)
+	case *geom.MultiPoint:
+		g.SetSRID(srid)
+	case *geom.MultiLineString:
+		g.SetSRID(srid)
+	case *geom.MultiPolygon:
+		g.SetSRID(srid)
+	case *geom.GeometryCollection:
+		g.SetSRID(srid)
+	}
+}
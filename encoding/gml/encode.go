@@ -0,0 +1,175 @@
+package gml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strconv"
+
+	"github.com/twpayne/go-geom"
+)
+
+// An encoder encodes geometries as GML.
+type encoder struct {
+	options
+	buf bytes.Buffer
+}
+
+// Marshal returns the GML encoding of g. The srsName and, for geometries with
+// a Z ordinate, srsDimension attributes are set on the outermost element,
+// which also declares the gml namespace.
+func Marshal(g geom.T, opts ...Option) ([]byte, error) {
+	e := &encoder{
+		options: newOptions(opts),
+	}
+	if err := e.encode(g, true, e.id); err != nil {
+		return nil, err
+	}
+	return e.buf.Bytes(), nil
+}
+
+// encode encodes g with id. If top is true, g is the outermost geometry.
+func (e *encoder) encode(g geom.T, top bool, id string) error {
+	dim := layoutDimension(g.Layout())
+	switch g := g.(type) {
+	case *geom.Point:
+		e.start("Point", g, top, id, dim)
+		e.posList("pos", g.FlatCoords(), g.Stride(), dim)
+		e.end("Point")
+	case *geom.LineString:
+		e.start("LineString", g, top, id, dim)
+		e.posList("posList", g.FlatCoords(), g.Stride(), dim)
+		e.end("LineString")
+	case *geom.LinearRing:
+		e.start("LinearRing", g, top, "", dim)
+		e.posList("posList", g.FlatCoords(), g.Stride(), dim)
+		e.end("LinearRing")
+	case *geom.Polygon:
+		e.start("Polygon", g, top, id, dim)
+		e.polygonRings(g, dim)
+		e.end("Polygon")
+	case *geom.MultiPoint:
+		e.start("MultiPoint", g, top, id, dim)
+		for i, n := 0, g.NumPoints(); i < n; i++ {
+			e.buf.WriteString("<gml:pointMember>")
+			if err := e.encode(g.Point(i), false, memberID(id, i)); err != nil {
+				return err
+			}
+			e.buf.WriteString("</gml:pointMember>")
+		}
+		e.end("MultiPoint")
+	case *geom.MultiLineString:
+		e.start("MultiCurve", g, top, id, dim)
+		for i, n := 0, g.NumLineStrings(); i < n; i++ {
+			e.buf.WriteString("<gml:curveMember>")
+			if err := e.encode(g.LineString(i), false, memberID(id, i)); err != nil {
+				return err
+			}
+			e.buf.WriteString("</gml:curveMember>")
+		}
+		e.end("MultiCurve")
+	case *geom.MultiPolygon:
+		e.start("MultiSurface", g, top, id, dim)
+		for i, n := 0, g.NumPolygons(); i < n; i++ {
+			e.buf.WriteString("<gml:surfaceMember>")
+			if err := e.encode(g.Polygon(i), false, memberID(id, i)); err != nil {
+				return err
+			}
+			e.buf.WriteString("</gml:surfaceMember>")
+		}
+		e.end("MultiSurface")
+	case *geom.GeometryCollection:
+		e.start("MultiGeometry", g, top, id, dim)
+		for i, member := range g.Geoms() {
+			e.buf.WriteString("<gml:geometryMember>")
+			if err := e.encode(member, false, memberID(id, i)); err != nil {
+				return err
+			}
+			e.buf.WriteString("</gml:geometryMember>")
+		}
+		e.end("MultiGeometry")
+	default:
+		return geom.ErrUnsupportedType{Value: g}
+	}
+	return nil
+}
+
+// start writes the start tag of the element called name that encodes g.
+func (e *encoder) start(name string, g geom.T, top bool, id string, dim int) {
+	e.buf.WriteString("<gml:")
+	e.buf.WriteString(name)
+	if top {
+		e.attr("xmlns:gml", Namespace)
+	}
+	if id != "" {
+		e.attr("gml:id", id)
+	}
+	if top {
+		if srsName := e.srsNameFunc(g.SRID()); srsName != "" {
+			e.attr("srsName", srsName)
+		}
+		if dim != 2 {
+			e.attr("srsDimension", strconv.Itoa(dim))
+		}
+	}
+	e.buf.WriteByte('>')
+}
+
+// end writes the end tag of the element called name.
+func (e *encoder) end(name string) {
+	e.buf.WriteString("</gml:")
+	e.buf.WriteString(name)
+	e.buf.WriteByte('>')
+}
+
+// attr writes the attribute name with value.
+func (e *encoder) attr(name, value string) {
+	e.buf.WriteByte(' ')
+	e.buf.WriteString(name)
+	e.buf.WriteString(`="`)
+	_ = xml.EscapeText(&e.buf, []byte(value))
+	e.buf.WriteByte('"')
+}
+
+// polygonRings writes the exterior and interior rings of g.
+func (e *encoder) polygonRings(g *geom.Polygon, dim int) {
+	for i, n := 0, g.NumLinearRings(); i < n; i++ {
+		boundary := "interior"
+		if i == 0 {
+			boundary = "exterior"
+		}
+		ring := g.LinearRing(i)
+		e.buf.WriteString("<gml:" + boundary + "><gml:LinearRing>")
+		e.posList("posList", ring.FlatCoords(), ring.Stride(), dim)
+		e.buf.WriteString("</gml:LinearRing></gml:" + boundary + ">")
+	}
+}
+
+// posList writes the first dim ordinates of each coordinate in flatCoords as
+// the element called name.
+func (e *encoder) posList(name string, flatCoords []float64, stride, dim int) {
+	e.buf.WriteString("<gml:" + name + ">")
+	var b []byte
+	for i := 0; i < len(flatCoords); i += stride {
+		coord := flatCoords[i : i+dim]
+		for j := range coord {
+			k := j
+			if e.swapXY && k < 2 {
+				k = 1 - k
+			}
+			if len(b) != 0 {
+				b = append(b, ' ')
+			}
+			b = strconv.AppendFloat(b, coord[k], 'f', -1, 64)
+		}
+	}
+	e.buf.Write(b)
+	e.buf.WriteString("</gml:" + name + ">")
+}
+
+// memberID returns the gml:id of the ith member of the geometry with id.
+func memberID(id string, i int) string {
+	if id == "" {
+		return ""
+	}
+	return id + "." + strconv.Itoa(i)
+}
@@ -0,0 +1,145 @@
+// Package gml implements GML 3.2 encoding and decoding of geometries. See
+// https://www.ogc.org/standard/gml/.
+//
+// Geometries are encoded as follows:
+//
+//	Point               gml:Point
+//	LineString          gml:LineString
+//	LinearRing          gml:LinearRing
+//	Polygon             gml:Polygon
+//	MultiPoint          gml:MultiPoint
+//	MultiLineString     gml:MultiCurve
+//	MultiPolygon        gml:MultiSurface
+//	GeometryCollection  gml:MultiGeometry
+//
+// GML has no M ordinate, so geometries are encoded with two or three
+// dimensions and M values are dropped.
+package gml
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/twpayne/go-geom"
+)
+
+// Namespace is the GML 3.2 namespace.
+const Namespace = "http://www.opengis.net/gml/3.2"
+
+// An ErrUnsupportedElement is returned when decoding an element that is not a
+// supported GML geometry.
+type ErrUnsupportedElement string
+
+func (e ErrUnsupportedElement) Error() string {
+	return fmt.Sprintf("gml: unsupported element: %s", string(e))
+}
+
+// An ErrInvalidCoordinates is returned when decoding malformed coordinates.
+type ErrInvalidCoordinates string
+
+func (e ErrInvalidCoordinates) Error() string {
+	return fmt.Sprintf("gml: invalid coordinates: %q", string(e))
+}
+
+// An ErrUnsupportedDimension is returned when decoding coordinates with an
+// srsDimension other than two or three.
+type ErrUnsupportedDimension int
+
+func (e ErrUnsupportedDimension) Error() string {
+	return fmt.Sprintf("gml: unsupported srsDimension: %d", int(e))
+}
+
+// An Option sets an option for encoding or decoding. Options that do not
+// apply to an operation are ignored.
+type Option func(*options)
+
+type options struct {
+	id          string
+	srsNameFunc func(srid int) string
+	swapXY      bool
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		srsNameFunc: DefaultSRSName,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// DefaultSRSName returns the srsName of srid used by default when encoding,
+// which is an EPSG URN, or the empty string if srid is zero.
+func DefaultSRSName(srid int) string {
+	if srid == 0 {
+		return ""
+	}
+	return "urn:ogc:def:crs:EPSG::" + strconv.Itoa(srid)
+}
+
+// WithID sets the gml:id of encoded geometries. The members of
+// multi-geometries are given the id followed by a period and their index. By
+// default, no gml:ids are encoded, although GML 3.2 requires them.
+func WithID(id string) Option {
+	return func(o *options) {
+		o.id = id
+	}
+}
+
+// WithSRSNameFunc sets the function that returns the srsName attribute of
+// encoded geometries from their SRIDs. No srsName is encoded if it returns
+// the empty string. The default is DefaultSRSName.
+func WithSRSNameFunc(srsNameFunc func(srid int) string) Option {
+	return func(o *options) {
+		o.srsNameFunc = srsNameFunc
+	}
+}
+
+// WithSwapXY sets whether the first two ordinates of each coordinate are
+// swapped when encoding and decoding. This is needed for coordinate
+// reference systems whose axis order is latitude, longitude, such as
+// urn:ogc:def:crs:EPSG::4326, as geometries always have the longitude first.
+// By default ordinates are not swapped.
+func WithSwapXY(swapXY bool) Option {
+	return func(o *options) {
+		o.swapXY = swapXY
+	}
+}
+
+// parseSRSName returns the EPSG code in srsName, or zero if there is none.
+// It recognizes EPSG:4326, urn:ogc:def:crs:EPSG::4326,
+// http://www.opengis.net/def/crs/EPSG/0/4326, and
+// http://www.opengis.net/gml/srs/epsg.xml#4326.
+func parseSRSName(srsName string) int {
+	for _, prefix := range []string{
+		"EPSG:",
+		"urn:ogc:def:crs:EPSG::",
+		"urn:ogc:def:crs:EPSG:",
+		"urn:x-ogc:def:crs:EPSG:",
+		"http://www.opengis.net/def/crs/EPSG/0/",
+		"http://www.opengis.net/gml/srs/epsg.xml#",
+	} {
+		if strings.HasPrefix(srsName, prefix) {
+			code := srsName[len(prefix):]
+			if i := strings.LastIndexByte(code, ':'); i != -1 {
+				// urn:ogc:def:crs:EPSG:version:code
+				code = code[i+1:]
+			}
+			if srid, err := strconv.Atoi(code); err == nil {
+				return srid
+			}
+		}
+	}
+	return 0
+}
+
+// layoutDimension returns the number of ordinates of layout that are
+// encoded.
+func layoutDimension(layout geom.Layout) int {
+	if layout.ZIndex() != -1 {
+		return 3
+	}
+	return 2
+}
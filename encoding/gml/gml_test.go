@@ -0,0 +1,279 @@
+package gml
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+)
+
+func TestMarshal(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		g    geom.T
+		opts []Option
+		want string
+	}{
+		{
+			name: "point",
+			g:    geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1, 2}),
+			want: `<gml:Point xmlns:gml="http://www.opengis.net/gml/3.2"><gml:pos>1 2</gml:pos></gml:Point>`,
+		},
+		{
+			name: "point_xyzm",
+			g:    geom.NewPoint(geom.XYZM).MustSetCoords(geom.Coord{1, 2, 3, 4}).SetSRID(4326),
+			want: `<gml:Point xmlns:gml="http://www.opengis.net/gml/3.2" srsName="urn:ogc:def:crs:EPSG::4326" srsDimension="3"><gml:pos>1 2 3</gml:pos></gml:Point>`,
+		},
+		{
+			name: "point_swap_xy",
+			g:    geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1, 2}),
+			opts: []Option{WithSwapXY(true), WithID("p")},
+			want: `<gml:Point xmlns:gml="http://www.opengis.net/gml/3.2" gml:id="p"><gml:pos>2 1</gml:pos></gml:Point>`,
+		},
+		{
+			name: "line_string",
+			g:    geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{{1, 2}, {3.5, 4}}).SetSRID(3857),
+			opts: []Option{WithSRSNameFunc(func(srid int) string { return "EPSG:3857" })},
+			want: `<gml:LineString xmlns:gml="http://www.opengis.net/gml/3.2" srsName="EPSG:3857"><gml:posList>1 2 3.5 4</gml:posList></gml:LineString>`,
+		},
+		{
+			name: "polygon",
+			g: geom.NewPolygon(geom.XY).MustSetCoords([][]geom.Coord{
+				{{0, 0}, {3, 0}, {3, 3}, {0, 0}},
+				{{1, 1}, {2, 2}, {2, 1}, {1, 1}},
+			}),
+			want: `<gml:Polygon xmlns:gml="http://www.opengis.net/gml/3.2">` +
+				`<gml:exterior><gml:LinearRing><gml:posList>0 0 3 0 3 3 0 0</gml:posList></gml:LinearRing></gml:exterior>` +
+				`<gml:interior><gml:LinearRing><gml:posList>1 1 2 2 2 1 1 1</gml:posList></gml:LinearRing></gml:interior>` +
+				`</gml:Polygon>`,
+		},
+		{
+			name: "multi_point",
+			g:    geom.NewMultiPoint(geom.XY).MustSetCoords([]geom.Coord{{1, 2}, {3, 4}}),
+			opts: []Option{WithID("mp")},
+			want: `<gml:MultiPoint xmlns:gml="http://www.opengis.net/gml/3.2" gml:id="mp">` +
+				`<gml:pointMember><gml:Point gml:id="mp.0"><gml:pos>1 2</gml:pos></gml:Point></gml:pointMember>` +
+				`<gml:pointMember><gml:Point gml:id="mp.1"><gml:pos>3 4</gml:pos></gml:Point></gml:pointMember>` +
+				`</gml:MultiPoint>`,
+		},
+		{
+			name: "multi_line_string",
+			g:    geom.NewMultiLineString(geom.XY).MustSetCoords([][]geom.Coord{{{1, 2}, {3, 4}}}),
+			want: `<gml:MultiCurve xmlns:gml="http://www.opengis.net/gml/3.2">` +
+				`<gml:curveMember><gml:LineString><gml:posList>1 2 3 4</gml:posList></gml:LineString></gml:curveMember>` +
+				`</gml:MultiCurve>`,
+		},
+		{
+			name: "multi_polygon",
+			g:    geom.NewMultiPolygon(geom.XY).MustSetCoords([][][]geom.Coord{{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}}),
+			want: `<gml:MultiSurface xmlns:gml="http://www.opengis.net/gml/3.2">` +
+				`<gml:surfaceMember><gml:Polygon><gml:exterior><gml:LinearRing><gml:posList>0 0 1 0 1 1 0 0</gml:posList></gml:LinearRing></gml:exterior></gml:Polygon></gml:surfaceMember>` +
+				`</gml:MultiSurface>`,
+		},
+		{
+			name: "geometry_collection",
+			g: geom.NewGeometryCollection().MustPush(
+				geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1, 2}),
+			),
+			want: `<gml:MultiGeometry xmlns:gml="http://www.opengis.net/gml/3.2">` +
+				`<gml:geometryMember><gml:Point><gml:pos>1 2</gml:pos></gml:Point></gml:geometryMember>` +
+				`</gml:MultiGeometry>`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Marshal(tc.g, tc.opts...)
+			if err != nil {
+				t.Fatalf("Marshal(...) == _, %v, want _, <nil>", err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("Marshal(...) == %s, _, want %s, _", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		data string
+		opts []Option
+		want geom.T
+	}{
+		{
+			name: "point",
+			data: `<gml:Point xmlns:gml="http://www.opengis.net/gml/3.2" gml:id="p"><gml:pos>1 2</gml:pos></gml:Point>`,
+			want: geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1, 2}),
+		},
+		{
+			name: "point_xyz",
+			data: `<Point srsName="EPSG:4326"><pos>1 2 3</pos></Point>`,
+			want: geom.NewPoint(geom.XYZ).MustSetCoords(geom.Coord{1, 2, 3}).SetSRID(4326),
+		},
+		{
+			name: "point_empty",
+			data: `<Point><pos/></Point>`,
+			want: geom.NewPointEmpty(geom.XY),
+		},
+		{
+			name: "point_coordinates",
+			data: `<Point srsName="http://www.opengis.net/gml/srs/epsg.xml#4326"><coordinates>1,2</coordinates></Point>`,
+			want: geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1, 2}).SetSRID(4326),
+		},
+		{
+			name: "point_swap_xy",
+			data: `<Point srsName="urn:ogc:def:crs:EPSG::4326"><pos>2 1</pos></Point>`,
+			opts: []Option{WithSwapXY(true)},
+			want: geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1, 2}).SetSRID(4326),
+		},
+		{
+			name: "line_string_pos_list",
+			data: `<LineString srsName="http://www.opengis.net/def/crs/EPSG/0/3857" srsDimension="3"><posList>1 2 3 4 5 6</posList></LineString>`,
+			want: geom.NewLineString(geom.XYZ).MustSetCoords([]geom.Coord{{1, 2, 3}, {4, 5, 6}}).SetSRID(3857),
+		},
+		{
+			name: "line_string_pos_list_srs_dimension",
+			data: `<LineString><posList srsDimension="3">1 2 3 4 5 6</posList></LineString>`,
+			want: geom.NewLineString(geom.XYZ).MustSetCoords([]geom.Coord{{1, 2, 3}, {4, 5, 6}}),
+		},
+		{
+			name: "line_string_pos",
+			data: `<LineString><pos>1 2</pos><pos>3 4</pos></LineString>`,
+			want: geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{{1, 2}, {3, 4}}),
+		},
+		{
+			name: "line_string_coordinates",
+			data: `<LineString><coordinates decimal="," cs=";" ts="|">1,5;2|3;4,5</coordinates></LineString>`,
+			want: geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{{1.5, 2}, {3, 4.5}}),
+		},
+		{
+			name: "polygon",
+			data: `<Polygon>` +
+				`<exterior><LinearRing><posList>0 0 3 0 3 3 0 0</posList></LinearRing></exterior>` +
+				`<interior><LinearRing><posList>1 1 2 2 2 1 1 1</posList></LinearRing></interior>` +
+				`</Polygon>`,
+			want: geom.NewPolygon(geom.XY).MustSetCoords([][]geom.Coord{
+				{{0, 0}, {3, 0}, {3, 3}, {0, 0}},
+				{{1, 1}, {2, 2}, {2, 1}, {1, 1}},
+			}),
+		},
+		{
+			name: "polygon_gml2",
+			data: `<Polygon><outerBoundaryIs><LinearRing><coordinates>0,0 1,0 1,1 0,0</coordinates></LinearRing></outerBoundaryIs></Polygon>`,
+			want: geom.NewPolygon(geom.XY).MustSetCoords([][]geom.Coord{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}),
+		},
+		{
+			name: "multi_point",
+			data: `<MultiPoint srsDimension="3">` +
+				`<pointMember><Point><pos>1 2 3</pos></Point></pointMember>` +
+				`<pointMembers><Point><pos>4 5 6</pos></Point><Point><pos>7 8 9</pos></Point></pointMembers>` +
+				`</MultiPoint>`,
+			want: geom.NewMultiPoint(geom.XYZ).MustSetCoords([]geom.Coord{{1, 2, 3}, {4, 5, 6}, {7, 8, 9}}),
+		},
+		{
+			name: "multi_point_empty",
+			data: `<MultiPoint srsDimension="3"/>`,
+			want: geom.NewMultiPoint(geom.XYZ),
+		},
+		{
+			name: "multi_line_string",
+			data: `<MultiLineString><lineStringMember><LineString><coordinates>1,2 3,4</coordinates></LineString></lineStringMember></MultiLineString>`,
+			want: geom.NewMultiLineString(geom.XY).MustSetCoords([][]geom.Coord{{{1, 2}, {3, 4}}}),
+		},
+		{
+			name: "multi_surface",
+			data: `<MultiSurface><surfaceMember><Polygon><exterior><LinearRing><posList>0 0 1 0 1 1 0 0</posList></LinearRing></exterior></Polygon></surfaceMember></MultiSurface>`,
+			want: geom.NewMultiPolygon(geom.XY).MustSetCoords([][][]geom.Coord{{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}}),
+		},
+		{
+			name: "multi_geometry",
+			data: `<MultiGeometry srsName="EPSG:4326">` +
+				`<geometryMember><Point><pos>1 2</pos></Point></geometryMember>` +
+				`<geometryMember><LineString><posList>1 2 3 4</posList></LineString></geometryMember>` +
+				`</MultiGeometry>`,
+			want: geom.NewGeometryCollection().MustPush(
+				geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1, 2}),
+				geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{{1, 2}, {3, 4}}),
+			).SetSRID(4326),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Unmarshal([]byte(tc.data), tc.opts...)
+			if err != nil {
+				t.Fatalf("Unmarshal(...) == _, %v, want _, <nil>", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Unmarshal(...) == %#v, _, want %#v, _", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		data string
+		want error
+	}{
+		{
+			name: "unsupported_element",
+			data: `<Curve/>`,
+			want: ErrUnsupportedElement("Curve"),
+		},
+		{
+			name: "unsupported_member",
+			data: `<MultiPoint><pointMember><LineString/></pointMember></MultiPoint>`,
+			want: ErrUnsupportedElement("LineString"),
+		},
+		{
+			name: "unsupported_dimension",
+			data: `<Point srsDimension="4"><pos>1 2 3 4</pos></Point>`,
+			want: ErrUnsupportedDimension(4),
+		},
+		{
+			name: "invalid_pos_list",
+			data: `<LineString srsDimension="3"><posList>1 2 3 4</posList></LineString>`,
+			want: ErrInvalidCoordinates("1 2 3 4"),
+		},
+		{
+			name: "invalid_float",
+			data: `<Point><pos>1 x</pos></Point>`,
+			want: ErrInvalidCoordinates("1 x"),
+		},
+		{
+			name: "layout_mismatch",
+			data: `<MultiPoint><pointMember><Point><pos>1 2</pos></Point></pointMember><pointMember><Point><pos>1 2 3</pos></Point></pointMember></MultiPoint>`,
+			want: geom.ErrLayoutMismatch{Got: geom.XYZ, Want: geom.XY},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := Unmarshal([]byte(tc.data)); err != tc.want {
+				t.Errorf("Unmarshal(...) == _, %v, want _, %v", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, g := range []geom.T{
+		geom.NewPoint(geom.XYZ).MustSetCoords(geom.Coord{1, 2, 3}).SetSRID(4326),
+		geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{{1.25, -2}, {3e-7, 4e10}}),
+		geom.NewPolygon(geom.XYZ).MustSetCoords([][]geom.Coord{{{0, 0, 1}, {1, 0, 2}, {1, 1, 3}, {0, 0, 1}}}),
+		geom.NewMultiPoint(geom.XY).MustSetCoords([]geom.Coord{{1, 2}, {3, 4}}),
+		geom.NewMultiLineString(geom.XYZ).MustSetCoords([][]geom.Coord{{{1, 2, 3}, {4, 5, 6}}, {{7, 8, 9}, {10, 11, 12}}}),
+		geom.NewMultiPolygon(geom.XY).MustSetCoords([][][]geom.Coord{{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}}).SetSRID(3857),
+	} {
+		for _, swapXY := range []bool{false, true} {
+			data, err := Marshal(g, WithSwapXY(swapXY), WithID("g"))
+			if err != nil {
+				t.Fatalf("Marshal(%#v) == _, %v, want _, <nil>", g, err)
+			}
+			got, err := Unmarshal(data, WithSwapXY(swapXY))
+			if err != nil {
+				t.Fatalf("Unmarshal(%s) == _, %v, want _, <nil>", data, err)
+			}
+			if !reflect.DeepEqual(got, g) {
+				t.Errorf("Unmarshal(%s) == %#v, _, want %#v, _", data, got, g)
+			}
+		}
+	}
+}
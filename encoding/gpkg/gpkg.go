@@ -0,0 +1,402 @@
+// Package gpkg implements encoding and decoding of GeoPackage binary
+// geometries, as stored in the geometry columns of GeoPackage feature
+// tables. A GeoPackage binary geometry is a WKB geometry preceded by a header
+// containing its SRID and, optionally, its envelope. See
+// https://www.geopackage.org/spec/#gpb_format.
+package gpkg
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/wkb"
+)
+
+// An EnvelopeType describes the envelope in a header.
+type EnvelopeType byte
+
+// Envelope types.
+const (
+	NoEnvelope   EnvelopeType = 0
+	EnvelopeXY   EnvelopeType = 1
+	EnvelopeXYZ  EnvelopeType = 2
+	EnvelopeXYM  EnvelopeType = 3
+	EnvelopeXYZM EnvelopeType = 4
+)
+
+// Header flag bits.
+const (
+	flagLittleEndian  = 0x01
+	flagEnvelopeShift = 1
+	flagEnvelopeMask  = 0x0e
+	flagEmpty         = 0x10
+	flagExtended      = 0x20
+)
+
+// headerSize is the size of the fixed part of a header, before the envelope.
+const headerSize = 8
+
+var magic = [2]byte{'G', 'P'}
+
+// quietNaN is the NaN that encodes the ordinates of empty points, which is
+// the one written by other implementations, rather than math.NaN().
+var quietNaN = math.Float64frombits(0x7ff8000000000000)
+
+var (
+	errExtended  = errors.New("gpkg: extended geometries are not supported")
+	errTruncated = errors.New("gpkg: truncated header")
+)
+
+// An ErrInvalidMagic is returned when decoding data that does not start with
+// the GeoPackage binary magic number.
+type ErrInvalidMagic [2]byte
+
+func (e ErrInvalidMagic) Error() string {
+	return fmt.Sprintf("gpkg: invalid magic %q", e[:])
+}
+
+// An ErrUnsupportedVersion is returned when decoding a header with an
+// unsupported version.
+type ErrUnsupportedVersion byte
+
+func (e ErrUnsupportedVersion) Error() string {
+	return fmt.Sprintf("gpkg: unsupported version %d", byte(e))
+}
+
+// An ErrUnsupportedEnvelope is returned when decoding a header with an
+// invalid envelope type, or when encoding an envelope with ordinates that
+// the geometry does not have.
+type ErrUnsupportedEnvelope EnvelopeType
+
+func (e ErrUnsupportedEnvelope) Error() string {
+	return fmt.Sprintf("gpkg: unsupported envelope type %d", byte(e))
+}
+
+// layout returns the layout of envelopes of type t.
+func (t EnvelopeType) layout() geom.Layout {
+	switch t {
+	case EnvelopeXY:
+		return geom.XY
+	case EnvelopeXYZ:
+		return geom.XYZ
+	case EnvelopeXYM:
+		return geom.XYM
+	case EnvelopeXYZM:
+		return geom.XYZM
+	default:
+		return geom.NoLayout
+	}
+}
+
+// A Header is a GeoPackage binary header.
+type Header struct {
+	Version   byte
+	ByteOrder binary.ByteOrder // byte order of the header, not of the WKB
+	Empty     bool
+	Extended  bool // whether the geometry uses an extension
+	SRID      int
+	Envelope  EnvelopeType
+	Bounds    *geom.Bounds // nil if Envelope is NoEnvelope
+}
+
+// DecodeHeader decodes the header at the start of data and returns it with
+// the remaining bytes, which, unless the header is extended, are the WKB
+// geometry.
+func DecodeHeader(data []byte) (*Header, []byte, error) {
+	if len(data) < headerSize {
+		return nil, nil, errTruncated
+	}
+	if data[0] != magic[0] || data[1] != magic[1] {
+		return nil, nil, ErrInvalidMagic{data[0], data[1]}
+	}
+	h := &Header{
+		Version:  data[2],
+		Empty:    data[3]&flagEmpty != 0,
+		Extended: data[3]&flagExtended != 0,
+		Envelope: EnvelopeType((data[3] & flagEnvelopeMask) >> flagEnvelopeShift),
+	}
+	if h.Version != 0 {
+		return nil, nil, ErrUnsupportedVersion(h.Version)
+	}
+	if data[3]&flagLittleEndian != 0 {
+		h.ByteOrder = binary.LittleEndian
+	} else {
+		h.ByteOrder = binary.BigEndian
+	}
+	h.SRID = int(int32(h.ByteOrder.Uint32(data[4:8])))
+	data = data[headerSize:]
+	if h.Envelope == NoEnvelope {
+		return h, data, nil
+	}
+	layout := h.Envelope.layout()
+	if layout == geom.NoLayout {
+		return nil, nil, ErrUnsupportedEnvelope(h.Envelope)
+	}
+	stride := layout.Stride()
+	if len(data) < 16*stride {
+		return nil, nil, errTruncated
+	}
+	// The envelope is encoded as minx, maxx, miny, maxy, and so on.
+	args := make([]float64, 2*stride)
+	for i := 0; i < stride; i++ {
+		args[i] = math.Float64frombits(h.ByteOrder.Uint64(data[16*i:]))
+		args[stride+i] = math.Float64frombits(h.ByteOrder.Uint64(data[16*i+8:]))
+	}
+	h.Bounds = geom.NewBounds(layout).Set(args...)
+	return h, data[16*stride:], nil
+}
+
+// AppendHeader appends the encoding of h to dst and returns the extended
+// buffer. h.Bounds must have the layout of h.Envelope.
+func AppendHeader(dst []byte, h *Header) []byte {
+	flags := byte(h.Envelope) << flagEnvelopeShift
+	byteOrder := h.ByteOrder
+	if byteOrder == nil {
+		byteOrder = binary.LittleEndian
+	}
+	if byteOrder == binary.LittleEndian {
+		flags |= flagLittleEndian
+	}
+	if h.Empty {
+		flags |= flagEmpty
+	}
+	if h.Extended {
+		flags |= flagExtended
+	}
+	dst = append(dst, magic[0], magic[1], h.Version, flags)
+	var buf [8]byte
+	byteOrder.PutUint32(buf[:4], uint32(int32(h.SRID)))
+	dst = append(dst, buf[:4]...)
+	if h.Envelope == NoEnvelope {
+		return dst
+	}
+	for i, stride := 0, h.Envelope.layout().Stride(); i < stride; i++ {
+		byteOrder.PutUint64(buf[:], math.Float64bits(h.Bounds.Min(i)))
+		dst = append(dst, buf[:]...)
+		byteOrder.PutUint64(buf[:], math.Float64bits(h.Bounds.Max(i)))
+		dst = append(dst, buf[:]...)
+	}
+	return dst
+}
+
+// An Option sets an option for encoding or decoding. Options that do not
+// apply to an operation are ignored.
+type Option func(*options)
+
+type options struct {
+	byteOrder   binary.ByteOrder
+	envelope    EnvelopeType
+	envelopeSet bool
+	wkbOptions  []wkb.Option
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		byteOrder: binary.LittleEndian,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithByteOrder sets the byte order of encoded headers and WKB. The default
+// is little endian.
+func WithByteOrder(byteOrder binary.ByteOrder) Option {
+	return func(o *options) {
+		o.byteOrder = byteOrder
+	}
+}
+
+// WithEnvelope sets the type of envelope of encoded geometries. By default,
+// points, which are their own envelopes, and empty geometries have no
+// envelope and other geometries have an XY envelope. Empty geometries never
+// have an envelope.
+func WithEnvelope(envelope EnvelopeType) Option {
+	return func(o *options) {
+		o.envelope = envelope
+		o.envelopeSet = true
+	}
+}
+
+// WithWKBOptions sets the options used to decode the enclosed WKB, for
+// example wkb.WithLimits.
+func WithWKBOptions(opts ...wkb.Option) Option {
+	return func(o *options) {
+		o.wkbOptions = opts
+	}
+}
+
+// Append appends the GeoPackage binary encoding of g to dst and returns the
+// extended buffer. Empty points are encoded with NaN ordinates, as required
+// by the specification.
+func Append(dst []byte, g geom.T, opts ...Option) ([]byte, error) {
+	o := newOptions(opts)
+	h := &Header{
+		ByteOrder: o.byteOrder,
+		Empty:     isEmpty(g),
+		SRID:      g.SRID(),
+	}
+	switch {
+	case h.Empty:
+	case o.envelopeSet:
+		h.Envelope = o.envelope
+	default:
+		if _, ok := g.(*geom.Point); !ok {
+			h.Envelope = EnvelopeXY
+		}
+	}
+	if h.Envelope != NoEnvelope {
+		var err error
+		if h.Bounds, err = envelope(g, h.Envelope); err != nil {
+			return nil, err
+		}
+	}
+	if p, ok := g.(*geom.Point); ok && p.Empty() {
+		flatCoords := make([]float64, p.Stride())
+		for i := range flatCoords {
+			flatCoords[i] = quietNaN
+		}
+		g = geom.NewPointFlat(p.Layout(), flatCoords)
+	}
+	data, err := wkb.Marshal(g, o.byteOrder)
+	if err != nil {
+		return nil, err
+	}
+	return append(AppendHeader(dst, h), data...), nil
+}
+
+// Marshal returns the GeoPackage binary encoding of g.
+func Marshal(g geom.T, opts ...Option) ([]byte, error) {
+	return Append(nil, g, opts...)
+}
+
+// Unmarshal decodes the GeoPackage binary geometry in data. The SRID of the
+// geometry is set from the header and points with NaN ordinates in headers
+// with the empty flag are decoded as empty points. Extended geometries are
+// not supported.
+func Unmarshal(data []byte, opts ...Option) (geom.T, error) {
+	o := newOptions(opts)
+	h, data, err := DecodeHeader(data)
+	if err != nil {
+		return nil, err
+	}
+	if h.Extended {
+		return nil, errExtended
+	}
+	g, err := wkb.Unmarshal(data, o.wkbOptions...)
+	if err != nil {
+		return nil, err
+	}
+	if p, ok := g.(*geom.Point); ok && h.Empty && isNaN(p.FlatCoords()) {
+		g = geom.NewPointEmpty(p.Layout())
+	}
+	setSRID(g, h.SRID)
+	return g, nil
+}
+
+// UnmarshalStub returns the Stub of the GeoPackage binary geometry in data
+// without decoding its coordinates.
+func UnmarshalStub(data []byte, opts ...Option) (*geom.Stub, error) {
+	o := newOptions(opts)
+	h, data, err := DecodeHeader(data)
+	if err != nil {
+		return nil, err
+	}
+	if h.Extended {
+		return nil, errExtended
+	}
+	stub, err := wkb.UnmarshalStub(data, o.wkbOptions...)
+	if err != nil {
+		return nil, err
+	}
+	stub.SRID = h.SRID
+	return stub, nil
+}
+
+// envelope returns the envelope of g of type t.
+func envelope(g geom.T, t EnvelopeType) (*geom.Bounds, error) {
+	layout := t.layout()
+	gLayout := g.Layout()
+	if layout == geom.NoLayout ||
+		layout.ZIndex() != -1 && gLayout.ZIndex() == -1 ||
+		layout.MIndex() != -1 && gLayout.MIndex() == -1 {
+		return nil, ErrUnsupportedEnvelope(t)
+	}
+	b := extend(geom.NewBounds(gLayout), g)
+	min := []float64{b.Min(0), b.Min(1)}
+	max := []float64{b.Max(0), b.Max(1)}
+	if layout.ZIndex() != -1 {
+		min = append(min, b.Min(gLayout.ZIndex()))
+		max = append(max, b.Max(gLayout.ZIndex()))
+	}
+	if layout.MIndex() != -1 {
+		min = append(min, b.Min(gLayout.MIndex()))
+		max = append(max, b.Max(gLayout.MIndex()))
+	}
+	return geom.NewBounds(layout).Set(append(min, max...)...), nil
+}
+
+// extend extends b to include g, recursing into GeometryCollections.
+func extend(b *geom.Bounds, g geom.T) *geom.Bounds {
+	if gc, ok := g.(*geom.GeometryCollection); ok {
+		for _, member := range gc.Geoms() {
+			extend(b, member)
+		}
+		return b
+	}
+	return b.Extend(g)
+}
+
+// isEmpty returns whether g is empty.
+func isEmpty(g geom.T) bool {
+	if e, ok := g.(interface{ Empty() bool }); ok {
+		return e.Empty()
+	}
+	return false
+}
+
+// isNaN returns whether all of flatCoords are NaN.
+func isNaN(flatCoords []float64) bool {
+	for _, x := range flatCoords {
+		if !math.IsNaN(x) {
+			return false
+		}
+	}
+	return true
+}
+
+// setSRID sets the SRID of g.
+func setSRID(g geom.T, srid int) {
+	switch g := g.(type) {
+	case *geom.Point:
+		g.SetSRID(srid)
+	case *geom.LineString:
+		g.SetSRID(srid)
+	case *geom.Polygon:
+		g.SetSRID(srid)
+	case *geom.MultiPoint:
+		g.SetSRID(srid)
+	case *geom.MultiLineString:
+		g.SetSRID(srid)
+	case *geom.MultiPolygon:
+		g.SetSRID(srid)
+	case *geom.GeometryCollection:
+		g.SetSRID(srid)
+	case *geom.CircularString:
+		g.SetSRID(srid)
+	case *geom.CompoundCurve:
+		g.SetSRID(srid)
+	case *geom.CurvePolygon:
+		g.SetSRID(srid)
+	case *geom.Triangle:
+		g.SetSRID(srid)
+	case *geom.PolyhedralSurface:
+		g.SetSRID(srid)
+	case *geom.TIN:
+		g.SetSRID(srid)
+	}
+}
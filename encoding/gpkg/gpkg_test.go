@@ -0,0 +1,203 @@
+package gpkg
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"reflect"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/conformance"
+	"github.com/twpayne/go-geom/internal/testdata"
+)
+
+func TestMarshal(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		g    geom.T
+		opts []Option
+		want string
+	}{
+		{
+			name: "point",
+			g:    geom.NewPointFlat(geom.XY, []float64{1, 2}).SetSRID(4326),
+			want: "47500001e6100000" + "0101000000000000000000f03f0000000000000040",
+		},
+		{
+			name: "point_big_endian",
+			g:    geom.NewPointFlat(geom.XY, []float64{1, 2}).SetSRID(4326),
+			opts: []Option{WithByteOrder(binary.BigEndian)},
+			want: "47500000000010e6" + "00000000013ff00000000000004000000000000000",
+		},
+		{
+			name: "point_empty",
+			g:    geom.NewPointEmpty(geom.XY),
+			want: "4750001100000000" + "0101000000000000000000f87f000000000000f87f",
+		},
+		{
+			name: "line_string",
+			g:    geom.NewLineStringFlat(geom.XY, []float64{1, 2, 3, 4}),
+			want: "4750000300000000" +
+				"000000000000f03f" + "0000000000000840" + "0000000000000040" + "0000000000001040" +
+				"010200000002000000" + "000000000000f03f" + "0000000000000040" + "0000000000000840" + "0000000000001040",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Marshal(tc.g, tc.opts...)
+			if err != nil {
+				t.Fatalf("Marshal(...) == _, %v, want _, <nil>", err)
+			}
+			if hex.EncodeToString(got) != tc.want {
+				t.Errorf("Marshal(...) == %x, _, want %s, _", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDecodeHeader(t *testing.T) {
+	data, err := Marshal(geom.NewLineStringFlat(geom.XYZM, []float64{1, 2, 3, 4, 5, 6, 7, 8}).SetSRID(3857), WithEnvelope(EnvelopeXYM), WithByteOrder(binary.BigEndian))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, rest, err := DecodeHeader(data)
+	if err != nil {
+		t.Fatalf("DecodeHeader(...) == _, _, %v, want _, _, <nil>", err)
+	}
+	want := &Header{
+		ByteOrder: binary.BigEndian,
+		SRID:      3857,
+		Envelope:  EnvelopeXYM,
+		Bounds:    geom.NewBounds(geom.XYM).Set(1, 2, 4, 5, 6, 8),
+	}
+	if !reflect.DeepEqual(h, want) {
+		t.Errorf("DecodeHeader(...) == %+v, _, _, want %+v, _, _", h, want)
+	}
+	if got, wantLen := len(rest), len(data)-headerSize-48; got != wantLen {
+		t.Errorf("len(rest) == %d, want %d", got, wantLen)
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	for _, g := range []geom.T{
+		geom.NewPointFlat(geom.XYZ, []float64{1, 2, 3}).SetSRID(4326),
+		geom.NewPointEmpty(geom.XYM),
+		geom.NewPolygonFlat(geom.XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}, []int{8}).SetSRID(-1),
+		geom.NewMultiPoint(geom.XY).SetSRID(4326),
+		geom.NewGeometryCollection().MustPush(
+			geom.NewPointFlat(geom.XY, []float64{1, 2}),
+			geom.NewGeometryCollection().MustPush(geom.NewPointFlat(geom.XY, []float64{3, 4})),
+		),
+	} {
+		data, err := Marshal(g)
+		if err != nil {
+			t.Fatalf("Marshal(%#v) == _, %v, want _, <nil>", g, err)
+		}
+		got, err := Unmarshal(data)
+		if err != nil {
+			t.Fatalf("Unmarshal(%x) == _, %v, want _, <nil>", data, err)
+		}
+		if !reflect.DeepEqual(got, g) {
+			t.Errorf("Unmarshal(%x) == %#v, _, want %#v, _", data, got, g)
+		}
+		stub, err := UnmarshalStub(data)
+		if err != nil {
+			t.Fatalf("UnmarshalStub(%x) == _, %v, want _, <nil>", data, err)
+		}
+		if stub.SRID != g.SRID() {
+			t.Errorf("UnmarshalStub(%x).SRID == %d, want %d", data, stub.SRID, g.SRID())
+		}
+	}
+}
+
+func TestCurvesAndSurfaces(t *testing.T) {
+	for _, layout := range []geom.Layout{geom.XY, geom.XYZ, geom.XYM, geom.XYZM} {
+		for i, g := range testdata.CurvesAndSurfaces(layout) {
+			setSRID(g, 4326)
+			data, err := Marshal(g)
+			if err != nil {
+				t.Fatalf("%s: %d: Marshal(...) == _, %v, want _, <nil>", layout, i, err)
+			}
+			got, err := Unmarshal(data)
+			if err != nil {
+				t.Fatalf("%s: %d: Unmarshal(...) == _, %v, want _, <nil>", layout, i, err)
+			}
+			if !reflect.DeepEqual(got, g) {
+				t.Errorf("%s: %d: Unmarshal(...) == %#v, _, want %#v, _", layout, i, got, g)
+			}
+			stub, err := UnmarshalStub(data)
+			if err != nil {
+				t.Fatalf("%s: %d: UnmarshalStub(...) == _, %v, want _, <nil>", layout, i, err)
+			}
+			want, err := geom.NewStub(g)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(stub, want) {
+				t.Errorf("%s: %d: UnmarshalStub(...) == %+v, _, want %+v, _", layout, i, stub, want)
+			}
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		data string
+		want error
+	}{
+		{
+			name: "truncated",
+			data: "4750",
+			want: errTruncated,
+		},
+		{
+			name: "invalid_magic",
+			data: "0101000000000000",
+			want: ErrInvalidMagic{0x01, 0x01},
+		},
+		{
+			name: "unsupported_version",
+			data: "4750010100000000",
+			want: ErrUnsupportedVersion(1),
+		},
+		{
+			name: "unsupported_envelope",
+			data: "4750000b00000000",
+			want: ErrUnsupportedEnvelope(5),
+		},
+		{
+			name: "truncated_envelope",
+			data: "4750000300000000000000000000f03f",
+			want: errTruncated,
+		},
+		{
+			name: "extended",
+			data: "4750002100000000",
+			want: errExtended,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := hex.DecodeString(tc.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := Unmarshal(data); err != tc.want {
+				t.Errorf("Unmarshal(%s) == _, %v, want _, %v", tc.data, err, tc.want)
+			}
+		})
+	}
+	if _, err := Marshal(geom.NewPointFlat(geom.XY, []float64{1, 2}), WithEnvelope(EnvelopeXYZ)); err != ErrUnsupportedEnvelope(EnvelopeXYZ) {
+		t.Errorf("Marshal(...) == _, %v, want _, %v", err, ErrUnsupportedEnvelope(EnvelopeXYZ))
+	}
+}
+
+func TestConformance(t *testing.T) {
+	conformance.Run(t, conformance.CodecFuncs{
+		MarshalFunc: func(g geom.T) ([]byte, error) {
+			return Marshal(g)
+		},
+		UnmarshalFunc: func(data []byte) (geom.T, error) {
+			return Unmarshal(data)
+		},
+	})
+}
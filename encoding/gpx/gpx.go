@@ -0,0 +1,242 @@
+// Package gpx implements GPX 1.1 encoding and decoding of geometries. See
+// https://www.topografix.com/GPX/1/1/.
+//
+// Waypoints are decoded as Points, routes as LineStrings, and tracks as
+// MultiLineStrings with one LineString per track segment. Elevations are
+// decoded as Z values, and, optionally, times as M values in seconds since
+// the Unix epoch, as in package igc. Other GPX elements, such as names and
+// extensions, are ignored.
+package gpx
+
+import (
+	"encoding/xml"
+	"io"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/twpayne/go-geom"
+)
+
+// Namespace is the GPX 1.1 namespace.
+const Namespace = "http://www.topografix.com/GPX/1/1"
+
+// A T represents the geometries of a GPX document.
+type T struct {
+	Waypoints []*geom.Point
+	Routes    []*geom.LineString
+	Tracks    []*geom.MultiLineString
+}
+
+// An Option sets an option for decoding.
+type Option func(*options)
+
+type options struct {
+	time bool
+}
+
+// WithTime sets whether times are decoded as M values, in seconds since the
+// Unix epoch. Points without a time have an M value of NaN. By default times
+// are ignored.
+func WithTime(time bool) Option {
+	return func(o *options) {
+		o.time = time
+	}
+}
+
+// A decimal is a float64 that is encoded without an exponent, as required by
+// the GPX schema.
+type decimal float64
+
+func (d decimal) MarshalText() ([]byte, error) {
+	return strconv.AppendFloat(nil, float64(d), 'f', -1, 64), nil
+}
+
+func (d *decimal) UnmarshalText(text []byte) error {
+	f, err := strconv.ParseFloat(string(text), 64)
+	if err != nil {
+		return err
+	}
+	*d = decimal(f)
+	return nil
+}
+
+type gpxDocument struct {
+	XMLName   xml.Name   `xml:"gpx"`
+	Xmlns     string     `xml:"xmlns,attr,omitempty"`
+	Version   string     `xml:"version,attr"`
+	Creator   string     `xml:"creator,attr"`
+	Waypoints []gpxPoint `xml:"wpt"`
+	Routes    []gpxRoute `xml:"rte"`
+	Tracks    []gpxTrack `xml:"trk"`
+}
+
+type gpxPoint struct {
+	Lat  decimal    `xml:"lat,attr"`
+	Lon  decimal    `xml:"lon,attr"`
+	Ele  *decimal   `xml:"ele"`
+	Time *time.Time `xml:"time"`
+}
+
+type gpxRoute struct {
+	Points []gpxPoint `xml:"rtept"`
+}
+
+type gpxTrack struct {
+	Segments []gpxSegment `xml:"trkseg"`
+}
+
+type gpxSegment struct {
+	Points []gpxPoint `xml:"trkpt"`
+}
+
+// Read reads the geometries of the GPX document in r. Each geometry has the
+// XYZ or XYZM layout if any of its points has an elevation, with the
+// elevation of points without one being zero, and the XY or XYM layout
+// otherwise.
+func Read(r io.Reader, opts ...Option) (*T, error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	var doc gpxDocument
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+	t := &T{}
+	for _, wpt := range doc.Waypoints {
+		layout := o.layout(wpt)
+		t.Waypoints = append(t.Waypoints, geom.NewPointFlat(layout, o.appendFlatCoords(nil, layout, wpt)))
+	}
+	for _, rte := range doc.Routes {
+		layout := o.layout(rte.Points...)
+		var flatCoords []float64
+		for _, rtept := range rte.Points {
+			flatCoords = o.appendFlatCoords(flatCoords, layout, rtept)
+		}
+		t.Routes = append(t.Routes, geom.NewLineStringFlat(layout, flatCoords))
+	}
+	for _, trk := range doc.Tracks {
+		var trkpts []gpxPoint
+		for _, trkseg := range trk.Segments {
+			trkpts = append(trkpts, trkseg.Points...)
+		}
+		layout := o.layout(trkpts...)
+		var flatCoords []float64
+		ends := make([]int, 0, len(trk.Segments))
+		for _, trkseg := range trk.Segments {
+			for _, trkpt := range trkseg.Points {
+				flatCoords = o.appendFlatCoords(flatCoords, layout, trkpt)
+			}
+			ends = append(ends, len(flatCoords))
+		}
+		t.Tracks = append(t.Tracks, geom.NewMultiLineStringFlat(layout, flatCoords, ends))
+	}
+	return t, nil
+}
+
+// layout returns the layout of a geometry with points.
+func (o options) layout(points ...gpxPoint) geom.Layout {
+	hasEle := false
+	for _, point := range points {
+		if point.Ele != nil {
+			hasEle = true
+			break
+		}
+	}
+	switch {
+	case hasEle && o.time:
+		return geom.XYZM
+	case hasEle:
+		return geom.XYZ
+	case o.time:
+		return geom.XYM
+	default:
+		return geom.XY
+	}
+}
+
+// appendFlatCoords appends the coordinates of point in layout to flatCoords.
+func (o options) appendFlatCoords(flatCoords []float64, layout geom.Layout, point gpxPoint) []float64 {
+	flatCoords = append(flatCoords, float64(point.Lon), float64(point.Lat))
+	if layout.ZIndex() != -1 {
+		ele := 0.0
+		if point.Ele != nil {
+			ele = float64(*point.Ele)
+		}
+		flatCoords = append(flatCoords, ele)
+	}
+	if layout.MIndex() != -1 {
+		m := math.NaN()
+		if point.Time != nil {
+			m = float64(point.Time.Unix()) + float64(point.Time.Nanosecond())/1e9
+		}
+		flatCoords = append(flatCoords, m)
+	}
+	return flatCoords
+}
+
+// Write writes t to w as a GPX 1.1 document. Z values are written as
+// elevations and M values as times, in seconds since the Unix epoch. Times
+// that are NaN and empty waypoints are omitted.
+func Write(w io.Writer, t *T) error {
+	doc := gpxDocument{
+		Xmlns:   Namespace,
+		Version: "1.1",
+		Creator: "go-geom",
+	}
+	for _, p := range t.Waypoints {
+		if !p.Empty() {
+			doc.Waypoints = append(doc.Waypoints, newGPXPoints(p.Layout(), p.FlatCoords())...)
+		}
+	}
+	for _, ls := range t.Routes {
+		doc.Routes = append(doc.Routes, gpxRoute{
+			Points: newGPXPoints(ls.Layout(), ls.FlatCoords()),
+		})
+	}
+	for _, mls := range t.Tracks {
+		trk := gpxTrack{
+			Segments: make([]gpxSegment, 0, mls.NumLineStrings()),
+		}
+		for i, n := 0, mls.NumLineStrings(); i < n; i++ {
+			ls := mls.LineString(i)
+			trk.Segments = append(trk.Segments, gpxSegment{
+				Points: newGPXPoints(ls.Layout(), ls.FlatCoords()),
+			})
+		}
+		doc.Tracks = append(doc.Tracks, trk)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	e := xml.NewEncoder(w)
+	if err := e.Encode(doc); err != nil {
+		return err
+	}
+	return e.Flush()
+}
+
+// newGPXPoints returns the GPX points of flatCoords in layout.
+func newGPXPoints(layout geom.Layout, flatCoords []float64) []gpxPoint {
+	stride := layout.Stride()
+	zIndex, mIndex := layout.ZIndex(), layout.MIndex()
+	points := make([]gpxPoint, 0, len(flatCoords)/stride)
+	for i := 0; i < len(flatCoords); i += stride {
+		point := gpxPoint{
+			Lat: decimal(flatCoords[i+1]),
+			Lon: decimal(flatCoords[i]),
+		}
+		if zIndex != -1 {
+			ele := decimal(flatCoords[i+zIndex])
+			point.Ele = &ele
+		}
+		if mIndex != -1 && !math.IsNaN(flatCoords[i+mIndex]) {
+			sec, frac := math.Modf(flatCoords[i+mIndex])
+			t := time.Unix(int64(sec), int64(math.Round(frac*1e9))).UTC()
+			point.Time = &t
+		}
+		points = append(points, point)
+	}
+	return points
+}
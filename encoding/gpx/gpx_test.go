@@ -0,0 +1,151 @@
+package gpx
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+)
+
+const testGPX = `<?xml version="1.0" encoding="UTF-8"?>
+<gpx xmlns="http://www.topografix.com/GPX/1/1" version="1.1" creator="test">
+  <wpt lat="46.57638889" lon="8.89263889">
+    <ele>2372</ele>
+    <name>LAGORETICO</name>
+  </wpt>
+  <wpt lat="46.57" lon="8.89"/>
+  <rte>
+    <rtept lat="1" lon="2"/>
+    <rtept lat="3" lon="4"/>
+  </rte>
+  <trk>
+    <name>Track</name>
+    <trkseg>
+      <trkpt lat="46.1" lon="8.1"><ele>100</ele><time>2020-01-02T03:04:05Z</time></trkpt>
+      <trkpt lat="46.2" lon="8.2"><ele>110.5</ele><time>2020-01-02T03:04:06.5Z</time></trkpt>
+    </trkseg>
+    <trkseg>
+      <trkpt lat="46.3" lon="8.3"></trkpt>
+    </trkseg>
+  </trk>
+</gpx>`
+
+func TestRead(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		opts []Option
+		want *T
+	}{
+		{
+			name: "default",
+			want: &T{
+				Waypoints: []*geom.Point{
+					geom.NewPointFlat(geom.XYZ, []float64{8.89263889, 46.57638889, 2372}),
+					geom.NewPointFlat(geom.XY, []float64{8.89, 46.57}),
+				},
+				Routes: []*geom.LineString{
+					geom.NewLineStringFlat(geom.XY, []float64{2, 1, 4, 3}),
+				},
+				Tracks: []*geom.MultiLineString{
+					geom.NewMultiLineStringFlat(geom.XYZ, []float64{8.1, 46.1, 100, 8.2, 46.2, 110.5, 8.3, 46.3, 0}, []int{6, 9}),
+				},
+			},
+		},
+		{
+			name: "time",
+			opts: []Option{WithTime(true)},
+			want: &T{
+				Waypoints: []*geom.Point{
+					geom.NewPointFlat(geom.XYZM, []float64{8.89263889, 46.57638889, 2372, math.NaN()}),
+					geom.NewPointFlat(geom.XYM, []float64{8.89, 46.57, math.NaN()}),
+				},
+				Routes: []*geom.LineString{
+					geom.NewLineStringFlat(geom.XYM, []float64{2, 1, math.NaN(), 4, 3, math.NaN()}),
+				},
+				Tracks: []*geom.MultiLineString{
+					geom.NewMultiLineStringFlat(geom.XYZM, []float64{
+						8.1, 46.1, 100, 1577934245,
+						8.2, 46.2, 110.5, 1577934246.5,
+						8.3, 46.3, 0, math.NaN(),
+					}, []int{8, 12}),
+				},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Read(strings.NewReader(testGPX), tc.opts...)
+			if err != nil {
+				t.Fatalf("Read(...) == %v, %v, want ..., nil", got, err)
+			}
+			if gotStr, wantStr := geomString(got), geomString(tc.want); gotStr != wantStr {
+				t.Errorf("Read(...) == %s, nil, want %s, nil", gotStr, wantStr)
+			}
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	tt := &T{
+		Waypoints: []*geom.Point{
+			geom.NewPointFlat(geom.XY, []float64{0.00001, -1}),
+		},
+		Tracks: []*geom.MultiLineString{
+			geom.NewMultiLineStringFlat(geom.XYZM, []float64{
+				8.1, 46.1, 100, 1577934245,
+				8.2, 46.2, 110.5, math.NaN(),
+			}, []int{8}),
+		},
+	}
+	b := &bytes.Buffer{}
+	if err := Write(b, tt); err != nil {
+		t.Fatalf("Write(...) == %v, want nil", err)
+	}
+	want := `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+		`<gpx xmlns="http://www.topografix.com/GPX/1/1" version="1.1" creator="go-geom">` +
+		`<wpt lat="-1" lon="0.00001"></wpt>` +
+		`<trk><trkseg>` +
+		`<trkpt lat="46.1" lon="8.1"><ele>100</ele><time>2020-01-02T03:04:05Z</time></trkpt>` +
+		`<trkpt lat="46.2" lon="8.2"><ele>110.5</ele></trkpt>` +
+		`</trkseg></trk>` +
+		`</gpx>`
+	if got := b.String(); got != want {
+		t.Errorf("Write(...) wrote\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	want, err := Read(strings.NewReader(testGPX), WithTime(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &bytes.Buffer{}
+	if err := Write(b, want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Read(b, WithTime(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotStr, wantStr := geomString(got), geomString(want); gotStr != wantStr {
+		t.Errorf("Read(Write(...)) == %s, want %s", gotStr, wantStr)
+	}
+}
+
+// geomString returns a string representation of the geometries of t, in
+// which NaNs compare equal.
+func geomString(t *T) string {
+	var gs []interface{}
+	for _, g := range t.Waypoints {
+		gs = append(gs, g.Layout(), g.FlatCoords())
+	}
+	for _, g := range t.Routes {
+		gs = append(gs, g.Layout(), g.FlatCoords())
+	}
+	for _, g := range t.Tracks {
+		gs = append(gs, g.Layout(), g.FlatCoords(), g.Ends())
+	}
+	return fmt.Sprint(gs...)
+}
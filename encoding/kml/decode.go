@@ -0,0 +1,274 @@
+package kml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/twpayne/go-geom"
+)
+
+// An ErrUnsupportedElement is returned when decoding an element that is not a
+// KML geometry.
+type ErrUnsupportedElement string
+
+func (e ErrUnsupportedElement) Error() string {
+	return fmt.Sprintf("kml: unsupported element: %s", string(e))
+}
+
+// An ErrInvalidCoordinates is returned when decoding malformed coordinates.
+type ErrInvalidCoordinates string
+
+func (e ErrInvalidCoordinates) Error() string {
+	return fmt.Sprintf("kml: invalid coordinates: %q", string(e))
+}
+
+// An element is a generic XML element.
+type element struct {
+	XMLName  xml.Name
+	Children []element `xml:",any"`
+	Text     string    `xml:",chardata"`
+}
+
+// children returns the children of e called name.
+func (e *element) children(name string) []*element {
+	var children []*element
+	for i := range e.Children {
+		if e.Children[i].XMLName.Local == name {
+			children = append(children, &e.Children[i])
+		}
+	}
+	return children
+}
+
+// text returns the text of the first child of e called name.
+func (e *element) text(name string) string {
+	if children := e.children(name); len(children) > 0 {
+		return strings.TrimSpace(children[0].Text)
+	}
+	return ""
+}
+
+// Unmarshal decodes the KML geometry element in data, which must be a Point,
+// LineString, LinearRing, Polygon, or MultiGeometry. MultiGeometries whose
+// members are all Points, all LineStrings, or all Polygons are decoded as
+// MultiPoints, MultiLineStrings, and MultiPolygons, and other MultiGeometries
+// as GeometryCollections. Geometries have the XYZ layout if any of their
+// coordinates has an altitude and the XY layout otherwise. Elements are
+// matched by their local names, so namespace prefixes are ignored.
+func Unmarshal(data []byte, opts ...Option) (geom.T, error) {
+	var e element
+	if err := xml.Unmarshal(data, &e); err != nil {
+		return nil, err
+	}
+	return decode(&e, newOptions(opts))
+}
+
+// UnmarshalStub returns the Stub of the KML geometry element in data.
+func UnmarshalStub(data []byte, opts ...Option) (*geom.Stub, error) {
+	g, err := Unmarshal(data, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return geom.NewStub(g)
+}
+
+// decode decodes the geometry element e.
+func decode(e *element, o options) (geom.T, error) {
+	clamp := o.clampToGround && isClamped(e.text("altitudeMode"))
+	switch e.XMLName.Local {
+	case "Point":
+		flatCoords, layout, err := decodeCoordinates(e, clamp)
+		switch {
+		case err != nil:
+			return nil, err
+		case len(flatCoords) == 0:
+			return geom.NewPointEmpty(layout), nil
+		case len(flatCoords) != layout.Stride():
+			return nil, ErrInvalidCoordinates(e.text("coordinates"))
+		}
+		return geom.NewPointFlat(layout, flatCoords), nil
+	case "LineString":
+		flatCoords, layout, err := decodeCoordinates(e, clamp)
+		if err != nil {
+			return nil, err
+		}
+		return geom.NewLineStringFlat(layout, flatCoords), nil
+	case "LinearRing":
+		flatCoords, layout, err := decodeCoordinates(e, clamp)
+		if err != nil {
+			return nil, err
+		}
+		return geom.NewLinearRingFlat(layout, flatCoords), nil
+	case "Polygon":
+		return decodePolygon(e, clamp)
+	case "MultiGeometry":
+		members := make([]geom.T, 0, len(e.Children))
+		for i := range e.Children {
+			member, err := decode(&e.Children[i], o)
+			if err != nil {
+				return nil, err
+			}
+			members = append(members, member)
+		}
+		return multi(members), nil
+	default:
+		return nil, ErrUnsupportedElement(e.XMLName.Local)
+	}
+}
+
+// decodePolygon decodes the Polygon element e.
+func decodePolygon(e *element, clamp bool) (geom.T, error) {
+	var rings []*element
+	for _, name := range []string{"outerBoundaryIs", "innerBoundaryIs"} {
+		for _, boundary := range e.children(name) {
+			rings = append(rings, boundary.children("LinearRing")...)
+		}
+	}
+	ringsFlatCoords := make([][]float64, len(rings))
+	ringLayouts := make([]geom.Layout, len(rings))
+	layout := geom.XY
+	for i, ring := range rings {
+		var err error
+		if ringsFlatCoords[i], ringLayouts[i], err = decodeCoordinates(ring, clamp); err != nil {
+			return nil, err
+		}
+		if ringLayouts[i] == geom.XYZ {
+			layout = geom.XYZ
+		}
+	}
+	var flatCoords []float64
+	ends := make([]int, 0, len(rings))
+	for i, ringFlatCoords := range ringsFlatCoords {
+		if ringLayouts[i] != layout {
+			ringFlatCoords = addAltitudes(ringFlatCoords)
+		}
+		flatCoords = append(flatCoords, ringFlatCoords...)
+		ends = append(ends, len(flatCoords))
+	}
+	return geom.NewPolygonFlat(layout, flatCoords, ends), nil
+}
+
+// decodeCoordinates decodes the coordinates child of e, dropping altitudes
+// if clamp is true.
+func decodeCoordinates(e *element, clamp bool) ([]float64, geom.Layout, error) {
+	s := e.text("coordinates")
+	tuples := strings.Fields(s)
+	flatCoords := make([]float64, 0, 3*len(tuples))
+	hasAltitude := false
+	for _, tuple := range tuples {
+		fields := strings.Split(tuple, ",")
+		if len(fields) != 2 && len(fields) != 3 {
+			return nil, geom.NoLayout, ErrInvalidCoordinates(s)
+		}
+		var coord [3]float64
+		for i, field := range fields {
+			f, err := strconv.ParseFloat(field, 64)
+			if err != nil {
+				return nil, geom.NoLayout, ErrInvalidCoordinates(s)
+			}
+			coord[i] = f
+		}
+		if len(fields) == 3 {
+			hasAltitude = true
+		}
+		flatCoords = append(flatCoords, coord[:]...)
+	}
+	if hasAltitude && !clamp {
+		return flatCoords, geom.XYZ, nil
+	}
+	xyFlatCoords := flatCoords[:0]
+	for i := 0; i < len(flatCoords); i += 3 {
+		xyFlatCoords = append(xyFlatCoords, flatCoords[i], flatCoords[i+1])
+	}
+	return xyFlatCoords, geom.XY, nil
+}
+
+// multi returns members as a MultiPoint, MultiLineString, or MultiPolygon if
+// they are all of the corresponding type, and as a GeometryCollection
+// otherwise.
+func multi(members []geom.T) geom.T {
+	if len(members) == 0 {
+		return geom.NewGeometryCollection()
+	}
+	layout := geom.XY
+	for _, member := range members {
+		if member.Layout() == geom.XYZ {
+			layout = geom.XYZ
+		}
+	}
+	var flatCoords []float64
+	switch members[0].(type) {
+	case *geom.Point:
+		for _, member := range members {
+			point, ok := member.(*geom.Point)
+			if !ok || point.Empty() {
+				return collection(members)
+			}
+			flatCoords = append(flatCoords, memberFlatCoords(member, layout)...)
+		}
+		return geom.NewMultiPointFlat(layout, flatCoords)
+	case *geom.LineString:
+		ends := make([]int, 0, len(members))
+		for _, member := range members {
+			if _, ok := member.(*geom.LineString); !ok {
+				return collection(members)
+			}
+			flatCoords = append(flatCoords, memberFlatCoords(member, layout)...)
+			ends = append(ends, len(flatCoords))
+		}
+		return geom.NewMultiLineStringFlat(layout, flatCoords, ends)
+	case *geom.Polygon:
+		endss := make([][]int, 0, len(members))
+		for _, member := range members {
+			polygon, ok := member.(*geom.Polygon)
+			if !ok {
+				return collection(members)
+			}
+			offset := len(flatCoords)
+			ends := make([]int, len(polygon.Ends()))
+			for i, end := range polygon.Ends() {
+				ends[i] = offset + end/polygon.Stride()*layout.Stride()
+			}
+			flatCoords = append(flatCoords, memberFlatCoords(member, layout)...)
+			endss = append(endss, ends)
+		}
+		return geom.NewMultiPolygonFlat(layout, flatCoords, endss)
+	default:
+		return collection(members)
+	}
+}
+
+// memberFlatCoords returns the flat coordinates of member in layout.
+func memberFlatCoords(member geom.T, layout geom.Layout) []float64 {
+	if member.Layout() != layout {
+		return addAltitudes(member.FlatCoords())
+	}
+	return member.FlatCoords()
+}
+
+func collection(members []geom.T) *geom.GeometryCollection {
+	return geom.NewGeometryCollection().MustPush(members...)
+}
+
+// addAltitudes returns the XY flatCoords as XYZ flat coordinates with zero
+// altitudes.
+func addAltitudes(flatCoords []float64) []float64 {
+	xyzFlatCoords := make([]float64, 0, len(flatCoords)/2*3)
+	for i := 0; i < len(flatCoords); i += 2 {
+		xyzFlatCoords = append(xyzFlatCoords, flatCoords[i], flatCoords[i+1], 0)
+	}
+	return xyzFlatCoords
+}
+
+// isClamped returns whether altitudeMode clamps geometries to the ground or
+// sea floor.
+func isClamped(altitudeMode string) bool {
+	switch altitudeMode {
+	case "", "clampToGround", "clampToSeaFloor":
+		return true
+	default:
+		return false
+	}
+}
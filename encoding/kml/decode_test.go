@@ -0,0 +1,183 @@
+package kml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"reflect"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+)
+
+func TestUnmarshal(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		data string
+		opts []Option
+		want geom.T
+	}{
+		{
+			name: "point",
+			data: `<Point><coordinates>1,2</coordinates></Point>`,
+			want: geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1, 2}),
+		},
+		{
+			name: "point_xyz",
+			data: `<Point><coordinates> 1,2,3 </coordinates></Point>`,
+			want: geom.NewPoint(geom.XYZ).MustSetCoords(geom.Coord{1, 2, 3}),
+		},
+		{
+			name: "namespace",
+			data: `<kml:Point xmlns:kml="http://www.opengis.net/kml/2.2"><kml:coordinates>1,2</kml:coordinates></kml:Point>`,
+			want: geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1, 2}),
+		},
+		{
+			name: "line_string",
+			data: `<LineString><tessellate>1</tessellate><coordinates>` + "\n\t1,2\n\t3,4,5\n" + `</coordinates></LineString>`,
+			want: geom.NewLineString(geom.XYZ).MustSetCoords([]geom.Coord{{1, 2, 0}, {3, 4, 5}}),
+		},
+		{
+			name: "linear_ring",
+			data: `<LinearRing><coordinates>0,0 1,0 1,1 0,0</coordinates></LinearRing>`,
+			want: geom.NewLinearRing(geom.XY).MustSetCoords([]geom.Coord{{0, 0}, {1, 0}, {1, 1}, {0, 0}}),
+		},
+		{
+			name: "polygon",
+			data: `<Polygon>` +
+				`<outerBoundaryIs><LinearRing><coordinates>0,0 3,0 3,3 0,0</coordinates></LinearRing></outerBoundaryIs>` +
+				`<innerBoundaryIs><LinearRing><coordinates>1,1,1 2,1,1 2,2,1 1,1,1</coordinates></LinearRing></innerBoundaryIs>` +
+				`</Polygon>`,
+			want: geom.NewPolygon(geom.XYZ).MustSetCoords([][]geom.Coord{
+				{{0, 0, 0}, {3, 0, 0}, {3, 3, 0}, {0, 0, 0}},
+				{{1, 1, 1}, {2, 1, 1}, {2, 2, 1}, {1, 1, 1}},
+			}),
+		},
+		{
+			name: "clamp_to_ground",
+			data: `<Point><coordinates>1,2,3</coordinates></Point>`,
+			opts: []Option{WithClampToGround(true)},
+			want: geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1, 2}),
+		},
+		{
+			name: "clamp_to_ground_absolute",
+			data: `<Point><altitudeMode>absolute</altitudeMode><coordinates>1,2,3</coordinates></Point>`,
+			opts: []Option{WithClampToGround(true)},
+			want: geom.NewPoint(geom.XYZ).MustSetCoords(geom.Coord{1, 2, 3}),
+		},
+		{
+			name: "multi_point",
+			data: `<MultiGeometry>` +
+				`<Point><coordinates>1,2</coordinates></Point>` +
+				`<Point><coordinates>3,4,5</coordinates></Point>` +
+				`</MultiGeometry>`,
+			want: geom.NewMultiPoint(geom.XYZ).MustSetCoords([]geom.Coord{{1, 2, 0}, {3, 4, 5}}),
+		},
+		{
+			name: "multi_line_string",
+			data: `<MultiGeometry>` +
+				`<LineString><coordinates>1,2 3,4</coordinates></LineString>` +
+				`<LineString><coordinates>5,6 7,8</coordinates></LineString>` +
+				`</MultiGeometry>`,
+			want: geom.NewMultiLineString(geom.XY).MustSetCoords([][]geom.Coord{
+				{{1, 2}, {3, 4}},
+				{{5, 6}, {7, 8}},
+			}),
+		},
+		{
+			name: "multi_polygon",
+			data: `<MultiGeometry>` +
+				`<Polygon><outerBoundaryIs><LinearRing><coordinates>0,0 1,0 1,1 0,0</coordinates></LinearRing></outerBoundaryIs></Polygon>` +
+				`<Polygon><outerBoundaryIs><LinearRing><coordinates>2,2,1 3,2,1 3,3,1 2,2,1</coordinates></LinearRing></outerBoundaryIs></Polygon>` +
+				`</MultiGeometry>`,
+			want: geom.NewMultiPolygon(geom.XYZ).MustSetCoords([][][]geom.Coord{
+				{{{0, 0, 0}, {1, 0, 0}, {1, 1, 0}, {0, 0, 0}}},
+				{{{2, 2, 1}, {3, 2, 1}, {3, 3, 1}, {2, 2, 1}}},
+			}),
+		},
+		{
+			name: "geometry_collection",
+			data: `<MultiGeometry>` +
+				`<Point><coordinates>1,2</coordinates></Point>` +
+				`<LineString><coordinates>3,4 5,6</coordinates></LineString>` +
+				`</MultiGeometry>`,
+			want: geom.NewGeometryCollection().MustPush(
+				geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1, 2}),
+				geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{{3, 4}, {5, 6}}),
+			),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Unmarshal([]byte(tc.data), tc.opts...)
+			if err != nil {
+				t.Fatalf("Unmarshal(%q) == %v, %v, want ..., nil", tc.data, got, err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Unmarshal(%q) == %#v, nil, want %#v, nil", tc.data, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	for _, tc := range []struct {
+		data string
+		want error
+	}{
+		{
+			data: `<Placemark><name>x</name></Placemark>`,
+			want: ErrUnsupportedElement("Placemark"),
+		},
+		{
+			data: `<MultiGeometry><Model></Model></MultiGeometry>`,
+			want: ErrUnsupportedElement("Model"),
+		},
+		{
+			data: `<Point><coordinates>1</coordinates></Point>`,
+			want: ErrInvalidCoordinates("1"),
+		},
+		{
+			data: `<Point><coordinates>1,2 3,4</coordinates></Point>`,
+			want: ErrInvalidCoordinates("1,2 3,4"),
+		},
+		{
+			data: `<LineString><coordinates>1,x 3,4</coordinates></LineString>`,
+			want: ErrInvalidCoordinates("1,x 3,4"),
+		},
+	} {
+		if got, err := Unmarshal([]byte(tc.data)); err != tc.want {
+			t.Errorf("Unmarshal(%q) == %v, %v, want nil, %v", tc.data, got, err, tc.want)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, g := range []geom.T{
+		geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1, 2}),
+		geom.NewLineString(geom.XYZ).MustSetCoords([]geom.Coord{{1, 2, 3}, {4, 5, 6}}),
+		geom.NewPolygon(geom.XY).MustSetCoords([][]geom.Coord{
+			{{0, 0}, {3, 0}, {3, 3}, {0, 0}},
+			{{1, 1}, {2, 1}, {2, 2}, {1, 1}},
+		}),
+		geom.NewMultiLineString(geom.XYZ).MustSetCoords([][]geom.Coord{
+			{{1, 2, 3}, {4, 5, 6}},
+			{{7, 8, 9}, {10, 11, 12}},
+		}),
+	} {
+		element, err := Encode(g, WithAltitudeMode("absolute"), WithExtrude(true))
+		if err != nil {
+			t.Fatalf("Encode(%#v, ...) == %v, %v, want ..., nil", g, element, err)
+		}
+		b := &bytes.Buffer{}
+		if err := xml.NewEncoder(b).Encode(element); err != nil {
+			t.Fatalf("Encode(%#v) == %v, want nil", element, err)
+		}
+		got, err := Unmarshal(b.Bytes(), WithClampToGround(true))
+		if err != nil {
+			t.Errorf("Unmarshal(%q) == %v, %v, want ..., nil", b.String(), got, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, g) {
+			t.Errorf("Unmarshal(%q) == %#v, nil, want %#v, nil", b.String(), got, g)
+		}
+	}
+}
@@ -1,4 +1,4 @@
-// Package kml implements KML encoding.
+// Package kml implements KML encoding and decoding of geometries.
 package kml
 
 import (
@@ -6,44 +6,49 @@ import (
 	"github.com/twpayne/go-kml"
 )
 
-// Encode encodes an arbitrary geometry.
-func Encode(g geom.T) (kml.Element, error) {
+// Encode encodes an arbitrary geometry. Options such as WithExtrude and
+// WithAltitudeMode apply to each Point, LineString, LinearRing, and Polygon
+// element.
+func Encode(g geom.T, opts ...Option) (kml.Element, error) {
 	switch g := g.(type) {
 	case *geom.Point:
-		return EncodePoint(g), nil
+		return EncodePoint(g, opts...), nil
 	case *geom.LineString:
-		return EncodeLineString(g), nil
+		return EncodeLineString(g, opts...), nil
 	case *geom.LinearRing:
-		return EncodeLinearRing(g), nil
+		return EncodeLinearRing(g, opts...), nil
 	case *geom.MultiLineString:
-		return EncodeMultiLineString(g), nil
+		return EncodeMultiLineString(g, opts...), nil
 	case *geom.MultiPoint:
-		return EncodeMultiPoint(g), nil
+		return EncodeMultiPoint(g, opts...), nil
 	case *geom.MultiPolygon:
-		return EncodeMultiPolygon(g), nil
+		return EncodeMultiPolygon(g, opts...), nil
 	case *geom.Polygon:
-		return EncodePolygon(g), nil
+		return EncodePolygon(g, opts...), nil
 	case *geom.GeometryCollection:
-		return EncodeGeometryCollection(g)
+		return EncodeGeometryCollection(g, opts...)
 	default:
 		return nil, geom.ErrUnsupportedType{Value: g}
 	}
 }
 
 // EncodeLineString encodes a LineString.
-func EncodeLineString(ls *geom.LineString) kml.Element {
+func EncodeLineString(ls *geom.LineString, opts ...Option) kml.Element {
+	o := newOptions(opts)
 	flatCoords := ls.FlatCoords()
-	return kml.LineString(kml.CoordinatesFlat(flatCoords, 0, len(flatCoords), ls.Stride(), dim(ls.Layout())))
+	return kml.LineString(o.children(true, kml.CoordinatesFlat(flatCoords, 0, len(flatCoords), ls.Stride(), dim(ls.Layout())))...)
 }
 
 // EncodeLinearRing encodes a LinearRing.
-func EncodeLinearRing(lr *geom.LinearRing) kml.Element {
+func EncodeLinearRing(lr *geom.LinearRing, opts ...Option) kml.Element {
+	o := newOptions(opts)
 	flatCoords := lr.FlatCoords()
-	return kml.LinearRing(kml.CoordinatesFlat(flatCoords, 0, len(flatCoords), lr.Stride(), dim(lr.Layout())))
+	return kml.LinearRing(o.children(true, kml.CoordinatesFlat(flatCoords, 0, len(flatCoords), lr.Stride(), dim(lr.Layout())))...)
 }
 
 // EncodeMultiLineString encodes a MultiLineString.
-func EncodeMultiLineString(mls *geom.MultiLineString) kml.Element {
+func EncodeMultiLineString(mls *geom.MultiLineString, opts ...Option) kml.Element {
+	o := newOptions(opts)
 	lineStrings := make([]kml.Element, mls.NumLineStrings())
 	flatCoords := mls.FlatCoords()
 	ends := mls.Ends()
@@ -51,27 +56,29 @@ func EncodeMultiLineString(mls *geom.MultiLineString) kml.Element {
 	d := dim(mls.Layout())
 	offset := 0
 	for i, end := range ends {
-		lineStrings[i] = kml.LineString(kml.CoordinatesFlat(flatCoords, offset, end, stride, d))
+		lineStrings[i] = kml.LineString(o.children(true, kml.CoordinatesFlat(flatCoords, offset, end, stride, d))...)
 		offset = end
 	}
 	return kml.MultiGeometry(lineStrings...)
 }
 
 // EncodeMultiPoint encodes a MultiPoint.
-func EncodeMultiPoint(mp *geom.MultiPoint) kml.Element {
+func EncodeMultiPoint(mp *geom.MultiPoint, opts ...Option) kml.Element {
+	o := newOptions(opts)
 	points := make([]kml.Element, mp.NumPoints())
 	flatCoords := mp.FlatCoords()
 	stride := mp.Stride()
 	d := dim(mp.Layout())
 	for i, offset, end := 0, 0, len(flatCoords); offset < end; i++ {
-		points[i] = kml.Point(kml.CoordinatesFlat(flatCoords, offset, offset+stride, stride, d))
+		points[i] = kml.Point(o.children(false, kml.CoordinatesFlat(flatCoords, offset, offset+stride, stride, d))...)
 		offset += stride
 	}
 	return kml.MultiGeometry(points...)
 }
 
 // EncodeMultiPolygon encodes a MultiPolygon.
-func EncodeMultiPolygon(mp *geom.MultiPolygon) kml.Element {
+func EncodeMultiPolygon(mp *geom.MultiPolygon, opts ...Option) kml.Element {
+	o := newOptions(opts)
 	polygons := make([]kml.Element, mp.NumPolygons())
 	flatCoords := mp.FlatCoords()
 	endss := mp.Endss()
@@ -89,19 +96,21 @@ func EncodeMultiPolygon(mp *geom.MultiPolygon) kml.Element {
 			}
 			offset = end
 		}
-		polygons[i] = kml.Polygon(boundaries...)
+		polygons[i] = kml.Polygon(o.children(true, boundaries...)...)
 	}
 	return kml.MultiGeometry(polygons...)
 }
 
 // EncodePoint encodes a Point.
-func EncodePoint(p *geom.Point) kml.Element {
+func EncodePoint(p *geom.Point, opts ...Option) kml.Element {
+	o := newOptions(opts)
 	flatCoords := p.FlatCoords()
-	return kml.Point(kml.CoordinatesFlat(flatCoords, 0, len(flatCoords), p.Stride(), dim(p.Layout())))
+	return kml.Point(o.children(false, kml.CoordinatesFlat(flatCoords, 0, len(flatCoords), p.Stride(), dim(p.Layout())))...)
 }
 
 // EncodePolygon encodes a Polygon.
-func EncodePolygon(p *geom.Polygon) kml.Element {
+func EncodePolygon(p *geom.Polygon, opts ...Option) kml.Element {
+	o := newOptions(opts)
 	boundaries := make([]kml.Element, p.NumLinearRings())
 	stride := p.Stride()
 	flatCoords := p.FlatCoords()
@@ -116,15 +125,15 @@ func EncodePolygon(p *geom.Polygon) kml.Element {
 		}
 		offset = end
 	}
-	return kml.Polygon(boundaries...)
+	return kml.Polygon(o.children(true, boundaries...)...)
 }
 
 // EncodeGeometryCollection encodes a GeometryCollection.
-func EncodeGeometryCollection(g *geom.GeometryCollection) (kml.Element, error) {
+func EncodeGeometryCollection(g *geom.GeometryCollection, opts ...Option) (kml.Element, error) {
 	geometries := make([]kml.Element, g.NumGeoms())
 	for i, g := range g.Geoms() {
 		var err error
-		geometries[i], err = Encode(g)
+		geometries[i], err = Encode(g, opts...)
 		if err != nil {
 			return nil, err
 		}
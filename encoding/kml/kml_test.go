@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-kml"
 )
 
 func Test(t *testing.T) {
@@ -238,3 +239,79 @@ func Test(t *testing.T) {
 		}
 	}
 }
+
+func TestEncodeOptions(t *testing.T) {
+	for _, tc := range []struct {
+		g    geom.T
+		opts []Option
+		want string
+	}{
+		{
+			g: geom.NewPoint(geom.XYZ).MustSetCoords(geom.Coord{1, 2, 3}),
+			opts: []Option{
+				WithAltitudeMode(kml.AltitudeModeAbsolute),
+				WithExtrude(true),
+				WithTessellate(true),
+			},
+			want: `<Point>` +
+				`<extrude>1</extrude>` +
+				`<altitudeMode>absolute</altitudeMode>` +
+				`<coordinates>1,2,3</coordinates>` +
+				`</Point>`,
+		},
+		{
+			g:    geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{{0, 0}, {1, 1}}),
+			opts: []Option{WithTessellate(true)},
+			want: `<LineString>` +
+				`<tessellate>1</tessellate>` +
+				`<coordinates>0,0 1,1</coordinates>` +
+				`</LineString>`,
+		},
+		{
+			g: geom.NewPolygon(geom.XYZ).MustSetCoords([][]geom.Coord{
+				{{0, 0, 1}, {1, 0, 1}, {1, 1, 1}, {0, 0, 1}},
+			}),
+			opts: []Option{
+				WithAltitudeMode(kml.AltitudeModeRelativeToGround),
+				WithExtrude(true),
+			},
+			want: `<Polygon>` +
+				`<extrude>1</extrude>` +
+				`<altitudeMode>relativeToGround</altitudeMode>` +
+				`<outerBoundaryIs>` +
+				`<LinearRing>` +
+				`<coordinates>0,0,1 1,0,1 1,1,1 0,0,1</coordinates>` +
+				`</LinearRing>` +
+				`</outerBoundaryIs>` +
+				`</Polygon>`,
+		},
+		{
+			g:    geom.NewMultiPoint(geom.XY).MustSetCoords([]geom.Coord{{1, 2}, {3, 4}}),
+			opts: []Option{WithExtrude(true)},
+			want: `<MultiGeometry>` +
+				`<Point>` +
+				`<extrude>1</extrude>` +
+				`<coordinates>1,2</coordinates>` +
+				`</Point>` +
+				`<Point>` +
+				`<extrude>1</extrude>` +
+				`<coordinates>3,4</coordinates>` +
+				`</Point>` +
+				`</MultiGeometry>`,
+		},
+	} {
+		element, err := Encode(tc.g, tc.opts...)
+		if err != nil {
+			t.Errorf("Encode(%#v, ...) == %#v, %v, want ..., nil", tc.g, element, err)
+			continue
+		}
+		b := &bytes.Buffer{}
+		if err := xml.NewEncoder(b).Encode(element); err != nil {
+			t.Errorf("Encode(%#v) == %v, want nil", element, err)
+			continue
+		}
+		if got := b.String(); got != tc.want {
+			t.Errorf("Encode(Encode(%#v, ...))\nwrote %v\n want %v", tc.g, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package kml
+
+import "github.com/twpayne/go-kml"
+
+// An Option configures encoding or decoding. Options that do not apply to an
+// operation are ignored.
+type Option func(*options)
+
+type options struct {
+	altitudeMode  kml.AltitudeModeEnum
+	clampToGround bool
+	extrude       bool
+	tessellate    bool
+}
+
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithAltitudeMode sets the altitudeMode of encoded geometries, which
+// determines how Google Earth interprets their altitudes. By default no
+// altitudeMode is encoded, which KML treats as clampToGround, so altitudes
+// are ignored.
+func WithAltitudeMode(altitudeMode kml.AltitudeModeEnum) Option {
+	return func(o *options) {
+		o.altitudeMode = altitudeMode
+	}
+}
+
+// WithClampToGround sets whether the altitudes of decoded geometries that
+// are clamped to the ground or sea floor, because they have no altitudeMode
+// or an altitudeMode of clampToGround or clampToSeaFloor, are dropped, as
+// Google Earth ignores them. By default altitudes are always decoded.
+func WithClampToGround(clampToGround bool) Option {
+	return func(o *options) {
+		o.clampToGround = clampToGround
+	}
+}
+
+// WithExtrude sets whether encoded geometries are extruded, that is
+// connected to the ground by vertical walls. Extrusion only has an effect
+// with an altitudeMode other than clampToGround.
+func WithExtrude(extrude bool) Option {
+	return func(o *options) {
+		o.extrude = extrude
+	}
+}
+
+// WithTessellate sets whether encoded LineStrings, LinearRings, and Polygons
+// are tessellated so that they follow the terrain when clamped to the
+// ground.
+func WithTessellate(tessellate bool) Option {
+	return func(o *options) {
+		o.tessellate = tessellate
+	}
+}
+
+// children returns children preceded by the extrude, tessellate, and
+// altitudeMode elements, in the order required by the KML schema.
+// tessellate is only included if canTessellate is true.
+func (o options) children(canTessellate bool, children ...kml.Element) []kml.Element {
+	var elements []kml.Element
+	if o.extrude {
+		elements = append(elements, kml.Extrude(true))
+	}
+	if o.tessellate && canTessellate {
+		elements = append(elements, kml.Tessellate(true))
+	}
+	if o.altitudeMode != "" {
+		elements = append(elements, kml.AltitudeMode(o.altitudeMode))
+	}
+	if elements == nil {
+		return children
+	}
+	return append(elements, children...)
+}
@@ -0,0 +1,303 @@
+package mvt
+
+import (
+	"math"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/geo"
+	"github.com/twpayne/go-geom/internal/protobuf"
+	"github.com/twpayne/go-geom/tile"
+)
+
+// Unmarshal decodes the layers of the tile in data. Geometries are in tile
+// coordinates unless WithBounds or WithTile is given, in which case they are
+// transformed to the coordinates of the bounds or to longitudes and
+// latitudes. Features with geometries of unknown type have nil geometries.
+// Unknown fields are ignored.
+func Unmarshal(data []byte, opts ...Option) ([]*Layer, error) {
+	layers, err := unmarshal(data, newOptions(opts))
+	if err == protobuf.ErrInvalid {
+		return nil, errInvalidTile
+	}
+	return layers, err
+}
+
+func unmarshal(data []byte, o options) ([]*Layer, error) {
+	d := protobuf.NewDecoder(data)
+	var layers []*Layer
+	for d.Next() {
+		if d.Field() != 3 || d.WireType() != protobuf.Bytes {
+			d.Skip()
+			continue
+		}
+		layerData := d.Bytes()
+		if d.Err() != nil {
+			break
+		}
+		l, err := decodeLayer(layerData, o)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, l)
+	}
+	if d.Err() != nil {
+		return nil, d.Err()
+	}
+	return layers, nil
+}
+
+// decodeLayer decodes the layer in data.
+func decodeLayer(data []byte, o options) (*Layer, error) {
+	l := &Layer{
+		Extent: tile.DefaultExtent,
+	}
+	layerVersion := uint64(1)
+	var featuresData [][]byte
+	var keys []string
+	var values []interface{}
+	d := protobuf.NewDecoder(data)
+	for d.Next() {
+		switch {
+		case d.Field() == 15 && d.WireType() == protobuf.Varint:
+			layerVersion = d.Varint()
+		case d.Field() == 1 && d.WireType() == protobuf.Bytes:
+			l.Name = string(d.Bytes())
+		case d.Field() == 2 && d.WireType() == protobuf.Bytes:
+			featuresData = append(featuresData, d.Bytes())
+		case d.Field() == 3 && d.WireType() == protobuf.Bytes:
+			keys = append(keys, string(d.Bytes()))
+		case d.Field() == 4 && d.WireType() == protobuf.Bytes:
+			value, err := decodeValue(d.Bytes())
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		case d.Field() == 5 && d.WireType() == protobuf.Varint:
+			l.Extent = int(d.Varint())
+		default:
+			d.Skip()
+		}
+	}
+	switch {
+	case d.Err() != nil:
+		return nil, d.Err()
+	case layerVersion < 1 || layerVersion > version:
+		return nil, ErrUnsupportedVersion(layerVersion)
+	case l.Extent <= 0:
+		return nil, errInvalidTile
+	}
+	l.Features = make([]*Feature, 0, len(featuresData))
+	for _, featureData := range featuresData {
+		f, err := decodeFeature(featureData, keys, values, l.Extent, o)
+		if err != nil {
+			return nil, err
+		}
+		l.Features = append(l.Features, f)
+	}
+	return l, nil
+}
+
+// decodeValue decodes the property value in data.
+func decodeValue(data []byte) (interface{}, error) {
+	var value interface{}
+	d := protobuf.NewDecoder(data)
+	for d.Next() {
+		switch {
+		case d.Field() == 1 && d.WireType() == protobuf.Bytes:
+			value = string(d.Bytes())
+		case d.Field() == 2 && d.WireType() == protobuf.Fixed32:
+			value = math.Float32frombits(d.Fixed32())
+		case d.Field() == 3 && d.WireType() == protobuf.Fixed64:
+			value = math.Float64frombits(d.Fixed64())
+		case d.Field() == 4 && d.WireType() == protobuf.Varint:
+			value = int64(d.Varint())
+		case d.Field() == 5 && d.WireType() == protobuf.Varint:
+			value = d.Varint()
+		case d.Field() == 6 && d.WireType() == protobuf.Varint:
+			value = protobuf.Unzigzag(d.Varint())
+		case d.Field() == 7 && d.WireType() == protobuf.Varint:
+			value = d.Varint() != 0
+		default:
+			d.Skip()
+		}
+	}
+	if d.Err() != nil {
+		return nil, d.Err()
+	}
+	if value == nil {
+		return nil, errInvalidTile
+	}
+	return value, nil
+}
+
+// decodeFeature decodes the feature in data, whose tags refer to keys and
+// values.
+func decodeFeature(data []byte, keys []string, values []interface{}, extent int, o options) (*Feature, error) {
+	f := &Feature{}
+	var tags, geometry []uint64
+	geomType := uint64(geomTypeUnknown)
+	d := protobuf.NewDecoder(data)
+	for d.Next() {
+		switch {
+		case d.Field() == 1 && d.WireType() == protobuf.Varint:
+			f.ID = d.Varint()
+		case d.Field() == 2:
+			tags = d.Varints(tags)
+		case d.Field() == 3 && d.WireType() == protobuf.Varint:
+			geomType = d.Varint()
+		case d.Field() == 4:
+			geometry = d.Varints(geometry)
+		default:
+			d.Skip()
+		}
+	}
+	if d.Err() != nil {
+		return nil, d.Err()
+	}
+	if len(tags)%2 != 0 {
+		return nil, errInvalidTile
+	}
+	if len(tags) > 0 {
+		f.Properties = make(map[string]interface{}, len(tags)/2)
+		for i := 0; i < len(tags); i += 2 {
+			keyIndex, valueIndex := int(tags[i]), int(tags[i+1])
+			if keyIndex >= len(keys) || valueIndex >= len(values) {
+				return nil, errInvalidTile
+			}
+			f.Properties[keys[keyIndex]] = values[valueIndex]
+		}
+	}
+	g, err := decodeGeometry(geomType, geometry)
+	if err != nil || g == nil {
+		return f, err
+	}
+	if o.bounds != nil {
+		if g, err = geo.Unproject(g, newGrid(o.bounds, extent)); err != nil {
+			return nil, err
+		}
+	}
+	if o.projection != nil {
+		if g, err = geo.Unproject(g, o.projection); err != nil {
+			return nil, err
+		}
+	}
+	f.Geom = g
+	return f, nil
+}
+
+// decodeGeometry decodes the geometry with geomType and commands geometry.
+// Geometries of unknown type are decoded as nil.
+func decodeGeometry(geomType uint64, geometry []uint64) (geom.T, error) {
+	if geomType < geomTypePoint || geomType > geomTypePolygon {
+		return nil, nil
+	}
+	parts, err := decodeParts(geometry)
+	if err != nil {
+		return nil, err
+	}
+	if len(parts) == 0 {
+		return nil, errInvalidGeometry
+	}
+	switch geomType {
+	case geomTypePoint:
+		flatCoords := make([]float64, 0, 2*len(parts))
+		for _, part := range parts {
+			if len(part) != 2 {
+				return nil, errInvalidGeometry
+			}
+			flatCoords = append(flatCoords, part...)
+		}
+		if len(parts) == 1 {
+			return geom.NewPointFlat(geom.XY, flatCoords), nil
+		}
+		return geom.NewMultiPointFlat(geom.XY, flatCoords), nil
+	case geomTypeLineString:
+		var flatCoords []float64
+		ends := make([]int, 0, len(parts))
+		for _, part := range parts {
+			if len(part) < 4 {
+				return nil, errInvalidGeometry
+			}
+			flatCoords = append(flatCoords, part...)
+			ends = append(ends, len(flatCoords))
+		}
+		if len(parts) == 1 {
+			return geom.NewLineStringFlat(geom.XY, flatCoords), nil
+		}
+		return geom.NewMultiLineStringFlat(geom.XY, flatCoords, ends), nil
+	default:
+		return decodePolygons(parts)
+	}
+}
+
+// decodePolygons returns the closed rings parts as a Polygon or
+// MultiPolygon. Each ring with a positive area starts a new polygon and each
+// ring with a negative area is an interior ring of the preceding one. Rings
+// with zero area are ignored.
+func decodePolygons(parts [][]float64) (geom.T, error) {
+	var flatCoords []float64
+	var endss [][]int
+	for _, part := range parts {
+		n := len(part)
+		if n < 8 || part[0] != part[n-2] || part[1] != part[n-1] {
+			return nil, errInvalidGeometry
+		}
+		area := ringDoubleArea(part)
+		switch {
+		case area > 0:
+			endss = append(endss, nil)
+		case area < 0:
+			if len(endss) == 0 {
+				return nil, errInvalidGeometry
+			}
+		default:
+			continue
+		}
+		flatCoords = append(flatCoords, part...)
+		endss[len(endss)-1] = append(endss[len(endss)-1], len(flatCoords))
+	}
+	switch len(endss) {
+	case 0:
+		return nil, errInvalidGeometry
+	case 1:
+		return geom.NewPolygonFlat(geom.XY, flatCoords, endss[0]), nil
+	default:
+		return geom.NewMultiPolygonFlat(geom.XY, flatCoords, endss), nil
+	}
+}
+
+// decodeParts decodes the commands geometry into parts, each starting with a
+// MoveTo command. ClosePath commands close the current part by repeating its
+// first vertex.
+func decodeParts(geometry []uint64) ([][]float64, error) {
+	var parts [][]float64
+	var x, y int64
+	for i := 0; i < len(geometry); {
+		id, count := geometry[i]&7, int(geometry[i]>>3)
+		i++
+		switch id {
+		case cmdMoveTo, cmdLineTo:
+			if id == cmdLineTo && len(parts) == 0 || len(geometry)-i < 2*count {
+				return nil, errInvalidGeometry
+			}
+			for j := 0; j < count; j++ {
+				x += protobuf.Unzigzag(geometry[i])
+				y += protobuf.Unzigzag(geometry[i+1])
+				i += 2
+				if id == cmdMoveTo {
+					parts = append(parts, nil)
+				}
+				parts[len(parts)-1] = append(parts[len(parts)-1], float64(x), float64(y))
+			}
+		case cmdClosePath:
+			if count != 1 || len(parts) == 0 {
+				return nil, errInvalidGeometry
+			}
+			part := parts[len(parts)-1]
+			parts[len(parts)-1] = append(part, part[0], part[1])
+		default:
+			return nil, errInvalidGeometry
+		}
+	}
+	return parts, nil
+}
@@ -0,0 +1,304 @@
+package mvt
+
+import (
+	"math"
+	"sort"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/geo"
+	"github.com/twpayne/go-geom/internal/protobuf"
+	"github.com/twpayne/go-geom/tile"
+)
+
+// Geometry types.
+const (
+	geomTypeUnknown    = 0
+	geomTypePoint      = 1
+	geomTypeLineString = 2
+	geomTypePolygon    = 3
+)
+
+// Geometry commands.
+const (
+	cmdMoveTo    = 1
+	cmdLineTo    = 2
+	cmdClosePath = 7
+)
+
+// Marshal returns the encoding of a tile containing layers. Geometries are
+// clipped to the tile, plus the buffer, and snapped to the grid of their
+// layer, and features whose geometries are nil or from which nothing is left
+// are omitted. Polygon rings are rewound as required by the specification.
+func Marshal(layers []*Layer, opts ...Option) ([]byte, error) {
+	o := newOptions(opts)
+	var data []byte
+	for _, l := range layers {
+		layerData, err := encodeLayer(l, o)
+		if err != nil {
+			return nil, err
+		}
+		data = protobuf.AppendBytesField(data, 3, layerData)
+	}
+	return data, nil
+}
+
+// A layerEncoder accumulates the keys and values of the properties of the
+// features of a layer.
+type layerEncoder struct {
+	keys         []string
+	keyIndexes   map[string]int
+	values       [][]byte
+	valueIndexes map[string]int
+}
+
+// encodeLayer encodes l.
+func encodeLayer(l *Layer, o options) ([]byte, error) {
+	extent := layerExtent(l)
+	e := &layerEncoder{
+		keyIndexes:   make(map[string]int),
+		valueIndexes: make(map[string]int),
+	}
+	data := protobuf.AppendVarintField(nil, 15, version)
+	data = protobuf.AppendBytesField(data, 1, []byte(l.Name))
+	for _, f := range l.Features {
+		g, err := o.prepare(f.Geom, extent)
+		if err != nil {
+			return nil, err
+		}
+		if g == nil {
+			continue
+		}
+		tags, err := e.tags(f.Properties)
+		if err != nil {
+			return nil, err
+		}
+		geomType, geometry := encodeGeometry(g)
+		var featureData []byte
+		if f.ID != 0 {
+			featureData = protobuf.AppendVarintField(featureData, 1, f.ID)
+		}
+		if len(tags) > 0 {
+			featureData = protobuf.AppendPackedField(featureData, 2, tags)
+		}
+		featureData = protobuf.AppendVarintField(featureData, 3, uint64(geomType))
+		featureData = protobuf.AppendPackedField(featureData, 4, geometry)
+		data = protobuf.AppendBytesField(data, 2, featureData)
+	}
+	for _, key := range e.keys {
+		data = protobuf.AppendBytesField(data, 3, []byte(key))
+	}
+	for _, value := range e.values {
+		data = protobuf.AppendBytesField(data, 4, value)
+	}
+	return protobuf.AppendVarintField(data, 5, uint64(extent)), nil
+}
+
+// prepare returns g clipped and snapped to the grid of a tile with extent,
+// or nil if nothing is left of g.
+func (o options) prepare(g geom.T, extent int) (geom.T, error) {
+	switch g.(type) {
+	case nil:
+		return nil, nil
+	case *geom.Point, *geom.MultiPoint, *geom.LineString, *geom.MultiLineString, *geom.Polygon, *geom.MultiPolygon:
+	default:
+		return nil, geom.ErrUnsupportedType{Value: g}
+	}
+	bounds := o.bounds
+	var err error
+	switch {
+	case o.projection != nil:
+		g, err = geo.Project(g, o.projection)
+	case bounds == nil:
+		g, err = geo.Project(g, flipY{})
+		bounds = geom.NewBounds(geom.XY).Set(0, -float64(extent), float64(extent), 0)
+	}
+	if err != nil {
+		return nil, err
+	}
+	bufferX := float64(o.buffer) * (bounds.Max(0) - bounds.Min(0)) / float64(extent)
+	bufferY := float64(o.buffer) * (bounds.Max(1) - bounds.Min(1)) / float64(extent)
+	clipBounds := geom.NewBounds(geom.XY).Set(
+		bounds.Min(0)-bufferX, bounds.Min(1)-bufferY,
+		bounds.Max(0)+bufferX, bounds.Max(1)+bufferY,
+	)
+	if g, err = tile.Clip(g, clipBounds); err != nil || g == nil {
+		return nil, err
+	}
+	return tile.Snap(g, bounds, extent)
+}
+
+// tags returns the tags of properties, adding their keys and values to e.
+func (e *layerEncoder) tags(properties map[string]interface{}) ([]uint64, error) {
+	names := make([]string, 0, len(properties))
+	for name, value := range properties {
+		if value != nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	tags := make([]uint64, 0, 2*len(names))
+	for _, name := range names {
+		value, err := encodeValue(name, properties[name])
+		if err != nil {
+			return nil, err
+		}
+		keyIndex, ok := e.keyIndexes[name]
+		if !ok {
+			keyIndex = len(e.keys)
+			e.keys = append(e.keys, name)
+			e.keyIndexes[name] = keyIndex
+		}
+		valueIndex, ok := e.valueIndexes[string(value)]
+		if !ok {
+			valueIndex = len(e.values)
+			e.values = append(e.values, value)
+			e.valueIndexes[string(value)] = valueIndex
+		}
+		tags = append(tags, uint64(keyIndex), uint64(valueIndex))
+	}
+	return tags, nil
+}
+
+// encodeValue encodes the value of the property name.
+func encodeValue(name string, value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case string:
+		return protobuf.AppendBytesField(nil, 1, []byte(v)), nil
+	case float32:
+		return protobuf.AppendFixed32Field(nil, 2, math.Float32bits(v)), nil
+	case float64:
+		return protobuf.AppendFixed64Field(nil, 3, math.Float64bits(v)), nil
+	case int:
+		return encodeIntValue(int64(v)), nil
+	case int8:
+		return encodeIntValue(int64(v)), nil
+	case int16:
+		return encodeIntValue(int64(v)), nil
+	case int32:
+		return encodeIntValue(int64(v)), nil
+	case int64:
+		return encodeIntValue(v), nil
+	case uint:
+		return protobuf.AppendVarintField(nil, 5, uint64(v)), nil
+	case uint8:
+		return protobuf.AppendVarintField(nil, 5, uint64(v)), nil
+	case uint16:
+		return protobuf.AppendVarintField(nil, 5, uint64(v)), nil
+	case uint32:
+		return protobuf.AppendVarintField(nil, 5, uint64(v)), nil
+	case uint64:
+		return protobuf.AppendVarintField(nil, 5, v), nil
+	case bool:
+		if v {
+			return protobuf.AppendVarintField(nil, 7, 1), nil
+		}
+		return protobuf.AppendVarintField(nil, 7, 0), nil
+	default:
+		return nil, ErrInvalidProperty{Name: name, Value: value}
+	}
+}
+
+// encodeIntValue encodes the signed integer v, as an int_value if it is
+// non-negative and as a more compact sint_value otherwise.
+func encodeIntValue(v int64) []byte {
+	if v < 0 {
+		return protobuf.AppendVarintField(nil, 6, protobuf.Zigzag(v))
+	}
+	return protobuf.AppendVarintField(nil, 4, uint64(v))
+}
+
+// A geometryEncoder encodes geometry commands.
+type geometryEncoder struct {
+	geometry []uint64
+	x, y     int64
+}
+
+// encodeGeometry returns the type and commands of g, which must have been
+// snapped to the grid of a tile.
+func encodeGeometry(g geom.T) (int, []uint64) {
+	e := &geometryEncoder{}
+	switch g := g.(type) {
+	case *geom.Point:
+		e.command(cmdMoveTo, 1)
+		e.vertices(g.FlatCoords())
+		return geomTypePoint, e.geometry
+	case *geom.MultiPoint:
+		e.command(cmdMoveTo, g.NumPoints())
+		e.vertices(g.FlatCoords())
+		return geomTypePoint, e.geometry
+	case *geom.LineString:
+		e.line(g.FlatCoords())
+		return geomTypeLineString, e.geometry
+	case *geom.MultiLineString:
+		for it := g.EndsIter(); it.Next(); {
+			e.line(it.FlatCoords())
+		}
+		return geomTypeLineString, e.geometry
+	case *geom.Polygon:
+		e.polygon(g.EndsIter())
+		return geomTypePolygon, e.geometry
+	case *geom.MultiPolygon:
+		for it := g.EndssIter(); it.Next(); {
+			e.polygon(it.EndsIter())
+		}
+		return geomTypePolygon, e.geometry
+	default:
+		return geomTypeUnknown, nil
+	}
+}
+
+// command encodes the command id with count.
+func (e *geometryEncoder) command(id, count int) {
+	e.geometry = append(e.geometry, uint64(id&7|count<<3))
+}
+
+// vertices encodes the XY flatCoords as parameters relative to the cursor.
+func (e *geometryEncoder) vertices(flatCoords []float64) {
+	for i := 0; i < len(flatCoords); i += 2 {
+		x, y := int64(flatCoords[i]), int64(flatCoords[i+1])
+		e.geometry = append(e.geometry, protobuf.Zigzag(x-e.x), protobuf.Zigzag(y-e.y))
+		e.x, e.y = x, y
+	}
+}
+
+// line encodes the XY line flatCoords.
+func (e *geometryEncoder) line(flatCoords []float64) {
+	e.command(cmdMoveTo, 1)
+	e.vertices(flatCoords[:2])
+	e.command(cmdLineTo, len(flatCoords)/2-1)
+	e.vertices(flatCoords[2:])
+}
+
+// polygon encodes the XY rings of a polygon. Exterior rings must have a
+// positive area in tile coordinates and interior rings a negative area, so
+// rings are reversed if necessary.
+func (e *geometryEncoder) polygon(it geom.EndsIter) {
+	for it.Next() {
+		ringFlatCoords := it.FlatCoords()
+		ring := ringFlatCoords[:len(ringFlatCoords)-2]
+		if exterior := it.Index() == 0; exterior != (ringDoubleArea(ring) > 0) {
+			reversed := make([]float64, len(ring))
+			for i := 0; i < len(ring); i += 2 {
+				reversed[len(ring)-i-2], reversed[len(ring)-i-1] = ring[i], ring[i+1]
+			}
+			ring = reversed
+		}
+		e.command(cmdMoveTo, 1)
+		e.vertices(ring[:2])
+		e.command(cmdLineTo, len(ring)/2-1)
+		e.vertices(ring[2:])
+		e.command(cmdClosePath, 1)
+	}
+}
+
+// ringDoubleArea returns twice the signed area of the XY ring flatCoords,
+// which may or may not be closed.
+func ringDoubleArea(flatCoords []float64) float64 {
+	area := 0.0
+	n := len(flatCoords)
+	for i := 0; i < n; i += 2 {
+		j := (i + 2) % n
+		area += flatCoords[i]*flatCoords[j+1] - flatCoords[j]*flatCoords[i+1]
+	}
+	return area
+}
@@ -0,0 +1,196 @@
+// Package mvt implements Mapbox Vector Tile encoding and decoding. See
+// https://github.com/mapbox/vector-tile-spec/tree/master/2.1.
+//
+// A tile contains named layers of features, each with a geometry, an
+// optional ID, and properties. Geometries are stored as integers on a grid of
+// extent×extent cells covering the tile, with the origin at the top left and
+// Y increasing downwards. When encoding, geometries are clipped to the tile,
+// plus a buffer, and snapped to the grid with package tile. Geometries may be
+// given in tile coordinates, in the coordinates of arbitrary tile bounds, or
+// as longitudes and latitudes of a Web Mercator tile z/x/y.
+//
+// Points and MultiPoints, LineStrings and MultiLineStrings, and Polygons and
+// MultiPolygons are supported. Properties are strings, floats, integers, and
+// booleans.
+package mvt
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/geo"
+	"github.com/twpayne/go-geom/tile"
+)
+
+// DefaultBuffer is the default buffer around tiles, in tile coordinates,
+// within which encoded geometries are kept.
+const DefaultBuffer = 64
+
+// version is the version of the vector tile specification that is encoded.
+const version = 2
+
+// webMercatorRadius is the radius of the sphere of the Web Mercator
+// projection, EPSG:3857.
+const webMercatorRadius = 6378137
+
+// maxLat is the latitude at which Web Mercator tiles are square.
+const maxLat = 85.0511287798066
+
+var (
+	errInvalidGeometry = errors.New("mvt: invalid geometry")
+	errInvalidTile     = errors.New("mvt: invalid tile")
+)
+
+// An ErrUnsupportedVersion is returned when decoding a layer with an
+// unsupported version.
+type ErrUnsupportedVersion uint64
+
+func (e ErrUnsupportedVersion) Error() string {
+	return fmt.Sprintf("mvt: unsupported version %d", uint64(e))
+}
+
+// An ErrInvalidProperty is returned when encoding a property whose value
+// does not have a supported Go type.
+type ErrInvalidProperty struct {
+	Name  string
+	Value interface{}
+}
+
+func (e ErrInvalidProperty) Error() string {
+	return fmt.Sprintf("mvt: invalid value for property %s: %T", e.Name, e.Value)
+}
+
+// A Layer is a layer of a tile.
+type Layer struct {
+	Name string
+	// Extent is the number of cells along each side of the tile. If it is
+	// zero then tile.DefaultExtent is used.
+	Extent   int
+	Features []*Feature
+}
+
+// A Feature is a feature of a layer.
+type Feature struct {
+	// ID is the ID of the feature. Zero means that the feature has no ID.
+	ID   uint64
+	Geom geom.T
+	// Properties are the properties of the feature. Values are strings,
+	// float32s, float64s, signed and unsigned integers, or bools. Decoded
+	// integers are int64s or uint64s. Properties with nil values are
+	// omitted.
+	Properties map[string]interface{}
+}
+
+// An Option configures encoding or decoding.
+type Option func(*options)
+
+type options struct {
+	bounds     *geom.Bounds
+	projection geo.Projection
+	buffer     int
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		buffer: DefaultBuffer,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithBounds sets the bounds of the tile in the coordinates of geometries,
+// with Y increasing upwards, as in most projections. By default geometries
+// are in tile coordinates.
+func WithBounds(bounds *geom.Bounds) Option {
+	return func(o *options) {
+		o.bounds = bounds
+		o.projection = nil
+	}
+}
+
+// WithBuffer sets the buffer around the tile, in tile coordinates, within
+// which encoded geometries are kept, so that lines and polygon edges that
+// cross tile boundaries render seamlessly. The default is DefaultBuffer.
+func WithBuffer(buffer int) Option {
+	return func(o *options) {
+		o.buffer = buffer
+	}
+}
+
+// WithTile sets the tile to the Web Mercator tile z/x/y, with geometries in
+// longitudes and latitudes. By default geometries are in tile coordinates.
+func WithTile(z, x, y int) Option {
+	return func(o *options) {
+		o.bounds = TileBounds(z, x, y)
+		o.projection = webMercator{}
+	}
+}
+
+// TileBounds returns the bounds of the Web Mercator tile z/x/y in meters, in
+// EPSG:3857.
+func TileBounds(z, x, y int) *geom.Bounds {
+	size := 2 * math.Pi * webMercatorRadius / float64(uint64(1)<<uint(z))
+	minX := -math.Pi*webMercatorRadius + float64(x)*size
+	maxY := math.Pi*webMercatorRadius - float64(y)*size
+	return geom.NewBounds(geom.XY).Set(minX, maxY-size, minX+size, maxY)
+}
+
+// webMercator is the spherical Web Mercator projection, EPSG:3857.
+type webMercator struct{}
+
+func (webMercator) Forward(lon, lat float64) (float64, float64) {
+	lat = math.Max(-maxLat, math.Min(lat, maxLat))
+	x := webMercatorRadius * lon * math.Pi / 180
+	y := webMercatorRadius * math.Log(math.Tan(math.Pi/4+lat*math.Pi/360))
+	return x, y
+}
+
+func (webMercator) Inverse(x, y float64) (float64, float64) {
+	lon := x / webMercatorRadius * 180 / math.Pi
+	lat := (2*math.Atan(math.Exp(y/webMercatorRadius)) - math.Pi/2) * 180 / math.Pi
+	return lon, lat
+}
+
+// flipY is a geo.Projection that flips tile coordinates, with Y increasing
+// downwards, so that Y increases upwards as tile.Snap expects.
+type flipY struct{}
+
+func (flipY) Forward(x, y float64) (float64, float64) { return x, -y }
+
+func (flipY) Inverse(x, y float64) (float64, float64) { return x, -y }
+
+// A grid is a geo.Projection that transforms the coordinates of the bounds
+// of a tile to and from tile coordinates.
+type grid struct {
+	minX, maxY     float64
+	scaleX, scaleY float64
+}
+
+func newGrid(bounds *geom.Bounds, extent int) grid {
+	return grid{
+		minX:   bounds.Min(0),
+		maxY:   bounds.Max(1),
+		scaleX: (bounds.Max(0) - bounds.Min(0)) / float64(extent),
+		scaleY: (bounds.Max(1) - bounds.Min(1)) / float64(extent),
+	}
+}
+
+func (g grid) Forward(x, y float64) (float64, float64) {
+	return (x - g.minX) / g.scaleX, (g.maxY - y) / g.scaleY
+}
+
+func (g grid) Inverse(x, y float64) (float64, float64) {
+	return g.minX + x*g.scaleX, g.maxY - y*g.scaleY
+}
+
+// layerExtent returns the extent of l.
+func layerExtent(l *Layer) int {
+	if l.Extent == 0 {
+		return tile.DefaultExtent
+	}
+	return l.Extent
+}
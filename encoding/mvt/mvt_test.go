@@ -0,0 +1,323 @@
+package mvt
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+)
+
+func TestEncodeGeometry(t *testing.T) {
+	// Examples from section 4.3.5 of the specification.
+	for _, tc := range []struct {
+		name         string
+		g            geom.T
+		wantGeomType int
+		wantGeometry []uint64
+	}{
+		{
+			name:         "point",
+			g:            geom.NewPointFlat(geom.XY, []float64{25, 17}),
+			wantGeomType: geomTypePoint,
+			wantGeometry: []uint64{9, 50, 34},
+		},
+		{
+			name:         "multi_point",
+			g:            geom.NewMultiPointFlat(geom.XY, []float64{5, 7, 3, 2}),
+			wantGeomType: geomTypePoint,
+			wantGeometry: []uint64{17, 10, 14, 3, 9},
+		},
+		{
+			name:         "line_string",
+			g:            geom.NewLineStringFlat(geom.XY, []float64{2, 2, 2, 10, 10, 10}),
+			wantGeomType: geomTypeLineString,
+			wantGeometry: []uint64{9, 4, 4, 18, 0, 16, 16, 0},
+		},
+		{
+			name:         "multi_line_string",
+			g:            geom.NewMultiLineStringFlat(geom.XY, []float64{2, 2, 2, 10, 10, 10, 1, 1, 3, 5}, []int{6, 10}),
+			wantGeomType: geomTypeLineString,
+			wantGeometry: []uint64{9, 4, 4, 18, 0, 16, 16, 0, 9, 17, 17, 10, 4, 8},
+		},
+		{
+			name:         "polygon",
+			g:            geom.NewPolygonFlat(geom.XY, []float64{3, 6, 8, 12, 20, 34, 3, 6}, []int{8}),
+			wantGeomType: geomTypePolygon,
+			wantGeometry: []uint64{9, 6, 12, 18, 10, 12, 24, 44, 15},
+		},
+		{
+			name:         "polygon_rewound",
+			g:            geom.NewPolygonFlat(geom.XY, []float64{3, 6, 20, 34, 8, 12, 3, 6}, []int{8}),
+			wantGeomType: geomTypePolygon,
+			wantGeometry: []uint64{9, 16, 24, 18, 24, 44, 33, 55, 15},
+		},
+		{
+			name: "multi_polygon",
+			g: geom.NewMultiPolygonFlat(geom.XY, []float64{
+				0, 0, 10, 0, 10, 10, 0, 10, 0, 0,
+				11, 11, 20, 11, 20, 20, 11, 20, 11, 11,
+				13, 13, 13, 17, 17, 17, 17, 13, 13, 13,
+			}, [][]int{{10}, {20, 30}}),
+			wantGeomType: geomTypePolygon,
+			wantGeometry: []uint64{
+				9, 0, 0, 26, 20, 0, 0, 20, 19, 0, 15,
+				9, 22, 2, 26, 18, 0, 0, 18, 17, 0, 15,
+				9, 4, 13, 26, 0, 8, 8, 0, 0, 7, 15,
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			gotGeomType, gotGeometry := encodeGeometry(tc.g)
+			if gotGeomType != tc.wantGeomType || !reflect.DeepEqual(gotGeometry, tc.wantGeometry) {
+				t.Errorf("encodeGeometry(...) == %d, %v, want %d, %v", gotGeomType, gotGeometry, tc.wantGeomType, tc.wantGeometry)
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	layers := []*Layer{
+		{
+			Name: "points",
+			Features: []*Feature{
+				{
+					ID:   1,
+					Geom: geom.NewPointFlat(geom.XY, []float64{25, 17}),
+					Properties: map[string]interface{}{
+						"name":       "a",
+						"population": int64(-3),
+						"count":      uint64(1 << 40),
+						"area":       1.5,
+						"ratio":      float32(0.25),
+						"capital":    true,
+					},
+				},
+				{
+					ID:   2,
+					Geom: geom.NewMultiPointFlat(geom.XY, []float64{5, 7, 3, 2}),
+					Properties: map[string]interface{}{
+						"name":    "b",
+						"capital": true,
+					},
+				},
+			},
+		},
+		{
+			Name:   "shapes",
+			Extent: 256,
+			Features: []*Feature{
+				{
+					Geom: geom.NewLineStringFlat(geom.XY, []float64{2, 2, 2, 10, 10, 10}),
+				},
+				{
+					Geom: geom.NewMultiLineStringFlat(geom.XY, []float64{2, 2, 2, 10, 1, 1, 3, 5}, []int{4, 8}),
+				},
+				{
+					Geom: geom.NewPolygonFlat(geom.XY, []float64{
+						0, 0, 10, 0, 10, 10, 0, 10, 0, 0,
+						2, 2, 2, 4, 4, 4, 4, 2, 2, 2,
+					}, []int{10, 20}),
+				},
+				{
+					Geom: geom.NewMultiPolygonFlat(geom.XY, []float64{
+						0, 0, 10, 0, 10, 10, 0, 10, 0, 0,
+						11, 11, 20, 11, 20, 20, 11, 20, 11, 11,
+					}, [][]int{{10}, {20}}),
+				},
+			},
+		},
+	}
+	data, err := Marshal(layers)
+	if err != nil {
+		t.Fatalf("Marshal(...) == _, %v, want _, <nil>", err)
+	}
+	got, err := Unmarshal(data)
+	if err != nil {
+		t.Fatalf("Unmarshal(...) == _, %v, want _, <nil>", err)
+	}
+	layers[0].Extent = 4096
+	if !reflect.DeepEqual(got, layers) {
+		t.Errorf("Unmarshal(Marshal(layers)) == %#v, want %#v", got, layers)
+	}
+}
+
+func TestMarshalClip(t *testing.T) {
+	l := &Layer{
+		Name:   "clip",
+		Extent: 100,
+		Features: []*Feature{
+			{
+				ID:   1,
+				Geom: geom.NewPointFlat(geom.XY, []float64{120, 50}),
+			},
+			{
+				ID:   2,
+				Geom: geom.NewLineStringFlat(geom.XY, []float64{50, 50, 150, 50}),
+			},
+			{
+				ID:   3,
+				Geom: geom.NewPolygonFlat(geom.XY, []float64{-50, -50, 50, -50, 50, 50, -50, 50, -50, -50}, []int{10}),
+			},
+			{
+				ID:   4,
+				Geom: geom.NewPointFlat(geom.XY, []float64{50.4, 49.6}),
+			},
+		},
+	}
+	data, err := Marshal([]*Layer{l}, WithBuffer(10))
+	if err != nil {
+		t.Fatalf("Marshal(...) == _, %v, want _, <nil>", err)
+	}
+	got, err := Unmarshal(data)
+	if err != nil {
+		t.Fatalf("Unmarshal(...) == _, %v, want _, <nil>", err)
+	}
+	want := []*Layer{
+		{
+			Name:   "clip",
+			Extent: 100,
+			Features: []*Feature{
+				{
+					ID:   2,
+					Geom: geom.NewLineStringFlat(geom.XY, []float64{50, 50, 110, 50}),
+				},
+				{
+					ID:   3,
+					Geom: geom.NewPolygonFlat(geom.XY, []float64{-10, -10, 50, -10, 50, 50, -10, 50, -10, -10}, []int{10}),
+				},
+				{
+					ID:   4,
+					Geom: geom.NewPointFlat(geom.XY, []float64{50, 50}),
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal(Marshal(...)) == %#v, want %#v", got, want)
+	}
+}
+
+func TestTile(t *testing.T) {
+	l := &Layer{
+		Name: "places",
+		Features: []*Feature{
+			{
+				Geom: geom.NewPointFlat(geom.XY, []float64{-90, 66.51326044311186}),
+			},
+		},
+	}
+	data, err := Marshal([]*Layer{l}, WithTile(1, 0, 0))
+	if err != nil {
+		t.Fatalf("Marshal(...) == _, %v, want _, <nil>", err)
+	}
+	got, err := Unmarshal(data)
+	if err != nil {
+		t.Fatalf("Unmarshal(...) == _, %v, want _, <nil>", err)
+	}
+	if want := []float64{2048, 2048}; !reflect.DeepEqual(got[0].Features[0].Geom.FlatCoords(), want) {
+		t.Errorf("got %v, want %v", got[0].Features[0].Geom.FlatCoords(), want)
+	}
+	got, err = Unmarshal(data, WithTile(1, 0, 0))
+	if err != nil {
+		t.Fatalf("Unmarshal(...) == _, %v, want _, <nil>", err)
+	}
+	gotFlatCoords := got[0].Features[0].Geom.FlatCoords()
+	if math.Abs(gotFlatCoords[0]+90) > 1e-9 || math.Abs(gotFlatCoords[1]-66.51326044311186) > 1e-9 {
+		t.Errorf("got %v, want [-90 66.51326044311186]", gotFlatCoords)
+	}
+}
+
+func TestTileBounds(t *testing.T) {
+	const halfWidth = math.Pi * webMercatorRadius
+	for _, tc := range []struct {
+		z, x, y int
+		want    *geom.Bounds
+	}{
+		{
+			want: geom.NewBounds(geom.XY).Set(-halfWidth, -halfWidth, halfWidth, halfWidth),
+		},
+		{
+			z:    1,
+			x:    1,
+			want: geom.NewBounds(geom.XY).Set(0, 0, halfWidth, halfWidth),
+		},
+		{
+			z:    1,
+			y:    1,
+			want: geom.NewBounds(geom.XY).Set(-halfWidth, -halfWidth, 0, 0),
+		},
+	} {
+		if got := TileBounds(tc.z, tc.x, tc.y); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("TileBounds(%d, %d, %d) == %v, want %v", tc.z, tc.x, tc.y, got, tc.want)
+		}
+	}
+}
+
+func TestMarshalErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		feature *Feature
+		want    error
+	}{
+		{
+			name: "geometry_collection",
+			feature: &Feature{
+				Geom: geom.NewGeometryCollection(),
+			},
+			want: geom.ErrUnsupportedType{Value: geom.NewGeometryCollection()},
+		},
+		{
+			name: "invalid_property",
+			feature: &Feature{
+				Geom: geom.NewPointFlat(geom.XY, []float64{1, 2}),
+				Properties: map[string]interface{}{
+					"tags": []string{"a"},
+				},
+			},
+			want: ErrInvalidProperty{Name: "tags", Value: []string{"a"}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Marshal([]*Layer{{Features: []*Feature{tc.feature}}})
+			if !reflect.DeepEqual(err, tc.want) {
+				t.Errorf("Marshal(...) == _, %v, want _, %v", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{
+			name: "truncated",
+			data: []byte{0x1a, 0x05, 0x78, 0x02},
+			want: errInvalidTile,
+		},
+		{
+			name: "unsupported_version",
+			data: []byte{0x1a, 0x02, 0x78, 0x03},
+			want: ErrUnsupportedVersion(3),
+		},
+		{
+			name: "invalid_tag",
+			data: []byte{0x1a, 0x06, 0x12, 0x04, 0x12, 0x02, 0x00, 0x00},
+			want: errInvalidTile,
+		},
+		{
+			name: "invalid_geometry",
+			data: []byte{0x1a, 0x08, 0x12, 0x06, 0x18, 0x01, 0x22, 0x02, 0x09, 0x00},
+			want: errInvalidGeometry,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := Unmarshal(tc.data); err != tc.want {
+				t.Errorf("Unmarshal(%v) == _, %v, want _, %v", tc.data, err, tc.want)
+			}
+		})
+	}
+}
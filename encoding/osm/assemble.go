@@ -0,0 +1,139 @@
+package osm
+
+import (
+	"math"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/internal/planar"
+	"github.com/twpayne/go-geom/xy"
+)
+
+// A ring is an assembled ring of a multipolygon relation.
+type ring struct {
+	flatCoords []float64
+	area       float64
+	holes      [][]float64
+}
+
+// assembleMultiPolygon returns the multipolygon formed by the outer and inner
+// member ways of a relation, or nil if it cannot be assembled. Members with
+// an empty role are outer members. Outer rings are oriented counterclockwise
+// and inner rings clockwise. Each inner ring is a hole of the smallest outer
+// ring that contains it.
+func (r *Reader) assembleMultiPolygon(members []member) *geom.MultiPolygon {
+	var outerWays, innerWays [][]int64
+	for _, m := range members {
+		if m.memberType != memberTypeWay {
+			continue
+		}
+		refs, ok := r.ways[m.id]
+		if !ok {
+			return nil
+		}
+		switch m.role {
+		case "outer", "":
+			outerWays = append(outerWays, refs)
+		case "inner":
+			innerWays = append(innerWays, refs)
+		}
+	}
+	outers, ok := r.assembleRings(outerWays, false)
+	if !ok || len(outers) == 0 {
+		return nil
+	}
+	inners, ok := r.assembleRings(innerWays, true)
+	if !ok {
+		return nil
+	}
+	for _, inner := range inners {
+		var container *ring
+		p := geom.Coord(inner.flatCoords[:2])
+		for _, outer := range outers {
+			if (container == nil || outer.area < container.area) && xy.IsPointInRing(geom.XY, p, outer.flatCoords) {
+				container = outer
+			}
+		}
+		if container == nil {
+			return nil
+		}
+		container.holes = append(container.holes, inner.flatCoords)
+	}
+	var flatCoords []float64
+	endss := make([][]int, 0, len(outers))
+	for _, outer := range outers {
+		flatCoords = append(flatCoords, outer.flatCoords...)
+		ends := []int{len(flatCoords)}
+		for _, hole := range outer.holes {
+			flatCoords = append(flatCoords, hole...)
+			ends = append(ends, len(flatCoords))
+		}
+		endss = append(endss, ends)
+	}
+	return geom.NewMultiPolygonFlat(geom.XY, flatCoords, endss)
+}
+
+// assembleRings joins the ways with node references ways end to end into
+// closed rings, oriented clockwise if clockwise is true and counterclockwise
+// otherwise. It returns false if any ring cannot be closed or any node is
+// missing.
+func (r *Reader) assembleRings(ways [][]int64, clockwise bool) ([]*ring, bool) {
+	used := make([]bool, len(ways))
+	var rings []*ring
+	for i, way := range ways {
+		if used[i] {
+			continue
+		}
+		used[i] = true
+		if len(way) < 2 {
+			return nil, false
+		}
+		refs := append([]int64(nil), way...)
+		for refs[0] != refs[len(refs)-1] {
+			var ok bool
+			if refs, ok = joinWay(refs, ways[i+1:], used[i+1:]); !ok {
+				return nil, false
+			}
+		}
+		if len(refs) < 4 {
+			return nil, false
+		}
+		flatCoords, ok := r.flatCoords(nil, refs)
+		if !ok {
+			return nil, false
+		}
+		area := planar.SignedArea(flatCoords, 2)
+		if area > 0 == clockwise {
+			planar.Reverse(flatCoords, 2)
+		}
+		rings = append(rings, &ring{
+			flatCoords: flatCoords,
+			area:       math.Abs(area),
+		})
+	}
+	return rings, true
+}
+
+// joinWay appends the first unused way of ways that starts or ends at the
+// last node of refs, reversed if necessary, and marks it as used. It returns
+// false if there is no such way.
+func joinWay(refs []int64, ways [][]int64, used []bool) ([]int64, bool) {
+	last := refs[len(refs)-1]
+	for i, way := range ways {
+		if used[i] || len(way) < 2 {
+			continue
+		}
+		switch {
+		case way[0] == last:
+			refs = append(refs, way[1:]...)
+		case way[len(way)-1] == last:
+			for j := len(way) - 2; j >= 0; j-- {
+				refs = append(refs, way[j])
+			}
+		default:
+			continue
+		}
+		used[i] = true
+		return refs, true
+	}
+	return nil, false
+}
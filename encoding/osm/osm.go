@@ -0,0 +1,144 @@
+// Package osm implements reading OpenStreetMap PBF files as features with
+// geometries. See https://wiki.openstreetmap.org/wiki/PBF_Format.
+//
+// Nodes are decoded as Points, ways as LineStrings, or Polygons if they are
+// closed and their tags describe areas, and multipolygon and boundary
+// relations as MultiPolygons, assembled from the rings formed by their outer
+// and inner member ways. Coordinates are longitudes and latitudes with the XY
+// layout.
+//
+// Ways and relations are assembled from the nodes and ways that precede them
+// in the file, so files must be sorted by type, as they are by convention.
+// The locations of all nodes and the node references of all ways are kept in
+// memory for this. Ways and relations that reference missing elements, and
+// relations whose rings cannot be assembled, are skipped.
+package osm
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/feature"
+)
+
+// An ElementType is the type of an OpenStreetMap element.
+type ElementType int
+
+// Element types.
+const (
+	Node ElementType = iota
+	Way
+	Relation
+)
+
+func (t ElementType) String() string {
+	switch t {
+	case Node:
+		return "node"
+	case Way:
+		return "way"
+	case Relation:
+		return "relation"
+	default:
+		return fmt.Sprintf("ElementType(%d)", int(t))
+	}
+}
+
+var (
+	errInvalidData            = errors.New("osm: invalid data")
+	errUnsupportedCompression = errors.New("osm: unsupported compression")
+)
+
+// An ErrUnsupportedFeature is returned when a file requires a feature that is
+// not supported, for example historical information.
+type ErrUnsupportedFeature string
+
+func (e ErrUnsupportedFeature) Error() string {
+	return fmt.Sprintf("osm: unsupported feature %q", string(e))
+}
+
+// A Feature is an element with its geometry.
+type Feature struct {
+	Type ElementType
+	ID   int64
+	Tags map[string]string
+	Geom geom.T
+}
+
+// An Option configures a Reader.
+type Option func(*options)
+
+type options struct {
+	untagged bool
+	filter   feature.Filter
+}
+
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithUntagged sets whether elements without tags are read. By default they
+// are skipped, as they are typically the nodes of ways and the member ways of
+// relations.
+func WithUntagged(untagged bool) Option {
+	return func(o *options) {
+		o.untagged = untagged
+	}
+}
+
+// WithFeatureFilter sets a filter by which features are filtered when
+// reading. It is called with the type and ID of each element, for example
+// "way/123", and its tags. The geometries of the features that it rejects are
+// not assembled, but their elements are still kept for assembling others.
+func WithFeatureFilter(filter feature.Filter) Option {
+	return func(o *options) {
+		o.filter = filter
+	}
+}
+
+// supportedFeatures are the required features of files that are supported.
+var supportedFeatures = map[string]bool{
+	"OsmSchema-V0.6": true,
+	"DenseNodes":     true,
+}
+
+// areaKeys are the keys of tags that make closed ways areas, with the values
+// for which they do not. This is a subset of the rules used by common
+// renderers.
+var areaKeys = map[string]map[string]bool{
+	"amenity":  nil,
+	"building": nil,
+	"landuse":  nil,
+	"leisure":  nil,
+	"natural": {
+		"coastline": true,
+		"cliff":     true,
+		"ridge":     true,
+		"tree_row":  true,
+	},
+	"place":   nil,
+	"shop":    nil,
+	"tourism": nil,
+	"water":   nil,
+}
+
+// isArea returns whether a closed way with tags is an area.
+func isArea(tags map[string]string) bool {
+	switch tags["area"] {
+	case "yes":
+		return true
+	case "no":
+		return false
+	}
+	for key, value := range tags {
+		if exceptions, ok := areaKeys[key]; ok && !exceptions[value] {
+			return true
+		}
+	}
+	return false
+}
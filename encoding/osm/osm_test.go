@@ -0,0 +1,294 @@
+package osm
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"io"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/internal/protobuf"
+)
+
+var testStrings = []string{"", "name", "a", "building", "yes", "type", "multipolygon", "outer", "inner", "highway", "residential"}
+
+// appendBlock appends a block with type blockType and data to b, compressed
+// with zlib if compress is true.
+func appendBlock(t *testing.T, b []byte, blockType string, data []byte, compress bool) []byte {
+	t.Helper()
+	var blob []byte
+	if compress {
+		var z bytes.Buffer
+		zw := zlib.NewWriter(&z)
+		if _, err := zw.Write(data); err != nil {
+			t.Fatal(err)
+		}
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		blob = protobuf.AppendVarintField(blob, 2, uint64(len(data)))
+		blob = protobuf.AppendBytesField(blob, 3, z.Bytes())
+	} else {
+		blob = protobuf.AppendBytesField(blob, 1, data)
+	}
+	var header []byte
+	header = protobuf.AppendBytesField(header, 1, []byte(blockType))
+	header = protobuf.AppendVarintField(header, 3, uint64(len(blob)))
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], uint32(len(header)))
+	b = append(b, size[:]...)
+	b = append(b, header...)
+	return append(b, blob...)
+}
+
+// headerBlock returns a header block with requiredFeatures.
+func headerBlock(requiredFeatures ...string) []byte {
+	var data []byte
+	for _, feature := range requiredFeatures {
+		data = protobuf.AppendBytesField(data, 4, []byte(feature))
+	}
+	return data
+}
+
+// primitiveBlock returns a primitive block with testStrings and groups.
+func primitiveBlock(groups ...[]byte) []byte {
+	var st []byte
+	for _, s := range testStrings {
+		st = protobuf.AppendBytesField(st, 1, []byte(s))
+	}
+	data := protobuf.AppendBytesField(nil, 1, st)
+	for _, group := range groups {
+		data = protobuf.AppendBytesField(data, 2, group)
+	}
+	return data
+}
+
+// deltas returns the zigzag encoded deltas of vs.
+func deltas(vs ...int64) []uint64 {
+	result := make([]uint64, len(vs))
+	prev := int64(0)
+	for i, v := range vs {
+		result[i] = protobuf.Zigzag(v - prev)
+		prev = v
+	}
+	return result
+}
+
+// denseNodes returns a primitive group of dense nodes at longitudes and
+// latitudes in degrees, with interleaved keysValues.
+func denseNodes(ids []int64, lonLats []int64, keysValues []uint64) []byte {
+	var lats, lons []int64
+	for i := 0; i < len(lonLats); i += 2 {
+		lons = append(lons, lonLats[i]*1e7)
+		lats = append(lats, lonLats[i+1]*1e7)
+	}
+	var dense []byte
+	dense = protobuf.AppendPackedField(dense, 1, deltas(ids...))
+	dense = protobuf.AppendPackedField(dense, 8, deltas(lats...))
+	dense = protobuf.AppendPackedField(dense, 9, deltas(lons...))
+	dense = protobuf.AppendPackedField(dense, 10, keysValues)
+	return protobuf.AppendBytesField(nil, 2, dense)
+}
+
+// way returns a way with id, tags, and refs.
+func way(id int64, keys, values []uint64, refs ...int64) []byte {
+	var data []byte
+	data = protobuf.AppendVarintField(data, 1, uint64(id))
+	data = protobuf.AppendPackedField(data, 2, keys)
+	data = protobuf.AppendPackedField(data, 3, values)
+	return protobuf.AppendPackedField(data, 8, deltas(refs...))
+}
+
+// relation returns a relation with id, tags, and way members.
+func relation(id int64, keys, values, roles []uint64, memberIDs ...int64) []byte {
+	var data []byte
+	data = protobuf.AppendVarintField(data, 1, uint64(id))
+	data = protobuf.AppendPackedField(data, 2, keys)
+	data = protobuf.AppendPackedField(data, 3, values)
+	data = protobuf.AppendPackedField(data, 8, roles)
+	data = protobuf.AppendPackedField(data, 9, deltas(memberIDs...))
+	types := make([]uint64, len(memberIDs))
+	for i := range types {
+		types[i] = memberTypeWay
+	}
+	return protobuf.AppendPackedField(data, 10, types)
+}
+
+func testFile(t *testing.T) []byte {
+	t.Helper()
+	var node []byte
+	node = protobuf.AppendVarintField(node, 1, protobuf.Zigzag(9))
+	node = protobuf.AppendPackedField(node, 2, []uint64{1})
+	node = protobuf.AppendPackedField(node, 3, []uint64{2})
+	node = protobuf.AppendVarintField(node, 8, protobuf.Zigzag(10e7))
+	node = protobuf.AppendVarintField(node, 9, protobuf.Zigzag(10e7))
+	nodes := denseNodes(
+		[]int64{1, 2, 3, 4, 5, 6, 7, 8},
+		[]int64{0, 0, 4, 0, 4, 4, 0, 4, 1, 1, 1, 2, 2, 2, 2, 1},
+		[]uint64{0, 0, 0, 0, 0, 0, 0, 0},
+	)
+	var ways []byte
+	ways = protobuf.AppendBytesField(ways, 3, way(10, nil, nil, 1, 2, 3))
+	ways = protobuf.AppendBytesField(ways, 3, way(11, nil, nil, 1, 4, 3))
+	ways = protobuf.AppendBytesField(ways, 3, way(12, nil, nil, 5, 8, 7, 6, 5))
+	ways = protobuf.AppendBytesField(ways, 3, way(13, []uint64{3}, []uint64{4}, 1, 2, 3, 4, 1))
+	ways = protobuf.AppendBytesField(ways, 3, way(14, []uint64{9}, []uint64{10}, 9, 1))
+	ways = protobuf.AppendBytesField(ways, 3, way(15, []uint64{9}, []uint64{10}, 1, 99))
+	var relations []byte
+	relations = protobuf.AppendBytesField(relations, 4, relation(20, []uint64{5}, []uint64{6}, []uint64{7, 0, 8}, 10, 11, 12))
+	relations = protobuf.AppendBytesField(relations, 4, relation(21, []uint64{5}, []uint64{6}, []uint64{7}, 99))
+
+	var data []byte
+	data = appendBlock(t, data, "OSMHeader", headerBlock("OsmSchema-V0.6", "DenseNodes"), false)
+	data = appendBlock(t, data, "OSMData", primitiveBlock(nodes, protobuf.AppendBytesField(nil, 1, node)), true)
+	data = appendBlock(t, data, "Unknown", []byte{0xff}, false)
+	data = appendBlock(t, data, "OSMData", primitiveBlock(ways, relations), false)
+	return data
+}
+
+func TestReader(t *testing.T) {
+	got, err := NewReader(bytes.NewReader(testFile(t))).ReadAll()
+	if err != nil {
+		t.Fatalf("ReadAll() == _, %v, want _, <nil>", err)
+	}
+	want := []*Feature{
+		{
+			Type: Node,
+			ID:   9,
+			Tags: map[string]string{"name": "a"},
+			Geom: geom.NewPointFlat(geom.XY, []float64{10, 10}),
+		},
+		{
+			Type: Way,
+			ID:   13,
+			Tags: map[string]string{"building": "yes"},
+			Geom: geom.NewPolygonFlat(geom.XY, []float64{0, 0, 4, 0, 4, 4, 0, 4, 0, 0}, []int{10}),
+		},
+		{
+			Type: Way,
+			ID:   14,
+			Tags: map[string]string{"highway": "residential"},
+			Geom: geom.NewLineStringFlat(geom.XY, []float64{10, 10, 0, 0}),
+		},
+		{
+			Type: Relation,
+			ID:   20,
+			Tags: map[string]string{"type": "multipolygon"},
+			Geom: geom.NewMultiPolygonFlat(geom.XY, []float64{
+				0, 0, 4, 0, 4, 4, 0, 4, 0, 0,
+				1, 1, 1, 2, 2, 2, 2, 1, 1, 1,
+			}, [][]int{{10, 20}}),
+		},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("len(got) == %d, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if !reflect.DeepEqual(got[i], want[i]) {
+			t.Errorf("got[%d] == %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestReaderUntagged(t *testing.T) {
+	got, err := NewReader(bytes.NewReader(testFile(t)), WithUntagged(true)).ReadAll()
+	if err != nil {
+		t.Fatalf("ReadAll() == _, %v, want _, <nil>", err)
+	}
+	counts := make(map[ElementType]int)
+	for _, f := range got {
+		counts[f.Type]++
+	}
+	if want := map[ElementType]int{Node: 9, Way: 5, Relation: 1}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("counts == %v, want %v", counts, want)
+	}
+}
+
+func TestReaderFeatureFilter(t *testing.T) {
+	var ids []string
+	filter := func(id string, properties map[string]interface{}) bool {
+		ids = append(ids, id)
+		return properties["highway"] == nil
+	}
+	got, err := NewReader(bytes.NewReader(testFile(t)), WithFeatureFilter(filter)).ReadAll()
+	if err != nil {
+		t.Fatalf("ReadAll() == _, %v, want _, <nil>", err)
+	}
+	var gotIDs []string
+	for _, f := range got {
+		gotIDs = append(gotIDs, f.Type.String()+"/"+strconv.FormatInt(f.ID, 10))
+	}
+	if want := []string{"node/9", "way/13", "way/14", "way/15", "relation/20", "relation/21"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("ids == %v, want %v", ids, want)
+	}
+	if want := []string{"node/9", "way/13", "relation/20"}; !reflect.DeepEqual(gotIDs, want) {
+		t.Errorf("gotIDs == %v, want %v", gotIDs, want)
+	}
+}
+
+func TestReaderErrors(t *testing.T) {
+	data := testFile(t)
+	lzmaBlob := protobuf.AppendBytesField(nil, 4, []byte{0})
+	for _, tc := range []struct {
+		name string
+		data []byte
+		err  error
+	}{
+		{
+			name: "unsupported_feature",
+			data: appendBlock(t, nil, "OSMHeader", headerBlock("HistoricalInformation"), false),
+			err:  ErrUnsupportedFeature("HistoricalInformation"),
+		},
+		{
+			name: "truncated",
+			data: data[:len(data)-1],
+			err:  io.ErrUnexpectedEOF,
+		},
+		{
+			name: "unsupported_compression",
+			data: append([]byte{0, 0, 0, 11}, append(protobuf.AppendVarintField(protobuf.AppendBytesField(nil, 1, []byte("OSMData")), 3, uint64(len(lzmaBlob))), lzmaBlob...)...),
+			err:  errUnsupportedCompression,
+		},
+		{
+			name: "invalid_string_index",
+			data: appendBlock(t, nil, "OSMData", primitiveBlock(protobuf.AppendBytesField(nil, 3, way(1, []uint64{100}, []uint64{1}))), false),
+			err:  errInvalidData,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewReader(bytes.NewReader(tc.data))
+			var err error
+			for err == nil {
+				_, err = r.Read()
+			}
+			if err != tc.err {
+				t.Errorf("r.Read() == _, %v, want _, %v", err, tc.err)
+			}
+			if _, err2 := r.Read(); err2 != err {
+				t.Errorf("r.Read() == _, %v, want _, %v", err2, err)
+			}
+		})
+	}
+}
+
+func TestIsArea(t *testing.T) {
+	for _, tc := range []struct {
+		tags map[string]string
+		want bool
+	}{
+		{tags: nil, want: false},
+		{tags: map[string]string{"highway": "pedestrian"}, want: false},
+		{tags: map[string]string{"highway": "pedestrian", "area": "yes"}, want: true},
+		{tags: map[string]string{"building": "yes"}, want: true},
+		{tags: map[string]string{"building": "yes", "area": "no"}, want: false},
+		{tags: map[string]string{"natural": "wood"}, want: true},
+		{tags: map[string]string{"natural": "coastline"}, want: false},
+	} {
+		if got := isArea(tc.tags); got != tc.want {
+			t.Errorf("isArea(%v) == %t, want %t", tc.tags, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,584 @@
+package osm
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"io"
+	"strconv"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/internal/protobuf"
+)
+
+// Maximum sizes of blob headers and blobs, from the specification.
+const (
+	maxBlobHeaderSize = 64 * 1024
+	maxBlobSize       = 32 * 1024 * 1024
+)
+
+// defaultGranularity is the default granularity of coordinates, in
+// nanodegrees.
+const defaultGranularity = 100
+
+// Relation member types.
+const (
+	memberTypeNode     = 0
+	memberTypeWay      = 1
+	memberTypeRelation = 2
+)
+
+// A Reader reads the features of an OpenStreetMap PBF file sequentially.
+type Reader struct {
+	r        io.Reader
+	o        options
+	nodes    map[int64][2]float64
+	ways     map[int64][]int64
+	features []*Feature
+	err      error
+}
+
+// A block is the context of the elements of a primitive block.
+type block struct {
+	strings     []string
+	granularity int64
+	latOffset   int64
+	lonOffset   int64
+}
+
+// A member is a member of a relation.
+type member struct {
+	memberType uint64
+	id         int64
+	role       string
+}
+
+// NewReader returns a new Reader that reads from r.
+func NewReader(r io.Reader, opts ...Option) *Reader {
+	return &Reader{
+		r:     r,
+		o:     newOptions(opts),
+		nodes: make(map[int64][2]float64),
+		ways:  make(map[int64][]int64),
+	}
+}
+
+// Read returns the next feature. It returns io.EOF after the last feature.
+// Errors are sticky.
+func (r *Reader) Read() (*Feature, error) {
+	for len(r.features) == 0 {
+		if r.err != nil {
+			return nil, r.err
+		}
+		r.features, r.err = r.readBlock()
+	}
+	f := r.features[0]
+	r.features[0] = nil
+	r.features = r.features[1:]
+	return f, nil
+}
+
+// ReadAll returns all remaining features.
+func (r *Reader) ReadAll() ([]*Feature, error) {
+	var features []*Feature
+	for {
+		f, err := r.Read()
+		switch {
+		case err == io.EOF:
+			return features, nil
+		case err != nil:
+			return nil, err
+		}
+		features = append(features, f)
+	}
+}
+
+// readBlock reads the next block and returns its features.
+func (r *Reader) readBlock() ([]*Feature, error) {
+	var sizeData [4]byte
+	if _, err := io.ReadFull(r.r, sizeData[:]); err != nil {
+		return nil, err
+	}
+	headerSize := binary.BigEndian.Uint32(sizeData[:])
+	if headerSize > maxBlobHeaderSize {
+		return nil, errInvalidData
+	}
+	headerData := make([]byte, headerSize)
+	if _, err := io.ReadFull(r.r, headerData); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	blockType, blobSize, err := decodeBlobHeader(headerData)
+	if err != nil {
+		return nil, err
+	}
+	blobData := make([]byte, blobSize)
+	if _, err := io.ReadFull(r.r, blobData); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	switch blockType {
+	case "OSMHeader":
+		data, err := decodeBlob(blobData)
+		if err != nil {
+			return nil, err
+		}
+		return nil, decodeHeaderBlock(data)
+	case "OSMData":
+		data, err := decodeBlob(blobData)
+		if err != nil {
+			return nil, err
+		}
+		return r.decodePrimitiveBlock(data)
+	default:
+		// Unknown blocks are skipped, as required by the specification.
+		return nil, nil
+	}
+}
+
+// decodeBlobHeader returns the type and the size of the blob of the blob
+// header in data.
+func decodeBlobHeader(data []byte) (string, int, error) {
+	var blockType string
+	blobSize := -1
+	d := protobuf.NewDecoder(data)
+	for d.Next() {
+		switch d.Field() {
+		case 1:
+			blockType = string(d.Bytes())
+		case 3:
+			blobSize = int(d.Varint())
+		default:
+			d.Skip()
+		}
+	}
+	if d.Err() != nil || blobSize < 0 || blobSize > maxBlobSize {
+		return "", 0, errInvalidData
+	}
+	return blockType, blobSize, nil
+}
+
+// decodeBlob returns the uncompressed contents of the blob in data.
+func decodeBlob(data []byte) ([]byte, error) {
+	var raw, zlibData []byte
+	rawSize := -1
+	compressed := false
+	d := protobuf.NewDecoder(data)
+	for d.Next() {
+		switch d.Field() {
+		case 1:
+			raw = d.Bytes()
+		case 2:
+			rawSize = int(d.Varint())
+		case 3:
+			zlibData = d.Bytes()
+			compressed = true
+		case 4, 5, 6, 7:
+			return nil, errUnsupportedCompression
+		default:
+			d.Skip()
+		}
+	}
+	switch {
+	case d.Err() != nil:
+		return nil, errInvalidData
+	case !compressed:
+		return raw, nil
+	case rawSize < 0 || rawSize > maxBlobSize:
+		return nil, errInvalidData
+	}
+	zr, err := zlib.NewReader(bytes.NewReader(zlibData))
+	if err != nil {
+		return nil, errInvalidData
+	}
+	raw = make([]byte, rawSize)
+	if _, err := io.ReadFull(zr, raw); err != nil {
+		return nil, errInvalidData
+	}
+	return raw, nil
+}
+
+// decodeHeaderBlock checks that the required features of the header block in
+// data are supported.
+func decodeHeaderBlock(data []byte) error {
+	d := protobuf.NewDecoder(data)
+	for d.Next() {
+		switch d.Field() {
+		case 4:
+			if feature := string(d.Bytes()); d.Err() == nil && !supportedFeatures[feature] {
+				return ErrUnsupportedFeature(feature)
+			}
+		default:
+			d.Skip()
+		}
+	}
+	if d.Err() != nil {
+		return errInvalidData
+	}
+	return nil
+}
+
+// decodePrimitiveBlock returns the features of the primitive block in data.
+func (r *Reader) decodePrimitiveBlock(data []byte) ([]*Feature, error) {
+	b := &block{
+		granularity: defaultGranularity,
+	}
+	var groups [][]byte
+	d := protobuf.NewDecoder(data)
+	for d.Next() {
+		switch d.Field() {
+		case 1:
+			st := protobuf.NewDecoder(d.Bytes())
+			for st.Next() {
+				if st.Field() == 1 {
+					b.strings = append(b.strings, string(st.Bytes()))
+				} else {
+					st.Skip()
+				}
+			}
+			if st.Err() != nil {
+				return nil, errInvalidData
+			}
+		case 2:
+			groups = append(groups, d.Bytes())
+		case 17:
+			b.granularity = int64(d.Varint())
+		case 19:
+			b.latOffset = int64(d.Varint())
+		case 20:
+			b.lonOffset = int64(d.Varint())
+		default:
+			d.Skip()
+		}
+	}
+	if d.Err() != nil {
+		return nil, errInvalidData
+	}
+	var features []*Feature
+	for _, group := range groups {
+		var err error
+		if features, err = r.decodePrimitiveGroup(features, b, group); err != nil {
+			return nil, err
+		}
+	}
+	return features, nil
+}
+
+// decodePrimitiveGroup appends the features of the primitive group in data to
+// features.
+func (r *Reader) decodePrimitiveGroup(features []*Feature, b *block, data []byte) ([]*Feature, error) {
+	d := protobuf.NewDecoder(data)
+	for d.Next() {
+		var err error
+		switch d.Field() {
+		case 1:
+			features, err = r.decodeNode(features, b, d.Bytes())
+		case 2:
+			features, err = r.decodeDenseNodes(features, b, d.Bytes())
+		case 3:
+			features, err = r.decodeWay(features, b, d.Bytes())
+		case 4:
+			features, err = r.decodeRelation(features, b, d.Bytes())
+		default:
+			d.Skip()
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if d.Err() != nil {
+		return nil, errInvalidData
+	}
+	return features, nil
+}
+
+// decodeNode appends the feature of the node in data to features.
+func (r *Reader) decodeNode(features []*Feature, b *block, data []byte) ([]*Feature, error) {
+	var id, lat, lon int64
+	var keys, values []uint64
+	d := protobuf.NewDecoder(data)
+	for d.Next() {
+		switch d.Field() {
+		case 1:
+			id = protobuf.Unzigzag(d.Varint())
+		case 2:
+			keys = d.Varints(keys)
+		case 3:
+			values = d.Varints(values)
+		case 8:
+			lat = protobuf.Unzigzag(d.Varint())
+		case 9:
+			lon = protobuf.Unzigzag(d.Varint())
+		default:
+			d.Skip()
+		}
+	}
+	if d.Err() != nil {
+		return nil, errInvalidData
+	}
+	tags, err := b.tags(keys, values)
+	if err != nil {
+		return nil, err
+	}
+	return r.addNode(features, id, b.location(lat, lon), tags), nil
+}
+
+// decodeDenseNodes appends the features of the dense nodes in data to
+// features.
+func (r *Reader) decodeDenseNodes(features []*Feature, b *block, data []byte) ([]*Feature, error) {
+	var ids, lats, lons, keysValues []uint64
+	d := protobuf.NewDecoder(data)
+	for d.Next() {
+		switch d.Field() {
+		case 1:
+			ids = d.Varints(ids)
+		case 8:
+			lats = d.Varints(lats)
+		case 9:
+			lons = d.Varints(lons)
+		case 10:
+			keysValues = d.Varints(keysValues)
+		default:
+			d.Skip()
+		}
+	}
+	if d.Err() != nil || len(lats) != len(ids) || len(lons) != len(ids) {
+		return nil, errInvalidData
+	}
+	var id, lat, lon int64
+	for i := range ids {
+		id += protobuf.Unzigzag(ids[i])
+		lat += protobuf.Unzigzag(lats[i])
+		lon += protobuf.Unzigzag(lons[i])
+		var tags map[string]string
+		if len(keysValues) > 0 {
+			// The keys and values of each node are terminated by a zero.
+			n := 0
+			for n < len(keysValues) && keysValues[n] != 0 {
+				n += 2
+			}
+			if n >= len(keysValues) {
+				return nil, errInvalidData
+			}
+			var err error
+			if tags, err = b.tagsInterleaved(keysValues[:n]); err != nil {
+				return nil, err
+			}
+			keysValues = keysValues[n+1:]
+		}
+		features = r.addNode(features, id, b.location(lat, lon), tags)
+	}
+	return features, nil
+}
+
+// decodeWay appends the feature of the way in data to features.
+func (r *Reader) decodeWay(features []*Feature, b *block, data []byte) ([]*Feature, error) {
+	var id int64
+	var keys, values, refDeltas []uint64
+	d := protobuf.NewDecoder(data)
+	for d.Next() {
+		switch d.Field() {
+		case 1:
+			id = int64(d.Varint())
+		case 2:
+			keys = d.Varints(keys)
+		case 3:
+			values = d.Varints(values)
+		case 8:
+			refDeltas = d.Varints(refDeltas)
+		default:
+			d.Skip()
+		}
+	}
+	if d.Err() != nil {
+		return nil, errInvalidData
+	}
+	tags, err := b.tags(keys, values)
+	if err != nil {
+		return nil, err
+	}
+	refs := make([]int64, len(refDeltas))
+	var ref int64
+	for i, delta := range refDeltas {
+		ref += protobuf.Unzigzag(delta)
+		refs[i] = ref
+	}
+	r.ways[id] = refs
+	if !r.accept(Way, id, tags) {
+		return features, nil
+	}
+	g := r.wayGeom(refs, tags)
+	if g == nil {
+		return features, nil
+	}
+	return append(features, &Feature{
+		Type: Way,
+		ID:   id,
+		Tags: tags,
+		Geom: g,
+	}), nil
+}
+
+// decodeRelation appends the feature of the relation in data to features.
+func (r *Reader) decodeRelation(features []*Feature, b *block, data []byte) ([]*Feature, error) {
+	var id int64
+	var keys, values, roles, memberIDDeltas, memberTypes []uint64
+	d := protobuf.NewDecoder(data)
+	for d.Next() {
+		switch d.Field() {
+		case 1:
+			id = int64(d.Varint())
+		case 2:
+			keys = d.Varints(keys)
+		case 3:
+			values = d.Varints(values)
+		case 8:
+			roles = d.Varints(roles)
+		case 9:
+			memberIDDeltas = d.Varints(memberIDDeltas)
+		case 10:
+			memberTypes = d.Varints(memberTypes)
+		default:
+			d.Skip()
+		}
+	}
+	if d.Err() != nil || len(roles) != len(memberIDDeltas) || len(memberTypes) != len(memberIDDeltas) {
+		return nil, errInvalidData
+	}
+	tags, err := b.tags(keys, values)
+	if err != nil {
+		return nil, err
+	}
+	if relationType := tags["type"]; relationType != "multipolygon" && relationType != "boundary" || !r.accept(Relation, id, tags) {
+		return features, nil
+	}
+	members := make([]member, len(memberIDDeltas))
+	var memberID int64
+	for i := range members {
+		if roles[i] >= uint64(len(b.strings)) {
+			return nil, errInvalidData
+		}
+		memberID += protobuf.Unzigzag(memberIDDeltas[i])
+		members[i] = member{
+			memberType: memberTypes[i],
+			id:         memberID,
+			role:       b.strings[roles[i]],
+		}
+	}
+	mp := r.assembleMultiPolygon(members)
+	if mp == nil {
+		return features, nil
+	}
+	return append(features, &Feature{
+		Type: Relation,
+		ID:   id,
+		Tags: tags,
+		Geom: mp,
+	}), nil
+}
+
+// addNode records the location of a node and appends its feature to
+// features.
+func (r *Reader) addNode(features []*Feature, id int64, location [2]float64, tags map[string]string) []*Feature {
+	r.nodes[id] = location
+	if !r.accept(Node, id, tags) {
+		return features
+	}
+	return append(features, &Feature{
+		Type: Node,
+		ID:   id,
+		Tags: tags,
+		Geom: geom.NewPointFlat(geom.XY, location[:]),
+	})
+}
+
+// accept returns whether the element with elementType, id, and tags is read
+// as a feature.
+func (r *Reader) accept(elementType ElementType, id int64, tags map[string]string) bool {
+	if len(tags) == 0 && !r.o.untagged {
+		return false
+	}
+	if r.o.filter == nil {
+		return true
+	}
+	properties := make(map[string]interface{}, len(tags))
+	for key, value := range tags {
+		properties[key] = value
+	}
+	return r.o.filter(elementType.String()+"/"+strconv.FormatInt(id, 10), properties)
+}
+
+// wayGeom returns the geometry of a way with refs and tags, or nil if any of
+// its nodes are missing.
+func (r *Reader) wayGeom(refs []int64, tags map[string]string) geom.T {
+	if len(refs) < 2 {
+		return nil
+	}
+	flatCoords, ok := r.flatCoords(nil, refs)
+	if !ok {
+		return nil
+	}
+	if len(refs) >= 4 && refs[0] == refs[len(refs)-1] && isArea(tags) {
+		return geom.NewPolygonFlat(geom.XY, flatCoords, []int{len(flatCoords)})
+	}
+	return geom.NewLineStringFlat(geom.XY, flatCoords)
+}
+
+// flatCoords appends the locations of the nodes refs to flatCoords. It
+// returns false if any of the nodes are missing.
+func (r *Reader) flatCoords(flatCoords []float64, refs []int64) ([]float64, bool) {
+	for _, ref := range refs {
+		location, ok := r.nodes[ref]
+		if !ok {
+			return nil, false
+		}
+		flatCoords = append(flatCoords, location[0], location[1])
+	}
+	return flatCoords, true
+}
+
+// location returns the longitude and latitude of the node at lat and lon.
+func (b *block) location(lat, lon int64) [2]float64 {
+	// Dividing, rather than multiplying by 1e-9, rounds correctly.
+	return [2]float64{
+		float64(b.lonOffset+b.granularity*lon) / 1e9,
+		float64(b.latOffset+b.granularity*lat) / 1e9,
+	}
+}
+
+// tags returns the tags with keys and values, which are indexes into the
+// string table, or nil if there are none.
+func (b *block) tags(keys, values []uint64) (map[string]string, error) {
+	if len(keys) != len(values) {
+		return nil, errInvalidData
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	tags := make(map[string]string, len(keys))
+	for i, key := range keys {
+		if key >= uint64(len(b.strings)) || values[i] >= uint64(len(b.strings)) {
+			return nil, errInvalidData
+		}
+		tags[b.strings[key]] = b.strings[values[i]]
+	}
+	return tags, nil
+}
+
+// tagsInterleaved returns the tags with interleaved keys and values, as in
+// dense nodes.
+func (b *block) tagsInterleaved(keysValues []uint64) (map[string]string, error) {
+	keys := make([]uint64, 0, len(keysValues)/2)
+	values := make([]uint64, 0, len(keysValues)/2)
+	for i := 0; i+1 < len(keysValues); i += 2 {
+		keys = append(keys, keysValues[i])
+		values = append(values, keysValues[i+1])
+	}
+	return b.tags(keys, values)
+}
+
+// unexpectedEOF returns err, replacing io.EOF with io.ErrUnexpectedEOF.
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,188 @@
+// Package polyline implements Google's encoded polyline algorithm.
+//
+// An encoded polyline is a printable ASCII string of the latitudes and
+// longitudes of a sequence of points, rounded to a fixed number of decimal
+// places and stored as variable-length deltas. It is used by many routing
+// APIs, typically with five decimal places, or six by OSRM and Valhalla. See
+// https://developers.google.com/maps/documentation/utilities/polylinealgorithm.
+//
+// Geometries must have the XY layout, with X as longitude and Y as latitude.
+// The encoding does not record the geometry type, so the caller chooses
+// whether to decode a LineString or a MultiPoint.
+package polyline
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/twpayne/go-geom"
+)
+
+// Common precisions.
+const (
+	Precision5 = 5
+	Precision6 = 6
+)
+
+// DefaultPrecision is the default number of decimal places, as used by
+// Google.
+const DefaultPrecision = Precision5
+
+// maxPrecision is the maximum number of decimal places. Larger precisions
+// would overflow the encoding of longitudes.
+const maxPrecision = 10
+
+var (
+	errCoordinateOutOfRange = errors.New("polyline: coordinate out of range")
+	errInvalidData          = errors.New("polyline: invalid data")
+)
+
+// An ErrInvalidPrecision is returned when a precision is out of range.
+type ErrInvalidPrecision int
+
+func (e ErrInvalidPrecision) Error() string {
+	return fmt.Sprintf("polyline: invalid precision: %d", int(e))
+}
+
+// An Option configures encoding and decoding.
+type Option func(*options)
+
+type options struct {
+	precision int
+}
+
+func newOptions(opts []Option) (options, error) {
+	o := options{
+		precision: DefaultPrecision,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.precision < 0 || o.precision > maxPrecision {
+		return options{}, ErrInvalidPrecision(o.precision)
+	}
+	return o, nil
+}
+
+// WithPrecision sets the number of decimal places, which must be between 0
+// and 10. The default is DefaultPrecision. Data must be decoded with the
+// precision with which they were encoded.
+func WithPrecision(precision int) Option {
+	return func(o *options) {
+		o.precision = precision
+	}
+}
+
+// Marshal encodes a LineString or MultiPoint as an encoded polyline.
+func Marshal(g geom.T, opts ...Option) ([]byte, error) {
+	o, err := newOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	switch g := g.(type) {
+	case *geom.LineString:
+	case *geom.MultiPoint:
+		for i := 0; i < g.NumPoints(); i++ {
+			if g.Point(i).Empty() {
+				return nil, geom.ErrUnsupportedType{Value: g}
+			}
+		}
+	default:
+		return nil, geom.ErrUnsupportedType{Value: g}
+	}
+	if g.Layout() != geom.XY {
+		return nil, geom.ErrUnsupportedLayout(g.Layout())
+	}
+	scale := math.Pow10(o.precision)
+	flatCoords := g.FlatCoords()
+	data := make([]byte, 0, 2*len(flatCoords))
+	var lat, lng int64
+	for i := 0; i < len(flatCoords); i += 2 {
+		x, y := math.Round(flatCoords[i]*scale), math.Round(flatCoords[i+1]*scale)
+		if !(math.Abs(x) <= 1<<53 && math.Abs(y) <= 1<<53) {
+			return nil, errCoordinateOutOfRange
+		}
+		data = appendValue(data, int64(y)-lat)
+		data = appendValue(data, int64(x)-lng)
+		lat, lng = int64(y), int64(x)
+	}
+	return data, nil
+}
+
+// Unmarshal decodes an encoded polyline as a LineString.
+func Unmarshal(data []byte, opts ...Option) (*geom.LineString, error) {
+	flatCoords, err := decode(data, opts)
+	if err != nil {
+		return nil, err
+	}
+	return geom.NewLineStringFlat(geom.XY, flatCoords), nil
+}
+
+// UnmarshalMultiPoint decodes an encoded polyline as a MultiPoint.
+func UnmarshalMultiPoint(data []byte, opts ...Option) (*geom.MultiPoint, error) {
+	flatCoords, err := decode(data, opts)
+	if err != nil {
+		return nil, err
+	}
+	return geom.NewMultiPointFlat(geom.XY, flatCoords), nil
+}
+
+// appendValue appends the encoding of v to data.
+func appendValue(data []byte, v int64) []byte {
+	u := uint64(v) << 1
+	if v < 0 {
+		u = ^u
+	}
+	for u >= 0x20 {
+		data = append(data, byte(0x20|u&0x1f)+63)
+		u >>= 5
+	}
+	return append(data, byte(u)+63)
+}
+
+// decode returns the flat coordinates encoded in data.
+func decode(data []byte, opts []Option) ([]float64, error) {
+	o, err := newOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	scale := math.Pow10(o.precision)
+	var flatCoords []float64
+	var lat, lng int64
+	for len(data) > 0 {
+		var dLat, dLng int64
+		if dLat, data, err = decodeValue(data); err != nil {
+			return nil, err
+		}
+		if dLng, data, err = decodeValue(data); err != nil {
+			return nil, err
+		}
+		lat += dLat
+		lng += dLng
+		flatCoords = append(flatCoords, float64(lng)/scale, float64(lat)/scale)
+	}
+	return flatCoords, nil
+}
+
+// decodeValue decodes a value from the start of data and returns it with the
+// remaining data.
+func decodeValue(data []byte) (int64, []byte, error) {
+	var u uint64
+	for i, shift := 0, uint(0); i < len(data); i, shift = i+1, shift+5 {
+		c := data[i]
+		if c < 63 || c > 126 || shift > 60 {
+			return 0, nil, errInvalidData
+		}
+		c -= 63
+		u |= uint64(c&0x1f) << shift
+		if c&0x20 == 0 {
+			v := int64(u >> 1)
+			if u&1 != 0 {
+				v = ^v
+			}
+			return v, data[i+1:], nil
+		}
+	}
+	return 0, nil, errInvalidData
+}
@@ -0,0 +1,121 @@
+package polyline
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+)
+
+func TestMarshalAndUnmarshal(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		flatCoords []float64
+		opts       []Option
+		s          string
+	}{
+		{
+			name: "empty",
+			s:    "",
+		},
+		{
+			name:       "google",
+			flatCoords: []float64{-120.2, 38.5, -120.95, 40.7, -126.453, 43.252},
+			s:          "_p~iF~ps|U_ulLnnqC_mqNvxq`@",
+		},
+		{
+			name:       "precision6",
+			flatCoords: []float64{-120.2, 38.5, -120.95, 40.7, -126.453, 43.252},
+			opts:       []Option{WithPrecision(Precision6)},
+			s:          "_izlhA~rlgdF_{geC~ywl@_kwzCn`{nI",
+		},
+		{
+			name:       "origin",
+			flatCoords: []float64{0, 0, 0.00001, -0.00001},
+			s:          "??@A",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ls := geom.NewLineStringFlat(geom.XY, tc.flatCoords)
+			got, err := Marshal(ls, tc.opts...)
+			if err != nil {
+				t.Fatalf("Marshal(...) == _, %v, want _, <nil>", err)
+			}
+			if string(got) != tc.s {
+				t.Errorf("Marshal(...) == %q, _, want %q, _", got, tc.s)
+			}
+			gotLineString, err := Unmarshal([]byte(tc.s), tc.opts...)
+			if err != nil {
+				t.Fatalf("Unmarshal(...) == _, %v, want _, <nil>", err)
+			}
+			if !reflect.DeepEqual(gotLineString, ls) {
+				t.Errorf("Unmarshal(...) == %#v, _, want %#v, _", gotLineString, ls)
+			}
+			mp := geom.NewMultiPointFlat(geom.XY, tc.flatCoords)
+			got, err = Marshal(mp, tc.opts...)
+			if err != nil {
+				t.Fatalf("Marshal(...) == _, %v, want _, <nil>", err)
+			}
+			if string(got) != tc.s {
+				t.Errorf("Marshal(...) == %q, _, want %q, _", got, tc.s)
+			}
+			gotMultiPoint, err := UnmarshalMultiPoint([]byte(tc.s), tc.opts...)
+			if err != nil {
+				t.Fatalf("UnmarshalMultiPoint(...) == _, %v, want _, <nil>", err)
+			}
+			if !reflect.DeepEqual(gotMultiPoint, mp) {
+				t.Errorf("UnmarshalMultiPoint(...) == %#v, _, want %#v, _", gotMultiPoint, mp)
+			}
+		})
+	}
+}
+
+func TestMarshalErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		g    geom.T
+		opts []Option
+		err  error
+	}{
+		{
+			name: "point",
+			g:    geom.NewPointFlat(geom.XY, []float64{1, 2}),
+			err:  geom.ErrUnsupportedType{Value: geom.NewPointFlat(geom.XY, []float64{1, 2})},
+		},
+		{
+			name: "xyz",
+			g:    geom.NewLineStringFlat(geom.XYZ, []float64{1, 2, 3, 4, 5, 6}),
+			err:  geom.ErrUnsupportedLayout(geom.XYZ),
+		},
+		{
+			name: "invalid_precision",
+			g:    geom.NewLineStringFlat(geom.XY, []float64{1, 2, 3, 4}),
+			opts: []Option{WithPrecision(11)},
+			err:  ErrInvalidPrecision(11),
+		},
+		{
+			name: "out_of_range",
+			g:    geom.NewLineStringFlat(geom.XY, []float64{1e300, 0}),
+			err:  errCoordinateOutOfRange,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := Marshal(tc.g, tc.opts...); !reflect.DeepEqual(err, tc.err) {
+				t.Errorf("Marshal(...) == _, %v, want _, %v", err, tc.err)
+			}
+		})
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	for _, s := range []string{
+		"_p~iF",
+		"_p~iF~ps|",
+		"_p~iF ps|U",
+		"~~~~~~~~~~~~~~?",
+	} {
+		if _, err := Unmarshal([]byte(s)); err != errInvalidData {
+			t.Errorf("Unmarshal(%q) == _, %v, want _, %v", s, err, errInvalidData)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package shp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var errInvalidDBFHeader = errors.New("shp: invalid dBASE header")
+
+// A Field describes a dBASE field.
+type Field struct {
+	Name     string
+	Type     byte // for example 'C' for character or 'N' for numeric
+	Length   int
+	Decimals int
+}
+
+// A dbfHeader is the header of a .dbf file.
+type dbfHeader struct {
+	numRecords   int
+	headerLength int
+	recordLength int
+	fields       []Field
+}
+
+// readDBFHeader reads the header of the .dbf file r.
+func readDBFHeader(r io.Reader) (*dbfHeader, error) {
+	var prefix [32]byte
+	if _, err := io.ReadFull(r, prefix[:]); err != nil {
+		return nil, err
+	}
+	h := &dbfHeader{
+		numRecords:   int(binary.LittleEndian.Uint32(prefix[4:])),
+		headerLength: int(binary.LittleEndian.Uint16(prefix[8:])),
+		recordLength: int(binary.LittleEndian.Uint16(prefix[10:])),
+	}
+	if h.headerLength < 33 || h.recordLength < 1 {
+		return nil, errInvalidDBFHeader
+	}
+	descriptors := make([]byte, h.headerLength-32)
+	if _, err := io.ReadFull(r, descriptors); err != nil {
+		return nil, err
+	}
+	length := 1 // deletion flag
+	for i := 0; i+32 <= len(descriptors) && descriptors[i] != 0x0d; i += 32 {
+		descriptor := descriptors[i : i+32]
+		name := descriptor[:11]
+		if j := bytes.IndexByte(name, 0); j != -1 {
+			name = name[:j]
+		}
+		field := Field{
+			Name:     string(name),
+			Type:     descriptor[11],
+			Length:   int(descriptor[16]),
+			Decimals: int(descriptor[17]),
+		}
+		h.fields = append(h.fields, field)
+		length += field.Length
+	}
+	if length > h.recordLength {
+		return nil, errInvalidDBFHeader
+	}
+	return h, nil
+}
+
+// decodeRecord decodes the attributes of the .dbf record data. Character
+// fields are decoded as strings with trailing spaces removed, numeric fields
+// as int64s if they have no decimals and float64s otherwise, logical fields
+// as bools, and date fields as time.Times. Empty and unknown values are
+// decoded as nil. Other fields are decoded as strings.
+func (h *dbfHeader) decodeRecord(data []byte) map[string]interface{} {
+	attributes := make(map[string]interface{}, len(h.fields))
+	offset := 1 // deletion flag
+	for _, field := range h.fields {
+		s := string(data[offset : offset+field.Length])
+		offset += field.Length
+		attributes[field.Name] = decodeValue(field, s)
+	}
+	return attributes
+}
+
+// decodeValue decodes the value s of field.
+func decodeValue(field Field, s string) interface{} {
+	switch field.Type {
+	case 'C':
+		return strings.TrimRight(s, " \x00")
+	case 'N', 'F':
+		s = strings.TrimSpace(s)
+		if field.Type == 'N' && field.Decimals == 0 {
+			if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+				return i
+			}
+		}
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f
+		}
+		return nil
+	case 'L':
+		switch s {
+		case "T", "t", "Y", "y":
+			return true
+		case "F", "f", "N", "n":
+			return false
+		default:
+			return nil
+		}
+	case 'D':
+		if t, err := time.Parse("20060102", strings.TrimSpace(s)); err == nil {
+			return t
+		}
+		return nil
+	default:
+		return s
+	}
+}
@@ -0,0 +1,452 @@
+package shp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math"
+	"strconv"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/feature"
+)
+
+var errDBFNotReaderAt = errors.New("shp: dBASE file does not implement io.ReaderAt")
+
+// An Option sets an option on a Reader or IndexedReader.
+type Option func(*options)
+
+type options struct {
+	dbf    io.Reader
+	bounds *geom.Bounds
+	filter feature.Filter
+}
+
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithDBF sets the .dbf file from which the attributes of records are read.
+// Strings are returned as they are encoded in the file, without conversion
+// from the code page given by any .cpg file. An IndexedReader requires dbf to
+// implement io.ReaderAt.
+func WithDBF(dbf io.Reader) Option {
+	return func(o *options) {
+		o.dbf = dbf
+	}
+}
+
+// WithBounds sets bounds by which records are filtered. Records whose
+// bounding boxes do not overlap bounds in X and Y, and Null records, are
+// skipped without being decoded. A Reader reads only their bounding boxes,
+// and seeks past the rest of them if the .shp file implements io.Seeker.
+func WithBounds(bounds *geom.Bounds) Option {
+	return func(o *options) {
+		o.bounds = bounds
+	}
+}
+
+// WithFeatureFilter sets a filter by which records are filtered after any
+// bounds. It is called with the record number and the attributes of each
+// record, which are nil if there is no .dbf file, and the shapes of the
+// records that it rejects are skipped without being decoded.
+func WithFeatureFilter(filter feature.Filter) Option {
+	return func(o *options) {
+		o.filter = filter
+	}
+}
+
+// maxAllocSize is the maximum size of a record's content that is allocated
+// before it is read.
+const maxAllocSize = 1 << 20
+
+// A Reader reads the records of a .shp file sequentially.
+type Reader struct {
+	r         io.Reader
+	header    Header
+	dbf       io.Reader
+	dbfHeader *dbfHeader
+	bounds    *geom.Bounds
+	filter    feature.Filter
+	offset    int64
+	err       error
+}
+
+// NewReader returns a new Reader that reads the .shp file shp.
+func NewReader(shp io.Reader, opts ...Option) (*Reader, error) {
+	o := newOptions(opts)
+	var data [headerSize]byte
+	if _, err := io.ReadFull(shp, data[:]); err != nil {
+		return nil, err
+	}
+	header, err := decodeHeader(data[:])
+	if err != nil {
+		return nil, err
+	}
+	r := &Reader{
+		r:      shp,
+		header: header,
+		dbf:    o.dbf,
+		bounds: o.bounds,
+		filter: o.filter,
+		offset: headerSize,
+	}
+	if o.dbf != nil {
+		if r.dbfHeader, err = readDBFHeader(o.dbf); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Header returns the header of the .shp file.
+func (r *Reader) Header() Header {
+	return r.header
+}
+
+// Fields returns the fields of the .dbf file, if any.
+func (r *Reader) Fields() []Field {
+	if r.dbfHeader == nil {
+		return nil
+	}
+	return r.dbfHeader.fields
+}
+
+// Read returns the next record. It returns io.EOF after the last record.
+// Errors are sticky.
+func (r *Reader) Read() (*Record, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	record, err := r.read()
+	if err != nil {
+		r.err = err
+		return nil, err
+	}
+	return record, nil
+}
+
+func (r *Reader) read() (*Record, error) {
+	for {
+		if r.offset >= r.header.Length {
+			return nil, io.EOF
+		}
+		var recordHeader [8]byte
+		if _, err := io.ReadFull(r.r, recordHeader[:]); err != nil {
+			return nil, err
+		}
+		number := int(binary.BigEndian.Uint32(recordHeader[0:]))
+		contentLength := 2 * int64(binary.BigEndian.Uint32(recordHeader[4:]))
+		if r.offset+8+contentLength > r.header.Length {
+			return nil, errRecordTooLong
+		}
+		r.offset += 8 + contentLength
+		var prefix []byte
+		if r.bounds != nil {
+			// Read only the shape type and bounding box of the record, and
+			// skip the rest of it if it does not match.
+			prefix = make([]byte, minInt64(contentLength, 36))
+			if _, err := io.ReadFull(r.r, prefix); err != nil {
+				return nil, err
+			}
+			if !overlaps(prefix, r.bounds) {
+				if err := skip(r.r, contentLength-int64(len(prefix))); err != nil {
+					return nil, err
+				}
+				if r.dbfHeader != nil {
+					if err := skip(r.dbf, int64(r.dbfHeader.recordLength)); err != nil {
+						return nil, err
+					}
+				}
+				continue
+			}
+		}
+		var attributes map[string]interface{}
+		if r.dbfHeader != nil {
+			data := make([]byte, r.dbfHeader.recordLength)
+			if _, err := io.ReadFull(r.dbf, data); err != nil {
+				return nil, err
+			}
+			attributes = r.dbfHeader.decodeRecord(data)
+		}
+		if r.filter != nil && !r.filter(strconv.Itoa(number), attributes) {
+			if err := skip(r.r, contentLength-int64(len(prefix))); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		content, err := readContent(r.r, prefix, contentLength)
+		if err != nil {
+			return nil, err
+		}
+		g, err := decodeShape(content)
+		if err != nil {
+			return nil, err
+		}
+		return &Record{
+			Number:     number,
+			Geom:       g,
+			Attributes: attributes,
+		}, nil
+	}
+}
+
+// readContent reads the n bytes of a record's content from r, starting with
+// prefix, which has already been read. Large contents are read incrementally
+// so that a corrupt length does not cause a huge allocation.
+func readContent(r io.Reader, prefix []byte, n int64) ([]byte, error) {
+	if n > maxAllocSize {
+		var buf bytes.Buffer
+		buf.Write(prefix)
+		if _, err := io.CopyN(&buf, r, n-int64(len(prefix))); err != nil {
+			if err == io.EOF {
+				return nil, io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	content := make([]byte, n)
+	m := copy(content, prefix)
+	if _, err := io.ReadFull(r, content[m:]); err != nil {
+		return nil, err
+	}
+	return content, nil
+}
+
+// skip skips n bytes of r, seeking if r implements io.Seeker.
+func skip(r io.Reader, n int64) error {
+	if seeker, ok := r.(io.Seeker); ok {
+		_, err := seeker.Seek(n, io.SeekCurrent)
+		return err
+	}
+	if _, err := io.CopyN(ioutil.Discard, r, n); err != nil {
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	return nil
+}
+
+// An IndexEntry is an entry in a .shx file.
+type IndexEntry struct {
+	Offset int64 // offset of the record in the .shp file, in bytes
+	Length int64 // length of the record's content, in bytes
+}
+
+// ReadIndex reads the entries of the .shx file shx.
+func ReadIndex(shx io.Reader) ([]IndexEntry, error) {
+	var data [headerSize]byte
+	if _, err := io.ReadFull(shx, data[:]); err != nil {
+		return nil, err
+	}
+	header, err := decodeHeader(data[:])
+	if err != nil {
+		return nil, err
+	}
+	n := (header.Length - headerSize) / 8
+	if n < 0 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	index := make([]IndexEntry, 0, minInt64(n, 1<<16))
+	for i := int64(0); i < n; i++ {
+		var entry [8]byte
+		if _, err := io.ReadFull(shx, entry[:]); err != nil {
+			return nil, err
+		}
+		index = append(index, IndexEntry{
+			Offset: 2 * int64(binary.BigEndian.Uint32(entry[0:])),
+			Length: 2 * int64(binary.BigEndian.Uint32(entry[4:])),
+		})
+	}
+	return index, nil
+}
+
+// An IndexedReader reads the records of a .shp file in any order using the
+// entries of its .shx file. When filtering by bounds, only the bounding boxes
+// of records that do not match are read.
+type IndexedReader struct {
+	shp       io.ReaderAt
+	header    Header
+	index     []IndexEntry
+	dbf       io.ReaderAt
+	dbfHeader *dbfHeader
+	bounds    *geom.Bounds
+	filter    feature.Filter
+	next      int
+}
+
+// NewIndexedReader returns a new IndexedReader that reads the .shp file shp
+// using the .shx file shx.
+func NewIndexedReader(shp io.ReaderAt, shx io.Reader, opts ...Option) (*IndexedReader, error) {
+	o := newOptions(opts)
+	var data [headerSize]byte
+	if _, err := shp.ReadAt(data[:], 0); err != nil {
+		return nil, err
+	}
+	header, err := decodeHeader(data[:])
+	if err != nil {
+		return nil, err
+	}
+	index, err := ReadIndex(shx)
+	if err != nil {
+		return nil, err
+	}
+	r := &IndexedReader{
+		shp:    shp,
+		header: header,
+		index:  index,
+		bounds: o.bounds,
+		filter: o.filter,
+	}
+	if o.dbf != nil {
+		dbf, ok := o.dbf.(io.ReaderAt)
+		if !ok {
+			return nil, errDBFNotReaderAt
+		}
+		if r.dbfHeader, err = readDBFHeader(io.NewSectionReader(dbf, 0, math.MaxInt64)); err != nil {
+			return nil, err
+		}
+		r.dbf = dbf
+	}
+	return r, nil
+}
+
+// Header returns the header of the .shp file.
+func (r *IndexedReader) Header() Header {
+	return r.header
+}
+
+// Fields returns the fields of the .dbf file, if any.
+func (r *IndexedReader) Fields() []Field {
+	if r.dbfHeader == nil {
+		return nil
+	}
+	return r.dbfHeader.fields
+}
+
+// NumRecords returns the number of records.
+func (r *IndexedReader) NumRecords() int {
+	return len(r.index)
+}
+
+// Record returns the ith record, regardless of any bounds or filter. It
+// panics if i is out of range.
+func (r *IndexedReader) Record(i int) (*Record, error) {
+	if i < 0 || i >= len(r.index) {
+		panic("shp: index out of range")
+	}
+	attributes, err := r.attributes(i)
+	if err != nil {
+		return nil, err
+	}
+	return r.record(i, attributes)
+}
+
+// Read returns the next record that overlaps the bounds set with WithBounds
+// and is accepted by the filter set with WithFeatureFilter, if any. It
+// returns io.EOF after the last record.
+func (r *IndexedReader) Read() (*Record, error) {
+	for ; r.next < len(r.index); r.next++ {
+		entry := r.index[r.next]
+		if r.bounds != nil {
+			// Read the shape type and bounding box, which is all that is
+			// needed to filter the record.
+			prefix := make([]byte, minInt64(entry.Length, 36))
+			if _, err := r.shp.ReadAt(prefix, entry.Offset+8); err != nil {
+				return nil, err
+			}
+			if !overlaps(prefix, r.bounds) {
+				continue
+			}
+		}
+		attributes, err := r.attributes(r.next)
+		if err != nil {
+			return nil, err
+		}
+		if r.filter != nil {
+			var recordHeader [8]byte
+			if _, err := r.shp.ReadAt(recordHeader[:], entry.Offset); err != nil {
+				return nil, err
+			}
+			if !r.filter(strconv.Itoa(int(binary.BigEndian.Uint32(recordHeader[0:]))), attributes) {
+				continue
+			}
+		}
+		record, err := r.record(r.next, attributes)
+		r.next++
+		return record, err
+	}
+	return nil, io.EOF
+}
+
+// attributes returns the attributes of the ith record, or nil if there is no
+// .dbf file.
+func (r *IndexedReader) attributes(i int) (map[string]interface{}, error) {
+	if r.dbfHeader == nil {
+		return nil, nil
+	}
+	data := make([]byte, r.dbfHeader.recordLength)
+	offset := int64(r.dbfHeader.headerLength) + int64(i)*int64(r.dbfHeader.recordLength)
+	if _, err := r.dbf.ReadAt(data, offset); err != nil {
+		return nil, err
+	}
+	return r.dbfHeader.decodeRecord(data), nil
+}
+
+// record reads the ith record and returns it with attributes.
+func (r *IndexedReader) record(i int, attributes map[string]interface{}) (*Record, error) {
+	entry := r.index[i]
+	if entry.Offset+8+entry.Length > r.header.Length {
+		return nil, errRecordTooLong
+	}
+	data, err := readContent(io.NewSectionReader(r.shp, entry.Offset, 8+entry.Length), nil, 8+entry.Length)
+	if err != nil {
+		return nil, err
+	}
+	g, err := decodeShape(data[8:])
+	if err != nil {
+		return nil, err
+	}
+	return &Record{
+		Number:     int(binary.BigEndian.Uint32(data[0:])),
+		Geom:       g,
+		Attributes: attributes,
+	}, nil
+}
+
+// overlaps returns whether the bounding box of the shape whose content starts
+// with data overlaps bounds in X and Y. data must contain at least the shape
+// type and bounding box. Null shapes do not overlap anything.
+func overlaps(data []byte, bounds *geom.Bounds) bool {
+	d := &shapeDecoder{data: data}
+	var x1, y1, x2, y2 float64
+	switch shapeType := ShapeType(d.uint32()); shapeType {
+	case Null:
+		return false
+	case Point, PointZ, PointM:
+		x1, y1 = d.float64(), d.float64()
+		x2, y2 = x1, y1
+	default:
+		x1, y1, x2, y2 = d.float64(), d.float64(), d.float64(), d.float64()
+	}
+	if d.err != nil {
+		// Let the decoder report the error.
+		return true
+	}
+	return geom.NewBounds(geom.XY).Set(x1, y1, x2, y2).Overlaps(geom.XY, bounds)
+}
+
+func minInt64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,368 @@
+// Package shp implements a reader for ESRI Shapefiles. See
+// https://www.esri.com/content/dam/esrisites/sitecore-archive/Files/Pdfs/library/whitepapers/pdfs/shapefile.pdf.
+//
+// Shapes are decoded as geometries as follows:
+//
+//	Point       *geom.Point
+//	PolyLine    *geom.MultiLineString, with one LineString per part
+//	Polygon     *geom.MultiPolygon, with rings grouped into Polygons
+//	MultiPoint  *geom.MultiPoint
+//	Null        nil
+//
+// Shapes without Z or M values have the XY layout, M shapes have the XYM
+// layout, and Z shapes have the XYZM layout, or the XYZ layout if the record
+// omits the optional M values. M values less than -1e38, which Shapefiles use
+// for "no data", are decoded as NaN. MultiPatch shapes are not supported.
+package shp
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/internal/planar"
+)
+
+// A ShapeType is a Shapefile shape type.
+type ShapeType int32
+
+// ShapeTypes.
+const (
+	Null        ShapeType = 0
+	Point       ShapeType = 1
+	PolyLine    ShapeType = 3
+	Polygon     ShapeType = 5
+	MultiPoint  ShapeType = 8
+	PointZ      ShapeType = 11
+	PolyLineZ   ShapeType = 13
+	PolygonZ    ShapeType = 15
+	MultiPointZ ShapeType = 18
+	PointM      ShapeType = 21
+	PolyLineM   ShapeType = 23
+	PolygonM    ShapeType = 25
+	MultiPointM ShapeType = 28
+)
+
+const (
+	fileCode   = 9994
+	version    = 1000
+	headerSize = 100
+
+	// noData is the threshold below which M values are "no data".
+	noData = -1e38
+)
+
+var (
+	errInvalidFileCode = errors.New("shp: invalid file code")
+	errInvalidVersion  = errors.New("shp: invalid version")
+	errInvalidParts    = errors.New("shp: invalid parts")
+	errRecordTooLong   = errors.New("shp: record extends beyond end of file")
+)
+
+// An ErrUnsupportedShapeType is returned when an unsupported shape type is
+// encountered.
+type ErrUnsupportedShapeType ShapeType
+
+func (e ErrUnsupportedShapeType) Error() string {
+	return fmt.Sprintf("shp: unsupported shape type %d", int32(e))
+}
+
+// A Header is the header of a .shp file.
+type Header struct {
+	ShapeType ShapeType
+	Length    int64        // length of the file in bytes
+	Bounds    *geom.Bounds // XYZM for Z shape types, XYM for M shape types
+}
+
+// A Record is a Shapefile record.
+type Record struct {
+	Number     int    // record number, starting at 1
+	Geom       geom.T // nil for Null shapes
+	Attributes map[string]interface{}
+}
+
+// hasZ returns whether shapes of type t have Z values.
+func (t ShapeType) hasZ() bool {
+	return t == PointZ || t == PolyLineZ || t == PolygonZ || t == MultiPointZ
+}
+
+// hasM returns whether shapes of type t have M values, which are optional for
+// Z shape types.
+func (t ShapeType) hasM() bool {
+	return t == PointM || t == PolyLineM || t == PolygonM || t == MultiPointM
+}
+
+// decodeHeader decodes the 100-byte header of a .shp or .shx file.
+func decodeHeader(data []byte) (Header, error) {
+	if binary.BigEndian.Uint32(data[0:]) != fileCode {
+		return Header{}, errInvalidFileCode
+	}
+	if binary.LittleEndian.Uint32(data[28:]) != version {
+		return Header{}, errInvalidVersion
+	}
+	var f [8]float64
+	for i := range f {
+		f[i] = math.Float64frombits(binary.LittleEndian.Uint64(data[36+8*i:]))
+	}
+	shapeType := ShapeType(binary.LittleEndian.Uint32(data[32:]))
+	var bounds *geom.Bounds
+	switch {
+	case shapeType.hasZ():
+		bounds = geom.NewBounds(geom.XYZM).Set(f[0], f[1], f[4], f[6], f[2], f[3], f[5], f[7])
+	case shapeType.hasM():
+		bounds = geom.NewBounds(geom.XYM).Set(f[0], f[1], f[6], f[2], f[3], f[7])
+	default:
+		bounds = geom.NewBounds(geom.XY).Set(f[0], f[1], f[2], f[3])
+	}
+	return Header{
+		ShapeType: shapeType,
+		Length:    2 * int64(binary.BigEndian.Uint32(data[24:])),
+		Bounds:    bounds,
+	}, nil
+}
+
+// A shapeDecoder decodes little endian values from the content of a record.
+// Errors are sticky.
+type shapeDecoder struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (d *shapeDecoder) remaining() int {
+	return len(d.data) - d.pos
+}
+
+func (d *shapeDecoder) skip(n int) {
+	if d.err == nil && d.remaining() < n {
+		d.err = io.ErrUnexpectedEOF
+	}
+	if d.err == nil {
+		d.pos += n
+	}
+}
+
+func (d *shapeDecoder) uint32() uint32 {
+	if d.skip(4); d.err != nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(d.data[d.pos-4:])
+}
+
+func (d *shapeDecoder) float64() float64 {
+	if d.skip(8); d.err != nil {
+		return 0
+	}
+	return math.Float64frombits(binary.LittleEndian.Uint64(d.data[d.pos-8:]))
+}
+
+// count reads a count of items of size bytes, checking that they fit in the
+// remaining data. It returns zero on error, so the count can never be used to
+// allocate more memory than the size of the record's content.
+func (d *shapeDecoder) count(size int) int {
+	n := int(d.uint32())
+	if d.err == nil && (n < 0 || n > d.remaining()/size) {
+		d.err = io.ErrUnexpectedEOF
+	}
+	if d.err != nil {
+		return 0
+	}
+	return n
+}
+
+// decodeShape decodes the content of a record, which starts with its shape
+// type.
+func decodeShape(content []byte) (geom.T, error) {
+	d := &shapeDecoder{data: content}
+	shapeType := ShapeType(d.uint32())
+	if d.err != nil {
+		return nil, d.err
+	}
+	switch shapeType {
+	case Null:
+		return nil, nil
+	case Point, PointZ, PointM:
+		layout := geom.XY
+		switch {
+		case shapeType == PointM:
+			layout = geom.XYM
+		case shapeType == PointZ && d.remaining() >= 32:
+			layout = geom.XYZM
+		case shapeType == PointZ:
+			layout = geom.XYZ
+		}
+		flatCoords := make([]float64, layout.Stride())
+		for i := range flatCoords {
+			flatCoords[i] = d.float64()
+		}
+		if mIndex := layout.MIndex(); mIndex != -1 && flatCoords[mIndex] < noData {
+			flatCoords[mIndex] = math.NaN()
+		}
+		if d.err != nil {
+			return nil, d.err
+		}
+		return geom.NewPointFlat(layout, flatCoords), nil
+	case PolyLine, PolyLineZ, PolyLineM, Polygon, PolygonZ, PolygonM:
+		d.skip(32) // bounding box
+		numParts := d.count(4)
+		numPoints := d.count(16)
+		if d.err != nil {
+			return nil, d.err
+		}
+		parts := make([]int, numParts)
+		for i := range parts {
+			parts[i] = int(d.uint32())
+		}
+		layout, flatCoords := d.points(shapeType, numPoints)
+		if d.err != nil {
+			return nil, d.err
+		}
+		stride := layout.Stride()
+		ends := make([]int, numParts)
+		for i := range parts {
+			end := numPoints
+			if i+1 < numParts {
+				end = parts[i+1]
+			}
+			if parts[i] < 0 || parts[i] > end || i == 0 && parts[i] != 0 {
+				return nil, errInvalidParts
+			}
+			ends[i] = stride * end
+		}
+		if numParts == 0 && numPoints != 0 {
+			return nil, errInvalidParts
+		}
+		switch shapeType {
+		case PolyLine, PolyLineZ, PolyLineM:
+			return geom.NewMultiLineStringFlat(layout, flatCoords, ends), nil
+		default:
+			return newMultiPolygon(layout, flatCoords, ends), nil
+		}
+	case MultiPoint, MultiPointZ, MultiPointM:
+		d.skip(32) // bounding box
+		numPoints := d.count(16)
+		layout, flatCoords := d.points(shapeType, numPoints)
+		if d.err != nil {
+			return nil, d.err
+		}
+		return geom.NewMultiPointFlat(layout, flatCoords), nil
+	default:
+		return nil, ErrUnsupportedShapeType(shapeType)
+	}
+}
+
+// points decodes numPoints points of a shape of type shapeType, followed by
+// their Z and M values, if any.
+func (d *shapeDecoder) points(shapeType ShapeType, numPoints int) (geom.Layout, []float64) {
+	xys := make([]float64, 2*numPoints)
+	for i := range xys {
+		xys[i] = d.float64()
+	}
+	var zs, ms []float64
+	if shapeType.hasZ() {
+		d.skip(16) // Z range
+		zs = d.values(numPoints)
+	}
+	if shapeType.hasM() || shapeType.hasZ() && d.remaining() >= 16+8*numPoints {
+		d.skip(16) // M range
+		ms = d.values(numPoints)
+		for i, m := range ms {
+			if m < noData {
+				ms[i] = math.NaN()
+			}
+		}
+	}
+	layout := geom.XY
+	switch {
+	case zs != nil && ms != nil:
+		layout = geom.XYZM
+	case zs != nil:
+		layout = geom.XYZ
+	case ms != nil:
+		layout = geom.XYM
+	}
+	stride := layout.Stride()
+	flatCoords := make([]float64, 0, stride*numPoints)
+	for i := 0; i < numPoints; i++ {
+		flatCoords = append(flatCoords, xys[2*i], xys[2*i+1])
+		if zs != nil {
+			flatCoords = append(flatCoords, zs[i])
+		}
+		if ms != nil {
+			flatCoords = append(flatCoords, ms[i])
+		}
+	}
+	return layout, flatCoords
+}
+
+// values decodes n float64s.
+func (d *shapeDecoder) values(n int) []float64 {
+	if d.err == nil && d.remaining() < 8*n {
+		d.err = io.ErrUnexpectedEOF
+	}
+	if d.err != nil {
+		return nil
+	}
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = d.float64()
+	}
+	return values
+}
+
+// newMultiPolygon returns a MultiPolygon from the rings of a Polygon shape.
+// Shapefile exterior rings are clockwise and holes are counterclockwise.
+// Each exterior ring starts a new Polygon, and each hole is added to the
+// first Polygon whose exterior ring contains its first vertex, or to the
+// preceding Polygon if there is none. The orientation of rings is preserved.
+func newMultiPolygon(layout geom.Layout, flatCoords []float64, ends []int) *geom.MultiPolygon {
+	stride := layout.Stride()
+	var polygons [][][]float64 // rings of each polygon
+	offset := 0
+	for _, end := range ends {
+		ring := flatCoords[offset:end]
+		offset = end
+		if planar.SignedArea(ring, stride) <= 0 || len(polygons) == 0 {
+			polygons = append(polygons, [][]float64{ring})
+			continue
+		}
+		i := len(polygons) - 1
+		if len(ring) > 0 {
+			for j, polygon := range polygons {
+				if containsPoint(polygon[0], stride, ring[0], ring[1]) {
+					i = j
+					break
+				}
+			}
+		}
+		polygons[i] = append(polygons[i], ring)
+	}
+	mpFlatCoords := make([]float64, 0, len(flatCoords))
+	endss := make([][]int, 0, len(polygons))
+	for _, polygon := range polygons {
+		polygonEnds := make([]int, 0, len(polygon))
+		for _, ring := range polygon {
+			mpFlatCoords = append(mpFlatCoords, ring...)
+			polygonEnds = append(polygonEnds, len(mpFlatCoords))
+		}
+		endss = append(endss, polygonEnds)
+	}
+	return geom.NewMultiPolygonFlat(layout, mpFlatCoords, endss)
+}
+
+// containsPoint returns whether (x, y) is inside ring, using the even-odd
+// rule.
+func containsPoint(ring []float64, stride int, x, y float64) bool {
+	inside := false
+	for i := stride; i < len(ring); i += stride {
+		ax, ay := ring[i-stride], ring[i-stride+1]
+		bx, by := ring[i], ring[i+1]
+		if (ay > y) != (by > y) && x < ax+(y-ay)*(bx-ax)/(by-ay) {
+			inside = !inside
+		}
+	}
+	return inside
+}
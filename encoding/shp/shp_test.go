@@ -0,0 +1,553 @@
+package shp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/twpayne/go-geom"
+)
+
+// le returns the little endian encoding of values, which must be int32s,
+// ShapeTypes, or float64s.
+func le(values ...interface{}) []byte {
+	b := &bytes.Buffer{}
+	for _, value := range values {
+		if err := binary.Write(b, binary.LittleEndian, value); err != nil {
+			panic(err)
+		}
+	}
+	return b.Bytes()
+}
+
+// newTestFiles returns the .shp and .shx files with the given shape type and
+// record contents.
+func newTestFiles(shapeType ShapeType, contents ...[]byte) ([]byte, []byte) {
+	header := func(length int) []byte {
+		data := make([]byte, headerSize)
+		binary.BigEndian.PutUint32(data[0:], fileCode)
+		binary.BigEndian.PutUint32(data[24:], uint32(length/2))
+		binary.LittleEndian.PutUint32(data[28:], version)
+		binary.LittleEndian.PutUint32(data[32:], uint32(shapeType))
+		return data
+	}
+	var shpRecords, shxRecords []byte
+	for i, content := range contents {
+		var recordHeader [8]byte
+		binary.BigEndian.PutUint32(recordHeader[0:], uint32(i+1))
+		binary.BigEndian.PutUint32(recordHeader[4:], uint32(len(content)/2))
+		var entry [8]byte
+		binary.BigEndian.PutUint32(entry[0:], uint32((headerSize+len(shpRecords))/2))
+		binary.BigEndian.PutUint32(entry[4:], uint32(len(content)/2))
+		shpRecords = append(append(shpRecords, recordHeader[:]...), content...)
+		shxRecords = append(shxRecords, entry[:]...)
+	}
+	shp := append(header(headerSize+len(shpRecords)), shpRecords...)
+	shx := append(header(headerSize+len(shxRecords)), shxRecords...)
+	return shp, shx
+}
+
+// newTestDBF returns a .dbf file with a character field NAME and a numeric
+// field VALUE with the given values.
+func newTestDBF(names []string, values []string) []byte {
+	b := &bytes.Buffer{}
+	prefix := make([]byte, 32)
+	prefix[0] = 3
+	binary.LittleEndian.PutUint32(prefix[4:], uint32(len(names)))
+	binary.LittleEndian.PutUint16(prefix[8:], 32+2*32+1)
+	binary.LittleEndian.PutUint16(prefix[10:], 1+10+8)
+	b.Write(prefix)
+	for _, field := range []Field{
+		{Name: "NAME", Type: 'C', Length: 10},
+		{Name: "VALUE", Type: 'N', Length: 8, Decimals: 2},
+	} {
+		descriptor := make([]byte, 32)
+		copy(descriptor, field.Name)
+		descriptor[11] = field.Type
+		descriptor[16] = byte(field.Length)
+		descriptor[17] = byte(field.Decimals)
+		b.Write(descriptor)
+	}
+	b.WriteByte(0x0d)
+	for i := range names {
+		fmt.Fprintf(b, " %-10s%8s", names[i], values[i])
+	}
+	b.WriteByte(0x1a)
+	return b.Bytes()
+}
+
+var (
+	bbox       = le(0.0, 0.0, 0.0, 0.0)
+	squareXYs  = le(0.0, 0.0, 0.0, 10.0, 10.0, 10.0, 10.0, 0.0, 0.0, 0.0)
+	holeXYs    = le(1.0, 1.0, 2.0, 1.0, 2.0, 2.0, 1.0, 2.0, 1.0, 1.0)
+	squareXYs2 = le(20.0, 0.0, 20.0, 1.0, 21.0, 1.0, 21.0, 0.0, 20.0, 0.0)
+)
+
+func concat(bs ...[]byte) []byte {
+	return bytes.Join(bs, nil)
+}
+
+func TestDecodeShape(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		content []byte
+		want    geom.T
+	}{
+		{
+			name:    "null",
+			content: le(Null),
+		},
+		{
+			name:    "point",
+			content: le(Point, 1.0, 2.0),
+			want:    geom.NewPointFlat(geom.XY, []float64{1, 2}),
+		},
+		{
+			name:    "point_m_no_data",
+			content: le(PointM, 1.0, 2.0, -1e39),
+			want:    geom.NewPointFlat(geom.XYM, []float64{1, 2, math.NaN()}),
+		},
+		{
+			name:    "point_z",
+			content: le(PointZ, 1.0, 2.0, 3.0, 4.0),
+			want:    geom.NewPointFlat(geom.XYZM, []float64{1, 2, 3, 4}),
+		},
+		{
+			name:    "point_z_without_m",
+			content: le(PointZ, 1.0, 2.0, 3.0),
+			want:    geom.NewPointFlat(geom.XYZ, []float64{1, 2, 3}),
+		},
+		{
+			name:    "poly_line",
+			content: concat(le(PolyLine), bbox, le(int32(2), int32(4), int32(0), int32(2), 1.0, 2.0, 3.0, 4.0, 5.0, 6.0, 7.0, 8.0)),
+			want:    geom.NewMultiLineStringFlat(geom.XY, []float64{1, 2, 3, 4, 5, 6, 7, 8}, []int{4, 8}),
+		},
+		{
+			name: "poly_line_m",
+			content: concat(
+				le(PolyLineM), bbox, le(int32(1), int32(2), int32(0), 1.0, 2.0, 3.0, 4.0),
+				le(0.0, 0.0, 5.0, 6.0),
+			),
+			want: geom.NewMultiLineStringFlat(geom.XYM, []float64{1, 2, 5, 3, 4, 6}, []int{6}),
+		},
+		{
+			name: "polygon_with_hole",
+			content: concat(
+				le(Polygon), bbox, le(int32(3), int32(15), int32(0), int32(5), int32(10)),
+				squareXYs, squareXYs2, holeXYs,
+			),
+			want: geom.NewMultiPolygonFlat(geom.XY, concatFloats(
+				[]float64{0, 0, 0, 10, 10, 10, 10, 0, 0, 0},
+				[]float64{1, 1, 2, 1, 2, 2, 1, 2, 1, 1},
+				[]float64{20, 0, 20, 1, 21, 1, 21, 0, 20, 0},
+			), [][]int{{10, 20}, {30}}),
+		},
+		{
+			name: "multi_point_z",
+			content: concat(
+				le(MultiPointZ), bbox, le(int32(2), 1.0, 2.0, 3.0, 4.0),
+				le(0.0, 0.0, 5.0, 6.0),
+				le(0.0, 0.0, 7.0, 8.0),
+			),
+			want: geom.NewMultiPointFlat(geom.XYZM, []float64{1, 2, 5, 7, 3, 4, 6, 8}),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := decodeShape(tc.content)
+			if err != nil {
+				t.Fatalf("decodeShape(...) == %v, %v, want ..., nil", got, err)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tc.want) {
+				t.Errorf("decodeShape(...) == %v, nil, want %v, nil", got, tc.want)
+			}
+		})
+	}
+}
+
+func concatFloats(fss ...[]float64) []float64 {
+	var result []float64
+	for _, fs := range fss {
+		result = append(result, fs...)
+	}
+	return result
+}
+
+func TestDecodeShapeErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		content []byte
+		want    error
+	}{
+		{
+			name:    "multi_patch",
+			content: le(int32(31)),
+			want:    ErrUnsupportedShapeType(31),
+		},
+		{
+			name:    "truncated_point",
+			content: le(Point, 1.0),
+			want:    io.ErrUnexpectedEOF,
+		},
+		{
+			name:    "too_many_points",
+			content: concat(le(MultiPoint), bbox, le(int32(1000), 1.0, 2.0)),
+			want:    io.ErrUnexpectedEOF,
+		},
+		{
+			name:    "invalid_parts",
+			content: concat(le(PolyLine), bbox, le(int32(2), int32(2), int32(0), int32(3), 1.0, 2.0, 3.0, 4.0)),
+			want:    errInvalidParts,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got, err := decodeShape(tc.content); err != tc.want {
+				t.Errorf("decodeShape(...) == %v, %v, want nil, %v", got, err, tc.want)
+			}
+		})
+	}
+}
+
+func TestReader(t *testing.T) {
+	shp, _ := newTestFiles(Point,
+		le(Point, 1.0, 2.0),
+		le(Null),
+		le(Point, 30.0, 40.0),
+	)
+	dbf := newTestDBF([]string{"a", "b", "c"}, []string{"1.50", "", "-2"})
+	r, err := NewReader(bytes.NewReader(shp), WithDBF(bytes.NewReader(dbf)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := r.Header().ShapeType, Point; got != want {
+		t.Errorf("r.Header().ShapeType == %v, want %v", got, want)
+	}
+	if got, want := len(r.Fields()), 2; got != want {
+		t.Errorf("len(r.Fields()) == %d, want %d", got, want)
+	}
+	want := []*Record{
+		{
+			Number:     1,
+			Geom:       geom.NewPointFlat(geom.XY, []float64{1, 2}),
+			Attributes: map[string]interface{}{"NAME": "a", "VALUE": 1.5},
+		},
+		{
+			Number:     2,
+			Attributes: map[string]interface{}{"NAME": "b", "VALUE": nil},
+		},
+		{
+			Number:     3,
+			Geom:       geom.NewPointFlat(geom.XY, []float64{30, 40}),
+			Attributes: map[string]interface{}{"NAME": "c", "VALUE": -2.0},
+		},
+	}
+	for i := range want {
+		got, err := r.Read()
+		if err != nil {
+			t.Fatalf("r.Read() == %v, %v, want ..., nil", got, err)
+		}
+		if !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("r.Read() == %+v, nil, want %+v, nil", got, want[i])
+		}
+	}
+	if got, err := r.Read(); err != io.EOF {
+		t.Errorf("r.Read() == %v, %v, want nil, io.EOF", got, err)
+	}
+
+	r, err = NewReader(bytes.NewReader(shp), WithBounds(geom.NewBounds(geom.XY).Set(0, 0, 10, 10)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := r.Read(); err != nil || got.Number != 1 {
+		t.Errorf("r.Read() == %v, %v, want record 1, nil", got, err)
+	}
+	if got, err := r.Read(); err != io.EOF {
+		t.Errorf("r.Read() == %v, %v, want nil, io.EOF", got, err)
+	}
+}
+
+func TestIndexedReader(t *testing.T) {
+	shp, shx := newTestFiles(PolyLine,
+		concat(le(PolyLine), le(0.0, 0.0, 1.0, 1.0), le(int32(1), int32(2), int32(0), 0.0, 0.0, 1.0, 1.0)),
+		concat(le(PolyLine), le(5.0, 5.0, 6.0, 6.0), le(int32(1), int32(2), int32(0), 5.0, 5.0, 6.0, 6.0)),
+		concat(le(PolyLine), le(0.0, 5.0, 6.0, 6.0), le(int32(1), int32(2), int32(0), 0.0, 5.0, 6.0, 6.0)),
+	)
+	dbf := newTestDBF([]string{"a", "b", "c"}, []string{"1", "2", "3"})
+	// countingReaderAt counts the bytes read, to check that filtered records
+	// are not read.
+	shpReaderAt := &countingReaderAt{r: bytes.NewReader(shp)}
+	r, err := NewIndexedReader(shpReaderAt, bytes.NewReader(shx),
+		WithBounds(geom.NewBounds(geom.XY).Set(4, 4, 10, 10)),
+		WithDBF(bytes.NewReader(dbf)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := r.NumRecords(), 3; got != want {
+		t.Errorf("r.NumRecords() == %d, want %d", got, want)
+	}
+	shpReaderAt.n = 0
+	var gotNames []interface{}
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		gotNames = append(gotNames, record.Attributes["NAME"])
+	}
+	if want := []interface{}{"b", "c"}; !reflect.DeepEqual(gotNames, want) {
+		t.Errorf("names == %v, want %v", gotNames, want)
+	}
+	if got, want := shpReaderAt.n, 3*36+2*(8+80); got != want {
+		t.Errorf("read %d bytes, want %d", got, want)
+	}
+
+	record, err := r.Record(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := geom.NewMultiLineStringFlat(geom.XY, []float64{0, 0, 1, 1}, []int{4}); !reflect.DeepEqual(record.Geom, want) {
+		t.Errorf("r.Record(0).Geom == %v, want %v", record.Geom, want)
+	}
+}
+
+func TestReaderBoundsSkip(t *testing.T) {
+	shp, _ := newTestFiles(PolyLine,
+		concat(le(PolyLine), le(0.0, 0.0, 1.0, 1.0), le(int32(1), int32(2), int32(0), 0.0, 0.0, 1.0, 1.0)),
+		concat(le(PolyLine), le(5.0, 5.0, 6.0, 6.0), le(int32(1), int32(2), int32(0), 5.0, 5.0, 6.0, 6.0)),
+		concat(le(PolyLine), le(0.0, 5.0, 6.0, 6.0), le(int32(1), int32(2), int32(0), 0.0, 5.0, 6.0, 6.0)),
+	)
+	dbf := newTestDBF([]string{"a", "b", "c"}, []string{"1", "2", "3"})
+	for _, tc := range []struct {
+		name      string
+		shp       io.Reader
+		wantBytes int
+	}{
+		{
+			name: "reader",
+			// Hide the io.Seeker implementation of the bytes.Reader.
+			shp: struct{ io.Reader }{bytes.NewReader(shp)},
+		},
+		{
+			// Only the header, the record headers, the shape type and
+			// bounding box of the first record, and the second and third
+			// records are read.
+			name:      "read_seeker",
+			shp:       &countingReadSeeker{r: bytes.NewReader(shp)},
+			wantBytes: headerSize + 3*8 + 36 + 2*80,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := NewReader(tc.shp,
+				WithBounds(geom.NewBounds(geom.XY).Set(4, 4, 10, 10)),
+				WithDBF(struct{ io.Reader }{bytes.NewReader(dbf)}),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var gotNames []interface{}
+			for {
+				record, err := r.Read()
+				if err == io.EOF {
+					break
+				} else if err != nil {
+					t.Fatal(err)
+				}
+				gotNames = append(gotNames, record.Attributes["NAME"])
+			}
+			if want := []interface{}{"b", "c"}; !reflect.DeepEqual(gotNames, want) {
+				t.Errorf("names == %v, want %v", gotNames, want)
+			}
+			if crs, ok := tc.shp.(*countingReadSeeker); ok && crs.n != tc.wantBytes {
+				t.Errorf("read %d bytes, want %d", crs.n, tc.wantBytes)
+			}
+		})
+	}
+}
+
+func TestFeatureFilter(t *testing.T) {
+	shp, shx := newTestFiles(PolyLine,
+		concat(le(PolyLine), le(0.0, 0.0, 1.0, 1.0), le(int32(1), int32(2), int32(0), 0.0, 0.0, 1.0, 1.0)),
+		concat(le(PolyLine), le(5.0, 5.0, 6.0, 6.0), le(int32(1), int32(2), int32(0), 5.0, 5.0, 6.0, 6.0)),
+		concat(le(PolyLine), le(0.0, 5.0, 6.0, 6.0), le(int32(1), int32(2), int32(0), 0.0, 5.0, 6.0, 6.0)),
+	)
+	dbf := newTestDBF([]string{"a", "b", "c"}, []string{"1", "2", "3"})
+	// Reject the second record by number and the third by attribute.
+	filter := func(id string, attributes map[string]interface{}) bool {
+		return id != "2" && attributes["NAME"] != "c"
+	}
+	r, err := NewReader(bytes.NewReader(shp), WithDBF(bytes.NewReader(dbf)), WithFeatureFilter(filter))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []*Record
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, record)
+	}
+	ir, err := NewIndexedReader(bytes.NewReader(shp), bytes.NewReader(shx), WithDBF(bytes.NewReader(dbf)), WithFeatureFilter(filter))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gotIndexed []*Record
+	for {
+		record, err := ir.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		gotIndexed = append(gotIndexed, record)
+	}
+	want := []*Record{
+		{
+			Number:     1,
+			Geom:       geom.NewMultiLineStringFlat(geom.XY, []float64{0, 0, 1, 1}, []int{4}),
+			Attributes: map[string]interface{}{"NAME": "a", "VALUE": 1.0},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Reader read %+v, want %+v", got, want)
+	}
+	if !reflect.DeepEqual(gotIndexed, want) {
+		t.Errorf("IndexedReader read %+v, want %+v", gotIndexed, want)
+	}
+}
+
+type countingReadSeeker struct {
+	r io.ReadSeeker
+	n int
+}
+
+func (r *countingReadSeeker) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += n
+	return n, err
+}
+
+func (r *countingReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	return r.r.Seek(offset, whence)
+}
+
+type countingReaderAt struct {
+	r io.ReaderAt
+	n int
+}
+
+func (r *countingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	n, err := r.r.ReadAt(p, off)
+	r.n += n
+	return n, err
+}
+
+func TestNewReaderErrors(t *testing.T) {
+	shp, _ := newTestFiles(Point)
+	shp[3] = 0
+	if _, err := NewReader(bytes.NewReader(shp)); err != errInvalidFileCode {
+		t.Errorf("NewReader(...) == ..., %v, want ..., %v", err, errInvalidFileCode)
+	}
+	shp, shx := newTestFiles(Point)
+	if _, err := NewIndexedReader(bytes.NewReader(shp), bytes.NewReader(shx), WithDBF(bytes.NewBufferString(""))); err != errDBFNotReaderAt {
+		t.Errorf("NewIndexedReader(...) == ..., %v, want ..., %v", err, errDBFNotReaderAt)
+	}
+}
+
+func TestOversizedRecords(t *testing.T) {
+	shp, shx := newTestFiles(Point, concat(le(Point), le(1.0, 2.0)))
+	// Claim that the file and its only record are nearly 8GB long.
+	binary.BigEndian.PutUint32(shp[24:], math.MaxUint32)
+	binary.BigEndian.PutUint32(shp[headerSize+4:], math.MaxUint32-64)
+	binary.BigEndian.PutUint32(shx[headerSize+4:], math.MaxUint32-64)
+
+	allocated := func(f func()) uint64 {
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+		f()
+		runtime.ReadMemStats(&after)
+		return after.TotalAlloc - before.TotalAlloc
+	}
+	const maxAllocated = 16 * maxAllocSize
+
+	if n := allocated(func() {
+		r, err := NewReader(bytes.NewReader(shp))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := r.Read(); err != io.ErrUnexpectedEOF {
+			t.Errorf("r.Read() == _, %v, want _, %v", err, io.ErrUnexpectedEOF)
+		}
+	}); n > maxAllocated {
+		t.Errorf("Reader allocated %d bytes, want at most %d", n, maxAllocated)
+	}
+
+	if n := allocated(func() {
+		r, err := NewIndexedReader(bytes.NewReader(shp), bytes.NewReader(shx))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := r.Record(0); err != io.ErrUnexpectedEOF {
+			t.Errorf("r.Record(0) == _, %v, want _, %v", err, io.ErrUnexpectedEOF)
+		}
+	}); n > maxAllocated {
+		t.Errorf("IndexedReader allocated %d bytes, want at most %d", n, maxAllocated)
+	}
+
+	binary.BigEndian.PutUint32(shp[24:], uint32(len(shp)/2))
+	r, err := NewIndexedReader(bytes.NewReader(shp), bytes.NewReader(shx))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Record(0); err != errRecordTooLong {
+		t.Errorf("r.Record(0) == _, %v, want _, %v", err, errRecordTooLong)
+	}
+}
+
+func TestHugeNumParts(t *testing.T) {
+	// A PolyLine claiming 2^31-1 parts in a 152 byte file.
+	shp, _ := newTestFiles(PolyLine, concat(le(PolyLine), bbox, le(int32(math.MaxInt32), int32(0))))
+	if got, want := len(shp), 152; got != want {
+		t.Fatalf("len(shp) == %d, want %d", got, want)
+	}
+	r, err := NewReader(bytes.NewReader(shp))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := r.Read(); err != io.ErrUnexpectedEOF {
+		t.Errorf("r.Read() == %v, %v, want nil, %v", got, err, io.ErrUnexpectedEOF)
+	}
+}
+
+func TestDecodeValue(t *testing.T) {
+	for _, tc := range []struct {
+		field Field
+		s     string
+		want  interface{}
+	}{
+		{field: Field{Type: 'C'}, s: "abc   ", want: "abc"},
+		{field: Field{Type: 'N'}, s: "  42", want: int64(42)},
+		{field: Field{Type: 'N', Decimals: 2}, s: " 4.20", want: 4.2},
+		{field: Field{Type: 'N'}, s: "    ", want: nil},
+		{field: Field{Type: 'F'}, s: "1e3", want: 1000.0},
+		{field: Field{Type: 'L'}, s: "Y", want: true},
+		{field: Field{Type: 'L'}, s: "?", want: nil},
+		{field: Field{Type: 'D'}, s: "20200102", want: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{field: Field{Type: 'D'}, s: "        ", want: nil},
+		{field: Field{Type: 'M'}, s: "0000000001", want: "0000000001"},
+	} {
+		if got := decodeValue(tc.field, tc.s); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("decodeValue(%v, %q) == %v, want %v", tc.field, tc.s, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+// Package smallest chooses the most compact encoding of a geometry.
+//
+// Storage-optimizing services can use it to choose an encoding per feature:
+// each registered codec encodes the geometry at the given precision and the
+// smallest result is returned together with the name of its codec.
+package smallest
+
+import (
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/geobuf"
+	"github.com/twpayne/go-geom/encoding/polyline"
+	"github.com/twpayne/go-geom/encoding/twkb"
+	"github.com/twpayne/go-geom/encoding/wkb"
+)
+
+// A Codec is a named encoding.
+type Codec struct {
+	// Name identifies the codec, for example so that the decoder can be
+	// chosen when the data are read.
+	Name string
+	// Encode encodes g. precision is the number of decimal places to which
+	// ordinates must be preserved. Lossless codecs may ignore it.
+	Encode func(g geom.T, precision int) ([]byte, error)
+}
+
+// Codecs are the codecs considered, in order of preference when encodings
+// have the same size.
+var Codecs = []Codec{
+	{
+		Name: "wkb",
+		Encode: func(g geom.T, precision int) ([]byte, error) {
+			return wkb.Append(nil, g)
+		},
+	},
+	{
+		Name: "twkb",
+		Encode: func(g geom.T, precision int) ([]byte, error) {
+			zmPrecision := precision
+			if zmPrecision < 0 {
+				zmPrecision = 0
+			} else if zmPrecision > 7 {
+				zmPrecision = 7
+			}
+			return twkb.Marshal(g,
+				twkb.WithPrecision(precision),
+				twkb.WithZPrecision(zmPrecision),
+				twkb.WithMPrecision(zmPrecision),
+			)
+		},
+	},
+	{
+		Name: "geobuf",
+		Encode: func(g geom.T, precision int) ([]byte, error) {
+			return geobuf.Marshal(g, geobuf.WithPrecision(precision))
+		},
+	},
+	{
+		Name: "polyline",
+		Encode: func(g geom.T, precision int) ([]byte, error) {
+			// Encoded polylines do not record the geometry type, so only
+			// LineStrings are encoded.
+			if _, ok := g.(*geom.LineString); !ok {
+				return nil, geom.ErrUnsupportedType{Value: g}
+			}
+			return polyline.Marshal(g, polyline.WithPrecision(precision))
+		},
+	},
+}
+
+// An Encoding is the result of encoding a geometry with a codec.
+type Encoding struct {
+	Codec string
+	Data  []byte
+	Err   error
+}
+
+// Size returns the size of e in bytes, or -1 if the encoding failed.
+func (e Encoding) Size() int {
+	if e.Err != nil {
+		return -1
+	}
+	return len(e.Data)
+}
+
+// Report encodes g with every codec in Codecs at precision and returns the
+// results, including those of codecs that cannot encode g.
+func Report(g geom.T, precision int) []Encoding {
+	encodings := make([]Encoding, 0, len(Codecs))
+	for _, codec := range Codecs {
+		data, err := codec.Encode(g, precision)
+		encodings = append(encodings, Encoding{
+			Codec: codec.Name,
+			Data:  data,
+			Err:   err,
+		})
+	}
+	return encodings
+}
+
+// Encode returns the smallest encoding of g at precision. If no codec can
+// encode g then it returns the error of the first codec.
+func Encode(g geom.T, precision int) (Encoding, error) {
+	var smallest Encoding
+	var firstErr error
+	found := false
+	for _, e := range Report(g, precision) {
+		switch {
+		case e.Err != nil:
+			if firstErr == nil {
+				firstErr = e.Err
+			}
+		case !found || len(e.Data) < len(smallest.Data):
+			smallest = e
+			found = true
+		}
+	}
+	if !found {
+		if firstErr == nil {
+			firstErr = geom.ErrUnsupportedType{Value: g}
+		}
+		return Encoding{}, firstErr
+	}
+	return smallest, nil
+}
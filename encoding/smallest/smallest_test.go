@@ -0,0 +1,84 @@
+package smallest
+
+import (
+	"testing"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/geobuf"
+	"github.com/twpayne/go-geom/encoding/polyline"
+	"github.com/twpayne/go-geom/encoding/twkb"
+	"github.com/twpayne/go-geom/encoding/wkb"
+)
+
+func TestEncode(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		g         geom.T
+		precision int
+		codec     string
+	}{
+		{
+			name:  "point",
+			g:     geom.NewPointFlat(geom.XY, []float64{1, 2}),
+			codec: "twkb",
+		},
+		{
+			name:      "line_string",
+			g:         geom.NewLineStringFlat(geom.XYZ, []float64{1.23456, 2.34567, 3, 4.56789, 5.67891, 6}),
+			precision: 5,
+			codec:     "twkb",
+		},
+		{
+			name:  "line_string_xy",
+			g:     geom.NewLineStringFlat(geom.XY, []float64{0, 0, 1, 1, 2, 1}),
+			codec: "polyline",
+		},
+		{
+			name:      "high_precision",
+			g:         geom.NewPointFlat(geom.XY, []float64{1, 2}),
+			precision: 12,
+			codec:     "geobuf",
+		},
+		{
+			name:      "invalid_precision",
+			g:         geom.NewPointFlat(geom.XY, []float64{1, 2}),
+			precision: 16,
+			codec:     "wkb",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			e, err := Encode(tc.g, tc.precision)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if e.Codec != tc.codec {
+				t.Errorf("e.Codec == %q, want %q", e.Codec, tc.codec)
+			}
+			var got geom.T
+			switch e.Codec {
+			case "wkb":
+				got, err = wkb.Unmarshal(e.Data)
+			case "twkb":
+				got, err = twkb.Unmarshal(e.Data)
+			case "geobuf":
+				got, err = geobuf.Unmarshal(e.Data)
+			case "polyline":
+				got, err = polyline.Unmarshal(e.Data, polyline.WithPrecision(tc.precision))
+			}
+			if err != nil || got.Layout() != tc.g.Layout() {
+				t.Errorf("decoding %s: %v, %v", e.Codec, got, err)
+			}
+			for _, r := range Report(tc.g, tc.precision) {
+				if r.Err == nil && r.Size() < e.Size() {
+					t.Errorf("%s encoding is smaller than %s: %d < %d", r.Codec, e.Codec, r.Size(), e.Size())
+				}
+			}
+		})
+	}
+}
+
+func TestEncodeUnsupported(t *testing.T) {
+	if _, err := Encode(geom.NewPointFlat(geom.NoLayout, nil), 0); err == nil {
+		t.Errorf("Encode(...) == ..., <nil>, want !<nil>")
+	}
+}
@@ -0,0 +1,243 @@
+package spatialite
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/twpayne/go-geom"
+)
+
+// A decoder decodes the class type and body of a geometry. Errors are
+// sticky.
+type decoder struct {
+	data      []byte
+	byteOrder binary.ByteOrder
+	err       error
+}
+
+// Unmarshal decodes the SpatiaLite BLOB geometry in data. The SRID of the
+// geometry is set from the header and points with NaN ordinates are decoded
+// as empty points.
+func Unmarshal(data []byte) (geom.T, error) {
+	if len(data) < headerSize+5 {
+		return nil, errTruncated
+	}
+	if data[0] != markerStart || data[headerSize-1] != markerMBREnd || data[len(data)-1] != markerEnd {
+		return nil, errInvalidData
+	}
+	d := &decoder{
+		data: data[headerSize : len(data)-1],
+	}
+	switch data[1] {
+	case bigEndian:
+		d.byteOrder = binary.BigEndian
+	case littleEndian:
+		d.byteOrder = binary.LittleEndian
+	default:
+		return nil, errInvalidData
+	}
+	srid := int(int32(d.byteOrder.Uint32(data[2:6])))
+	g, err := d.decode()
+	switch {
+	case err != nil:
+		return nil, err
+	case d.err != nil:
+		return nil, d.err
+	case len(d.data) != 0:
+		return nil, errInvalidData
+	}
+	setSRID(g, srid)
+	return g, nil
+}
+
+// decode decodes a class type and body.
+func (d *decoder) decode() (geom.T, error) {
+	baseType, layout, compressed, err := parseClassType(d.uint32())
+	if d.err != nil {
+		return nil, d.err
+	}
+	if err != nil {
+		return nil, err
+	}
+	switch baseType {
+	case pointType:
+		flatCoords := d.float64s(nil, layout.Stride())
+		if d.err != nil {
+			return nil, d.err
+		}
+		if isNaN(flatCoords) {
+			return geom.NewPointEmpty(layout), nil
+		}
+		return geom.NewPointFlat(layout, flatCoords), nil
+	case lineStringType:
+		flatCoords := d.points(nil, layout, compressed)
+		if d.err != nil {
+			return nil, d.err
+		}
+		return geom.NewLineStringFlat(layout, flatCoords), nil
+	case polygonType:
+		flatCoords, ends := d.rings(layout, compressed)
+		if d.err != nil {
+			return nil, d.err
+		}
+		return geom.NewPolygonFlat(layout, flatCoords, ends), nil
+	case multiPointType:
+		mp := geom.NewMultiPoint(layout)
+		if err := d.entities(func(g geom.T) error {
+			p, ok := g.(*geom.Point)
+			if !ok {
+				return errInvalidData
+			}
+			return mp.Push(p)
+		}); err != nil {
+			return nil, err
+		}
+		return mp, nil
+	case multiLineStringType:
+		mls := geom.NewMultiLineString(layout)
+		if err := d.entities(func(g geom.T) error {
+			ls, ok := g.(*geom.LineString)
+			if !ok {
+				return errInvalidData
+			}
+			return mls.Push(ls)
+		}); err != nil {
+			return nil, err
+		}
+		return mls, nil
+	case multiPolygonType:
+		mp := geom.NewMultiPolygon(layout)
+		if err := d.entities(func(g geom.T) error {
+			p, ok := g.(*geom.Polygon)
+			if !ok {
+				return errInvalidData
+			}
+			return mp.Push(p)
+		}); err != nil {
+			return nil, err
+		}
+		return mp, nil
+	default:
+		gc := geom.NewGeometryCollection()
+		if err := d.entities(func(g geom.T) error {
+			return gc.Push(g)
+		}); err != nil {
+			return nil, err
+		}
+		return gc, nil
+	}
+}
+
+// entities decodes a number of entities and calls f with each of them.
+func (d *decoder) entities(f func(geom.T) error) error {
+	// Each entity has a marker, a class type, and a body of at least four
+	// bytes.
+	n := d.count(9)
+	for i := 0; i < n; i++ {
+		if marker := d.read(1); d.err != nil {
+			return d.err
+		} else if marker[0] != markerEntity {
+			return errInvalidData
+		}
+		g, err := d.decode()
+		if err != nil {
+			return err
+		}
+		if err := f(g); err != nil {
+			return err
+		}
+	}
+	return d.err
+}
+
+// rings decodes the rings of a polygon.
+func (d *decoder) rings(layout geom.Layout, compressed bool) ([]float64, []int) {
+	n := d.count(4)
+	var flatCoords []float64
+	ends := make([]int, 0, n)
+	for i := 0; i < n && d.err == nil; i++ {
+		flatCoords = d.points(flatCoords, layout, compressed)
+		ends = append(ends, len(flatCoords))
+	}
+	return flatCoords, ends
+}
+
+// points decodes a number of points and appends their coordinates to
+// flatCoords. In compressed geometries, the first and last points are
+// stored as float64s and the others as float32 deltas from the previous
+// point, except for M values, which are always stored as float64s.
+func (d *decoder) points(flatCoords []float64, layout geom.Layout, compressed bool) []float64 {
+	stride := layout.Stride()
+	if !compressed {
+		return d.float64s(flatCoords, d.count(8*stride)*stride)
+	}
+	mIndex := layout.MIndex()
+	n := d.count(4 * stride)
+	for i := 0; i < n && d.err == nil; i++ {
+		if i == 0 || i == n-1 {
+			flatCoords = d.float64s(flatCoords, stride)
+			continue
+		}
+		prev := flatCoords[len(flatCoords)-stride:]
+		for j := 0; j < stride; j++ {
+			if j == mIndex {
+				flatCoords = d.float64s(flatCoords, 1)
+			} else {
+				flatCoords = append(flatCoords, prev[j]+float64(math.Float32frombits(d.uint32())))
+			}
+		}
+	}
+	return flatCoords
+}
+
+// count decodes a count of items of at least minSize bytes each.
+func (d *decoder) count(minSize int) int {
+	n := d.uint32()
+	if d.err == nil && uint64(n)*uint64(minSize) > uint64(len(d.data)) {
+		d.err = errTruncated
+	}
+	if d.err != nil {
+		return 0
+	}
+	return int(n)
+}
+
+// float64s appends n float64s to vs.
+func (d *decoder) float64s(vs []float64, n int) []float64 {
+	data := d.read(8 * n)
+	for i := 0; i < len(data); i += 8 {
+		vs = append(vs, math.Float64frombits(d.byteOrder.Uint64(data[i:])))
+	}
+	return vs
+}
+
+func (d *decoder) uint32() uint32 {
+	if data := d.read(4); data != nil {
+		return d.byteOrder.Uint32(data)
+	}
+	return 0
+}
+
+// read returns the next n bytes.
+func (d *decoder) read(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if len(d.data) < n {
+		d.err = errTruncated
+		return nil
+	}
+	data := d.data[:n]
+	d.data = d.data[n:]
+	return data
+}
+
+// isNaN returns whether all of flatCoords are NaN.
+func isNaN(flatCoords []float64) bool {
+	for _, x := range flatCoords {
+		if !math.IsNaN(x) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,186 @@
+package spatialite
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/twpayne/go-geom"
+)
+
+// An encoder appends the encoding of geometries to a buffer.
+type encoder struct {
+	b         []byte
+	byteOrder binary.ByteOrder
+}
+
+// Append appends the SpatiaLite BLOB encoding of g to dst and returns the
+// extended buffer. Empty points are encoded with NaN ordinates and empty
+// geometries with a zero minimum bounding rectangle.
+func Append(dst []byte, g geom.T, opts ...Option) ([]byte, error) {
+	o := newOptions(opts)
+	e := &encoder{
+		b:         dst,
+		byteOrder: o.byteOrder,
+	}
+	if o.byteOrder == binary.LittleEndian {
+		e.b = append(e.b, markerStart, littleEndian)
+	} else {
+		e.b = append(e.b, markerStart, bigEndian)
+	}
+	e.uint32(uint32(int32(g.SRID())))
+	b := extend(geom.NewBounds(geom.XY), g)
+	if b.IsEmpty() {
+		e.float64s(0, 0, 0, 0)
+	} else {
+		e.float64s(b.Min(0), b.Min(1), b.Max(0), b.Max(1))
+	}
+	e.b = append(e.b, markerMBREnd)
+	if err := e.encode(g); err != nil {
+		return nil, err
+	}
+	return append(e.b, markerEnd), nil
+}
+
+// Marshal returns the SpatiaLite BLOB encoding of g.
+func Marshal(g geom.T, opts ...Option) ([]byte, error) {
+	return Append(nil, g, opts...)
+}
+
+// encode encodes the class type and body of g.
+func (e *encoder) encode(g geom.T) error {
+	switch g := g.(type) {
+	case *geom.Point:
+		if err := e.classType(pointType, g.Layout()); err != nil {
+			return err
+		}
+		e.point(g)
+	case *geom.LineString:
+		if err := e.classType(lineStringType, g.Layout()); err != nil {
+			return err
+		}
+		e.uint32(uint32(g.NumCoords()))
+		e.float64s(g.FlatCoords()...)
+	case *geom.Polygon:
+		if err := e.classType(polygonType, g.Layout()); err != nil {
+			return err
+		}
+		e.polygon(g.FlatCoords(), g.Ends(), g.Stride())
+	case *geom.MultiPoint:
+		if err := e.classType(multiPointType, g.Layout()); err != nil {
+			return err
+		}
+		e.uint32(uint32(g.NumPoints()))
+		for i := 0; i < g.NumPoints(); i++ {
+			e.b = append(e.b, markerEntity)
+			e.uint32(classType(pointType, g.Layout()))
+			e.point(g.Point(i))
+		}
+	case *geom.MultiLineString:
+		if err := e.classType(multiLineStringType, g.Layout()); err != nil {
+			return err
+		}
+		e.uint32(uint32(g.NumLineStrings()))
+		for i := 0; i < g.NumLineStrings(); i++ {
+			ls := g.LineString(i)
+			e.b = append(e.b, markerEntity)
+			e.uint32(classType(lineStringType, g.Layout()))
+			e.uint32(uint32(ls.NumCoords()))
+			e.float64s(ls.FlatCoords()...)
+		}
+	case *geom.MultiPolygon:
+		if err := e.classType(multiPolygonType, g.Layout()); err != nil {
+			return err
+		}
+		e.uint32(uint32(g.NumPolygons()))
+		for i := 0; i < g.NumPolygons(); i++ {
+			p := g.Polygon(i)
+			e.b = append(e.b, markerEntity)
+			e.uint32(classType(polygonType, g.Layout()))
+			e.polygon(p.FlatCoords(), p.Ends(), p.Stride())
+		}
+	case *geom.GeometryCollection:
+		layout := g.Layout()
+		if layout == geom.NoLayout {
+			layout = geom.XY
+		}
+		if err := e.classType(geometryCollectionType, layout); err != nil {
+			return err
+		}
+		e.uint32(uint32(g.NumGeoms()))
+		for _, member := range g.Geoms() {
+			switch member.(type) {
+			case *geom.Point, *geom.LineString, *geom.Polygon:
+			default:
+				return geom.ErrUnsupportedType{Value: member}
+			}
+			if member.Layout() != layout {
+				return geom.ErrLayoutMismatch{Got: member.Layout(), Want: layout}
+			}
+			e.b = append(e.b, markerEntity)
+			if err := e.encode(member); err != nil {
+				return err
+			}
+		}
+	default:
+		return geom.ErrUnsupportedType{Value: g}
+	}
+	return nil
+}
+
+// classType encodes the class type of geometries of type baseType with
+// layout.
+func (e *encoder) classType(baseType uint32, layout geom.Layout) error {
+	t := classType(baseType, layout)
+	if t == 0 {
+		return geom.ErrUnsupportedLayout(layout)
+	}
+	e.uint32(t)
+	return nil
+}
+
+// point encodes the coordinates of p, which are NaN if p is empty.
+func (e *encoder) point(p *geom.Point) {
+	if !p.Empty() {
+		e.float64s(p.FlatCoords()...)
+		return
+	}
+	for i := 0; i < p.Stride(); i++ {
+		e.float64s(quietNaN)
+	}
+}
+
+// polygon encodes the rings of a polygon.
+func (e *encoder) polygon(flatCoords []float64, ends []int, stride int) {
+	e.uint32(uint32(len(ends)))
+	offset := 0
+	for _, end := range ends {
+		e.uint32(uint32((end - offset) / stride))
+		e.float64s(flatCoords[offset:end]...)
+		offset = end
+	}
+}
+
+func (e *encoder) uint32(v uint32) {
+	var buf [4]byte
+	e.byteOrder.PutUint32(buf[:], v)
+	e.b = append(e.b, buf[:]...)
+}
+
+func (e *encoder) float64s(vs ...float64) {
+	var buf [8]byte
+	for _, v := range vs {
+		e.byteOrder.PutUint64(buf[:], math.Float64bits(v))
+		e.b = append(e.b, buf[:]...)
+	}
+}
+
+// extend extends b to include g, recursing into GeometryCollections.
+func extend(b *geom.Bounds, g geom.T) *geom.Bounds {
+	if gc, ok := g.(*geom.GeometryCollection); ok {
+		for _, member := range gc.Geoms() {
+			extend(b, member)
+		}
+		return b
+	}
+	return b.Extend(g)
+}
@@ -0,0 +1,164 @@
+// Package spatialite implements encoding and decoding of SpatiaLite BLOB
+// geometries, as stored in the geometry columns of SpatiaLite databases. See
+// https://www.gaia-gis.it/gaia-sins/BLOB-Geometry.html.
+//
+// A SpatiaLite BLOB geometry has a header containing its byte order, SRID,
+// and minimum bounding rectangle, followed by a class type and a body that
+// is similar to WKB, in which the members of collections are marked as
+// entities. Geometries with compressed coordinates, as written by SpatiaLite
+// when compression is enabled, are decoded but not encoded. SpatiaLite does
+// not support nested collections, so the members of GeometryCollections must
+// be Points, LineStrings, or Polygons.
+package spatialite
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/twpayne/go-geom"
+)
+
+// Markers.
+const (
+	markerStart  = 0x00
+	markerMBREnd = 0x7c
+	markerEntity = 0x69
+	markerEnd    = 0xfe
+)
+
+// Byte order flags.
+const (
+	bigEndian    = 0x00
+	littleEndian = 0x01
+)
+
+// Class types, before the dimension offsets.
+const (
+	pointType              = 1
+	lineStringType         = 2
+	polygonType            = 3
+	multiPointType         = 4
+	multiLineStringType    = 5
+	multiPolygonType       = 6
+	geometryCollectionType = 7
+)
+
+// Class type offsets.
+const (
+	zOffset          = 1000
+	mOffset          = 2000
+	zmOffset         = 3000
+	compressedOffset = 1000000
+)
+
+// headerSize is the size of the header, before the class type.
+const headerSize = 39
+
+// quietNaN is the NaN that encodes the ordinates of empty points.
+var quietNaN = math.Float64frombits(0x7ff8000000000000)
+
+var (
+	errInvalidData = errors.New("spatialite: invalid data")
+	errTruncated   = errors.New("spatialite: truncated data")
+)
+
+// An ErrUnsupportedClassType is returned when decoding a geometry with an
+// unknown or unsupported class type.
+type ErrUnsupportedClassType uint32
+
+func (e ErrUnsupportedClassType) Error() string {
+	return fmt.Sprintf("spatialite: unsupported class type %d", uint32(e))
+}
+
+// An Option sets an option for encoding.
+type Option func(*options)
+
+type options struct {
+	byteOrder binary.ByteOrder
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		byteOrder: binary.LittleEndian,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithByteOrder sets the byte order of encoded geometries. The default is
+// little endian.
+func WithByteOrder(byteOrder binary.ByteOrder) Option {
+	return func(o *options) {
+		o.byteOrder = byteOrder
+	}
+}
+
+// classType returns the class type of geometries of type baseType with
+// layout, or zero if layout is not supported.
+func classType(baseType uint32, layout geom.Layout) uint32 {
+	switch layout {
+	case geom.XY:
+		return baseType
+	case geom.XYZ:
+		return baseType + zOffset
+	case geom.XYM:
+		return baseType + mOffset
+	case geom.XYZM:
+		return baseType + zmOffset
+	default:
+		return 0
+	}
+}
+
+// parseClassType returns the base type, layout, and whether coordinates are
+// compressed of classType.
+func parseClassType(classType uint32) (uint32, geom.Layout, bool, error) {
+	t := classType
+	compressed := false
+	if t >= compressedOffset {
+		t -= compressedOffset
+		compressed = true
+	}
+	baseType := t % 1000
+	var layout geom.Layout
+	switch t / 1000 {
+	case 0:
+		layout = geom.XY
+	case 1:
+		layout = geom.XYZ
+	case 2:
+		layout = geom.XYM
+	case 3:
+		layout = geom.XYZM
+	}
+	// Points are never compressed.
+	if layout == geom.NoLayout || baseType < pointType || baseType > geometryCollectionType ||
+		compressed && baseType == pointType {
+		return 0, geom.NoLayout, false, ErrUnsupportedClassType(classType)
+	}
+	return baseType, layout, compressed, nil
+}
+
+// setSRID sets the SRID of g.
+func setSRID(g geom.T, srid int) {
+	switch g := g.(type) {
+	case *geom.Point:
+		g.SetSRID(srid)
+	case *geom.LineString:
+		g.SetSRID(srid)
+	case *geom.Polygon:
+		g.SetSRID(srid)
+	case *geom.MultiPoint:
+		g.SetSRID(srid)
+	case *geom.MultiLineString:
+		g.SetSRID(srid)
+	case *geom.MultiPolygon:
+		g.SetSRID(srid)
+	case *geom.GeometryCollection:
+		g.SetSRID(srid)
+	}
+}
@@ -0,0 +1,152 @@
+package spatialite
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+)
+
+func TestMarshal(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		g       geom.T
+		opts    []Option
+		wantHex string
+	}{
+		{
+			name: "point",
+			g:    geom.NewPointFlat(geom.XY, []float64{1, 2}).SetSRID(4326),
+			wantHex: "0001e6100000" +
+				"000000000000f03f" + "0000000000000040" + "000000000000f03f" + "0000000000000040" +
+				"7c" + "01000000" + "000000000000f03f" + "0000000000000040" + "fe",
+		},
+		{
+			name: "line_string_xdr",
+			g:    geom.NewLineStringFlat(geom.XYZ, []float64{1, 2, 3, 4, 5, 6}),
+			opts: []Option{WithByteOrder(binary.BigEndian)},
+			wantHex: "000000000000" +
+				"3ff0000000000000" + "4000000000000000" + "4010000000000000" + "4014000000000000" +
+				"7c" + "000003ea" + "00000002" +
+				"3ff0000000000000" + "4000000000000000" + "4008000000000000" +
+				"4010000000000000" + "4014000000000000" + "4018000000000000" + "fe",
+		},
+		{
+			name: "multi_point",
+			g:    geom.NewMultiPointFlat(geom.XY, []float64{1, 2}),
+			wantHex: "000100000000" +
+				"000000000000f03f" + "0000000000000040" + "000000000000f03f" + "0000000000000040" +
+				"7c" + "04000000" + "01000000" +
+				"69" + "01000000" + "000000000000f03f" + "0000000000000040" + "fe",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := Marshal(tc.g, tc.opts...)
+			if err != nil {
+				t.Fatalf("Marshal(%v) == _, %v, want _, <nil>", tc.g, err)
+			}
+			if got := hex.EncodeToString(data); got != tc.wantHex {
+				t.Errorf("Marshal(%v) == %s, _, want %s, _", tc.g, got, tc.wantHex)
+			}
+			got, err := Unmarshal(data)
+			if err != nil || !reflect.DeepEqual(got, tc.g) {
+				t.Errorf("Unmarshal(%s) == %v, %v, want %v, <nil>", tc.wantHex, got, err, tc.g)
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, g := range []geom.T{
+		geom.NewPointEmpty(geom.XYZM).SetSRID(3857),
+		geom.NewPointFlat(geom.XYM, []float64{1, 2, 3}),
+		geom.NewLineString(geom.XY),
+		geom.NewPolygonFlat(geom.XYZM, []float64{0, 0, 1, 2, 1, 0, 3, 4, 1, 1, 5, 6, 0, 0, 1, 2}, []int{16}).SetSRID(4326),
+		geom.NewMultiPointFlat(geom.XY, []float64{1, 2, 3, 4}),
+		geom.NewMultiLineStringFlat(geom.XYZ, []float64{1, 2, 3, 4, 5, 6, 7, 8, 9}, []int{6, 9}),
+		geom.NewMultiPolygonFlat(geom.XY, []float64{0, 0, 1, 0, 1, 1, 0, 0, 2, 2, 3, 2, 3, 3, 2, 2}, [][]int{{8}, {16}}),
+		geom.NewGeometryCollection().MustPush(
+			geom.NewPointFlat(geom.XY, []float64{1, 2}),
+			geom.NewLineStringFlat(geom.XY, []float64{3, 4, 5, 6}),
+		).SetSRID(4326),
+	} {
+		data, err := Marshal(g)
+		if err != nil {
+			t.Errorf("Marshal(%v) == _, %v, want _, <nil>", g, err)
+			continue
+		}
+		if got, err := Unmarshal(data); err != nil || !reflect.DeepEqual(got, g) {
+			t.Errorf("Unmarshal(Marshal(%v)) == %v, %v, want %v, <nil>", g, got, err, g)
+		}
+	}
+}
+
+func TestUnmarshalCompressed(t *testing.T) {
+	e := &encoder{
+		b:         []byte{markerStart, littleEndian, 0, 0, 0, 0},
+		byteOrder: binary.LittleEndian,
+	}
+	e.float64s(0, 0, 3, 2)
+	e.b = append(e.b, markerMBREnd)
+	e.uint32(compressedOffset + mOffset + lineStringType)
+	e.uint32(3)
+	e.float64s(0, 0, 10)
+	e.uint32(math.Float32bits(1.5))
+	e.uint32(math.Float32bits(2))
+	e.float64s(20)
+	e.float64s(3, 2, 30)
+	e.b = append(e.b, markerEnd)
+	want := geom.NewLineStringFlat(geom.XYM, []float64{0, 0, 10, 1.5, 2, 20, 3, 2, 30})
+	if got, err := Unmarshal(e.b); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal(%x) == %v, %v, want %v, <nil>", e.b, got, err, want)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	data, err := Marshal(geom.NewLineStringFlat(geom.XY, []float64{1, 2, 3, 4}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	badClassType := append([]byte(nil), data...)
+	badClassType[headerSize] = 8
+	for _, tc := range []struct {
+		name string
+		data []byte
+		err  error
+	}{
+		{
+			name: "short",
+			data: data[:headerSize],
+			err:  errTruncated,
+		},
+		{
+			name: "truncated",
+			data: append(append([]byte(nil), data[:len(data)-9]...), markerEnd),
+			err:  errTruncated,
+		},
+		{
+			name: "invalid_end",
+			data: data[:len(data)-1],
+			err:  errInvalidData,
+		},
+		{
+			name: "unsupported_class_type",
+			data: badClassType,
+			err:  ErrUnsupportedClassType(8),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := Unmarshal(tc.data); err != tc.err {
+				t.Errorf("Unmarshal(%x) == _, %v, want _, %v", tc.data, err, tc.err)
+			}
+		})
+	}
+
+	nested := geom.NewGeometryCollection().MustPush(geom.NewMultiPointFlat(geom.XY, []float64{1, 2}))
+	if _, err := Marshal(nested); !reflect.DeepEqual(err, geom.ErrUnsupportedType{Value: nested.Geom(0)}) {
+		t.Errorf("Marshal(%v) == _, %v, want _, %v", nested, err, geom.ErrUnsupportedType{Value: nested.Geom(0)})
+	}
+}
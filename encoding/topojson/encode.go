@@ -0,0 +1,510 @@
+package topojson
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"sort"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/geojson"
+)
+
+// An Option configures encoding.
+type Option func(*options)
+
+type options struct {
+	quantization int
+}
+
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithQuantization sets the number of distinct values of each of X and Y
+// when encoding, for example 1e4 or 1e6. Quantized coordinates are snapped to
+// a grid over the bounding box of the topology, stored as integers, and
+// delta-encoded in arcs, which makes the encoding much smaller. Values less
+// than two disable quantization, which is the default.
+func WithQuantization(n int) Option {
+	return func(o *options) {
+		o.quantization = n
+	}
+}
+
+// Encode encodes each of objects as a GeometryCollection object of the same
+// name whose members are its features, with their IDs and properties. Lines
+// and polygon rings are split into arcs where they meet, and arcs that are
+// shared, in either direction, are stored once. All lines and rings must have
+// the same layout.
+func Encode(objects map[string]*geojson.FeatureCollection, opts ...Option) (*Topology, error) {
+	o := newOptions(opts)
+	e := &encoder{
+		layout: geom.NoLayout,
+	}
+
+	bounds := geom.NewBounds(geom.XY)
+	for _, fc := range objects {
+		for _, f := range fc.Features {
+			extendBounds(bounds, f.Geometry)
+		}
+	}
+	t := &Topology{
+		Type:    "Topology",
+		Objects: make(map[string]*Object, len(objects)),
+	}
+	if !bounds.IsEmpty() {
+		t.BBox = []float64{bounds.Min(0), bounds.Min(1), bounds.Max(0), bounds.Max(1)}
+		if o.quantization > 1 {
+			e.transform = newTransform(bounds, o.quantization)
+			t.Transform = e.transform
+		}
+	}
+
+	// Encode the objects in order of name so that the encoding is
+	// deterministic.
+	names := make([]string, 0, len(objects))
+	for name := range objects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fc := objects[name]
+		collection := &Object{
+			Type:       "GeometryCollection",
+			Geometries: make([]*Object, 0, len(fc.Features)),
+		}
+		for _, f := range fc.Features {
+			object, err := e.object(f.Geometry)
+			if err != nil {
+				return nil, err
+			}
+			object.ID = f.ID
+			object.Properties = f.Properties
+			collection.Geometries = append(collection.Geometries, object)
+		}
+		t.Objects[name] = collection
+	}
+
+	lineArcs := e.arcs()
+	arcsOf := func(line int) []int {
+		if line < 0 {
+			return []int{}
+		}
+		return lineArcs[line]
+	}
+	for _, p := range e.pending {
+		var arcs interface{}
+		switch lines := p.lines.(type) {
+		case int:
+			arcs = arcsOf(lines)
+		case []int:
+			arcs2 := make([][]int, 0, len(lines))
+			for _, line := range lines {
+				arcs2 = append(arcs2, arcsOf(line))
+			}
+			arcs = arcs2
+		case [][]int:
+			arcs3 := make([][][]int, 0, len(lines))
+			for _, polygon := range lines {
+				arcs2 := make([][]int, 0, len(polygon))
+				for _, ring := range polygon {
+					arcs2 = append(arcs2, arcsOf(ring))
+				}
+				arcs3 = append(arcs3, arcs2)
+			}
+			arcs = arcs3
+		}
+		data, err := json.Marshal(arcs)
+		if err != nil {
+			return nil, err
+		}
+		p.object.Arcs = data
+	}
+	t.Arcs = e.encodeArcs()
+	return t, nil
+}
+
+// Marshal encodes objects as Encode does and marshals the result.
+func Marshal(objects map[string]*geojson.FeatureCollection, opts ...Option) ([]byte, error) {
+	t, err := Encode(objects, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(t)
+}
+
+// newTransform returns a transform that quantizes bounds to n values in X and
+// Y.
+func newTransform(bounds *geom.Bounds, n int) *Transform {
+	t := &Transform{
+		Scale:     [2]float64{1, 1},
+		Translate: [2]float64{bounds.Min(0), bounds.Min(1)},
+	}
+	for i := 0; i < 2; i++ {
+		if extent := bounds.Max(i) - bounds.Min(i); extent > 0 {
+			t.Scale[i] = extent / float64(n-1)
+		}
+	}
+	return t
+}
+
+// A coordKey identifies a coordinate of up to four dimensions.
+type coordKey [4]float64
+
+// A line is a line or ring to be split into arcs.
+type line struct {
+	flatCoords []float64
+	ring       bool
+}
+
+// A pendingObject is an object whose arcs are set once the arcs of its lines
+// are known. lines is the index of its line, a slice of indexes, or a slice
+// of slices of indexes, depending on its type.
+type pendingObject struct {
+	object *Object
+	lines  interface{}
+}
+
+// An encoder collects the lines of objects and splits them into arcs.
+type encoder struct {
+	transform *Transform
+	layout    geom.Layout
+	stride    int
+	lines     []line
+	pending   []pendingObject
+	arcCoords [][]float64
+}
+
+// extendBounds extends b by g, or by each of the members of g if it is a
+// GeometryCollection, whose Bounds cannot be extended directly.
+func extendBounds(b *geom.Bounds, g geom.T) {
+	switch g := g.(type) {
+	case nil:
+	case *geom.GeometryCollection:
+		for _, member := range g.Geoms() {
+			extendBounds(b, member)
+		}
+	default:
+		b.Extend(g)
+	}
+}
+
+// object returns the object encoding g, recording its lines.
+func (e *encoder) object(g geom.T) (*Object, error) {
+	var err error
+	switch g := g.(type) {
+	case nil:
+		return &Object{}, nil
+	case *geom.Point:
+		o := &Object{Type: "Point"}
+		o.Coordinates, err = json.Marshal(e.quantize(g.FlatCoords(), g.Stride()))
+		return o, err
+	case *geom.MultiPoint:
+		o := &Object{Type: "MultiPoint"}
+		positions := make([][]float64, 0, g.NumPoints())
+		for i := 0; i < g.NumPoints(); i++ {
+			positions = append(positions, e.quantize(g.Coord(i), g.Stride()))
+		}
+		o.Coordinates, err = json.Marshal(positions)
+		return o, err
+	case *geom.LineString:
+		o := &Object{Type: "LineString"}
+		index, err := e.addLine(g.Layout(), g.FlatCoords(), false)
+		if err != nil {
+			return nil, err
+		}
+		e.pending = append(e.pending, pendingObject{object: o, lines: index})
+		return o, nil
+	case *geom.MultiLineString:
+		o := &Object{Type: "MultiLineString"}
+		indexes, err := e.addLines(g.Layout(), g.FlatCoords(), 0, g.Ends(), false)
+		if err != nil {
+			return nil, err
+		}
+		e.pending = append(e.pending, pendingObject{object: o, lines: indexes})
+		return o, nil
+	case *geom.Polygon:
+		o := &Object{Type: "Polygon"}
+		indexes, err := e.addLines(g.Layout(), g.FlatCoords(), 0, g.Ends(), true)
+		if err != nil {
+			return nil, err
+		}
+		e.pending = append(e.pending, pendingObject{object: o, lines: indexes})
+		return o, nil
+	case *geom.MultiPolygon:
+		o := &Object{Type: "MultiPolygon"}
+		indexes := make([][]int, 0, g.NumPolygons())
+		offset := 0
+		for _, ends := range g.Endss() {
+			polygonIndexes, err := e.addLines(g.Layout(), g.FlatCoords(), offset, ends, true)
+			if err != nil {
+				return nil, err
+			}
+			indexes = append(indexes, polygonIndexes)
+			if len(ends) > 0 {
+				offset = ends[len(ends)-1]
+			}
+		}
+		e.pending = append(e.pending, pendingObject{object: o, lines: indexes})
+		return o, nil
+	case *geom.GeometryCollection:
+		o := &Object{
+			Type:       "GeometryCollection",
+			Geometries: make([]*Object, 0, g.NumGeoms()),
+		}
+		for _, member := range g.Geoms() {
+			memberObject, err := e.object(member)
+			if err != nil {
+				return nil, err
+			}
+			o.Geometries = append(o.Geometries, memberObject)
+		}
+		return o, nil
+	default:
+		return nil, geom.ErrUnsupportedType{Value: g}
+	}
+}
+
+// quantize returns the quantized position of the coordinate at the start of
+// flatCoords, or an empty position if flatCoords is empty.
+func (e *encoder) quantize(flatCoords []float64, stride int) []float64 {
+	if len(flatCoords) == 0 {
+		return []float64{}
+	}
+	position := append([]float64(nil), flatCoords[:stride]...)
+	if e.transform != nil {
+		for i := 0; i < 2; i++ {
+			position[i] = math.Round((position[i] - e.transform.Translate[i]) / e.transform.Scale[i])
+		}
+	}
+	return position
+}
+
+// addLine records the line or ring flatCoords, quantizing it and removing
+// consecutive duplicate coordinates, and returns its index, or -1 if it is
+// empty.
+func (e *encoder) addLine(layout geom.Layout, flatCoords []float64, ring bool) (int, error) {
+	if len(flatCoords) == 0 {
+		return -1, nil
+	}
+	if e.layout == geom.NoLayout {
+		e.layout, e.stride = layout, layout.Stride()
+	} else if layout != e.layout {
+		return 0, geom.ErrLayoutMismatch{Got: layout, Want: e.layout}
+	}
+	stride := e.stride
+	quantized := make([]float64, 0, len(flatCoords))
+	for i := 0; i < len(flatCoords); i += stride {
+		position := e.quantize(flatCoords[i:], stride)
+		if n := len(quantized); n > 0 && equal(quantized[n-stride:], position) {
+			continue
+		}
+		quantized = append(quantized, position...)
+	}
+	if len(quantized) == stride {
+		// Keep lines that collapse to a single coordinate as lines.
+		quantized = append(quantized, quantized...)
+	}
+	e.lines = append(e.lines, line{flatCoords: quantized, ring: ring})
+	return len(e.lines) - 1, nil
+}
+
+// addLines records the lines or rings with ends starting at offset in
+// flatCoords and returns their indexes.
+func (e *encoder) addLines(layout geom.Layout, flatCoords []float64, offset int, ends []int, ring bool) ([]int, error) {
+	indexes := make([]int, 0, len(ends))
+	for _, end := range ends {
+		index, err := e.addLine(layout, flatCoords[offset:end], ring)
+		if err != nil {
+			return nil, err
+		}
+		indexes = append(indexes, index)
+		offset = end
+	}
+	return indexes, nil
+}
+
+// arcs splits the recorded lines into shared arcs, and returns the arc
+// indexes of each line.
+func (e *encoder) arcs() [][]int {
+	junctions := e.junctions()
+	arcIndexes := make(map[string]int)
+	lineArcs := make([][]int, len(e.lines))
+	var keyBuf []byte
+	addArc := func(flatCoords []float64) int {
+		keyBuf = appendArcKey(keyBuf[:0], flatCoords, e.stride, false)
+		if index, ok := arcIndexes[string(keyBuf)]; ok {
+			return index
+		}
+		keyBuf = appendArcKey(keyBuf[:0], flatCoords, e.stride, true)
+		if index, ok := arcIndexes[string(keyBuf)]; ok {
+			return ^index
+		}
+		index := len(e.arcCoords)
+		e.arcCoords = append(e.arcCoords, flatCoords)
+		keyBuf = appendArcKey(keyBuf[:0], flatCoords, e.stride, false)
+		arcIndexes[string(keyBuf)] = index
+		return index
+	}
+	for i, l := range e.lines {
+		flatCoords, stride := l.flatCoords, e.stride
+		if l.ring {
+			flatCoords = e.rotateRing(flatCoords, junctions)
+		}
+		arcs := []int{}
+		start := 0
+		for j := stride; j < len(flatCoords)-stride; j += stride {
+			if junctions[e.key(flatCoords[j:])] {
+				arcs = append(arcs, addArc(flatCoords[start:j+stride]))
+				start = j
+			}
+		}
+		lineArcs[i] = append(arcs, addArc(flatCoords[start:]))
+	}
+	return lineArcs
+}
+
+// junctions returns the coordinates at which lines must be split: the ends
+// of lines, and the coordinates at which lines meet or diverge, which have
+// different neighbors on different lines.
+func (e *encoder) junctions() map[coordKey]bool {
+	type neighbors struct{ a, b coordKey }
+	visited := make(map[coordKey]neighbors)
+	junctions := make(map[coordKey]bool)
+	stride := e.stride
+	for _, l := range e.lines {
+		flatCoords := l.flatCoords
+		n := len(flatCoords)
+		if !l.ring {
+			junctions[e.key(flatCoords)] = true
+			junctions[e.key(flatCoords[n-stride:])] = true
+		}
+		for i := 0; i < n-stride; i += stride {
+			if !l.ring && i == 0 {
+				continue
+			}
+			prev := i - stride
+			if prev < 0 {
+				// The previous coordinate of the start of a ring is the one
+				// before its closing coordinate.
+				prev = n - 2*stride
+			}
+			next := i + stride
+			if l.ring && next == n-stride {
+				next = 0
+			}
+			k, a, b := e.key(flatCoords[i:]), e.key(flatCoords[prev:]), e.key(flatCoords[next:])
+			if less(b, a) {
+				a, b = b, a
+			}
+			if v, ok := visited[k]; !ok {
+				visited[k] = neighbors{a: a, b: b}
+			} else if v.a != a || v.b != b {
+				junctions[k] = true
+			}
+		}
+	}
+	return junctions
+}
+
+// rotateRing returns ring rotated to start at its first junction or, if it
+// has none, at its least coordinate, so that rings shared in their entirety
+// are split into identical arcs.
+func (e *encoder) rotateRing(ring []float64, junctions map[coordKey]bool) []float64 {
+	stride := e.stride
+	n := len(ring) - stride
+	if n <= stride {
+		return ring
+	}
+	start := -1
+	for i := 0; i < n; i += stride {
+		if junctions[e.key(ring[i:])] {
+			start = i
+			break
+		}
+	}
+	if start == -1 {
+		start = 0
+		for i := stride; i < n; i += stride {
+			if less(e.key(ring[i:]), e.key(ring[start:])) {
+				start = i
+			}
+		}
+	}
+	if start == 0 {
+		return ring
+	}
+	rotated := make([]float64, 0, len(ring))
+	rotated = append(rotated, ring[start:n]...)
+	rotated = append(rotated, ring[:start]...)
+	return append(rotated, ring[start:start+stride]...)
+}
+
+// encodeArcs returns the arcs, delta-encoded if they are quantized.
+func (e *encoder) encodeArcs() [][][]float64 {
+	arcs := make([][][]float64, 0, len(e.arcCoords))
+	stride := e.stride
+	for _, flatCoords := range e.arcCoords {
+		arc := make([][]float64, 0, len(flatCoords)/stride)
+		var x, y float64
+		for i := 0; i < len(flatCoords); i += stride {
+			position := append([]float64(nil), flatCoords[i:i+stride]...)
+			if e.transform != nil {
+				position[0], position[1] = flatCoords[i]-x, flatCoords[i+1]-y
+				x, y = flatCoords[i], flatCoords[i+1]
+			}
+			arc = append(arc, position)
+		}
+		arcs = append(arcs, arc)
+	}
+	return arcs
+}
+
+// key returns the key of the coordinate at the start of flatCoords.
+func (e *encoder) key(flatCoords []float64) coordKey {
+	var k coordKey
+	copy(k[:], flatCoords[:e.stride])
+	return k
+}
+
+// appendArcKey appends a key identifying the coordinates of an arc, in
+// reverse order if reversed, to dst.
+func appendArcKey(dst []byte, flatCoords []float64, stride int, reversed bool) []byte {
+	var buf [8]byte
+	for i := 0; i < len(flatCoords); i += stride {
+		j := i
+		if reversed {
+			j = len(flatCoords) - stride - i
+		}
+		for _, f := range flatCoords[j : j+stride] {
+			binary.LittleEndian.PutUint64(buf[:], math.Float64bits(f))
+			dst = append(dst, buf[:]...)
+		}
+	}
+	return dst
+}
+
+// equal returns whether the coordinates a and b are equal.
+func equal(a, b []float64) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// less returns whether a is lexicographically less than b.
+func less(a, b coordKey) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return false
+}
@@ -0,0 +1,372 @@
+// Package topojson implements encoding and decoding of TopoJSON topologies.
+//
+// A TopoJSON topology stores the lines and polygon rings of its geometries
+// as shared arcs, so boundaries shared by adjacent polygons are stored once,
+// and may quantize and delta-encode coordinates to integers. See
+// https://github.com/topojson/topojson-specification.
+package topojson
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/geojson"
+)
+
+// An ErrUnsupportedType is returned when the type is unsupported.
+type ErrUnsupportedType string
+
+func (e ErrUnsupportedType) Error() string {
+	return fmt.Sprintf("topojson: unsupported type: %s", string(e))
+}
+
+// An ErrInvalidTopology is returned when a topology is structurally invalid,
+// for example when it refers to an arc that does not exist.
+type ErrInvalidTopology string
+
+func (e ErrInvalidTopology) Error() string {
+	return "topojson: invalid topology: " + string(e)
+}
+
+// An ErrUnknownObject is returned when a topology has no object with the
+// requested name.
+type ErrUnknownObject string
+
+func (e ErrUnknownObject) Error() string {
+	return fmt.Sprintf("topojson: unknown object: %s", string(e))
+}
+
+// A Topology is a TopoJSON topology.
+type Topology struct {
+	Type      string             `json:"type"`
+	BBox      []float64          `json:"bbox,omitempty"`
+	Transform *Transform         `json:"transform,omitempty"`
+	Objects   map[string]*Object `json:"objects"`
+	Arcs      [][][]float64      `json:"arcs"`
+}
+
+// A Transform maps the quantized positions of a topology to coordinates.
+type Transform struct {
+	Scale     [2]float64 `json:"scale"`
+	Translate [2]float64 `json:"translate"`
+}
+
+// An Object is a TopoJSON geometry object. Its coordinates or arc indexes are
+// kept undecoded, as their structure depends on its type. Null objects, which
+// have no geometry, have an empty Type.
+type Object struct {
+	Type        string                 `json:"type"`
+	ID          string                 `json:"id,omitempty"`
+	BBox        []float64              `json:"bbox,omitempty"`
+	Properties  map[string]interface{} `json:"properties,omitempty"`
+	Coordinates json.RawMessage        `json:"coordinates,omitempty"`
+	Arcs        json.RawMessage        `json:"arcs,omitempty"`
+	Geometries  []*Object              `json:"geometries,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler.MarshalJSON. Null objects are encoded
+// with a null type, and GeometryCollections always have a geometries member.
+func (o *Object) MarshalJSON() ([]byte, error) {
+	type object Object
+	var typ, geometries interface{}
+	if o.Type != "" {
+		typ = o.Type
+	}
+	if o.Type == "GeometryCollection" {
+		if o.Geometries == nil {
+			geometries = []*Object{}
+		} else {
+			geometries = o.Geometries
+		}
+	}
+	return json.Marshal(struct {
+		Type       interface{} `json:"type"`
+		Geometries interface{} `json:"geometries,omitempty"`
+		*object
+	}{
+		Type:       typ,
+		Geometries: geometries,
+		object:     (*object)(o),
+	})
+}
+
+// Unmarshal unmarshals a topology.
+func Unmarshal(data []byte) (*Topology, error) {
+	t := &Topology{}
+	if err := json.Unmarshal(data, t); err != nil {
+		return nil, err
+	}
+	if t.Type != "Topology" {
+		return nil, ErrUnsupportedType(t.Type)
+	}
+	return t, nil
+}
+
+// Geometry returns the geometry of the object called name. Nested objects of
+// GeometryCollections are returned as nested GeometryCollections, and null
+// objects as nil.
+func (t *Topology) Geometry(name string) (geom.T, error) {
+	o, ok := t.Objects[name]
+	if !ok {
+		return nil, ErrUnknownObject(name)
+	}
+	d, err := newDecoder(t)
+	if err != nil {
+		return nil, err
+	}
+	return d.geometry(o)
+}
+
+// FeatureCollection returns the object called name as a FeatureCollection.
+// The members of a GeometryCollection become features, and any other object
+// becomes a single feature, with the IDs and properties of the objects.
+func (t *Topology) FeatureCollection(name string) (*geojson.FeatureCollection, error) {
+	o, ok := t.Objects[name]
+	if !ok {
+		return nil, ErrUnknownObject(name)
+	}
+	d, err := newDecoder(t)
+	if err != nil {
+		return nil, err
+	}
+	members := []*Object{o}
+	if o.Type == "GeometryCollection" {
+		members = o.Geometries
+	}
+	fc := &geojson.FeatureCollection{
+		Features: make([]*geojson.Feature, 0, len(members)),
+	}
+	for _, member := range members {
+		g, err := d.geometry(member)
+		if err != nil {
+			return nil, err
+		}
+		fc.Features = append(fc.Features, &geojson.Feature{
+			ID:         member.ID,
+			Geometry:   g,
+			Properties: member.Properties,
+		})
+	}
+	return fc, nil
+}
+
+// A decoder decodes the objects of a topology.
+type decoder struct {
+	transform *Transform
+	arcs      [][]float64 // flat coordinates of each arc
+	layout    geom.Layout
+	stride    int
+}
+
+// newDecoder returns a new decoder for t, decoding its arcs.
+func newDecoder(t *Topology) (*decoder, error) {
+	d := &decoder{
+		transform: t.Transform,
+		arcs:      make([][]float64, len(t.Arcs)),
+		layout:    geom.XY,
+		stride:    2,
+	}
+	if len(t.Arcs) > 0 && len(t.Arcs[0]) > 0 {
+		var err error
+		if d.layout, err = layoutOf(t.Arcs[0][0]); err != nil {
+			return nil, err
+		}
+		d.stride = d.layout.Stride()
+	}
+	for i, arc := range t.Arcs {
+		flatCoords := make([]float64, 0, len(arc)*d.stride)
+		var x, y float64
+		for _, position := range arc {
+			if len(position) != d.stride {
+				return nil, ErrInvalidTopology(fmt.Sprintf("arc %d has a position with %d dimensions, want %d", i, len(position), d.stride))
+			}
+			if d.transform == nil {
+				flatCoords = append(flatCoords, position...)
+				continue
+			}
+			// Quantized arcs are delta-encoded.
+			x += position[0]
+			y += position[1]
+			flatCoords = append(flatCoords, x*d.transform.Scale[0]+d.transform.Translate[0], y*d.transform.Scale[1]+d.transform.Translate[1])
+			flatCoords = append(flatCoords, position[2:]...)
+		}
+		d.arcs[i] = flatCoords
+	}
+	return d, nil
+}
+
+// geometry decodes o.
+func (d *decoder) geometry(o *Object) (geom.T, error) {
+	switch o.Type {
+	case "":
+		return nil, nil
+	case "Point":
+		var position []float64
+		if err := unmarshalMember(o.Coordinates, &position); err != nil {
+			return nil, err
+		}
+		if len(position) == 0 {
+			return geom.NewPointEmpty(d.layout), nil
+		}
+		layout, err := layoutOf(position)
+		if err != nil {
+			return nil, err
+		}
+		return geom.NewPointFlat(layout, d.position(position)), nil
+	case "MultiPoint":
+		var positions [][]float64
+		if err := unmarshalMember(o.Coordinates, &positions); err != nil {
+			return nil, err
+		}
+		if len(positions) == 0 {
+			return geom.NewMultiPoint(d.layout), nil
+		}
+		layout, err := layoutOf(positions[0])
+		if err != nil {
+			return nil, err
+		}
+		flatCoords := make([]float64, 0, len(positions)*layout.Stride())
+		for _, position := range positions {
+			if len(position) != layout.Stride() {
+				return nil, ErrInvalidTopology(fmt.Sprintf("MultiPoint has a position with %d dimensions, want %d", len(position), layout.Stride()))
+			}
+			flatCoords = append(flatCoords, d.position(position)...)
+		}
+		return geom.NewMultiPointFlat(layout, flatCoords), nil
+	case "LineString":
+		var arcs []int
+		if err := unmarshalMember(o.Arcs, &arcs); err != nil {
+			return nil, err
+		}
+		flatCoords, err := d.line(nil, arcs)
+		if err != nil {
+			return nil, err
+		}
+		return geom.NewLineStringFlat(d.layout, flatCoords), nil
+	case "MultiLineString", "Polygon":
+		var arcs [][]int
+		if err := unmarshalMember(o.Arcs, &arcs); err != nil {
+			return nil, err
+		}
+		flatCoords, ends, err := d.lines(nil, arcs)
+		if err != nil {
+			return nil, err
+		}
+		if o.Type == "Polygon" {
+			return geom.NewPolygonFlat(d.layout, flatCoords, ends), nil
+		}
+		return geom.NewMultiLineStringFlat(d.layout, flatCoords, ends), nil
+	case "MultiPolygon":
+		var arcs [][][]int
+		if err := unmarshalMember(o.Arcs, &arcs); err != nil {
+			return nil, err
+		}
+		var flatCoords []float64
+		endss := make([][]int, 0, len(arcs))
+		for _, polygonArcs := range arcs {
+			var ends []int
+			var err error
+			if flatCoords, ends, err = d.lines(flatCoords, polygonArcs); err != nil {
+				return nil, err
+			}
+			endss = append(endss, ends)
+		}
+		return geom.NewMultiPolygonFlat(d.layout, flatCoords, endss), nil
+	case "GeometryCollection":
+		gc := geom.NewGeometryCollection()
+		for _, member := range o.Geometries {
+			g, err := d.geometry(member)
+			if err != nil {
+				return nil, err
+			}
+			if g == nil {
+				continue
+			}
+			if err := gc.Push(g); err != nil {
+				return nil, err
+			}
+		}
+		return gc, nil
+	default:
+		return nil, ErrUnsupportedType(o.Type)
+	}
+}
+
+// position returns the coordinates of the quantized position.
+func (d *decoder) position(position []float64) []float64 {
+	if d.transform == nil {
+		return position
+	}
+	coords := append([]float64(nil), position...)
+	coords[0] = position[0]*d.transform.Scale[0] + d.transform.Translate[0]
+	coords[1] = position[1]*d.transform.Scale[1] + d.transform.Translate[1]
+	return coords
+}
+
+// line appends the coordinates of the line or ring made of arcs to
+// flatCoords. Each arc after the first starts at the end of the previous arc,
+// so its first coordinate is omitted. Negative indexes refer to reversed
+// arcs.
+func (d *decoder) line(flatCoords []float64, arcs []int) ([]float64, error) {
+	for k, index := range arcs {
+		reversed := index < 0
+		if reversed {
+			index = ^index
+		}
+		if index >= len(d.arcs) {
+			return nil, ErrInvalidTopology(fmt.Sprintf("arc %d does not exist", index))
+		}
+		arc := d.arcs[index]
+		skip := 0
+		if k > 0 {
+			skip = d.stride
+		}
+		if !reversed {
+			if skip < len(arc) {
+				flatCoords = append(flatCoords, arc[skip:]...)
+			}
+			continue
+		}
+		for i := len(arc) - d.stride - skip; i >= 0; i -= d.stride {
+			flatCoords = append(flatCoords, arc[i:i+d.stride]...)
+		}
+	}
+	return flatCoords, nil
+}
+
+// lines appends the coordinates of each line or ring in arcs to flatCoords
+// and returns them with their ends.
+func (d *decoder) lines(flatCoords []float64, arcs [][]int) ([]float64, []int, error) {
+	ends := make([]int, 0, len(arcs))
+	for _, lineArcs := range arcs {
+		var err error
+		if flatCoords, err = d.line(flatCoords, lineArcs); err != nil {
+			return nil, nil, err
+		}
+		ends = append(ends, len(flatCoords))
+	}
+	return flatCoords, ends, nil
+}
+
+// layoutOf returns the layout of position.
+func layoutOf(position []float64) (geom.Layout, error) {
+	switch len(position) {
+	case 2:
+		return geom.XY, nil
+	case 3:
+		return geom.XYZ, nil
+	case 4:
+		return geom.XYZM, nil
+	default:
+		return geom.NoLayout, ErrInvalidTopology(fmt.Sprintf("position with %d dimensions", len(position)))
+	}
+}
+
+// unmarshalMember unmarshals data, if any, into v.
+func unmarshalMember(data json.RawMessage, v interface{}) error {
+	if data == nil {
+		return nil
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,361 @@
+package topojson
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/geojson"
+)
+
+func TestUnmarshal(t *testing.T) {
+	data := []byte(`{
+		"type": "Topology",
+		"objects": {
+			"example": {
+				"type": "GeometryCollection",
+				"geometries": [
+					{"type": "Point", "id": "p", "properties": {"name": "point"}, "coordinates": [1, 2]},
+					{"type": "MultiPoint", "coordinates": [[1, 2], [3, 4]]},
+					{"type": "LineString", "arcs": [0, 1]},
+					{"type": "MultiLineString", "arcs": [[0], [-2]]},
+					{"type": "Polygon", "arcs": [[2]]},
+					{"type": "MultiPolygon", "arcs": [[[2]], [[-3]]]},
+					{"type": null},
+					{"type": "GeometryCollection", "geometries": []}
+				]
+			}
+		},
+		"arcs": [
+			[[0, 0], [1, 0]],
+			[[1, 0], [1, 1], [2, 1]],
+			[[0, 0], [1, 0], [0, 1], [0, 0]]
+		]
+	}`)
+	topology, err := Unmarshal(data)
+	if err != nil {
+		t.Fatalf("Unmarshal(...) == _, %v, want _, <nil>", err)
+	}
+	wantGeoms := []geom.T{
+		geom.NewPointFlat(geom.XY, []float64{1, 2}),
+		geom.NewMultiPointFlat(geom.XY, []float64{1, 2, 3, 4}),
+		geom.NewLineStringFlat(geom.XY, []float64{0, 0, 1, 0, 1, 1, 2, 1}),
+		geom.NewMultiLineStringFlat(geom.XY, []float64{0, 0, 1, 0, 2, 1, 1, 1, 1, 0}, []int{4, 10}),
+		geom.NewPolygonFlat(geom.XY, []float64{0, 0, 1, 0, 0, 1, 0, 0}, []int{8}),
+		geom.NewMultiPolygonFlat(geom.XY, []float64{0, 0, 1, 0, 0, 1, 0, 0, 0, 0, 0, 1, 1, 0, 0, 0}, [][]int{{8}, {16}}),
+		nil,
+		geom.NewGeometryCollection(),
+	}
+
+	fc, err := topology.FeatureCollection("example")
+	if err != nil {
+		t.Fatalf("topology.FeatureCollection(%q) == _, %v, want _, <nil>", "example", err)
+	}
+	if len(fc.Features) != len(wantGeoms) {
+		t.Fatalf("len(fc.Features) == %d, want %d", len(fc.Features), len(wantGeoms))
+	}
+	for i, f := range fc.Features {
+		if !reflect.DeepEqual(f.Geometry, wantGeoms[i]) {
+			t.Errorf("fc.Features[%d].Geometry == %#v, want %#v", i, f.Geometry, wantGeoms[i])
+		}
+	}
+	if f := fc.Features[0]; f.ID != "p" || !reflect.DeepEqual(f.Properties, map[string]interface{}{"name": "point"}) {
+		t.Errorf("fc.Features[0] == %+v, want ID p and properties", f)
+	}
+
+	g, err := topology.Geometry("example")
+	if err != nil {
+		t.Fatalf("topology.Geometry(%q) == _, %v, want _, <nil>", "example", err)
+	}
+	wantGC := geom.NewGeometryCollection()
+	for _, wantGeom := range wantGeoms {
+		if wantGeom != nil {
+			wantGC.MustPush(wantGeom)
+		}
+	}
+	if !reflect.DeepEqual(g, wantGC) {
+		t.Errorf("topology.Geometry(%q) == %#v, want %#v", "example", g, wantGC)
+	}
+}
+
+func TestUnmarshalQuantized(t *testing.T) {
+	// This is the example from the TopoJSON specification.
+	data := []byte(`{
+		"type": "Topology",
+		"transform": {
+			"scale": [0.0005000500050005, 0.00010001000100010001],
+			"translate": [100, 0]
+		},
+		"objects": {
+			"example": {
+				"type": "GeometryCollection",
+				"geometries": [
+					{"type": "Point", "properties": {"prop0": "value0"}, "coordinates": [4000, 5000]},
+					{"type": "LineString", "properties": {"prop0": "value0", "prop1": 0}, "arcs": [0]},
+					{"type": "Polygon", "properties": {"prop0": "value0", "prop1": {"this": "that"}}, "arcs": [[-2]]}
+				]
+			}
+		},
+		"arcs": [
+			[[4000, 0], [1999, 9999], [2000, -9999], [2000, 9999]],
+			[[0, 0], [0, 9999], [2000, 0], [0, -9999], [-2000, 0]]
+		]
+	}`)
+	topology, err := Unmarshal(data)
+	if err != nil {
+		t.Fatalf("Unmarshal(...) == _, %v, want _, <nil>", err)
+	}
+	fc, err := topology.FeatureCollection("example")
+	if err != nil {
+		t.Fatalf("topology.FeatureCollection(%q) == _, %v, want _, <nil>", "example", err)
+	}
+	for i, want := range [][]float64{
+		{102, 0.5},
+		{102, 0, 103, 1, 104, 0, 105, 1},
+		{100, 0, 101, 0, 101, 1, 100, 1, 100, 0},
+	} {
+		got := fc.Features[i].Geometry.FlatCoords()
+		if len(got) != len(want) {
+			t.Errorf("fc.Features[%d].Geometry.FlatCoords() == %v, want %v", i, got, want)
+			continue
+		}
+		for j := range want {
+			if math.Abs(got[j]-want[j]) > 1e-3 {
+				t.Errorf("fc.Features[%d].Geometry.FlatCoords() == %v, want %v", i, got, want)
+				break
+			}
+		}
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		data    string
+		object  string
+		wantErr error
+	}{
+		{
+			name:    "unsupported_topology_type",
+			data:    `{"type":"FeatureCollection"}`,
+			wantErr: ErrUnsupportedType("FeatureCollection"),
+		},
+		{
+			name:    "unknown_object",
+			data:    `{"type":"Topology","objects":{},"arcs":[]}`,
+			object:  "example",
+			wantErr: ErrUnknownObject("example"),
+		},
+		{
+			name:    "unsupported_object_type",
+			data:    `{"type":"Topology","objects":{"example":{"type":"Circle"}},"arcs":[]}`,
+			object:  "example",
+			wantErr: ErrUnsupportedType("Circle"),
+		},
+		{
+			name:    "missing_arc",
+			data:    `{"type":"Topology","objects":{"example":{"type":"LineString","arcs":[-2]}},"arcs":[[[0,0],[1,1]]]}`,
+			object:  "example",
+			wantErr: ErrInvalidTopology("arc 1 does not exist"),
+		},
+		{
+			name:    "inconsistent_arcs",
+			data:    `{"type":"Topology","objects":{"example":{"type":"LineString","arcs":[0]}},"arcs":[[[0,0],[1,1,1]]]}`,
+			object:  "example",
+			wantErr: ErrInvalidTopology("arc 0 has a position with 3 dimensions, want 2"),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			topology, err := Unmarshal([]byte(tc.data))
+			if err == nil {
+				_, err = topology.Geometry(tc.object)
+			}
+			if err != tc.wantErr {
+				t.Errorf("got error %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	objects := map[string]*geojson.FeatureCollection{
+		"squares": {
+			Features: []*geojson.Feature{
+				{
+					ID:         "a",
+					Geometry:   geom.NewPolygonFlat(geom.XY, []float64{0, 0, 1, 0, 1, 1, 0, 1, 0, 0}, []int{10}),
+					Properties: map[string]interface{}{"name": "a"},
+				},
+				{
+					ID:       "b",
+					Geometry: geom.NewPolygonFlat(geom.XY, []float64{1, 0, 2, 0, 2, 1, 1, 1, 1, 0}, []int{10}),
+				},
+				{
+					ID: "c",
+				},
+			},
+		},
+	}
+	want := `{"type":"Topology","bbox":[0,0,2,1],"objects":{"squares":{"type":"GeometryCollection","geometries":[` +
+		`{"type":"Polygon","id":"a","properties":{"name":"a"},"arcs":[[0,1]]},` +
+		`{"type":"Polygon","id":"b","arcs":[[2,-1]]},` +
+		`{"type":null,"id":"c"}]}},` +
+		`"arcs":[[[1,0],[1,1]],[[1,1],[0,1],[0,0],[1,0]],[[1,0],[2,0],[2,1],[1,1]]]}`
+	got, err := Marshal(objects)
+	if err != nil || string(got) != want {
+		t.Errorf("Marshal(objects) == %s, %v, want %s, <nil>", got, err, want)
+	}
+
+	topology, err := Unmarshal(got)
+	if err != nil {
+		t.Fatalf("Unmarshal(...) == _, %v, want _, <nil>", err)
+	}
+	fc, err := topology.FeatureCollection("squares")
+	if err != nil {
+		t.Fatalf("topology.FeatureCollection(%q) == _, %v, want _, <nil>", "squares", err)
+	}
+	for i, wantGeom := range []geom.T{
+		geom.NewPolygonFlat(geom.XY, []float64{1, 0, 1, 1, 0, 1, 0, 0, 1, 0}, []int{10}),
+		geom.NewPolygonFlat(geom.XY, []float64{1, 0, 2, 0, 2, 1, 1, 1, 1, 0}, []int{10}),
+		nil,
+	} {
+		if !reflect.DeepEqual(fc.Features[i].Geometry, wantGeom) {
+			t.Errorf("fc.Features[%d].Geometry == %#v, want %#v", i, fc.Features[i].Geometry, wantGeom)
+		}
+	}
+}
+
+func TestEncodeLines(t *testing.T) {
+	// The lines share the segment from (1, 0) to (2, 0), and the ring of the
+	// island is a hole of the lake in the opposite direction.
+	objects := map[string]*geojson.FeatureCollection{
+		"lines": {
+			Features: []*geojson.Feature{
+				{Geometry: geom.NewLineStringFlat(geom.XY, []float64{0, 0, 1, 0, 2, 0, 3, 0})},
+				{Geometry: geom.NewLineStringFlat(geom.XY, []float64{1, 1, 1, 0, 2, 0, 2, 1})},
+				{Geometry: geom.NewLineString(geom.XY)},
+			},
+		},
+		"water": {
+			Features: []*geojson.Feature{
+				{Geometry: geom.NewPolygonFlat(geom.XY, []float64{
+					10, 10, 20, 10, 20, 20, 10, 20, 10, 10,
+					12, 12, 12, 14, 14, 14, 14, 12, 12, 12,
+				}, []int{10, 20})},
+				{Geometry: geom.NewMultiPolygonFlat(geom.XY, []float64{
+					14, 14, 12, 14, 12, 12, 14, 12, 14, 14,
+				}, [][]int{{10}})},
+			},
+		},
+	}
+	topology, err := Encode(objects)
+	if err != nil {
+		t.Fatalf("Encode(objects) == _, %v, want _, <nil>", err)
+	}
+	var gotArcs []string
+	for _, object := range []*Object{topology.Objects["lines"], topology.Objects["water"]} {
+		for _, member := range object.Geometries {
+			gotArcs = append(gotArcs, string(member.Arcs))
+		}
+	}
+	wantArcs := []string{`[0,1,2]`, `[3,1,4]`, `[]`, `[[5],[6]]`, `[[[-7]]]`}
+	if !reflect.DeepEqual(gotArcs, wantArcs) {
+		t.Errorf("got arcs %v, want %v", gotArcs, wantArcs)
+	}
+	if len(topology.Arcs) != 7 {
+		t.Errorf("len(topology.Arcs) == %d, want 7", len(topology.Arcs))
+	}
+
+	data, err := json.Marshal(topology)
+	if err != nil {
+		t.Fatalf("json.Marshal(topology) == _, %v, want _, <nil>", err)
+	}
+	decoded, err := Unmarshal(data)
+	if err != nil {
+		t.Fatalf("Unmarshal(...) == _, %v, want _, <nil>", err)
+	}
+	fc, err := decoded.FeatureCollection("lines")
+	if err != nil {
+		t.Fatalf("decoded.FeatureCollection(%q) == _, %v, want _, <nil>", "lines", err)
+	}
+	for i, f := range objects["lines"].Features {
+		if !reflect.DeepEqual(fc.Features[i].Geometry, f.Geometry) {
+			t.Errorf("fc.Features[%d].Geometry == %#v, want %#v", i, fc.Features[i].Geometry, f.Geometry)
+		}
+	}
+}
+
+func TestEncodeQuantized(t *testing.T) {
+	objects := map[string]*geojson.FeatureCollection{
+		"example": {
+			Features: []*geojson.Feature{
+				{Geometry: geom.NewPointFlat(geom.XYZ, []float64{1, 0.5, 7})},
+				{Geometry: geom.NewLineStringFlat(geom.XY, []float64{0, 0, 0.9, 0.26, 2, 1})},
+			},
+		},
+	}
+	want := `{"type":"Topology","bbox":[0,0,2,1],"transform":{"scale":[1,0.5],"translate":[0,0]},` +
+		`"objects":{"example":{"type":"GeometryCollection","geometries":[` +
+		`{"type":"Point","coordinates":[1,1,7]},` +
+		`{"type":"LineString","arcs":[0]}]}},` +
+		`"arcs":[[[0,0],[1,1],[1,1]]]}`
+	got, err := Marshal(objects, WithQuantization(3))
+	if err != nil || string(got) != want {
+		t.Errorf("Marshal(objects, WithQuantization(3)) == %s, %v, want %s, <nil>", got, err, want)
+	}
+	topology, err := Unmarshal(got)
+	if err != nil {
+		t.Fatalf("Unmarshal(...) == _, %v, want _, <nil>", err)
+	}
+	g, err := topology.Geometry("example")
+	if err != nil {
+		t.Fatalf("topology.Geometry(%q) == _, %v, want _, <nil>", "example", err)
+	}
+	wantGeom := geom.NewGeometryCollection().MustPush(
+		geom.NewPointFlat(geom.XYZ, []float64{1, 0.5, 7}),
+		geom.NewLineStringFlat(geom.XY, []float64{0, 0, 1, 0.5, 2, 1}),
+	)
+	if !reflect.DeepEqual(g, wantGeom) {
+		t.Errorf("topology.Geometry(%q) == %#v, want %#v", "example", g, wantGeom)
+	}
+}
+
+func TestEncodeGeometryCollection(t *testing.T) {
+	objects := map[string]*geojson.FeatureCollection{
+		"example": {
+			Features: []*geojson.Feature{
+				{Geometry: geom.NewGeometryCollection().MustPush(
+					geom.NewPointFlat(geom.XY, []float64{3, 2}),
+					geom.NewGeometryCollection().MustPush(
+						geom.NewLineStringFlat(geom.XY, []float64{0, 0, 1, 1}),
+					),
+				)},
+			},
+		},
+	}
+	want := `{"type":"Topology","bbox":[0,0,3,2],"objects":{"example":{"type":"GeometryCollection","geometries":[` +
+		`{"type":"GeometryCollection","geometries":[` +
+		`{"type":"Point","coordinates":[3,2]},` +
+		`{"type":"GeometryCollection","geometries":[{"type":"LineString","arcs":[0]}]}]}]}},` +
+		`"arcs":[[[0,0],[1,1]]]}`
+	if got, err := Marshal(objects); err != nil || string(got) != want {
+		t.Errorf("Marshal(objects) == %s, %v, want %s, <nil>", got, err, want)
+	}
+}
+
+func TestEncodeLayoutMismatch(t *testing.T) {
+	objects := map[string]*geojson.FeatureCollection{
+		"example": {
+			Features: []*geojson.Feature{
+				{Geometry: geom.NewLineStringFlat(geom.XY, []float64{0, 0, 1, 1})},
+				{Geometry: geom.NewLineStringFlat(geom.XYZ, []float64{0, 0, 0, 1, 1, 1})},
+			},
+		},
+	}
+	wantErr := geom.ErrLayoutMismatch{Got: geom.XYZ, Want: geom.XY}
+	if _, err := Encode(objects); err != wantErr {
+		t.Errorf("Encode(objects) == _, %v, want _, %v", err, wantErr)
+	}
+}
@@ -0,0 +1,297 @@
+package twkb
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+
+	"github.com/twpayne/go-geom"
+)
+
+// A decoder holds the state of a single decode.
+type decoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *decoder) readByte() (byte, error) {
+	if d.pos >= len(d.data) {
+		return 0, io.ErrUnexpectedEOF
+	}
+	b := d.data[d.pos]
+	d.pos++
+	return b, nil
+}
+
+func (d *decoder) readUvarint() (uint64, error) {
+	x, n := binary.Uvarint(d.data[d.pos:])
+	switch {
+	case n == 0:
+		return 0, io.ErrUnexpectedEOF
+	case n < 0:
+		return 0, errOverflow
+	}
+	d.pos += n
+	return x, nil
+}
+
+func (d *decoder) readVarint() (int64, error) {
+	x, n := binary.Varint(d.data[d.pos:])
+	switch {
+	case n == 0:
+		return 0, io.ErrUnexpectedEOF
+	case n < 0:
+		return 0, errOverflow
+	}
+	d.pos += n
+	return x, nil
+}
+
+// readCount reads a count of elements, each of which occupies at least
+// minSize bytes.
+func (d *decoder) readCount(minSize int) (int, error) {
+	n, err := d.readUvarint()
+	if err != nil {
+		return 0, err
+	}
+	if n > uint64(len(d.data)-d.pos)/uint64(minSize) {
+		return 0, ErrCountTooLarge(n)
+	}
+	return int(n), nil
+}
+
+// decode decodes a geometry and the ids of its parts.
+func (d *decoder) decode() (geom.T, []int64, error) {
+	header, err := d.readByte()
+	if err != nil {
+		return nil, nil, err
+	}
+	typeID := header & 0xf
+	precision := unzigzag(uint64(header >> 4))
+	metadata, err := d.readByte()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	layout := geom.XY
+	var zPrecision, mPrecision int
+	if metadata&extendedPrecisionFlag != 0 {
+		extended, err := d.readByte()
+		if err != nil {
+			return nil, nil, err
+		}
+		switch extended & 3 {
+		case 1:
+			layout = geom.XYZ
+		case 2:
+			layout = geom.XYM
+		case 3:
+			layout = geom.XYZM
+		}
+		zPrecision = int(extended>>2) & 7
+		mPrecision = int(extended >> 5)
+	}
+	stride := layout.Stride()
+
+	if metadata&sizeFlag != 0 {
+		if _, err := d.readCount(1); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	empty := metadata&emptyFlag != 0
+	if empty {
+		switch typeID {
+		case pointID:
+			return geom.NewPointEmpty(layout), nil, nil
+		case lineStringID:
+			return geom.NewLineString(layout), nil, nil
+		case polygonID:
+			return geom.NewPolygon(layout), nil, nil
+		case multiPointID:
+			return geom.NewMultiPoint(layout), nil, nil
+		case multiLineStringID:
+			return geom.NewMultiLineString(layout), nil, nil
+		case multiPolygonID:
+			return geom.NewMultiPolygon(layout), nil, nil
+		case geometryCollectionID:
+			return geom.NewGeometryCollection(), nil, nil
+		default:
+			return nil, nil, ErrUnknownType(typeID)
+		}
+	}
+
+	if metadata&bboxFlag != 0 {
+		for i := 0; i < 2*stride; i++ {
+			if _, err := d.readVarint(); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	precisions := make([]int, stride)
+	precisions[0] = int(precision)
+	precisions[1] = int(precision)
+	if i := layout.ZIndex(); i != -1 {
+		precisions[i] = zPrecision
+	}
+	if i := layout.MIndex(); i != -1 {
+		precisions[i] = mPrecision
+	}
+	prev := make([]int64, stride)
+	readCoords := func(flatCoords []float64, n int) ([]float64, error) {
+		for i := 0; i < n*stride; i++ {
+			delta, err := d.readVarint()
+			if err != nil {
+				return nil, err
+			}
+			prev[i%stride] += delta
+			flatCoords = append(flatCoords, unscale(prev[i%stride], precisions[i%stride]))
+		}
+		return flatCoords, nil
+	}
+	readRings := func(flatCoords []float64, ends []int) ([]float64, []int, error) {
+		numRings, err := d.readCount(1)
+		if err != nil {
+			return nil, nil, err
+		}
+		for i := 0; i < numRings; i++ {
+			n, err := d.readCount(stride)
+			if err != nil {
+				return nil, nil, err
+			}
+			if flatCoords, err = readCoords(flatCoords, n); err != nil {
+				return nil, nil, err
+			}
+			ends = append(ends, len(flatCoords))
+		}
+		return flatCoords, ends, nil
+	}
+	readIDs := func(n int) ([]int64, error) {
+		if metadata&idListFlag == 0 {
+			return nil, nil
+		}
+		ids := make([]int64, n)
+		for i := range ids {
+			var err error
+			if ids[i], err = d.readVarint(); err != nil {
+				return nil, err
+			}
+		}
+		return ids, nil
+	}
+
+	switch typeID {
+	case pointID:
+		flatCoords, err := readCoords(nil, 1)
+		if err != nil {
+			return nil, nil, err
+		}
+		return geom.NewPointFlat(layout, flatCoords), nil, nil
+	case lineStringID:
+		n, err := d.readCount(stride)
+		if err != nil {
+			return nil, nil, err
+		}
+		flatCoords, err := readCoords(nil, n)
+		if err != nil {
+			return nil, nil, err
+		}
+		return geom.NewLineStringFlat(layout, flatCoords), nil, nil
+	case polygonID:
+		flatCoords, ends, err := readRings(nil, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		return geom.NewPolygonFlat(layout, flatCoords, ends), nil, nil
+	case multiPointID:
+		n, err := d.readCount(stride)
+		if err != nil {
+			return nil, nil, err
+		}
+		ids, err := readIDs(n)
+		if err != nil {
+			return nil, nil, err
+		}
+		flatCoords, err := readCoords(nil, n)
+		if err != nil {
+			return nil, nil, err
+		}
+		return geom.NewMultiPointFlat(layout, flatCoords), ids, nil
+	case multiLineStringID:
+		n, err := d.readCount(1)
+		if err != nil {
+			return nil, nil, err
+		}
+		ids, err := readIDs(n)
+		if err != nil {
+			return nil, nil, err
+		}
+		var flatCoords []float64
+		var ends []int
+		for i := 0; i < n; i++ {
+			m, err := d.readCount(stride)
+			if err != nil {
+				return nil, nil, err
+			}
+			if flatCoords, err = readCoords(flatCoords, m); err != nil {
+				return nil, nil, err
+			}
+			ends = append(ends, len(flatCoords))
+		}
+		return geom.NewMultiLineStringFlat(layout, flatCoords, ends), ids, nil
+	case multiPolygonID:
+		n, err := d.readCount(1)
+		if err != nil {
+			return nil, nil, err
+		}
+		ids, err := readIDs(n)
+		if err != nil {
+			return nil, nil, err
+		}
+		var flatCoords []float64
+		endss := make([][]int, n)
+		for i := 0; i < n; i++ {
+			if flatCoords, endss[i], err = readRings(flatCoords, nil); err != nil {
+				return nil, nil, err
+			}
+		}
+		return geom.NewMultiPolygonFlat(layout, flatCoords, endss), ids, nil
+	case geometryCollectionID:
+		n, err := d.readCount(2)
+		if err != nil {
+			return nil, nil, err
+		}
+		ids, err := readIDs(n)
+		if err != nil {
+			return nil, nil, err
+		}
+		gc := geom.NewGeometryCollection()
+		for i := 0; i < n; i++ {
+			g, _, err := d.decode()
+			if err != nil {
+				return nil, nil, err
+			}
+			if err := gc.Push(g); err != nil {
+				return nil, nil, err
+			}
+		}
+		return gc, ids, nil
+	default:
+		return nil, nil, ErrUnknownType(typeID)
+	}
+}
+
+// unscale returns value divided by 10^precision. Dividing by, rather than
+// multiplying by the reciprocal of, a power of ten returns the closest float64
+// to the decimal value.
+func unscale(value int64, precision int) float64 {
+	if precision >= 0 {
+		return float64(value) / math.Pow10(precision)
+	}
+	return float64(value) * math.Pow10(-precision)
+}
+
+func unzigzag(n uint64) int64 {
+	return int64(n>>1) ^ -int64(n&1)
+}
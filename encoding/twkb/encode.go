@@ -0,0 +1,244 @@
+package twkb
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/twpayne/go-geom"
+)
+
+// An encoder holds the options of a single encode.
+type encoder struct {
+	options options
+}
+
+// encode appends the TWKB of g, with ids for its parts if ids is not nil, to
+// buf.
+func (e *encoder) encode(buf []byte, g geom.T, ids []int64) ([]byte, error) {
+	var typeID byte
+	var numParts int
+	switch g := g.(type) {
+	case *geom.Point:
+		typeID = pointID
+	case *geom.LineString:
+		typeID = lineStringID
+	case *geom.Polygon:
+		typeID = polygonID
+	case *geom.MultiPoint:
+		typeID, numParts = multiPointID, g.NumPoints()
+	case *geom.MultiLineString:
+		typeID, numParts = multiLineStringID, g.NumLineStrings()
+	case *geom.MultiPolygon:
+		typeID, numParts = multiPolygonID, g.NumPolygons()
+	case *geom.GeometryCollection:
+		typeID, numParts = geometryCollectionID, g.NumGeoms()
+	default:
+		return nil, geom.ErrUnsupportedType{Value: g}
+	}
+	if ids != nil && typeID < multiPointID {
+		ids = nil
+	}
+	if ids != nil && len(ids) != numParts {
+		return nil, ErrIDsMismatch{Got: len(ids), Want: numParts}
+	}
+
+	layout := g.Layout()
+	var empty bool
+	switch g := g.(type) {
+	case *geom.Point:
+		empty = g.Empty()
+	case *geom.GeometryCollection:
+		empty = g.Empty()
+		if empty {
+			layout = geom.XY
+		}
+	default:
+		empty = len(g.FlatCoords()) == 0
+	}
+	var extended byte
+	switch layout {
+	case geom.XY:
+	case geom.XYZ:
+		extended = 1 | byte(e.options.zPrecision)<<2
+	case geom.XYM:
+		extended = 2 | byte(e.options.mPrecision)<<5
+	case geom.XYZM:
+		extended = 3 | byte(e.options.zPrecision)<<2 | byte(e.options.mPrecision)<<5
+	default:
+		return nil, geom.ErrUnsupportedLayout(layout)
+	}
+
+	buf = append(buf, typeID|byte(zigzag(int64(e.options.precision)))<<4)
+	var metadata byte
+	if e.options.bbox && !empty && typeID != pointID {
+		metadata |= bboxFlag
+	}
+	if e.options.size {
+		metadata |= sizeFlag
+	}
+	if ids != nil && !empty {
+		metadata |= idListFlag
+	}
+	if extended != 0 {
+		metadata |= extendedPrecisionFlag
+	}
+	if empty {
+		metadata |= emptyFlag
+	}
+	buf = append(buf, metadata)
+	if extended != 0 {
+		buf = append(buf, extended)
+	}
+
+	var body []byte
+	if !empty {
+		var err error
+		if body, err = e.encodeBody(g, layout, metadata, ids); err != nil {
+			return nil, err
+		}
+	}
+	if e.options.size {
+		buf = appendUvarint(buf, uint64(len(body)))
+	}
+	return append(buf, body...), nil
+}
+
+// encodeBody returns the TWKB of the optional bounding box, optional id list,
+// and coordinates of the non-empty geometry g.
+func (e *encoder) encodeBody(g geom.T, layout geom.Layout, metadata byte, ids []int64) ([]byte, error) {
+	var body []byte
+	if gc, ok := g.(*geom.GeometryCollection); ok {
+		body = appendUvarint(body, uint64(gc.NumGeoms()))
+		body = appendIDs(body, ids)
+		for _, g := range gc.Geoms() {
+			var err error
+			if body, err = e.encode(body, g, nil); err != nil {
+				return nil, err
+			}
+		}
+		return body, nil
+	}
+
+	stride := layout.Stride()
+	values, err := e.quantize(g.FlatCoords(), layout)
+	if err != nil {
+		return nil, err
+	}
+	if metadata&bboxFlag != 0 {
+		for i := 0; i < stride; i++ {
+			min, max := values[i], values[i]
+			for j := i; j < len(values); j += stride {
+				if values[j] < min {
+					min = values[j]
+				}
+				if values[j] > max {
+					max = values[j]
+				}
+			}
+			body = appendVarint(body, min)
+			body = appendVarint(body, max-min)
+		}
+	}
+
+	prev := make([]int64, stride)
+	appendCoords := func(body []byte, values []int64, withCount bool) []byte {
+		if withCount {
+			body = appendUvarint(body, uint64(len(values)/stride))
+		}
+		for i, value := range values {
+			body = appendVarint(body, value-prev[i%stride])
+			prev[i%stride] = value
+		}
+		return body
+	}
+	appendRings := func(body []byte, offset int, ends []int) []byte {
+		body = appendUvarint(body, uint64(len(ends)))
+		for _, end := range ends {
+			body = appendCoords(body, values[offset:end], true)
+			offset = end
+		}
+		return body
+	}
+
+	switch g := g.(type) {
+	case *geom.Point:
+		body = appendCoords(body, values, false)
+	case *geom.LineString:
+		body = appendCoords(body, values, true)
+	case *geom.Polygon:
+		body = appendRings(body, 0, g.Ends())
+	case *geom.MultiPoint:
+		body = appendUvarint(body, uint64(len(values)/stride))
+		body = appendIDs(body, ids)
+		body = appendCoords(body, values, false)
+	case *geom.MultiLineString:
+		body = appendUvarint(body, uint64(len(g.Ends())))
+		body = appendIDs(body, ids)
+		offset := 0
+		for _, end := range g.Ends() {
+			body = appendCoords(body, values[offset:end], true)
+			offset = end
+		}
+	case *geom.MultiPolygon:
+		body = appendUvarint(body, uint64(len(g.Endss())))
+		body = appendIDs(body, ids)
+		offset := 0
+		for _, ends := range g.Endss() {
+			body = appendRings(body, offset, ends)
+			if len(ends) > 0 {
+				offset = ends[len(ends)-1]
+			}
+		}
+	}
+	return body, nil
+}
+
+// quantize returns flatCoords scaled by the precision of each dimension and
+// rounded to integers.
+func (e *encoder) quantize(flatCoords []float64, layout geom.Layout) ([]int64, error) {
+	stride := layout.Stride()
+	precisions := make([]int, stride)
+	precisions[0] = e.options.precision
+	precisions[1] = e.options.precision
+	if i := layout.ZIndex(); i != -1 {
+		precisions[i] = e.options.zPrecision
+	}
+	if i := layout.MIndex(); i != -1 {
+		precisions[i] = e.options.mPrecision
+	}
+	values := make([]int64, len(flatCoords))
+	for i, x := range flatCoords {
+		var scaled float64
+		if precision := precisions[i%stride]; precision >= 0 {
+			scaled = math.Round(x * math.Pow10(precision))
+		} else {
+			scaled = math.Round(x / math.Pow10(-precision))
+		}
+		if math.IsNaN(scaled) || scaled < math.MinInt64 || scaled >= math.MaxInt64 {
+			return nil, errOverflow
+		}
+		values[i] = int64(scaled)
+	}
+	return values, nil
+}
+
+func appendIDs(buf []byte, ids []int64) []byte {
+	for _, id := range ids {
+		buf = appendVarint(buf, id)
+	}
+	return buf
+}
+
+func appendUvarint(buf []byte, x uint64) []byte {
+	var b [binary.MaxVarintLen64]byte
+	return append(buf, b[:binary.PutUvarint(b[:], x)]...)
+}
+
+func appendVarint(buf []byte, x int64) []byte {
+	var b [binary.MaxVarintLen64]byte
+	return append(buf, b[:binary.PutVarint(b[:], x)]...)
+}
+
+func zigzag(n int64) uint64 {
+	return uint64(n<<1) ^ uint64(n>>63)
+}
@@ -0,0 +1,180 @@
+// Package twkb implements Tiny Well Known Binary encoding and decoding.
+//
+// TWKB is a compact binary format in which coordinates are rounded to a
+// fixed number of decimal places and stored as variable-length deltas. See
+// https://github.com/TWKB/Specification.
+package twkb
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/twpayne/go-geom"
+)
+
+// Geometry type IDs.
+const (
+	pointID              = 1
+	lineStringID         = 2
+	polygonID            = 3
+	multiPointID         = 4
+	multiLineStringID    = 5
+	multiPolygonID       = 6
+	geometryCollectionID = 7
+)
+
+// Metadata flags.
+const (
+	bboxFlag              = 1 << 0
+	sizeFlag              = 1 << 1
+	idListFlag            = 1 << 2
+	extendedPrecisionFlag = 1 << 3
+	emptyFlag             = 1 << 4
+)
+
+var errOverflow = errors.New("twkb: overflow")
+
+// An ErrUnknownType is returned when an unknown type is encountered.
+type ErrUnknownType byte
+
+func (e ErrUnknownType) Error() string {
+	return fmt.Sprintf("twkb: unknown type: %d", byte(e))
+}
+
+// An ErrInvalidPrecision is returned when a precision is out of range.
+type ErrInvalidPrecision int
+
+func (e ErrInvalidPrecision) Error() string {
+	return fmt.Sprintf("twkb: invalid precision: %d", int(e))
+}
+
+// An ErrCountTooLarge is returned when a count in the input exceeds the number
+// of elements that the remaining input could possibly contain.
+type ErrCountTooLarge uint64
+
+func (e ErrCountTooLarge) Error() string {
+	return fmt.Sprintf("twkb: count too large: %d", uint64(e))
+}
+
+// An ErrIDsMismatch is returned when the number of ids does not match the
+// number of parts of the geometry being encoded.
+type ErrIDsMismatch struct {
+	Got  int
+	Want int
+}
+
+func (e ErrIDsMismatch) Error() string {
+	return fmt.Sprintf("twkb: got %d ids, want %d", e.Got, e.Want)
+}
+
+// An Option configures encoding.
+type Option func(*options)
+
+type options struct {
+	precision  int
+	zPrecision int
+	mPrecision int
+	bbox       bool
+	size       bool
+	ids        []int64
+}
+
+// WithPrecision sets the number of decimal places to which X and Y ordinates
+// are rounded, which must be between -8 and 7. Negative values round to tens,
+// hundreds, and so on. The default is zero.
+func WithPrecision(precision int) Option {
+	return func(o *options) {
+		o.precision = precision
+	}
+}
+
+// WithZPrecision sets the number of decimal places to which Z ordinates are
+// rounded, which must be between 0 and 7. The default is zero.
+func WithZPrecision(precision int) Option {
+	return func(o *options) {
+		o.zPrecision = precision
+	}
+}
+
+// WithMPrecision sets the number of decimal places to which M ordinates are
+// rounded, which must be between 0 and 7. The default is zero.
+func WithMPrecision(precision int) Option {
+	return func(o *options) {
+		o.mPrecision = precision
+	}
+}
+
+// WithBBox includes bounding boxes in the output.
+func WithBBox() Option {
+	return func(o *options) {
+		o.bbox = true
+	}
+}
+
+// WithSize includes sizes in the output, which allows decoders to skip
+// geometries without decoding them.
+func WithSize() Option {
+	return func(o *options) {
+		o.size = true
+	}
+}
+
+// WithIDs includes ids for the parts of a MultiPoint, MultiLineString,
+// MultiPolygon, or GeometryCollection.
+func WithIDs(ids []int64) Option {
+	return func(o *options) {
+		o.ids = ids
+	}
+}
+
+// Marshal translates a geometry to the corresponding TWKB.
+func Marshal(g geom.T, opts ...Option) ([]byte, error) {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.precision < -8 || o.precision > 7 {
+		return nil, ErrInvalidPrecision(o.precision)
+	}
+	if o.zPrecision < 0 || o.zPrecision > 7 {
+		return nil, ErrInvalidPrecision(o.zPrecision)
+	}
+	if o.mPrecision < 0 || o.mPrecision > 7 {
+		return nil, ErrInvalidPrecision(o.mPrecision)
+	}
+	e := &encoder{
+		options: o,
+	}
+	return e.encode(nil, g, o.ids)
+}
+
+// Unmarshal translates a TWKB to the corresponding geometry.
+func Unmarshal(data []byte) (geom.T, error) {
+	g, _, err := UnmarshalWithIDs(data)
+	return g, err
+}
+
+// UnmarshalWithIDs translates a TWKB to the corresponding geometry and the ids
+// of its parts, if present.
+func UnmarshalWithIDs(data []byte) (geom.T, []int64, error) {
+	d := &decoder{
+		data: data,
+	}
+	g, ids, err := d.decode()
+	if err != nil {
+		return nil, nil, err
+	}
+	if d.pos != len(d.data) {
+		return nil, nil, fmt.Errorf("twkb: %d trailing bytes", len(d.data)-d.pos)
+	}
+	return g, ids, nil
+}
+
+// UnmarshalStub returns the Stub of the geometry in data.
+func UnmarshalStub(data []byte) (*geom.Stub, error) {
+	g, err := Unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return geom.NewStub(g)
+}
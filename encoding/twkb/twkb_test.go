@@ -0,0 +1,183 @@
+package twkb
+
+import (
+	"encoding/hex"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+)
+
+func TestMarshalAndUnmarshal(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		g       geom.T
+		opts    []Option
+		ids     []int64
+		hex     string
+		decoded geom.T
+	}{
+		{
+			name: "point",
+			g:    geom.NewPointFlat(geom.XY, []float64{1, 2}),
+			hex:  "01000204",
+		},
+		{
+			name: "point_empty",
+			g:    geom.NewPointEmpty(geom.XY),
+			hex:  "0110",
+		},
+		{
+			name:    "point_precision",
+			g:       geom.NewPointFlat(geom.XY, []float64{1.2345, 2.3456}),
+			opts:    []Option{WithPrecision(2)},
+			hex:     "4100f601d603",
+			decoded: geom.NewPointFlat(geom.XY, []float64{1.23, 2.35}),
+		},
+		{
+			name:    "point_negative_precision",
+			g:       geom.NewPointFlat(geom.XY, []float64{1234, 5678}),
+			opts:    []Option{WithPrecision(-2)},
+			hex:     "31001872",
+			decoded: geom.NewPointFlat(geom.XY, []float64{1200, 5700}),
+		},
+		{
+			name: "point_size",
+			g:    geom.NewPointFlat(geom.XY, []float64{1, 2}),
+			opts: []Option{WithSize()},
+			hex:  "0102020204",
+		},
+		{
+			name: "point_z",
+			g:    geom.NewPointFlat(geom.XYZ, []float64{1, 2, 3.5}),
+			opts: []Option{WithZPrecision(1)},
+			hex:  "0108050204" + "46",
+		},
+		{
+			name: "point_zm",
+			g:    geom.NewPointFlat(geom.XYZM, []float64{1, 2, 3, 4}),
+			hex:  "010803" + "02040608",
+		},
+		{
+			name: "linestring",
+			g:    geom.NewLineStringFlat(geom.XY, []float64{1, 1, 5, 5}),
+			hex:  "02000202020808",
+		},
+		{
+			name: "linestring_bbox",
+			g:    geom.NewLineStringFlat(geom.XY, []float64{1, 1, 5, 5}),
+			opts: []Option{WithBBox()},
+			hex:  "0201" + "02080208" + "0202020808",
+		},
+		{
+			name: "linestring_empty",
+			g:    geom.NewLineString(geom.XYM),
+			opts: []Option{WithBBox(), WithSize()},
+			hex:  "021a0200",
+		},
+		{
+			name: "polygon",
+			g:    geom.NewPolygonFlat(geom.XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}, []int{8}),
+			hex:  "030001040000020000020101",
+		},
+		{
+			name: "multipoint_ids",
+			g:    geom.NewMultiPointFlat(geom.XY, []float64{0, 0, 1, 1}),
+			opts: []Option{WithIDs([]int64{5, 6})},
+			ids:  []int64{5, 6},
+			hex:  "040402" + "0a0c" + "00000202",
+		},
+		{
+			name: "multilinestring",
+			g:    geom.NewMultiLineStringFlat(geom.XY, []float64{0, 0, 1, 1, 2, 2, 3, 3}, []int{4, 8}),
+			hex:  "050002" + "0200000202" + "0202020202",
+		},
+		{
+			name: "multipolygon",
+			g: geom.NewMultiPolygonFlat(geom.XY, []float64{
+				0, 0, 1, 0, 1, 1, 0, 0,
+				2, 2, 3, 2, 3, 3, 2, 2,
+			}, [][]int{{8}, {16}}),
+			opts: []Option{WithBBox(), WithSize()},
+			hex:  "0603" + "19" + "00060006" + "02" + "0104" + "0000020000020101" + "0104" + "0404020000020101",
+		},
+		{
+			name: "geometrycollection",
+			g: geom.NewGeometryCollection().MustPush(
+				geom.NewPointFlat(geom.XY, []float64{1, 2}),
+				geom.NewLineStringFlat(geom.XY, []float64{1, 1, 5, 5}),
+			),
+			opts: []Option{WithIDs([]int64{-1, 1})},
+			ids:  []int64{-1, 1},
+			hex:  "070402" + "0102" + "01000204" + "02000202020808",
+		},
+		{
+			name: "geometrycollection_empty",
+			g:    geom.NewGeometryCollection(),
+			hex:  "0710",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := Marshal(tc.g, tc.opts...)
+			if err != nil || hex.EncodeToString(data) != tc.hex {
+				t.Errorf("Marshal(%v, ...) == %s, %v, want %s, <nil>", tc.g, hex.EncodeToString(data), err, tc.hex)
+			}
+			want := tc.decoded
+			if want == nil {
+				want = tc.g
+			}
+			data, err = hex.DecodeString(tc.hex)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, ids, err := UnmarshalWithIDs(data)
+			if err != nil || !reflect.DeepEqual(got, want) || !reflect.DeepEqual(ids, tc.ids) {
+				t.Errorf("UnmarshalWithIDs(%s) == %v, %v, %v, want %v, %v, <nil>", tc.hex, got, ids, err, want, tc.ids)
+			}
+		})
+	}
+}
+
+func TestMarshalErrors(t *testing.T) {
+	point := geom.NewPointFlat(geom.XY, []float64{1, 2})
+	if _, err := Marshal(point, WithPrecision(8)); err != ErrInvalidPrecision(8) {
+		t.Errorf("Marshal(point, WithPrecision(8)) == _, %v, want _, %v", err, ErrInvalidPrecision(8))
+	}
+	if _, err := Marshal(point, WithZPrecision(-1)); err != ErrInvalidPrecision(-1) {
+		t.Errorf("Marshal(point, WithZPrecision(-1)) == _, %v, want _, %v", err, ErrInvalidPrecision(-1))
+	}
+	multiPoint := geom.NewMultiPointFlat(geom.XY, []float64{1, 2})
+	if _, err := Marshal(multiPoint, WithIDs([]int64{1, 2})); !reflect.DeepEqual(err, ErrIDsMismatch{Got: 2, Want: 1}) {
+		t.Errorf("Marshal(multiPoint, WithIDs(...)) == _, %v, want _, %v", err, ErrIDsMismatch{Got: 2, Want: 1})
+	}
+	if _, err := Marshal(geom.NewPointFlat(geom.XY, []float64{1e300, 0}), WithPrecision(7)); err == nil {
+		t.Errorf("Marshal(huge point, ...) == _, <nil>, want _, !<nil>")
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	for _, tc := range []struct {
+		hex string
+		err error
+	}{
+		{hex: "", err: io.ErrUnexpectedEOF},
+		{hex: "01", err: io.ErrUnexpectedEOF},
+		{hex: "0100", err: io.ErrUnexpectedEOF},
+		{hex: "010002", err: io.ErrUnexpectedEOF},
+		{hex: "0900", err: ErrUnknownType(9)},
+		{hex: "0200ffffffff0f", err: ErrCountTooLarge(4294967295)},
+		{hex: "020080", err: io.ErrUnexpectedEOF},
+	} {
+		data, err := hex.DecodeString(tc.hex)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Unmarshal(data); err != tc.err {
+			t.Errorf("Unmarshal(%s) == _, %v, want _, %v", tc.hex, err, tc.err)
+		}
+	}
+	if _, err := Unmarshal([]byte{0x01, 0x00, 0x02, 0x04, 0x00}); err == nil {
+		t.Errorf("Unmarshal(trailing data) == _, <nil>, want _, !<nil>")
+	}
+}
@@ -0,0 +1,162 @@
+package wkb
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/wkbcommon"
+)
+
+// An Encoder writes geometries to an output stream, reusing an internal
+// buffer between geometries.
+type Encoder struct {
+	w       io.Writer
+	buf     []byte
+	options options
+}
+
+// NewEncoder returns a new Encoder that writes to w.
+func NewEncoder(w io.Writer, opts ...Option) *Encoder {
+	return &Encoder{
+		w:       w,
+		options: newOptions(opts),
+	}
+}
+
+// Encode writes the WKB encoding of g.
+func (e *Encoder) Encode(g geom.T) error {
+	buf, err := appendGeom(e.buf[:0], g, e.options.byteOrder, e.options.wkb25D)
+	if err != nil {
+		return err
+	}
+	e.buf = buf
+	_, err = e.w.Write(buf)
+	return err
+}
+
+// Append appends the WKB encoding of g to dst and returns the extended
+// buffer. Without options, it does not allocate if dst has sufficient
+// capacity, except for the segments of CompoundCurves and the rings of
+// CurvePolygons.
+func Append(dst []byte, g geom.T, opts ...Option) ([]byte, error) {
+	if len(opts) == 0 {
+		return appendGeom(dst, g, NDR, false)
+	}
+	o := newOptions(opts)
+	return appendGeom(dst, g, o.byteOrder, o.wkb25D)
+}
+
+func appendGeom(dst []byte, g geom.T, byteOrder binary.ByteOrder, wkb25D bool) ([]byte, error) {
+	wkbGeometryType, err := geometryType(g, wkb25D)
+	if err != nil {
+		return nil, err
+	}
+	dst, err = appendHeader(dst, byteOrder, wkbGeometryType)
+	if err != nil {
+		return nil, err
+	}
+	if gc, ok := g.(*geom.GeometryCollection); ok {
+		dst = wkbcommon.AppendUInt32(dst, byteOrder, uint32(gc.NumGeoms()))
+		for _, member := range gc.Geoms() {
+			if dst, err = appendGeom(dst, member, byteOrder, wkb25D); err != nil {
+				return nil, err
+			}
+		}
+		return dst, nil
+	}
+	flatCoords, stride := g.FlatCoords(), g.Stride()
+	switch g := g.(type) {
+	case *geom.Point:
+		return wkbcommon.AppendFloatArray(dst, byteOrder, flatCoords), nil
+	case *geom.LineString:
+		return wkbcommon.AppendFlatCoords1(dst, byteOrder, flatCoords, stride), nil
+	case *geom.Polygon:
+		return wkbcommon.AppendFlatCoords2(dst, byteOrder, flatCoords, 0, g.Ends(), stride), nil
+	case *geom.MultiPoint:
+		pointType := wkbGeometryType - wkbcommon.MultiPointID + wkbcommon.PointID
+		dst = wkbcommon.AppendUInt32(dst, byteOrder, uint32(len(flatCoords)/stride))
+		for i := 0; i < len(flatCoords); i += stride {
+			if dst, err = appendHeader(dst, byteOrder, pointType); err != nil {
+				return nil, err
+			}
+			dst = wkbcommon.AppendFloatArray(dst, byteOrder, flatCoords[i:i+stride])
+		}
+		return dst, nil
+	case *geom.MultiLineString:
+		lineStringType := wkbGeometryType - wkbcommon.MultiLineStringID + wkbcommon.LineStringID
+		dst = wkbcommon.AppendUInt32(dst, byteOrder, uint32(len(g.Ends())))
+		offset := 0
+		for _, end := range g.Ends() {
+			if dst, err = appendHeader(dst, byteOrder, lineStringType); err != nil {
+				return nil, err
+			}
+			dst = wkbcommon.AppendFlatCoords1(dst, byteOrder, flatCoords[offset:end], stride)
+			offset = end
+		}
+		return dst, nil
+	case *geom.MultiPolygon:
+		polygonType := wkbGeometryType - wkbcommon.MultiPolygonID + wkbcommon.PolygonID
+		return appendPolygons(dst, byteOrder, polygonType, flatCoords, g.Endss(), stride)
+	case *geom.CircularString:
+		return wkbcommon.AppendFlatCoords1(dst, byteOrder, flatCoords, stride), nil
+	case *geom.CompoundCurve:
+		n := g.NumSegments()
+		dst = wkbcommon.AppendUInt32(dst, byteOrder, uint32(n))
+		for i := 0; i < n; i++ {
+			if dst, err = appendGeom(dst, g.Segment(i), byteOrder, wkb25D); err != nil {
+				return nil, err
+			}
+		}
+		return dst, nil
+	case *geom.CurvePolygon:
+		n := g.NumRings()
+		dst = wkbcommon.AppendUInt32(dst, byteOrder, uint32(n))
+		for i := 0; i < n; i++ {
+			if dst, err = appendGeom(dst, g.Ring(i), byteOrder, wkb25D); err != nil {
+				return nil, err
+			}
+		}
+		return dst, nil
+	case *geom.PolyhedralSurface:
+		polygonType := wkbGeometryType - wkbcommon.PolyhedralSurfaceID + wkbcommon.PolygonID
+		return appendPolygons(dst, byteOrder, polygonType, flatCoords, g.Endss(), stride)
+	case *geom.TIN:
+		triangleType := wkbGeometryType - wkbcommon.TINID + wkbcommon.TriangleID
+		return appendPolygons(dst, byteOrder, triangleType, flatCoords, g.Endss(), stride)
+	case *geom.Triangle:
+		return wkbcommon.AppendFlatCoords2(dst, byteOrder, flatCoords, 0, g.Ends(), stride), nil
+	default:
+		return nil, geom.ErrUnsupportedType{Value: g}
+	}
+}
+
+// appendPolygons appends the number of polygons with endss and the polygons,
+// each with a header of polygonType, to dst.
+func appendPolygons(dst []byte, byteOrder binary.ByteOrder, polygonType uint32, flatCoords []float64, endss [][]int, stride int) ([]byte, error) {
+	dst = wkbcommon.AppendUInt32(dst, byteOrder, uint32(len(endss)))
+	offset := 0
+	for _, ends := range endss {
+		var err error
+		if dst, err = appendHeader(dst, byteOrder, polygonType); err != nil {
+			return nil, err
+		}
+		dst = wkbcommon.AppendFlatCoords2(dst, byteOrder, flatCoords, offset, ends, stride)
+		if len(ends) > 0 {
+			offset = ends[len(ends)-1]
+		}
+	}
+	return dst, nil
+}
+
+func appendHeader(dst []byte, byteOrder binary.ByteOrder, wkbGeometryType uint32) ([]byte, error) {
+	switch byteOrder {
+	case XDR:
+		dst = append(dst, wkbcommon.XDRID)
+	case NDR:
+		dst = append(dst, wkbcommon.NDRID)
+	default:
+		return nil, wkbcommon.ErrUnsupportedByteOrder{}
+	}
+	return wkbcommon.AppendUInt32(dst, byteOrder, wkbGeometryType), nil
+}
@@ -0,0 +1,253 @@
+package wkb
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/wkbcommon"
+)
+
+// A Lazy is a geometry backed by its raw WKB encoding. Its type and layout
+// are read from the header when it is created, its bounds are computed from
+// the encoded coordinates on demand, and the geometry itself is only decoded
+// when Geom is called. This makes filtering large numbers of geometries by
+// type or bounds cheap.
+type Lazy struct {
+	data   []byte
+	t      wkbcommon.Type
+	srid   int
+	opts   []Option
+	wkb25D bool
+	bounds *geom.Bounds
+	g      geom.T
+}
+
+// NewLazy returns a new Lazy backed by data, which is not copied. Only the
+// header is checked. opts are used when decoding the geometry.
+func NewLazy(data []byte, opts ...Option) (*Lazy, error) {
+	o := newOptions(opts)
+	_, t, _, err := readHeader(data, 0, o.wkb25D)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := typeNames[t%1000]; !ok {
+		return nil, wkbcommon.ErrUnsupportedType(t)
+	}
+	srid := 0
+	if o.sridFunc != nil {
+		if srid, err = o.sridFunc(0); err != nil {
+			return nil, err
+		}
+	}
+	return &Lazy{
+		data:   data,
+		t:      t,
+		srid:   srid,
+		opts:   opts,
+		wkb25D: o.wkb25D,
+	}, nil
+}
+
+// Bytes returns the WKB encoding of l.
+func (l *Lazy) Bytes() []byte {
+	return l.data
+}
+
+// Type returns the WKB geometry type of l without its layout, for example
+// wkbcommon.PolygonID.
+func (l *Lazy) Type() uint32 {
+	return uint32(l.t % 1000)
+}
+
+// Layout returns l's layout.
+func (l *Lazy) Layout() geom.Layout {
+	return typeLayout(l.t)
+}
+
+// SRID returns l's SRID. WKB does not encode SRIDs, so it is zero unless set
+// with SetSRID or assigned by WithSRIDFunc.
+func (l *Lazy) SRID() int {
+	return l.srid
+}
+
+// SetSRID sets the SRID of l and of the geometry returned by Geom.
+func (l *Lazy) SetSRID(srid int) *Lazy {
+	l.srid = srid
+	if l.g != nil {
+		setSRID(l.g, srid)
+	}
+	return l
+}
+
+// Bounds returns the bounds of l, computed from the encoded coordinates
+// without decoding the geometry. WKB empty points, which are encoded with NaN
+// ordinates, are ignored. Geometries that contain CircularStrings are
+// decoded, as arcs extend beyond their control points.
+func (l *Lazy) Bounds() (*geom.Bounds, error) {
+	if l.bounds != nil {
+		return l.bounds.Clone(), nil
+	}
+	var min, max [4]float64
+	for i := range min {
+		min[i], max[i] = math.Inf(1), math.Inf(-1)
+	}
+	hasZ, hasM, hasArcs := false, false, false
+	extend := func(dim int, f float64) {
+		min[dim] = math.Min(min[dim], f)
+		max[dim] = math.Max(max[dim], f)
+	}
+	if _, err := walkGeom(l.data, 0, l.wkb25D, func(byteOrder binary.ByteOrder, layout geom.Layout, coords []byte, circular bool) {
+		hasArcs = hasArcs || circular
+		stride := layout.Stride()
+		zIndex, mIndex := layout.ZIndex(), layout.MIndex()
+		hasZ = hasZ || zIndex != -1
+		hasM = hasM || mIndex != -1
+		for i := 0; i < len(coords); i += 8 * stride {
+			x := math.Float64frombits(byteOrder.Uint64(coords[i:]))
+			y := math.Float64frombits(byteOrder.Uint64(coords[i+8:]))
+			if math.IsNaN(x) && math.IsNaN(y) {
+				continue
+			}
+			extend(0, x)
+			extend(1, y)
+			if zIndex != -1 {
+				extend(2, math.Float64frombits(byteOrder.Uint64(coords[i+8*zIndex:])))
+			}
+			if mIndex != -1 {
+				extend(3, math.Float64frombits(byteOrder.Uint64(coords[i+8*mIndex:])))
+			}
+		}
+	}); err != nil {
+		return nil, err
+	}
+	if hasArcs {
+		g, err := l.Geom()
+		if err != nil {
+			return nil, err
+		}
+		s, err := geom.NewStub(g)
+		if err != nil {
+			return nil, err
+		}
+		l.bounds = s.Bounds
+		return l.bounds.Clone(), nil
+	}
+	switch {
+	case hasZ && hasM:
+		l.bounds = geom.NewBounds(geom.XYZM).Set(min[0], min[1], min[2], min[3], max[0], max[1], max[2], max[3])
+	case hasZ:
+		l.bounds = geom.NewBounds(geom.XYZ).Set(min[0], min[1], min[2], max[0], max[1], max[2])
+	case hasM:
+		l.bounds = geom.NewBounds(geom.XYM).Set(min[0], min[1], min[3], max[0], max[1], max[3])
+	default:
+		l.bounds = geom.NewBounds(geom.XY).Set(min[0], min[1], max[0], max[1])
+	}
+	return l.bounds.Clone(), nil
+}
+
+// Geom decodes and returns the geometry. The result is cached, so the data
+// are decoded at most once.
+func (l *Lazy) Geom() (geom.T, error) {
+	if l.g != nil {
+		return l.g, nil
+	}
+	g, err := Unmarshal(l.data, l.opts...)
+	if err != nil {
+		return nil, err
+	}
+	setSRID(g, l.srid)
+	l.g = g
+	return g, nil
+}
+
+// typeNames are the names of WKB geometry types, as used by geom.Stub.
+var typeNames = map[wkbcommon.Type]string{
+	wkbcommon.PointID:              "Point",
+	wkbcommon.LineStringID:         "LineString",
+	wkbcommon.PolygonID:            "Polygon",
+	wkbcommon.MultiPointID:         "MultiPoint",
+	wkbcommon.MultiLineStringID:    "MultiLineString",
+	wkbcommon.MultiPolygonID:       "MultiPolygon",
+	wkbcommon.GeometryCollectionID: "GeometryCollection",
+	wkbcommon.CircularStringID:     "CircularString",
+	wkbcommon.CompoundCurveID:      "CompoundCurve",
+	wkbcommon.CurvePolygonID:       "CurvePolygon",
+	wkbcommon.PolyhedralSurfaceID:  "PolyhedralSurface",
+	wkbcommon.TINID:                "TIN",
+	wkbcommon.TriangleID:           "Triangle",
+}
+
+// Stub returns the Stub of l, computed from the encoded coordinates without
+// decoding the geometry. WKB empty points are not counted as vertices.
+func (l *Lazy) Stub() (*geom.Stub, error) {
+	bounds, err := l.Bounds()
+	if err != nil {
+		return nil, err
+	}
+	numVertices := 0
+	if _, err := walkGeom(l.data, 0, l.wkb25D, func(byteOrder binary.ByteOrder, layout geom.Layout, coords []byte, _ bool) {
+		for i := 0; i < len(coords); i += 8 * layout.Stride() {
+			x := math.Float64frombits(byteOrder.Uint64(coords[i:]))
+			y := math.Float64frombits(byteOrder.Uint64(coords[i+8:]))
+			if !math.IsNaN(x) || !math.IsNaN(y) {
+				numVertices++
+			}
+		}
+	}); err != nil {
+		return nil, err
+	}
+	if numVertices == 0 {
+		bounds = geom.NewBounds(l.Layout())
+	}
+	return &geom.Stub{
+		Type:        typeNames[l.t%1000],
+		Layout:      l.Layout(),
+		SRID:        l.srid,
+		Bounds:      bounds,
+		NumVertices: numVertices,
+	}, nil
+}
+
+// UnmarshalStub returns the Stub of the geometry in data without decoding it.
+// Only the headers, counts, and coordinates are read, and no geometry is
+// allocated.
+func UnmarshalStub(data []byte, opts ...Option) (*geom.Stub, error) {
+	l, err := NewLazy(data, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return l.Stub()
+}
+
+// setSRID sets the SRID of g.
+func setSRID(g geom.T, srid int) {
+	switch g := g.(type) {
+	case *geom.Point:
+		g.SetSRID(srid)
+	case *geom.LineString:
+		g.SetSRID(srid)
+	case *geom.Polygon:
+		g.SetSRID(srid)
+	case *geom.MultiPoint:
+		g.SetSRID(srid)
+	case *geom.MultiLineString:
+		g.SetSRID(srid)
+	case *geom.MultiPolygon:
+		g.SetSRID(srid)
+	case *geom.GeometryCollection:
+		g.SetSRID(srid)
+	case *geom.CircularString:
+		g.SetSRID(srid)
+	case *geom.CompoundCurve:
+		g.SetSRID(srid)
+	case *geom.CurvePolygon:
+		g.SetSRID(srid)
+	case *geom.PolyhedralSurface:
+		g.SetSRID(srid)
+	case *geom.TIN:
+		g.SetSRID(srid)
+	case *geom.Triangle:
+		g.SetSRID(srid)
+	}
+}
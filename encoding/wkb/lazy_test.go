@@ -0,0 +1,171 @@
+package wkb
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/wkbcommon"
+	"github.com/twpayne/go-geom/internal/testdata"
+)
+
+func TestLazy(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		g     geom.T
+		t     uint32
+		empty bool
+	}{
+		{
+			name: "point",
+			g:    geom.NewPointFlat(geom.XYZ, []float64{1, 2, 3}),
+			t:    wkbcommon.PointID,
+		},
+		{
+			name: "line_string",
+			g:    geom.NewLineStringFlat(geom.XYM, []float64{1, 2, 3, -4, 5, 6, 7, -8, 9}),
+			t:    wkbcommon.LineStringID,
+		},
+		{
+			name: "polygon",
+			g:    geom.NewPolygonFlat(geom.XY, []float64{0, 0, 3, 0, 3, 3, 0, 0, 1, 1, 2, 1, 2, 2, 1, 1}, []int{8, 16}),
+			t:    wkbcommon.PolygonID,
+		},
+		{
+			name: "multi_polygon",
+			g: geom.NewMultiPolygonFlat(geom.XYZM, []float64{
+				0, 0, 0, 0, 1, 0, 1, 2, 1, 1, 2, 4, 0, 0, 0, 0,
+				-5, -5, -1, 8, -4, -5, -1, 8, -4, -4, -1, 8, -5, -5, -1, 8,
+			}, [][]int{{16}, {32}}),
+			t: wkbcommon.MultiPolygonID,
+		},
+		{
+			name:  "multi_line_string_empty",
+			g:     geom.NewMultiLineString(geom.XY),
+			t:     wkbcommon.MultiLineStringID,
+			empty: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := Append(nil, tc.g)
+			if err != nil {
+				t.Fatal(err)
+			}
+			l, err := NewLazy(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := l.Type(); got != tc.t {
+				t.Errorf("l.Type() == %d, want %d", got, tc.t)
+			}
+			if got, want := l.Layout(), tc.g.Layout(); got != want {
+				t.Errorf("l.Layout() == %v, want %v", got, want)
+			}
+			bounds, err := l.Bounds()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.empty {
+				if !bounds.IsEmpty() {
+					t.Errorf("l.Bounds() == %v, want empty", bounds)
+				}
+			} else if want := tc.g.Bounds(); !reflect.DeepEqual(bounds, want) {
+				t.Errorf("l.Bounds() == %v, want %v", bounds, want)
+			}
+			l.SetSRID(4326)
+			g, err := l.Geom()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if g.SRID() != 4326 {
+				t.Errorf("g.SRID() == %d, want 4326", g.SRID())
+			}
+			if g2, _ := l.Geom(); g2 != g {
+				t.Errorf("l.Geom() was decoded twice")
+			}
+		})
+	}
+}
+
+func TestLazyErrors(t *testing.T) {
+	if _, err := NewLazy([]byte{0x02, 0x01, 0x00, 0x00, 0x00}); !reflect.DeepEqual(err, wkbcommon.ErrUnknownByteOrder(2)) {
+		t.Errorf("NewLazy(...) == ..., %v, want %v", err, wkbcommon.ErrUnknownByteOrder(2))
+	}
+	l, err := NewLazy([]byte{0x01, 0x02, 0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Bounds(); err == nil {
+		t.Errorf("l.Bounds() == ..., <nil>, want !<nil>")
+	}
+}
+
+func TestUnmarshalStub(t *testing.T) {
+	for _, g := range []geom.T{
+		geom.NewPointFlat(geom.XYZ, []float64{1, 2, 3}),
+		geom.NewLineStringFlat(geom.XYM, []float64{1, 2, 3, -4, 5, 6, 7, -8, 9}),
+		geom.NewPolygonFlat(geom.XY, []float64{0, 0, 3, 0, 3, 3, 0, 0, 1, 1, 2, 1, 2, 2, 1, 1}, []int{8, 16}),
+		geom.NewMultiPolygonFlat(geom.XYZM, []float64{
+			0, 0, 0, 0, 1, 0, 1, 2, 1, 1, 2, 4, 0, 0, 0, 0,
+			-5, -5, -1, 8, -4, -5, -1, 8, -4, -4, -1, 8, -5, -5, -1, 8,
+		}, [][]int{{16}, {32}}),
+		geom.NewMultiLineString(geom.XY),
+		geom.NewGeometryCollection().MustPush(
+			geom.NewPointFlat(geom.XY, []float64{1, 2}),
+			geom.NewLineStringFlat(geom.XY, []float64{3, 4, 5, 6}),
+		),
+	} {
+		data, err := Append(nil, g)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := UnmarshalStub(data)
+		if err != nil {
+			t.Errorf("UnmarshalStub(%v) == %v, %v, want ..., nil", data, got, err)
+			continue
+		}
+		want, err := geom.NewStub(g)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("UnmarshalStub(%v) == %+v, nil, want %+v, nil", data, got, want)
+		}
+	}
+}
+
+func TestLazyCurvesAndSurfaces(t *testing.T) {
+	for _, layout := range []geom.Layout{geom.XY, geom.XYZ, geom.XYM, geom.XYZM} {
+		gs := testdata.CurvesAndSurfaces(layout)
+		gs = append(gs, geom.NewGeometryCollection().MustPush(gs...))
+		for _, g := range gs {
+			data, err := Append(nil, g)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := geom.NewStub(g)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, err := UnmarshalStub(data); err != nil || !reflect.DeepEqual(got, want) {
+				t.Errorf("UnmarshalStub(%v) == %+v, %v, want %+v, <nil>", g, got, err, want)
+			}
+			l, err := NewLazy(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, err := l.Bounds(); err != nil || !reflect.DeepEqual(got, want.Bounds) {
+				t.Errorf("l.Bounds() == %v, %v, want %v, <nil> for %v", got, err, want.Bounds, g)
+			}
+			if got, err := l.Stub(); err != nil || !reflect.DeepEqual(got, want) {
+				t.Errorf("l.Stub() == %+v, %v, want %+v, <nil> for %v", got, err, want, g)
+			}
+			if got, err := l.Geom(); err != nil || !reflect.DeepEqual(got, g) {
+				t.Errorf("l.Geom() == %v, %v, want %v, <nil>", got, err, g)
+			}
+			if got, err := l.SetSRID(4326).Geom(); err != nil || got.SRID() != 4326 {
+				t.Errorf("l.SetSRID(4326).Geom() == %v, %v, want a geometry with SRID 4326, <nil>", got, err)
+			}
+		}
+	}
+}
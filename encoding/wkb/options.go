@@ -0,0 +1,64 @@
+package wkb
+
+import (
+	"encoding/binary"
+
+	"github.com/twpayne/go-geom/encoding/wkbcommon"
+)
+
+// An Option configures encoding or decoding. Options that do not apply to an
+// operation are ignored.
+type Option func(*options)
+
+type options struct {
+	byteOrder binary.ByteOrder
+	limits    wkbcommon.Limits
+	sridFunc  func(int) (int, error)
+	wkb25D    bool
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		byteOrder: NDR,
+		limits:    wkbcommon.DefaultLimits,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithByteOrder sets the byte order used when encoding. The default is NDR.
+func WithByteOrder(byteOrder binary.ByteOrder) Option {
+	return func(o *options) {
+		o.byteOrder = byteOrder
+	}
+}
+
+// WithLimits sets the limits on the size of decoded geometries. The default
+// is wkbcommon.DefaultLimits.
+func WithLimits(limits wkbcommon.Limits) Option {
+	return func(o *options) {
+		o.limits = limits
+	}
+}
+
+// WithSRIDFunc sets a function that is called with the SRID of each decoded
+// geometry and returns the SRID to assign to it, or an error to reject it. It
+// allows SRIDs to be normalized or validated in one place. WKB does not encode
+// SRIDs, so f is always called with zero.
+func WithSRIDFunc(f func(srid int) (int, error)) Option {
+	return func(o *options) {
+		o.sridFunc = f
+	}
+}
+
+// WithWKB25D sets whether the pre-ISO 2.5D geometry types, which mark Z with
+// the 0x80000000 flag and M with the 0x40000000 flag instead of adding 1000,
+// 2000, or 3000 to the type, are accepted when decoding and used when
+// encoding. They are found in dumps from old versions of PostGIS.
+func WithWKB25D(wkb25D bool) Option {
+	return func(o *options) {
+		o.wkb25D = wkb25D
+	}
+}
@@ -0,0 +1,206 @@
+package wkb
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/wkbcommon"
+)
+
+// A Scanner provides random access to the members of a WKB MultiPoint,
+// MultiLineString, MultiPolygon, or GeometryCollection, the segments of a
+// CompoundCurve, the rings of a CurvePolygon, or the faces of a
+// PolyhedralSurface or TIN without decoding the whole geometry. Members are
+// located by reading only their headers and counts, and only the requested
+// member is decoded.
+type Scanner struct {
+	data      []byte
+	byteOrder binary.ByteOrder
+	n         int
+	offsets   []int // offsets[i] is the start of the ith member, if known
+	opts      []Option
+	wkb25D    bool
+}
+
+// NewScanner returns a new Scanner over the multi-geometry or geometry
+// collection encoded in data. opts are used when decoding members.
+func NewScanner(data []byte, opts ...Option) (*Scanner, error) {
+	o := newOptions(opts)
+	byteOrder, t, offset, err := readHeader(data, 0, o.wkb25D)
+	if err != nil {
+		return nil, err
+	}
+	if !isCollectionType(t) {
+		return nil, wkbcommon.ErrUnsupportedType(t)
+	}
+	if len(data) < offset+4 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	n := byteOrder.Uint32(data[offset:])
+	return &Scanner{
+		data:      data,
+		byteOrder: byteOrder,
+		n:         int(n),
+		offsets:   []int{offset + 4},
+		opts:      opts,
+		wkb25D:    o.wkb25D,
+	}, nil
+}
+
+// NumGeoms returns the number of members.
+func (s *Scanner) NumGeoms() int {
+	return s.n
+}
+
+// GeomBytes returns the WKB encoding of the ith member, without decoding it.
+// It panics if i is out of range.
+func (s *Scanner) GeomBytes(i int) ([]byte, error) {
+	if i < 0 || i >= s.n {
+		panic("wkb: index out of range")
+	}
+	for len(s.offsets) <= i+1 {
+		offset := s.offsets[len(s.offsets)-1]
+		end, err := walkGeom(s.data, offset, s.wkb25D, nil)
+		if err != nil {
+			return nil, err
+		}
+		s.offsets = append(s.offsets, end)
+	}
+	return s.data[s.offsets[i]:s.offsets[i+1]], nil
+}
+
+// Geom decodes and returns the ith member. It panics if i is out of range.
+func (s *Scanner) Geom(i int) (geom.T, error) {
+	data, err := s.GeomBytes(i)
+	if err != nil {
+		return nil, err
+	}
+	return Unmarshal(data, s.opts...)
+}
+
+// readHeader reads the byte order and ISO geometry type of the geometry at
+// offset in data and returns them with the offset of the geometry's body.
+func readHeader(data []byte, offset int, wkb25D bool) (binary.ByteOrder, wkbcommon.Type, int, error) {
+	if len(data) < offset+5 {
+		return nil, 0, 0, io.ErrUnexpectedEOF
+	}
+	var byteOrder binary.ByteOrder
+	switch data[offset] {
+	case wkbcommon.XDRID:
+		byteOrder = XDR
+	case wkbcommon.NDRID:
+		byteOrder = NDR
+	default:
+		return nil, 0, 0, wkbcommon.ErrUnknownByteOrder(data[offset])
+	}
+	t := isoType(wkbcommon.Type(byteOrder.Uint32(data[offset+1:])), wkb25D)
+	if t/1000 > 3 {
+		return nil, 0, 0, wkbcommon.ErrUnknownType(t)
+	}
+	return byteOrder, t, offset + 5, nil
+}
+
+// typeLayout returns the layout of the WKB geometry type t, which must have
+// been checked by readHeader.
+func typeLayout(t wkbcommon.Type) geom.Layout {
+	switch t / 1000 {
+	case 1:
+		return geom.XYZ
+	case 2:
+		return geom.XYM
+	case 3:
+		return geom.XYZM
+	default:
+		return geom.XY
+	}
+}
+
+// isCollectionType returns whether geometries of type t are encoded as a
+// count followed by the WKB encodings of their members.
+func isCollectionType(t wkbcommon.Type) bool {
+	switch t % 1000 {
+	case wkbcommon.MultiPointID, wkbcommon.MultiLineStringID, wkbcommon.MultiPolygonID, wkbcommon.GeometryCollectionID:
+		return true
+	case wkbcommon.CompoundCurveID, wkbcommon.CurvePolygonID, wkbcommon.PolyhedralSurfaceID, wkbcommon.TINID:
+		return true
+	default:
+		return false
+	}
+}
+
+// A coordsFunc is called with the encoded coordinates of a geometry.
+// circular is true if they are the control points of a CircularString.
+type coordsFunc func(byteOrder binary.ByteOrder, layout geom.Layout, coords []byte, circular bool)
+
+// walkGeom returns the offset of the end of the geometry at offset in data,
+// reading only headers and counts. If f is not nil then it is called with
+// each run of coordinates.
+func walkGeom(data []byte, offset int, wkb25D bool, f coordsFunc) (int, error) {
+	byteOrder, t, offset, err := readHeader(data, offset, wkb25D)
+	if err != nil {
+		return 0, err
+	}
+	layout := typeLayout(t)
+	coordSize := 8 * layout.Stride()
+	readCount := func() (int, error) {
+		if len(data) < offset+4 {
+			return 0, io.ErrUnexpectedEOF
+		}
+		n := int(byteOrder.Uint32(data[offset:]))
+		offset += 4
+		return n, nil
+	}
+	walkCoords := func(n int) error {
+		if n > (len(data)-offset)/coordSize {
+			return io.ErrUnexpectedEOF
+		}
+		if f != nil {
+			f(byteOrder, layout, data[offset:offset+n*coordSize], t%1000 == wkbcommon.CircularStringID)
+		}
+		offset += n * coordSize
+		return nil
+	}
+	switch t % 1000 {
+	case wkbcommon.PointID:
+		if err := walkCoords(1); err != nil {
+			return 0, err
+		}
+	case wkbcommon.LineStringID, wkbcommon.CircularStringID:
+		n, err := readCount()
+		if err != nil {
+			return 0, err
+		}
+		if err := walkCoords(n); err != nil {
+			return 0, err
+		}
+	case wkbcommon.PolygonID, wkbcommon.TriangleID:
+		n, err := readCount()
+		if err != nil {
+			return 0, err
+		}
+		for i := 0; i < n; i++ {
+			m, err := readCount()
+			if err != nil {
+				return 0, err
+			}
+			if err := walkCoords(m); err != nil {
+				return 0, err
+			}
+		}
+	default:
+		if !isCollectionType(t) {
+			return 0, wkbcommon.ErrUnsupportedType(t)
+		}
+		n, err := readCount()
+		if err != nil {
+			return 0, err
+		}
+		for i := 0; i < n; i++ {
+			if offset, err = walkGeom(data, offset, wkb25D, f); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return offset, nil
+}
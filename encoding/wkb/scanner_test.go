@@ -0,0 +1,160 @@
+package wkb
+
+import (
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/wkbcommon"
+	"github.com/twpayne/go-geom/internal/testdata"
+)
+
+func TestScanner(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		g       geom.T
+		members []geom.T
+	}{
+		{
+			name: "multi_point",
+			g:    geom.NewMultiPointFlat(geom.XYZ, []float64{1, 2, 3, 4, 5, 6}),
+			members: []geom.T{
+				geom.NewPointFlat(geom.XYZ, []float64{1, 2, 3}),
+				geom.NewPointFlat(geom.XYZ, []float64{4, 5, 6}),
+			},
+		},
+		{
+			name: "multi_polygon",
+			g: geom.NewMultiPolygonFlat(geom.XY, []float64{
+				0, 0, 1, 0, 1, 1, 0, 0,
+				2, 2, 3, 2, 3, 3, 2, 2, 2.1, 2.1, 2.2, 2.1, 2.2, 2.2, 2.1, 2.1,
+				4, 4, 5, 4, 5, 5, 4, 4,
+			}, [][]int{{8}, {16, 24}, {32}}),
+			members: []geom.T{
+				geom.NewPolygonFlat(geom.XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}, []int{8}),
+				geom.NewPolygonFlat(geom.XY, []float64{2, 2, 3, 2, 3, 3, 2, 2, 2.1, 2.1, 2.2, 2.1, 2.2, 2.2, 2.1, 2.1}, []int{8, 16}),
+				geom.NewPolygonFlat(geom.XY, []float64{4, 4, 5, 4, 5, 5, 4, 4}, []int{8}),
+			},
+		},
+		{
+			name: "geometry_collection",
+			g: geom.NewGeometryCollection().MustPush(
+				geom.NewGeometryCollection().MustPush(
+					geom.NewLineStringFlat(geom.XYM, []float64{1, 2, 3, 4, 5, 6}),
+				),
+				geom.NewPointFlat(geom.XYZM, []float64{1, 2, 3, 4}),
+			),
+			members: []geom.T{
+				geom.NewGeometryCollection().MustPush(
+					geom.NewLineStringFlat(geom.XYM, []float64{1, 2, 3, 4, 5, 6}),
+				),
+				geom.NewPointFlat(geom.XYZM, []float64{1, 2, 3, 4}),
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, byteOrder := range []Option{WithByteOrder(NDR), WithByteOrder(XDR)} {
+				data, err := Append(nil, tc.g, byteOrder)
+				if err != nil {
+					t.Fatal(err)
+				}
+				s, err := NewScanner(data)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := s.NumGeoms(); got != len(tc.members) {
+					t.Fatalf("s.NumGeoms() == %d, want %d", got, len(tc.members))
+				}
+				// Access the members in reverse order to exercise seeking.
+				for i := len(tc.members) - 1; i >= 0; i-- {
+					if got, err := s.Geom(i); err != nil || !reflect.DeepEqual(got, tc.members[i]) {
+						t.Errorf("s.Geom(%d) == %v, %v, want %v, <nil>", i, got, err, tc.members[i])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestScannerCurvesAndSurfaces(t *testing.T) {
+	for _, g := range testdata.CurvesAndSurfaces(geom.XYZ) {
+		var members []geom.T
+		switch g := g.(type) {
+		case *geom.CompoundCurve:
+			for i := 0; i < g.NumSegments(); i++ {
+				members = append(members, g.Segment(i))
+			}
+		case *geom.CurvePolygon:
+			for i := 0; i < g.NumRings(); i++ {
+				members = append(members, g.Ring(i))
+			}
+		case *geom.PolyhedralSurface:
+			for i := 0; i < g.NumPatches(); i++ {
+				members = append(members, g.Patch(i))
+			}
+		case *geom.TIN:
+			for i := 0; i < g.NumTriangles(); i++ {
+				members = append(members, g.Triangle(i))
+			}
+		default:
+			if _, err := NewScanner(mustAppend(t, g)); err == nil {
+				t.Errorf("NewScanner(%v) == _, <nil>, want _, !<nil>", g)
+			}
+			continue
+		}
+		s, err := NewScanner(mustAppend(t, g))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := s.NumGeoms(); got != len(members) {
+			t.Fatalf("s.NumGeoms() == %d, want %d", got, len(members))
+		}
+		for i := len(members) - 1; i >= 0; i-- {
+			if got, err := s.Geom(i); err != nil || !reflect.DeepEqual(got, members[i]) {
+				t.Errorf("s.Geom(%d) == %v, %v, want %v, <nil>", i, got, err, members[i])
+			}
+		}
+	}
+
+	// Members of GeometryCollections may be curves and surfaces.
+	members := testdata.CurvesAndSurfaces(geom.XYM)
+	s, err := NewScanner(mustAppend(t, geom.NewGeometryCollection().MustPush(members...)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := len(members) - 1; i >= 0; i-- {
+		if got, err := s.Geom(i); err != nil || !reflect.DeepEqual(got, members[i]) {
+			t.Errorf("s.Geom(%d) == %v, %v, want %v, <nil>", i, got, err, members[i])
+		}
+	}
+}
+
+func mustAppend(t *testing.T, g geom.T) []byte {
+	t.Helper()
+	data, err := Append(nil, g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestScannerErrors(t *testing.T) {
+	if _, err := NewScanner([]byte{0x01, 0x01, 0x00, 0x00, 0x00}); !reflect.DeepEqual(err, wkbcommon.ErrUnsupportedType(wkbcommon.PointID)) {
+		t.Errorf("NewScanner(point) == ..., %v, want %v", err, wkbcommon.ErrUnsupportedType(wkbcommon.PointID))
+	}
+	data, err := Append(nil, geom.NewMultiPointFlat(geom.XY, []float64{1, 2, 3, 4}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewScanner(data[:len(data)-1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Geom(0); err != nil {
+		t.Errorf("s.Geom(0) == ..., %v, want <nil>", err)
+	}
+	if _, err := s.Geom(1); err != io.ErrUnexpectedEOF {
+		t.Errorf("s.Geom(1) == ..., %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+}
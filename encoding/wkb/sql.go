@@ -60,9 +60,23 @@ type GeometryCollection struct {
 	*geom.GeometryCollection
 }
 
-// Scan scans from a []byte.
+// A Geometry is a WKB-encoded geometry of any type that implements the
+// sql.Scanner and driver.Valuer interfaces. It is useful for columns that may
+// contain geometries of different types.
+type Geometry struct {
+	geom.T
+}
+
+// Scan scans from a []byte or string containing binary or hex WKB.
 func (p *Point) Scan(src interface{}) error {
-	b, ok := src.([]byte)
+	if src == nil {
+		p.Point = nil
+		return nil
+	}
+	b, ok, err := wkbcommon.SQLBytes(src)
+	if err != nil {
+		return err
+	}
 	if !ok {
 		return ErrExpectedByteSlice{Value: src}
 	}
@@ -78,14 +92,29 @@ func (p *Point) Scan(src interface{}) error {
 	return nil
 }
 
+// Valid returns true if p has a value.
+func (p *Point) Valid() bool {
+	return p != nil && p.Point != nil
+}
+
 // Value returns the WKB encoding of p.
 func (p *Point) Value() (driver.Value, error) {
+	if p.Point == nil {
+		return nil, nil
+	}
 	return value(p.Point)
 }
 
-// Scan scans from a []byte.
+// Scan scans from a []byte or string containing binary or hex WKB.
 func (ls *LineString) Scan(src interface{}) error {
-	b, ok := src.([]byte)
+	if src == nil {
+		ls.LineString = nil
+		return nil
+	}
+	b, ok, err := wkbcommon.SQLBytes(src)
+	if err != nil {
+		return err
+	}
 	if !ok {
 		return ErrExpectedByteSlice{Value: src}
 	}
@@ -101,14 +130,29 @@ func (ls *LineString) Scan(src interface{}) error {
 	return nil
 }
 
+// Valid returns true if ls has a value.
+func (ls *LineString) Valid() bool {
+	return ls != nil && ls.LineString != nil
+}
+
 // Value returns the WKB encoding of ls.
 func (ls *LineString) Value() (driver.Value, error) {
+	if ls.LineString == nil {
+		return nil, nil
+	}
 	return value(ls.LineString)
 }
 
-// Scan scans from a []byte.
+// Scan scans from a []byte or string containing binary or hex WKB.
 func (p *Polygon) Scan(src interface{}) error {
-	b, ok := src.([]byte)
+	if src == nil {
+		p.Polygon = nil
+		return nil
+	}
+	b, ok, err := wkbcommon.SQLBytes(src)
+	if err != nil {
+		return err
+	}
 	if !ok {
 		return ErrExpectedByteSlice{Value: src}
 	}
@@ -124,14 +168,29 @@ func (p *Polygon) Scan(src interface{}) error {
 	return nil
 }
 
+// Valid returns true if p has a value.
+func (p *Polygon) Valid() bool {
+	return p != nil && p.Polygon != nil
+}
+
 // Value returns the WKB encoding of p.
 func (p *Polygon) Value() (driver.Value, error) {
+	if p.Polygon == nil {
+		return nil, nil
+	}
 	return value(p.Polygon)
 }
 
-// Scan scans from a []byte.
+// Scan scans from a []byte or string containing binary or hex WKB.
 func (mp *MultiPoint) Scan(src interface{}) error {
-	b, ok := src.([]byte)
+	if src == nil {
+		mp.MultiPoint = nil
+		return nil
+	}
+	b, ok, err := wkbcommon.SQLBytes(src)
+	if err != nil {
+		return err
+	}
 	if !ok {
 		return ErrExpectedByteSlice{Value: src}
 	}
@@ -147,14 +206,29 @@ func (mp *MultiPoint) Scan(src interface{}) error {
 	return nil
 }
 
+// Valid returns true if mp has a value.
+func (mp *MultiPoint) Valid() bool {
+	return mp != nil && mp.MultiPoint != nil
+}
+
 // Value returns the WKB encoding of mp.
 func (mp *MultiPoint) Value() (driver.Value, error) {
+	if mp.MultiPoint == nil {
+		return nil, nil
+	}
 	return value(mp.MultiPoint)
 }
 
-// Scan scans from a []byte.
+// Scan scans from a []byte or string containing binary or hex WKB.
 func (mls *MultiLineString) Scan(src interface{}) error {
-	b, ok := src.([]byte)
+	if src == nil {
+		mls.MultiLineString = nil
+		return nil
+	}
+	b, ok, err := wkbcommon.SQLBytes(src)
+	if err != nil {
+		return err
+	}
 	if !ok {
 		return ErrExpectedByteSlice{Value: src}
 	}
@@ -170,14 +244,29 @@ func (mls *MultiLineString) Scan(src interface{}) error {
 	return nil
 }
 
+// Valid returns true if mls has a value.
+func (mls *MultiLineString) Valid() bool {
+	return mls != nil && mls.MultiLineString != nil
+}
+
 // Value returns the WKB encoding of mls.
 func (mls *MultiLineString) Value() (driver.Value, error) {
+	if mls.MultiLineString == nil {
+		return nil, nil
+	}
 	return value(mls.MultiLineString)
 }
 
-// Scan scans from a []byte.
+// Scan scans from a []byte or string containing binary or hex WKB.
 func (mp *MultiPolygon) Scan(src interface{}) error {
-	b, ok := src.([]byte)
+	if src == nil {
+		mp.MultiPolygon = nil
+		return nil
+	}
+	b, ok, err := wkbcommon.SQLBytes(src)
+	if err != nil {
+		return err
+	}
 	if !ok {
 		return ErrExpectedByteSlice{Value: src}
 	}
@@ -193,14 +282,29 @@ func (mp *MultiPolygon) Scan(src interface{}) error {
 	return nil
 }
 
+// Valid returns true if mp has a value.
+func (mp *MultiPolygon) Valid() bool {
+	return mp != nil && mp.MultiPolygon != nil
+}
+
 // Value returns the WKB encoding of mp.
 func (mp *MultiPolygon) Value() (driver.Value, error) {
+	if mp.MultiPolygon == nil {
+		return nil, nil
+	}
 	return value(mp.MultiPolygon)
 }
 
-// Scan scans from a []byte.
+// Scan scans from a []byte or string containing binary or hex WKB.
 func (gc *GeometryCollection) Scan(src interface{}) error {
-	b, ok := src.([]byte)
+	if src == nil {
+		gc.GeometryCollection = nil
+		return nil
+	}
+	b, ok, err := wkbcommon.SQLBytes(src)
+	if err != nil {
+		return err
+	}
 	if !ok {
 		return ErrExpectedByteSlice{Value: src}
 	}
@@ -216,11 +320,51 @@ func (gc *GeometryCollection) Scan(src interface{}) error {
 	return nil
 }
 
+// Valid returns true if gc has a value.
+func (gc *GeometryCollection) Valid() bool {
+	return gc != nil && gc.GeometryCollection != nil
+}
+
 // Value returns the WKB encoding of gc.
 func (gc *GeometryCollection) Value() (driver.Value, error) {
+	if gc.GeometryCollection == nil {
+		return nil, nil
+	}
 	return value(gc.GeometryCollection)
 }
 
+// Scan scans from a []byte or string containing binary or hex WKB.
+func (g *Geometry) Scan(src interface{}) error {
+	if src == nil {
+		g.T = nil
+		return nil
+	}
+	b, ok, err := wkbcommon.SQLBytes(src)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrExpectedByteSlice{Value: src}
+	}
+	if g.T, err = Unmarshal(b); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Valid returns true if g has a value.
+func (g *Geometry) Valid() bool {
+	return g != nil && g.T != nil
+}
+
+// Value returns the WKB encoding of g.
+func (g *Geometry) Value() (driver.Value, error) {
+	if g.T == nil {
+		return nil, nil
+	}
+	return value(g.T)
+}
+
 func value(g geom.T) (driver.Value, error) {
 	b := &bytes.Buffer{}
 	if err := Write(b, NDR, g); err != nil {
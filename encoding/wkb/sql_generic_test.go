@@ -0,0 +1,64 @@
+//go:build go1.18
+// +build go1.18
+
+package wkb
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/wkbcommon"
+	"github.com/twpayne/go-geom/internal/geomtest"
+)
+
+var _ = []interface {
+	sql.Scanner
+	driver.Valuer
+	Valid() bool
+}{
+	&Geom[*geom.Point]{},
+	&Geom[*geom.MultiPolygon]{},
+	&Geom[geom.T]{},
+}
+
+func TestGeomScanAndValue(t *testing.T) {
+	point := geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1, 2})
+	data := geomtest.MustHexDecode("0101000000000000000000f03f0000000000000040")
+
+	var p Geom[*geom.Point]
+	for _, src := range []interface{}{data, "0101000000000000000000f03f0000000000000040"} {
+		if err := p.Scan(src); err != nil || !reflect.DeepEqual(p.Geom, point) {
+			t.Errorf("p.Scan(%v) == %v, p.Geom == %v, want <nil>, %v", src, err, p.Geom, point)
+		}
+		if !p.Valid() {
+			t.Errorf("p.Valid() == false, want true")
+		}
+	}
+	if got, err := p.Value(); err != nil || !reflect.DeepEqual(got, data) {
+		t.Errorf("p.Value() == %v, %v, want %v, <nil>", got, err, data)
+	}
+	if err := p.Scan(nil); err != nil || p.Geom != nil || p.Valid() {
+		t.Errorf("p.Scan(nil) == %v, p.Geom == %v, p.Valid() == %t, want <nil>, <nil>, false", err, p.Geom, p.Valid())
+	}
+	if got, err := p.Value(); err != nil || got != nil {
+		t.Errorf("p.Value() == %v, %v, want <nil>, <nil>", got, err)
+	}
+
+	var ls Geom[*geom.LineString]
+	var errUnexpectedType wkbcommon.ErrUnexpectedType
+	if err := ls.Scan(data); !errors.As(err, &errUnexpectedType) {
+		t.Errorf("ls.Scan(%v) == %v, want wkbcommon.ErrUnexpectedType", data, err)
+	}
+	if err := ls.Scan(1); !reflect.DeepEqual(err, ErrExpectedByteSlice{Value: 1}) {
+		t.Errorf("ls.Scan(1) == %v, want %v", err, ErrExpectedByteSlice{Value: 1})
+	}
+
+	var g Geom[geom.T]
+	if err := g.Scan(data); err != nil || !reflect.DeepEqual(g.Geom, point) {
+		t.Errorf("g.Scan(%v) == %v, g.Geom == %v, want <nil>, %v", data, err, g.Geom, point)
+	}
+}
@@ -74,3 +74,41 @@ func Example_value() {
 	// Output:
 	// 1 rows affected
 }
+
+func Example_scanGeometry() {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT location FROM places;`).
+		WillReturnRows(
+			sqlmock.NewRows([]string{"location"}).
+				AddRow("010100000052B81E85EB51C03F45F0BF95ECC04940").
+				AddRow("01020000000200000000000000000000000000000000000000000000000000f03f000000000000f03f").
+				AddRow(nil),
+		)
+
+	rows, err := db.Query(`SELECT location FROM places;`)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var location wkb.Geometry
+		if err := rows.Scan(&location); err != nil {
+			log.Fatal(err)
+		}
+		if !location.Valid() {
+			fmt.Println("NULL")
+			continue
+		}
+		fmt.Printf("%T %v\n", location.T, location.FlatCoords())
+	}
+
+	// Output:
+	// *geom.Point [0.1275 51.50722]
+	// *geom.LineString [0 0 1 1]
+	// NULL
+}
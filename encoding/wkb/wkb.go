@@ -28,8 +28,38 @@ const (
 	wkbXYZMID = 3000
 )
 
-// Read reads an arbitrary geometry from r.
-func Read(r io.Reader) (geom.T, error) {
+// Pre-ISO 2.5D flags.
+const (
+	wkb25DZ = 0x80000000
+	wkb25DM = 0x40000000
+)
+
+// Read reads an arbitrary geometry from r. Each member of a multi-geometry or
+// geometry collection is read with its own byte order, which may differ from
+// that of its container.
+func Read(r io.Reader, opts ...Option) (geom.T, error) {
+	o := newOptions(opts)
+	g, err := read(r, o, wkbcommon.NewLimiter(o.limits))
+	if err != nil {
+		return nil, err
+	}
+	if o.sridFunc != nil {
+		srid, err := o.sridFunc(0)
+		if err != nil {
+			return nil, err
+		}
+		setSRID(g, srid)
+	}
+	return g, nil
+}
+
+// read reads an arbitrary geometry from r, enforcing the limits of l.
+func read(r io.Reader, o options, l *wkbcommon.Limiter) (geom.T, error) {
+	if err := l.Enter(); err != nil {
+		return nil, err
+	}
+	defer l.Leave()
+
 	wkbByteOrder, err := wkbcommon.ReadByte(r)
 	if err != nil {
 		return nil, err
@@ -48,7 +78,7 @@ func Read(r io.Reader) (geom.T, error) {
 	if err != nil {
 		return nil, err
 	}
-	t := wkbcommon.Type(wkbGeometryType)
+	t := isoType(wkbcommon.Type(wkbGeometryType), o.wkb25D)
 
 	layout := geom.NoLayout
 	switch 1000 * (t / 1000) {
@@ -66,19 +96,19 @@ func Read(r io.Reader) (geom.T, error) {
 
 	switch t % 1000 {
 	case wkbcommon.PointID:
-		flatCoords, err := wkbcommon.ReadFlatCoords0(r, byteOrder, layout.Stride())
+		flatCoords, err := l.ReadFlatCoords0(r, byteOrder, layout.Stride())
 		if err != nil {
 			return nil, err
 		}
 		return geom.NewPointFlat(layout, flatCoords), nil
 	case wkbcommon.LineStringID:
-		flatCoords, err := wkbcommon.ReadFlatCoords1(r, byteOrder, layout.Stride())
+		flatCoords, err := l.ReadFlatCoords1(r, byteOrder, layout.Stride())
 		if err != nil {
 			return nil, err
 		}
 		return geom.NewLineStringFlat(layout, flatCoords), nil
 	case wkbcommon.PolygonID:
-		flatCoords, ends, err := wkbcommon.ReadFlatCoords2(r, byteOrder, layout.Stride())
+		flatCoords, ends, err := l.ReadFlatCoords2(r, byteOrder, layout.Stride())
 		if err != nil {
 			return nil, err
 		}
@@ -88,12 +118,12 @@ func Read(r io.Reader) (geom.T, error) {
 		if err != nil {
 			return nil, err
 		}
-		if limit := wkbcommon.MaxGeometryElements[1]; limit >= 0 && int(n) > limit {
-			return nil, wkbcommon.ErrGeometryTooLarge{Level: 1, N: int(n), Limit: limit}
+		if err := l.CheckElements(1, n); err != nil {
+			return nil, err
 		}
 		mp := geom.NewMultiPoint(layout)
 		for i := uint32(0); i < n; i++ {
-			g, err := Read(r)
+			g, err := read(r, o, l)
 			if err != nil {
 				return nil, err
 			}
@@ -111,12 +141,12 @@ func Read(r io.Reader) (geom.T, error) {
 		if err != nil {
 			return nil, err
 		}
-		if limit := wkbcommon.MaxGeometryElements[2]; limit >= 0 && int(n) > limit {
-			return nil, wkbcommon.ErrGeometryTooLarge{Level: 2, N: int(n), Limit: limit}
+		if err := l.CheckElements(2, n); err != nil {
+			return nil, err
 		}
 		mls := geom.NewMultiLineString(layout)
 		for i := uint32(0); i < n; i++ {
-			g, err := Read(r)
+			g, err := read(r, o, l)
 			if err != nil {
 				return nil, err
 			}
@@ -134,12 +164,12 @@ func Read(r io.Reader) (geom.T, error) {
 		if err != nil {
 			return nil, err
 		}
-		if limit := wkbcommon.MaxGeometryElements[3]; limit >= 0 && int(n) > limit {
-			return nil, wkbcommon.ErrGeometryTooLarge{Level: 3, N: int(n), Limit: limit}
+		if err := l.CheckElements(3, n); err != nil {
+			return nil, err
 		}
 		mp := geom.NewMultiPolygon(layout)
 		for i := uint32(0); i < n; i++ {
-			g, err := Read(r)
+			g, err := read(r, o, l)
 			if err != nil {
 				return nil, err
 			}
@@ -157,9 +187,12 @@ func Read(r io.Reader) (geom.T, error) {
 		if err != nil {
 			return nil, err
 		}
+		if err := l.CheckElements(1, n); err != nil {
+			return nil, err
+		}
 		gc := geom.NewGeometryCollection()
 		for i := uint32(0); i < n; i++ {
-			g, err := Read(r)
+			g, err := read(r, o, l)
 			if err != nil {
 				return nil, err
 			}
@@ -168,18 +201,131 @@ func Read(r io.Reader) (geom.T, error) {
 			}
 		}
 		return gc, nil
+	case wkbcommon.CircularStringID:
+		flatCoords, err := l.ReadFlatCoords1(r, byteOrder, layout.Stride())
+		if err != nil {
+			return nil, err
+		}
+		return geom.NewCircularStringFlat(layout, flatCoords), nil
+	case wkbcommon.CompoundCurveID:
+		n, err := wkbcommon.ReadUInt32(r, byteOrder)
+		if err != nil {
+			return nil, err
+		}
+		if err := l.CheckElements(2, n); err != nil {
+			return nil, err
+		}
+		cc := geom.NewCompoundCurve(layout)
+		for i := uint32(0); i < n; i++ {
+			g, err := read(r, o, l)
+			if err != nil {
+				return nil, err
+			}
+			if err := cc.Push(g); err != nil {
+				return nil, err
+			}
+		}
+		return cc, nil
+	case wkbcommon.CurvePolygonID:
+		n, err := wkbcommon.ReadUInt32(r, byteOrder)
+		if err != nil {
+			return nil, err
+		}
+		if err := l.CheckElements(2, n); err != nil {
+			return nil, err
+		}
+		cp := geom.NewCurvePolygon(layout)
+		for i := uint32(0); i < n; i++ {
+			g, err := read(r, o, l)
+			if err != nil {
+				return nil, err
+			}
+			if err := cp.Push(g); err != nil {
+				return nil, err
+			}
+		}
+		return cp, nil
+	case wkbcommon.PolyhedralSurfaceID:
+		n, err := wkbcommon.ReadUInt32(r, byteOrder)
+		if err != nil {
+			return nil, err
+		}
+		if err := l.CheckElements(3, n); err != nil {
+			return nil, err
+		}
+		ps := geom.NewPolyhedralSurface(layout)
+		for i := uint32(0); i < n; i++ {
+			g, err := read(r, o, l)
+			if err != nil {
+				return nil, err
+			}
+			p, ok := g.(*geom.Polygon)
+			if !ok {
+				return nil, wkbcommon.ErrUnexpectedType{Got: g, Want: &geom.Polygon{}}
+			}
+			if err := ps.Push(p); err != nil {
+				return nil, err
+			}
+		}
+		return ps, nil
+	case wkbcommon.TINID:
+		n, err := wkbcommon.ReadUInt32(r, byteOrder)
+		if err != nil {
+			return nil, err
+		}
+		if err := l.CheckElements(3, n); err != nil {
+			return nil, err
+		}
+		tin := geom.NewTIN(layout)
+		for i := uint32(0); i < n; i++ {
+			g, err := read(r, o, l)
+			if err != nil {
+				return nil, err
+			}
+			t, ok := g.(*geom.Triangle)
+			if !ok {
+				return nil, wkbcommon.ErrUnexpectedType{Got: g, Want: &geom.Triangle{}}
+			}
+			if err := tin.Push(t); err != nil {
+				return nil, err
+			}
+		}
+		return tin, nil
+	case wkbcommon.TriangleID:
+		flatCoords, ends, err := l.ReadFlatCoords2(r, byteOrder, layout.Stride())
+		if err != nil {
+			return nil, err
+		}
+		return geom.NewTriangleFlat(layout, flatCoords, ends), nil
 	default:
 		return nil, wkbcommon.ErrUnsupportedType(wkbGeometryType)
 	}
 }
 
 // Unmarshal unmrshals an arbitrary geometry from a []byte.
-func Unmarshal(data []byte) (geom.T, error) {
-	return Read(bytes.NewBuffer(data))
+func Unmarshal(data []byte, opts ...Option) (geom.T, error) {
+	return Read(bytes.NewBuffer(data), opts...)
+}
+
+// DecodePrefix decodes a single geometry from the start of data and returns
+// it with the remaining bytes, which may be empty. It is useful for consuming
+// concatenated WKB geometries or WKB embedded in other formats.
+func DecodePrefix(data []byte, opts ...Option) (geom.T, []byte, error) {
+	r := bytes.NewReader(data)
+	g, err := Read(r, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return g, data[len(data)-r.Len():], nil
 }
 
-// Write writes an arbitrary geometry to w.
-func Write(w io.Writer, byteOrder binary.ByteOrder, g geom.T) error {
+// Write writes an arbitrary geometry to w. The byteOrder argument takes
+// precedence over WithByteOrder.
+func Write(w io.Writer, byteOrder binary.ByteOrder, g geom.T, opts ...Option) error {
+	return write(w, byteOrder, g, newOptions(opts))
+}
+
+func write(w io.Writer, byteOrder binary.ByteOrder, g geom.T, o options) error {
 	var wkbByteOrder byte
 	switch byteOrder {
 	case XDR:
@@ -193,36 +339,9 @@ func Write(w io.Writer, byteOrder binary.ByteOrder, g geom.T) error {
 		return err
 	}
 
-	var wkbGeometryType uint32
-	switch g.(type) {
-	case *geom.Point:
-		wkbGeometryType = wkbcommon.PointID
-	case *geom.LineString:
-		wkbGeometryType = wkbcommon.LineStringID
-	case *geom.Polygon:
-		wkbGeometryType = wkbcommon.PolygonID
-	case *geom.MultiPoint:
-		wkbGeometryType = wkbcommon.MultiPointID
-	case *geom.MultiLineString:
-		wkbGeometryType = wkbcommon.MultiLineStringID
-	case *geom.MultiPolygon:
-		wkbGeometryType = wkbcommon.MultiPolygonID
-	case *geom.GeometryCollection:
-		wkbGeometryType = wkbcommon.GeometryCollectionID
-	default:
-		return geom.ErrUnsupportedType{Value: g}
-	}
-	switch g.Layout() {
-	case geom.XY:
-		wkbGeometryType += wkbXYID
-	case geom.XYZ:
-		wkbGeometryType += wkbXYZID
-	case geom.XYM:
-		wkbGeometryType += wkbXYMID
-	case geom.XYZM:
-		wkbGeometryType += wkbXYZMID
-	default:
-		return geom.ErrUnsupportedLayout(g.Layout())
+	wkbGeometryType, err := geometryType(g, o.wkb25D)
+	if err != nil {
+		return err
 	}
 	if err := wkbcommon.WriteUInt32(w, byteOrder, wkbGeometryType); err != nil {
 		return err
@@ -241,7 +360,7 @@ func Write(w io.Writer, byteOrder binary.ByteOrder, g geom.T) error {
 			return err
 		}
 		for i := 0; i < n; i++ {
-			if err := Write(w, byteOrder, g.Point(i)); err != nil {
+			if err := write(w, byteOrder, g.Point(i), o); err != nil {
 				return err
 			}
 		}
@@ -252,7 +371,7 @@ func Write(w io.Writer, byteOrder binary.ByteOrder, g geom.T) error {
 			return err
 		}
 		for i := 0; i < n; i++ {
-			if err := Write(w, byteOrder, g.LineString(i)); err != nil {
+			if err := write(w, byteOrder, g.LineString(i), o); err != nil {
 				return err
 			}
 		}
@@ -263,7 +382,7 @@ func Write(w io.Writer, byteOrder binary.ByteOrder, g geom.T) error {
 			return err
 		}
 		for i := 0; i < n; i++ {
-			if err := Write(w, byteOrder, g.Polygon(i)); err != nil {
+			if err := write(w, byteOrder, g.Polygon(i), o); err != nil {
 				return err
 			}
 		}
@@ -274,21 +393,149 @@ func Write(w io.Writer, byteOrder binary.ByteOrder, g geom.T) error {
 			return err
 		}
 		for i := 0; i < n; i++ {
-			if err := Write(w, byteOrder, g.Geom(i)); err != nil {
+			if err := write(w, byteOrder, g.Geom(i), o); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *geom.CircularString:
+		return wkbcommon.WriteFlatCoords1(w, byteOrder, g.FlatCoords(), g.Stride())
+	case *geom.CompoundCurve:
+		n := g.NumSegments()
+		if err := wkbcommon.WriteUInt32(w, byteOrder, uint32(n)); err != nil {
+			return err
+		}
+		for i := 0; i < n; i++ {
+			if err := write(w, byteOrder, g.Segment(i), o); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *geom.CurvePolygon:
+		n := g.NumRings()
+		if err := wkbcommon.WriteUInt32(w, byteOrder, uint32(n)); err != nil {
+			return err
+		}
+		for i := 0; i < n; i++ {
+			if err := write(w, byteOrder, g.Ring(i), o); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *geom.PolyhedralSurface:
+		n := g.NumPatches()
+		if err := wkbcommon.WriteUInt32(w, byteOrder, uint32(n)); err != nil {
+			return err
+		}
+		for i := 0; i < n; i++ {
+			if err := write(w, byteOrder, g.Patch(i), o); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *geom.TIN:
+		n := g.NumTriangles()
+		if err := wkbcommon.WriteUInt32(w, byteOrder, uint32(n)); err != nil {
+			return err
+		}
+		for i := 0; i < n; i++ {
+			if err := write(w, byteOrder, g.Triangle(i), o); err != nil {
 				return err
 			}
 		}
 		return nil
+	case *geom.Triangle:
+		return wkbcommon.WriteFlatCoords2(w, byteOrder, g.FlatCoords(), g.Ends(), g.Stride())
 	default:
 		return geom.ErrUnsupportedType{Value: g}
 	}
 }
 
 // Marshal marshals an arbitrary geometry to a []byte.
-func Marshal(g geom.T, byteOrder binary.ByteOrder) ([]byte, error) {
+func Marshal(g geom.T, byteOrder binary.ByteOrder, opts ...Option) ([]byte, error) {
 	w := bytes.NewBuffer(nil)
-	if err := Write(w, byteOrder, g); err != nil {
+	if err := Write(w, byteOrder, g, opts...); err != nil {
 		return nil, err
 	}
 	return w.Bytes(), nil
 }
+
+// geometryType returns the WKB geometry type of g. If wkb25D is true then Z
+// and M are encoded with the pre-ISO 2.5D flags.
+func geometryType(g geom.T, wkb25D bool) (uint32, error) {
+	var wkbGeometryType uint32
+	switch g.(type) {
+	case *geom.Point:
+		wkbGeometryType = wkbcommon.PointID
+	case *geom.LineString:
+		wkbGeometryType = wkbcommon.LineStringID
+	case *geom.Polygon:
+		wkbGeometryType = wkbcommon.PolygonID
+	case *geom.MultiPoint:
+		wkbGeometryType = wkbcommon.MultiPointID
+	case *geom.MultiLineString:
+		wkbGeometryType = wkbcommon.MultiLineStringID
+	case *geom.MultiPolygon:
+		wkbGeometryType = wkbcommon.MultiPolygonID
+	case *geom.GeometryCollection:
+		wkbGeometryType = wkbcommon.GeometryCollectionID
+	case *geom.CircularString:
+		wkbGeometryType = wkbcommon.CircularStringID
+	case *geom.CompoundCurve:
+		wkbGeometryType = wkbcommon.CompoundCurveID
+	case *geom.CurvePolygon:
+		wkbGeometryType = wkbcommon.CurvePolygonID
+	case *geom.PolyhedralSurface:
+		wkbGeometryType = wkbcommon.PolyhedralSurfaceID
+	case *geom.TIN:
+		wkbGeometryType = wkbcommon.TINID
+	case *geom.Triangle:
+		wkbGeometryType = wkbcommon.TriangleID
+	default:
+		return 0, geom.ErrUnsupportedType{Value: g}
+	}
+	if wkb25D {
+		switch g.Layout() {
+		case geom.XY:
+		case geom.XYZ:
+			wkbGeometryType |= wkb25DZ
+		case geom.XYM:
+			wkbGeometryType |= wkb25DM
+		case geom.XYZM:
+			wkbGeometryType |= wkb25DZ | wkb25DM
+		default:
+			return 0, geom.ErrUnsupportedLayout(g.Layout())
+		}
+		return wkbGeometryType, nil
+	}
+	switch g.Layout() {
+	case geom.XY:
+		wkbGeometryType += wkbXYID
+	case geom.XYZ:
+		wkbGeometryType += wkbXYZID
+	case geom.XYM:
+		wkbGeometryType += wkbXYMID
+	case geom.XYZM:
+		wkbGeometryType += wkbXYZMID
+	default:
+		return 0, geom.ErrUnsupportedLayout(g.Layout())
+	}
+	return wkbGeometryType, nil
+}
+
+// isoType returns the ISO geometry type equivalent to t. If wkb25D is true
+// then the pre-ISO 2.5D flags are converted, otherwise t is returned
+// unchanged.
+func isoType(t wkbcommon.Type, wkb25D bool) wkbcommon.Type {
+	if !wkb25D || t&(wkb25DZ|wkb25DM) == 0 || t&^(wkb25DZ|wkb25DM) >= 1000 {
+		return t
+	}
+	iso := t &^ (wkb25DZ | wkb25DM)
+	if t&wkb25DZ != 0 {
+		iso += wkbXYZID
+	}
+	if t&wkb25DM != 0 {
+		iso += wkbXYMID
+	}
+	return iso
+}
@@ -1,7 +1,11 @@
 package wkb
 
 import (
+	"bytes"
+	"encoding/binary"
 	"encoding/hex"
+	"errors"
+	"io"
 	"reflect"
 	"testing"
 
@@ -19,6 +23,9 @@ func test(t *testing.T, g geom.T, xdr []byte, ndr []byte) {
 		if got, err := Marshal(g, XDR); err != nil || !reflect.DeepEqual(got, xdr) {
 			t.Errorf("Marshal(%#v, XDR) == %s, %#v, want %s, nil", g, hex.EncodeToString(got), err, hex.EncodeToString(xdr))
 		}
+		if got, err := Append([]byte{0xff}, g, WithByteOrder(XDR)); err != nil || !reflect.DeepEqual(got, append([]byte{0xff}, xdr...)) {
+			t.Errorf("Append([]byte{0xff}, %#v, WithByteOrder(XDR)) == %s, %#v, want ff%s, nil", g, hex.EncodeToString(got), err, hex.EncodeToString(xdr))
+		}
 	}
 	if ndr != nil {
 		if got, err := Unmarshal(ndr); err != nil || !reflect.DeepEqual(got, g) {
@@ -27,6 +34,9 @@ func test(t *testing.T, g geom.T, xdr []byte, ndr []byte) {
 		if got, err := Marshal(g, NDR); err != nil || !reflect.DeepEqual(got, ndr) {
 			t.Errorf("Marshal(%#v, NDR) == %s, %#v, want %#v, nil", g, hex.EncodeToString(got), err, hex.EncodeToString(ndr))
 		}
+		if got, err := Append([]byte{0xff}, g, WithByteOrder(NDR)); err != nil || !reflect.DeepEqual(got, append([]byte{0xff}, ndr...)) {
+			t.Errorf("Append([]byte{0xff}, %#v, WithByteOrder(NDR)) == %s, %#v, want ff%s, nil", g, hex.EncodeToString(got), err, hex.EncodeToString(ndr))
+		}
 	}
 	switch g := g.(type) {
 	case *geom.Point:
@@ -258,6 +268,56 @@ func Test(t *testing.T) {
 	}
 }
 
+func TestCurvesAndSurfaces(t *testing.T) {
+	ids := []uint32{
+		wkbcommon.CircularStringID,
+		wkbcommon.CompoundCurveID,
+		wkbcommon.CurvePolygonID,
+		wkbcommon.PolyhedralSurfaceID,
+		wkbcommon.TINID,
+		wkbcommon.TriangleID,
+	}
+	for _, tc := range []struct {
+		layout geom.Layout
+		iso    uint32
+		wkb25D uint32
+	}{
+		{layout: geom.XY, iso: wkbXYID},
+		{layout: geom.XYZ, iso: wkbXYZID, wkb25D: wkb25DZ},
+		{layout: geom.XYM, iso: wkbXYMID, wkb25D: wkb25DM},
+		{layout: geom.XYZM, iso: wkbXYZMID, wkb25D: wkb25DZ | wkb25DM},
+	} {
+		for i, g := range testdata.CurvesAndSurfaces(tc.layout) {
+			for _, byteOrder := range []binary.ByteOrder{XDR, NDR} {
+				data, err := Marshal(g, byteOrder)
+				if err != nil {
+					t.Fatalf("Marshal(%#v, %v) == _, %v, want _, <nil>", g, byteOrder, err)
+				}
+				if got, want := byteOrder.Uint32(data[1:]), ids[i]+tc.iso; got != want {
+					t.Errorf("Marshal(%#v, %v) type == %d, want %d", g, byteOrder, got, want)
+				}
+				if got, err := Unmarshal(data); err != nil || !reflect.DeepEqual(got, g) {
+					t.Errorf("Unmarshal(%s) == %#v, %v, want %#v, <nil>", hex.EncodeToString(data), got, err, g)
+				}
+				if got, err := Append(nil, g, WithByteOrder(byteOrder)); err != nil || !bytes.Equal(got, data) {
+					t.Errorf("Append(nil, %#v, WithByteOrder(%v)) == %s, %v, want %s, <nil>", g, byteOrder, hex.EncodeToString(got), err, hex.EncodeToString(data))
+				}
+
+				data25D, err := Marshal(g, byteOrder, WithWKB25D(true))
+				if err != nil {
+					t.Fatalf("Marshal(%#v, %v, WithWKB25D(true)) == _, %v, want _, <nil>", g, byteOrder, err)
+				}
+				if got, want := byteOrder.Uint32(data25D[1:]), ids[i]|tc.wkb25D; got != want {
+					t.Errorf("Marshal(%#v, %v, WithWKB25D(true)) type == %#x, want %#x", g, byteOrder, got, want)
+				}
+				if got, err := Unmarshal(data25D, WithWKB25D(true)); err != nil || !reflect.DeepEqual(got, g) {
+					t.Errorf("Unmarshal(%s, WithWKB25D(true)) == %#v, %v, want %#v, <nil>", hex.EncodeToString(data25D), got, err, g)
+				}
+			}
+		}
+	}
+}
+
 func TestRandom(t *testing.T) {
 	for _, tc := range testdata.Random {
 		test(t, tc.G, nil, tc.WKB)
@@ -284,6 +344,40 @@ func BenchmarkMarshal(b *testing.B) {
 	}
 }
 
+func BenchmarkAppend(b *testing.B) {
+	var buf []byte
+	for n := 0; n < b.N; n++ {
+		for _, tc := range testdata.Random {
+			var err error
+			if buf, err = Append(buf[:0], tc.G); err != nil {
+				b.Errorf("append error %v", err)
+			}
+		}
+	}
+}
+
+func TestEncoder(t *testing.T) {
+	var b bytes.Buffer
+	e := NewEncoder(&b, WithByteOrder(XDR))
+	var want []byte
+	for _, tc := range testdata.Random[:10] {
+		if err := e.Encode(tc.G); err != nil {
+			t.Fatal(err)
+		}
+		data, err := Marshal(tc.G, XDR)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want = append(want, data...)
+	}
+	if !bytes.Equal(b.Bytes(), want) {
+		t.Errorf("got %s, want %s", hex.EncodeToString(b.Bytes()), hex.EncodeToString(want))
+	}
+	if err := e.Encode(geom.NewPointFlat(geom.NoLayout, nil)); err == nil {
+		t.Errorf("e.Encode(...) == <nil>, want !<nil>")
+	}
+}
+
 func TestCrashes(t *testing.T) {
 	// FIXME this test modifies a global variable. It will be racy if tests are
 	// run in parallel.
@@ -323,3 +417,257 @@ func TestCrashes(t *testing.T) {
 		}
 	}
 }
+
+func TestLimits(t *testing.T) {
+	mustMarshal := func(g geom.T) []byte {
+		data, err := Marshal(g, NDR)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+	lineString := mustMarshal(geom.NewLineStringFlat(geom.XY, []float64{0, 0, 1, 1, 2, 2}))
+	multiPolygon := mustMarshal(geom.NewMultiPolygonFlat(geom.XY, []float64{
+		0, 0, 1, 0, 1, 1, 0, 0,
+		2, 2, 3, 2, 3, 3, 2, 2,
+	}, [][]int{{8}, {16}}))
+	// A LineString header claiming 2^30 points followed by a single point.
+	huge := []byte{0x01, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x40, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}
+	// GeometryCollections nested 2^20 deep.
+	deep := append(bytes.Repeat([]byte{0x01, 0x07, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00}, 1<<20), 0x01, 0x07, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00)
+	nested := mustMarshal(geom.NewGeometryCollection().MustPush(geom.NewGeometryCollection().MustPush(geom.NewPointFlat(geom.XY, []float64{1, 2}))))
+	for _, tc := range []struct {
+		name string
+		data []byte
+		opts []Option
+		err  error
+	}{
+		{
+			name: "default_limits",
+			data: huge,
+			err:  wkbcommon.ErrGeometryTooLarge{Level: 1, N: 1 << 30, Limit: wkbcommon.DefaultLimits.MaxElements},
+		},
+		{
+			name: "zero_limits_are_default_limits",
+			data: huge,
+			opts: []Option{WithLimits(wkbcommon.Limits{})},
+			err:  wkbcommon.ErrGeometryTooLarge{Level: 1, N: 1 << 30, Limit: wkbcommon.DefaultLimits.MaxElements},
+		},
+		{
+			name: "zero_limits_ok",
+			data: multiPolygon,
+			opts: []Option{WithLimits(wkbcommon.Limits{})},
+		},
+		{
+			name: "no_limits_truncated",
+			data: huge,
+			opts: []Option{WithLimits(wkbcommon.NoLimits)},
+			err:  io.ErrUnexpectedEOF,
+		},
+		{
+			name: "max_elements",
+			data: lineString,
+			opts: []Option{WithLimits(wkbcommon.Limits{MaxElements: 2, MaxCoords: -1})},
+			err:  wkbcommon.ErrGeometryTooLarge{Level: 1, N: 3, Limit: 2},
+		},
+		{
+			name: "max_elements_ok",
+			data: lineString,
+			opts: []Option{WithLimits(wkbcommon.Limits{MaxElements: 3, MaxCoords: -1})},
+		},
+		{
+			name: "max_coords",
+			data: multiPolygon,
+			opts: []Option{WithLimits(wkbcommon.Limits{MaxElements: -1, MaxCoords: 7})},
+			err:  wkbcommon.ErrTooManyCoords{N: 8, Limit: 7},
+		},
+		{
+			name: "max_coords_ok",
+			data: multiPolygon,
+			opts: []Option{WithLimits(wkbcommon.Limits{MaxElements: -1, MaxCoords: 8})},
+		},
+		{
+			name: "default_max_depth",
+			data: deep,
+			err:  wkbcommon.ErrTooDeep{Limit: wkbcommon.DefaultLimits.MaxDepth},
+		},
+		{
+			name: "no_limits_hard_max_depth",
+			data: deep,
+			opts: []Option{WithLimits(wkbcommon.NoLimits)},
+			err:  wkbcommon.ErrTooDeep{Limit: wkbcommon.HardMaxDepth},
+		},
+		{
+			name: "max_depth",
+			data: nested,
+			opts: []Option{WithLimits(wkbcommon.Limits{MaxDepth: 2})},
+			err:  wkbcommon.ErrTooDeep{Limit: 2},
+		},
+		{
+			name: "max_depth_ok",
+			data: nested,
+			opts: []Option{WithLimits(wkbcommon.Limits{MaxDepth: 3})},
+		},
+		{
+			name: "max_coords_default_max_elements",
+			data: multiPolygon,
+			opts: []Option{WithLimits(wkbcommon.Limits{MaxCoords: 7})},
+			err:  wkbcommon.ErrTooManyCoords{N: 8, Limit: 7},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := Unmarshal(tc.data, tc.opts...); !reflect.DeepEqual(err, tc.err) {
+				t.Errorf("Unmarshal(...) == ..., %v, want %v", err, tc.err)
+			}
+		})
+	}
+}
+
+func TestDecodePrefix(t *testing.T) {
+	gs := []geom.T{
+		geom.NewPointFlat(geom.XY, []float64{1, 2}),
+		geom.NewLineStringFlat(geom.XYZ, []float64{1, 2, 3, 4, 5, 6}),
+		geom.NewGeometryCollection().MustPush(geom.NewPointFlat(geom.XYM, []float64{1, 2, 3})),
+	}
+	var data []byte
+	for _, g := range gs {
+		var err error
+		if data, err = Append(data, g); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rest := data
+	for i, want := range gs {
+		var got geom.T
+		var err error
+		got, rest, err = DecodePrefix(rest)
+		if err != nil || !reflect.DeepEqual(got, want) {
+			t.Fatalf("%d: DecodePrefix(...) == %v, ..., %v, want %v, ..., <nil>", i, got, err, want)
+		}
+	}
+	if len(rest) != 0 {
+		t.Errorf("len(rest) == %d, want 0", len(rest))
+	}
+	if _, _, err := DecodePrefix(data[:20]); err == nil {
+		t.Errorf("DecodePrefix(truncated) == ..., <nil>, want !<nil>")
+	}
+}
+
+func TestMixedByteOrders(t *testing.T) {
+	point := geom.NewPointFlat(geom.XY, []float64{1, 2})
+	lineString := geom.NewLineStringFlat(geom.XYZ, []float64{1, 2, 3, 4, 5, 6})
+	mustMarshal := func(g geom.T, byteOrder binary.ByteOrder) []byte {
+		data, err := Marshal(g, byteOrder)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+	// A big endian GeometryCollection header followed by little and big
+	// endian members.
+	data := []byte{0x00, 0x00, 0x00, 0x00, 0x07, 0x00, 0x00, 0x00, 0x02}
+	data = append(data, mustMarshal(point, NDR)...)
+	data = append(data, mustMarshal(lineString, XDR)...)
+	want := geom.NewGeometryCollection().MustPush(point, lineString)
+	if got, err := Unmarshal(data); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal(%s) == %v, %v, want %v, <nil>", hex.EncodeToString(data), got, err, want)
+	}
+	s, err := NewScanner(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := s.Geom(1); err != nil || !reflect.DeepEqual(got, lineString) {
+		t.Errorf("s.Geom(1) == %v, %v, want %v, <nil>", got, err, lineString)
+	}
+	l, err := NewLazy(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := l.Bounds(); err != nil || !reflect.DeepEqual(got, want.Bounds()) {
+		t.Errorf("l.Bounds() == %v, %v, want %v, <nil>", got, err, want.Bounds())
+	}
+}
+
+func TestWKB25D(t *testing.T) {
+	for _, tc := range []struct {
+		g   geom.T
+		hex string
+	}{
+		{
+			g:   geom.NewPointFlat(geom.XYZ, []float64{1, 2, 3}),
+			hex: "0101000080000000000000f03f00000000000000400000000000000840",
+		},
+		{
+			g:   geom.NewLineStringFlat(geom.XYM, []float64{1, 2, 3, 4, 5, 6}),
+			hex: "010200004002000000000000000000f03f00000000000000400000000000000840000000000000104000000000000014400000000000001840",
+		},
+		{
+			g:   geom.NewMultiPointFlat(geom.XYZM, []float64{1, 2, 3, 4}),
+			hex: "01040000c00100000001010000c0000000000000f03f000000000000004000000000000008400000000000001040",
+		},
+	} {
+		data, err := Marshal(tc.g, NDR, WithWKB25D(true))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hex.EncodeToString(data); got != tc.hex {
+			t.Errorf("Marshal(%v, NDR, WithWKB25D(true)) == %s, want %s", tc.g, got, tc.hex)
+		}
+		if got, err := Append(nil, tc.g, WithWKB25D(true)); err != nil || !bytes.Equal(got, data) {
+			t.Errorf("Append(nil, %v, WithWKB25D(true)) == %s, %v, want %s, <nil>", tc.g, hex.EncodeToString(got), err, tc.hex)
+		}
+		if got, err := Unmarshal(data, WithWKB25D(true)); err != nil || !reflect.DeepEqual(got, tc.g) {
+			t.Errorf("Unmarshal(%s, WithWKB25D(true)) == %v, %v, want %v, <nil>", tc.hex, got, err, tc.g)
+		}
+		if _, err := Unmarshal(data); err == nil {
+			t.Errorf("Unmarshal(%s) == ..., <nil>, want !<nil>", tc.hex)
+		}
+		l, err := NewLazy(data, WithWKB25D(true))
+		if err != nil || l.Layout() != tc.g.Layout() {
+			t.Errorf("NewLazy(%s, WithWKB25D(true)) == %v, %v", tc.hex, l, err)
+		}
+	}
+	// ISO types are still accepted.
+	iso, err := Marshal(geom.NewPointFlat(geom.XYZ, []float64{1, 2, 3}), NDR)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Unmarshal(iso, WithWKB25D(true)); err != nil {
+		t.Errorf("Unmarshal(%s, WithWKB25D(true)) == ..., %v, want <nil>", hex.EncodeToString(iso), err)
+	}
+}
+
+func TestSRIDFunc(t *testing.T) {
+	errNoSRID := errors.New("no SRID")
+	point := geom.NewPointFlat(geom.XY, []float64{1, 2})
+	data, err := Marshal(point, NDR)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assign := WithSRIDFunc(func(srid int) (int, error) {
+		return 4326, nil
+	})
+	want := geom.NewPointFlat(geom.XY, []float64{1, 2}).SetSRID(4326)
+	if got, err := Unmarshal(data, assign); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal(..., assign) == %v, %v, want %v, <nil>", got, err, want)
+	}
+	if got, _, err := DecodePrefix(data, assign); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("DecodePrefix(..., assign) == %v, ..., %v, want %v, ..., <nil>", got, err, want)
+	}
+	l, err := NewLazy(data, assign)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := l.SRID(); got != 4326 {
+		t.Errorf("l.SRID() == %d, want 4326", got)
+	}
+	if got, err := l.Geom(); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("l.Geom() == %v, %v, want %v, <nil>", got, err, want)
+	}
+	reject := WithSRIDFunc(func(srid int) (int, error) {
+		return 0, errNoSRID
+	})
+	if _, err := Unmarshal(data, reject); err != errNoSRID {
+		t.Errorf("Unmarshal(..., reject) == _, %v, want _, %v", err, errNoSRID)
+	}
+}
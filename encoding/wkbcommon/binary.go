@@ -73,3 +73,20 @@ func WriteByte(w io.Writer, value byte) error {
 	_, err := w.Write(buf[:])
 	return err
 }
+
+// AppendUInt32 appends a uint32 to dst.
+func AppendUInt32(dst []byte, byteOrder binary.ByteOrder, value uint32) []byte {
+	dst = append(dst, 0, 0, 0, 0)
+	byteOrder.PutUint32(dst[len(dst)-4:], value)
+	return dst
+}
+
+// AppendFloatArray appends a []float64 to dst.
+func AppendFloatArray(dst []byte, byteOrder binary.ByteOrder, array []float64) []byte {
+	n := len(dst)
+	dst = append(dst, make([]byte, 8*len(array))...)
+	for i, f := range array {
+		writeFloat(dst[n+8*i:], byteOrder, f)
+	}
+	return dst
+}
@@ -0,0 +1,211 @@
+package wkbcommon
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// Limits bounds the size of the geometries that are decoded, so that untrusted
+// inputs cannot cause excessive memory allocations. Zero means the
+// corresponding value of DefaultLimits and a negative value means no limit.
+type Limits struct {
+	// MaxElements is the maximum declared number of elements (points, rings,
+	// or member geometries) at any level.
+	MaxElements int
+	// MaxCoords is the maximum total number of coordinates in a geometry,
+	// including the coordinates of all its members.
+	MaxCoords int
+	// MaxDepth is the maximum nesting depth of geometries, where a geometry
+	// that is not a member of another geometry has depth 1. A negative value
+	// means HardMaxDepth, as arbitrarily deep nesting would exhaust the stack.
+	MaxDepth int
+}
+
+// HardMaxDepth is the maximum nesting depth of geometries that is ever
+// decoded, whatever the limits.
+const HardMaxDepth = 10000
+
+var (
+	// DefaultLimits are the limits used when none are specified. They are
+	// large enough for all but the most exceptional real-world geometries.
+	DefaultLimits = Limits{
+		MaxElements: 1 << 24,
+		MaxCoords:   1 << 26,
+		MaxDepth:    100,
+	}
+
+	// NoLimits disables all limits except MaxGeometryElements and
+	// HardMaxDepth.
+	NoLimits = Limits{
+		MaxElements: -1,
+		MaxCoords:   -1,
+		MaxDepth:    -1,
+	}
+)
+
+// readChunkSize is the maximum number of floats that are allocated before
+// their data have been read. Larger arrays are read in chunks, so a header
+// claiming a large number of coordinates on a short input fails before much
+// memory is allocated.
+const readChunkSize = 1 << 16
+
+// An ErrTooManyCoords is returned when the total number of coordinates in a
+// geometry exceeds the limit.
+type ErrTooManyCoords struct {
+	N     int
+	Limit int
+}
+
+func (e ErrTooManyCoords) Error() string {
+	return fmt.Sprintf("wkb: number of coordinates (%d) exceeds %d", e.N, e.Limit)
+}
+
+// An ErrTooDeep is returned when geometries are nested more deeply than the
+// limit.
+type ErrTooDeep struct {
+	Limit int
+}
+
+func (e ErrTooDeep) Error() string {
+	return fmt.Sprintf("wkb: nesting depth exceeds %d", e.Limit)
+}
+
+// A Limiter enforces Limits while decoding a single geometry. The zero value
+// is not usable; use NewLimiter.
+type Limiter struct {
+	limits Limits
+	coords int
+	depth  int
+}
+
+// NewLimiter returns a new Limiter that enforces limits.
+func NewLimiter(limits Limits) *Limiter {
+	if limits.MaxElements == 0 {
+		limits.MaxElements = DefaultLimits.MaxElements
+	}
+	if limits.MaxCoords == 0 {
+		limits.MaxCoords = DefaultLimits.MaxCoords
+	}
+	switch {
+	case limits.MaxDepth == 0:
+		limits.MaxDepth = DefaultLimits.MaxDepth
+	case limits.MaxDepth < 0 || limits.MaxDepth > HardMaxDepth:
+		limits.MaxDepth = HardMaxDepth
+	}
+	return &Limiter{
+		limits: limits,
+	}
+}
+
+// CheckElements checks a declared number of elements n at level, where level
+// 1 is the points of a LineString, CircularString, or MultiPoint or the
+// members of a GeometryCollection, level 2 is the rings of a Polygon or
+// CurvePolygon, the LineStrings of a MultiLineString, or the segments of a
+// CompoundCurve, and level 3 is the Polygons of a MultiPolygon or
+// PolyhedralSurface or the Triangles of a TIN.
+func (l *Limiter) CheckElements(level int, n uint32) error {
+	if level > 0 && level < len(MaxGeometryElements) {
+		if limit := MaxGeometryElements[level]; limit >= 0 && int64(n) > int64(limit) {
+			return ErrGeometryTooLarge{Level: level, N: int(n), Limit: limit}
+		}
+	}
+	if limit := l.limits.MaxElements; limit >= 0 && int64(n) > int64(limit) {
+		return ErrGeometryTooLarge{Level: level, N: int(n), Limit: limit}
+	}
+	return nil
+}
+
+// CheckCoords adds n to the number of coordinates decoded so far and checks
+// the total.
+func (l *Limiter) CheckCoords(n uint32) error {
+	l.coords += int(n)
+	if limit := l.limits.MaxCoords; limit >= 0 && l.coords > limit {
+		return ErrTooManyCoords{N: l.coords, Limit: limit}
+	}
+	return nil
+}
+
+// Enter records that a geometry is about to be decoded and checks the nesting
+// depth. Each successful call must be followed by a call to Leave.
+func (l *Limiter) Enter() error {
+	if l.depth >= l.limits.MaxDepth {
+		return ErrTooDeep{Limit: l.limits.MaxDepth}
+	}
+	l.depth++
+	return nil
+}
+
+// Leave records that a geometry has been decoded.
+func (l *Limiter) Leave() {
+	l.depth--
+}
+
+// ReadFlatCoords0 reads flat coordinates 0.
+func (l *Limiter) ReadFlatCoords0(r io.Reader, byteOrder binary.ByteOrder, stride int) ([]float64, error) {
+	if err := l.CheckCoords(1); err != nil {
+		return nil, err
+	}
+	return ReadFlatCoords0(r, byteOrder, stride)
+}
+
+// ReadFlatCoords1 reads flat coordinates 1.
+func (l *Limiter) ReadFlatCoords1(r io.Reader, byteOrder binary.ByteOrder, stride int) ([]float64, error) {
+	n, err := ReadUInt32(r, byteOrder)
+	if err != nil {
+		return nil, err
+	}
+	if err := l.CheckElements(1, n); err != nil {
+		return nil, err
+	}
+	if err := l.CheckCoords(n); err != nil {
+		return nil, err
+	}
+	return readFloats(r, byteOrder, int(n)*stride)
+}
+
+// ReadFlatCoords2 reads flat coordinates 2.
+func (l *Limiter) ReadFlatCoords2(r io.Reader, byteOrder binary.ByteOrder, stride int) ([]float64, []int, error) {
+	n, err := ReadUInt32(r, byteOrder)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := l.CheckElements(2, n); err != nil {
+		return nil, nil, err
+	}
+	var flatCoordss []float64
+	var ends []int
+	for i := 0; i < int(n); i++ {
+		flatCoords, err := l.ReadFlatCoords1(r, byteOrder, stride)
+		if err != nil {
+			return nil, nil, err
+		}
+		flatCoordss = append(flatCoordss, flatCoords...)
+		ends = append(ends, len(flatCoordss))
+	}
+	return flatCoordss, ends, nil
+}
+
+// readFloats reads n floats from r, allocating memory only as data are read.
+func readFloats(r io.Reader, byteOrder binary.ByteOrder, n int) ([]float64, error) {
+	if n <= readChunkSize {
+		floats := make([]float64, n)
+		if err := ReadFloatArray(r, byteOrder, floats); err != nil {
+			return nil, err
+		}
+		return floats, nil
+	}
+	floats := make([]float64, 0, readChunkSize)
+	for len(floats) < n {
+		chunk := n - len(floats)
+		if chunk > readChunkSize {
+			chunk = readChunkSize
+		}
+		start := len(floats)
+		floats = append(floats, make([]float64, chunk)...)
+		if err := ReadFloatArray(r, byteOrder, floats[start:]); err != nil {
+			return nil, err
+		}
+	}
+	return floats, nil
+}
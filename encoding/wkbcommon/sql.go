@@ -0,0 +1,33 @@
+package wkbcommon
+
+import (
+	"bytes"
+	"encoding/hex"
+)
+
+// SQLBytes returns the binary WKB or EWKB in src, a value scanned from a
+// database column. PostGIS and CockroachDB return geometry columns as hex
+// text unless they are converted with ST_AsBinary or ST_AsEWKB, and bytea
+// columns may be in PostgreSQL's \x hex format, so hex data in a []byte or a
+// string is decoded. ok is false if src is neither a []byte nor a string.
+func SQLBytes(src interface{}) (data []byte, ok bool, err error) {
+	switch src := src.(type) {
+	case []byte:
+		data = src
+	case string:
+		data = []byte(src)
+	default:
+		return nil, false, nil
+	}
+	data = bytes.TrimPrefix(data, []byte(`\x`))
+	// Binary WKB starts with a byte order byte of 0 or 1, and hex WKB starts
+	// with the hex digit 0.
+	if len(data) == 0 || data[0] != '0' {
+		return data, true, nil
+	}
+	decoded := make([]byte, hex.DecodedLen(len(data)))
+	if _, err := hex.Decode(decoded, data); err != nil {
+		return nil, true, err
+	}
+	return decoded, true, nil
+}
@@ -0,0 +1,34 @@
+//go:build go1.18
+// +build go1.18
+
+package wkbcommon
+
+import "github.com/twpayne/go-geom"
+
+// SQLGeom returns the geometry of type T in src, a value scanned from a
+// database column, decoded with unmarshal. It returns the zero T if src is
+// nil and ok is false if src is neither a []byte nor a string.
+func SQLGeom[T geom.T](src interface{}, unmarshal func([]byte) (geom.T, error)) (g T, ok bool, err error) {
+	if src == nil {
+		return g, true, nil
+	}
+	data, ok, err := SQLBytes(src)
+	if !ok || err != nil {
+		return g, ok, err
+	}
+	got, err := unmarshal(data)
+	if err != nil {
+		return g, true, err
+	}
+	g, ok = got.(T)
+	if !ok {
+		return g, true, ErrUnexpectedType{Got: got, Want: g}
+	}
+	return g, true, nil
+}
+
+// IsZero returns true if g is the zero T, i.e. a nil geometry.
+func IsZero[T geom.T](g T) bool {
+	var zero T
+	return geom.T(g) == geom.T(zero)
+}
@@ -69,8 +69,8 @@ func (e ErrUnexpectedType) Error() string {
 // importing the `github.com/twpayne/go-geom/encoding/wkbcommon` module and
 // setting the value of `wkbcommon.MaxGeometryElements`.
 //
-// FIXME This should be Codec-specific, not global
-// FIXME Consider overall per-geometry limit rather than per-level limit
+// Per-decoder limits, including a limit on the total number of coordinates,
+// can be set with Limits.
 var MaxGeometryElements = [4]int{
 	0,  // Unused
 	-1, // LineString, LinearRing, and MultiPoint
@@ -98,6 +98,11 @@ const (
 	MultiLineStringID    = 5
 	MultiPolygonID       = 6
 	GeometryCollectionID = 7
+	CircularStringID     = 8
+	CompoundCurveID      = 9
+	CurvePolygonID       = 10
+	MultiCurveID         = 11
+	MultiSurfaceID       = 12
 	PolyhedralSurfaceID  = 15
 	TINID                = 16
 	TriangleID           = 17
@@ -114,40 +119,12 @@ func ReadFlatCoords0(r io.Reader, byteOrder binary.ByteOrder, stride int) ([]flo
 
 // ReadFlatCoords1 reads flat coordinates 1.
 func ReadFlatCoords1(r io.Reader, byteOrder binary.ByteOrder, stride int) ([]float64, error) {
-	n, err := ReadUInt32(r, byteOrder)
-	if err != nil {
-		return nil, err
-	}
-	if limit := MaxGeometryElements[1]; limit >= 0 && int(n) > limit {
-		return nil, ErrGeometryTooLarge{Level: 1, N: int(n), Limit: limit}
-	}
-	flatCoords := make([]float64, int(n)*stride)
-	if err := ReadFloatArray(r, byteOrder, flatCoords); err != nil {
-		return nil, err
-	}
-	return flatCoords, nil
+	return NewLimiter(NoLimits).ReadFlatCoords1(r, byteOrder, stride)
 }
 
 // ReadFlatCoords2 reads flat coordinates 2.
 func ReadFlatCoords2(r io.Reader, byteOrder binary.ByteOrder, stride int) ([]float64, []int, error) {
-	n, err := ReadUInt32(r, byteOrder)
-	if err != nil {
-		return nil, nil, err
-	}
-	if limit := MaxGeometryElements[2]; limit >= 0 && int(n) > limit {
-		return nil, nil, ErrGeometryTooLarge{Level: 2, N: int(n), Limit: limit}
-	}
-	var flatCoordss []float64
-	var ends []int
-	for i := 0; i < int(n); i++ {
-		flatCoords, err := ReadFlatCoords1(r, byteOrder, stride)
-		if err != nil {
-			return nil, nil, err
-		}
-		flatCoordss = append(flatCoordss, flatCoords...)
-		ends = append(ends, len(flatCoordss))
-	}
-	return flatCoordss, ends, nil
+	return NewLimiter(NoLimits).ReadFlatCoords2(r, byteOrder, stride)
 }
 
 // WriteFlatCoords0 writes flat coordinates 0.
@@ -177,3 +154,19 @@ func WriteFlatCoords2(w io.Writer, byteOrder binary.ByteOrder, flatCoords []floa
 	}
 	return nil
 }
+
+// AppendFlatCoords1 appends flat coordinates 1 to dst.
+func AppendFlatCoords1(dst []byte, byteOrder binary.ByteOrder, coords []float64, stride int) []byte {
+	dst = AppendUInt32(dst, byteOrder, uint32(len(coords)/stride))
+	return AppendFloatArray(dst, byteOrder, coords)
+}
+
+// AppendFlatCoords2 appends flat coordinates 2 to dst.
+func AppendFlatCoords2(dst []byte, byteOrder binary.ByteOrder, flatCoords []float64, offset int, ends []int, stride int) []byte {
+	dst = AppendUInt32(dst, byteOrder, uint32(len(ends)))
+	for _, end := range ends {
+		dst = AppendFlatCoords1(dst, byteOrder, flatCoords[offset:end], stride)
+		offset = end
+	}
+	return dst
+}
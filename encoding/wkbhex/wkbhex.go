@@ -5,6 +5,7 @@ package wkbhex
 import (
 	"encoding/binary"
 	"encoding/hex"
+	"strings"
 
 	"github.com/twpayne/go-geom"
 	"github.com/twpayne/go-geom/encoding/wkb"
@@ -17,20 +18,42 @@ var (
 	NDR = wkb.NDR
 )
 
-// Encode encodes an arbitrary geometry to a string.
-func Encode(g geom.T, byteOrder binary.ByteOrder) (string, error) {
-	wkb, err := wkb.Marshal(g, byteOrder)
+// Encode encodes an arbitrary geometry to a string of lower case hexadecimal
+// digits.
+func Encode(g geom.T, byteOrder binary.ByteOrder, opts ...wkb.Option) (string, error) {
+	wkb, err := wkb.Marshal(g, byteOrder, opts...)
 	if err != nil {
 		return "", err
 	}
 	return hex.EncodeToString(wkb), nil
 }
 
-// Decode decodes an arbitrary geometry from a string.
-func Decode(s string) (geom.T, error) {
+// EncodeUpper encodes an arbitrary geometry to a string of upper case
+// hexadecimal digits, as used by PostGIS.
+func EncodeUpper(g geom.T, byteOrder binary.ByteOrder, opts ...wkb.Option) (string, error) {
+	s, err := Encode(g, byteOrder, opts...)
+	if err != nil {
+		return "", err
+	}
+	return strings.ToUpper(s), nil
+}
+
+// Decode decodes an arbitrary geometry from a string of upper or lower case
+// hexadecimal digits.
+func Decode(s string, opts ...wkb.Option) (geom.T, error) {
+	data, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return wkb.Unmarshal(data, opts...)
+}
+
+// DecodeStub returns the Stub of the geometry in s without decoding the
+// geometry. See wkb.UnmarshalStub.
+func DecodeStub(s string, opts ...wkb.Option) (*geom.Stub, error) {
 	data, err := hex.DecodeString(s)
 	if err != nil {
 		return nil, err
 	}
-	return wkb.Unmarshal(data)
+	return wkb.UnmarshalStub(data, opts...)
 }
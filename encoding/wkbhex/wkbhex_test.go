@@ -2,6 +2,7 @@ package wkbhex
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/twpayne/go-geom"
@@ -126,6 +127,13 @@ func Test(t *testing.T) {
 			if got, err := Decode(tc.ndr); err != nil || !reflect.DeepEqual(got, tc.g) {
 				t.Errorf("Decode(%#v) == %#v, %v, want %#v, nil", tc.ndr, got, err, tc.g)
 			}
+			upper := strings.ToUpper(tc.ndr)
+			if got, err := EncodeUpper(tc.g, wkb.NDR); err != nil || got != upper {
+				t.Errorf("EncodeUpper(%#v, %#v) == %#v, %#v, want %#v, nil", tc.g, wkb.NDR, got, err, upper)
+			}
+			if got, err := Decode(upper); err != nil || !reflect.DeepEqual(got, tc.g) {
+				t.Errorf("Decode(%#v) == %#v, %v, want %#v, nil", upper, got, err, tc.g)
+			}
 		}
 		if tc.xdr != "" {
 			if got, err := Encode(tc.g, wkb.XDR); err != nil || got != tc.xdr {
@@ -134,6 +142,13 @@ func Test(t *testing.T) {
 			if got, err := Decode(tc.xdr); err != nil || !reflect.DeepEqual(got, tc.g) {
 				t.Errorf("Decode(%#v) == %#v, %v, want %#v, nil", tc.xdr, got, err, tc.g)
 			}
+			upper := strings.ToUpper(tc.xdr)
+			if got, err := EncodeUpper(tc.g, wkb.XDR); err != nil || got != upper {
+				t.Errorf("EncodeUpper(%#v, %#v) == %#v, %#v, want %#v, nil", tc.g, wkb.XDR, got, err, upper)
+			}
+			if got, err := Decode(upper); err != nil || !reflect.DeepEqual(got, tc.g) {
+				t.Errorf("Decode(%#v) == %#v, %v, want %#v, nil", upper, got, err, tc.g)
+			}
 		}
 	}
 }
@@ -1,352 +1,819 @@
 package wkt
 
 import (
-	"errors"
+	"bufio"
 	"fmt"
+	"io"
+	"math"
 	"strconv"
 	"strings"
-	"unicode"
 
 	"github.com/twpayne/go-geom"
 )
 
+// An ErrSyntax is returned when the WKT is malformed.
+type ErrSyntax struct {
+	Pos int
+	Msg string
+}
+
+func (e ErrSyntax) Error() string {
+	return fmt.Sprintf("wkt: syntax error at position %d: %s", e.Pos, e.Msg)
+}
+
+type tokenType int
+
+const (
+	tokenEOF tokenType = iota
+	tokenWord
+	tokenLParen
+	tokenRParen
+	tokenComma
+)
+
+type token struct {
+	t     tokenType
+	pos   int
+	value string
+}
+
+// A lexer splits a WKT into tokens. Words are runs of characters that are not
+// whitespace, parentheses, or commas, and so include both keywords and
+// numbers.
+type lexer struct {
+	s    string
+	pos  int
+	peek *token
+}
+
+func (l *lexer) next() token {
+	if l.peek != nil {
+		t := *l.peek
+		l.peek = nil
+		return t
+	}
+	for l.pos < len(l.s) && isSpace(l.s[l.pos]) {
+		l.pos++
+	}
+	if l.pos == len(l.s) {
+		return token{t: tokenEOF, pos: l.pos}
+	}
+	start := l.pos
+	switch l.s[l.pos] {
+	case '(':
+		l.pos++
+		return token{t: tokenLParen, pos: start, value: "("}
+	case ')':
+		l.pos++
+		return token{t: tokenRParen, pos: start, value: ")"}
+	case ',':
+		l.pos++
+		return token{t: tokenComma, pos: start, value: ","}
+	}
+	for l.pos < len(l.s) && !isSpace(l.s[l.pos]) && !isDelimiter(l.s[l.pos]) {
+		l.pos++
+	}
+	return token{t: tokenWord, pos: start, value: l.s[start:l.pos]}
+}
+
+func (l *lexer) unread(t token) {
+	l.peek = &t
+}
+
+func isDelimiter(c byte) bool {
+	return c == '(' || c == ')' || c == ','
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// A Decoder reads WKTs, one per line, from an input stream, such as that
+// written by an Encoder.
+type Decoder struct {
+	r       *bufio.Reader
+	options options
+}
+
+// NewDecoder returns a new Decoder that reads from r with opts.
+func NewDecoder(r io.Reader, opts ...Option) *Decoder {
+	return &Decoder{
+		r:       bufio.NewReader(r),
+		options: newOptions(opts),
+	}
+}
+
+// Decode reads the next WKT from the stream and returns the corresponding
+// geometry. Blank lines are skipped. It returns io.EOF when there are no more
+// WKTs.
+func (dec *Decoder) Decode() (geom.T, error) {
+	for {
+		line, err := dec.r.ReadString('\n')
+		if strings.TrimSpace(line) != "" {
+			return newDecoder(line, dec.options).decode()
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// A decoder holds the state of a single decode.
+type decoder struct {
+	limiter
+	lexer   lexer
+	options options
+}
+
+// newDecoder returns a new decoder of s with options o.
+func newDecoder(s string, o options) *decoder {
+	return &decoder{
+		limiter: limiter{limits: o.limits},
+		lexer:   lexer{s: s},
+		options: o,
+	}
+}
+
 // decode translates a WKT to the corresponding geometry.
-func decode(wkt string) (geom.T, error) {
-	t, l, err := findTypeAndLayout(wkt)
+func (d *decoder) decode() (geom.T, error) {
+	g, err := d.readGeometry()
+	if err != nil {
+		return nil, err
+	}
+	if t := d.lexer.next(); t.t != tokenEOF {
+		return nil, d.unexpected(t, "end of input")
+	}
+	return g, nil
+}
+
+// A limiter counts the coordinates and geometries decoded and the depth of
+// nested GEOMETRYCOLLECTIONs, and enforces limits.
+type limiter struct {
+	limits    Limits
+	numCoords int
+	numGeoms  int
+	depth     int
+}
+
+// addCoords records that n more coordinates are about to be decoded.
+func (l *limiter) addCoords(n int) error {
+	l.numCoords += n
+	if limit := l.limits.maxCoords(); limit >= 0 && l.numCoords > limit {
+		return ErrLimitExceeded{Name: "coordinates", Limit: limit}
+	}
+	return nil
+}
+
+// addGeoms records that n more geometries are about to be decoded.
+func (l *limiter) addGeoms(n int) error {
+	l.numGeoms += n
+	if limit := l.limits.maxGeometries(); limit >= 0 && l.numGeoms > limit {
+		return ErrLimitExceeded{Name: "geometries", Limit: limit}
+	}
+	return nil
+}
+
+// enter records that the members of a GEOMETRYCOLLECTION are about to be
+// decoded. Each successful call must be followed by a call to leave.
+func (l *limiter) enter() error {
+	if limit := l.limits.maxDepth(); l.depth >= limit {
+		return ErrLimitExceeded{Name: "depth", Limit: limit}
+	}
+	l.depth++
+	return nil
+}
+
+// leave records that the members of a GEOMETRYCOLLECTION have been decoded.
+func (l *limiter) leave() {
+	l.depth--
+}
+
+func (d *decoder) expect(tt tokenType, want string) error {
+	if t := d.lexer.next(); t.t != tt {
+		return d.unexpected(t, want)
+	}
+	return nil
+}
+
+func (d *decoder) unexpected(t token, want string) error {
+	got := strconv.Quote(t.value)
+	if t.t == tokenEOF {
+		got = "end of input"
+	}
+	return ErrSyntax{Pos: t.pos, Msg: fmt.Sprintf("got %s, want %s", got, want)}
+}
+
+// readEmpty reads either EMPTY, in which case it returns true, or an opening
+// parenthesis, in which case it returns false.
+func (d *decoder) readEmpty() (bool, error) {
+	t := d.lexer.next()
+	switch {
+	case t.t == tokenWord && strings.EqualFold(t.value, "EMPTY"):
+		return true, nil
+	case t.t == tokenLParen:
+		return false, nil
+	default:
+		return false, d.unexpected(t, `"(" or "EMPTY"`)
+	}
+}
+
+// readTypeAndLayout reads a geometry type and optional dimension qualifier.
+func (d *decoder) readTypeAndLayout() (string, geom.Layout, error) {
+	t := d.lexer.next()
+	if t.t != tokenWord {
+		return "", geom.NoLayout, d.unexpected(t, "geometry type")
+	}
+	typeString := strings.ToUpper(t.value) + " "
+	switch typeString {
+	case tPoint, tLineString, tPolygon, tMultiPoint, tMultiLineString, tMultiPolygon, tGeometryCollection:
+	case tCircularString, tCompoundCurve, tCurvePolygon, tPolyhedralSurface, tTIN, tTriangle:
+	default:
+		return "", geom.NoLayout, ErrSyntax{Pos: t.pos, Msg: fmt.Sprintf("unknown geometry type %q", t.value)}
+	}
+	return typeString, d.readLayout(geom.XY), nil
+}
+
+// readLayout reads an optional dimension qualifier, returning l if there is
+// none.
+func (d *decoder) readLayout(l geom.Layout) geom.Layout {
+	t := d.lexer.next()
+	if t.t == tokenWord {
+		switch strings.ToUpper(t.value) + " " {
+		case tZ:
+			return geom.XYZ
+		case tM:
+			return geom.XYM
+		case tZm:
+			return geom.XYZM
+		}
+	}
+	d.lexer.unread(t)
+	return l
+}
+
+func (d *decoder) readGeometry() (geom.T, error) {
+	typeString, l, err := d.readTypeAndLayout()
 	if err != nil {
 		return nil, err
 	}
+	if err := d.addGeoms(1); err != nil {
+		return nil, err
+	}
 
-	switch t {
+	switch typeString {
 	case tPoint:
-		coords, _, err := readCoordsDim1(l, wkt)
+		empty, err := d.readEmpty()
 		if err != nil {
 			return nil, err
 		}
-
-		p := geom.NewPointEmpty(l)
-		if len(coords) > 0 {
-			p.MustSetCoords(coords[0])
+		if empty {
+			return geom.NewPointEmpty(l), nil
+		}
+		if err := d.addCoords(1); err != nil {
+			return nil, err
 		}
-		return p, nil
+		nanPointAsEmpty := d.options.nonFinitePolicy == NonFiniteNaNPointAsEmpty
+		flatCoords, err := d.readOrdinates(nil, l.Stride(), nanPointAsEmpty)
+		if err != nil {
+			return nil, err
+		}
+		if err := d.expect(tokenRParen, `")"`); err != nil {
+			return nil, err
+		}
+		if nanPointAsEmpty {
+			numNaNs := 0
+			for _, f := range flatCoords {
+				if math.IsNaN(f) {
+					numNaNs++
+				}
+			}
+			switch numNaNs {
+			case 0:
+			case len(flatCoords):
+				return geom.NewPointEmpty(l), nil
+			default:
+				return nil, ErrNonFinite(math.NaN())
+			}
+		}
+		return geom.NewPointFlat(l, flatCoords), nil
 	case tLineString:
-		coords, _, err := readCoordsDim1(l, wkt)
+		empty, err := d.readEmpty()
 		if err != nil {
 			return nil, err
 		}
-
-		if len(coords) == 0 {
+		if empty {
 			return geom.NewLineString(l), nil
 		}
-
-		isLinearRing := coords[0].Equal(l, coords[(len(coords)-1)])
-		if isLinearRing {
-			lr := geom.NewLinearRing(l).MustSetCoords(coords)
-			return lr, nil
+		flatCoords, err := d.readFlatCoords1(nil, l.Stride())
+		if err != nil {
+			return nil, err
 		}
-
-		ls := geom.NewLineString(l).MustSetCoords(coords)
-		return ls, nil
+		stride := l.Stride()
+		first, last := geom.Coord(flatCoords[:stride]), geom.Coord(flatCoords[len(flatCoords)-stride:])
+		if first.Equal(l, last) {
+			return geom.NewLinearRingFlat(l, flatCoords), nil
+		}
+		return geom.NewLineStringFlat(l, flatCoords), nil
 	case tPolygon:
-		coords, _, err := readCoordsDim2(l, wkt)
+		empty, err := d.readEmpty()
 		if err != nil {
 			return nil, err
 		}
-
-		p := geom.NewPolygon(l)
-		if len(coords) > 0 {
-			p.MustSetCoords(coords)
+		if empty {
+			return geom.NewPolygon(l), nil
 		}
-		return p, nil
+		flatCoords, ends, err := d.readFlatCoords2(nil, nil, l.Stride())
+		if err != nil {
+			return nil, err
+		}
+		return geom.NewPolygonFlat(l, flatCoords, ends), nil
 	case tMultiPoint:
-		coords, _, err := readCoordsDim1(l, wkt)
+		empty, err := d.readEmpty()
 		if err != nil {
 			return nil, err
 		}
-
-		mp := geom.NewMultiPoint(l)
-		if len(coords) > 0 {
-			mp.MustSetCoords(coords)
+		if empty {
+			return geom.NewMultiPoint(l), nil
 		}
-		return mp, nil
+		flatCoords, err := d.readMultiPointFlatCoords(l.Stride())
+		if err != nil {
+			return nil, err
+		}
+		return geom.NewMultiPointFlat(l, flatCoords), nil
 	case tMultiLineString:
-		coords, _, err := readCoordsDim2(l, wkt)
+		empty, err := d.readEmpty()
 		if err != nil {
 			return nil, err
 		}
-
-		mls := geom.NewMultiLineString(l)
-		if len(coords) > 0 {
-			mls.MustSetCoords(coords)
+		if empty {
+			return geom.NewMultiLineString(l), nil
 		}
-		return mls, nil
+		flatCoords, ends, err := d.readFlatCoords2(nil, nil, l.Stride())
+		if err != nil {
+			return nil, err
+		}
+		if err := d.addGeoms(len(ends)); err != nil {
+			return nil, err
+		}
+		return geom.NewMultiLineStringFlat(l, flatCoords, ends), nil
 	case tMultiPolygon:
-		mp := geom.NewMultiPolygon(l)
-		coords, _, err := readCoordsDim3(l, wkt)
+		empty, err := d.readEmpty()
+		if err != nil {
+			return nil, err
+		}
+		if empty {
+			return geom.NewMultiPolygon(l), nil
+		}
+		flatCoords, endss, err := d.readFlatCoords3(l.Stride())
+		if err != nil {
+			return nil, err
+		}
+		return geom.NewMultiPolygonFlat(l, flatCoords, endss), nil
+	case tCircularString:
+		empty, err := d.readEmpty()
+		if err != nil {
+			return nil, err
+		}
+		if empty {
+			return geom.NewCircularString(l), nil
+		}
+		flatCoords, err := d.readFlatCoords1(nil, l.Stride())
+		if err != nil {
+			return nil, err
+		}
+		return geom.NewCircularStringFlat(l, flatCoords), nil
+	case tCompoundCurve:
+		empty, err := d.readEmpty()
 		if err != nil {
 			return nil, err
 		}
-		if len(coords) > 0 {
-			mp.MustSetCoords(coords)
+		if empty {
+			return geom.NewCompoundCurve(l), nil
 		}
-		return mp, nil
-	case tGeometryCollection:
-		return createGeomCollectionForWkt(wkt)
+		return d.readCompoundCurve(l)
+	case tCurvePolygon:
+		empty, err := d.readEmpty()
+		if err != nil {
+			return nil, err
+		}
+		cp := geom.NewCurvePolygon(l)
+		if empty {
+			return cp, nil
+		}
+		for {
+			ring, err := d.readCurve(l, true)
+			if err != nil {
+				return nil, err
+			}
+			if err := cp.Push(ring); err != nil {
+				return nil, err
+			}
+			if more, err := d.readCommaOrRParen(); err != nil {
+				return nil, err
+			} else if !more {
+				return cp, nil
+			}
+		}
+	case tPolyhedralSurface:
+		empty, err := d.readEmpty()
+		if err != nil {
+			return nil, err
+		}
+		if empty {
+			return geom.NewPolyhedralSurface(l), nil
+		}
+		flatCoords, endss, err := d.readFlatCoords3(l.Stride())
+		if err != nil {
+			return nil, err
+		}
+		return geom.NewPolyhedralSurfaceFlat(l, flatCoords, endss), nil
+	case tTIN:
+		empty, err := d.readEmpty()
+		if err != nil {
+			return nil, err
+		}
+		if empty {
+			return geom.NewTIN(l), nil
+		}
+		flatCoords, endss, err := d.readFlatCoords3(l.Stride())
+		if err != nil {
+			return nil, err
+		}
+		return geom.NewTINFlat(l, flatCoords, endss), nil
+	case tTriangle:
+		empty, err := d.readEmpty()
+		if err != nil {
+			return nil, err
+		}
+		if empty {
+			return geom.NewTriangle(l), nil
+		}
+		flatCoords, ends, err := d.readFlatCoords2(nil, nil, l.Stride())
+		if err != nil {
+			return nil, err
+		}
+		return geom.NewTriangleFlat(l, flatCoords, ends), nil
 	default:
-		msg := fmt.Sprintf("Cannot create geometry for unsupported type %s", t)
-		return nil, errors.New(msg)
+		gc, err := d.readGeometryCollection(l)
+		if err != nil {
+			return nil, err
+		}
+		return gc, nil
 	}
 }
 
-func findTypeAndLayout(wkt string) (string, geom.Layout, error) {
-	typeString := ""
-	layout := geom.NoLayout
-
-	switch {
-	case strings.HasPrefix(wkt, tPoint):
-		typeString = tPoint
-	case strings.HasPrefix(wkt, tLineString):
-		typeString = tLineString
-	case strings.HasPrefix(wkt, tPolygon):
-		typeString = tPolygon
-	case strings.HasPrefix(wkt, tMultiPoint):
-		typeString = tMultiPoint
-	case strings.HasPrefix(wkt, tMultiLineString):
-		typeString = tMultiLineString
-	case strings.HasPrefix(wkt, tMultiPolygon):
-		typeString = tMultiPolygon
-	case strings.HasPrefix(wkt, tGeometryCollection):
-		typeString = tGeometryCollection
-	default:
-		return typeString, layout, errors.New("Unknown geometry type in WKT: " + wkt)
+// readCompoundCurve reads the segments of a COMPOUNDCURVE with layout l, after
+// the opening parenthesis has been read.
+func (d *decoder) readCompoundCurve(l geom.Layout) (*geom.CompoundCurve, error) {
+	cc := geom.NewCompoundCurve(l)
+	for {
+		segment, err := d.readCurve(l, false)
+		if err != nil {
+			return nil, err
+		}
+		if err := cc.Push(segment); err != nil {
+			return nil, err
+		}
+		if more, err := d.readCommaOrRParen(); err != nil {
+			return nil, err
+		} else if !more {
+			return cc, nil
+		}
 	}
+}
 
-	switch {
-	case strings.HasPrefix(wkt, (typeString + tZm)):
-		layout = geom.XYZM
-	case strings.HasPrefix(wkt, (typeString + tM)):
-		layout = geom.XYM
-	case strings.HasPrefix(wkt, (typeString + tZ)):
-		layout = geom.XYZ
-	default:
-		layout = geom.XY
+// readCurve reads a segment of a COMPOUNDCURVE or, if compound is true, a
+// ring of a CURVEPOLYGON, with layout l. A curve is either a parenthesized
+// list of coordinates of a line string, or a CIRCULARSTRING or, if compound
+// is true, a COMPOUNDCURVE, whose dimension qualifier may be omitted.
+func (d *decoder) readCurve(l geom.Layout, compound bool) (geom.T, error) {
+	if err := d.addGeoms(1); err != nil {
+		return nil, err
 	}
-
-	return typeString, layout, nil
+	t := d.lexer.next()
+	if t.t == tokenLParen {
+		flatCoords, err := d.readFlatCoords1(nil, l.Stride())
+		if err != nil {
+			return nil, err
+		}
+		return geom.NewLineStringFlat(l, flatCoords), nil
+	}
+	want := `"(" or "CIRCULARSTRING"`
+	if compound {
+		want = `"(", "CIRCULARSTRING", or "COMPOUNDCURVE"`
+	}
+	if t.t != tokenWord {
+		return nil, d.unexpected(t, want)
+	}
+	typeString := strings.ToUpper(t.value) + " "
+	if typeString != tCircularString && (!compound || typeString != tCompoundCurve) {
+		return nil, d.unexpected(t, want)
+	}
+	if layout := d.readLayout(l); layout != l {
+		return nil, geom.ErrLayoutMismatch{Got: layout, Want: l}
+	}
+	if err := d.expect(tokenLParen, `"("`); err != nil {
+		return nil, err
+	}
+	if typeString == tCompoundCurve {
+		return d.readCompoundCurve(l)
+	}
+	flatCoords, err := d.readFlatCoords1(nil, l.Stride())
+	if err != nil {
+		return nil, err
+	}
+	return geom.NewCircularStringFlat(l, flatCoords), nil
 }
 
-func createGeomCollectionForWkt(wkt string) (*geom.GeometryCollection, error) {
+// readGeometryCollection reads a GEOMETRYCOLLECTION with layout l, applying the
+// mixed layout policy to its members.
+func (d *decoder) readGeometryCollection(l geom.Layout) (*geom.GeometryCollection, error) {
+	empty, err := d.readEmpty()
+	if err != nil {
+		return nil, err
+	}
 	gc := geom.NewGeometryCollection()
-
-	isEmpty := strings.HasSuffix(wkt, tEmpty)
-	if isEmpty {
+	if empty {
 		return gc, nil
 	}
 
-	content, _, err := braceContentAndRest(wkt)
-	if err != nil {
+	if err := d.enter(); err != nil {
 		return nil, err
 	}
+	defer d.leave()
 
+	var geoms []geom.T
 	for {
-		geomContent, rest, err := typeContentAndRestStartingWithLetter(content)
+		g, err := d.readGeometry()
 		if err != nil {
 			return nil, err
 		}
-
-		g, err := decode(geomContent)
-		if err != nil {
+		geoms = append(geoms, g)
+		if more, err := d.readCommaOrRParen(); err != nil {
 			return nil, err
-		}
-
-		gc.MustPush(g)
-
-		content = rest
-		if content == "" {
+		} else if !more {
 			break
 		}
 	}
 
+	switch d.options.mixedLayoutPolicy {
+	case MixedLayoutsError:
+		want := geom.NoLayout
+		if l != geom.XY {
+			want = l
+		}
+		for _, g := range geoms {
+			switch got := g.Layout(); {
+			case got == geom.NoLayout:
+			case want == geom.NoLayout:
+				want = got
+			case got != want:
+				return nil, geom.ErrLayoutMismatch{Got: got, Want: want}
+			}
+		}
+	case MixedLayoutsPromote:
+		layout := l
+		for _, g := range geoms {
+			layout = coveringLayout(layout, g.Layout())
+		}
+		for i, g := range geoms {
+			if geoms[i], err = promote(g, layout); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := gc.Push(geoms...); err != nil {
+		return nil, err
+	}
 	return gc, nil
 }
 
-func readCoordsDim1(l geom.Layout, wkt string) ([]geom.Coord, string, error) {
-	isEmpty := strings.HasSuffix(wkt, tEmpty)
-	if isEmpty {
-		return []geom.Coord{}, "", nil
+// coveringLayout returns the smallest layout that covers both l1 and l2.
+func coveringLayout(l1, l2 geom.Layout) geom.Layout {
+	switch {
+	case l1 == geom.NoLayout:
+		return l2
+	case l2 == geom.NoLayout:
+		return l1
+	case l1 == geom.XYZ && l2 == geom.XYM, l1 == geom.XYM && l2 == geom.XYZ:
+		return geom.XYZM
+	case l1 > l2:
+		return l1
+	default:
+		return l2
 	}
+}
 
-	braceContent, rest, err := braceContentAndRestStartingWithOpeningBrace(wkt)
-	if err != nil {
-		return nil, rest, err
+// promote returns g converted to layout, which must cover g's layout, padding
+// missing ordinates with zero. Empty GEOMETRYCOLLECTIONs, which have no
+// layout, are returned unchanged.
+func promote(g geom.T, layout geom.Layout) (geom.T, error) {
+	from := g.Layout()
+	if from == layout || from == geom.NoLayout {
+		return g, nil
+	}
+	if gc, ok := g.(*geom.GeometryCollection); ok {
+		promoted := geom.NewGeometryCollection()
+		for _, g := range gc.Geoms() {
+			g, err := promote(g, layout)
+			if err != nil {
+				return nil, err
+			}
+			if err := promoted.Push(g); err != nil {
+				return nil, err
+			}
+		}
+		return promoted, nil
 	}
 
-	coords, err := coordsFromBraceContent(braceContent, l)
-	if err != nil {
-		return nil, rest, err
+	fromStride, toStride := from.Stride(), layout.Stride()
+	flatCoords := g.FlatCoords()
+	promotedFlatCoords := make([]float64, len(flatCoords)/fromStride*toStride)
+	fromZ, fromM := from.ZIndex(), from.MIndex()
+	toZ, toM := layout.ZIndex(), layout.MIndex()
+	for i, j := 0, 0; i < len(flatCoords); i, j = i+fromStride, j+toStride {
+		promotedFlatCoords[j] = flatCoords[i]
+		promotedFlatCoords[j+1] = flatCoords[i+1]
+		if fromZ != -1 {
+			promotedFlatCoords[j+toZ] = flatCoords[i+fromZ]
+		}
+		if fromM != -1 {
+			promotedFlatCoords[j+toM] = flatCoords[i+fromM]
+		}
+	}
+	promoteEnds := func(ends []int) []int {
+		promotedEnds := make([]int, len(ends))
+		for i, end := range ends {
+			promotedEnds[i] = end / fromStride * toStride
+		}
+		return promotedEnds
 	}
 
-	return coords, rest, nil
+	switch g := g.(type) {
+	case *geom.Point:
+		return geom.NewPointFlat(layout, promotedFlatCoords), nil
+	case *geom.LineString:
+		return geom.NewLineStringFlat(layout, promotedFlatCoords), nil
+	case *geom.LinearRing:
+		return geom.NewLinearRingFlat(layout, promotedFlatCoords), nil
+	case *geom.Polygon:
+		return geom.NewPolygonFlat(layout, promotedFlatCoords, promoteEnds(g.Ends())), nil
+	case *geom.MultiPoint:
+		return geom.NewMultiPointFlat(layout, promotedFlatCoords), nil
+	case *geom.MultiLineString:
+		return geom.NewMultiLineStringFlat(layout, promotedFlatCoords, promoteEnds(g.Ends())), nil
+	case *geom.MultiPolygon:
+		endss := make([][]int, len(g.Endss()))
+		for i, ends := range g.Endss() {
+			endss[i] = promoteEnds(ends)
+		}
+		return geom.NewMultiPolygonFlat(layout, promotedFlatCoords, endss), nil
+	default:
+		return g, nil
+	}
 }
 
-func readCoordsDim2(l geom.Layout, wkt string) ([][]geom.Coord, string, error) {
-	coordsDim2 := [][]geom.Coord{}
-	isEmpty := strings.HasSuffix(wkt, tEmpty)
-	if isEmpty {
-		return coordsDim2, "", nil
+// readCommaOrRParen reads either a comma, in which case it returns true, or a
+// closing parenthesis, in which case it returns false.
+func (d *decoder) readCommaOrRParen() (bool, error) {
+	switch t := d.lexer.next(); t.t {
+	case tokenComma:
+		return true, nil
+	case tokenRParen:
+		return false, nil
+	default:
+		return false, d.unexpected(t, `"," or ")"`)
 	}
+}
 
-	contentDim2, restDim2, err := braceContentAndRestStartingWithOpeningBrace(wkt)
-	if err != nil {
-		return nil, restDim2, err
+// readCoord reads a single coordinate of stride ordinates and appends it to
+// flatCoords.
+func (d *decoder) readCoord(flatCoords []float64, stride int) ([]float64, error) {
+	if err := d.addCoords(1); err != nil {
+		return nil, err
 	}
+	return d.readOrdinates(flatCoords, stride, false)
+}
 
-	for {
-		coordsDim1, restDim1, err := readCoordsDim1(l, contentDim2)
+// readOrdinates reads stride ordinates, appending them to flatCoords. NaNs are
+// permitted if the policy allows non-finite ordinates or if allowNaN is true.
+func (d *decoder) readOrdinates(flatCoords []float64, stride int, allowNaN bool) ([]float64, error) {
+	for i := 0; i < stride; i++ {
+		t := d.lexer.next()
+		if t.t != tokenWord {
+			return nil, d.unexpected(t, "number")
+		}
+		f, err := strconv.ParseFloat(t.value, 64)
 		if err != nil {
-			return coordsDim2, restDim2, err
+			return nil, ErrSyntax{Pos: t.pos, Msg: fmt.Sprintf("invalid number %q", t.value)}
 		}
-
-		coordsDim2 = append(coordsDim2, coordsDim1)
-
-		contentDim2 = restDim1
-		if contentDim2 == "" {
-			break
+		if d.options.nonFinitePolicy != NonFiniteAllow && (math.IsInf(f, 0) || math.IsNaN(f) && !allowNaN) {
+			return nil, ErrNonFinite(f)
 		}
+		flatCoords = append(flatCoords, f)
 	}
-
-	return coordsDim2, restDim2, nil
-}
-
-func readCoordsDim3(l geom.Layout, wkt string) ([][][]geom.Coord, string, error) {
-	coordsDim3 := [][][]geom.Coord{}
-	isEmpty := strings.HasSuffix(wkt, tEmpty)
-	if isEmpty {
-		return coordsDim3, "", nil
-	}
-
-	contentDim3, restDim3, err := braceContentAndRestStartingWithOpeningBrace(wkt)
-	if err != nil {
-		return nil, restDim3, err
+	t := d.lexer.next()
+	if t.t == tokenWord {
+		return nil, ErrSyntax{Pos: t.pos, Msg: fmt.Sprintf("expected coordinates with dimension %d", stride)}
 	}
+	d.lexer.unread(t)
+	return flatCoords, nil
+}
 
+// readFlatCoords1 reads a parenthesized list of coordinates, after the
+// opening parenthesis has been read, and appends them to flatCoords.
+func (d *decoder) readFlatCoords1(flatCoords []float64, stride int) ([]float64, error) {
 	for {
-		coordsDim2, restDim2, err := readCoordsDim2(l, contentDim3)
+		var err error
+		flatCoords, err = d.readCoord(flatCoords, stride)
 		if err != nil {
-			return coordsDim3, restDim3, err
+			return nil, err
 		}
-
-		coordsDim3 = append(coordsDim3, coordsDim2)
-
-		contentDim3 = restDim2
-		if contentDim3 == "" {
-			break
+		if more, err := d.readCommaOrRParen(); err != nil {
+			return nil, err
+		} else if !more {
+			return flatCoords, nil
 		}
 	}
-
-	return coordsDim3, restDim3, nil
 }
 
-func coordsFromBraceContent(s string, l geom.Layout) ([]geom.Coord, error) {
-	coords := []geom.Coord{}
-
-	coordStrings := strings.Split(s, ",")
-	for _, coordStr := range coordStrings {
-		coordElems := strings.Split(strings.TrimSpace(coordStr), " ")
-		if len(coordElems) != l.Stride() {
-			msg := fmt.Sprintf("Expected coordinates with dimension %v. Found: %v", l.Stride(), s)
-			return nil, errors.New(msg)
+// readFlatCoords2 reads a parenthesized list of lists of coordinates, after
+// the opening parenthesis has been read, and appends them to flatCoords and
+// ends.
+func (d *decoder) readFlatCoords2(flatCoords []float64, ends []int, stride int) ([]float64, []int, error) {
+	for {
+		if err := d.expect(tokenLParen, `"("`); err != nil {
+			return nil, nil, err
 		}
-
-		coordVals := make([]float64, l.Stride())
-		for i, val := range coordElems {
-			f, err := strconv.ParseFloat(val, 64)
-			if err != nil {
-				msg := fmt.Sprintf("Found invalid coordinate value in WKT String: %v \n", val)
-				return nil, errors.New(msg)
-			}
-			coordVals[i] = f
-		}
-		coords = append(coords, coordVals)
-	}
-	return coords, nil
-}
-
-// braceContentAndRest returns:
-//
-// -the string between the first opening brace "(" and its closing brace ")"
-//
-// -the rest of the input string (starting with the next opening brace "(")
-func braceContentAndRest(s string) (string, string, error) {
-	braceOpenIdx := -1
-	braceCloseIdx := -1
-	braceOpenCount := 0
-	braceCloseCount := 0
-	for i, c := range s {
-		char := string(c)
-		if char == "(" {
-			if braceOpenCount == 0 {
-				braceOpenIdx = i
-			}
-			braceOpenCount++
-		} else if char == ")" {
-			braceCloseCount++
-			if braceCloseCount == braceOpenCount {
-				braceCloseIdx = i
-				break
-			}
+		var err error
+		flatCoords, err = d.readFlatCoords1(flatCoords, stride)
+		if err != nil {
+			return nil, nil, err
+		}
+		ends = append(ends, len(flatCoords))
+		if more, err := d.readCommaOrRParen(); err != nil {
+			return nil, nil, err
+		} else if !more {
+			return flatCoords, ends, nil
 		}
 	}
-
-	if braceOpenIdx < 0 || braceCloseIdx < 0 {
-		msg := fmt.Sprintf("Malformatted braces in WKT string: %s", s)
-		return "", "", errors.New(msg)
-	}
-
-	braceContent := s[(braceOpenIdx + 1):braceCloseIdx]
-	rest := s[braceCloseIdx:]
-
-	return braceContent, rest, nil
 }
 
-func braceContentAndRestStartingWithOpeningBrace(s string) (string, string, error) {
-	content, rest, err := braceContentAndRest(s)
-	if err != nil {
-		return content, rest, err
-	}
-
-	nextOpeningBraceIdx := strings.Index(rest, "(")
-	if nextOpeningBraceIdx > -1 {
-		rest = rest[nextOpeningBraceIdx:]
-	} else {
-		rest = ""
+// readFlatCoords3 reads a parenthesized list of lists of lists of
+// coordinates, after the opening parenthesis has been read.
+func (d *decoder) readFlatCoords3(stride int) ([]float64, [][]int, error) {
+	var flatCoords []float64
+	var endss [][]int
+	for {
+		if err := d.addGeoms(1); err != nil {
+			return nil, nil, err
+		}
+		if err := d.expect(tokenLParen, `"("`); err != nil {
+			return nil, nil, err
+		}
+		var ends []int
+		var err error
+		flatCoords, ends, err = d.readFlatCoords2(flatCoords, nil, stride)
+		if err != nil {
+			return nil, nil, err
+		}
+		endss = append(endss, ends)
+		if more, err := d.readCommaOrRParen(); err != nil {
+			return nil, nil, err
+		} else if !more {
+			return flatCoords, endss, nil
+		}
 	}
-	return content, rest, nil
 }
 
-func typeContentAndRestStartingWithLetter(s string) (string, string, error) {
-	content, rest, err := braceContentAndRest(s)
-	if err != nil {
-		return content, rest, err
-	}
-
-	t, _, err := findTypeAndLayout(s)
-	if err != nil {
-		return content, rest, err
-	}
-	content = t + "(" + content + ")"
-
-	nextLetterIdx := -1
-	for i, char := range rest {
-		if unicode.IsLetter(char) {
-			nextLetterIdx = i
-			break
+// readMultiPointFlatCoords reads the points of a MULTIPOINT, after the
+// opening parenthesis has been read. Points may optionally be enclosed in
+// parentheses.
+func (d *decoder) readMultiPointFlatCoords(stride int) ([]float64, error) {
+	var flatCoords []float64
+	for {
+		if err := d.addGeoms(1); err != nil {
+			return nil, err
+		}
+		t := d.lexer.next()
+		parenthesized := t.t == tokenLParen
+		if !parenthesized {
+			d.lexer.unread(t)
+		}
+		var err error
+		flatCoords, err = d.readCoord(flatCoords, stride)
+		if err != nil {
+			return nil, err
+		}
+		if parenthesized {
+			if err := d.expect(tokenRParen, `")"`); err != nil {
+				return nil, err
+			}
+		}
+		if more, err := d.readCommaOrRParen(); err != nil {
+			return nil, err
+		} else if !more {
+			return flatCoords, nil
 		}
 	}
-	if nextLetterIdx > -1 {
-		rest = rest[nextLetterIdx:]
-	} else {
-		rest = ""
-	}
-	return content, rest, nil
 }
@@ -1,22 +1,75 @@
 package wkt
 
 import (
-	"bytes"
+	"bufio"
+	"io"
+	"math"
 	"strconv"
+	"strings"
 
 	"github.com/twpayne/go-geom"
 )
 
+// A writer is implemented by both *strings.Builder and *bufio.Writer.
+type writer interface {
+	io.Writer
+	WriteRune(rune) (int, error)
+	WriteString(string) (int, error)
+}
+
+// An Encoder writes WKT to an output stream.
+type Encoder struct {
+	w       *bufio.Writer
+	options options
+}
+
+// NewEncoder returns a new Encoder that writes to w with opts.
+func NewEncoder(w io.Writer, opts ...Option) *Encoder {
+	return &Encoder{
+		w:       bufio.NewWriter(w),
+		options: newOptions(opts),
+	}
+}
+
+// Encode writes the WKT of g, followed by a newline, to the stream.
+// Coordinates are written as they are formatted, so the WKT is never held in
+// memory in its entirety. If Encode returns an error then a partial WKT may
+// have been written.
+func (e *Encoder) Encode(g geom.T) error {
+	enc := &encoder{
+		w:       e.w,
+		options: e.options,
+	}
+	if err := enc.write(g); err != nil {
+		return err
+	}
+	if err := e.w.WriteByte('\n'); err != nil {
+		return err
+	}
+	return e.w.Flush()
+}
+
+// An encoder holds the state of a single encode.
+type encoder struct {
+	w       writer
+	options options
+	buf     []byte
+}
+
 // encode translates a geometry to the corresponding WKT.
-func encode(g geom.T) (string, error) {
-	b := &bytes.Buffer{}
-	if err := write(b, g); err != nil {
+func encode(g geom.T, o options) (string, error) {
+	b := &strings.Builder{}
+	enc := &encoder{
+		w:       b,
+		options: o,
+	}
+	if err := enc.write(g); err != nil {
 		return "", err
 	}
 	return b.String(), nil
 }
 
-func write(b *bytes.Buffer, g geom.T) error {
+func (e *encoder) write(g geom.T) error {
 	typeString := ""
 	switch g := g.(type) {
 	case *geom.Point:
@@ -33,6 +86,18 @@ func write(b *bytes.Buffer, g geom.T) error {
 		typeString = tMultiPolygon
 	case *geom.GeometryCollection:
 		typeString = tGeometryCollection
+	case *geom.CircularString:
+		typeString = tCircularString
+	case *geom.CompoundCurve:
+		typeString = tCompoundCurve
+	case *geom.CurvePolygon:
+		typeString = tCurvePolygon
+	case *geom.PolyhedralSurface:
+		typeString = tPolyhedralSurface
+	case *geom.TIN:
+		typeString = tTIN
+	case *geom.Triangle:
+		typeString = tTriangle
 	default:
 		return geom.ErrUnsupportedType{Value: g}
 	}
@@ -53,152 +118,255 @@ func write(b *bytes.Buffer, g geom.T) error {
 	default:
 		return geom.ErrUnsupportedLayout(layout)
 	}
-	if _, err := b.WriteString(typeString); err != nil {
+	if _, err := e.w.WriteString(typeString); err != nil {
 		return err
 	}
 	switch g := g.(type) {
 	case *geom.Point:
 		if g.Empty() {
-			return writeEMPTY(b)
+			if e.options.nonFinitePolicy == NonFiniteNaNPointAsEmpty {
+				return e.writeNaNPoint(layout.Stride())
+			}
+			return e.writeEMPTY()
 		}
-		return writeFlatCoords0(b, g.FlatCoords(), layout.Stride())
+		return e.writeFlatCoords0(g.FlatCoords(), layout.Stride())
 	case *geom.LineString:
 		if g.Empty() {
-			return writeEMPTY(b)
+			return e.writeEMPTY()
 		}
-		return writeFlatCoords1(b, g.FlatCoords(), layout.Stride())
+		return e.writeFlatCoords1(g.FlatCoords(), layout.Stride())
 	case *geom.LinearRing:
 		if g.Empty() {
-			return writeEMPTY(b)
+			return e.writeEMPTY()
 		}
-		return writeFlatCoords1(b, g.FlatCoords(), layout.Stride())
+		return e.writeFlatCoords1(g.FlatCoords(), layout.Stride())
 	case *geom.Polygon:
 		if g.Empty() {
-			return writeEMPTY(b)
+			return e.writeEMPTY()
 		}
-		return writeFlatCoords2(b, g.FlatCoords(), 0, g.Ends(), layout.Stride())
+		return e.writeFlatCoords2(g.FlatCoords(), 0, g.Ends(), layout.Stride())
 	case *geom.MultiPoint:
 		if g.Empty() {
-			return writeEMPTY(b)
+			return e.writeEMPTY()
 		}
-		return writeFlatCoords1(b, g.FlatCoords(), layout.Stride())
+		return e.writeFlatCoords1(g.FlatCoords(), layout.Stride())
 	case *geom.MultiLineString:
 		if g.Empty() {
-			return writeEMPTY(b)
+			return e.writeEMPTY()
 		}
-		return writeFlatCoords2(b, g.FlatCoords(), 0, g.Ends(), layout.Stride())
+		return e.writeFlatCoords2(g.FlatCoords(), 0, g.Ends(), layout.Stride())
 	case *geom.MultiPolygon:
 		if g.Empty() {
-			return writeEMPTY(b)
+			return e.writeEMPTY()
 		}
-		return writeFlatCoords3(b, g.FlatCoords(), g.Endss(), layout.Stride())
+		return e.writeFlatCoords3(g.FlatCoords(), g.Endss(), layout.Stride())
 	case *geom.GeometryCollection:
 		if g.Empty() {
-			return writeEMPTY(b)
+			return e.writeEMPTY()
 		}
-		if _, err := b.WriteRune('('); err != nil {
+		if _, err := e.w.WriteRune('('); err != nil {
 			return err
 		}
 		for i, g := range g.Geoms() {
 			if i != 0 {
-				if _, err := b.WriteString(", "); err != nil {
+				if _, err := e.w.WriteString(", "); err != nil {
 					return err
 				}
 			}
-			if err := write(b, g); err != nil {
+			if err := e.write(g); err != nil {
 				return err
 			}
 		}
-		_, err := b.WriteRune(')')
+		_, err := e.w.WriteRune(')')
 		return err
+	case *geom.CircularString:
+		if g.Empty() {
+			return e.writeEMPTY()
+		}
+		return e.writeFlatCoords1(g.FlatCoords(), layout.Stride())
+	case *geom.CompoundCurve:
+		if g.Empty() {
+			return e.writeEMPTY()
+		}
+		return e.writeCurves(g.NumSegments(), g.Segment)
+	case *geom.CurvePolygon:
+		if g.Empty() {
+			return e.writeEMPTY()
+		}
+		return e.writeCurves(g.NumRings(), g.Ring)
+	case *geom.PolyhedralSurface:
+		if g.Empty() {
+			return e.writeEMPTY()
+		}
+		return e.writeFlatCoords3(g.FlatCoords(), g.Endss(), layout.Stride())
+	case *geom.TIN:
+		if g.Empty() {
+			return e.writeEMPTY()
+		}
+		return e.writeFlatCoords3(g.FlatCoords(), g.Endss(), layout.Stride())
+	case *geom.Triangle:
+		if g.Empty() {
+			return e.writeEMPTY()
+		}
+		return e.writeFlatCoords2(g.FlatCoords(), 0, g.Ends(), layout.Stride())
 	}
 	return nil
 }
 
-func writeCoord(b *bytes.Buffer, coord []float64) error {
+// writeCurves writes the n curves returned by curve, which are the segments
+// of a COMPOUNDCURVE or the rings of a CURVEPOLYGON. LineStrings are written
+// as parenthesized lists of coordinates.
+func (e *encoder) writeCurves(n int, curve func(int) geom.T) error {
+	if _, err := e.w.WriteRune('('); err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		if i != 0 {
+			if _, err := e.w.WriteString(", "); err != nil {
+				return err
+			}
+		}
+		var err error
+		c := curve(i)
+		if ls, ok := c.(*geom.LineString); ok {
+			err = e.writeFlatCoords1(ls.FlatCoords(), ls.Stride())
+		} else {
+			err = e.write(c)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	_, err := e.w.WriteRune(')')
+	return err
+}
+
+func (e *encoder) writeCoord(coord []float64) error {
 	for i, x := range coord {
 		if i != 0 {
-			if _, err := b.WriteRune(' '); err != nil {
+			if _, err := e.w.WriteRune(' '); err != nil {
 				return err
 			}
 		}
-		if _, err := b.WriteString(strconv.FormatFloat(x, 'f', -1, 64)); err != nil {
+		if e.options.nonFinitePolicy != NonFiniteAllow && (math.IsNaN(x) || math.IsInf(x, 0)) {
+			return ErrNonFinite(x)
+		}
+		e.buf = e.appendFloat(e.buf[:0], x)
+		if _, err := e.w.Write(e.buf); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-//nolint:interfacer
-func writeEMPTY(b *bytes.Buffer) error {
-	_, err := b.WriteString(tEmpty)
+// appendFloat appends the shortest representation of x to buf, rounded to at
+// most e.options.maxDecimalDigits decimal places if that is non-negative.
+func (e *encoder) appendFloat(buf []byte, x float64) []byte {
+	if e.options.maxDecimalDigits < 0 {
+		return strconv.AppendFloat(buf, x, 'f', -1, 64)
+	}
+	buf = strconv.AppendFloat(buf, x, 'f', e.options.maxDecimalDigits, 64)
+	if e.options.maxDecimalDigits > 0 {
+		for buf[len(buf)-1] == '0' {
+			buf = buf[:len(buf)-1]
+		}
+		if buf[len(buf)-1] == '.' {
+			buf = buf[:len(buf)-1]
+		}
+	}
+	if len(buf) == 2 && buf[0] == '-' && buf[1] == '0' {
+		buf = append(buf[:0], '0')
+	}
+	return buf
+}
+
+func (e *encoder) writeNaNPoint(stride int) error {
+	if _, err := e.w.WriteRune('('); err != nil {
+		return err
+	}
+	for i := 0; i < stride; i++ {
+		if i != 0 {
+			if _, err := e.w.WriteRune(' '); err != nil {
+				return err
+			}
+		}
+		if _, err := e.w.WriteString("NaN"); err != nil {
+			return err
+		}
+	}
+	_, err := e.w.WriteRune(')')
+	return err
+}
+
+func (e *encoder) writeEMPTY() error {
+	_, err := e.w.WriteString(tEmpty)
 	return err
 }
 
-func writeFlatCoords0(b *bytes.Buffer, flatCoords []float64, stride int) error {
-	if _, err := b.WriteRune('('); err != nil {
+func (e *encoder) writeFlatCoords0(flatCoords []float64, stride int) error {
+	if _, err := e.w.WriteRune('('); err != nil {
 		return err
 	}
-	if err := writeCoord(b, flatCoords[:stride]); err != nil {
+	if err := e.writeCoord(flatCoords[:stride]); err != nil {
 		return err
 	}
-	_, err := b.WriteRune(')')
+	_, err := e.w.WriteRune(')')
 	return err
 }
 
-func writeFlatCoords1(b *bytes.Buffer, flatCoords []float64, stride int) error {
-	if _, err := b.WriteRune('('); err != nil {
+func (e *encoder) writeFlatCoords1(flatCoords []float64, stride int) error {
+	if _, err := e.w.WriteRune('('); err != nil {
 		return err
 	}
 	for i, n := 0, len(flatCoords); i < n; i += stride {
 		if i != 0 {
-			if _, err := b.WriteString(", "); err != nil {
+			if _, err := e.w.WriteString(", "); err != nil {
 				return err
 			}
 		}
-		if err := writeCoord(b, flatCoords[i:i+stride]); err != nil {
+		if err := e.writeCoord(flatCoords[i : i+stride]); err != nil {
 			return err
 		}
 	}
-	_, err := b.WriteRune(')')
+	_, err := e.w.WriteRune(')')
 	return err
 }
 
-func writeFlatCoords2(b *bytes.Buffer, flatCoords []float64, start int, ends []int, stride int) error {
-	if _, err := b.WriteRune('('); err != nil {
+func (e *encoder) writeFlatCoords2(flatCoords []float64, start int, ends []int, stride int) error {
+	if _, err := e.w.WriteRune('('); err != nil {
 		return err
 	}
 	for i, end := range ends {
 		if i != 0 {
-			if _, err := b.WriteString(", "); err != nil {
+			if _, err := e.w.WriteString(", "); err != nil {
 				return err
 			}
 		}
-		if err := writeFlatCoords1(b, flatCoords[start:end], stride); err != nil {
+		if err := e.writeFlatCoords1(flatCoords[start:end], stride); err != nil {
 			return err
 		}
 		start = end
 	}
-	_, err := b.WriteRune(')')
+	_, err := e.w.WriteRune(')')
 	return err
 }
 
-func writeFlatCoords3(b *bytes.Buffer, flatCoords []float64, endss [][]int, stride int) error {
-	if _, err := b.WriteRune('('); err != nil {
+func (e *encoder) writeFlatCoords3(flatCoords []float64, endss [][]int, stride int) error {
+	if _, err := e.w.WriteRune('('); err != nil {
 		return err
 	}
 	start := 0
 	for i, ends := range endss {
 		if i != 0 {
-			if _, err := b.WriteString(", "); err != nil {
+			if _, err := e.w.WriteString(", "); err != nil {
 				return err
 			}
 		}
-		if err := writeFlatCoords2(b, flatCoords, start, ends, stride); err != nil {
+		if err := e.writeFlatCoords2(flatCoords, start, ends, stride); err != nil {
 			return err
 		}
 		start = ends[len(ends)-1]
 	}
-	_, err := b.WriteRune(')')
+	_, err := e.w.WriteRune(')')
 	return err
 }
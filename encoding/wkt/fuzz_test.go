@@ -0,0 +1,36 @@
+//go:build go1.18
+// +build go1.18
+
+package wkt
+
+import "testing"
+
+func FuzzUnmarshal(f *testing.F) {
+	for _, s := range []string{
+		"POINT EMPTY",
+		"POINT (1 2)",
+		"POINT Z (1 2 3)",
+		"LINESTRING (1 2, 3 4)",
+		"POLYGON ((1 2, 3 4, 5 6, 1 2), (7 8, 9 10, 11 12, 7 8))",
+		"MULTIPOINT ZM (1 2 1 42, 3 4 1 43)",
+		"MULTILINESTRING ((1 2, 3 4), (5 6, 7 8))",
+		"MULTIPOLYGON (((1 2, 3 4, 5 6)), ((7 8, 9 10, 11 12)))",
+		"GEOMETRYCOLLECTION (POINT (1 2), LINESTRING (3 4, 5 6))",
+		"GEOMETRYCOLLECTION (POINT EMPTY, GEOMETRYCOLLECTION (MULTIPOINT ((1 2))))",
+	} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		g, err := Unmarshal(s)
+		if err != nil {
+			return
+		}
+		s2, err := Marshal(g)
+		if err != nil {
+			t.Fatalf("Marshal(%#v) == _, %v, want _, nil", g, err)
+		}
+		if _, err := Unmarshal(s2); err != nil {
+			t.Errorf("Unmarshal(%q) == _, %v, want _, nil", s2, err)
+		}
+	})
+}
@@ -0,0 +1,90 @@
+package wkt
+
+// An Option configures encoding or decoding. Options that do not apply to an
+// operation are ignored, so the same options can be shared between Marshal
+// and Unmarshal.
+type Option func(*options)
+
+type options struct {
+	limits            Limits
+	maxDecimalDigits  int
+	mixedLayoutPolicy MixedLayoutPolicy
+	nonFinitePolicy   NonFinitePolicy
+}
+
+// A MixedLayoutPolicy determines how GEOMETRYCOLLECTIONs whose members have
+// different layouts, such as GEOMETRYCOLLECTION (POINT Z (1 2 3), POINT (4
+// 5)), are decoded.
+type MixedLayoutPolicy int
+
+const (
+	// MixedLayoutsPreserve decodes each member with its own layout. This is
+	// the default.
+	MixedLayoutsPreserve MixedLayoutPolicy = iota
+	// MixedLayoutsError returns a geom.ErrLayoutMismatch if any member's
+	// layout differs from that of the first member or from the
+	// collection's own explicit Z, M, or ZM layout.
+	MixedLayoutsError
+	// MixedLayoutsPromote converts every member to the smallest layout that
+	// covers the layouts of all members and the collection's own layout,
+	// padding missing ordinates with zero.
+	MixedLayoutsPromote
+)
+
+// A NonFinitePolicy determines how NaN and infinite ordinates are handled.
+type NonFinitePolicy int
+
+const (
+	// NonFiniteAllow decodes and encodes non-finite ordinates as NaN, +Inf,
+	// and -Inf. This is the default.
+	NonFiniteAllow NonFinitePolicy = iota
+	// NonFiniteError returns an ErrNonFinite for any non-finite ordinate.
+	NonFiniteError
+	// NonFiniteNaNPointAsEmpty follows the convention that a POINT whose
+	// ordinates are all NaN is empty. Such POINTs are decoded as empty
+	// Points, and empty Points are encoded as such POINTs. Any other
+	// non-finite ordinate returns an ErrNonFinite.
+	NonFiniteNaNPointAsEmpty
+)
+
+func newOptions(opts []Option) options {
+	o := options{
+		maxDecimalDigits: -1,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithLimits limits the resources used when decoding. The default is the zero
+// Limits, i.e. DefaultMaxCoords, DefaultMaxGeometries, and DefaultMaxDepth.
+func WithLimits(limits Limits) Option {
+	return func(o *options) {
+		o.limits = limits
+	}
+}
+
+// WithMaxDecimalDigits rounds encoded ordinates to at most n decimal places
+// and removes trailing zeros. A negative n, the default, encodes each ordinate
+// with the fewest digits that represent it exactly.
+func WithMaxDecimalDigits(n int) Option {
+	return func(o *options) {
+		o.maxDecimalDigits = n
+	}
+}
+
+// WithMixedLayoutPolicy sets the policy for decoding GEOMETRYCOLLECTIONs whose
+// members have different layouts.
+func WithMixedLayoutPolicy(policy MixedLayoutPolicy) Option {
+	return func(o *options) {
+		o.mixedLayoutPolicy = policy
+	}
+}
+
+// WithNonFinitePolicy sets the policy for NaN and infinite ordinates.
+func WithNonFinitePolicy(policy NonFinitePolicy) Option {
+	return func(o *options) {
+		o.nonFinitePolicy = policy
+	}
+}
@@ -0,0 +1,618 @@
+package wkt
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"math"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/wkbcommon"
+)
+
+// nanCoord is the coordinate of an empty WKB Point, using the same NaN as
+// PostGIS.
+var nanCoord = []float64{
+	math.Float64frombits(0x7ff8000000000000),
+	math.Float64frombits(0x7ff8000000000000),
+	math.Float64frombits(0x7ff8000000000000),
+	math.Float64frombits(0x7ff8000000000000),
+}
+
+// FromWKB writes the WKT of the ISO WKB geometry in data to w, translating it
+// directly without decoding it to a geom.T. WKB Points whose ordinates are all
+// NaN are written as empty POINTs, or according to the NonFinitePolicy. The
+// dimension of a GEOMETRYCOLLECTION is taken from its WKB type. Any data after
+// the geometry are ignored. If FromWKB returns an error then a partial WKT may
+// have been written.
+func FromWKB(w io.Writer, data []byte, opts ...Option) error {
+	bw := bufio.NewWriter(w)
+	o := newOptions(opts)
+	t := &wkbTranscoder{
+		encoder: encoder{
+			w:       bw,
+			options: o,
+		},
+		limiter: limiter{limits: o.limits},
+		data:    data,
+	}
+	if err := t.transcodeGeometry(); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// AppendWKB appends the ISO WKB of wkt to dst with byteOrder, translating it
+// directly without decoding it to a geom.T, and returns the extended buffer.
+// Empty POINTs are encoded with NaN ordinates. The WKB type of a
+// GEOMETRYCOLLECTION is determined by the layouts of its members, as it is for
+// a geom.GeometryCollection. Members with different layouts are not promoted,
+// so the MixedLayoutsPromote policy returns a geom.ErrLayoutMismatch for them.
+func AppendWKB(dst []byte, wkt string, byteOrder binary.ByteOrder, opts ...Option) ([]byte, error) {
+	var byteOrderID byte
+	switch byteOrder {
+	case wkbcommon.XDR:
+		byteOrderID = wkbcommon.XDRID
+	case wkbcommon.NDR:
+		byteOrderID = wkbcommon.NDRID
+	default:
+		return nil, wkbcommon.ErrUnsupportedByteOrder{}
+	}
+	t := &wktTranscoder{
+		decoder:     *newDecoder(wkt, newOptions(opts)),
+		byteOrder:   byteOrder,
+		byteOrderID: byteOrderID,
+	}
+	dst, _, err := t.appendGeometry(dst)
+	if err != nil {
+		return nil, err
+	}
+	if tok := t.lexer.next(); tok.t != tokenEOF {
+		return nil, t.unexpected(tok, "end of input")
+	}
+	return dst, nil
+}
+
+// A wkbTranscoder writes the WKT of a WKB geometry.
+type wkbTranscoder struct {
+	encoder
+	limiter
+	data  []byte
+	pos   int
+	coord [4]float64
+}
+
+// readHeader reads a geometry's byte order and type and returns them with
+// the layout and the geometry type without its dimension.
+func (t *wkbTranscoder) readHeader() (binary.ByteOrder, wkbcommon.Type, geom.Layout, error) {
+	if len(t.data)-t.pos < 5 {
+		return nil, 0, geom.NoLayout, io.ErrUnexpectedEOF
+	}
+	var byteOrder binary.ByteOrder
+	switch t.data[t.pos] {
+	case wkbcommon.XDRID:
+		byteOrder = wkbcommon.XDR
+	case wkbcommon.NDRID:
+		byteOrder = wkbcommon.NDR
+	default:
+		return nil, 0, geom.NoLayout, wkbcommon.ErrUnknownByteOrder(t.data[t.pos])
+	}
+	wkbType := wkbcommon.Type(byteOrder.Uint32(t.data[t.pos+1:]))
+	t.pos += 5
+	var layout geom.Layout
+	switch wkbType / 1000 {
+	case 0:
+		layout = geom.XY
+	case 1:
+		layout = geom.XYZ
+	case 2:
+		layout = geom.XYM
+	case 3:
+		layout = geom.XYZM
+	default:
+		return nil, 0, geom.NoLayout, wkbcommon.ErrUnknownType(wkbType)
+	}
+	return byteOrder, wkbType % 1000, layout, nil
+}
+
+// readMemberHeader reads the header of a member of a multi-geometry, which
+// must have type wantType and layout wantLayout.
+func (t *wkbTranscoder) readMemberHeader(wantType wkbcommon.Type, wantLayout geom.Layout) (binary.ByteOrder, error) {
+	byteOrder, wkbType, layout, err := t.readHeader()
+	switch {
+	case err != nil:
+		return nil, err
+	case wkbType != wantType:
+		return nil, wkbcommon.ErrUnsupportedType(wkbType)
+	case layout != wantLayout:
+		return nil, geom.ErrLayoutMismatch{Got: layout, Want: wantLayout}
+	}
+	return byteOrder, nil
+}
+
+func (t *wkbTranscoder) readCount(byteOrder binary.ByteOrder) (int, error) {
+	if len(t.data)-t.pos < 4 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	n := byteOrder.Uint32(t.data[t.pos:])
+	t.pos += 4
+	return int(n), nil
+}
+
+func (t *wkbTranscoder) readCoord(byteOrder binary.ByteOrder, stride int) ([]float64, error) {
+	if len(t.data)-t.pos < 8*stride {
+		return nil, io.ErrUnexpectedEOF
+	}
+	coord := t.coord[:stride]
+	for i := range coord {
+		coord[i] = math.Float64frombits(byteOrder.Uint64(t.data[t.pos:]))
+		t.pos += 8
+	}
+	return coord, nil
+}
+
+func (t *wkbTranscoder) transcodeGeometry() error {
+	byteOrder, wkbType, layout, err := t.readHeader()
+	if err != nil {
+		return err
+	}
+	if err := t.addGeoms(1); err != nil {
+		return err
+	}
+	var typeString string
+	switch wkbType {
+	case wkbcommon.PointID:
+		typeString = tPoint
+	case wkbcommon.LineStringID:
+		typeString = tLineString
+	case wkbcommon.PolygonID:
+		typeString = tPolygon
+	case wkbcommon.MultiPointID:
+		typeString = tMultiPoint
+	case wkbcommon.MultiLineStringID:
+		typeString = tMultiLineString
+	case wkbcommon.MultiPolygonID:
+		typeString = tMultiPolygon
+	case wkbcommon.GeometryCollectionID:
+		typeString = tGeometryCollection
+	default:
+		return wkbcommon.ErrUnsupportedType(wkbType)
+	}
+	switch layout {
+	case geom.XYZ:
+		typeString += tZ
+	case geom.XYM:
+		typeString += tM
+	case geom.XYZM:
+		typeString += tZm
+	}
+	if _, err := t.w.WriteString(typeString); err != nil {
+		return err
+	}
+	stride := layout.Stride()
+
+	if wkbType == wkbcommon.PointID {
+		if err := t.addCoords(1); err != nil {
+			return err
+		}
+		coord, err := t.readCoord(byteOrder, stride)
+		if err != nil {
+			return err
+		}
+		if isNaNCoord(coord) {
+			if t.options.nonFinitePolicy == NonFiniteNaNPointAsEmpty {
+				return t.writeNaNPoint(stride)
+			}
+			return t.writeEMPTY()
+		}
+		return t.writeFlatCoords0(coord, stride)
+	}
+
+	n, err := t.readCount(byteOrder)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return t.writeEMPTY()
+	}
+	if wkbType == wkbcommon.GeometryCollectionID {
+		if err := t.enter(); err != nil {
+			return err
+		}
+		defer t.leave()
+	}
+	if _, err := t.w.WriteRune('('); err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		if i != 0 {
+			if _, err := t.w.WriteString(", "); err != nil {
+				return err
+			}
+		}
+		switch wkbType {
+		case wkbcommon.MultiPointID, wkbcommon.MultiLineStringID, wkbcommon.MultiPolygonID:
+			if err := t.addGeoms(1); err != nil {
+				return err
+			}
+		}
+		switch wkbType {
+		case wkbcommon.LineStringID:
+			err = t.transcodeCoord(byteOrder, stride)
+		case wkbcommon.PolygonID:
+			err = t.transcodeCoords1(byteOrder, stride)
+		case wkbcommon.MultiPointID:
+			var memberByteOrder binary.ByteOrder
+			if memberByteOrder, err = t.readMemberHeader(wkbcommon.PointID, layout); err == nil {
+				err = t.transcodeCoord(memberByteOrder, stride)
+			}
+		case wkbcommon.MultiLineStringID:
+			var memberByteOrder binary.ByteOrder
+			if memberByteOrder, err = t.readMemberHeader(wkbcommon.LineStringID, layout); err == nil {
+				err = t.transcodeCoords1(memberByteOrder, stride)
+			}
+		case wkbcommon.MultiPolygonID:
+			var memberByteOrder binary.ByteOrder
+			if memberByteOrder, err = t.readMemberHeader(wkbcommon.PolygonID, layout); err == nil {
+				err = t.transcodeCoords2(memberByteOrder, stride)
+			}
+		case wkbcommon.GeometryCollectionID:
+			err = t.transcodeGeometry()
+		}
+		if err != nil {
+			return err
+		}
+	}
+	_, err = t.w.WriteRune(')')
+	return err
+}
+
+// transcodeCoord reads and writes a single coordinate.
+func (t *wkbTranscoder) transcodeCoord(byteOrder binary.ByteOrder, stride int) error {
+	if err := t.addCoords(1); err != nil {
+		return err
+	}
+	coord, err := t.readCoord(byteOrder, stride)
+	if err != nil {
+		return err
+	}
+	return t.writeCoord(coord)
+}
+
+// transcodeCoords1 reads and writes a counted list of coordinates.
+func (t *wkbTranscoder) transcodeCoords1(byteOrder binary.ByteOrder, stride int) error {
+	n, err := t.readCount(byteOrder)
+	if err != nil {
+		return err
+	}
+	if n > (len(t.data)-t.pos)/(8*stride) {
+		return io.ErrUnexpectedEOF
+	}
+	if n == 0 {
+		return t.writeEMPTY()
+	}
+	if _, err := t.w.WriteRune('('); err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		if i != 0 {
+			if _, err := t.w.WriteString(", "); err != nil {
+				return err
+			}
+		}
+		if err := t.transcodeCoord(byteOrder, stride); err != nil {
+			return err
+		}
+	}
+	_, err = t.w.WriteRune(')')
+	return err
+}
+
+// transcodeCoords2 reads and writes a counted list of counted lists of
+// coordinates.
+func (t *wkbTranscoder) transcodeCoords2(byteOrder binary.ByteOrder, stride int) error {
+	n, err := t.readCount(byteOrder)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return t.writeEMPTY()
+	}
+	if _, err := t.w.WriteRune('('); err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		if i != 0 {
+			if _, err := t.w.WriteString(", "); err != nil {
+				return err
+			}
+		}
+		if err := t.transcodeCoords1(byteOrder, stride); err != nil {
+			return err
+		}
+	}
+	_, err = t.w.WriteRune(')')
+	return err
+}
+
+// A wktTranscoder appends the WKB of a WKT geometry.
+type wktTranscoder struct {
+	decoder
+	byteOrder   binary.ByteOrder
+	byteOrderID byte
+	coord       []float64
+}
+
+// appendHeader appends a geometry header and returns the extended buffer.
+func (t *wktTranscoder) appendHeader(dst []byte, wkbType wkbcommon.Type, layout geom.Layout) []byte {
+	switch layout {
+	case geom.XYZ:
+		wkbType += 1000
+	case geom.XYM:
+		wkbType += 2000
+	case geom.XYZM:
+		wkbType += 3000
+	}
+	dst = append(dst, t.byteOrderID)
+	return wkbcommon.AppendUInt32(dst, t.byteOrder, uint32(wkbType))
+}
+
+// appendCount appends a placeholder for a count and returns the extended
+// buffer and the offset of the placeholder, to be set with setCount.
+func (t *wktTranscoder) appendCount(dst []byte) ([]byte, int) {
+	return append(dst, 0, 0, 0, 0), len(dst)
+}
+
+func (t *wktTranscoder) setCount(dst []byte, offset, n int) {
+	t.byteOrder.PutUint32(dst[offset:], uint32(n))
+}
+
+// appendGeometry appends the WKB of the next geometry and returns the
+// extended buffer and the geometry's layout.
+func (t *wktTranscoder) appendGeometry(dst []byte) ([]byte, geom.Layout, error) {
+	typeString, layout, err := t.readTypeAndLayout()
+	if err != nil {
+		return nil, geom.NoLayout, err
+	}
+	if err := t.addGeoms(1); err != nil {
+		return nil, geom.NoLayout, err
+	}
+	if typeString == tGeometryCollection {
+		return t.appendGeometryCollection(dst, layout)
+	}
+	stride := layout.Stride()
+
+	var wkbType wkbcommon.Type
+	switch typeString {
+	case tPoint:
+		wkbType = wkbcommon.PointID
+	case tLineString:
+		wkbType = wkbcommon.LineStringID
+	case tPolygon:
+		wkbType = wkbcommon.PolygonID
+	case tMultiPoint:
+		wkbType = wkbcommon.MultiPointID
+	case tMultiLineString:
+		wkbType = wkbcommon.MultiLineStringID
+	case tMultiPolygon:
+		wkbType = wkbcommon.MultiPolygonID
+	case tCircularString:
+		return nil, geom.NoLayout, wkbcommon.ErrUnsupportedType(wkbcommon.CircularStringID)
+	case tCompoundCurve:
+		return nil, geom.NoLayout, wkbcommon.ErrUnsupportedType(wkbcommon.CompoundCurveID)
+	case tCurvePolygon:
+		return nil, geom.NoLayout, wkbcommon.ErrUnsupportedType(wkbcommon.CurvePolygonID)
+	case tPolyhedralSurface:
+		return nil, geom.NoLayout, wkbcommon.ErrUnsupportedType(wkbcommon.PolyhedralSurfaceID)
+	case tTIN:
+		return nil, geom.NoLayout, wkbcommon.ErrUnsupportedType(wkbcommon.TINID)
+	case tTriangle:
+		return nil, geom.NoLayout, wkbcommon.ErrUnsupportedType(wkbcommon.TriangleID)
+	}
+	dst = t.appendHeader(dst, wkbType, layout)
+	empty, err := t.readEmpty()
+	if err != nil {
+		return nil, geom.NoLayout, err
+	}
+
+	if wkbType == wkbcommon.PointID {
+		if empty {
+			return wkbcommon.AppendFloatArray(dst, t.byteOrder, nanCoord[:stride]), layout, nil
+		}
+		if err := t.addCoords(1); err != nil {
+			return nil, geom.NoLayout, err
+		}
+		nanPointAsEmpty := t.options.nonFinitePolicy == NonFiniteNaNPointAsEmpty
+		if t.coord, err = t.readOrdinates(t.coord[:0], stride, nanPointAsEmpty); err != nil {
+			return nil, geom.NoLayout, err
+		}
+		if err := t.expect(tokenRParen, `")"`); err != nil {
+			return nil, geom.NoLayout, err
+		}
+		if nanPointAsEmpty {
+			if isNaNCoord(t.coord) {
+				return wkbcommon.AppendFloatArray(dst, t.byteOrder, nanCoord[:stride]), layout, nil
+			}
+			for _, f := range t.coord {
+				if math.IsNaN(f) {
+					return nil, geom.NoLayout, ErrNonFinite(f)
+				}
+			}
+		}
+		return wkbcommon.AppendFloatArray(dst, t.byteOrder, t.coord), layout, nil
+	}
+
+	if empty {
+		return wkbcommon.AppendUInt32(dst, t.byteOrder, 0), layout, nil
+	}
+	switch wkbType {
+	case wkbcommon.LineStringID:
+		dst, err = t.appendCoords1(dst, stride)
+	case wkbcommon.PolygonID:
+		dst, err = t.appendCoords2(dst, stride)
+	default:
+		dst, err = t.appendMembers(dst, wkbType, layout)
+	}
+	if err != nil {
+		return nil, geom.NoLayout, err
+	}
+	return dst, layout, nil
+}
+
+// appendMembers appends the members of a multi-geometry of type wkbType,
+// after the opening parenthesis has been read.
+func (t *wktTranscoder) appendMembers(dst []byte, wkbType wkbcommon.Type, layout geom.Layout) ([]byte, error) {
+	stride := layout.Stride()
+	dst, countOffset := t.appendCount(dst)
+	for n := 1; ; n++ {
+		if err := t.addGeoms(1); err != nil {
+			return nil, err
+		}
+		var err error
+		switch wkbType {
+		case wkbcommon.MultiPointID:
+			tok := t.lexer.next()
+			parenthesized := tok.t == tokenLParen
+			if !parenthesized {
+				t.lexer.unread(tok)
+			}
+			dst = t.appendHeader(dst, wkbcommon.PointID, layout)
+			if dst, err = t.appendCoord(dst, stride); err != nil {
+				return nil, err
+			}
+			if parenthesized {
+				err = t.expect(tokenRParen, `")"`)
+			}
+		case wkbcommon.MultiLineStringID:
+			if err := t.expect(tokenLParen, `"("`); err != nil {
+				return nil, err
+			}
+			dst = t.appendHeader(dst, wkbcommon.LineStringID, layout)
+			dst, err = t.appendCoords1(dst, stride)
+		case wkbcommon.MultiPolygonID:
+			if err := t.expect(tokenLParen, `"("`); err != nil {
+				return nil, err
+			}
+			dst = t.appendHeader(dst, wkbcommon.PolygonID, layout)
+			dst, err = t.appendCoords2(dst, stride)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if more, err := t.readCommaOrRParen(); err != nil {
+			return nil, err
+		} else if !more {
+			t.setCount(dst, countOffset, n)
+			return dst, nil
+		}
+	}
+}
+
+// appendCoord appends a single coordinate.
+func (t *wktTranscoder) appendCoord(dst []byte, stride int) ([]byte, error) {
+	var err error
+	if t.coord, err = t.readCoord(t.coord[:0], stride); err != nil {
+		return nil, err
+	}
+	return wkbcommon.AppendFloatArray(dst, t.byteOrder, t.coord), nil
+}
+
+// appendCoords1 appends a counted list of coordinates, after the opening
+// parenthesis has been read.
+func (t *wktTranscoder) appendCoords1(dst []byte, stride int) ([]byte, error) {
+	dst, countOffset := t.appendCount(dst)
+	for n := 1; ; n++ {
+		var err error
+		if dst, err = t.appendCoord(dst, stride); err != nil {
+			return nil, err
+		}
+		if more, err := t.readCommaOrRParen(); err != nil {
+			return nil, err
+		} else if !more {
+			t.setCount(dst, countOffset, n)
+			return dst, nil
+		}
+	}
+}
+
+// appendCoords2 appends a counted list of counted lists of coordinates, after
+// the opening parenthesis has been read.
+func (t *wktTranscoder) appendCoords2(dst []byte, stride int) ([]byte, error) {
+	dst, countOffset := t.appendCount(dst)
+	for n := 1; ; n++ {
+		if err := t.expect(tokenLParen, `"("`); err != nil {
+			return nil, err
+		}
+		var err error
+		if dst, err = t.appendCoords1(dst, stride); err != nil {
+			return nil, err
+		}
+		if more, err := t.readCommaOrRParen(); err != nil {
+			return nil, err
+		} else if !more {
+			t.setCount(dst, countOffset, n)
+			return dst, nil
+		}
+	}
+}
+
+// appendGeometryCollection appends a GEOMETRYCOLLECTION with layout l, after
+// its type has been read.
+func (t *wktTranscoder) appendGeometryCollection(dst []byte, l geom.Layout) ([]byte, geom.Layout, error) {
+	start := len(dst)
+	dst = t.appendHeader(dst, wkbcommon.GeometryCollectionID, geom.XY)
+	empty, err := t.readEmpty()
+	if err != nil {
+		return nil, geom.NoLayout, err
+	}
+	if empty {
+		return wkbcommon.AppendUInt32(dst, t.byteOrder, 0), geom.NoLayout, nil
+	}
+
+	if err := t.enter(); err != nil {
+		return nil, geom.NoLayout, err
+	}
+	defer t.leave()
+
+	want := geom.NoLayout
+	if l != geom.XY {
+		want = l
+	}
+	layout := geom.NoLayout
+	dst, countOffset := t.appendCount(dst)
+	for n := 1; ; n++ {
+		var memberLayout geom.Layout
+		if dst, memberLayout, err = t.appendGeometry(dst); err != nil {
+			return nil, geom.NoLayout, err
+		}
+		if t.options.mixedLayoutPolicy != MixedLayoutsPreserve {
+			switch {
+			case memberLayout == geom.NoLayout:
+			case want == geom.NoLayout:
+				want = memberLayout
+			case memberLayout != want:
+				return nil, geom.NoLayout, geom.ErrLayoutMismatch{Got: memberLayout, Want: want}
+			}
+		}
+		layout = coveringLayout(layout, memberLayout)
+		if more, err := t.readCommaOrRParen(); err != nil {
+			return nil, geom.NoLayout, err
+		} else if !more {
+			t.setCount(dst, countOffset, n)
+			break
+		}
+	}
+	// Overwrite the placeholder header now that the layout is known.
+	t.appendHeader(dst[start:start], wkbcommon.GeometryCollectionID, layout)
+	return dst, layout, nil
+}
+
+// isNaNCoord returns whether every ordinate of coord is NaN.
+func isNaNCoord(coord []float64) bool {
+	for _, f := range coord {
+		if !math.IsNaN(f) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,242 @@
+package wkt
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/wkb"
+)
+
+func TestTranscode(t *testing.T) {
+	for _, s := range []string{
+		"POINT (1 2)",
+		"POINT Z (1 2 3)",
+		"POINT M (1 2 3)",
+		"POINT ZM (1 2 3 4)",
+		"LINESTRING EMPTY",
+		"LINESTRING (1 2, 3 4)",
+		"LINESTRING Z (1 2 3, 4 5 6)",
+		"POLYGON EMPTY",
+		"POLYGON ((0 0, 1 0, 1 1, 0 0), (0.25 0.25, 0.5 0.25, 0.5 0.5, 0.25 0.25))",
+		"MULTIPOINT EMPTY",
+		"MULTIPOINT (1 2, 3 4)",
+		"MULTIPOINT M (1 2 3)",
+		"MULTILINESTRING EMPTY",
+		"MULTILINESTRING ((1 2, 3 4), (5 6, 7 8))",
+		"MULTIPOLYGON EMPTY",
+		"MULTIPOLYGON (((0 0, 1 0, 1 1, 0 0)), ((2 2, 3 2, 3 3, 2 2), (2.1 2.1, 2.2 2.1, 2.2 2.2, 2.1 2.1)))",
+		"GEOMETRYCOLLECTION (POINT (1 2), LINESTRING (1 2, 3 4))",
+		"GEOMETRYCOLLECTION Z (POINT Z (1 2 3), GEOMETRYCOLLECTION Z (POINT Z (4 5 6)))",
+	} {
+		t.Run(s, func(t *testing.T) {
+			g, err := Unmarshal(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := wkb.Marshal(g, wkb.NDR)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, err := AppendWKB(nil, s, wkb.NDR); err != nil || !bytes.Equal(got, want) {
+				t.Errorf("AppendWKB(nil, %q, NDR) == %s, %v, want %s, <nil>", s, hex.EncodeToString(got), err, hex.EncodeToString(want))
+			}
+			xdr, err := wkb.Marshal(g, wkb.XDR)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, err := AppendWKB([]byte{0xff}, s, wkb.XDR); err != nil || !bytes.Equal(got, append([]byte{0xff}, xdr...)) {
+				t.Errorf("AppendWKB({0xff}, %q, XDR) == %s, %v, want ff%s, <nil>", s, hex.EncodeToString(got), err, hex.EncodeToString(xdr))
+			}
+			sb := &strings.Builder{}
+			if err := FromWKB(sb, xdr); err != nil || sb.String() != s {
+				t.Errorf("FromWKB(_, %s) == %v, wrote %q, want <nil>, %q", hex.EncodeToString(xdr), err, sb.String(), s)
+			}
+		})
+	}
+}
+
+func TestTranscodeSpecialCases(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		wkt     string
+		opts    []Option
+		ndr     string
+		fromWKB string
+	}{
+		{
+			name: "empty_point",
+			wkt:  "POINT EMPTY",
+			ndr:  "0101000000000000000000f87f000000000000f87f",
+		},
+		{
+			name:    "empty_point_nan",
+			wkt:     "POINT (NaN NaN)",
+			opts:    []Option{WithNonFinitePolicy(NonFiniteNaNPointAsEmpty)},
+			ndr:     "0101000000000000000000f87f000000000000f87f",
+			fromWKB: "POINT (NaN NaN)",
+		},
+		{
+			name: "empty_geometry_collection",
+			wkt:  "GEOMETRYCOLLECTION EMPTY",
+			ndr:  "010700000000000000",
+		},
+		{
+			name:    "geometry_collection_layout_from_members",
+			wkt:     "GEOMETRYCOLLECTION (POINT Z (1 2 3))",
+			ndr:     "01ef0300000100000001e9030000000000000000f03f00000000000000400000000000000840",
+			fromWKB: "GEOMETRYCOLLECTION Z (POINT Z (1 2 3))",
+		},
+		{
+			name:    "multipoint_parenthesized",
+			wkt:     "MULTIPOINT ((1 2), (3 4))",
+			ndr:     "0104000000020000000101000000000000000000f03f0000000000000040010100000000000000000008400000000000001040",
+			fromWKB: "MULTIPOINT (1 2, 3 4)",
+		},
+		{
+			name:    "max_decimal_digits",
+			wkt:     "POINT (1.2345 2)",
+			opts:    []Option{WithMaxDecimalDigits(2)},
+			ndr:     "01010000008d976e1283c0f33f0000000000000040",
+			fromWKB: "POINT (1.23 2)",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := AppendWKB(nil, tc.wkt, wkb.NDR, tc.opts...)
+			if err != nil || hex.EncodeToString(got) != tc.ndr {
+				t.Errorf("AppendWKB(nil, %q, NDR, ...) == %s, %v, want %s, <nil>", tc.wkt, hex.EncodeToString(got), err, tc.ndr)
+			}
+			wantWKT := tc.fromWKB
+			if wantWKT == "" {
+				wantWKT = tc.wkt
+			}
+			data, err := hex.DecodeString(tc.ndr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sb := &strings.Builder{}
+			if err := FromWKB(sb, data, tc.opts...); err != nil || sb.String() != wantWKT {
+				t.Errorf("FromWKB(_, %s, ...) == %v, wrote %q, want <nil>, %q", tc.ndr, err, sb.String(), wantWKT)
+			}
+		})
+	}
+}
+
+func TestTranscodeErrors(t *testing.T) {
+	for _, tc := range []struct {
+		wkt  string
+		opts []Option
+		err  error
+	}{
+		{wkt: "POINT (1 2"},
+		{wkt: "POINT (1 2) x"},
+		{wkt: "LINESTRING (1 2, 3)"},
+		{wkt: "TRIANGLE ((0 0, 1 0, 1 1, 0 0))"},
+		{
+			wkt:  "GEOMETRYCOLLECTION (POINT (1 2), POINT Z (1 2 3))",
+			opts: []Option{WithMixedLayoutPolicy(MixedLayoutsError)},
+			err:  geom.ErrLayoutMismatch{Got: geom.XYZ, Want: geom.XY},
+		},
+		{
+			wkt:  "MULTIPOINT (1 2, 3 4)",
+			opts: []Option{WithLimits(Limits{MaxCoords: 1})},
+			err:  ErrLimitExceeded{Name: "coordinates", Limit: 1},
+		},
+		{
+			wkt:  "GEOMETRYCOLLECTION (GEOMETRYCOLLECTION (POINT (1 2)))",
+			opts: []Option{WithLimits(Limits{MaxDepth: 1})},
+			err:  ErrLimitExceeded{Name: "depth", Limit: 1},
+		},
+	} {
+		got, err := AppendWKB(nil, tc.wkt, wkb.NDR, tc.opts...)
+		if err == nil || tc.err != nil && err != tc.err {
+			t.Errorf("AppendWKB(nil, %q, NDR, ...) == %s, %v, want nil, %v", tc.wkt, hex.EncodeToString(got), err, tc.err)
+		}
+	}
+
+	data, err := hex.DecodeString("0102000000020000000000000000000000000000000000f03f")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < len(data); i++ {
+		if err := FromWKB(&strings.Builder{}, data[:i]); err == nil {
+			t.Errorf("FromWKB(_, %s) == <nil>, want !<nil>", hex.EncodeToString(data[:i]))
+		}
+	}
+	mixed, err := hex.DecodeString("0104000000010000000101000080000000000000f03f00000000000000400000000000000840")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := FromWKB(&strings.Builder{}, mixed); err == nil {
+		t.Errorf("FromWKB(_, %s) == <nil>, want !<nil>", hex.EncodeToString(mixed))
+	}
+}
+
+func TestFromWKBLimits(t *testing.T) {
+	for _, tc := range []struct {
+		wkt    string
+		limits Limits
+		err    error
+	}{
+		{
+			wkt:    "MULTIPOINT (1 2, 3 4)",
+			limits: Limits{MaxCoords: 2},
+		},
+		{
+			wkt:    "MULTIPOINT (1 2, 3 4)",
+			limits: Limits{MaxCoords: 1},
+			err:    ErrLimitExceeded{Name: "coordinates", Limit: 1},
+		},
+		{
+			wkt:    "GEOMETRYCOLLECTION (POINT (1 2), POINT (3 4))",
+			limits: Limits{MaxGeometries: 2},
+			err:    ErrLimitExceeded{Name: "geometries", Limit: 2},
+		},
+		{
+			wkt:    "GEOMETRYCOLLECTION (POINT (1 2))",
+			limits: Limits{MaxDepth: 1},
+		},
+		{
+			wkt:    "GEOMETRYCOLLECTION (GEOMETRYCOLLECTION (POINT (1 2)))",
+			limits: Limits{MaxDepth: 1},
+			err:    ErrLimitExceeded{Name: "depth", Limit: 1},
+		},
+	} {
+		data, err := AppendWKB(nil, tc.wkt, wkb.NDR, WithLimits(Limits{MaxDepth: -1, MaxGeometries: -1, MaxCoords: -1}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := FromWKB(&strings.Builder{}, data, WithLimits(tc.limits)); err != tc.err {
+			t.Errorf("FromWKB(_, %q, WithLimits(%+v)) == %v, want %v", tc.wkt, tc.limits, err, tc.err)
+		}
+	}
+
+	// A deeply nested GEOMETRYCOLLECTION must return an error rather than
+	// overflow the stack.
+	const depth = 1 << 20
+	member := []byte{1, 7, 0, 0, 0, 1, 0, 0, 0}
+	data := bytes.Repeat(member, depth)
+	data = append(data, 1, 7, 0, 0, 0, 0, 0, 0, 0)
+	for _, tc := range []struct {
+		limits Limits
+		err    error
+	}{
+		{
+			limits: Limits{MaxDepth: 5},
+			err:    ErrLimitExceeded{Name: "depth", Limit: 5},
+		},
+		{
+			err: ErrLimitExceeded{Name: "depth", Limit: DefaultMaxDepth},
+		},
+		{
+			limits: Limits{MaxDepth: -1, MaxGeometries: -1},
+			err:    ErrLimitExceeded{Name: "depth", Limit: HardMaxDepth},
+		},
+	} {
+		if err := FromWKB(&strings.Builder{}, data, WithLimits(tc.limits)); err != tc.err {
+			t.Errorf("FromWKB(_, <%d nested GEOMETRYCOLLECTIONs>, WithLimits(%+v)) == %v, want %v", depth, tc.limits, err, tc.err)
+		}
+	}
+}
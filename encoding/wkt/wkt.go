@@ -2,6 +2,8 @@
 package wkt
 
 import (
+	"fmt"
+
 	"github.com/twpayne/go-geom"
 )
 
@@ -13,18 +15,129 @@ const (
 	tPolygon            = "POLYGON "
 	tMultiPolygon       = "MULTIPOLYGON "
 	tGeometryCollection = "GEOMETRYCOLLECTION "
+	tCircularString     = "CIRCULARSTRING "
+	tCompoundCurve      = "COMPOUNDCURVE "
+	tCurvePolygon       = "CURVEPOLYGON "
+	tPolyhedralSurface  = "POLYHEDRALSURFACE "
+	tTIN                = "TIN "
+	tTriangle           = "TRIANGLE "
 	tZ                  = "Z "
 	tM                  = "M "
 	tZm                 = "ZM "
 	tEmpty              = "EMPTY"
 )
 
+// Limits limits the resources used when decoding WKT, so that untrusted input
+// cannot cause excessive memory allocations. Zero means the default and a
+// negative value means no limit, except for MaxDepth.
+type Limits struct {
+	// MaxCoords is the maximum total number of coordinates. Zero means
+	// DefaultMaxCoords.
+	MaxCoords int
+	// MaxGeometries is the maximum total number of geometries, including
+	// the members of GEOMETRYCOLLECTIONs and the components of MULTI
+	// geometries. Zero means DefaultMaxGeometries.
+	MaxGeometries int
+	// MaxDepth is the maximum nesting depth of GEOMETRYCOLLECTIONs. Zero
+	// means DefaultMaxDepth. Decoding is recursive, so the depth is never
+	// allowed to exceed HardMaxDepth, which is also used if MaxDepth is
+	// negative.
+	MaxDepth int
+}
+
+const (
+	// DefaultMaxCoords is the maximum total number of coordinates if
+	// Limits.MaxCoords is zero.
+	DefaultMaxCoords = 1 << 26
+	// DefaultMaxGeometries is the maximum total number of geometries if
+	// Limits.MaxGeometries is zero.
+	DefaultMaxGeometries = 1 << 24
+	// DefaultMaxDepth is the maximum nesting depth of GEOMETRYCOLLECTIONs if
+	// Limits.MaxDepth is zero.
+	DefaultMaxDepth = 100
+	// HardMaxDepth is the maximum nesting depth of GEOMETRYCOLLECTIONs
+	// regardless of Limits.MaxDepth, so that crafted input cannot overflow
+	// the stack.
+	HardMaxDepth = 10000
+)
+
+// maxCoords returns the effective maximum number of coordinates of l, or -1
+// if there is no limit.
+func (l Limits) maxCoords() int {
+	return orDefault(l.MaxCoords, DefaultMaxCoords)
+}
+
+// maxGeometries returns the effective maximum number of geometries of l, or
+// -1 if there is no limit.
+func (l Limits) maxGeometries() int {
+	return orDefault(l.MaxGeometries, DefaultMaxGeometries)
+}
+
+// orDefault returns defaultLimit if limit is zero, -1 if limit is negative,
+// and limit otherwise.
+func orDefault(limit, defaultLimit int) int {
+	switch {
+	case limit == 0:
+		return defaultLimit
+	case limit < 0:
+		return -1
+	default:
+		return limit
+	}
+}
+
+// maxDepth returns the effective maximum depth of l.
+func (l Limits) maxDepth() int {
+	switch {
+	case l.MaxDepth == 0:
+		return DefaultMaxDepth
+	case l.MaxDepth < 0 || l.MaxDepth > HardMaxDepth:
+		return HardMaxDepth
+	default:
+		return l.MaxDepth
+	}
+}
+
+// An ErrLimitExceeded is returned when decoding would exceed a limit.
+type ErrLimitExceeded struct {
+	Name  string
+	Limit int
+}
+
+func (e ErrLimitExceeded) Error() string {
+	return fmt.Sprintf("wkt: %s limit (%d) exceeded", e.Name, e.Limit)
+}
+
+// An ErrNonFinite is returned when a non-finite ordinate is not allowed by the
+// NonFinitePolicy.
+type ErrNonFinite float64
+
+func (e ErrNonFinite) Error() string {
+	return fmt.Sprintf("wkt: non-finite ordinate %v", float64(e))
+}
+
 // Marshal translates a geometry to the corresponding WKT.
-func Marshal(g geom.T) (string, error) {
-	return encode(g)
+func Marshal(g geom.T, opts ...Option) (string, error) {
+	return encode(g, newOptions(opts))
 }
 
 // Unmarshal translates a WKT to the corresponding geometry.
-func Unmarshal(wkt string) (geom.T, error) {
-	return decode(wkt)
+func Unmarshal(wkt string, opts ...Option) (geom.T, error) {
+	return newDecoder(wkt, newOptions(opts)).decode()
+}
+
+// UnmarshalWithLimits translates a WKT to the corresponding geometry,
+// returning an ErrLimitExceeded if decoding would exceed limits. It is
+// equivalent to Unmarshal(wkt, WithLimits(limits)).
+func UnmarshalWithLimits(wkt string, limits Limits) (geom.T, error) {
+	return Unmarshal(wkt, WithLimits(limits))
+}
+
+// UnmarshalStub returns the Stub of the geometry in wkt.
+func UnmarshalStub(wkt string, opts ...Option) (*geom.Stub, error) {
+	g, err := Unmarshal(wkt, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return geom.NewStub(g)
 }
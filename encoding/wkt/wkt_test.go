@@ -1,10 +1,15 @@
 package wkt
 
 import (
+	"encoding/binary"
+	"io"
+	"math"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/internal/testdata"
 )
 
 func TestMarshalAndUnmarshal(t *testing.T) {
@@ -138,6 +143,84 @@ func TestMarshalAndUnmarshal(t *testing.T) {
 		if got, err := Unmarshal(tc.s); err != nil || !reflect.DeepEqual(got, tc.g) {
 			t.Errorf("Unmarshal(%#v) == %v, %v, want %v, nil", tc.s, got, err, tc.g)
 		}
+		b := &strings.Builder{}
+		if err := NewEncoder(b).Encode(tc.g); err != nil || b.String() != tc.s+"\n" {
+			t.Errorf("NewEncoder(b).Encode(%#v) == %v and wrote %q, want <nil> and %q", tc.g, err, b.String(), tc.s+"\n")
+		}
+	}
+}
+
+func TestCurvesAndSurfaces(t *testing.T) {
+	xy := testdata.CurvesAndSurfaces(geom.XY)
+	for i, want := range []string{
+		"CIRCULARSTRING (0 0, 1 1, 2 0)",
+		"COMPOUNDCURVE (CIRCULARSTRING (0 0, 1 1, 2 0), (2 0, 3 0, 3 1))",
+		"CURVEPOLYGON (COMPOUNDCURVE (CIRCULARSTRING (0 0, 2 2, 4 0), (4 0, 0 0)), CIRCULARSTRING (1 0.5, 1.5 1, 1 0.5), (3 0.2, 3.2 0.2, 3 0.4, 3 0.2))",
+		"POLYHEDRALSURFACE (((0 0, 1 0, 1 1, 0 1, 0 0)), ((1 0, 2 0, 2 1, 1 1, 1 0)))",
+		"TIN (((0 0, 1 0, 0 1, 0 0)), ((1 0, 1 1, 0 1, 1 0)))",
+		"TRIANGLE ((0 0, 1 0, 0 1, 0 0))",
+	} {
+		if got, err := Marshal(xy[i]); err != nil || got != want {
+			t.Errorf("Marshal(%#v) == %q, %v, want %q, <nil>", xy[i], got, err, want)
+		}
+	}
+	for _, layout := range []geom.Layout{geom.XY, geom.XYZ, geom.XYM, geom.XYZM} {
+		for _, g := range testdata.CurvesAndSurfaces(layout) {
+			s, err := Marshal(g)
+			if err != nil {
+				t.Fatalf("Marshal(%#v) == _, %v, want _, <nil>", g, err)
+			}
+			if got, err := Unmarshal(s); err != nil || !reflect.DeepEqual(got, g) {
+				t.Errorf("Unmarshal(%q) == %#v, %v, want %#v, <nil>", s, got, err, g)
+			}
+		}
+	}
+	for _, s := range []string{
+		"CIRCULARSTRING EMPTY",
+		"COMPOUNDCURVE EMPTY",
+		"CURVEPOLYGON EMPTY",
+		"POLYHEDRALSURFACE Z EMPTY",
+		"TIN Z EMPTY",
+		"TRIANGLE EMPTY",
+	} {
+		g, err := Unmarshal(s)
+		if err != nil || len(g.FlatCoords()) != 0 {
+			t.Errorf("Unmarshal(%q) == %#v, %v, want empty, <nil>", s, g, err)
+		}
+		if got, err := Marshal(g); err != nil || got != s {
+			t.Errorf("Marshal(%#v) == %q, %v, want %q, <nil>", g, got, err, s)
+		}
+	}
+	// The dimension qualifiers of nested curves may be omitted.
+	g, err := Unmarshal("COMPOUNDCURVE Z (CIRCULARSTRING (0 0 1, 1 1 1, 2 0 1), (2 0 1, 3 0 1))")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := geom.NewCompoundCurve(geom.XYZ).MustPush(
+		geom.NewCircularStringFlat(geom.XYZ, []float64{0, 0, 1, 1, 1, 1, 2, 0, 1}),
+		geom.NewLineStringFlat(geom.XYZ, []float64{2, 0, 1, 3, 0, 1}),
+	)
+	if !reflect.DeepEqual(g, want) {
+		t.Errorf("got %#v, want %#v", g, want)
+	}
+}
+
+func TestEncoder(t *testing.T) {
+	b := &strings.Builder{}
+	e := NewEncoder(b)
+	for _, g := range []geom.T{
+		geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1, 2}),
+		geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{{3, 4}, {5, 6}}),
+	} {
+		if err := e.Encode(g); err != nil {
+			t.Errorf("e.Encode(%#v) == %v, want <nil>", g, err)
+		}
+	}
+	if want := "POINT (1 2)\nLINESTRING (3 4, 5 6)\n"; b.String() != want {
+		t.Errorf("got %q, want %q", b.String(), want)
+	}
+	if err := e.Encode(geom.NewPointFlat(geom.NoLayout, nil)); err == nil {
+		t.Errorf("e.Encode(NoLayout point) == <nil>, want !<nil>")
 	}
 }
 
@@ -180,3 +263,465 @@ func TestUnmarshalEmptyGeomWithArbitrarySpaces(t *testing.T) {
 		}
 	}
 }
+
+func TestUnmarshalWithLimits(t *testing.T) {
+	for _, tc := range []struct {
+		s       string
+		limits  Limits
+		wantErr error
+	}{
+		{
+			s:      "LINESTRING (1 2, 3 4, 5 6)",
+			limits: Limits{MaxCoords: 3},
+		},
+		{
+			s:       "LINESTRING (1 2, 3 4, 5 6)",
+			limits:  Limits{MaxCoords: 2},
+			wantErr: ErrLimitExceeded{Name: "coordinates", Limit: 2},
+		},
+		{
+			s:       "MULTIPOLYGON (((1 2, 3 4, 5 6)), ((7 8, 9 10, 11 12)))",
+			limits:  Limits{MaxCoords: 5},
+			wantErr: ErrLimitExceeded{Name: "coordinates", Limit: 5},
+		},
+		{
+			s:      "MULTIPOINT (1 2, 3 4)",
+			limits: Limits{MaxGeometries: 3},
+		},
+		{
+			s:       "MULTIPOINT (1 2, 3 4)",
+			limits:  Limits{MaxGeometries: 2},
+			wantErr: ErrLimitExceeded{Name: "geometries", Limit: 2},
+		},
+		{
+			s:       "GEOMETRYCOLLECTION (POINT (1 2), POINT (3 4))",
+			limits:  Limits{MaxGeometries: 2},
+			wantErr: ErrLimitExceeded{Name: "geometries", Limit: 2},
+		},
+		{
+			s:      "GEOMETRYCOLLECTION (POINT (1 2), POINT (3 4))",
+			limits: Limits{MaxDepth: 1},
+		},
+		{
+			s:      "GEOMETRYCOLLECTION (POINT (1 2), POINT (3 4))",
+			limits: Limits{MaxDepth: -1, MaxGeometries: -1, MaxCoords: -1},
+		},
+		{
+			s: "GEOMETRYCOLLECTION (POINT (1 2), POINT (3 4))",
+		},
+	} {
+		_, err := UnmarshalWithLimits(tc.s, tc.limits)
+		if err != tc.wantErr {
+			t.Errorf("UnmarshalWithLimits(%q, %+v) returned error %v, want %v", tc.s, tc.limits, err, tc.wantErr)
+		}
+	}
+}
+
+func TestLimitsDefaults(t *testing.T) {
+	for _, tc := range []struct {
+		limits            Limits
+		wantMaxCoords     int
+		wantMaxGeometries int
+		wantMaxDepth      int
+	}{
+		{
+			limits:            Limits{},
+			wantMaxCoords:     DefaultMaxCoords,
+			wantMaxGeometries: DefaultMaxGeometries,
+			wantMaxDepth:      DefaultMaxDepth,
+		},
+		{
+			limits:            Limits{MaxCoords: -1, MaxGeometries: -1, MaxDepth: -1},
+			wantMaxCoords:     -1,
+			wantMaxGeometries: -1,
+			wantMaxDepth:      HardMaxDepth,
+		},
+		{
+			limits:            Limits{MaxCoords: 1, MaxGeometries: 2, MaxDepth: 3},
+			wantMaxCoords:     1,
+			wantMaxGeometries: 2,
+			wantMaxDepth:      3,
+		},
+	} {
+		if got := tc.limits.maxCoords(); got != tc.wantMaxCoords {
+			t.Errorf("%+v.maxCoords() == %d, want %d", tc.limits, got, tc.wantMaxCoords)
+		}
+		if got := tc.limits.maxGeometries(); got != tc.wantMaxGeometries {
+			t.Errorf("%+v.maxGeometries() == %d, want %d", tc.limits, got, tc.wantMaxGeometries)
+		}
+		if got := tc.limits.maxDepth(); got != tc.wantMaxDepth {
+			t.Errorf("%+v.maxDepth() == %d, want %d", tc.limits, got, tc.wantMaxDepth)
+		}
+	}
+}
+
+func TestUnmarshalMaxDepth(t *testing.T) {
+	nested := func(depth int) string {
+		return strings.Repeat("GEOMETRYCOLLECTION (", depth) + "POINT (1 2)" + strings.Repeat(")", depth)
+	}
+	for _, tc := range []struct {
+		s       string
+		limits  Limits
+		wantErr error
+	}{
+		{
+			s: nested(DefaultMaxDepth),
+		},
+		{
+			s:       nested(DefaultMaxDepth + 1),
+			wantErr: ErrLimitExceeded{Name: "depth", Limit: DefaultMaxDepth},
+		},
+		{
+			s:      nested(DefaultMaxDepth + 1),
+			limits: Limits{MaxDepth: DefaultMaxDepth + 1},
+		},
+		{
+			s:       nested(HardMaxDepth + 1),
+			limits:  Limits{MaxDepth: -1},
+			wantErr: ErrLimitExceeded{Name: "depth", Limit: HardMaxDepth},
+		},
+		{
+			s:       nested(HardMaxDepth + 1),
+			limits:  Limits{MaxDepth: 2 * HardMaxDepth},
+			wantErr: ErrLimitExceeded{Name: "depth", Limit: HardMaxDepth},
+		},
+		{
+			// Unterminated input that would overflow the stack without a
+			// depth limit.
+			s:       strings.Repeat("GEOMETRYCOLLECTION(", 3<<20),
+			limits:  Limits{MaxDepth: -1},
+			wantErr: ErrLimitExceeded{Name: "depth", Limit: HardMaxDepth},
+		},
+	} {
+		if _, err := UnmarshalWithLimits(tc.s, tc.limits); err != tc.wantErr {
+			t.Errorf("UnmarshalWithLimits(<%d bytes>, %+v) returned error %v, want %v", len(tc.s), tc.limits, err, tc.wantErr)
+		}
+		if _, err := AppendWKB(nil, tc.s, binary.LittleEndian, WithLimits(tc.limits)); err != tc.wantErr {
+			t.Errorf("AppendWKB(nil, <%d bytes>, ...) returned error %v, want %v", len(tc.s), err, tc.wantErr)
+		}
+	}
+}
+
+func TestUnmarshalAlternativeForms(t *testing.T) {
+	for _, tc := range []struct {
+		s    string
+		want geom.T
+	}{
+		{
+			s:    "point(1 2)",
+			want: geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1, 2}),
+		},
+		{
+			s:    "\tPOINT Z(1 2 3)\n",
+			want: geom.NewPoint(geom.XYZ).MustSetCoords(geom.Coord{1, 2, 3}),
+		},
+		{
+			s:    "MULTIPOINT ((1 2), (3 4))",
+			want: geom.NewMultiPoint(geom.XY).MustSetCoords([]geom.Coord{{1, 2}, {3, 4}}),
+		},
+		{
+			s: "GEOMETRYCOLLECTION (POINT EMPTY, LINESTRING (1 2, 3 4))",
+			want: geom.NewGeometryCollection().MustPush(
+				geom.NewPointEmpty(geom.XY),
+				geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{{1, 2}, {3, 4}}),
+			),
+		},
+	} {
+		if got, err := Unmarshal(tc.s); err != nil || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Unmarshal(%q) == %v, %v, want %v, nil", tc.s, got, err, tc.want)
+		}
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"POINT",
+		"POINT ()",
+		"POINT (1)",
+		"POINT (1 2",
+		"POINT (1 2 3)",
+		"POINT (1 x)",
+		"POINT (1 2) POINT (3 4)",
+		"POINT (1 2))",
+		"CIRCLE (1 2)",
+		"LINESTRING ()",
+		"LINESTRING (1 2,)",
+		"POLYGON (())",
+		"POLYGON (EMPTY)",
+		"MULTILINESTRING (EMPTY)",
+		"MULTIPOLYGON (EMPTY)",
+		"MULTIPOLYGON ((EMPTY))",
+		"GEOMETRYCOLLECTION ()",
+		"GEOMETRYCOLLECTION (POINT (1 2)",
+		"GEOMETRYCOLLECTION (POINT (1 2), )",
+		"GEOMETRYCOLLECTION (MULTIPOLYGON (EMPTY))",
+		"CIRCULARSTRING ()",
+		"COMPOUNDCURVE (POINT (1 2))",
+		"COMPOUNDCURVE (COMPOUNDCURVE ((0 0, 1 1)))",
+		"COMPOUNDCURVE ((0 0, 1 1), (2 2, 3 3))",
+		"COMPOUNDCURVE Z (CIRCULARSTRING M (0 0 1, 1 1 1, 2 0 1))",
+		"CURVEPOLYGON ((0 0, 1 1))",
+		"TRIANGLE (EMPTY)",
+		"TIN ((EMPTY))",
+	} {
+		if got, err := Unmarshal(s); err == nil || got != nil {
+			t.Errorf("Unmarshal(%q) == %#v, %v, want nil, non-nil", s, got, err)
+		}
+	}
+}
+
+func TestMarshalWithMaxDecimalDigits(t *testing.T) {
+	for _, tc := range []struct {
+		g                geom.T
+		maxDecimalDigits int
+		s                string
+	}{
+		{
+			g:                geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1.23456, -2.5}),
+			maxDecimalDigits: -1,
+			s:                "POINT (1.23456 -2.5)",
+		},
+		{
+			g:                geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1.23456, -2.5}),
+			maxDecimalDigits: 2,
+			s:                "POINT (1.23 -2.5)",
+		},
+		{
+			g:                geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1.999, 10}),
+			maxDecimalDigits: 2,
+			s:                "POINT (2 10)",
+		},
+		{
+			g:                geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{-0.001, 0.6}),
+			maxDecimalDigits: 0,
+			s:                "POINT (0 1)",
+		},
+		{
+			g:                geom.NewLineString(geom.XYZ).MustSetCoords([]geom.Coord{{0.125, 0.0001, 3}, {1, 2, 3}}),
+			maxDecimalDigits: 3,
+			s:                "LINESTRING Z (0.125 0 3, 1 2 3)",
+		},
+	} {
+		if got, err := Marshal(tc.g, WithMaxDecimalDigits(tc.maxDecimalDigits)); err != nil || got != tc.s {
+			t.Errorf("Marshal(%#v, WithMaxDecimalDigits(%d)) == %v, %v, want %v, <nil>", tc.g, tc.maxDecimalDigits, got, err, tc.s)
+		}
+	}
+}
+
+func TestDecoder(t *testing.T) {
+	d := NewDecoder(strings.NewReader("POINT (1 2)\n\n  \nLINESTRING (3 4, 5 6)"))
+	for _, want := range []geom.T{
+		geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1, 2}),
+		geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{{3, 4}, {5, 6}}),
+	} {
+		if got, err := d.Decode(); err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("d.Decode() == %v, %v, want %v, <nil>", got, err, want)
+		}
+	}
+	if got, err := d.Decode(); err != io.EOF {
+		t.Errorf("d.Decode() == %v, %v, want <nil>, %v", got, err, io.EOF)
+	}
+
+	d = NewDecoder(strings.NewReader("MULTIPOINT (1 2, 3 4)\n"), WithLimits(Limits{MaxCoords: 1}))
+	if _, err := d.Decode(); !reflect.DeepEqual(err, ErrLimitExceeded{Name: "coordinates", Limit: 1}) {
+		t.Errorf("d.Decode() == _, %v, want _, %v", err, ErrLimitExceeded{Name: "coordinates", Limit: 1})
+	}
+}
+
+func TestUnmarshalMixedLayouts(t *testing.T) {
+	for _, tc := range []struct {
+		s        string
+		preserve geom.T
+		err      error
+		promote  geom.T
+	}{
+		{
+			s: "GEOMETRYCOLLECTION (POINT Z (1 2 3), POINT (4 5))",
+			preserve: geom.NewGeometryCollection().MustPush(
+				geom.NewPointFlat(geom.XYZ, []float64{1, 2, 3}),
+				geom.NewPointFlat(geom.XY, []float64{4, 5}),
+			),
+			err: geom.ErrLayoutMismatch{Got: geom.XY, Want: geom.XYZ},
+			promote: geom.NewGeometryCollection().MustPush(
+				geom.NewPointFlat(geom.XYZ, []float64{1, 2, 3}),
+				geom.NewPointFlat(geom.XYZ, []float64{4, 5, 0}),
+			),
+		},
+		{
+			s: "GEOMETRYCOLLECTION (POINT Z (1 2 3), LINESTRING M (4 5 6, 7 8 9))",
+			preserve: geom.NewGeometryCollection().MustPush(
+				geom.NewPointFlat(geom.XYZ, []float64{1, 2, 3}),
+				geom.NewLineStringFlat(geom.XYM, []float64{4, 5, 6, 7, 8, 9}),
+			),
+			err: geom.ErrLayoutMismatch{Got: geom.XYM, Want: geom.XYZ},
+			promote: geom.NewGeometryCollection().MustPush(
+				geom.NewPointFlat(geom.XYZM, []float64{1, 2, 3, 0}),
+				geom.NewLineStringFlat(geom.XYZM, []float64{4, 5, 0, 6, 7, 8, 0, 9}),
+			),
+		},
+		{
+			s: "GEOMETRYCOLLECTION Z (POLYGON ((0 0, 1 0, 1 1, 0 0)), POINT EMPTY)",
+			preserve: geom.NewGeometryCollection().MustPush(
+				geom.NewPolygonFlat(geom.XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}, []int{8}),
+				geom.NewPointEmpty(geom.XY),
+			),
+			err: geom.ErrLayoutMismatch{Got: geom.XY, Want: geom.XYZ},
+			promote: geom.NewGeometryCollection().MustPush(
+				geom.NewPolygonFlat(geom.XYZ, []float64{0, 0, 0, 1, 0, 0, 1, 1, 0, 0, 0, 0}, []int{12}),
+				geom.NewPointEmpty(geom.XYZ),
+			),
+		},
+		{
+			s: "GEOMETRYCOLLECTION (GEOMETRYCOLLECTION EMPTY, GEOMETRYCOLLECTION (MULTIPOINT M (1 2 3)), POINT (4 5))",
+			preserve: geom.NewGeometryCollection().MustPush(
+				geom.NewGeometryCollection(),
+				geom.NewGeometryCollection().MustPush(
+					geom.NewMultiPointFlat(geom.XYM, []float64{1, 2, 3}),
+				),
+				geom.NewPointFlat(geom.XY, []float64{4, 5}),
+			),
+			err: geom.ErrLayoutMismatch{Got: geom.XY, Want: geom.XYM},
+			promote: geom.NewGeometryCollection().MustPush(
+				geom.NewGeometryCollection(),
+				geom.NewGeometryCollection().MustPush(
+					geom.NewMultiPointFlat(geom.XYM, []float64{1, 2, 3}),
+				),
+				geom.NewPointFlat(geom.XYM, []float64{4, 5, 0}),
+			),
+		},
+		{
+			s: "GEOMETRYCOLLECTION ZM (MULTILINESTRING ZM ((1 2 3 4)), POINT ZM (5 6 7 8))",
+			preserve: geom.NewGeometryCollection().MustPush(
+				geom.NewMultiLineStringFlat(geom.XYZM, []float64{1, 2, 3, 4}, []int{4}),
+				geom.NewPointFlat(geom.XYZM, []float64{5, 6, 7, 8}),
+			),
+			promote: geom.NewGeometryCollection().MustPush(
+				geom.NewMultiLineStringFlat(geom.XYZM, []float64{1, 2, 3, 4}, []int{4}),
+				geom.NewPointFlat(geom.XYZM, []float64{5, 6, 7, 8}),
+			),
+		},
+	} {
+		t.Run(tc.s, func(t *testing.T) {
+			if got, err := Unmarshal(tc.s); err != nil || !reflect.DeepEqual(got, tc.preserve) {
+				t.Errorf("Unmarshal(%q) == %v, %v, want %v, <nil>", tc.s, got, err, tc.preserve)
+			}
+			got, err := Unmarshal(tc.s, WithMixedLayoutPolicy(MixedLayoutsError))
+			if tc.err != nil {
+				if !reflect.DeepEqual(err, tc.err) {
+					t.Errorf("Unmarshal(%q, WithMixedLayoutPolicy(MixedLayoutsError)) == _, %v, want _, %v", tc.s, err, tc.err)
+				}
+			} else if err != nil || !reflect.DeepEqual(got, tc.preserve) {
+				t.Errorf("Unmarshal(%q, WithMixedLayoutPolicy(MixedLayoutsError)) == %v, %v, want %v, <nil>", tc.s, got, err, tc.preserve)
+			}
+			if got, err := Unmarshal(tc.s, WithMixedLayoutPolicy(MixedLayoutsPromote)); err != nil || !reflect.DeepEqual(got, tc.promote) {
+				t.Errorf("Unmarshal(%q, WithMixedLayoutPolicy(MixedLayoutsPromote)) == %v, %v, want %v, <nil>", tc.s, got, err, tc.promote)
+			}
+		})
+	}
+}
+
+func TestNonFinitePolicy(t *testing.T) {
+	nan, inf := math.NaN(), math.Inf(1)
+	for _, tc := range []struct {
+		s      string
+		policy NonFinitePolicy
+		want   geom.T
+		err    bool
+	}{
+		{s: "POINT (NaN NaN)", policy: NonFiniteAllow, want: geom.NewPointFlat(geom.XY, []float64{nan, nan})},
+		{s: "POINT (NaN NaN)", policy: NonFiniteError, err: true},
+		{s: "POINT (NaN NaN)", policy: NonFiniteNaNPointAsEmpty, want: geom.NewPointEmpty(geom.XY)},
+		{s: "POINT Z (NaN NaN NaN)", policy: NonFiniteNaNPointAsEmpty, want: geom.NewPointEmpty(geom.XYZ)},
+		{s: "POINT (1 NaN)", policy: NonFiniteAllow, want: geom.NewPointFlat(geom.XY, []float64{1, nan})},
+		{s: "POINT (1 NaN)", policy: NonFiniteNaNPointAsEmpty, err: true},
+		{s: "POINT (+Inf -Inf)", policy: NonFiniteAllow, want: geom.NewPointFlat(geom.XY, []float64{inf, -inf})},
+		{s: "POINT (+Inf -Inf)", policy: NonFiniteNaNPointAsEmpty, err: true},
+		{s: "LINESTRING (0 0, NaN NaN)", policy: NonFiniteAllow, want: geom.NewLineStringFlat(geom.XY, []float64{0, 0, nan, nan})},
+		{s: "LINESTRING (0 0, NaN NaN)", policy: NonFiniteError, err: true},
+		{s: "LINESTRING (0 0, NaN NaN)", policy: NonFiniteNaNPointAsEmpty, err: true},
+	} {
+		got, err := Unmarshal(tc.s, WithNonFinitePolicy(tc.policy))
+		switch {
+		case tc.err:
+			if _, ok := err.(ErrNonFinite); !ok {
+				t.Errorf("Unmarshal(%q, WithNonFinitePolicy(%d)) == %v, %v, want _, ErrNonFinite", tc.s, tc.policy, got, err)
+			}
+		case err != nil || !equalNaN(got, tc.want):
+			t.Errorf("Unmarshal(%q, WithNonFinitePolicy(%d)) == %v, %v, want %v, <nil>", tc.s, tc.policy, got, err, tc.want)
+		}
+	}
+
+	for _, tc := range []struct {
+		g      geom.T
+		policy NonFinitePolicy
+		want   string
+		err    bool
+	}{
+		{g: geom.NewPointFlat(geom.XY, []float64{nan, inf}), policy: NonFiniteAllow, want: "POINT (NaN +Inf)"},
+		{g: geom.NewPointFlat(geom.XY, []float64{nan, inf}), policy: NonFiniteError, err: true},
+		{g: geom.NewPointFlat(geom.XY, []float64{nan, inf}), policy: NonFiniteNaNPointAsEmpty, err: true},
+		{g: geom.NewPointEmpty(geom.XY), policy: NonFiniteAllow, want: "POINT EMPTY"},
+		{g: geom.NewPointEmpty(geom.XYM), policy: NonFiniteNaNPointAsEmpty, want: "POINT M (NaN NaN NaN)"},
+		{g: geom.NewLineStringFlat(geom.XY, []float64{0, 0, 1, -inf}), policy: NonFiniteError, err: true},
+	} {
+		got, err := Marshal(tc.g, WithNonFinitePolicy(tc.policy))
+		switch {
+		case tc.err:
+			if _, ok := err.(ErrNonFinite); !ok {
+				t.Errorf("Marshal(%v, WithNonFinitePolicy(%d)) == %q, %v, want _, ErrNonFinite", tc.g, tc.policy, got, err)
+			}
+		case err != nil || got != tc.want:
+			t.Errorf("Marshal(%v, WithNonFinitePolicy(%d)) == %q, %v, want %q, <nil>", tc.g, tc.policy, got, err, tc.want)
+		}
+	}
+}
+
+// equalNaN returns whether g1 and g2 are equal, treating NaNs as equal.
+func equalNaN(g1, g2 geom.T) bool {
+	if reflect.TypeOf(g1) != reflect.TypeOf(g2) || g1.Layout() != g2.Layout() || !reflect.DeepEqual(g1.Ends(), g2.Ends()) {
+		return false
+	}
+	flatCoords1, flatCoords2 := g1.FlatCoords(), g2.FlatCoords()
+	if len(flatCoords1) != len(flatCoords2) {
+		return false
+	}
+	for i := range flatCoords1 {
+		if flatCoords1[i] != flatCoords2[i] && !(math.IsNaN(flatCoords1[i]) && math.IsNaN(flatCoords2[i])) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestNestedGeometryCollectionsRoundTrip(t *testing.T) {
+	for _, g := range []*geom.GeometryCollection{
+		geom.NewGeometryCollection().MustPush(
+			geom.NewGeometryCollection().MustPush(
+				geom.NewPointFlat(geom.XY, []float64{1, 2}),
+				geom.NewGeometryCollection().MustPush(
+					geom.NewPointFlat(geom.XYZ, []float64{1, 2, 3}),
+				),
+			),
+			geom.NewPointFlat(geom.XYM, []float64{1, 2, 3}),
+		),
+		geom.NewGeometryCollection().MustPush(
+			geom.NewGeometryCollection().MustPush(
+				geom.NewGeometryCollection().MustPush(
+					geom.NewGeometryCollection(),
+					geom.NewLineStringFlat(geom.XYZM, []float64{1, 2, 3, 4, 5, 6, 7, 8}),
+				),
+			),
+			geom.NewPolygonFlat(geom.XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}, []int{8}),
+			geom.NewMultiPointFlat(geom.XYZ, []float64{1, 2, 3}),
+		),
+	} {
+		s, err := Marshal(g)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := Unmarshal(s); err != nil || !reflect.DeepEqual(got, g) {
+			t.Errorf("Unmarshal(%q) == %v, %v, want %v, <nil>", s, got, err, g)
+		}
+	}
+}
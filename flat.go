@@ -105,7 +105,8 @@ func (g *geom1) Coord(i int) Coord {
 	return g.flatCoords[i*g.stride : (i+1)*g.stride]
 }
 
-// Coords unpacks and returns all of g's coordinates.
+// Coords unpacks and returns all of g's coordinates. New code should prefer
+// CoordsIter, which does not copy.
 func (g *geom1) Coords() []Coord {
 	return inflate1(g.flatCoords, 0, len(g.flatCoords), g.stride)
 }
@@ -137,7 +138,8 @@ func (g *geom1) verify() error {
 	return nil
 }
 
-// Coords returns all of g's coordinates.
+// Coords returns all of g's coordinates. New code should prefer EndsIter and
+// CoordsIter, which do not copy.
 func (g *geom2) Coords() [][]Coord {
 	return inflate2(g.flatCoords, 0, g.ends, g.stride)
 }
@@ -185,7 +187,8 @@ func (g *geom2) verify() error {
 	return nil
 }
 
-// Coords returns all the coordinates in g.
+// Coords returns all the coordinates in g. New code should prefer EndssIter,
+// EndsIter, and CoordsIter, which do not copy.
 func (g *geom3) Coords() [][][]Coord {
 	return inflate3(g.flatCoords, 0, g.endss, g.stride)
 }
@@ -367,6 +370,27 @@ func length2(flatCoords []float64, offset int, ends []int, stride int) float64 {
 	return length
 }
 
+// ringAreas2 returns the signed area of each ring, which is positive for
+// counterclockwise rings.
+func ringAreas2(flatCoords []float64, offset int, ends []int, stride int) []float64 {
+	areas := make([]float64, len(ends))
+	for i, end := range ends {
+		areas[i] = doubleArea1(flatCoords, offset, end, stride) / 2
+		offset = end
+	}
+	return areas
+}
+
+// lengths2 returns the length of each line or ring.
+func lengths2(flatCoords []float64, offset int, ends []int, stride int) []float64 {
+	lengths := make([]float64, len(ends))
+	for i, end := range ends {
+		lengths[i] = length1(flatCoords, offset, end, stride)
+		offset = end
+	}
+	return lengths
+}
+
 func length3(flatCoords []float64, offset int, endss [][]int, stride int) float64 {
 	var length float64
 	for _, ends := range endss {
@@ -375,3 +399,47 @@ func length3(flatCoords []float64, offset int, endss [][]int, stride int) float6
 	}
 	return length
 }
+
+// surfaceArea1 returns the area of the planar ring in three dimensions,
+// computed with Newell's method. Z is taken to be zero if zIndex is -1.
+func surfaceArea1(flatCoords []float64, offset, end, stride, zIndex int) float64 {
+	var nx, ny, nz float64
+	for i := offset + stride; i < end; i += stride {
+		x0, y0, x1, y1 := flatCoords[i-stride], flatCoords[i+1-stride], flatCoords[i], flatCoords[i+1]
+		var z0, z1 float64
+		if zIndex != -1 {
+			z0, z1 = flatCoords[i-stride+zIndex], flatCoords[i+zIndex]
+		}
+		nx += (y0 - y1) * (z0 + z1)
+		ny += (z0 - z1) * (x0 + x1)
+		nz += (x0 - x1) * (y0 + y1)
+	}
+	return math.Sqrt(nx*nx+ny*ny+nz*nz) / 2
+}
+
+// surfaceArea2 returns the area of the planar polygon in three dimensions,
+// i.e. the area of its first ring less the areas of its other rings.
+func surfaceArea2(flatCoords []float64, offset int, ends []int, stride, zIndex int) float64 {
+	var area float64
+	for i, end := range ends {
+		if a := surfaceArea1(flatCoords, offset, end, stride, zIndex); i == 0 {
+			area = a
+		} else {
+			area -= a
+		}
+		offset = end
+	}
+	return area
+}
+
+func surfaceArea3(flatCoords []float64, offset int, endss [][]int, stride, zIndex int) float64 {
+	var area float64
+	for _, ends := range endss {
+		if len(ends) == 0 {
+			continue
+		}
+		area += surfaceArea2(flatCoords, offset, ends, stride, zIndex)
+		offset = ends[len(ends)-1]
+	}
+	return area
+}
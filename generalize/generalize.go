@@ -0,0 +1,121 @@
+// Package generalize produces generalized copies of GeoJSON
+// FeatureCollections for display at web map zoom levels.
+//
+// Coordinates are assumed to be longitudes and latitudes in degrees, as
+// required by GeoJSON, and tolerances are derived from the size of a pixel at
+// the equator in 256×256 pixel Web Mercator tiles.
+package generalize
+
+import (
+	"math"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/geojson"
+	"github.com/twpayne/go-geom/xy"
+)
+
+// TileSize is the size of a map tile in pixels.
+const TileSize = 256
+
+// A Preset contains the generalization parameters for a zoom level.
+type Preset struct {
+	// Zoom is the zoom level.
+	Zoom int
+	// Tolerance is the Douglas-Peucker simplification tolerance, in degrees.
+	Tolerance float64
+	// MinSize is the minimum size of a feature, in degrees. Features whose
+	// bounds are smaller than MinSize in both dimensions are dropped. Points
+	// and MultiPoints are never dropped.
+	MinSize float64
+}
+
+// PixelSize returns the size of a pixel at the equator at zoom level zoom, in
+// degrees.
+func PixelSize(zoom int) float64 {
+	return 360.0 / TileSize / math.Exp2(float64(zoom))
+}
+
+// ZoomPreset returns the default Preset for zoom level zoom, which simplifies
+// with a tolerance of half a pixel and drops features smaller than a pixel.
+func ZoomPreset(zoom int) Preset {
+	pixelSize := PixelSize(zoom)
+	return Preset{
+		Zoom:      zoom,
+		Tolerance: pixelSize / 2,
+		MinSize:   pixelSize,
+	}
+}
+
+// ZoomPresets returns the default Presets for zoom levels minZoom to maxZoom
+// inclusive.
+func ZoomPresets(minZoom, maxZoom int) []Preset {
+	var presets []Preset
+	for zoom := minZoom; zoom <= maxZoom; zoom++ {
+		presets = append(presets, ZoomPreset(zoom))
+	}
+	return presets
+}
+
+// FeatureCollection returns a generalized copy of fc for each preset. The
+// copies share their features' IDs and Properties with fc.
+func FeatureCollection(fc *geojson.FeatureCollection, presets []Preset) ([]*geojson.FeatureCollection, error) {
+	result := make([]*geojson.FeatureCollection, len(presets))
+	for i, preset := range presets {
+		generalized, err := preset.FeatureCollection(fc)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = generalized
+	}
+	return result, nil
+}
+
+// FeatureCollection returns a copy of fc generalized according to p.
+func (p Preset) FeatureCollection(fc *geojson.FeatureCollection) (*geojson.FeatureCollection, error) {
+	generalized := &geojson.FeatureCollection{
+		BBox: fc.BBox,
+	}
+	for _, f := range fc.Features {
+		g, ok, err := p.Geometry(f.Geometry)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		feature := &geojson.Feature{
+			ID:         f.ID,
+			Geometry:   g,
+			Properties: f.Properties,
+		}
+		if f.BBox != nil && g != nil {
+			feature.BBox = g.Bounds()
+		}
+		generalized.Features = append(generalized.Features, feature)
+	}
+	return generalized, nil
+}
+
+// Geometry returns g generalized according to p. ok is false if g should be
+// dropped because it is too small or collapses when simplified. A nil g is
+// returned unchanged.
+func (p Preset) Geometry(g geom.T) (generalized geom.T, ok bool, err error) {
+	if g == nil {
+		return nil, true, nil
+	}
+	switch g.(type) {
+	case *geom.Point, *geom.MultiPoint:
+		return g, true, nil
+	}
+	if b := g.Bounds(); b.IsEmpty() || b.Max(0)-b.Min(0) < p.MinSize && b.Max(1)-b.Min(1) < p.MinSize {
+		return nil, false, nil
+	}
+	generalized, err = xy.Simplify(g, p.Tolerance)
+	if err != nil {
+		return nil, false, err
+	}
+	if generalized.Bounds().IsEmpty() {
+		return nil, false, nil
+	}
+	return generalized, true, nil
+}
@@ -0,0 +1,102 @@
+package generalize
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/geojson"
+)
+
+func TestZoomPreset(t *testing.T) {
+	for _, tc := range []struct {
+		zoom int
+		want Preset
+	}{
+		{
+			zoom: 0,
+			want: Preset{Zoom: 0, Tolerance: 360.0 / 512, MinSize: 360.0 / 256},
+		},
+		{
+			zoom: 10,
+			want: Preset{Zoom: 10, Tolerance: 360.0 / 524288, MinSize: 360.0 / 262144},
+		},
+	} {
+		if got := ZoomPreset(tc.zoom); got != tc.want {
+			t.Errorf("ZoomPreset(%d) == %+v, want %+v", tc.zoom, got, tc.want)
+		}
+	}
+}
+
+func TestFeatureCollection(t *testing.T) {
+	properties := map[string]interface{}{"name": "a"}
+	// A wiggly line about 1 degree long with deviations of about 0.01
+	// degrees, a small polygon about 0.1 degrees across, and a point.
+	var lineFlatCoords []float64
+	for i := 0; i <= 100; i++ {
+		lineFlatCoords = append(lineFlatCoords, float64(i)/100, 0.01*math.Sin(float64(i)))
+	}
+	fc := &geojson.FeatureCollection{
+		Features: []*geojson.Feature{
+			{
+				ID:         "line",
+				Geometry:   geom.NewLineStringFlat(geom.XY, lineFlatCoords),
+				Properties: properties,
+			},
+			{
+				ID:       "polygon",
+				Geometry: geom.NewPolygonFlat(geom.XY, []float64{0, 0, 0.1, 0, 0.1, 0.1, 0, 0}, []int{8}),
+			},
+			{
+				ID:       "point",
+				Geometry: geom.NewPointFlat(geom.XY, []float64{0.5, 0.5}),
+			},
+			{
+				ID: "null",
+			},
+		},
+	}
+
+	presets := ZoomPresets(2, 12)
+	got, err := FeatureCollection(fc, presets)
+	if err != nil {
+		t.Fatalf("FeatureCollection(...) == _, %v, want _, <nil>", err)
+	}
+	if len(got) != len(presets) {
+		t.Fatalf("len(FeatureCollection(...)) == %d, want %d", len(got), len(presets))
+	}
+
+	ids := func(fc *geojson.FeatureCollection) []string {
+		var ids []string
+		for _, f := range fc.Features {
+			ids = append(ids, f.ID)
+		}
+		return ids
+	}
+	// At zoom 2 a pixel is about 0.35 degrees so the polygon is dropped and
+	// the line is simplified to its endpoints.
+	if want := []string{"line", "point", "null"}; !reflect.DeepEqual(ids(got[0]), want) {
+		t.Errorf("zoom 2: got features %v, want %v", ids(got[0]), want)
+	}
+	if n := got[0].Features[0].Geometry.(*geom.LineString).NumCoords(); n != 2 {
+		t.Errorf("zoom 2: got %d coords, want 2", n)
+	}
+	if !reflect.DeepEqual(got[0].Features[0].Properties, properties) {
+		t.Errorf("zoom 2: got properties %v, want %v", got[0].Features[0].Properties, properties)
+	}
+	// At zoom 12 a pixel is about 0.0003 degrees so every feature is kept and
+	// the line keeps its detail.
+	if want := []string{"line", "polygon", "point", "null"}; !reflect.DeepEqual(ids(got[10]), want) {
+		t.Errorf("zoom 12: got features %v, want %v", ids(got[10]), want)
+	}
+	// The number of coordinates is non-decreasing with zoom.
+	prev := 0
+	for i, fc := range got {
+		n := fc.Features[0].Geometry.(*geom.LineString).NumCoords()
+		if n < prev {
+			t.Errorf("zoom %d: got %d coords, want at least %d", presets[i].Zoom, n, prev)
+		}
+		prev = n
+	}
+}
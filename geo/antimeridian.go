@@ -0,0 +1,415 @@
+package geo
+
+import (
+	"errors"
+	"math"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/internal/planar"
+)
+
+// ErrCannotCut is returned by CutAntimeridian when a polygon cannot be cut at
+// the antimeridian because it encloses a pole or is more than 360° wide.
+var ErrCannotCut = errors.New("geo: polygon encloses a pole or is too wide to cut at the antimeridian")
+
+// CutAntimeridian returns a copy of g in which lines and polygons that cross
+// the antimeridian are cut into parts on either side of it, as recommended by
+// RFC 7946 section 3.1.9, so that they render correctly on web maps.
+// Consecutive coordinates are assumed to be joined by the shortest path in
+// longitude, so an edge from 170° to -170° crosses the antimeridian, and all
+// longitudes in the result are between -180° and 180°. Crossing points are
+// interpolated linearly in longitude and latitude, and in other ordinates.
+//
+// Lines that are cut become MultiLineStrings and polygons that are cut become
+// MultiPolygons. Polygons in the result follow the right-hand rule, with
+// counterclockwise exterior rings and clockwise holes. Points are returned
+// unchanged.
+func CutAntimeridian(g geom.T) (geom.T, error) {
+	switch g := g.(type) {
+	case *geom.Point, *geom.MultiPoint:
+		return g, nil
+	case *geom.LineString:
+		parts := cutLine(g.FlatCoords(), g.Stride())
+		switch len(parts) {
+		case 0:
+			return geom.NewLineString(g.Layout()).SetSRID(g.SRID()), nil
+		case 1:
+			return geom.NewLineStringFlat(g.Layout(), parts[0]).SetSRID(g.SRID()), nil
+		}
+		mls := geom.NewMultiLineString(g.Layout()).SetSRID(g.SRID())
+		for _, part := range parts {
+			if err := mls.Push(geom.NewLineStringFlat(g.Layout(), part)); err != nil {
+				return nil, err
+			}
+		}
+		return mls, nil
+	case *geom.MultiLineString:
+		mls := geom.NewMultiLineString(g.Layout()).SetSRID(g.SRID())
+		for i := 0; i < g.NumLineStrings(); i++ {
+			for _, part := range cutLine(g.LineString(i).FlatCoords(), g.Stride()) {
+				if err := mls.Push(geom.NewLineStringFlat(g.Layout(), part)); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return mls, nil
+	case *geom.Polygon:
+		polygons, err := cutPolygon(g)
+		if err != nil {
+			return nil, err
+		}
+		if len(polygons) == 1 {
+			return polygons[0].SetSRID(g.SRID()), nil
+		}
+		mp := geom.NewMultiPolygon(g.Layout()).SetSRID(g.SRID())
+		for _, p := range polygons {
+			if err := mp.Push(p); err != nil {
+				return nil, err
+			}
+		}
+		return mp, nil
+	case *geom.MultiPolygon:
+		mp := geom.NewMultiPolygon(g.Layout()).SetSRID(g.SRID())
+		for i := 0; i < g.NumPolygons(); i++ {
+			polygons, err := cutPolygon(g.Polygon(i))
+			if err != nil {
+				return nil, err
+			}
+			for _, p := range polygons {
+				if err := mp.Push(p); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return mp, nil
+	case *geom.GeometryCollection:
+		gc := geom.NewGeometryCollection().SetSRID(g.SRID())
+		for _, member := range g.Geoms() {
+			cut, err := CutAntimeridian(member)
+			if err != nil {
+				return nil, err
+			}
+			if err := gc.Push(cut); err != nil {
+				return nil, err
+			}
+		}
+		return gc, nil
+	default:
+		return nil, geom.ErrUnsupportedType{Value: g}
+	}
+}
+
+// unwrap returns a copy of flatCoords in which each longitude differs from
+// the previous one by at most 180°, so that lines do not jump across the
+// antimeridian.
+func unwrap(flatCoords []float64, stride int) []float64 {
+	unwrapped := append([]float64(nil), flatCoords...)
+	for i := stride; i < len(unwrapped); i += stride {
+		dx := flatCoords[i] - flatCoords[i-stride]
+		dx -= 360 * math.Round(dx/360)
+		unwrapped[i] = unwrapped[i-stride] + dx
+	}
+	return unwrapped
+}
+
+// shiftX adds dx to the longitudes of flatCoords.
+func shiftX(flatCoords []float64, stride int, dx float64) {
+	if dx == 0 {
+		return
+	}
+	for i := 0; i < len(flatCoords); i += stride {
+		flatCoords[i] += dx
+	}
+}
+
+// strip returns the index k of the 360°-wide strip centered on 360k that
+// contains x.
+func strip(x float64) float64 {
+	return math.Floor((x + 180) / 360)
+}
+
+// crossing returns the coordinate at longitude x on the edge from a to b.
+func crossing(a, b []float64, x float64) []float64 {
+	t := (x - a[0]) / (b[0] - a[0])
+	c := make([]float64, len(a))
+	for i := range c {
+		c[i] = a[i] + t*(b[i]-a[i])
+	}
+	c[0] = x
+	return c
+}
+
+// cutLine cuts the line flatCoords at the antimeridian, returning its parts
+// with longitudes between -180° and 180°.
+func cutLine(flatCoords []float64, stride int) [][]float64 {
+	if len(flatCoords) == 0 {
+		return nil
+	}
+	unwrapped := unwrap(flatCoords, stride)
+	var parts [][]float64
+	part := append([]float64(nil), unwrapped[:stride]...)
+	for i := stride; i < len(unwrapped); i += stride {
+		a, b := unwrapped[i-stride:i], unwrapped[i:i+stride]
+		// Cut at each antimeridian, at 180° + 360k, strictly between a and b.
+		switch {
+		case a[0] < b[0]:
+			for x := 360*strip(a[0]) + 180; x < b[0]; x += 360 {
+				c := crossing(a, b, x)
+				parts = append(parts, append(part, c...))
+				part = c
+			}
+		case a[0] > b[0]:
+			for x := 360*strip(a[0]) - 180; x > b[0]; x -= 360 {
+				if x < a[0] {
+					c := crossing(a, b, x)
+					parts = append(parts, append(part, c...))
+					part = c
+				}
+			}
+		}
+		part = append(part, b...)
+	}
+	parts = append(parts, part)
+	for _, part := range parts {
+		minX, maxX := part[0], part[0]
+		for i := stride; i < len(part); i += stride {
+			minX, maxX = math.Min(minX, part[i]), math.Max(maxX, part[i])
+		}
+		shiftX(part, stride, -360*strip((minX+maxX)/2))
+	}
+	return parts
+}
+
+// A ringSegment is a part of a ring on one side of the antimeridian that
+// starts and ends on it.
+type ringSegment struct {
+	flatCoords []float64
+	west       bool
+	used       bool
+}
+
+func (s *ringSegment) startY() float64 {
+	return s.flatCoords[1]
+}
+
+func (s *ringSegment) endY(stride int) float64 {
+	return s.flatCoords[len(s.flatCoords)-stride+1]
+}
+
+// cutPolygon cuts p at the antimeridian, returning the resulting polygons with
+// longitudes between -180° and 180°.
+func cutPolygon(p *geom.Polygon) ([]*geom.Polygon, error) {
+	layout, stride := p.Layout(), p.Stride()
+	if p.NumLinearRings() == 0 {
+		return []*geom.Polygon{geom.NewPolygon(layout)}, nil
+	}
+
+	// Unwrap and orient the rings, and move the holes next to the exterior
+	// ring.
+	rings := make([][]float64, p.NumLinearRings())
+	var minX, maxX float64
+	for i := range rings {
+		ring := unwrap(p.LinearRing(i).FlatCoords(), stride)
+		n := len(ring)
+		if n < stride {
+			return nil, ErrCannotCut
+		}
+		if math.Abs(ring[n-stride]-ring[0]) > 180 {
+			return nil, ErrCannotCut
+		}
+		ring[n-stride] = ring[0]
+		if area := planar.SignedArea(ring, stride); (area < 0) == (i == 0) {
+			planar.Reverse(ring, stride)
+		}
+		if i == 0 {
+			minX, maxX = ring[0], ring[0]
+			for j := stride; j < n; j += stride {
+				minX, maxX = math.Min(minX, ring[j]), math.Max(maxX, ring[j])
+			}
+			if maxX-minX >= 360 {
+				return nil, ErrCannotCut
+			}
+		} else {
+			shiftX(ring, stride, -360*math.Round((ring[0]-(minX+maxX)/2)/360))
+		}
+		rings[i] = ring
+	}
+
+	// If the exterior ring does not cross an antimeridian then the polygon
+	// only needs to be moved.
+	k := strip(minX)
+	antimeridian := 360*k + 180
+	if maxX <= antimeridian {
+		flatCoords := make([]float64, 0, len(p.FlatCoords()))
+		ends := make([]int, 0, len(rings))
+		for _, ring := range rings {
+			flatCoords = append(flatCoords, ring...)
+			ends = append(ends, len(flatCoords))
+		}
+		shiftX(flatCoords, stride, -360*k)
+		return []*geom.Polygon{geom.NewPolygonFlat(layout, flatCoords, ends)}, nil
+	}
+
+	// Split the rings into segments on either side of the antimeridian, and
+	// keep the holes that do not cross it. The exterior ring has coordinates
+	// on both sides, so it always crosses.
+	var segments []*ringSegment
+	var westHoles, eastHoles [][]float64
+	for _, ring := range rings {
+		ringSegments, west := splitRing(ring, stride, antimeridian)
+		switch {
+		case ringSegments != nil:
+			segments = append(segments, ringSegments...)
+		case west:
+			westHoles = append(westHoles, ring)
+		default:
+			eastHoles = append(eastHoles, ring)
+		}
+	}
+
+	// Join the segments into exterior rings. Rings are oriented with the
+	// interior on their left, so west of the antimeridian the boundary
+	// continues north along it, and east of it south.
+	var westPolygons, eastPolygons [][][]float64
+	for _, first := range segments {
+		if first.used {
+			continue
+		}
+		var ring []float64
+		for s := first; ; {
+			s.used = true
+			ring = append(ring, s.flatCoords...)
+			s = nextSegment(segments, s, first, stride)
+			if s == first {
+				break
+			}
+		}
+		ring = append(ring, first.flatCoords[:stride]...)
+		if first.west {
+			westPolygons = append(westPolygons, [][]float64{ring})
+		} else {
+			eastPolygons = append(eastPolygons, [][]float64{ring})
+		}
+	}
+	westPolygons = addHoles(westPolygons, westHoles, stride)
+	eastPolygons = addHoles(eastPolygons, eastHoles, stride)
+
+	var polygons []*geom.Polygon
+	for _, side := range []struct {
+		polygons [][][]float64
+		dx       float64
+	}{
+		{westPolygons, -360 * k},
+		{eastPolygons, -360 * (k + 1)},
+	} {
+		for _, polygon := range side.polygons {
+			var flatCoords []float64
+			ends := make([]int, 0, len(polygon))
+			for _, ring := range polygon {
+				flatCoords = append(flatCoords, ring...)
+				ends = append(ends, len(flatCoords))
+			}
+			shiftX(flatCoords, stride, side.dx)
+			polygons = append(polygons, geom.NewPolygonFlat(layout, flatCoords, ends))
+		}
+	}
+	return polygons, nil
+}
+
+// splitRing splits the closed ring at longitude x into segments that start
+// and end at x. If ring does not cross x, it returns nil and whether ring is
+// west of x.
+func splitRing(ring []float64, stride int, x float64) ([]*ringSegment, bool) {
+	// Start at a coordinate that is not on x.
+	n := len(ring) - stride
+	start := -1
+	for i := 0; i < n; i += stride {
+		if ring[i] != x {
+			start = i
+			break
+		}
+	}
+	if start == -1 {
+		return nil, true
+	}
+	west := ring[start] < x
+	var segments []*ringSegment
+	segment := append([]float64(nil), ring[start:start+stride]...)
+	prevOnX := false
+	for j := stride; j <= n; j += stride {
+		i := (start + j) % n
+		prev, c := ring[(start+j-stride)%n:(start+j-stride)%n+stride], ring[i:i+stride]
+		switch {
+		case c[0] == x:
+			segment = append(segment, c...)
+			prevOnX = true
+			continue
+		case (c[0] < x) == west:
+			segment = append(segment, c...)
+		case prevOnX:
+			segments = append(segments, &ringSegment{flatCoords: segment, west: west})
+			segment = append(append([]float64(nil), prev...), c...)
+			west = !west
+		default:
+			cx := crossing(prev, c, x)
+			segments = append(segments, &ringSegment{flatCoords: append(segment, cx...), west: west})
+			segment = append(cx, c...)
+			west = !west
+		}
+		prevOnX = false
+	}
+	if segments == nil {
+		return nil, west
+	}
+	// The last segment continues into the first.
+	segments[0].flatCoords = append(segment, segments[0].flatCoords[stride:]...)
+	return segments, false
+}
+
+// nextSegment returns the segment that continues the boundary of the
+// polygon after s, which is the segment on the same side whose start is
+// nearest to the end of s along the antimeridian in the direction of travel.
+// It returns first to close the ring.
+func nextSegment(segments []*ringSegment, s, first *ringSegment, stride int) *ringSegment {
+	endY := s.endY(stride)
+	next := first
+	nextDistance := math.Inf(1)
+	for _, candidate := range segments {
+		if candidate.west != s.west || candidate.used && candidate != first {
+			continue
+		}
+		distance := candidate.startY() - endY
+		if !s.west {
+			distance = -distance
+		}
+		if distance >= 0 && distance < nextDistance {
+			next, nextDistance = candidate, distance
+		}
+	}
+	return next
+}
+
+// addHoles adds each hole to the polygon that contains it.
+func addHoles(polygons [][][]float64, holes [][]float64, stride int) [][][]float64 {
+	for _, hole := range holes {
+		for i, polygon := range polygons {
+			if i == len(polygons)-1 || ringContains(polygon[0], stride, hole[0], hole[1]) {
+				polygons[i] = append(polygon, hole)
+				break
+			}
+		}
+	}
+	return polygons
+}
+
+// ringContains reports whether the closed ring contains (x, y).
+func ringContains(ring []float64, stride int, x, y float64) bool {
+	inside := false
+	for i := stride; i < len(ring); i += stride {
+		x1, y1, x2, y2 := ring[i-stride], ring[i-stride+1], ring[i], ring[i+1]
+		if (y1 > y) != (y2 > y) && x < x1+(y-y1)*(x2-x1)/(y2-y1) {
+			inside = !inside
+		}
+	}
+	return inside
+}
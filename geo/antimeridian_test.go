@@ -0,0 +1,115 @@
+package geo
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+)
+
+func TestCutAntimeridian(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		g    geom.T
+		want geom.T
+	}{
+		{
+			name: "point",
+			g:    geom.NewPointFlat(geom.XY, []float64{190, 0}),
+			want: geom.NewPointFlat(geom.XY, []float64{190, 0}),
+		},
+		{
+			name: "line_not_crossing",
+			g:    geom.NewLineStringFlat(geom.XY, []float64{-10, 0, 10, 10}),
+			want: geom.NewLineStringFlat(geom.XY, []float64{-10, 0, 10, 10}),
+		},
+		{
+			name: "line_crossing",
+			g:    geom.NewLineStringFlat(geom.XYM, []float64{170, 0, 0, 175, 5, 1, -175, 15, 3, -170, 15, 4}).SetSRID(4326),
+			want: geom.NewMultiLineStringFlat(geom.XYM, []float64{
+				170, 0, 0, 175, 5, 1, 180, 10, 2,
+				-180, 10, 2, -175, 15, 3, -170, 15, 4,
+			}, []int{9, 18}).SetSRID(4326),
+		},
+		{
+			name: "line_crossing_westwards_twice",
+			g:    geom.NewLineStringFlat(geom.XY, []float64{-170, 0, 170, 10, -170, 20}),
+			want: geom.NewMultiLineStringFlat(geom.XY, []float64{
+				-170, 0, -180, 5,
+				180, 5, 170, 10, 180, 15,
+				-180, 15, -170, 20,
+			}, []int{4, 10, 14}),
+		},
+		{
+			name: "line_on_antimeridian",
+			g:    geom.NewLineStringFlat(geom.XY, []float64{170, 0, 180, 0, 170, 10}),
+			want: geom.NewLineStringFlat(geom.XY, []float64{170, 0, 180, 0, 170, 10}),
+		},
+		{
+			name: "multilinestring",
+			g:    geom.NewMultiLineStringFlat(geom.XY, []float64{0, 0, 1, 1, 170, 0, -170, 10}, []int{4, 8}),
+			want: geom.NewMultiLineStringFlat(geom.XY, []float64{0, 0, 1, 1, 170, 0, 180, 5, -180, 5, -170, 10}, []int{4, 8, 12}),
+		},
+		{
+			name: "polygon_not_crossing",
+			g:    geom.NewPolygonFlat(geom.XY, []float64{0, 0, 0, 10, 10, 10, 0, 0}, []int{8}),
+			want: geom.NewPolygonFlat(geom.XY, []float64{0, 0, 10, 10, 0, 10, 0, 0}, []int{8}),
+		},
+		{
+			name: "polygon_outside_range",
+			g:    geom.NewPolygonFlat(geom.XY, []float64{190, 0, 200, 0, 200, 10, 190, 0}, []int{8}),
+			want: geom.NewPolygonFlat(geom.XY, []float64{-170, 0, -160, 0, -160, 10, -170, 0}, []int{8}),
+		},
+		{
+			name: "polygon_crossing",
+			g: geom.NewPolygonFlat(geom.XY, []float64{
+				170, 0, -170, 0, -170, 10, 170, 10, 170, 0,
+				172, 2, 174, 4, 174, 2, 172, 2,
+				-172, 2, -174, 4, -172, 4, -172, 2,
+			}, []int{10, 18, 26}).SetSRID(4326),
+			want: geom.NewMultiPolygonFlat(geom.XY, []float64{
+				180, 10, 170, 10, 170, 0, 180, 0, 180, 10,
+				172, 2, 174, 4, 174, 2, 172, 2,
+				-180, 0, -170, 0, -170, 10, -180, 10, -180, 0,
+				-172, 2, -174, 4, -172, 4, -172, 2,
+			}, [][]int{{10, 18}, {28, 36}}).SetSRID(4326),
+		},
+		{
+			name: "polygon_hole_crossing",
+			g: geom.NewPolygonFlat(geom.XY, []float64{
+				170, 0, -170, 0, -170, 10, 170, 10, 170, 0,
+				175, 2, 175, 8, -175, 8, -175, 2, 175, 2,
+			}, []int{10, 20}),
+			want: geom.NewMultiPolygonFlat(geom.XY, []float64{
+				180, 10, 170, 10, 170, 0, 180, 0, 180, 2, 175, 2, 175, 8, 180, 8, 180, 10,
+				-180, 0, -170, 0, -170, 10, -180, 10, -180, 8, -175, 8, -175, 2, -180, 2, -180, 0,
+			}, [][]int{{18}, {36}}),
+		},
+		{
+			name: "polygon_concave",
+			// A C shape open to the east whose arms cross the antimeridian.
+			g: geom.NewPolygonFlat(geom.XY, []float64{
+				170, 0, -170, 0, -170, 2, 175, 2, 175, 8, -170, 8, -170, 10, 170, 10, 170, 0,
+			}, []int{18}),
+			want: geom.NewMultiPolygonFlat(geom.XY, []float64{
+				180, 10, 170, 10, 170, 0, 180, 0, 180, 2, 175, 2, 175, 8, 180, 8, 180, 10,
+				-180, 0, -170, 0, -170, 2, -180, 2, -180, 0,
+				-180, 8, -170, 8, -170, 10, -180, 10, -180, 8,
+			}, [][]int{{18}, {28}, {38}}),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got, err := CutAntimeridian(tc.g); err != nil || !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("CutAntimeridian(...) == %v, %v, want %v, <nil>", got, err, tc.want)
+			}
+		})
+	}
+}
+
+func TestCutAntimeridianPole(t *testing.T) {
+	// A ring around the north pole.
+	g := geom.NewPolygonFlat(geom.XY, []float64{0, 80, 90, 80, 180, 80, -90, 80, 0, 80}, []int{10})
+	if _, err := CutAntimeridian(g); err != ErrCannotCut {
+		t.Errorf("CutAntimeridian(...) == _, %v, want _, %v", err, ErrCannotCut)
+	}
+}
@@ -0,0 +1,191 @@
+package geo
+
+import (
+	"math"
+	"runtime"
+	"sync"
+)
+
+// defaultChunkSize is the default number of coordinates in each chunk
+// projected by ProjectFlat and UnprojectFlat.
+const defaultChunkSize = 16384
+
+// A FlatProjection is a Projection that can also project flat coordinates in
+// place. Its methods compute the parameters of the projection once per call,
+// rather than once per coordinate, and run tight loops without interface
+// calls, which makes them faster than calling Forward and Inverse for
+// each coordinate.
+type FlatProjection interface {
+	Projection
+	ForwardFlat(flatCoords []float64, stride int)
+	InverseFlat(flatCoords []float64, stride int)
+}
+
+// An Option configures ProjectFlat and UnprojectFlat.
+type Option func(*options)
+
+type options struct {
+	chunkSize int
+	workers   int
+}
+
+// WithChunkSize sets the number of coordinates in each chunk of work. The
+// default is 16384.
+func WithChunkSize(chunkSize int) Option {
+	return func(o *options) {
+		o.chunkSize = chunkSize
+	}
+}
+
+// WithWorkers sets the number of goroutines that project chunks
+// concurrently. If workers is zero then runtime.GOMAXPROCS(0) goroutines are
+// used. By default, coordinates are projected by the calling goroutine.
+func WithWorkers(workers int) Option {
+	return func(o *options) {
+		if workers == 0 {
+			workers = runtime.GOMAXPROCS(0)
+		}
+		o.workers = workers
+	}
+}
+
+// ProjectFlat projects the longitudes and latitudes of flatCoords, which
+// has the given stride, in place with p. Ordinates after X and Y are
+// unchanged. If p is a FlatProjection then its ForwardFlat method is used.
+// It is intended for reprojecting large numbers of coordinates, for example
+// during ingestion, before geometries are constructed from them.
+func ProjectFlat(flatCoords []float64, stride int, p Projection, opts ...Option) {
+	f := func(flatCoords []float64, stride int) {
+		mapFlat(flatCoords, stride, p.Forward)
+	}
+	if fp, ok := p.(FlatProjection); ok {
+		f = fp.ForwardFlat
+	}
+	transformFlat(flatCoords, stride, f, opts)
+}
+
+// UnprojectFlat unprojects the X and Y coordinates of flatCoords, which has
+// the given stride, in place with p to longitudes and latitudes. Ordinates
+// after X and Y are unchanged. If p is a FlatProjection then its InverseFlat
+// method is used.
+func UnprojectFlat(flatCoords []float64, stride int, p Projection, opts ...Option) {
+	f := func(flatCoords []float64, stride int) {
+		mapFlat(flatCoords, stride, p.Inverse)
+	}
+	if fp, ok := p.(FlatProjection); ok {
+		f = fp.InverseFlat
+	}
+	transformFlat(flatCoords, stride, f, opts)
+}
+
+// transformFlat applies f to flatCoords in chunks, concurrently if
+// configured by opts.
+func transformFlat(flatCoords []float64, stride int, f func([]float64, int), opts []Option) {
+	o := options{
+		chunkSize: defaultChunkSize,
+		workers:   1,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	chunkLen := o.chunkSize * stride
+	if o.workers <= 1 || chunkLen <= 0 || len(flatCoords) <= chunkLen {
+		f(flatCoords, stride)
+		return
+	}
+	chunks := make(chan []float64)
+	var wg sync.WaitGroup
+	for i := 0; i < o.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for chunk := range chunks {
+				f(chunk, stride)
+			}
+		}()
+	}
+	for i := 0; i < len(flatCoords); i += chunkLen {
+		end := i + chunkLen
+		if end > len(flatCoords) {
+			end = len(flatCoords)
+		}
+		chunks <- flatCoords[i:end]
+	}
+	close(chunks)
+	wg.Wait()
+}
+
+// mapFlat applies f to the X and Y coordinates of flatCoords in place.
+func mapFlat(flatCoords []float64, stride int, f func(x, y float64) (float64, float64)) {
+	for i := 0; i+1 < len(flatCoords); i += stride {
+		xy := flatCoords[i : i+2]
+		xy[0], xy[1] = f(xy[0], xy[1])
+	}
+}
+
+// ForwardFlat implements FlatProjection.ForwardFlat.
+func (p *PolarStereographic) ForwardFlat(flatCoords []float64, stride int) {
+	scale, centralMeridian := p.scale(), p.CentralMeridian
+	sign := 1.0
+	if p.South {
+		sign = -1
+	}
+	for i := 0; i+1 < len(flatCoords); i += stride {
+		xy := flatCoords[i : i+2]
+		dLon := radians(xy[0] - centralMeridian)
+		rho := scale * math.Tan(math.Pi/4-sign*radians(xy[1])/2)
+		sinDLon, cosDLon := math.Sincos(dLon)
+		xy[0], xy[1] = rho*sinDLon, -sign*rho*cosDLon
+	}
+}
+
+// InverseFlat implements FlatProjection.InverseFlat.
+func (p *PolarStereographic) InverseFlat(flatCoords []float64, stride int) {
+	scale, centralMeridian := p.scale(), p.CentralMeridian
+	sign := 1.0
+	if p.South {
+		sign = -1
+	}
+	for i := 0; i+1 < len(flatCoords); i += stride {
+		xy := flatCoords[i : i+2]
+		x, y := xy[0], xy[1]
+		c := 2 * math.Atan(math.Hypot(x, y)/scale)
+		xy[0] = normalizeLon(centralMeridian + degrees(math.Atan2(x, -sign*y)))
+		xy[1] = degrees(sign * (math.Pi/2 - c))
+	}
+}
+
+// ForwardFlat implements FlatProjection.ForwardFlat.
+func (p *AzimuthalEquidistant) ForwardFlat(flatCoords []float64, stride int) {
+	lon1, lat1 := p.Lon, radians(p.Lat)
+	sinLat1, cosLat1 := math.Sincos(lat1)
+	for i := 0; i+1 < len(flatCoords); i += stride {
+		xy := flatCoords[i : i+2]
+		lat2 := radians(xy[1])
+		dLon := radians(xy[0] - lon1)
+		sinLat2, cosLat2 := math.Sincos(lat2)
+		sinDLon, cosDLon := math.Sincos(dLon)
+		sinHalfDLat, sinHalfDLon := math.Sin((lat2-lat1)/2), math.Sin(dLon/2)
+		h := sinHalfDLat*sinHalfDLat + cosLat1*cosLat2*sinHalfDLon*sinHalfDLon
+		c := 2 * math.Asin(math.Sqrt(math.Min(h, 1)))
+		azimuth := math.Atan2(sinDLon*cosLat2, cosLat1*sinLat2-sinLat1*cosLat2*cosDLon)
+		sinAzimuth, cosAzimuth := math.Sincos(azimuth)
+		xy[0], xy[1] = EarthRadius*c*sinAzimuth, EarthRadius*c*cosAzimuth
+	}
+}
+
+// InverseFlat implements FlatProjection.InverseFlat.
+func (p *AzimuthalEquidistant) InverseFlat(flatCoords []float64, stride int) {
+	lon1 := p.Lon
+	sinLat1, cosLat1 := math.Sincos(radians(p.Lat))
+	for i := 0; i+1 < len(flatCoords); i += stride {
+		xy := flatCoords[i : i+2]
+		c := math.Hypot(xy[0], xy[1]) / EarthRadius
+		sinC, cosC := math.Sincos(c)
+		sinAzimuth, cosAzimuth := math.Sincos(math.Atan2(xy[0], xy[1]))
+		sinLat := sinLat1*cosC + cosLat1*sinC*cosAzimuth
+		lat := math.Asin(math.Max(-1, math.Min(sinLat, 1)))
+		dLon := math.Atan2(sinAzimuth*sinC*cosLat1, cosC-sinLat1*sinLat)
+		xy[0], xy[1] = normalizeLon(lon1+degrees(dLon)), degrees(lat)
+	}
+}
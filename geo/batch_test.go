@@ -0,0 +1,84 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+// projectionFunc is a Projection that is not a FlatProjection.
+type projectionFunc struct {
+	Projection
+}
+
+// testFlatCoords returns n XYZ coordinates spread over the northern
+// hemisphere, with Z values equal to their indexes.
+func testFlatCoords(n int) []float64 {
+	flatCoords := make([]float64, 0, 3*n)
+	for i := 0; i < n; i++ {
+		lon := -180 + 360*float64(i)/float64(n)
+		lat := 1 + 88*math.Abs(math.Sin(float64(i)))
+		flatCoords = append(flatCoords, lon, lat, float64(i))
+	}
+	return flatCoords
+}
+
+func TestProjectFlat(t *testing.T) {
+	const n = 1000
+	for _, tc := range []struct {
+		name string
+		p    Projection
+		opts []Option
+	}{
+		{name: "polar_stereographic", p: &PolarStereographic{CentralMeridian: -45, TrueScaleLatitude: 70}},
+		{name: "polar_stereographic_south", p: &PolarStereographic{South: true}},
+		{name: "azimuthal_equidistant", p: &AzimuthalEquidistant{Lon: 10, Lat: 50}},
+		{name: "generic", p: projectionFunc{&AzimuthalEquidistant{Lon: 10, Lat: 50}}},
+		{name: "workers", p: &PolarStereographic{}, opts: []Option{WithWorkers(4), WithChunkSize(64)}},
+		{name: "default_workers", p: projectionFunc{&PolarStereographic{}}, opts: []Option{WithWorkers(0), WithChunkSize(7)}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			flatCoords := testFlatCoords(n)
+			if ps, ok := tc.p.(*PolarStereographic); ok && ps.South {
+				// Use the southern hemisphere.
+				for i := 1; i < len(flatCoords); i += 3 {
+					flatCoords[i] = -flatCoords[i]
+				}
+			}
+			original := append([]float64(nil), flatCoords...)
+			ProjectFlat(flatCoords, 3, tc.p, tc.opts...)
+			for i := 0; i < len(flatCoords); i += 3 {
+				wantX, wantY := tc.p.Forward(original[i], original[i+1])
+				if math.Abs(flatCoords[i]-wantX) > 1e-6 || math.Abs(flatCoords[i+1]-wantY) > 1e-6 || flatCoords[i+2] != original[i+2] {
+					t.Fatalf("ProjectFlat(...)[%d:%d] == %v, want [%v %v %v]", i, i+3, flatCoords[i:i+3], wantX, wantY, original[i+2])
+				}
+			}
+			UnprojectFlat(flatCoords, 3, tc.p, tc.opts...)
+			for i := 0; i < len(flatCoords); i += 3 {
+				if math.Abs(math.Remainder(flatCoords[i]-original[i], 360)) > 1e-9 || math.Abs(flatCoords[i+1]-original[i+1]) > 1e-9 {
+					t.Fatalf("UnprojectFlat(ProjectFlat(...))[%d:%d] == %v, want %v", i, i+3, flatCoords[i:i+3], original[i:i+3])
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkProjectFlat(b *testing.B) {
+	p := &PolarStereographic{CentralMeridian: -45, TrueScaleLatitude: 70}
+	flatCoords := testFlatCoords(1 << 16)
+	for _, bc := range []struct {
+		name string
+		p    Projection
+		opts []Option
+	}{
+		{name: "generic", p: projectionFunc{p}},
+		{name: "flat", p: p},
+		{name: "flat_parallel", p: p, opts: []Option{WithWorkers(0)}},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				ProjectFlat(flatCoords, 3, bc.p, bc.opts...)
+				UnprojectFlat(flatCoords, 3, bc.p, bc.opts...)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+// Package geo contains functions for geographic coordinates, i.e. longitude
+// and latitude in degrees, on a spherical model of the Earth.
+//
+// Paths between coordinates follow great circles, which approximate
+// geodesics on the WGS84 ellipsoid to within about 0.5%. Ordinates after
+// longitude and latitude, such as Z and M, are interpolated linearly.
+package geo
+
+import (
+	"math"
+
+	"github.com/twpayne/go-geom"
+)
+
+// EarthRadius is the mean radius of the Earth in meters.
+const EarthRadius = 6371008.8
+
+// A DistanceFunc returns the distance between two coordinates in meters.
+// Distance and EquirectangularDistance are DistanceFuncs.
+type DistanceFunc func(a, b geom.Coord) float64
+
+// Distance returns the great-circle distance between a and b in meters,
+// computed with the haversine formula.
+func Distance(a, b geom.Coord) float64 {
+	return EarthRadius * centralAngle(a, b)
+}
+
+// EquirectangularDistance returns an approximation of the great-circle
+// distance between a and b in meters, treating the Earth as flat around the
+// mean latitude of a and b. It is several times faster than Distance, and
+// its error is below 0.5% for points up to a few hundred kilometers apart
+// away from the poles, which makes it suitable for dense local data.
+func EquirectangularDistance(a, b geom.Coord) float64 {
+	dLon := radians(b.X() - a.X())
+	switch {
+	case dLon > math.Pi:
+		dLon -= 2 * math.Pi
+	case dLon < -math.Pi:
+		dLon += 2 * math.Pi
+	}
+	x := dLon * math.Cos(radians(a.Y()+b.Y())/2)
+	y := radians(b.Y() - a.Y())
+	return EarthRadius * math.Sqrt(x*x+y*y)
+}
+
+// Length returns the length of g in meters, measured with distance. The
+// length of a Polygon or MultiPolygon is the length of its rings, and the
+// length of a Point or MultiPoint is zero.
+func Length(g geom.T, distance DistanceFunc) float64 {
+	switch g := g.(type) {
+	case *geom.GeometryCollection:
+		length := 0.0
+		for _, member := range g.Geoms() {
+			length += Length(member, distance)
+		}
+		return length
+	case *geom.LineString, *geom.LinearRing:
+		return length1(g.FlatCoords(), g.Stride(), distance)
+	case *geom.Polygon, *geom.MultiLineString:
+		return length2(g.FlatCoords(), 0, g.Ends(), g.Stride(), distance)
+	case *geom.MultiPolygon:
+		length, offset := 0.0, 0
+		for _, ends := range g.Endss() {
+			length += length2(g.FlatCoords(), offset, ends, g.Stride(), distance)
+			if len(ends) > 0 {
+				offset = ends[len(ends)-1]
+			}
+		}
+		return length
+	default:
+		return 0
+	}
+}
+
+func length1(flatCoords []float64, stride int, distance DistanceFunc) float64 {
+	length := 0.0
+	for i := stride; i < len(flatCoords); i += stride {
+		length += distance(flatCoords[i-stride:i], flatCoords[i:i+stride])
+	}
+	return length
+}
+
+func length2(flatCoords []float64, offset int, ends []int, stride int, distance DistanceFunc) float64 {
+	length := 0.0
+	for _, end := range ends {
+		length += length1(flatCoords[offset:end], stride, distance)
+		offset = end
+	}
+	return length
+}
+
+// Midpoint returns the midpoint of the great-circle path between a and b. If
+// a and b are antipodal then the path is undefined and the result is one of
+// the possible midpoints.
+func Midpoint(a, b geom.Coord) geom.Coord {
+	return interpolate(a, b, 0.5)
+}
+
+// InterpolateGeodesic returns the coordinate at fraction of the great-circle
+// length of ls, where fraction is clamped to the range [0, 1]. It returns nil
+// if ls is empty.
+func InterpolateGeodesic(ls *geom.LineString, fraction float64) geom.Coord {
+	n := ls.NumCoords()
+	switch {
+	case n == 0:
+		return nil
+	case n == 1 || fraction <= 0:
+		return copyCoord(ls.Coord(0))
+	case fraction >= 1:
+		return copyCoord(ls.Coord(n - 1))
+	}
+	lengths := make([]float64, n-1)
+	total := 0.0
+	for i := range lengths {
+		lengths[i] = centralAngle(ls.Coord(i), ls.Coord(i+1))
+		total += lengths[i]
+	}
+	target := fraction * total
+	for i, length := range lengths {
+		if target <= length && length > 0 {
+			return interpolate(ls.Coord(i), ls.Coord(i+1), target/length)
+		}
+		target -= length
+	}
+	return copyCoord(ls.Coord(n - 1))
+}
+
+// centralAngle returns the angle in radians subtended at the center of the
+// Earth by a and b.
+func centralAngle(a, b geom.Coord) float64 {
+	lat1, lat2 := radians(a.Y()), radians(b.Y())
+	sinDLat := math.Sin((lat2 - lat1) / 2)
+	sinDLon := math.Sin(radians(b.X()-a.X()) / 2)
+	h := sinDLat*sinDLat + math.Cos(lat1)*math.Cos(lat2)*sinDLon*sinDLon
+	return 2 * math.Asin(math.Sqrt(math.Min(h, 1)))
+}
+
+// interpolate returns the coordinate at fraction f of the great-circle path
+// between a and b.
+func interpolate(a, b geom.Coord, f float64) geom.Coord {
+	result := make(geom.Coord, len(a))
+	for i := 2; i < len(a); i++ {
+		result[i] = a[i] + f*(b[i]-a[i])
+	}
+	ax, ay, az := unitVector(a)
+	bx, by, bz := unitVector(b)
+	d := centralAngle(a, b)
+	var wa, wb float64
+	if sinD := math.Sin(d); sinD < 1e-12 {
+		// a and b are coincident or antipodal, so interpolate linearly.
+		wa, wb = 1-f, f
+	} else {
+		wa, wb = math.Sin((1-f)*d)/sinD, math.Sin(f*d)/sinD
+	}
+	x, y, z := wa*ax+wb*bx, wa*ay+wb*by, wa*az+wb*bz
+	if math.Sqrt(x*x+y*y+z*z) < 1e-12 {
+		// a and b are antipodal and f is one half, so choose the great
+		// circle through the pole.
+		result[0], result[1] = a.X(), 90
+		return result
+	}
+	result[0] = degrees(math.Atan2(y, x))
+	result[1] = degrees(math.Atan2(z, math.Hypot(x, y)))
+	return result
+}
+
+func copyCoord(c geom.Coord) geom.Coord {
+	return append(geom.Coord(nil), c...)
+}
+
+// unitVector returns the unit vector from the center of the Earth to c.
+func unitVector(c geom.Coord) (x, y, z float64) {
+	lon, lat := radians(c.X()), radians(c.Y())
+	return math.Cos(lat) * math.Cos(lon), math.Cos(lat) * math.Sin(lon), math.Sin(lat)
+}
+
+func degrees(radians float64) float64 {
+	return radians * 180 / math.Pi
+}
+
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
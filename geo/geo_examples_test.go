@@ -0,0 +1,22 @@
+package geo_test
+
+import (
+	"fmt"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/geo"
+)
+
+func ExampleMidpoint() {
+	london := geom.Coord{-0.1278, 51.5074}
+	newYork := geom.Coord{-74.0060, 40.7128}
+	midpoint := geo.Midpoint(london, newYork)
+	fmt.Printf("%.2f %.2f\n", midpoint.X(), midpoint.Y())
+	// Output: -41.29 52.37
+}
+
+func ExampleInterpolateGeodesic() {
+	ls := geom.NewLineStringFlat(geom.XY, []float64{0, 0, 90, 0, 90, 90})
+	fmt.Println(geo.InterpolateGeodesic(ls, 0.75))
+	// Output: [90 45]
+}
@@ -0,0 +1,155 @@
+package geo
+
+import (
+	"math"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+)
+
+func coordsAlmostEqual(a, b geom.Coord, epsilon float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if math.Abs(a[i]-b[i]) > epsilon {
+			return false
+		}
+	}
+	return true
+}
+
+func TestDistance(t *testing.T) {
+	for _, tc := range []struct {
+		a, b geom.Coord
+		want float64
+	}{
+		{a: geom.Coord{0, 0}, b: geom.Coord{0, 0}, want: 0},
+		{a: geom.Coord{0, 0}, b: geom.Coord{0, 1}, want: EarthRadius * math.Pi / 180},
+		{a: geom.Coord{179.5, 0}, b: geom.Coord{-179.5, 0}, want: EarthRadius * math.Pi / 180},
+		{a: geom.Coord{0, 0}, b: geom.Coord{180, 0}, want: EarthRadius * math.Pi},
+		{a: geom.Coord{-0.1278, 51.5074}, b: geom.Coord{2.3522, 48.8566}, want: 343557},
+	} {
+		if got := Distance(tc.a, tc.b); math.Abs(got-tc.want) > 1 {
+			t.Errorf("Distance(%v, %v) == %f, want %f", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
+
+func TestMidpoint(t *testing.T) {
+	for _, tc := range []struct {
+		a, b geom.Coord
+		want geom.Coord
+	}{
+		{a: geom.Coord{0, 0}, b: geom.Coord{90, 0}, want: geom.Coord{45, 0}},
+		{a: geom.Coord{0, 0}, b: geom.Coord{0, 90}, want: geom.Coord{0, 45}},
+		{a: geom.Coord{179, 10}, b: geom.Coord{-179, 10}, want: geom.Coord{180, 10.0015}},
+		{a: geom.Coord{-10, 45}, b: geom.Coord{10, 45}, want: geom.Coord{0, 45.4385}},
+		{a: geom.Coord{1, 2, 10, 100}, b: geom.Coord{1, 2, 20, 200}, want: geom.Coord{1, 2, 15, 150}},
+		{a: geom.Coord{0, 0}, b: geom.Coord{180, 0}, want: geom.Coord{0, 90}},
+	} {
+		got := Midpoint(tc.a, tc.b)
+		if math.Abs(got[0]) == 180 && math.Abs(tc.want[0]) == 180 {
+			got[0] = tc.want[0]
+		}
+		if !coordsAlmostEqual(got, tc.want, 1e-4) {
+			t.Errorf("Midpoint(%v, %v) == %v, want %v", tc.a, tc.b, got, tc.want)
+		}
+		if d1, d2 := Distance(tc.a, got), Distance(got, tc.b); math.Abs(d1-d2) > 1e-6 {
+			t.Errorf("Midpoint(%v, %v) == %v is %f and %f from the ends", tc.a, tc.b, got, d1, d2)
+		}
+	}
+}
+
+func TestInterpolateGeodesic(t *testing.T) {
+	ls := geom.NewLineStringFlat(geom.XYM, []float64{
+		0, 0, 0,
+		0, 0, 0, // zero length segment
+		90, 0, 90,
+		90, 90, 180,
+	})
+	for _, tc := range []struct {
+		fraction float64
+		want     geom.Coord
+	}{
+		{fraction: -1, want: geom.Coord{0, 0, 0}},
+		{fraction: 0, want: geom.Coord{0, 0, 0}},
+		{fraction: 0.25, want: geom.Coord{45, 0, 45}},
+		{fraction: 0.5, want: geom.Coord{90, 0, 90}},
+		{fraction: 0.75, want: geom.Coord{90, 45, 135}},
+		{fraction: 1, want: geom.Coord{90, 90, 180}},
+		{fraction: 2, want: geom.Coord{90, 90, 180}},
+	} {
+		if got := InterpolateGeodesic(ls, tc.fraction); !coordsAlmostEqual(got, tc.want, 1e-9) {
+			t.Errorf("InterpolateGeodesic(%v, %f) == %v, want %v", ls.FlatCoords(), tc.fraction, got, tc.want)
+		}
+	}
+
+	got := InterpolateGeodesic(ls, 0)
+	got[0] = 1
+	if ls.FlatCoords()[0] != 0 {
+		t.Errorf("InterpolateGeodesic(...) returned a coordinate aliasing ls")
+	}
+	if got := InterpolateGeodesic(geom.NewLineString(geom.XY), 0.5); got != nil {
+		t.Errorf("InterpolateGeodesic(empty, 0.5) == %v, want <nil>", got)
+	}
+}
+
+func TestEquirectangularDistance(t *testing.T) {
+	for _, tc := range []struct {
+		a, b geom.Coord
+	}{
+		{a: geom.Coord{0, 0}, b: geom.Coord{0, 1}},
+		{a: geom.Coord{-0.1278, 51.5074}, b: geom.Coord{2.3522, 48.8566}},
+		{a: geom.Coord{179.9, 60}, b: geom.Coord{-179.9, 60.1}},
+		{a: geom.Coord{10, -45}, b: geom.Coord{12, -44}},
+		{a: geom.Coord{1, 2}, b: geom.Coord{1, 2}},
+	} {
+		want := Distance(tc.a, tc.b)
+		if got := EquirectangularDistance(tc.a, tc.b); math.Abs(got-want) > 0.005*want {
+			t.Errorf("EquirectangularDistance(%v, %v) == %f, want %f within 0.5%%", tc.a, tc.b, got, want)
+		}
+	}
+}
+
+func TestLength(t *testing.T) {
+	degree := EarthRadius * math.Pi / 180
+	ls := geom.NewLineStringFlat(geom.XY, []float64{0, 0, 0, 1, 0, 3})
+	for _, tc := range []struct {
+		g    geom.T
+		want float64
+	}{
+		{g: geom.NewPointFlat(geom.XY, []float64{1, 2}), want: 0},
+		{g: ls, want: 3 * degree},
+		{g: geom.NewMultiLineStringFlat(geom.XYZ, []float64{0, 0, 5, 0, 1, 5, 1, 0, 5, 1, 2, 5}, []int{6, 12}), want: 3 * degree},
+		{g: geom.NewMultiPolygonFlat(geom.XY, []float64{0, 0, 0, 1, 0, 0, 0, 0, 0, 2, 0, 0}, [][]int{{6}, {}, {12}}), want: 6 * degree},
+		{g: geom.NewGeometryCollection().MustPush(ls, geom.NewGeometryCollection().MustPush(ls)), want: 6 * degree},
+	} {
+		for _, distance := range []DistanceFunc{Distance, EquirectangularDistance} {
+			if got := Length(tc.g, distance); math.Abs(got-tc.want) > 1e-6 {
+				t.Errorf("Length(%v, ...) == %f, want %f", tc.g, got, tc.want)
+			}
+		}
+	}
+}
+
+func benchmarkDistanceFunc(b *testing.B, distance DistanceFunc) {
+	coords := make([]geom.Coord, 1024)
+	for i := range coords {
+		coords[i] = geom.Coord{-0.1 + float64(i%32)/1000, 51.5 + float64(i/32)/1000}
+	}
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		for i := 1; i < len(coords); i++ {
+			distance(coords[i-1], coords[i])
+		}
+	}
+}
+
+func BenchmarkDistance(b *testing.B) {
+	benchmarkDistanceFunc(b, Distance)
+}
+
+func BenchmarkEquirectangularDistance(b *testing.B) {
+	benchmarkDistanceFunc(b, EquirectangularDistance)
+}
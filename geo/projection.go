@@ -0,0 +1,160 @@
+package geo
+
+import (
+	"math"
+
+	"github.com/twpayne/go-geom"
+)
+
+// A Projection projects longitudes and latitudes in degrees to planar X and
+// Y coordinates in meters, and back.
+type Projection interface {
+	Forward(lon, lat float64) (x, y float64)
+	Inverse(x, y float64) (lon, lat float64)
+}
+
+// A PolarStereographic is a polar stereographic projection, which is
+// conformal and commonly used for Arctic and Antarctic data where Web
+// Mercator breaks down, for example by EPSG:3413 and EPSG:3031. It is
+// computed on a sphere of radius EarthRadius, so distances differ from those
+// of the ellipsoidal EPSG projections by up to about 0.5%.
+type PolarStereographic struct {
+	// South is whether the projection is centered on the South Pole instead
+	// of the North Pole.
+	South bool
+	// CentralMeridian is the longitude, in degrees, that runs straight down
+	// the map from the North Pole, or straight up from the South Pole.
+	CentralMeridian float64
+	// TrueScaleLatitude is the latitude, in degrees, at which the scale is
+	// true. Only its absolute value is used. If it is zero, the scale is true
+	// at the pole.
+	TrueScaleLatitude float64
+}
+
+// scale returns the scale of the distance from the pole, in meters, which is
+// twice the scale factor at the pole times EarthRadius.
+func (p *PolarStereographic) scale() float64 {
+	if p.TrueScaleLatitude == 0 {
+		return 2 * EarthRadius
+	}
+	return EarthRadius * (1 + math.Sin(radians(math.Abs(p.TrueScaleLatitude))))
+}
+
+// Forward implements Projection.Forward.
+func (p *PolarStereographic) Forward(lon, lat float64) (float64, float64) {
+	dLon := radians(lon - p.CentralMeridian)
+	if p.South {
+		rho := p.scale() * math.Tan(math.Pi/4+radians(lat)/2)
+		return rho * math.Sin(dLon), rho * math.Cos(dLon)
+	}
+	rho := p.scale() * math.Tan(math.Pi/4-radians(lat)/2)
+	return rho * math.Sin(dLon), -rho * math.Cos(dLon)
+}
+
+// Inverse implements Projection.Inverse.
+func (p *PolarStereographic) Inverse(x, y float64) (float64, float64) {
+	c := 2 * math.Atan(math.Hypot(x, y)/p.scale())
+	if p.South {
+		return normalizeLon(p.CentralMeridian + degrees(math.Atan2(x, y))), degrees(c - math.Pi/2)
+	}
+	return normalizeLon(p.CentralMeridian + degrees(math.Atan2(x, -y))), degrees(math.Pi/2 - c)
+}
+
+// An AzimuthalEquidistant is an azimuthal equidistant projection centered on
+// a longitude and latitude. Distances and directions from the center are
+// true, so it is useful for measuring around a point, including at the
+// poles. It is computed on a sphere of radius EarthRadius, so distances from
+// the center are great-circle distances.
+type AzimuthalEquidistant struct {
+	// Lon and Lat are the longitude and latitude of the center, in degrees.
+	Lon, Lat float64
+}
+
+// Forward implements Projection.Forward.
+func (p *AzimuthalEquidistant) Forward(lon, lat float64) (float64, float64) {
+	lat1, lat2 := radians(p.Lat), radians(lat)
+	dLon := radians(lon - p.Lon)
+	c := centralAngle(geom.Coord{p.Lon, p.Lat}, geom.Coord{lon, lat})
+	azimuth := math.Atan2(math.Sin(dLon)*math.Cos(lat2), math.Cos(lat1)*math.Sin(lat2)-math.Sin(lat1)*math.Cos(lat2)*math.Cos(dLon))
+	return EarthRadius * c * math.Sin(azimuth), EarthRadius * c * math.Cos(azimuth)
+}
+
+// Inverse implements Projection.Inverse.
+func (p *AzimuthalEquidistant) Inverse(x, y float64) (float64, float64) {
+	lat1 := radians(p.Lat)
+	c := math.Hypot(x, y) / EarthRadius
+	azimuth := math.Atan2(x, y)
+	sinLat := math.Sin(lat1)*math.Cos(c) + math.Cos(lat1)*math.Sin(c)*math.Cos(azimuth)
+	lat := math.Asin(math.Max(-1, math.Min(sinLat, 1)))
+	dLon := math.Atan2(math.Sin(azimuth)*math.Sin(c)*math.Cos(lat1), math.Cos(c)-math.Sin(lat1)*sinLat)
+	return normalizeLon(p.Lon + degrees(dLon)), degrees(lat)
+}
+
+// Project returns a copy of g with its longitudes and latitudes projected
+// with p. Ordinates after X and Y, such as Z and M, are copied unchanged. The
+// result has no SRID, as the projection does not know its own.
+func Project(g geom.T, p Projection) (geom.T, error) {
+	return mapXY(g, p.Forward)
+}
+
+// Unproject returns a copy of g with its X and Y coordinates unprojected
+// with p to longitudes and latitudes. Ordinates after X and Y are copied
+// unchanged. The result has no SRID.
+func Unproject(g geom.T, p Projection) (geom.T, error) {
+	return mapXY(g, p.Inverse)
+}
+
+// mapXY returns a copy of g, without its SRID, with f applied to the X and Y
+// coordinates of each coordinate.
+func mapXY(g geom.T, f func(x, y float64) (float64, float64)) (geom.T, error) {
+	if gc, ok := g.(*geom.GeometryCollection); ok {
+		result := geom.NewGeometryCollection()
+		for _, member := range gc.Geoms() {
+			mapped, err := mapXY(member, f)
+			if err != nil {
+				return nil, err
+			}
+			if err := result.Push(mapped); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	}
+	var flatCoords []float64
+	if g != nil {
+		flatCoords = append([]float64(nil), g.FlatCoords()...)
+		for i, stride := 0, g.Stride(); i < len(flatCoords); i += stride {
+			flatCoords[i], flatCoords[i+1] = f(flatCoords[i], flatCoords[i+1])
+		}
+	}
+	switch g := g.(type) {
+	case *geom.Point:
+		if g.Empty() {
+			return geom.NewPointEmpty(g.Layout()), nil
+		}
+		return geom.NewPointFlat(g.Layout(), flatCoords), nil
+	case *geom.LineString:
+		return geom.NewLineStringFlat(g.Layout(), flatCoords), nil
+	case *geom.LinearRing:
+		return geom.NewLinearRingFlat(g.Layout(), flatCoords), nil
+	case *geom.Polygon:
+		return geom.NewPolygonFlat(g.Layout(), flatCoords, append([]int(nil), g.Ends()...)), nil
+	case *geom.MultiPoint:
+		return geom.NewMultiPointFlat(g.Layout(), flatCoords), nil
+	case *geom.MultiLineString:
+		return geom.NewMultiLineStringFlat(g.Layout(), flatCoords, append([]int(nil), g.Ends()...)), nil
+	case *geom.MultiPolygon:
+		endss := make([][]int, len(g.Endss()))
+		for i, ends := range g.Endss() {
+			endss[i] = append([]int(nil), ends...)
+		}
+		return geom.NewMultiPolygonFlat(g.Layout(), flatCoords, endss), nil
+	default:
+		return nil, geom.ErrUnsupportedType{Value: g}
+	}
+}
+
+// normalizeLon returns lon normalized to the range [-180, 180).
+func normalizeLon(lon float64) float64 {
+	return lon - 360*math.Floor((lon+180)/360)
+}
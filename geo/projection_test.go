@@ -0,0 +1,162 @@
+package geo
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+)
+
+func TestProjectionForward(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		p            Projection
+		lon, lat     float64
+		wantX, wantY float64
+	}{
+		{
+			name:  "north_pole",
+			p:     &PolarStereographic{},
+			lon:   123,
+			lat:   90,
+			wantX: 0,
+			wantY: 0,
+		},
+		{
+			name:  "north_equator",
+			p:     &PolarStereographic{},
+			lon:   0,
+			lat:   0,
+			wantX: 0,
+			wantY: -2 * EarthRadius,
+		},
+		{
+			name:  "north_central_meridian",
+			p:     &PolarStereographic{CentralMeridian: -45},
+			lon:   45,
+			lat:   0,
+			wantX: 2 * EarthRadius,
+			wantY: 0,
+		},
+		{
+			name:  "north_true_scale",
+			p:     &PolarStereographic{TrueScaleLatitude: 30},
+			lon:   0,
+			lat:   0,
+			wantX: 0,
+			wantY: -1.5 * EarthRadius,
+		},
+		{
+			name:  "south_equator",
+			p:     &PolarStereographic{South: true, TrueScaleLatitude: -90},
+			lon:   0,
+			lat:   0,
+			wantX: 0,
+			wantY: 2 * EarthRadius,
+		},
+		{
+			name:  "azimuthal_equidistant_center",
+			p:     &AzimuthalEquidistant{Lon: 10, Lat: 50},
+			lon:   10,
+			lat:   50,
+			wantX: 0,
+			wantY: 0,
+		},
+		{
+			name:  "azimuthal_equidistant_north_pole",
+			p:     &AzimuthalEquidistant{Lat: 90},
+			lon:   0,
+			lat:   0,
+			wantX: 0,
+			wantY: -EarthRadius * math.Pi / 2,
+		},
+		{
+			name:  "azimuthal_equidistant_east",
+			p:     &AzimuthalEquidistant{},
+			lon:   90,
+			lat:   0,
+			wantX: EarthRadius * math.Pi / 2,
+			wantY: 0,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			x, y := tc.p.Forward(tc.lon, tc.lat)
+			if math.Abs(x-tc.wantX) > 1e-6 || math.Abs(y-tc.wantY) > 1e-6 {
+				t.Errorf("Forward(%v, %v) == %v, %v, want %v, %v", tc.lon, tc.lat, x, y, tc.wantX, tc.wantY)
+			}
+		})
+	}
+}
+
+func TestProjectionRoundTrip(t *testing.T) {
+	for _, p := range []Projection{
+		&PolarStereographic{CentralMeridian: -45, TrueScaleLatitude: 70},
+		&PolarStereographic{South: true, TrueScaleLatitude: -71},
+		&AzimuthalEquidistant{Lon: 15, Lat: 78},
+		&AzimuthalEquidistant{Lon: -170, Lat: -60},
+	} {
+		for lon := -180.0; lon < 180; lon += 15 {
+			for lat := -80.0; lat <= 80; lat += 10 {
+				if ps, ok := p.(*PolarStereographic); ok && ps.South == (lat > 0) {
+					continue
+				}
+				x, y := p.Forward(lon, lat)
+				gotLon, gotLat := p.Inverse(x, y)
+				if math.Abs(math.Remainder(gotLon-lon, 360)) > 1e-9 || math.Abs(gotLat-lat) > 1e-9 {
+					t.Errorf("%#v: Inverse(Forward(%v, %v)) == %v, %v", p, lon, lat, gotLon, gotLat)
+				}
+			}
+		}
+	}
+}
+
+func TestAzimuthalEquidistantDistance(t *testing.T) {
+	center := geom.Coord{25, 80}
+	p := &AzimuthalEquidistant{Lon: center.X(), Lat: center.Y()}
+	for _, c := range []geom.Coord{
+		{25, 80},
+		{-155, 80},
+		{0, 0},
+		{100, -30},
+	} {
+		x, y := p.Forward(c.X(), c.Y())
+		if got, want := math.Hypot(x, y), Distance(center, c); math.Abs(got-want) > 1e-6 {
+			t.Errorf("distance from center to %v == %v, want %v", c, got, want)
+		}
+	}
+}
+
+func TestProject(t *testing.T) {
+	p := &PolarStereographic{}
+	g := geom.NewGeometryCollection().MustPush(
+		geom.NewPolygonFlat(geom.XYZ, []float64{0, 0, 1, 90, 0, 2, 0, 90, 3, 0, 0, 1}, []int{12}).SetSRID(4326),
+		geom.NewPointEmpty(geom.XY),
+	)
+	got, err := Project(g, p)
+	if err != nil {
+		t.Fatalf("Project(...) == _, %v, want _, <nil>", err)
+	}
+	polygon := got.(*geom.GeometryCollection).Geom(0).(*geom.Polygon)
+	if polygon.SRID() != 0 || polygon.Layout() != geom.XYZ || !reflect.DeepEqual(polygon.Ends(), []int{12}) {
+		t.Errorf("Project(...) == %#v", got)
+	}
+	want := []float64{0, -2 * EarthRadius, 1, 2 * EarthRadius, 0, 2, 0, 0, 3, 0, -2 * EarthRadius, 1}
+	for i, f := range polygon.FlatCoords() {
+		if math.Abs(f-want[i]) > 1e-6 {
+			t.Errorf("Project(...) flat coordinate %d == %v, want %v", i, f, want[i])
+		}
+	}
+	unprojected, err := Unproject(got, p)
+	if err != nil {
+		t.Fatalf("Unproject(...) == _, %v, want _, <nil>", err)
+	}
+	for i, f := range unprojected.(*geom.GeometryCollection).Geom(0).FlatCoords() {
+		if wantF := g.Geom(0).FlatCoords()[i]; math.Abs(f-wantF) > 1e-9 {
+			t.Errorf("Unproject(...) flat coordinate %d == %v, want %v", i, f, wantF)
+		}
+	}
+	if !unprojected.(*geom.GeometryCollection).Geom(1).(*geom.Point).Empty() {
+		t.Errorf("Unproject(...) did not keep the empty point")
+	}
+}
@@ -1,5 +1,9 @@
 // Package geom implements efficient geometry types for geospatial
 // applications.
+//
+// Geometries implement gob.GobEncoder and gob.GobDecoder so that they survive
+// gob-based RPC. To send geometries as T interface values, register their
+// concrete types with gob.Register.
 package geom
 
 //go:generate goderive .
@@ -52,6 +56,51 @@ func (e ErrStrideMismatch) Error() string {
 	return fmt.Sprintf("geom: stride mismatch, got %d, want %d", e.Got, e.Want)
 }
 
+// An ErrInvalidHole is returned when a hole of a Polygon is not inside its
+// exterior ring or overlaps another hole.
+type ErrInvalidHole struct {
+	Index  int // index of the hole's LinearRing in the Polygon
+	Reason string
+}
+
+func (e ErrInvalidHole) Error() string {
+	return fmt.Sprintf("geom: invalid hole %d: %s", e.Index, e.Reason)
+}
+
+// An ErrInvalidRing is returned when a LinearRing of a Polygon or
+// MultiPolygon has too few coordinates or is wound the wrong way.
+type ErrInvalidRing struct {
+	Path   Path // path to the ring in the geometry
+	Reason string
+}
+
+func (e ErrInvalidRing) Error() string {
+	return fmt.Sprintf("geom: invalid ring %s: %s", e.Path, e.Reason)
+}
+
+// An ErrInvalidSegment is returned when a segment of a CompoundCurve is not a
+// valid LineString or CircularString or does not start where the previous
+// segment ends.
+type ErrInvalidSegment struct {
+	Index  int // index of the segment in its curve
+	Reason string
+}
+
+func (e ErrInvalidSegment) Error() string {
+	return fmt.Sprintf("geom: invalid segment %d: %s", e.Index, e.Reason)
+}
+
+// An ErrInvalidVertex is returned when editing a vertex would leave a line or
+// ring with too few coordinates.
+type ErrInvalidVertex struct {
+	Index  int // index of the vertex in its line or ring
+	Reason string
+}
+
+func (e ErrInvalidVertex) Error() string {
+	return fmt.Sprintf("geom: invalid vertex %d: %s", e.Index, e.Reason)
+}
+
 // An ErrUnsupportedLayout is returned when the requested layout is not
 // supported.
 type ErrUnsupportedLayout Layout
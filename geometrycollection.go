@@ -108,7 +108,8 @@ func (g *GeometryCollection) Push(gs ...T) error {
 	return nil
 }
 
-// SetSRID sets g's SRID and the SRID of all its elements.
+// SetSRID sets g's SRID. It does not change the SRIDs of g's geometries; use
+// SetSRIDRecursive to set them too.
 func (g *GeometryCollection) SetSRID(srid int) *GeometryCollection {
 	g.srid = srid
 	return g
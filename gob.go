@@ -0,0 +1,401 @@
+package geom
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+)
+
+var (
+	errGobCircular = errors.New("geom: gob: invalid circular flags")
+	errGobLayout   = errors.New("geom: gob: invalid layout")
+	errGobType     = errors.New("geom: gob: invalid type")
+)
+
+// gobGeom is the gob encoding of a geometry.
+type gobGeom struct {
+	Type       string
+	Layout     Layout
+	SRID       int
+	FlatCoords []float64
+	Ends       []int
+	Endss      [][]int
+	Geoms      []gobGeom
+	Circular   []bool   // of the segments of a CompoundCurve
+	Circulars  [][]bool // of the segments of the rings of a CurvePolygon
+}
+
+func newGobGeom(g T) (gobGeom, error) {
+	var gg gobGeom
+	switch g := g.(type) {
+	case *Point:
+		gg.Type = "Point"
+	case *LineString:
+		gg.Type = "LineString"
+	case *LinearRing:
+		gg.Type = "LinearRing"
+	case *Polygon:
+		gg.Type = "Polygon"
+	case *MultiPoint:
+		gg.Type = "MultiPoint"
+	case *MultiLineString:
+		gg.Type = "MultiLineString"
+	case *MultiPolygon:
+		gg.Type = "MultiPolygon"
+	case *GeometryCollection:
+		gg.Type = "GeometryCollection"
+		gg.SRID = g.SRID()
+		gg.Geoms = make([]gobGeom, 0, len(g.geoms))
+		for _, member := range g.geoms {
+			gm, err := newGobGeom(member)
+			if err != nil {
+				return gobGeom{}, err
+			}
+			gg.Geoms = append(gg.Geoms, gm)
+		}
+		return gg, nil
+	case *CircularString:
+		gg.Type = "CircularString"
+	case *CompoundCurve:
+		gg.Type = "CompoundCurve"
+		gg.Circular = g.circular
+	case *CurvePolygon:
+		gg.Type = "CurvePolygon"
+		gg.Circulars = g.circular
+	case *Triangle:
+		gg.Type = "Triangle"
+	case *PolyhedralSurface:
+		gg.Type = "PolyhedralSurface"
+	case *TIN:
+		gg.Type = "TIN"
+	default:
+		return gobGeom{}, ErrUnsupportedType{Value: g}
+	}
+	gg.Layout = g.Layout()
+	gg.SRID = g.SRID()
+	gg.FlatCoords = g.FlatCoords()
+	gg.Ends = g.Ends()
+	gg.Endss = g.Endss()
+	return gg, nil
+}
+
+// geom returns the geometry encoded by gg, checking that it is well formed.
+func (gg gobGeom) geom() (T, error) {
+	if gg.Layout < NoLayout {
+		return nil, errGobLayout
+	}
+	g0 := geom0{
+		layout:     gg.Layout,
+		stride:     gg.Layout.Stride(),
+		flatCoords: gg.FlatCoords,
+		srid:       gg.SRID,
+	}
+	g2 := geom2{geom1: geom1{g0}, ends: gg.Ends}
+	g3 := geom3{geom1: geom1{g0}, endss: gg.Endss}
+	var g T
+	var err error
+	switch gg.Type {
+	case "Point":
+		if g0.flatCoords == nil {
+			g0.flatCoords = []float64{}
+		}
+		if len(g0.flatCoords) != 0 {
+			err = g0.verify()
+		}
+		g = &Point{g0}
+	case "LineString":
+		err = g2.geom1.verify()
+		g = &LineString{g2.geom1}
+	case "LinearRing":
+		err = g2.geom1.verify()
+		g = &LinearRing{g2.geom1}
+	case "Polygon":
+		err = g2.verify()
+		g = &Polygon{g2}
+	case "MultiPoint":
+		err = g2.geom1.verify()
+		g = &MultiPoint{g2.geom1}
+	case "MultiLineString":
+		err = g2.verify()
+		g = &MultiLineString{g2}
+	case "MultiPolygon":
+		err = g3.verify()
+		g = &MultiPolygon{g3}
+	case "GeometryCollection":
+		gc := NewGeometryCollection().SetSRID(gg.SRID)
+		for _, gm := range gg.Geoms {
+			member, err := gm.geom()
+			if err != nil {
+				return nil, err
+			}
+			gc.geoms = append(gc.geoms, member)
+		}
+		g = gc
+	case "CircularString":
+		err = g2.geom1.verify()
+		g = &CircularString{g2.geom1}
+	case "CompoundCurve":
+		if err = g2.verify(); err == nil && len(gg.Circular) != len(gg.Ends) {
+			err = errGobCircular
+		}
+		g = &CompoundCurve{geom2: g2, circular: gg.Circular}
+	case "CurvePolygon":
+		if err = g3.verify(); err == nil && len(gg.Circulars) != len(gg.Endss) {
+			err = errGobCircular
+		}
+		for i := 0; err == nil && i < len(gg.Circulars); i++ {
+			if len(gg.Circulars[i]) != len(gg.Endss[i]) {
+				err = errGobCircular
+			}
+		}
+		g = &CurvePolygon{geom3: g3, circular: gg.Circulars}
+	case "Triangle":
+		err = g2.verify()
+		g = &Triangle{g2}
+	case "PolyhedralSurface":
+		err = g3.verify()
+		g = &PolyhedralSurface{g3}
+	case "TIN":
+		err = g3.verify()
+		g = &TIN{g3}
+	default:
+		return nil, errGobType
+	}
+	if err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func gobEncode(g T) ([]byte, error) {
+	gg, err := newGobGeom(g)
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	if err := gob.NewEncoder(&b).Encode(gg); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func gobDecode(data []byte, typ string) (T, error) {
+	var gg gobGeom
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&gg); err != nil {
+		return nil, err
+	}
+	if gg.Type != typ {
+		return nil, errGobType
+	}
+	return gg.geom()
+}
+
+// GobEncode implements gob.GobEncoder.
+func (g *Point) GobEncode() ([]byte, error) {
+	return gobEncode(g)
+}
+
+// GobDecode implements gob.GobDecoder.
+func (g *Point) GobDecode(data []byte) error {
+	t, err := gobDecode(data, "Point")
+	if err != nil {
+		return err
+	}
+	*g = *t.(*Point)
+	return nil
+}
+
+// GobEncode implements gob.GobEncoder.
+func (g *LineString) GobEncode() ([]byte, error) {
+	return gobEncode(g)
+}
+
+// GobDecode implements gob.GobDecoder.
+func (g *LineString) GobDecode(data []byte) error {
+	t, err := gobDecode(data, "LineString")
+	if err != nil {
+		return err
+	}
+	*g = *t.(*LineString)
+	return nil
+}
+
+// GobEncode implements gob.GobEncoder.
+func (g *LinearRing) GobEncode() ([]byte, error) {
+	return gobEncode(g)
+}
+
+// GobDecode implements gob.GobDecoder.
+func (g *LinearRing) GobDecode(data []byte) error {
+	t, err := gobDecode(data, "LinearRing")
+	if err != nil {
+		return err
+	}
+	*g = *t.(*LinearRing)
+	return nil
+}
+
+// GobEncode implements gob.GobEncoder.
+func (g *Polygon) GobEncode() ([]byte, error) {
+	return gobEncode(g)
+}
+
+// GobDecode implements gob.GobDecoder.
+func (g *Polygon) GobDecode(data []byte) error {
+	t, err := gobDecode(data, "Polygon")
+	if err != nil {
+		return err
+	}
+	*g = *t.(*Polygon)
+	return nil
+}
+
+// GobEncode implements gob.GobEncoder.
+func (g *MultiPoint) GobEncode() ([]byte, error) {
+	return gobEncode(g)
+}
+
+// GobDecode implements gob.GobDecoder.
+func (g *MultiPoint) GobDecode(data []byte) error {
+	t, err := gobDecode(data, "MultiPoint")
+	if err != nil {
+		return err
+	}
+	*g = *t.(*MultiPoint)
+	return nil
+}
+
+// GobEncode implements gob.GobEncoder.
+func (g *MultiLineString) GobEncode() ([]byte, error) {
+	return gobEncode(g)
+}
+
+// GobDecode implements gob.GobDecoder.
+func (g *MultiLineString) GobDecode(data []byte) error {
+	t, err := gobDecode(data, "MultiLineString")
+	if err != nil {
+		return err
+	}
+	*g = *t.(*MultiLineString)
+	return nil
+}
+
+// GobEncode implements gob.GobEncoder.
+func (g *MultiPolygon) GobEncode() ([]byte, error) {
+	return gobEncode(g)
+}
+
+// GobDecode implements gob.GobDecoder.
+func (g *MultiPolygon) GobDecode(data []byte) error {
+	t, err := gobDecode(data, "MultiPolygon")
+	if err != nil {
+		return err
+	}
+	*g = *t.(*MultiPolygon)
+	return nil
+}
+
+// GobEncode implements gob.GobEncoder.
+func (g *GeometryCollection) GobEncode() ([]byte, error) {
+	return gobEncode(g)
+}
+
+// GobDecode implements gob.GobDecoder.
+func (g *GeometryCollection) GobDecode(data []byte) error {
+	t, err := gobDecode(data, "GeometryCollection")
+	if err != nil {
+		return err
+	}
+	*g = *t.(*GeometryCollection)
+	return nil
+}
+
+// GobEncode implements gob.GobEncoder.
+func (g *CircularString) GobEncode() ([]byte, error) {
+	return gobEncode(g)
+}
+
+// GobDecode implements gob.GobDecoder.
+func (g *CircularString) GobDecode(data []byte) error {
+	t, err := gobDecode(data, "CircularString")
+	if err != nil {
+		return err
+	}
+	*g = *t.(*CircularString)
+	return nil
+}
+
+// GobEncode implements gob.GobEncoder.
+func (g *CompoundCurve) GobEncode() ([]byte, error) {
+	return gobEncode(g)
+}
+
+// GobDecode implements gob.GobDecoder.
+func (g *CompoundCurve) GobDecode(data []byte) error {
+	t, err := gobDecode(data, "CompoundCurve")
+	if err != nil {
+		return err
+	}
+	*g = *t.(*CompoundCurve)
+	return nil
+}
+
+// GobEncode implements gob.GobEncoder.
+func (g *CurvePolygon) GobEncode() ([]byte, error) {
+	return gobEncode(g)
+}
+
+// GobDecode implements gob.GobDecoder.
+func (g *CurvePolygon) GobDecode(data []byte) error {
+	t, err := gobDecode(data, "CurvePolygon")
+	if err != nil {
+		return err
+	}
+	*g = *t.(*CurvePolygon)
+	return nil
+}
+
+// GobEncode implements gob.GobEncoder.
+func (g *Triangle) GobEncode() ([]byte, error) {
+	return gobEncode(g)
+}
+
+// GobDecode implements gob.GobDecoder.
+func (g *Triangle) GobDecode(data []byte) error {
+	t, err := gobDecode(data, "Triangle")
+	if err != nil {
+		return err
+	}
+	*g = *t.(*Triangle)
+	return nil
+}
+
+// GobEncode implements gob.GobEncoder.
+func (g *PolyhedralSurface) GobEncode() ([]byte, error) {
+	return gobEncode(g)
+}
+
+// GobDecode implements gob.GobDecoder.
+func (g *PolyhedralSurface) GobDecode(data []byte) error {
+	t, err := gobDecode(data, "PolyhedralSurface")
+	if err != nil {
+		return err
+	}
+	*g = *t.(*PolyhedralSurface)
+	return nil
+}
+
+// GobEncode implements gob.GobEncoder.
+func (g *TIN) GobEncode() ([]byte, error) {
+	return gobEncode(g)
+}
+
+// GobDecode implements gob.GobDecoder.
+func (g *TIN) GobDecode(data []byte) error {
+	t, err := gobDecode(data, "TIN")
+	if err != nil {
+		return err
+	}
+	*g = *t.(*TIN)
+	return nil
+}
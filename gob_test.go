@@ -0,0 +1,109 @@
+package geom
+
+import (
+	"bytes"
+	"encoding/gob"
+	"reflect"
+	"testing"
+)
+
+func TestGob(t *testing.T) {
+	for _, g := range []T{
+		NewPointFlat(XY, []float64{1, 2}).SetSRID(4326),
+		NewPointEmpty(XYZ),
+		NewLineStringFlat(XYM, []float64{1, 2, 3, 4, 5, 6}),
+		NewLinearRingFlat(XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}),
+		NewPolygonFlat(XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}, []int{8}).SetSRID(3857),
+		NewMultiPointFlat(XYZM, []float64{1, 2, 3, 4, 5, 6, 7, 8}),
+		NewMultiLineStringFlat(XY, []float64{1, 2, 3, 4, 5, 6, 7, 8}, []int{4, 8}),
+		NewMultiPolygonFlat(XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}, [][]int{{8}}),
+		NewGeometryCollection().MustPush(
+			NewPointFlat(XY, []float64{1, 2}),
+			NewGeometryCollection().MustPush(NewLineStringFlat(XYZ, []float64{1, 2, 3, 4, 5, 6})),
+		).SetSRID(4326),
+		NewCircularStringFlat(XYZ, []float64{0, 0, 1, 1, 1, 2, 2, 0, 3}).SetSRID(4326),
+		NewCompoundCurve(XY).MustPush(
+			NewCircularStringFlat(XY, []float64{0, 0, 1, 1, 2, 0}),
+			NewLineStringFlat(XY, []float64{2, 0, 3, 0}),
+		),
+		NewCurvePolygon(XY).MustPush(
+			NewCompoundCurve(XY).MustPush(
+				NewCircularStringFlat(XY, []float64{0, 0, 2, 2, 4, 0}),
+				NewLineStringFlat(XY, []float64{4, 0, 0, 0}),
+			),
+			NewLinearRingFlat(XY, []float64{1, 0.5, 2, 0.5, 2, 1, 1, 0.5}),
+		).SetSRID(3857),
+		NewTriangleFlat(XYZ, []float64{0, 0, 0, 1, 0, 1, 0, 1, 2, 0, 0, 0}, []int{12}).SetSRID(4326),
+		NewPolyhedralSurfaceFlat(XY, []float64{0, 0, 1, 0, 1, 1, 0, 0, 1, 0, 2, 0, 2, 1, 1, 0}, [][]int{{8}, {16}}),
+		NewTINFlat(XYM, []float64{0, 0, 1, 1, 0, 2, 0, 1, 3, 0, 0, 1}, [][]int{{12}}),
+	} {
+		t.Run(reflect.TypeOf(g).Elem().Name(), func(t *testing.T) {
+			var b bytes.Buffer
+			if err := gob.NewEncoder(&b).Encode(g); err != nil {
+				t.Fatal(err)
+			}
+			got := reflect.New(reflect.TypeOf(g).Elem())
+			if err := gob.NewDecoder(&b).Decode(got.Interface()); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got.Interface(), g) {
+				t.Errorf("got %#v, want %#v", got.Interface(), g)
+			}
+		})
+	}
+}
+
+func TestGobInterface(t *testing.T) {
+	gob.Register(&Point{})
+	gob.Register(&LineString{})
+	type message struct {
+		Name  string
+		Geoms []T
+	}
+	want := message{
+		Name: "track",
+		Geoms: []T{
+			NewPointFlat(XY, []float64{1, 2}),
+			NewLineStringFlat(XY, []float64{1, 2, 3, 4}).SetSRID(4326),
+		},
+	}
+	var b bytes.Buffer
+	if err := gob.NewEncoder(&b).Encode(want); err != nil {
+		t.Fatal(err)
+	}
+	var got message
+	if err := gob.NewDecoder(&b).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v, want %#v", got, want)
+	}
+}
+
+func TestGobDecodeErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		gg   gobGeom
+		g    gob.GobDecoder
+		want error
+	}{
+		{name: "type", gg: gobGeom{Type: "LineString", Layout: XY}, g: &Polygon{}, want: errGobType},
+		{name: "layout", gg: gobGeom{Type: "Polygon", Layout: -1}, g: &Polygon{}, want: errGobLayout},
+		{name: "stride", gg: gobGeom{Type: "Polygon", Layout: XY, FlatCoords: []float64{1, 2, 3}}, g: &Polygon{}, want: errLengthStrideMismatch},
+		{name: "ends", gg: gobGeom{Type: "Polygon", Layout: XY, FlatCoords: []float64{1, 2}, Ends: []int{4}}, g: &Polygon{}, want: errIncorrectEnd},
+		{name: "member", gg: gobGeom{Type: "GeometryCollection", Geoms: []gobGeom{{Type: "Curve"}}}, g: &GeometryCollection{}, want: errGobType},
+		{name: "compound_curve_circular", gg: gobGeom{Type: "CompoundCurve", Layout: XY, FlatCoords: []float64{0, 0, 1, 1}, Ends: []int{4}}, g: &CompoundCurve{}, want: errGobCircular},
+		{name: "tin_endss", gg: gobGeom{Type: "TIN", Layout: XY, FlatCoords: []float64{0, 0}, Endss: [][]int{{4}}}, g: &TIN{}, want: errIncorrectEnd},
+		{name: "curve_polygon_circulars", gg: gobGeom{Type: "CurvePolygon", Layout: XY, FlatCoords: []float64{0, 0, 1, 1, 0, 0}, Endss: [][]int{{6}}, Circulars: [][]bool{{false, true}}}, g: &CurvePolygon{}, want: errGobCircular},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := gob.NewEncoder(&b).Encode(tc.gg); err != nil {
+				t.Fatal(err)
+			}
+			if err := tc.g.GobDecode(b.Bytes()); err != tc.want {
+				t.Errorf("GobDecode(...) == %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,312 @@
+// Package geohash implements geohashes, a hierarchical grid of cells on the
+// longitude/latitude plane identified by base 32 strings. See
+// https://en.wikipedia.org/wiki/Geohash.
+//
+// Each character of a geohash encodes five bits, which alternately halve the
+// longitude and latitude ranges of the cell, starting with longitude. Cells
+// whose geohashes share a prefix lie within the cell of that prefix, so
+// geohashes of nearby points often, but not always, share long prefixes.
+// Geometries and bounds use X for longitude and Y for latitude.
+package geohash
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/twpayne/go-geom"
+)
+
+// MaxPrecision is the maximum number of characters of a geohash, giving
+// cells smaller than 4cm square.
+const MaxPrecision = 12
+
+const alphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// decodeTable maps characters to their values, or -1 for invalid characters.
+var decodeTable [256]int8
+
+func init() {
+	for i := range decodeTable {
+		decodeTable[i] = -1
+	}
+	for i := 0; i < len(alphabet); i++ {
+		decodeTable[alphabet[i]] = int8(i)
+	}
+}
+
+// ErrInvalidCoordinate is returned when encoding a latitude outside
+// [-90, 90] or a longitude outside [-180, 180].
+var ErrInvalidCoordinate = errors.New("geohash: invalid coordinate")
+
+// An ErrInvalidPrecision is returned when a precision is not between 1 and
+// MaxPrecision.
+type ErrInvalidPrecision int
+
+func (e ErrInvalidPrecision) Error() string {
+	return fmt.Sprintf("geohash: invalid precision: %d", int(e))
+}
+
+// An ErrInvalidHash is returned when decoding a string that is not a valid
+// geohash.
+type ErrInvalidHash string
+
+func (e ErrInvalidHash) Error() string {
+	return fmt.Sprintf("geohash: invalid hash: %q", string(e))
+}
+
+// A Direction is the direction of a neighboring cell.
+type Direction int
+
+// Directions.
+const (
+	North Direction = iota
+	NorthEast
+	East
+	SouthEast
+	South
+	SouthWest
+	West
+	NorthWest
+)
+
+// offsets are the longitude and latitude offsets of each Direction.
+var offsets = [...][2]int64{
+	North:     {0, 1},
+	NorthEast: {1, 1},
+	East:      {1, 0},
+	SouthEast: {1, -1},
+	South:     {0, -1},
+	SouthWest: {-1, -1},
+	West:      {-1, 0},
+	NorthWest: {-1, 1},
+}
+
+// A grid is the grid of cells at a precision.
+type grid struct {
+	precision        int
+	lonBits, latBits uint
+	width, height    float64
+}
+
+func newGrid(precision int) (grid, error) {
+	if precision < 1 || precision > MaxPrecision {
+		return grid{}, ErrInvalidPrecision(precision)
+	}
+	bits := uint(5 * precision)
+	lonBits, latBits := (bits+1)/2, bits/2
+	return grid{
+		precision: precision,
+		lonBits:   lonBits,
+		latBits:   latBits,
+		width:     360 / float64(uint64(1)<<lonBits),
+		height:    180 / float64(uint64(1)<<latBits),
+	}, nil
+}
+
+// index returns the indexes of the cell containing lon and lat.
+func (g grid) index(lon, lat float64) (int64, int64) {
+	return g.lonIndex(lon), g.latIndex(lat)
+}
+
+func (g grid) lonIndex(lon float64) int64 {
+	return clamp(int64(math.Floor((lon+180)/g.width)), int64(1)<<g.lonBits-1)
+}
+
+func (g grid) latIndex(lat float64) int64 {
+	return clamp(int64(math.Floor((lat+90)/g.height)), int64(1)<<g.latBits-1)
+}
+
+// hash returns the geohash of the cell with indexes i and j.
+func (g grid) hash(i, j int64) string {
+	bits := uint(5 * g.precision)
+	var interleaved uint64
+	lonBit, latBit := g.lonBits, g.latBits
+	for k := uint(0); k < bits; k++ {
+		interleaved <<= 1
+		if k%2 == 0 {
+			lonBit--
+			interleaved |= uint64(i>>lonBit) & 1
+		} else {
+			latBit--
+			interleaved |= uint64(j>>latBit) & 1
+		}
+	}
+	b := make([]byte, g.precision)
+	for k := g.precision - 1; k >= 0; k-- {
+		b[k] = alphabet[interleaved&0x1f]
+		interleaved >>= 5
+	}
+	return string(b)
+}
+
+// bounds returns the bounds of the cell with indexes i and j.
+func (g grid) bounds(i, j int64) *geom.Bounds {
+	minLon := -180 + float64(i)*g.width
+	minLat := -90 + float64(j)*g.height
+	return geom.NewBounds(geom.XY).Set(minLon, minLat, minLon+g.width, minLat+g.height)
+}
+
+// parse returns the grid and cell indexes of hash.
+func parse(hash string) (grid, int64, int64, error) {
+	g, err := newGrid(len(hash))
+	if err != nil {
+		return grid{}, 0, 0, ErrInvalidHash(hash)
+	}
+	var i, j int64
+	k := 0
+	for n := 0; n < len(hash); n++ {
+		v := decodeTable[hash[n]]
+		if v < 0 {
+			return grid{}, 0, 0, ErrInvalidHash(hash)
+		}
+		for bit := 4; bit >= 0; bit-- {
+			b := int64(v>>uint(bit)) & 1
+			if k%2 == 0 {
+				i = i<<1 | b
+			} else {
+				j = j<<1 | b
+			}
+			k++
+		}
+	}
+	return g, i, j, nil
+}
+
+// Encode returns the geohash with precision characters of the cell
+// containing lat and lon. Points on the boundary between cells belong to the
+// cell to their north and east, except at the maximum latitude and longitude.
+func Encode(lat, lon float64, precision int) (string, error) {
+	g, err := newGrid(precision)
+	if err != nil {
+		return "", err
+	}
+	if !(-90 <= lat && lat <= 90 && -180 <= lon && lon <= 180) {
+		return "", ErrInvalidCoordinate
+	}
+	i, j := g.index(lon, lat)
+	return g.hash(i, j), nil
+}
+
+// EncodePoint returns the geohash with precision characters of the cell
+// containing p.
+func EncodePoint(p *geom.Point, precision int) (string, error) {
+	if p.Empty() {
+		return "", ErrInvalidCoordinate
+	}
+	return Encode(p.Y(), p.X(), precision)
+}
+
+// Decode returns the center of the cell of hash.
+func Decode(hash string) (*geom.Point, error) {
+	b, err := DecodeBounds(hash)
+	if err != nil {
+		return nil, err
+	}
+	return geom.NewPointFlat(geom.XY, []float64{
+		(b.Min(0) + b.Max(0)) / 2,
+		(b.Min(1) + b.Max(1)) / 2,
+	}), nil
+}
+
+// DecodeBounds returns the bounds of the cell of hash.
+func DecodeBounds(hash string) (*geom.Bounds, error) {
+	g, i, j, err := parse(hash)
+	if err != nil {
+		return nil, err
+	}
+	return g.bounds(i, j), nil
+}
+
+// Neighbor returns the geohash of the cell of the same precision adjacent to
+// hash in direction. Neighbors wrap around the antimeridian. Cells at the
+// poles have no neighbors to the north or south, for which Neighbor returns
+// an empty string.
+func Neighbor(hash string, direction Direction) (string, error) {
+	g, i, j, err := parse(hash)
+	if err != nil {
+		return "", err
+	}
+	return g.neighbor(i, j, direction), nil
+}
+
+// Neighbors returns the geohashes of the cells adjacent to hash, in the order
+// of the Directions from North clockwise to NorthWest. Neighbors that do not
+// exist, beyond the poles, are omitted.
+func Neighbors(hash string) ([]string, error) {
+	g, i, j, err := parse(hash)
+	if err != nil {
+		return nil, err
+	}
+	neighbors := make([]string, 0, len(offsets))
+	for direction := range offsets {
+		if neighbor := g.neighbor(i, j, Direction(direction)); neighbor != "" {
+			neighbors = append(neighbors, neighbor)
+		}
+	}
+	return neighbors, nil
+}
+
+func (g grid) neighbor(i, j int64, direction Direction) string {
+	offset := offsets[direction]
+	j += offset[1]
+	if j < 0 || j >= int64(1)<<g.latBits {
+		return ""
+	}
+	i = (i + offset[0]) & (int64(1)<<g.lonBits - 1)
+	return g.hash(i, j)
+}
+
+// Cover returns the geohashes with precision characters of the cells that
+// intersect b, whose X and Y are longitude and latitude, ordered from south
+// to north and west to east. b is clamped to the valid range of longitudes
+// and latitudes. If b is empty then Cover returns no geohashes.
+func Cover(b *geom.Bounds, precision int) ([]string, error) {
+	g, err := newGrid(precision)
+	if err != nil {
+		return nil, err
+	}
+	if b.IsEmpty() {
+		return nil, nil
+	}
+	minI, minJ := g.index(b.Min(0), b.Min(1))
+	maxI, maxJ := g.index(b.Max(0), b.Max(1))
+	hashes := make([]string, 0, (maxI-minI+1)*(maxJ-minJ+1))
+	for j := minJ; j <= maxJ; j++ {
+		for i := minI; i <= maxI; i++ {
+			hashes = append(hashes, g.hash(i, j))
+		}
+	}
+	return hashes, nil
+}
+
+// CoverMax returns the geohashes of the cells that intersect b at the
+// highest precision at which there are no more than maxCells of them. The
+// covering always contains at least the cells at precision 1, of which there
+// are at most 32.
+func CoverMax(b *geom.Bounds, maxCells int) ([]string, error) {
+	if b.IsEmpty() {
+		return nil, nil
+	}
+	precision := 1
+	for ; precision < MaxPrecision; precision++ {
+		g, _ := newGrid(precision + 1)
+		minI, minJ := g.index(b.Min(0), b.Min(1))
+		maxI, maxJ := g.index(b.Max(0), b.Max(1))
+		if (maxI-minI+1)*(maxJ-minJ+1) > int64(maxCells) {
+			break
+		}
+	}
+	return Cover(b, precision)
+}
+
+func clamp(x, max int64) int64 {
+	switch {
+	case x < 0:
+		return 0
+	case x > max:
+		return max
+	default:
+		return x
+	}
+}
@@ -0,0 +1,135 @@
+package geohash
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+)
+
+func TestEncode(t *testing.T) {
+	for _, tc := range []struct {
+		lat, lon  float64
+		precision int
+		want      string
+	}{
+		{lat: 57.64911, lon: 10.40744, precision: 11, want: "u4pruydqqvj"},
+		{lat: 42.6, lon: -5.6, precision: 5, want: "ezs42"},
+		{lat: -90, lon: -180, precision: 3, want: "000"},
+		{lat: 90, lon: 180, precision: 3, want: "zzz"},
+		{lat: 0, lon: 0, precision: 1, want: "s"},
+	} {
+		got, err := Encode(tc.lat, tc.lon, tc.precision)
+		if err != nil || got != tc.want {
+			t.Errorf("Encode(%v, %v, %d) == %q, %v, want %q, <nil>", tc.lat, tc.lon, tc.precision, got, err, tc.want)
+		}
+	}
+}
+
+func TestEncodePoint(t *testing.T) {
+	got, err := EncodePoint(geom.NewPointFlat(geom.XY, []float64{-5.6, 42.6}), 5)
+	if err != nil || got != "ezs42" {
+		t.Errorf("EncodePoint(...) == %q, %v, want \"ezs42\", <nil>", got, err)
+	}
+	if _, err := EncodePoint(geom.NewPointEmpty(geom.XY), 5); err != ErrInvalidCoordinate {
+		t.Errorf("EncodePoint(empty, 5) == _, %v, want _, %v", err, ErrInvalidCoordinate)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	b, err := DecodeBounds("ezs42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []float64{-5.625, 42.5830078125, -5.5810546875, 42.626953125}
+	if got := []float64{b.Min(0), b.Min(1), b.Max(0), b.Max(1)}; !reflect.DeepEqual(got, want) {
+		t.Errorf("DecodeBounds(\"ezs42\") == %v, _, want %v, _", got, want)
+	}
+	p, err := Decode("u4pruydqqvj")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(p.X()-10.40744) > 1e-6 || math.Abs(p.Y()-57.64911) > 1e-6 {
+		t.Errorf("Decode(\"u4pruydqqvj\") == %v, _, want (10.40744, 57.64911)", p.FlatCoords())
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for precision := 1; precision <= MaxPrecision; precision++ {
+		hash, err := Encode(-33.8688, 151.2093, precision)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p, err := Decode(hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := EncodePoint(p, precision); err != nil || got != hash {
+			t.Errorf("EncodePoint(Decode(%q), %d) == %q, %v, want %q, <nil>", hash, precision, got, err, hash)
+		}
+	}
+}
+
+func TestNeighbors(t *testing.T) {
+	for _, tc := range []struct {
+		hash string
+		want []string
+	}{
+		{
+			hash: "ezs42",
+			want: []string{"ezs48", "ezs49", "ezs43", "ezs41", "ezs40", "ezefp", "ezefr", "ezefx"},
+		},
+		{
+			hash: "zzz",
+			want: []string{"bpb", "bp8", "zzx", "zzw", "zzy"},
+		},
+	} {
+		got, err := Neighbors(tc.hash)
+		if err != nil || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Neighbors(%q) == %v, %v, want %v, <nil>", tc.hash, got, err, tc.want)
+		}
+	}
+	if got, err := Neighbor("zzz", North); err != nil || got != "" {
+		t.Errorf("Neighbor(\"zzz\", North) == %q, %v, want \"\", <nil>", got, err)
+	}
+}
+
+func TestCover(t *testing.T) {
+	b := geom.NewBounds(geom.XY).Set(-5.62, 42.59, -5.57, 42.60)
+	got, err := Cover(b, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"ezs42", "ezs43"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Cover(b, 5) == %v, _, want %v, _", got, want)
+	}
+	got, err = CoverMax(b, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"ezs42", "ezs43"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("CoverMax(b, 4) == %v, _, want %v, _", got, want)
+	}
+	got, err = CoverMax(geom.NewBounds(geom.XY).Set(-180, -90, 180, 90), 1)
+	if err != nil || len(got) != 32 {
+		t.Errorf("CoverMax(world, 1) == %v, %v, want 32 cells, <nil>", got, err)
+	}
+	if got, err := Cover(geom.NewBounds(geom.XY), 5); err != nil || got != nil {
+		t.Errorf("Cover(empty, 5) == %v, %v, want <nil>, <nil>", got, err)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	if _, err := Encode(0, 0, 13); err != ErrInvalidPrecision(13) {
+		t.Errorf("Encode(0, 0, 13) == _, %v, want _, %v", err, ErrInvalidPrecision(13))
+	}
+	if _, err := Encode(91, 0, 5); err != ErrInvalidCoordinate {
+		t.Errorf("Encode(91, 0, 5) == _, %v, want _, %v", err, ErrInvalidCoordinate)
+	}
+	for _, hash := range []string{"", "ezs4a", "0123456789bcd"} {
+		if _, err := DecodeBounds(hash); err != ErrInvalidHash(hash) {
+			t.Errorf("DecodeBounds(%q) == _, %v, want _, %v", hash, err, ErrInvalidHash(hash))
+		}
+	}
+}
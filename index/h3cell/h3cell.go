@@ -0,0 +1,176 @@
+// Package h3cell converts geometries to and from sets of cells of Uber's H3
+// hexagonal grid. See https://h3geo.org.
+//
+// The package does not depend on an H3 implementation. Instead, functions
+// take a Grid, which callers implement with a thin adapter, for example
+// around github.com/uber/h3-go/v4:
+//
+//	type grid struct{}
+//
+//	func (grid) PolygonToCells(p h3cell.Polygon, resolution int) ([]h3cell.Cell, error) {
+//		// Convert p to an h3.GeoPolygon and call h3.PolygonToCells.
+//	}
+//
+//	func (grid) CellsToMultiPolygon(cells []h3cell.Cell) ([]h3cell.Polygon, error) {
+//		// Convert cells to h3.Cells and call h3.CellsToMultiPolygon.
+//	}
+//
+// Geometries use X for longitude and Y for latitude, in degrees.
+package h3cell
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/twpayne/go-geom"
+)
+
+// MaxResolution is the finest H3 resolution.
+const MaxResolution = 15
+
+// A Cell is an H3 cell index.
+type Cell uint64
+
+// A LatLng is a latitude and longitude in degrees.
+type LatLng struct {
+	Lat float64
+	Lng float64
+}
+
+// A Loop is a ring of vertices. It is implicitly closed: the last vertex is
+// not a repeat of the first.
+type Loop []LatLng
+
+// A Polygon is an exterior ring with zero or more holes.
+type Polygon struct {
+	Exterior Loop
+	Holes    []Loop
+}
+
+// A Grid is an implementation of H3.
+type Grid interface {
+	// PolygonToCells returns the cells at resolution whose centers are within
+	// p.
+	PolygonToCells(p Polygon, resolution int) ([]Cell, error)
+	// CellsToMultiPolygon returns the outlines of the contiguous regions
+	// covered by cells.
+	CellsToMultiPolygon(cells []Cell) ([]Polygon, error)
+}
+
+// An ErrInvalidResolution is returned when a resolution is not between 0 and
+// MaxResolution.
+type ErrInvalidResolution int
+
+func (e ErrInvalidResolution) Error() string {
+	return fmt.Sprintf("h3cell: invalid resolution: %d", int(e))
+}
+
+// Polyfill returns the cells at resolution whose centers are within g, which
+// must be a Polygon or a MultiPolygon, sorted and without duplicates. Empty
+// polygons are ignored.
+func Polyfill(grid Grid, g geom.T, resolution int) ([]Cell, error) {
+	if resolution < 0 || resolution > MaxResolution {
+		return nil, ErrInvalidResolution(resolution)
+	}
+	var polygons []*geom.Polygon
+	switch g := g.(type) {
+	case *geom.Polygon:
+		polygons = []*geom.Polygon{g}
+	case *geom.MultiPolygon:
+		polygons = make([]*geom.Polygon, 0, g.NumPolygons())
+		for i := 0; i < g.NumPolygons(); i++ {
+			polygons = append(polygons, g.Polygon(i))
+		}
+	default:
+		return nil, geom.ErrUnsupportedType{Value: g}
+	}
+	var cells []Cell
+	for _, p := range polygons {
+		if p.Empty() {
+			continue
+		}
+		polygonCells, err := grid.PolygonToCells(polygon(p), resolution)
+		if err != nil {
+			return nil, err
+		}
+		cells = append(cells, polygonCells...)
+	}
+	return unique(cells), nil
+}
+
+// MultiPolygon returns the outlines of the contiguous regions covered by
+// cells, with closed rings, in the orientation returned by grid.
+func MultiPolygon(grid Grid, cells []Cell) (*geom.MultiPolygon, error) {
+	polygons, err := grid.CellsToMultiPolygon(cells)
+	if err != nil {
+		return nil, err
+	}
+	var flatCoords []float64
+	endss := make([][]int, 0, len(polygons))
+	for _, p := range polygons {
+		ends := make([]int, 0, 1+len(p.Holes))
+		flatCoords = appendLoop(flatCoords, p.Exterior)
+		ends = append(ends, len(flatCoords))
+		for _, hole := range p.Holes {
+			flatCoords = appendLoop(flatCoords, hole)
+			ends = append(ends, len(flatCoords))
+		}
+		endss = append(endss, ends)
+	}
+	return geom.NewMultiPolygonFlat(geom.XY, flatCoords, endss), nil
+}
+
+// polygon returns the Polygon of p.
+func polygon(p *geom.Polygon) Polygon {
+	result := Polygon{
+		Exterior: loop(p.LinearRing(0)),
+	}
+	if n := p.NumLinearRings(); n > 1 {
+		result.Holes = make([]Loop, 0, n-1)
+		for i := 1; i < n; i++ {
+			result.Holes = append(result.Holes, loop(p.LinearRing(i)))
+		}
+	}
+	return result
+}
+
+// loop returns the Loop of ring, without its closing vertex.
+func loop(ring *geom.LinearRing) Loop {
+	flatCoords, stride := ring.FlatCoords(), ring.Stride()
+	n := len(flatCoords) / stride
+	if n > 1 && flatCoords[0] == flatCoords[(n-1)*stride] && flatCoords[1] == flatCoords[(n-1)*stride+1] {
+		n--
+	}
+	l := make(Loop, 0, n)
+	for i := 0; i < n*stride; i += stride {
+		l = append(l, LatLng{Lat: flatCoords[i+1], Lng: flatCoords[i]})
+	}
+	return l
+}
+
+// appendLoop appends the closed ring of l to flatCoords.
+func appendLoop(flatCoords []float64, l Loop) []float64 {
+	for _, ll := range l {
+		flatCoords = append(flatCoords, ll.Lng, ll.Lat)
+	}
+	if len(l) > 0 && l[0] != l[len(l)-1] {
+		flatCoords = append(flatCoords, l[0].Lng, l[0].Lat)
+	}
+	return flatCoords
+}
+
+// unique sorts cells and removes duplicates.
+func unique(cells []Cell) []Cell {
+	sort.Slice(cells, func(i, j int) bool {
+		return cells[i] < cells[j]
+	})
+	n := 0
+	for i, cell := range cells {
+		if i > 0 && cell == cells[n-1] {
+			continue
+		}
+		cells[n] = cell
+		n++
+	}
+	return cells[:n]
+}
@@ -0,0 +1,106 @@
+package h3cell
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+)
+
+// A testGrid records the polygons passed to it and returns fixed results.
+type testGrid struct {
+	polygons []Polygon
+	cells    [][]Cell
+	outlines []Polygon
+	err      error
+}
+
+func (g *testGrid) PolygonToCells(p Polygon, resolution int) ([]Cell, error) {
+	g.polygons = append(g.polygons, p)
+	if g.err != nil {
+		return nil, g.err
+	}
+	cells := g.cells[0]
+	g.cells = g.cells[1:]
+	return cells, nil
+}
+
+func (g *testGrid) CellsToMultiPolygon(cells []Cell) ([]Polygon, error) {
+	return g.outlines, g.err
+}
+
+func TestPolyfill(t *testing.T) {
+	grid := &testGrid{
+		cells: [][]Cell{{3, 1}, {2, 3}},
+	}
+	mp := geom.NewMultiPolygonFlat(geom.XYZ, []float64{
+		0, 0, 9, 2, 0, 9, 2, 2, 9, 0, 0, 9,
+		0.5, 0.5, 9, 1, 0.5, 9, 1, 1, 9, 0.5, 0.5, 9,
+		10, 20, 9, 11, 20, 9, 11, 21, 9, 10, 20, 9,
+	}, [][]int{{12, 24}, {36}, {36}})
+	got, err := Polyfill(grid, mp, 9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []Cell{1, 2, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Polyfill(...) == %v, _, want %v, _", got, want)
+	}
+	wantPolygons := []Polygon{
+		{
+			Exterior: Loop{{Lat: 0, Lng: 0}, {Lat: 0, Lng: 2}, {Lat: 2, Lng: 2}},
+			Holes: []Loop{
+				{{Lat: 0.5, Lng: 0.5}, {Lat: 0.5, Lng: 1}, {Lat: 1, Lng: 1}},
+			},
+		},
+		{
+			Exterior: Loop{{Lat: 20, Lng: 10}, {Lat: 20, Lng: 11}, {Lat: 21, Lng: 11}},
+		},
+	}
+	if !reflect.DeepEqual(grid.polygons, wantPolygons) {
+		t.Errorf("grid.PolygonToCells called with %v, want %v", grid.polygons, wantPolygons)
+	}
+}
+
+func TestPolyfillErrors(t *testing.T) {
+	p := geom.NewPolygonFlat(geom.XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}, []int{8})
+	if _, err := Polyfill(&testGrid{}, p, 16); err != ErrInvalidResolution(16) {
+		t.Errorf("Polyfill(..., 16) == _, %v, want _, %v", err, ErrInvalidResolution(16))
+	}
+	point := geom.NewPointFlat(geom.XY, []float64{1, 2})
+	if _, err := Polyfill(&testGrid{}, point, 9); !reflect.DeepEqual(err, geom.ErrUnsupportedType{Value: point}) {
+		t.Errorf("Polyfill(point, 9) == _, %v, want _, %v", err, geom.ErrUnsupportedType{Value: point})
+	}
+	errGrid := errors.New("grid error")
+	if _, err := Polyfill(&testGrid{err: errGrid}, p, 9); err != errGrid {
+		t.Errorf("Polyfill(...) == _, %v, want _, %v", err, errGrid)
+	}
+}
+
+func TestMultiPolygon(t *testing.T) {
+	grid := &testGrid{
+		outlines: []Polygon{
+			{
+				Exterior: Loop{{Lat: 0, Lng: 0}, {Lat: 0, Lng: 2}, {Lat: 2, Lng: 2}},
+				Holes: []Loop{
+					{{Lat: 0.5, Lng: 0.5}, {Lat: 0.5, Lng: 1}, {Lat: 1, Lng: 1}, {Lat: 0.5, Lng: 0.5}},
+				},
+			},
+			{
+				Exterior: Loop{{Lat: 20, Lng: 10}, {Lat: 20, Lng: 11}, {Lat: 21, Lng: 11}},
+			},
+		},
+	}
+	got, err := MultiPolygon(grid, []Cell{1, 2, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := geom.NewMultiPolygonFlat(geom.XY, []float64{
+		0, 0, 2, 0, 2, 2, 0, 0,
+		0.5, 0.5, 1, 0.5, 1, 1, 0.5, 0.5,
+		10, 20, 11, 20, 11, 21, 10, 20,
+	}, [][]int{{8, 16}, {24}})
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MultiPolygon(...) == %v, _, want %v, _", got.FlatCoords(), want.FlatCoords())
+	}
+}
@@ -0,0 +1,262 @@
+// Package s2cell converts geometries to and from regions and cells of the S2
+// geometry library, github.com/golang/geo/s2, for building S2-based spatial
+// indexes.
+//
+// Geometries use X for longitude and Y for latitude, in degrees. Edges of
+// geometries are converted to geodesics on the sphere, and edges of cells are
+// converted to straight lines between their vertices, so both conversions are
+// approximate for long edges.
+package s2cell
+
+import (
+	"github.com/golang/geo/s2"
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/internal/planar"
+)
+
+// DefaultMaxCells is the default maximum number of cells in a covering.
+const DefaultMaxCells = 8
+
+// MaxLevel is the level of the smallest S2 cells, about 1cm across.
+const MaxLevel = 30
+
+// An Option configures coverings.
+type Option func(*options)
+
+type options struct {
+	coverer s2.RegionCoverer
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		coverer: s2.RegionCoverer{
+			MinLevel: 0,
+			MaxLevel: MaxLevel,
+			LevelMod: 1,
+			MaxCells: DefaultMaxCells,
+		},
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithMinLevel sets the minimum level of cells in coverings. Larger cells are
+// replaced by their descendants at this level. The default is zero.
+func WithMinLevel(minLevel int) Option {
+	return func(o *options) {
+		o.coverer.MinLevel = minLevel
+	}
+}
+
+// WithMaxLevel sets the maximum level of cells in coverings. The default is
+// MaxLevel.
+func WithMaxLevel(maxLevel int) Option {
+	return func(o *options) {
+		o.coverer.MaxLevel = maxLevel
+	}
+}
+
+// WithLevelMod restricts the levels of cells in coverings to MinLevel plus
+// multiples of levelMod, which must be between 1 and 3. The default is 1.
+func WithLevelMod(levelMod int) Option {
+	return func(o *options) {
+		o.coverer.LevelMod = levelMod
+	}
+}
+
+// WithMaxCells sets the maximum number of cells in coverings. It is a target
+// rather than a limit: coverings may contain more cells if required by the
+// minimum level. The default is DefaultMaxCells.
+func WithMaxCells(maxCells int) Option {
+	return func(o *options) {
+		o.coverer.MaxCells = maxCells
+	}
+}
+
+// Region returns the S2 region of g. Points are converted to s2.Points,
+// LineStrings to *s2.Polylines, and Polygons and MultiPolygons to
+// *s2.Polygons. MultiPoints, MultiLineStrings, and GeometryCollections are
+// converted to s2.RegionUnions of their parts. Empty geometries, and rings
+// with fewer than three distinct vertices, are omitted. Polygons must be
+// valid: rings that cross give undefined results.
+func Region(g geom.T) (s2.Region, error) {
+	switch g := g.(type) {
+	case *geom.Point:
+		if g.Empty() {
+			return s2.RegionUnion{}, nil
+		}
+		return point(g.FlatCoords()), nil
+	case *geom.LineString:
+		if g.Empty() {
+			return s2.RegionUnion{}, nil
+		}
+		return polyline(g.FlatCoords(), g.Stride()), nil
+	case *geom.Polygon:
+		return polygon([]*geom.Polygon{g}), nil
+	case *geom.MultiPoint:
+		union := make(s2.RegionUnion, 0, g.NumPoints())
+		for i := 0; i < g.NumPoints(); i++ {
+			if p := g.Point(i); !p.Empty() {
+				union = append(union, point(p.FlatCoords()))
+			}
+		}
+		return union, nil
+	case *geom.MultiLineString:
+		union := make(s2.RegionUnion, 0, g.NumLineStrings())
+		for i := 0; i < g.NumLineStrings(); i++ {
+			if ls := g.LineString(i); !ls.Empty() {
+				union = append(union, polyline(ls.FlatCoords(), ls.Stride()))
+			}
+		}
+		return union, nil
+	case *geom.MultiPolygon:
+		polygons := make([]*geom.Polygon, 0, g.NumPolygons())
+		for i := 0; i < g.NumPolygons(); i++ {
+			polygons = append(polygons, g.Polygon(i))
+		}
+		return polygon(polygons), nil
+	case *geom.GeometryCollection:
+		union := make(s2.RegionUnion, 0, g.NumGeoms())
+		for _, child := range g.Geoms() {
+			region, err := Region(child)
+			if err != nil {
+				return nil, err
+			}
+			union = append(union, region)
+		}
+		return union, nil
+	default:
+		return nil, geom.ErrUnsupportedType{Value: g}
+	}
+}
+
+// Covering returns a normalized union of cells that covers g.
+func Covering(g geom.T, opts ...Option) (s2.CellUnion, error) {
+	region, err := Region(g)
+	if err != nil {
+		return nil, err
+	}
+	o := newOptions(opts)
+	return o.coverer.Covering(region), nil
+}
+
+// InteriorCovering returns a normalized union of cells that is contained by
+// g. Points and lines have no interior, so their interior coverings are
+// empty.
+func InteriorCovering(g geom.T, opts ...Option) (s2.CellUnion, error) {
+	region, err := Region(g)
+	if err != nil {
+		return nil, err
+	}
+	o := newOptions(opts)
+	return o.coverer.InteriorCovering(region), nil
+}
+
+// CellPolygon returns the Polygon of the cell id, with its four vertices in
+// counter-clockwise order. Longitudes are unwrapped so that the edges of cells
+// crossing the antimeridian do not span the whole world, so they may be
+// outside [-180, 180]. The polygons of the cells containing the poles do not
+// contain the poles.
+func CellPolygon(id s2.CellID) *geom.Polygon {
+	cell := s2.CellFromCellID(id)
+	flatCoords := make([]float64, 0, 10)
+	for k := 0; k < 4; k++ {
+		ll := s2.LatLngFromPoint(cell.Vertex(k))
+		lng := ll.Lng.Degrees()
+		if k > 0 {
+			lng = unwrap(lng, flatCoords[0])
+		}
+		flatCoords = append(flatCoords, lng, ll.Lat.Degrees())
+	}
+	flatCoords = append(flatCoords, flatCoords[0], flatCoords[1])
+	return geom.NewPolygonFlat(geom.XY, flatCoords, []int{len(flatCoords)})
+}
+
+// MultiPolygon returns a MultiPolygon with the polygons of the cells of
+// cellUnion, as returned by CellPolygon. Adjacent cells are not merged.
+func MultiPolygon(cellUnion s2.CellUnion) *geom.MultiPolygon {
+	flatCoords := make([]float64, 0, 10*len(cellUnion))
+	endss := make([][]int, 0, len(cellUnion))
+	for _, id := range cellUnion {
+		flatCoords = append(flatCoords, CellPolygon(id).FlatCoords()...)
+		endss = append(endss, []int{len(flatCoords)})
+	}
+	return geom.NewMultiPolygonFlat(geom.XY, flatCoords, endss)
+}
+
+func latLng(coord []float64) s2.LatLng {
+	return s2.LatLngFromDegrees(coord[1], coord[0])
+}
+
+func point(coord []float64) s2.Point {
+	return s2.PointFromLatLng(latLng(coord))
+}
+
+func polyline(flatCoords []float64, stride int) *s2.Polyline {
+	latLngs := make([]s2.LatLng, 0, len(flatCoords)/stride)
+	for i := 0; i < len(flatCoords); i += stride {
+		latLngs = append(latLngs, latLng(flatCoords[i:]))
+	}
+	return s2.PolylineFromLatLngs(latLngs)
+}
+
+// polygon returns the S2 polygon of polygons. Exterior rings are oriented
+// counter-clockwise and interior rings clockwise, as required by
+// s2.PolygonFromOrientedLoops.
+func polygon(polygons []*geom.Polygon) *s2.Polygon {
+	var loops []*s2.Loop
+	for _, p := range polygons {
+		for i := 0; i < p.NumLinearRings(); i++ {
+			ring := p.LinearRing(i)
+			points := ringPoints(ring.FlatCoords(), ring.Stride(), i == 0)
+			if len(points) < 3 {
+				if i == 0 {
+					break
+				}
+				continue
+			}
+			loops = append(loops, s2.LoopFromPoints(points))
+		}
+	}
+	return s2.PolygonFromOrientedLoops(loops)
+}
+
+// ringPoints returns the distinct vertices of ring, without the closing
+// vertex, oriented counter-clockwise if ccw is true and clockwise otherwise.
+func ringPoints(ring []float64, stride int, ccw bool) []s2.Point {
+	n := len(ring) / stride
+	if n > 1 && ring[0] == ring[(n-1)*stride] && ring[1] == ring[(n-1)*stride+1] {
+		n--
+	}
+	reverse := (planar.SignedArea(ring[:n*stride], stride) > 0) != ccw
+	points := make([]s2.Point, 0, n)
+	for k := 0; k < n; k++ {
+		i := k
+		if reverse {
+			i = n - 1 - k
+		}
+		p := point(ring[i*stride:])
+		if len(points) > 0 && points[len(points)-1] == p {
+			continue
+		}
+		points = append(points, p)
+	}
+	if len(points) > 1 && points[0] == points[len(points)-1] {
+		points = points[:len(points)-1]
+	}
+	return points
+}
+
+// unwrap returns lng shifted by a multiple of 360 to be within 180 of ref.
+func unwrap(lng, ref float64) float64 {
+	switch {
+	case lng-ref > 180:
+		return lng - 360
+	case lng-ref < -180:
+		return lng + 360
+	default:
+		return lng
+	}
+}
@@ -0,0 +1,133 @@
+package s2cell
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/golang/geo/s2"
+	"github.com/twpayne/go-geom"
+)
+
+func TestRegion(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		g    geom.T
+		want s2.Region
+	}{
+		{
+			name: "point",
+			g:    geom.NewPointFlat(geom.XYZ, []float64{10, 20, 30}),
+			want: s2.PointFromLatLng(s2.LatLngFromDegrees(20, 10)),
+		},
+		{
+			name: "point_empty",
+			g:    geom.NewPointEmpty(geom.XY),
+			want: s2.RegionUnion{},
+		},
+		{
+			name: "line_string",
+			g:    geom.NewLineStringFlat(geom.XY, []float64{0, 0, 1, 1}),
+			want: s2.PolylineFromLatLngs([]s2.LatLng{
+				s2.LatLngFromDegrees(0, 0),
+				s2.LatLngFromDegrees(1, 1),
+			}),
+		},
+		{
+			name: "multi_point",
+			g:    geom.NewMultiPointFlat(geom.XY, []float64{1, 2, 3, 4}),
+			want: s2.RegionUnion{
+				s2.PointFromLatLng(s2.LatLngFromDegrees(2, 1)),
+				s2.PointFromLatLng(s2.LatLngFromDegrees(4, 3)),
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Region(tc.g)
+			if err != nil {
+				t.Fatalf("Region(...) == _, %v, want _, <nil>", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Region(...) == %#v, _, want %#v, _", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCoveringPoint(t *testing.T) {
+	got, err := Covering(geom.NewPointFlat(geom.XY, []float64{-0.1276, 51.5072}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := s2.CellUnion{s2.CellIDFromLatLng(s2.LatLngFromDegrees(51.5072, -0.1276))}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Covering(...) == %v, _, want %v, _", got, want)
+	}
+	got, err = Covering(geom.NewPointFlat(geom.XY, []float64{-0.1276, 51.5072}), WithMaxLevel(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (s2.CellUnion{want[0].Parent(10)}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Covering(..., WithMaxLevel(10)) == %v, _, want %v, _", got, want)
+	}
+}
+
+func TestCoveringPolygon(t *testing.T) {
+	ccw := geom.NewPolygonFlat(geom.XY, []float64{
+		0, 0, 4, 0, 4, 4, 0, 4, 0, 0,
+		1, 1, 1, 3, 3, 3, 3, 1, 1, 1,
+	}, []int{10, 20})
+	cw := geom.NewPolygonFlat(geom.XY, []float64{
+		0, 0, 0, 4, 4, 4, 4, 0, 0, 0,
+		1, 1, 3, 1, 3, 3, 1, 3, 1, 1,
+	}, []int{10, 20})
+	opts := []Option{WithMinLevel(4), WithMaxLevel(12), WithMaxCells(32)}
+	covering, err := Covering(ccw, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(covering) == 0 {
+		t.Fatal("Covering(ccw, ...) is empty")
+	}
+	for _, id := range covering {
+		if level := id.Level(); level < 4 || level > 12 {
+			t.Errorf("cell %v has level %d, want between 4 and 12", id, level)
+		}
+	}
+	if cwCovering, err := Covering(cw, opts...); err != nil || !reflect.DeepEqual(cwCovering, covering) {
+		t.Errorf("Covering(cw, ...) == %v, %v, want %v, <nil>", cwCovering, err, covering)
+	}
+	for _, lngLat := range [][2]float64{{0.5, 0.5}, {3.5, 3.5}, {2, 0.5}} {
+		if !covering.ContainsPoint(s2.PointFromLatLng(s2.LatLngFromDegrees(lngLat[1], lngLat[0]))) {
+			t.Errorf("covering does not contain %v", lngLat)
+		}
+	}
+	interior, err := InteriorCovering(ccw, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(interior) == 0 {
+		t.Fatal("InteriorCovering(ccw, ...) is empty")
+	}
+	if interior.ContainsPoint(s2.PointFromLatLng(s2.LatLngFromDegrees(2, 2))) {
+		t.Errorf("interior covering contains the hole")
+	}
+}
+
+func TestCellPolygon(t *testing.T) {
+	id := s2.CellIDFromLatLng(s2.LatLngFromDegrees(51.5072, -0.1276)).Parent(12)
+	p := CellPolygon(id)
+	if p.NumLinearRings() != 1 || p.NumCoords() != 5 {
+		t.Fatalf("CellPolygon(%v) has %d rings and %d coords, want 1 and 5", id, p.NumLinearRings(), p.NumCoords())
+	}
+	if !p.Bounds().OverlapsPoint(geom.XY, geom.Coord{-0.1276, 51.5072}) {
+		t.Errorf("CellPolygon(%v) == %v, does not contain the point", id, p.FlatCoords())
+	}
+	mp := MultiPolygon(s2.CellUnion{id, id.Next()})
+	if mp.NumPolygons() != 2 || !reflect.DeepEqual(mp.Polygon(0), p) {
+		t.Errorf("MultiPolygon(...) == %v, want two polygons starting with %v", mp.FlatCoords(), p.FlatCoords())
+	}
+	antimeridian := CellPolygon(s2.CellIDFromLatLng(s2.LatLngFromDegrees(0, 180)).Parent(2))
+	if b := antimeridian.Bounds(); b.Max(0)-b.Min(0) > 180 {
+		t.Errorf("CellPolygon(...) == %v, spans more than 180 degrees", antimeridian.FlatCoords())
+	}
+}
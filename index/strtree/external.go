@@ -0,0 +1,62 @@
+package strtree
+
+import (
+	"github.com/twpayne/go-geom"
+)
+
+// A FetchFunc returns the geometry with the given id from external storage.
+type FetchFunc func(id int64) (geom.T, error)
+
+// An Item is an entry in a Tree whose geometry is held in external storage.
+type Item struct {
+	ID     int64
+	Bounds *geom.Bounds
+}
+
+// NewExternal returns a new Tree indexing items with at most nodeCapacity
+// children per node. The tree holds only the ids and bounds of the items, so
+// it can index datasets whose geometries do not fit in memory. Geometries are
+// loaded on demand by Fetch, which calls fetch. Items with empty bounds are not
+// indexed.
+func NewExternal(nodeCapacity int, items []Item, fetch FetchFunc) *Tree {
+	if nodeCapacity < 2 {
+		panic("strtree: node capacity must be at least 2")
+	}
+	var indexes []int
+	var boxes []float64
+	ids := make([]int64, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+		if item.Bounds.IsEmpty() {
+			continue
+		}
+		indexes = append(indexes, i)
+		boxes = append(boxes, item.Bounds.Min(0), item.Bounds.Min(1), item.Bounds.Max(0), item.Bounds.Max(1))
+	}
+	t := &Tree{
+		nodeCapacity: nodeCapacity,
+		numGeoms:     len(items),
+		ids:          ids,
+		fetch:        fetch,
+	}
+	t.build(indexes, boxes)
+	return t
+}
+
+// ID returns the id of the ith item. For trees built over in-memory
+// geometries, the id is i.
+func (t *Tree) ID(i int) int64 {
+	if t.ids == nil {
+		return int64(i)
+	}
+	return t.ids[i]
+}
+
+// Fetch returns the ith geometry, loading it from external storage if
+// necessary.
+func (t *Tree) Fetch(i int) (geom.T, error) {
+	if t.ids == nil || t.fetch == nil {
+		return t.Geom(i), nil
+	}
+	return t.fetch(t.ids[i])
+}
@@ -0,0 +1,210 @@
+package strtree
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/twpayne/go-geom"
+)
+
+// The serialized form of a Tree is a fixed-size header followed by the flat
+// arrays that make up the tree, all little endian:
+//
+//	magic        [4]byte  "STRT"
+//	version      uint32
+//	flags        uint32   flagIDs if the tree has ids
+//	nodeCapacity uint32
+//	numGeoms     uint32   number of geometries or items passed to the constructor
+//	numLeaves    uint32
+//	numNodes     uint32
+//	reserved     uint32
+//	boxes        [4 * numNodes]float64
+//	ids          [numGeoms]int64, only if flags has flagIDs
+//	children     [2 * (numNodes - numLeaves)]uint32
+//	indexes      [numLeaves]uint32
+//
+// The header is 32 bytes long so the boxes and ids are 8-byte aligned if the
+// data are. Unmarshal copies the arrays out of the data, so the data may be
+// reused or unmapped once it returns.
+
+const (
+	magic         = "STRT"
+	version       = 1
+	headerSize    = 32
+	flagIDs       = 1
+	maxArrayIndex = math.MaxUint32
+)
+
+var errCorrupt = errors.New("strtree: corrupt data")
+
+// An ErrUnsupportedVersion is returned when the serialized form has an
+// unsupported version.
+type ErrUnsupportedVersion uint32
+
+func (e ErrUnsupportedVersion) Error() string {
+	return fmt.Sprintf("strtree: unsupported version %d", uint32(e))
+}
+
+// An ErrGeomsMismatch is returned when the number of geometries passed to
+// Unmarshal does not match the number used to build the tree.
+type ErrGeomsMismatch struct {
+	Got  int
+	Want int
+}
+
+func (e ErrGeomsMismatch) Error() string {
+	return fmt.Sprintf("strtree: got %d geometries, want %d", e.Got, e.Want)
+}
+
+// Marshal returns the serialized form of t. The geometries themselves are not
+// serialized.
+func Marshal(t *Tree) ([]byte, error) {
+	numNodes := len(t.boxes) / 4
+	if uint64(t.numGeoms) > maxArrayIndex || uint64(numNodes) > maxArrayIndex {
+		return nil, errors.New("strtree: tree too large")
+	}
+	var flags uint32
+	if t.ids != nil {
+		flags |= flagIDs
+	}
+	data := make([]byte, headerSize+8*len(t.boxes)+8*len(t.ids)+4*len(t.children)+4*len(t.indexes))
+	copy(data, magic)
+	le := binary.LittleEndian
+	le.PutUint32(data[4:], version)
+	le.PutUint32(data[8:], flags)
+	le.PutUint32(data[12:], uint32(t.nodeCapacity))
+	le.PutUint32(data[16:], uint32(t.numGeoms))
+	le.PutUint32(data[20:], uint32(t.numLeaves))
+	le.PutUint32(data[24:], uint32(numNodes))
+	offset := headerSize
+	for _, f := range t.boxes {
+		le.PutUint64(data[offset:], math.Float64bits(f))
+		offset += 8
+	}
+	for _, id := range t.ids {
+		le.PutUint64(data[offset:], uint64(id))
+		offset += 8
+	}
+	for _, child := range t.children {
+		le.PutUint32(data[offset:], uint32(child))
+		offset += 4
+	}
+	for _, index := range t.indexes {
+		le.PutUint32(data[offset:], uint32(index))
+		offset += 4
+	}
+	return data, nil
+}
+
+// Unmarshal returns the Tree serialized in data. geoms must be the same
+// geometries, in the same order, as were used to build the tree, or nil if
+// only the indexes or ids of the geometries are needed, in which case Geom
+// returns nil.
+func Unmarshal(data []byte, geoms []geom.T) (*Tree, error) {
+	if len(data) < headerSize || string(data[:4]) != magic {
+		return nil, errCorrupt
+	}
+	le := binary.LittleEndian
+	if v := le.Uint32(data[4:]); v != version {
+		return nil, ErrUnsupportedVersion(v)
+	}
+	flags := le.Uint32(data[8:])
+	nodeCapacity := int(le.Uint32(data[12:]))
+	numGeoms := int(le.Uint32(data[16:]))
+	numLeaves := int(le.Uint32(data[20:]))
+	numNodes := int(le.Uint32(data[24:]))
+	if flags&^flagIDs != 0 || nodeCapacity < 2 || numLeaves > numGeoms || numLeaves > numNodes || (numLeaves == 0) != (numNodes == 0) {
+		return nil, errCorrupt
+	}
+	numInternal := numNodes - numLeaves
+	numIDs := 0
+	if flags&flagIDs != 0 {
+		numIDs = numGeoms
+	}
+	if len(data) != headerSize+32*numNodes+8*numIDs+8*numInternal+4*numLeaves {
+		return nil, errCorrupt
+	}
+	if geoms != nil && len(geoms) != numGeoms {
+		return nil, ErrGeomsMismatch{Got: len(geoms), Want: numGeoms}
+	}
+
+	t := &Tree{
+		nodeCapacity: nodeCapacity,
+		numGeoms:     numGeoms,
+		numLeaves:    numLeaves,
+		boxes:        make([]float64, 4*numNodes),
+		children:     make([]int, 2*numInternal),
+		indexes:      make([]int, numLeaves),
+		geoms:        geoms,
+	}
+	offset := headerSize
+	for i := range t.boxes {
+		t.boxes[i] = math.Float64frombits(le.Uint64(data[offset:]))
+		offset += 8
+	}
+	if flags&flagIDs != 0 {
+		t.ids = make([]int64, numIDs)
+		for i := range t.ids {
+			t.ids[i] = int64(le.Uint64(data[offset:]))
+			offset += 8
+		}
+	}
+	for i := range t.children {
+		t.children[i] = int(le.Uint32(data[offset:]))
+		offset += 4
+	}
+	seen := make([]bool, numGeoms)
+	for i := range t.indexes {
+		t.indexes[i] = int(le.Uint32(data[offset:]))
+		offset += 4
+		if t.indexes[i] >= numGeoms || seen[t.indexes[i]] {
+			return nil, errCorrupt
+		}
+		seen[t.indexes[i]] = true
+	}
+
+	// Check that every internal node's children precede it and that every
+	// node other than the root is the child of exactly one internal node, so
+	// that traversals visit each node once, always terminate, and never index
+	// out of range. Check also that every node's bounds contain its
+	// children's, so that pruning a node never hides a match.
+	hasParent := make([]bool, numNodes)
+	for i := 0; i < numInternal; i++ {
+		node := numLeaves + i
+		start, end := t.children[2*i], t.children[2*i+1]
+		if start >= end || end > node || end-start > nodeCapacity {
+			return nil, errCorrupt
+		}
+		for child := start; child < end; child++ {
+			if hasParent[child] || !containsBox(t.boxes[4*node:4*node+4], t.boxes[4*child:4*child+4]) {
+				return nil, errCorrupt
+			}
+			hasParent[child] = true
+		}
+	}
+	for node := 0; node < numNodes-1; node++ {
+		if !hasParent[node] {
+			return nil, errCorrupt
+		}
+	}
+	return t, nil
+}
+
+// containsBox returns whether box contains child. Boxes with NaN coordinates,
+// as built from geometries with NaN coordinates, are treated as contained.
+func containsBox(box, child []float64) bool {
+	return !(child[0] < box[0] || child[1] < box[1] || child[2] > box[2] || child[3] > box[3])
+}
+
+// UnmarshalExternal returns the Tree serialized in data, typically by a tree
+// built with NewExternal, that loads its geometries with fetch.
+func UnmarshalExternal(data []byte, fetch FetchFunc) (*Tree, error) {
+	t, err := Unmarshal(data, nil)
+	if err != nil {
+		return nil, err
+	}
+	t.fetch = fetch
+	return t, nil
+}
@@ -0,0 +1,100 @@
+package strtree
+
+import (
+	"math"
+	"sort"
+
+	"github.com/twpayne/go-geom"
+)
+
+// A Cursor browses the geometries in a Tree in increasing order of the
+// distance from a query point to their bounds. Since the distance to a
+// geometry's bounds is a lower bound on the distance to the geometry itself,
+// the geometries returned are candidates which callers should refine with an
+// exact distance calculation if required.
+//
+// A Cursor does the minimum amount of work needed to return each candidate,
+// so callers searching for the nearest geometry that satisfies an expensive
+// predicate can stop as soon as they find one.
+type Cursor struct {
+	tree    *Tree
+	x, y    float64
+	pending []cursorEntry // sorted by decreasing distance
+}
+
+type cursorEntry struct {
+	node     int
+	distance float64
+}
+
+// Nearest returns a new Cursor that browses the geometries in t in increasing
+// order of distance from c.
+func (t *Tree) Nearest(c geom.Coord) *Cursor {
+	cursor := &Cursor{
+		tree: t,
+		x:    c[0],
+		y:    c[1],
+	}
+	if t.numLeaves != 0 {
+		cursor.push(t.root())
+	}
+	return cursor
+}
+
+// Next returns the index of the next nearest geometry and the distance from
+// the query point to its bounds. ok is false when there are no more
+// geometries.
+func (c *Cursor) Next() (i int, distance float64, ok bool) {
+	for len(c.pending) > 0 {
+		entry := c.pending[len(c.pending)-1]
+		c.pending = c.pending[:len(c.pending)-1]
+		if entry.node < c.tree.numLeaves {
+			return c.tree.indexes[entry.node], entry.distance, true
+		}
+		start, end := c.tree.childRange(entry.node)
+		for child := start; child < end; child++ {
+			c.push(child)
+		}
+	}
+	return 0, 0, false
+}
+
+// push inserts node into c's pending list, which is kept sorted by decreasing
+// distance so that the nearest entry can be popped from the end. Ties are
+// broken in favor of leaves so that candidates are returned as early as
+// possible.
+func (c *Cursor) push(node int) {
+	entry := cursorEntry{
+		node:     node,
+		distance: c.tree.distance(node, c.x, c.y),
+	}
+	isLeaf := node < c.tree.numLeaves
+	i := sort.Search(len(c.pending), func(i int) bool {
+		if c.pending[i].distance != entry.distance {
+			return c.pending[i].distance < entry.distance
+		}
+		return !isLeaf && c.pending[i].node < c.tree.numLeaves
+	})
+	c.pending = append(c.pending, cursorEntry{})
+	copy(c.pending[i+1:], c.pending[i:])
+	c.pending[i] = entry
+}
+
+// distance returns the distance from (x, y) to the bounds of node.
+func (t *Tree) distance(node int, x, y float64) float64 {
+	b := t.boxes[4*node : 4*node+4]
+	var dx, dy float64
+	switch {
+	case x < b[0]:
+		dx = b[0] - x
+	case x > b[2]:
+		dx = x - b[2]
+	}
+	switch {
+	case y < b[1]:
+		dy = b[1] - y
+	case y > b[3]:
+		dy = y - b[3]
+	}
+	return math.Hypot(dx, dy)
+}
@@ -0,0 +1,203 @@
+// Package strtree implements a static, two-dimensional R-tree packed with the
+// Sort-Tile-Recursive (STR) algorithm.
+//
+// The tree is stored in a small number of flat arrays rather than as a graph
+// of node objects. Leaf slots come first, followed by the internal nodes of
+// each level in turn, with the root last.
+package strtree
+
+import (
+	"math"
+	"sort"
+
+	"github.com/twpayne/go-geom"
+)
+
+// DefaultNodeCapacity is the default maximum number of children per node.
+const DefaultNodeCapacity = 10
+
+// A Tree is a static, two-dimensional, STR-packed R-tree over a collection of
+// geometries. Empty geometries are not indexed.
+type Tree struct {
+	nodeCapacity int
+	numGeoms     int
+	numLeaves    int
+	boxes        []float64 // minX, minY, maxX, maxY for each node
+	children     []int     // start and end child node for each internal node
+	indexes      []int     // index into geoms or ids for each leaf
+	geoms        []geom.T
+	ids          []int64
+	fetch        FetchFunc
+}
+
+// New returns a new Tree indexing geoms with DefaultNodeCapacity.
+func New(geoms []geom.T) *Tree {
+	return NewWithNodeCapacity(DefaultNodeCapacity, geoms)
+}
+
+// NewWithNodeCapacity returns a new Tree indexing geoms with at most
+// nodeCapacity children per node.
+func NewWithNodeCapacity(nodeCapacity int, geoms []geom.T) *Tree {
+	if nodeCapacity < 2 {
+		panic("strtree: node capacity must be at least 2")
+	}
+	var indexes []int
+	var boxes []float64
+	for i, g := range geoms {
+		b := g.Bounds()
+		if b.IsEmpty() {
+			continue
+		}
+		indexes = append(indexes, i)
+		boxes = append(boxes, b.Min(0), b.Min(1), b.Max(0), b.Max(1))
+	}
+	t := &Tree{
+		nodeCapacity: nodeCapacity,
+		numGeoms:     len(geoms),
+		geoms:        geoms,
+	}
+	t.build(indexes, boxes)
+	return t
+}
+
+// Geom returns the ith geometry passed to the constructor, or nil if t was
+// unmarshaled without its geometries.
+func (t *Tree) Geom(i int) geom.T {
+	if t.geoms == nil {
+		return nil
+	}
+	return t.geoms[i]
+}
+
+// Len returns the number of indexed geometries.
+func (t *Tree) Len() int {
+	return t.numLeaves
+}
+
+// Query calls fn with the index of every geometry whose bounds overlap b in
+// two dimensions. Iteration stops if fn returns false.
+func (t *Tree) Query(b *geom.Bounds, fn func(i int) bool) {
+	if t.numLeaves == 0 || b.IsEmpty() {
+		return
+	}
+	query := [4]float64{b.Min(0), b.Min(1), b.Max(0), b.Max(1)}
+	stack := []int{t.root()}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if !t.overlaps(node, &query) {
+			continue
+		}
+		if node < t.numLeaves {
+			if !fn(t.indexes[node]) {
+				return
+			}
+			continue
+		}
+		start, end := t.childRange(node)
+		for child := end - 1; child >= start; child-- {
+			stack = append(stack, child)
+		}
+	}
+}
+
+func (t *Tree) build(indexes []int, boxes []float64) {
+	t.numLeaves = len(indexes)
+	if t.numLeaves == 0 {
+		return
+	}
+	t.indexes = indexes
+	t.boxes = boxes
+
+	// Build each level on top of the previous one until only the root
+	// remains. The nodes of each level are first sorted into STR order so
+	// that consecutive runs of nodes can be grouped under a common parent.
+	levelStart, levelEnd := 0, t.numLeaves
+	for {
+		order := make([]int, levelEnd-levelStart)
+		for i := range order {
+			order[i] = i
+		}
+		t.strSort(order, t.boxes[4*levelStart:4*levelEnd])
+		t.permuteLevel(levelStart, order)
+		if levelEnd-levelStart == 1 {
+			return
+		}
+		for start := levelStart; start < levelEnd; start += t.nodeCapacity {
+			end := start + t.nodeCapacity
+			if end > levelEnd {
+				end = levelEnd
+			}
+			box := [4]float64{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)}
+			for child := start; child < end; child++ {
+				box[0] = math.Min(box[0], t.boxes[4*child])
+				box[1] = math.Min(box[1], t.boxes[4*child+1])
+				box[2] = math.Max(box[2], t.boxes[4*child+2])
+				box[3] = math.Max(box[3], t.boxes[4*child+3])
+			}
+			t.boxes = append(t.boxes, box[:]...)
+			t.children = append(t.children, start, end)
+		}
+		levelStart, levelEnd = levelEnd, len(t.boxes)/4
+	}
+}
+
+// permuteLevel reorders the nodes of the level starting at levelStart
+// according to order. The level above must not have been built yet.
+func (t *Tree) permuteLevel(levelStart int, order []int) {
+	boxes := make([]float64, 4*len(order))
+	for i, j := range order {
+		copy(boxes[4*i:4*i+4], t.boxes[4*(levelStart+j):4*(levelStart+j)+4])
+	}
+	copy(t.boxes[4*levelStart:], boxes)
+	if levelStart < t.numLeaves {
+		indexes := make([]int, len(order))
+		for i, j := range order {
+			indexes[i] = t.indexes[j]
+		}
+		t.indexes = indexes
+		return
+	}
+	children := make([]int, 2*len(order))
+	offset := levelStart - t.numLeaves
+	for i, j := range order {
+		copy(children[2*i:2*i+2], t.children[2*(offset+j):2*(offset+j)+2])
+	}
+	copy(t.children[2*offset:], children)
+}
+
+// strSort sorts nodes, which index into boxes, into Sort-Tile-Recursive order.
+func (t *Tree) strSort(nodes []int, boxes []float64) {
+	centerX := func(i int) float64 { return boxes[4*i] + boxes[4*i+2] }
+	centerY := func(i int) float64 { return boxes[4*i+1] + boxes[4*i+3] }
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return centerX(nodes[i]) < centerX(nodes[j])
+	})
+	numParents := (len(nodes) + t.nodeCapacity - 1) / t.nodeCapacity
+	numSlices := int(math.Ceil(math.Sqrt(float64(numParents))))
+	sliceSize := t.nodeCapacity * ((numParents + numSlices - 1) / numSlices)
+	for start := 0; start < len(nodes); start += sliceSize {
+		end := start + sliceSize
+		if end > len(nodes) {
+			end = len(nodes)
+		}
+		slice := nodes[start:end]
+		sort.SliceStable(slice, func(i, j int) bool {
+			return centerY(slice[i]) < centerY(slice[j])
+		})
+	}
+}
+
+func (t *Tree) childRange(node int) (int, int) {
+	i := 2 * (node - t.numLeaves)
+	return t.children[i], t.children[i+1]
+}
+
+func (t *Tree) overlaps(node int, box *[4]float64) bool {
+	b := t.boxes[4*node : 4*node+4]
+	return b[0] <= box[2] && box[0] <= b[2] && b[1] <= box[3] && box[1] <= b[3]
+}
+
+func (t *Tree) root() int {
+	return len(t.boxes)/4 - 1
+}
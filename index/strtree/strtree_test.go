@@ -0,0 +1,344 @@
+package strtree
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+)
+
+func randomGeoms(r *rand.Rand, n int) []geom.T {
+	geoms := make([]geom.T, n)
+	for i := range geoms {
+		x, y := 1000*r.Float64(), 1000*r.Float64()
+		if i%3 == 0 {
+			geoms[i] = geom.NewPointFlat(geom.XY, []float64{x, y})
+		} else {
+			dx, dy := 10*r.Float64(), 10*r.Float64()
+			geoms[i] = geom.NewLineStringFlat(geom.XY, []float64{x, y, x + dx, y + dy})
+		}
+	}
+	return geoms
+}
+
+func bruteForceQuery(geoms []geom.T, b *geom.Bounds) []int {
+	var result []int
+	for i, g := range geoms {
+		gb := g.Bounds()
+		if !gb.IsEmpty() && gb.Overlaps(geom.XY, b) {
+			result = append(result, i)
+		}
+	}
+	return result
+}
+
+func boundsDistance(g geom.T, x, y float64) float64 {
+	b := g.Bounds()
+	dx := math.Max(0, math.Max(b.Min(0)-x, x-b.Max(0)))
+	dy := math.Max(0, math.Max(b.Min(1)-y, y-b.Max(1)))
+	return math.Hypot(dx, dy)
+}
+
+func TestEmpty(t *testing.T) {
+	for _, tree := range []*Tree{
+		New(nil),
+		New([]geom.T{geom.NewPointEmpty(geom.XY), geom.NewLineString(geom.XY)}),
+	} {
+		if got := tree.Len(); got != 0 {
+			t.Errorf("tree.Len() == %d, want 0", got)
+		}
+		tree.Query(geom.NewBounds(geom.XY).Set(-1, -1, 1, 1), func(i int) bool {
+			t.Errorf("tree.Query called fn with %d, want no calls", i)
+			return true
+		})
+		if i, distance, ok := tree.Nearest(geom.Coord{0, 0}).Next(); ok {
+			t.Errorf("tree.Nearest(...).Next() == %d, %f, %t, want _, _, false", i, distance, ok)
+		}
+	}
+}
+
+func TestQuery(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+	for _, nodeCapacity := range []int{2, 3, 10, 16} {
+		for _, n := range []int{1, 2, 10, 100, 1000} {
+			geoms := randomGeoms(r, n)
+			tree := NewWithNodeCapacity(nodeCapacity, geoms)
+			if got := tree.Len(); got != n {
+				t.Errorf("tree.Len() == %d, want %d", got, n)
+			}
+			for j := 0; j < 10; j++ {
+				x, y := 1000*r.Float64(), 1000*r.Float64()
+				b := geom.NewBounds(geom.XY).Set(x, y, x+100*r.Float64(), y+100*r.Float64())
+				var got []int
+				tree.Query(b, func(i int) bool {
+					got = append(got, i)
+					return true
+				})
+				sort.Ints(got)
+				if want := bruteForceQuery(geoms, b); !reflect.DeepEqual(got, want) {
+					t.Errorf("nodeCapacity=%d, n=%d: tree.Query(%v, ...) returned %v, want %v", nodeCapacity, n, b, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestQueryStop(t *testing.T) {
+	geoms := randomGeoms(rand.New(rand.NewSource(0)), 100)
+	tree := New(geoms)
+	calls := 0
+	tree.Query(geom.NewBounds(geom.XY).Set(0, 0, 1000, 1000), func(int) bool {
+		calls++
+		return calls < 5
+	})
+	if calls != 5 {
+		t.Errorf("got %d calls, want 5", calls)
+	}
+}
+
+func TestNearest(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+	for _, nodeCapacity := range []int{2, 4, 10} {
+		for _, n := range []int{1, 7, 100, 500} {
+			geoms := randomGeoms(r, n)
+			tree := NewWithNodeCapacity(nodeCapacity, geoms)
+			x, y := 1000*r.Float64(), 1000*r.Float64()
+			cursor := tree.Nearest(geom.Coord{x, y})
+			seen := make(map[int]bool)
+			lastDistance := 0.0
+			for {
+				i, distance, ok := cursor.Next()
+				if !ok {
+					break
+				}
+				if seen[i] {
+					t.Errorf("nodeCapacity=%d, n=%d: cursor returned %d twice", nodeCapacity, n, i)
+				}
+				seen[i] = true
+				if want := boundsDistance(geoms[i], x, y); math.Abs(distance-want) > 1e-9 {
+					t.Errorf("nodeCapacity=%d, n=%d: cursor returned distance %f for %d, want %f", nodeCapacity, n, distance, i, want)
+				}
+				if distance < lastDistance {
+					t.Errorf("nodeCapacity=%d, n=%d: cursor returned distance %f after %f", nodeCapacity, n, distance, lastDistance)
+				}
+				lastDistance = distance
+			}
+			if len(seen) != n {
+				t.Errorf("nodeCapacity=%d, n=%d: cursor returned %d geometries, want %d", nodeCapacity, n, len(seen), n)
+			}
+		}
+	}
+}
+
+func TestNearestFiltered(t *testing.T) {
+	geoms := []geom.T{
+		geom.NewPointFlat(geom.XY, []float64{1, 0}),
+		geom.NewPointFlat(geom.XY, []float64{2, 0}),
+		geom.NewPointFlat(geom.XY, []float64{3, 0}),
+		geom.NewPointFlat(geom.XY, []float64{4, 0}),
+	}
+	tree := NewWithNodeCapacity(2, geoms)
+	cursor := tree.Nearest(geom.Coord{0, 0})
+	for {
+		i, distance, ok := cursor.Next()
+		if !ok {
+			t.Fatal("cursor exhausted")
+		}
+		if geoms[i].(*geom.Point).X() >= 2.5 {
+			if i != 2 || distance != 3 {
+				t.Errorf("cursor.Next() == %d, %f, true, want 2, 3, true", i, distance)
+			}
+			break
+		}
+	}
+	if i, distance, ok := cursor.Next(); !ok || i != 3 || distance != 4 {
+		t.Errorf("cursor.Next() == %d, %f, %t, want 3, 4, true", i, distance, ok)
+	}
+}
+
+func TestMarshalUnmarshal(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+	for _, n := range []int{0, 1, 10, 1000} {
+		geoms := randomGeoms(r, n)
+		tree := NewWithNodeCapacity(4, geoms)
+		data, err := Marshal(tree)
+		if err != nil {
+			t.Fatalf("Marshal(...) == _, %v, want _, <nil>", err)
+		}
+		for _, gs := range [][]geom.T{geoms, nil} {
+			got, err := Unmarshal(data, gs)
+			if err != nil {
+				t.Fatalf("n=%d: Unmarshal(...) == _, %v, want _, <nil>", n, err)
+			}
+			if gotData, err := Marshal(got); err != nil || !reflect.DeepEqual(gotData, data) {
+				t.Errorf("n=%d: Marshal(Unmarshal(data)) == %v, %v, want %v, <nil>", n, gotData, err, data)
+			}
+			if n > 0 && got.Geom(0) != geomAt(gs, 0) {
+				t.Errorf("n=%d: got.Geom(0) == %v, want %v", n, got.Geom(0), geomAt(gs, 0))
+			}
+			b := geom.NewBounds(geom.XY).Set(250, 250, 750, 750)
+			var gotIndexes []int
+			got.Query(b, func(i int) bool {
+				gotIndexes = append(gotIndexes, i)
+				return true
+			})
+			sort.Ints(gotIndexes)
+			if want := bruteForceQuery(geoms, b); !reflect.DeepEqual(gotIndexes, want) {
+				t.Errorf("n=%d: got.Query(%v, ...) returned %v, want %v", n, b, gotIndexes, want)
+			}
+		}
+	}
+}
+
+func geomAt(geoms []geom.T, i int) geom.T {
+	if geoms == nil {
+		return nil
+	}
+	return geoms[i]
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	geoms := randomGeoms(rand.New(rand.NewSource(0)), 100)
+	data, err := Marshal(New(geoms))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Unmarshal(data, geoms[:99]); !reflect.DeepEqual(err, ErrGeomsMismatch{Got: 99, Want: 100}) {
+		t.Errorf("Unmarshal(data, geoms[:99]) == _, %v, want _, %v", err, ErrGeomsMismatch{Got: 99, Want: 100})
+	}
+	badVersion := append([]byte(nil), data...)
+	badVersion[4] = 2 // version
+	if _, err := Unmarshal(badVersion, nil); err != ErrUnsupportedVersion(2) {
+		t.Errorf("Unmarshal(badVersion, nil) == _, %v, want _, %v", err, ErrUnsupportedVersion(2))
+	}
+	for i := 0; i < len(data); i++ {
+		if _, err := Unmarshal(data[:i], nil); err == nil {
+			t.Errorf("Unmarshal(data[:%d], nil) == _, <nil>, want _, !<nil>", i)
+		}
+	}
+	// Corrupt every byte in turn and check that any tree that is accepted can
+	// be queried without panicking.
+	for i := 0; i < len(data); i++ {
+		corrupt := append([]byte(nil), data...)
+		corrupt[i] ^= 0xff
+		tree, err := Unmarshal(corrupt, nil)
+		if err != nil {
+			continue
+		}
+		tree.Query(geom.NewBounds(geom.XY).Set(0, 0, 1000, 1000), func(int) bool { return true })
+		cursor := tree.Nearest(geom.Coord{500, 500})
+		for _, _, ok := cursor.Next(); ok; _, _, ok = cursor.Next() {
+		}
+	}
+}
+
+func TestUnmarshalInconsistent(t *testing.T) {
+	geoms := randomGeoms(rand.New(rand.NewSource(0)), 10)
+	data, err := Marshal(NewWithNodeCapacity(4, geoms))
+	if err != nil {
+		t.Fatal(err)
+	}
+	le := binary.LittleEndian
+	numLeaves := int(le.Uint32(data[20:]))
+	numNodes := int(le.Uint32(data[24:]))
+	boxesOffset := headerSize
+	childrenOffset := boxesOffset + 32*numNodes
+	indexesOffset := childrenOffset + 8*(numNodes-numLeaves)
+	for _, tc := range []struct {
+		name    string
+		corrupt func([]byte)
+	}{
+		{
+			name: "duplicate index",
+			corrupt: func(data []byte) {
+				copy(data[indexesOffset+4:indexesOffset+8], data[indexesOffset:indexesOffset+4])
+			},
+		},
+		{
+			name: "overlapping children",
+			corrupt: func(data []byte) {
+				start := le.Uint32(data[childrenOffset+8:])
+				le.PutUint32(data[childrenOffset+8:], start-1)
+			},
+		},
+		{
+			name: "orphaned node",
+			corrupt: func(data []byte) {
+				end := le.Uint32(data[childrenOffset+4:])
+				le.PutUint32(data[childrenOffset+4:], end-1)
+			},
+		},
+		{
+			name: "uncontained bounds",
+			corrupt: func(data []byte) {
+				le.PutUint64(data[boxesOffset:], math.Float64bits(-1))
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			corrupt := append([]byte(nil), data...)
+			tc.corrupt(corrupt)
+			if _, err := Unmarshal(corrupt, nil); err != errCorrupt {
+				t.Errorf("Unmarshal(...) == _, %v, want _, %v", err, errCorrupt)
+			}
+		})
+	}
+}
+
+func TestExternal(t *testing.T) {
+	geoms := randomGeoms(rand.New(rand.NewSource(0)), 100)
+	items := make([]Item, len(geoms))
+	storage := make(map[int64]geom.T)
+	for i, g := range geoms {
+		id := int64(1000 + 7*i)
+		items[i] = Item{ID: id, Bounds: g.Bounds()}
+		storage[id] = g
+	}
+	var fetches int
+	fetch := func(id int64) (geom.T, error) {
+		fetches++
+		g, ok := storage[id]
+		if !ok {
+			return nil, fmt.Errorf("missing id %d", id)
+		}
+		return g, nil
+	}
+	tree := NewExternal(4, items, fetch)
+	data, err := Marshal(tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unmarshaled, err := UnmarshalExternal(data, fetch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := geom.NewBounds(geom.XY).Set(250, 250, 500, 500)
+	want := bruteForceQuery(geoms, b)
+	for _, tree := range []*Tree{tree, unmarshaled} {
+		fetches = 0
+		var got []int
+		tree.Query(b, func(i int) bool {
+			if id := tree.ID(i); id != items[i].ID {
+				t.Errorf("tree.ID(%d) == %d, want %d", i, id, items[i].ID)
+			}
+			g, err := tree.Fetch(i)
+			if err != nil || g != geoms[i] {
+				t.Errorf("tree.Fetch(%d) == %v, %v, want %v, <nil>", i, g, err, geoms[i])
+			}
+			got = append(got, i)
+			return true
+		})
+		sort.Ints(got)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("tree.Query(%v, ...) returned %v, want %v", b, got, want)
+		}
+		if fetches != len(want) {
+			t.Errorf("got %d fetches, want %d", fetches, len(want))
+		}
+	}
+}
@@ -0,0 +1,26 @@
+// Package planar contains planar algorithms on flat coordinates that are
+// shared by several packages.
+package planar
+
+// SignedArea returns the signed area of the ring flatCoords with stride,
+// which is positive if the ring is counterclockwise. The ring may or may not
+// repeat its first coordinate as its last.
+func SignedArea(flatCoords []float64, stride int) float64 {
+	area := 0.0
+	n := len(flatCoords)
+	for i := 0; i < n; i += stride {
+		j := (i + stride) % n
+		area += flatCoords[i]*flatCoords[j+1] - flatCoords[j]*flatCoords[i+1]
+	}
+	return area / 2
+}
+
+// Reverse reverses the order of the coordinates of flatCoords with stride in
+// place.
+func Reverse(flatCoords []float64, stride int) {
+	for i, j := 0, len(flatCoords)-stride; i < j; i, j = i+stride, j-stride {
+		for k := 0; k < stride; k++ {
+			flatCoords[i+k], flatCoords[j+k] = flatCoords[j+k], flatCoords[i+k]
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package planar
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSignedArea(t *testing.T) {
+	for _, tc := range []struct {
+		flatCoords []float64
+		stride     int
+		want       float64
+	}{
+		{flatCoords: nil, stride: 2, want: 0},
+		{flatCoords: []float64{0, 0, 1, 1}, stride: 2, want: 0},
+		{flatCoords: []float64{0, 0, 2, 0, 2, 2, 0, 2, 0, 0}, stride: 2, want: 4},
+		{flatCoords: []float64{0, 0, 2, 0, 2, 2, 0, 2}, stride: 2, want: 4},
+		{flatCoords: []float64{0, 0, 0, 2, 2, 2, 2, 0, 0, 0}, stride: 2, want: -4},
+		{flatCoords: []float64{0, 0, 9, 2, 0, 9, 2, 2, 9, 0, 0, 9}, stride: 3, want: 2},
+	} {
+		if got := SignedArea(tc.flatCoords, tc.stride); got != tc.want {
+			t.Errorf("SignedArea(%v, %d) == %v, want %v", tc.flatCoords, tc.stride, got, tc.want)
+		}
+	}
+}
+
+func TestReverse(t *testing.T) {
+	for _, tc := range []struct {
+		flatCoords []float64
+		stride     int
+		want       []float64
+	}{
+		{flatCoords: nil, stride: 2, want: nil},
+		{flatCoords: []float64{1, 2}, stride: 2, want: []float64{1, 2}},
+		{flatCoords: []float64{1, 2, 3, 4, 5, 6}, stride: 2, want: []float64{5, 6, 3, 4, 1, 2}},
+		{flatCoords: []float64{1, 2, 3, 4, 5, 6}, stride: 3, want: []float64{4, 5, 6, 1, 2, 3}},
+	} {
+		got := append([]float64(nil), tc.flatCoords...)
+		Reverse(got, tc.stride)
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Reverse(%v, %d) == %v, want %v", tc.flatCoords, tc.stride, got, tc.want)
+		}
+	}
+}
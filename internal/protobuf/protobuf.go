@@ -0,0 +1,215 @@
+// Package protobuf implements the low-level protocol buffer wire format, for
+// the encodings that are defined by protocol buffer messages. See
+// https://protobuf.dev/programming-guides/encoding/.
+package protobuf
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// Wire types.
+const (
+	Varint  = 0
+	Fixed64 = 1
+	Bytes   = 2
+	Fixed32 = 5
+)
+
+// ErrInvalid is returned when decoding malformed data.
+var ErrInvalid = errors.New("protobuf: invalid data")
+
+// AppendVarint appends the varint encoding of v to b.
+func AppendVarint(b []byte, v uint64) []byte {
+	for v >= 0x80 {
+		b = append(b, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(b, byte(v))
+}
+
+// AppendKey appends the key of field with wireType to b.
+func AppendKey(b []byte, field, wireType int) []byte {
+	return AppendVarint(b, uint64(field)<<3|uint64(wireType))
+}
+
+// AppendVarintField appends the varint field with value v to b.
+func AppendVarintField(b []byte, field int, v uint64) []byte {
+	return AppendVarint(AppendKey(b, field, Varint), v)
+}
+
+// AppendFixed32Field appends the fixed32 field with value v to b.
+func AppendFixed32Field(b []byte, field int, v uint32) []byte {
+	b = AppendKey(b, field, Fixed32)
+	return append(b, byte(v), byte(v>>8), byte(v>>16), byte(v>>24))
+}
+
+// AppendFixed64Field appends the fixed64 field with value v to b.
+func AppendFixed64Field(b []byte, field int, v uint64) []byte {
+	b = AppendKey(b, field, Fixed64)
+	return append(b, byte(v), byte(v>>8), byte(v>>16), byte(v>>24), byte(v>>32), byte(v>>40), byte(v>>48), byte(v>>56))
+}
+
+// AppendBytesField appends the length-delimited field with value data to b.
+func AppendBytesField(b []byte, field int, data []byte) []byte {
+	b = AppendVarint(AppendKey(b, field, Bytes), uint64(len(data)))
+	return append(b, data...)
+}
+
+// AppendPackedField appends the packed repeated varint field with values vs
+// to b.
+func AppendPackedField(b []byte, field int, vs []uint64) []byte {
+	var data []byte
+	for _, v := range vs {
+		data = AppendVarint(data, v)
+	}
+	return AppendBytesField(b, field, data)
+}
+
+// Zigzag returns the zigzag encoding of v, as used by sint32 and sint64
+// fields.
+func Zigzag(v int64) uint64 {
+	return uint64(v<<1) ^ uint64(v>>63)
+}
+
+// Unzigzag returns the value of the zigzag encoding v.
+func Unzigzag(v uint64) int64 {
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+// A Decoder decodes the fields of a message. Errors are sticky: after the
+// first error, all methods return zero values and Err returns the error.
+type Decoder struct {
+	data     []byte
+	field    int
+	wireType int
+	err      error
+}
+
+// NewDecoder returns a new Decoder that decodes the message in data.
+func NewDecoder(data []byte) *Decoder {
+	return &Decoder{
+		data: data,
+	}
+}
+
+// Next advances to the next field and returns whether there is one.
+func (d *Decoder) Next() bool {
+	if d.err != nil || len(d.data) == 0 {
+		return false
+	}
+	key := d.Varint()
+	d.field, d.wireType = int(key>>3), int(key&7)
+	return d.err == nil
+}
+
+// Field returns the number of the current field.
+func (d *Decoder) Field() int {
+	return d.field
+}
+
+// WireType returns the wire type of the current field.
+func (d *Decoder) WireType() int {
+	return d.wireType
+}
+
+// Err returns the first error encountered.
+func (d *Decoder) Err() error {
+	return d.err
+}
+
+// Varint returns the next varint.
+func (d *Decoder) Varint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(d.data)
+	if n <= 0 {
+		d.err = ErrInvalid
+		return 0
+	}
+	d.data = d.data[n:]
+	return v
+}
+
+// Bytes returns the value of the current length-delimited field.
+func (d *Decoder) Bytes() []byte {
+	n := d.Varint()
+	if d.err != nil {
+		return nil
+	}
+	if n > uint64(len(d.data)) {
+		d.err = ErrInvalid
+		return nil
+	}
+	data := d.data[:n]
+	d.data = d.data[n:]
+	return data
+}
+
+// Fixed32 returns the value of the current fixed32 field.
+func (d *Decoder) Fixed32() uint32 {
+	if data := d.fixed(4); data != nil {
+		return binary.LittleEndian.Uint32(data)
+	}
+	return 0
+}
+
+// Fixed64 returns the value of the current fixed64 field.
+func (d *Decoder) Fixed64() uint64 {
+	if data := d.fixed(8); data != nil {
+		return binary.LittleEndian.Uint64(data)
+	}
+	return 0
+}
+
+// fixed returns the next n bytes.
+func (d *Decoder) fixed(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if len(d.data) < n {
+		d.err = ErrInvalid
+		return nil
+	}
+	data := d.data[:n]
+	d.data = d.data[n:]
+	return data
+}
+
+// Varints appends the values of the current repeated varint field, which
+// may be packed or not, to vs.
+func (d *Decoder) Varints(vs []uint64) []uint64 {
+	if d.wireType != Bytes {
+		v := d.Varint()
+		if d.err != nil {
+			return vs
+		}
+		return append(vs, v)
+	}
+	p := NewDecoder(d.Bytes())
+	for d.err == nil && p.err == nil && len(p.data) > 0 {
+		vs = append(vs, p.Varint())
+	}
+	if p.err != nil {
+		d.err = p.err
+		return vs[:len(vs)-1]
+	}
+	return vs
+}
+
+// Skip skips the value of the current field.
+func (d *Decoder) Skip() {
+	switch d.wireType {
+	case Varint:
+		d.Varint()
+	case Fixed64:
+		d.fixed(8)
+	case Bytes:
+		d.Bytes()
+	case Fixed32:
+		d.fixed(4)
+	default:
+		d.err = ErrInvalid
+	}
+}
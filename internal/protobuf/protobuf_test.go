@@ -0,0 +1,90 @@
+package protobuf
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestZigzag(t *testing.T) {
+	for _, tc := range []struct {
+		v    int64
+		want uint64
+	}{
+		{v: 0, want: 0},
+		{v: -1, want: 1},
+		{v: 1, want: 2},
+		{v: -2, want: 3},
+		{v: math.MaxInt64, want: math.MaxUint64 - 1},
+		{v: math.MinInt64, want: math.MaxUint64},
+	} {
+		if got := Zigzag(tc.v); got != tc.want {
+			t.Errorf("Zigzag(%d) == %d, want %d", tc.v, got, tc.want)
+		}
+		if got := Unzigzag(tc.want); got != tc.v {
+			t.Errorf("Unzigzag(%d) == %d, want %d", tc.want, got, tc.v)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	var data []byte
+	data = AppendVarintField(data, 1, 300)
+	data = AppendBytesField(data, 2, []byte("go-geom"))
+	data = AppendFixed32Field(data, 3, math.Float32bits(1.5))
+	data = AppendFixed64Field(data, 4, math.Float64bits(-2.5))
+	data = AppendPackedField(data, 5, []uint64{1, 150, 1 << 40})
+	data = AppendVarintField(data, 5, 7)
+	data = AppendVarintField(data, 6, 1)
+
+	d := NewDecoder(data)
+	var varints []uint64
+	for d.Next() {
+		switch d.Field() {
+		case 1:
+			if got := d.Varint(); got != 300 {
+				t.Errorf("d.Varint() == %d, want 300", got)
+			}
+		case 2:
+			if got := string(d.Bytes()); got != "go-geom" {
+				t.Errorf("d.Bytes() == %q, want \"go-geom\"", got)
+			}
+		case 3:
+			if got := math.Float32frombits(d.Fixed32()); got != 1.5 {
+				t.Errorf("d.Fixed32() == %v, want 1.5", got)
+			}
+		case 4:
+			if got := math.Float64frombits(d.Fixed64()); got != -2.5 {
+				t.Errorf("d.Fixed64() == %v, want -2.5", got)
+			}
+		case 5:
+			varints = d.Varints(varints)
+		default:
+			d.Skip()
+		}
+	}
+	if err := d.Err(); err != nil {
+		t.Fatalf("d.Err() == %v, want <nil>", err)
+	}
+	if want := []uint64{1, 150, 1 << 40, 7}; !reflect.DeepEqual(varints, want) {
+		t.Errorf("d.Varints(...) == %v, want %v", varints, want)
+	}
+}
+
+func TestDecoderErrors(t *testing.T) {
+	for _, data := range [][]byte{
+		{0x08},
+		{0x08, 0x80},
+		{0x12, 0x05, 0x00},
+		{0x1d, 0x00, 0x00},
+		{0x0b},
+	} {
+		d := NewDecoder(data)
+		for d.Next() {
+			d.Skip()
+		}
+		if err := d.Err(); err != ErrInvalid {
+			t.Errorf("decoding %v: d.Err() == %v, want %v", data, err, ErrInvalid)
+		}
+	}
+}
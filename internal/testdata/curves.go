@@ -0,0 +1,43 @@
+package testdata
+
+import "github.com/twpayne/go-geom"
+
+// CurvesAndSurfaces returns a geometry of each curve and surface type with
+// layout. Ordinates other than X and Y are derived from X and Y, so that
+// shared end points are equal in every layout.
+func CurvesAndSurfaces(layout geom.Layout) []geom.T {
+	c := func(x, y float64) geom.Coord {
+		coord := geom.Coord{x, y}
+		for i := 2; i < layout.Stride(); i++ {
+			coord = append(coord, 10*float64(i)+x-y)
+		}
+		return coord
+	}
+	triangle := func(coords ...geom.Coord) *geom.Triangle {
+		return geom.NewTriangle(layout).MustSetCoords([][]geom.Coord{coords})
+	}
+	return []geom.T{
+		geom.NewCircularString(layout).MustSetCoords([]geom.Coord{c(0, 0), c(1, 1), c(2, 0)}),
+		geom.NewCompoundCurve(layout).MustPush(
+			geom.NewCircularString(layout).MustSetCoords([]geom.Coord{c(0, 0), c(1, 1), c(2, 0)}),
+			geom.NewLineString(layout).MustSetCoords([]geom.Coord{c(2, 0), c(3, 0), c(3, 1)}),
+		),
+		geom.NewCurvePolygon(layout).MustPush(
+			geom.NewCompoundCurve(layout).MustPush(
+				geom.NewCircularString(layout).MustSetCoords([]geom.Coord{c(0, 0), c(2, 2), c(4, 0)}),
+				geom.NewLineString(layout).MustSetCoords([]geom.Coord{c(4, 0), c(0, 0)}),
+			),
+			geom.NewCircularString(layout).MustSetCoords([]geom.Coord{c(1, 0.5), c(1.5, 1), c(1, 0.5)}),
+			geom.NewLineString(layout).MustSetCoords([]geom.Coord{c(3, 0.2), c(3.2, 0.2), c(3, 0.4), c(3, 0.2)}),
+		),
+		geom.NewPolyhedralSurface(layout).MustSetCoords([][][]geom.Coord{
+			{{c(0, 0), c(1, 0), c(1, 1), c(0, 1), c(0, 0)}},
+			{{c(1, 0), c(2, 0), c(2, 1), c(1, 1), c(1, 0)}},
+		}),
+		geom.NewTIN(layout).MustSetCoords([][][]geom.Coord{
+			{{c(0, 0), c(1, 0), c(0, 1), c(0, 0)}},
+			{{c(1, 0), c(1, 1), c(0, 1), c(1, 0)}},
+		}),
+		triangle(c(0, 0), c(1, 0), c(0, 1), c(0, 0)),
+	}
+}
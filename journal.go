@@ -0,0 +1,271 @@
+package geom
+
+// A Journal edits the vertices of a geometry and records each edit so that it
+// can be undone and redone. Each edit records only the coordinates that it
+// changes, so the cost of an edit does not depend on the size of the
+// geometry.
+//
+// Vertices are identified by paths: [i] is the ith vertex of a LineString or
+// LinearRing, [i, j] is the jth vertex of the ith LinearRing of a Polygon or
+// of the ith LineString of a MultiLineString, and [i, j, k] is the kth vertex
+// of the jth LinearRing of the ith Polygon of a MultiPolygon. The geometry
+// must not be modified other than through the Journal while the Journal is in
+// use.
+type Journal struct {
+	g     T
+	g0    *geom0
+	g2    *geom2
+	g3    *geom3
+	ring  bool
+	edits []journalEdit
+	n     int // number of edits that are currently applied
+}
+
+// A journalEdit is a sequence of splices to the flat coordinates of a line or
+// ring, which change the length of the line or ring by delta.
+type journalEdit struct {
+	part    []int
+	splices []splice
+	delta   int
+}
+
+// A splice replaces the flat coordinates old at index at with new.
+type splice struct {
+	at  int
+	old []float64
+	new []float64
+}
+
+// NewJournal returns a new Journal that edits g, which must be a LineString,
+// LinearRing, Polygon, MultiLineString, or MultiPolygon. Other geometries,
+// including curves and surfaces, return an ErrUnsupportedType.
+func NewJournal(g T) (*Journal, error) {
+	j := &Journal{g: g}
+	switch g := g.(type) {
+	case *LineString:
+		j.g0 = &g.geom0
+	case *LinearRing:
+		j.g0 = &g.geom0
+		j.ring = true
+	case *Polygon:
+		j.g0, j.g2 = &g.geom0, &g.geom2
+		j.ring = true
+	case *MultiLineString:
+		j.g0, j.g2 = &g.geom0, &g.geom2
+	case *MultiPolygon:
+		j.g0, j.g3 = &g.geom0, &g.geom3
+		j.ring = true
+	default:
+		return nil, ErrUnsupportedType{Value: g}
+	}
+	return j, nil
+}
+
+// CanRedo returns true if there is an undone edit that can be redone.
+func (j *Journal) CanRedo() bool {
+	return j.n < len(j.edits)
+}
+
+// CanUndo returns true if there is an edit that can be undone.
+func (j *Journal) CanUndo() bool {
+	return j.n > 0
+}
+
+// DeleteVertex deletes the vertex at path, as described for the DeleteVertex
+// method of the geometry's type.
+func (j *Journal) DeleteVertex(path []int) error {
+	part, i := splitVertexPath(path)
+	offset, end := j.lineRange(part)
+	stride := j.g0.stride
+	n := (end - offset) / stride
+	checkVertexIndex(i, n)
+	if j.ring && i == n-1 {
+		i = 0
+	}
+	at := offset + i*stride
+	e := journalEdit{
+		part: part,
+		splices: []splice{
+			{at: at, old: copyFlatCoords(j.g0.flatCoords[at : at+stride])},
+		},
+		delta: -stride,
+	}
+	if j.ring && i == 0 && n > 1 {
+		e.splices = append(e.splices, splice{
+			at:  end - 2*stride,
+			old: copyFlatCoords(j.g0.flatCoords[end-stride : end]),
+			new: copyFlatCoords(j.g0.flatCoords[offset+stride : offset+2*stride]),
+		})
+	}
+	flatCoords, err := deleteVertex(j.g0.flatCoords, offset, end, stride, path[len(path)-1], j.ring)
+	if err != nil {
+		return err
+	}
+	j.g0.flatCoords = flatCoords
+	j.shiftEnds(part, e.delta)
+	j.record(e)
+	return nil
+}
+
+// Geom returns the geometry edited by j.
+func (j *Journal) Geom() T {
+	return j.g
+}
+
+// InsertVertex inserts c before the vertex at path, as described for the
+// InsertVertex method of the geometry's type.
+func (j *Journal) InsertVertex(path []int, c Coord) error {
+	part, i := splitVertexPath(path)
+	offset, end := j.lineRange(part)
+	stride := j.g0.stride
+	n := (end - offset) / stride
+	e := journalEdit{
+		part: part,
+	}
+	switch at := offset + i*stride; {
+	case j.ring && n == 0:
+		e.splices = []splice{
+			{at: at, new: append(c.Clone(), c...)},
+		}
+	case j.ring && i == 0:
+		e.splices = []splice{
+			{at: at, new: c.Clone()},
+			{at: end, old: copyFlatCoords(j.g0.flatCoords[offset : offset+stride]), new: c.Clone()},
+		}
+	default:
+		e.splices = []splice{
+			{at: at, new: c.Clone()},
+		}
+	}
+	flatCoords, err := insertVertex(j.g0.flatCoords, offset, end, stride, i, c, j.ring)
+	if err != nil {
+		return err
+	}
+	e.delta = len(flatCoords) - len(j.g0.flatCoords)
+	j.g0.flatCoords = flatCoords
+	j.shiftEnds(part, e.delta)
+	j.record(e)
+	return nil
+}
+
+// MoveVertex sets the vertex at path to c, as described for the MoveVertex
+// method of the geometry's type.
+func (j *Journal) MoveVertex(path []int, c Coord) error {
+	part, i := splitVertexPath(path)
+	offset, end := j.lineRange(part)
+	stride := j.g0.stride
+	n := (end - offset) / stride
+	checkVertexIndex(i, n)
+	ats := []int{offset + i*stride}
+	if j.ring && (i == 0 || i == n-1) {
+		ats = []int{offset, end - stride}
+	}
+	e := journalEdit{
+		part: part,
+	}
+	for _, at := range ats {
+		e.splices = append(e.splices, splice{
+			at:  at,
+			old: copyFlatCoords(j.g0.flatCoords[at : at+stride]),
+			new: c.Clone(),
+		})
+	}
+	if err := moveVertex(j.g0.flatCoords, offset, end, stride, i, c, j.ring); err != nil {
+		return err
+	}
+	j.record(e)
+	return nil
+}
+
+// Redo redoes the most recently undone edit. It returns false if there is no
+// edit to redo.
+func (j *Journal) Redo() bool {
+	if !j.CanRedo() {
+		return false
+	}
+	e := j.edits[j.n]
+	for _, s := range e.splices {
+		j.g0.flatCoords = s.apply(j.g0.flatCoords)
+	}
+	j.shiftEnds(e.part, e.delta)
+	j.n++
+	return true
+}
+
+// Undo undoes the most recent edit. It returns false if there is no edit to
+// undo.
+func (j *Journal) Undo() bool {
+	if !j.CanUndo() {
+		return false
+	}
+	j.n--
+	e := j.edits[j.n]
+	for k := len(e.splices) - 1; k >= 0; k-- {
+		j.g0.flatCoords = e.splices[k].inverse().apply(j.g0.flatCoords)
+	}
+	j.shiftEnds(e.part, -e.delta)
+	return true
+}
+
+// lineRange returns the offset and end of the line or ring identified by
+// part.
+func (j *Journal) lineRange(part []int) (int, int) {
+	switch {
+	case j.g2 != nil && len(part) == 1:
+		return j.g2.offset(part[0]), j.g2.ends[part[0]]
+	case j.g3 != nil && len(part) == 2:
+		return j.g3.ringRange(part[0], part[1])
+	case j.g2 == nil && j.g3 == nil && len(part) == 0:
+		return 0, len(j.g0.flatCoords)
+	default:
+		panic("geom: invalid vertex path")
+	}
+}
+
+// record records e, discarding any undone edits.
+func (j *Journal) record(e journalEdit) {
+	j.edits = append(j.edits[:j.n], e)
+	j.n++
+}
+
+// shiftEnds adds delta to the ends from the line or ring identified by part.
+func (j *Journal) shiftEnds(part []int, delta int) {
+	switch {
+	case j.g2 != nil:
+		shiftEnds(j.g2.ends, part[0], delta)
+	case j.g3 != nil:
+		j.g3.shiftEndss(part[0], part[1], delta)
+	}
+}
+
+// apply applies s to flatCoords.
+func (s splice) apply(flatCoords []float64) []float64 {
+	switch d := len(s.new) - len(s.old); {
+	case d > 0:
+		flatCoords = append(flatCoords, s.new[:d]...)
+		copy(flatCoords[s.at+len(s.new):], flatCoords[s.at+len(s.old):len(flatCoords)-d])
+	case d < 0:
+		flatCoords = append(flatCoords[:s.at+len(s.new)], flatCoords[s.at+len(s.old):]...)
+	}
+	copy(flatCoords[s.at:], s.new)
+	return flatCoords
+}
+
+// inverse returns the splice that undoes s.
+func (s splice) inverse() splice {
+	return splice{at: s.at, old: s.new, new: s.old}
+}
+
+// copyFlatCoords returns a copy of flatCoords.
+func copyFlatCoords(flatCoords []float64) []float64 {
+	return append([]float64(nil), flatCoords...)
+}
+
+// splitVertexPath splits path into the path of a line or ring and the index
+// of a vertex in it.
+func splitVertexPath(path []int) ([]int, int) {
+	if len(path) == 0 {
+		panic("geom: invalid vertex path")
+	}
+	return append([]int(nil), path[:len(path)-1]...), path[len(path)-1]
+}
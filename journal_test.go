@@ -0,0 +1,173 @@
+package geom
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestJournal(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		g    T
+	}{
+		{
+			name: "linestring",
+			g:    NewLineStringFlat(XY, []float64{0, 0, 1, 1, 2, 2}),
+		},
+		{
+			name: "linearring",
+			g:    NewLinearRingFlat(XYZ, []float64{0, 0, 0, 1, 0, 0, 1, 1, 0, 0, 0, 0}),
+		},
+		{
+			name: "polygon",
+			g: NewPolygon(XY).MustSetCoords([][]Coord{
+				{{0, 0}, {10, 0}, {10, 10}, {0, 0}},
+				{{1, 1}, {2, 1}, {2, 2}, {1, 1}},
+			}),
+		},
+		{
+			name: "multilinestring",
+			g:    NewMultiLineStringFlat(XYM, []float64{0, 0, 0, 1, 1, 1, 2, 2, 2, 3, 3, 3}, []int{6, 12}),
+		},
+		{
+			name: "multipolygon",
+			g: NewMultiPolygon(XY).MustSetCoords([][][]Coord{
+				{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}},
+				{},
+				{{{2, 2}, {3, 2}, {3, 3}, {2, 2}}, {{2.1, 2.1}, {2.2, 2.1}, {2.2, 2.2}, {2.1, 2.1}}},
+			}),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := rand.New(rand.NewSource(0))
+			j, err := NewJournal(tc.g)
+			if err != nil {
+				t.Fatal(err)
+			}
+			states := []T{cloneT(tc.g)}
+			for len(states) < 100 {
+				path := randomVertexPath(r, tc.g)
+				c := make(Coord, tc.g.Stride())
+				for i := range c {
+					c[i] = float64(r.Intn(100))
+				}
+				switch r.Intn(3) {
+				case 0:
+					err = j.DeleteVertex(path)
+				case 1:
+					path[len(path)-1] = r.Intn(path[len(path)-1] + 1)
+					err = j.InsertVertex(path, c)
+				case 2:
+					err = j.MoveVertex(path, c)
+				}
+				if err != nil {
+					continue
+				}
+				states = append(states, cloneT(tc.g))
+			}
+			for i := len(states) - 2; i >= 0; i-- {
+				if !j.Undo() {
+					t.Fatalf("j.Undo() == false, want true")
+				}
+				if !reflect.DeepEqual(cloneT(tc.g), states[i]) {
+					t.Fatalf("after undo, got %v, want %v", tc.g, states[i])
+				}
+			}
+			if j.Undo() {
+				t.Errorf("j.Undo() == true, want false")
+			}
+			for i := 1; i < len(states); i++ {
+				if !j.Redo() {
+					t.Fatalf("j.Redo() == false, want true")
+				}
+				if !reflect.DeepEqual(cloneT(tc.g), states[i]) {
+					t.Fatalf("after redo, got %v, want %v", tc.g, states[i])
+				}
+			}
+			if j.Redo() {
+				t.Errorf("j.Redo() == true, want false")
+			}
+		})
+	}
+}
+
+func TestJournalDiscardsRedo(t *testing.T) {
+	g := NewLineStringFlat(XY, []float64{0, 0, 1, 1})
+	j, err := NewJournal(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := j.MoveVertex([]int{0}, Coord{2, 2}); err != nil {
+		t.Fatal(err)
+	}
+	j.Undo()
+	if err := j.InsertVertex([]int{2}, Coord{3, 3}); err != nil {
+		t.Fatal(err)
+	}
+	if j.CanRedo() {
+		t.Errorf("j.CanRedo() == true, want false")
+	}
+	j.Undo()
+	if want := []float64{0, 0, 1, 1}; !reflect.DeepEqual(g.FlatCoords(), want) {
+		t.Errorf("g.FlatCoords() == %v, want %v", g.FlatCoords(), want)
+	}
+	if _, err := NewJournal(NewPoint(XY)); err == nil {
+		t.Errorf("NewJournal(NewPoint(XY)) == _, <nil>, want _, !<nil>")
+	}
+}
+
+func TestJournalUnsupportedTypes(t *testing.T) {
+	for _, g := range []T{
+		NewCircularStringFlat(XY, []float64{0, 0, 1, 1, 2, 0}),
+		NewCompoundCurve(XY).MustPush(NewLineStringFlat(XY, []float64{0, 0, 1, 1})),
+		NewCurvePolygon(XY).MustPush(NewCircularStringFlat(XY, []float64{0, 0, 1, 1, 0, 0})),
+		NewTriangleFlat(XY, []float64{0, 0, 1, 0, 0, 1, 0, 0}, []int{8}),
+		NewPolyhedralSurfaceFlat(XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}, [][]int{{8}}),
+		NewTINFlat(XY, []float64{0, 0, 1, 0, 0, 1, 0, 0}, [][]int{{8}}),
+	} {
+		if _, err := NewJournal(g); err != (ErrUnsupportedType{Value: g}) {
+			t.Errorf("NewJournal(%T) == _, %v, want _, %v", g, err, ErrUnsupportedType{Value: g})
+		}
+	}
+}
+
+func cloneT(g T) T {
+	switch g := g.(type) {
+	case *LineString:
+		return g.Clone()
+	case *LinearRing:
+		return g.Clone()
+	case *Polygon:
+		return g.Clone()
+	case *MultiLineString:
+		return g.Clone()
+	case *MultiPolygon:
+		return g.Clone()
+	default:
+		panic(g)
+	}
+}
+
+// randomVertexPath returns the path of a random vertex of g.
+func randomVertexPath(r *rand.Rand, g T) []int {
+	vertex := func(offset, end int) int {
+		return r.Intn((end - offset) / g.Stride())
+	}
+	switch g := g.(type) {
+	case *LineString, *LinearRing:
+		return []int{vertex(0, len(g.FlatCoords()))}
+	case *Polygon:
+		i := r.Intn(len(g.ends))
+		return []int{i, vertex(g.offset(i), g.ends[i])}
+	case *MultiLineString:
+		i := r.Intn(len(g.ends))
+		return []int{i, vertex(g.offset(i), g.ends[i])}
+	case *MultiPolygon:
+		i := []int{0, 2}[r.Intn(2)]
+		j := r.Intn(len(g.endss[i]))
+		return []int{i, j, vertex(g.ringRange(i, j))}
+	default:
+		panic(g)
+	}
+}
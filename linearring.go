@@ -29,16 +29,44 @@ func (g *LinearRing) Clone() *LinearRing {
 	return deriveCloneLinearRing(g)
 }
 
+// DeleteVertex deletes the ith vertex, keeping the ring closed. It returns an
+// ErrInvalidVertex if fewer than four coordinates would remain.
+func (g *LinearRing) DeleteVertex(i int) error {
+	flatCoords, err := deleteVertex(g.flatCoords, 0, len(g.flatCoords), g.stride, i, true)
+	if err != nil {
+		return err
+	}
+	g.flatCoords = flatCoords
+	return nil
+}
+
 // Empty returns false.
 func (g *LinearRing) Empty() bool {
 	return false
 }
 
+// InsertVertex inserts c before the ith vertex, keeping the ring closed.
+// Inserting before the closing vertex adds c as the last vertex of the ring.
+func (g *LinearRing) InsertVertex(i int, c Coord) error {
+	flatCoords, err := insertVertex(g.flatCoords, 0, len(g.flatCoords), g.stride, i, c, true)
+	if err != nil {
+		return err
+	}
+	g.flatCoords = flatCoords
+	return nil
+}
+
 // Length returns the length of the perimeter.
 func (g *LinearRing) Length() float64 {
 	return length1(g.flatCoords, 0, len(g.flatCoords), g.stride)
 }
 
+// MoveVertex sets the ith vertex to c. Moving the first or closing vertex
+// moves both.
+func (g *LinearRing) MoveVertex(i int, c Coord) error {
+	return moveVertex(g.flatCoords, 0, len(g.flatCoords), g.stride, i, c, true)
+}
+
 // MustSetCoords sets the coordinates and panics if there is any error.
 func (g *LinearRing) MustSetCoords(coords []Coord) *LinearRing {
 	Must(g.SetCoords(coords))
@@ -31,11 +31,33 @@ func (g *LineString) Clone() *LineString {
 	return deriveCloneLineString(g)
 }
 
+// DeleteVertex deletes the ith vertex of g. It returns an ErrInvalidVertex if
+// g would be left with fewer than two coordinates.
+func (g *LineString) DeleteVertex(i int) error {
+	flatCoords, err := deleteVertex(g.flatCoords, 0, len(g.flatCoords), g.stride, i, false)
+	if err != nil {
+		return err
+	}
+	g.flatCoords = flatCoords
+	return nil
+}
+
 // Empty returns true if the geometry has no coordinate.
 func (g *LineString) Empty() bool {
 	return len(g.FlatCoords()) == 0
 }
 
+// InsertVertex inserts c before the ith vertex of g, or appends c if i is the
+// number of coordinates in g.
+func (g *LineString) InsertVertex(i int, c Coord) error {
+	flatCoords, err := insertVertex(g.flatCoords, 0, len(g.flatCoords), g.stride, i, c, false)
+	if err != nil {
+		return err
+	}
+	g.flatCoords = flatCoords
+	return nil
+}
+
 // Interpolate returns the index and delta of val in dimension dim.
 func (g *LineString) Interpolate(val float64, dim int) (int, float64) {
 	n := len(g.flatCoords)
@@ -72,6 +94,11 @@ func (g *LineString) Length() float64 {
 	return length1(g.flatCoords, 0, len(g.flatCoords), g.stride)
 }
 
+// MoveVertex sets the ith vertex of g to c.
+func (g *LineString) MoveVertex(i int, c Coord) error {
+	return moveVertex(g.flatCoords, 0, len(g.flatCoords), g.stride, i, c, false)
+}
+
 // MustSetCoords is like SetCoords but it panics on any error.
 func (g *LineString) MustSetCoords(coords []Coord) *LineString {
 	Must(g.SetCoords(coords))
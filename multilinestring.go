@@ -30,11 +30,23 @@ func (g *MultiLineString) Clone() *MultiLineString {
 	return deriveCloneMultiLineString(g)
 }
 
+// DeleteVertex deletes the jth vertex of the ith LineString. It returns an
+// ErrInvalidVertex if fewer than two coordinates would remain.
+func (g *MultiLineString) DeleteVertex(i, j int) error {
+	return g.deleteVertex(i, j, false)
+}
+
 // Empty returns true if the collection is empty.
 func (g *MultiLineString) Empty() bool {
 	return g.NumLineStrings() == 0
 }
 
+// InsertVertex inserts c before the jth vertex of the ith LineString, or
+// appends c if j is the number of coordinates in the LineString.
+func (g *MultiLineString) InsertVertex(i, j int, c Coord) error {
+	return g.insertVertex(i, j, c, false)
+}
+
 // Length returns the sum of the length of the LineStrings.
 func (g *MultiLineString) Length() float64 {
 	return length2(g.flatCoords, 0, g.ends, g.stride)
@@ -46,7 +58,17 @@ func (g *MultiLineString) LineString(i int) *LineString {
 	if i > 0 {
 		offset = g.ends[i-1]
 	}
-	return NewLineStringFlat(g.layout, g.flatCoords[offset:g.ends[i]])
+	return NewLineStringFlat(g.layout, g.flatCoords[offset:g.ends[i]]).SetSRID(g.srid)
+}
+
+// LineStringLengths returns the length of each LineString.
+func (g *MultiLineString) LineStringLengths() []float64 {
+	return lengths2(g.flatCoords, 0, g.ends, g.stride)
+}
+
+// MoveVertex sets the jth vertex of the ith LineString to c.
+func (g *MultiLineString) MoveVertex(i, j int, c Coord) error {
+	return moveVertex(g.flatCoords, g.offset(i), g.ends[i], g.stride, j, c, false)
 }
 
 // MustSetCoords sets the coordinates and panics on any error.
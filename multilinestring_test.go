@@ -118,3 +118,14 @@ func TestMultiLineStringStrideMismatch(t *testing.T) {
 		}
 	}
 }
+
+func TestMultiLineStringLineStringLengths(t *testing.T) {
+	mls := NewMultiLineString(XYZ).MustSetCoords([][]Coord{
+		{{0, 0, 0}, {3, 4, 100}},
+		{},
+		{{0, 0, 0}, {1, 0, 0}, {1, 1, 0}},
+	})
+	if got, want := mls.LineStringLengths(), []float64{5, 0, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("mls.LineStringLengths() == %v, want %v", got, want)
+	}
+}
@@ -66,7 +66,7 @@ func (g *MultiPoint) NumPoints() int {
 
 // Point returns the ith Point.
 func (g *MultiPoint) Point(i int) *Point {
-	return NewPointFlat(g.layout, g.Coord(i))
+	return NewPointFlat(g.layout, g.Coord(i)).SetSRID(g.srid)
 }
 
 // Push appends a point.
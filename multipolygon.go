@@ -30,16 +30,36 @@ func (g *MultiPolygon) Clone() *MultiPolygon {
 	return deriveCloneMultiPolygon(g)
 }
 
+// DeleteVertex deletes the kth vertex of the jth LinearRing of the ith
+// Polygon, keeping the ring closed. It returns an ErrInvalidVertex if fewer
+// than four coordinates would remain.
+func (g *MultiPolygon) DeleteVertex(i, j, k int) error {
+	return g.deleteVertex(i, j, k)
+}
+
 // Empty returns true if the collection is empty.
 func (g *MultiPolygon) Empty() bool {
 	return g.NumPolygons() == 0
 }
 
+// InsertVertex inserts c before the kth vertex of the jth LinearRing of the
+// ith Polygon, keeping the ring closed.
+func (g *MultiPolygon) InsertVertex(i, j, k int, c Coord) error {
+	return g.insertVertex(i, j, k, c)
+}
+
 // Length returns the sum of the perimeters of the Polygons.
 func (g *MultiPolygon) Length() float64 {
 	return length3(g.flatCoords, 0, g.endss, g.stride)
 }
 
+// MoveVertex sets the kth vertex of the jth LinearRing of the ith Polygon to
+// c, keeping the ring closed.
+func (g *MultiPolygon) MoveVertex(i, j, k int, c Coord) error {
+	offset, end := g.ringRange(i, j)
+	return moveVertex(g.flatCoords, offset, end, g.stride, k, c, true)
+}
+
 // MustSetCoords sets the coordinates and panics on any error.
 func (g *MultiPolygon) MustSetCoords(coords [][][]Coord) *MultiPolygon {
 	Must(g.SetCoords(coords))
@@ -66,7 +86,34 @@ func (g *MultiPolygon) Polygon(i int) *Polygon {
 			ends[j] = end - offset
 		}
 	}
-	return NewPolygonFlat(g.layout, g.flatCoords[offset:g.endss[i][len(g.endss[i])-1]], ends)
+	return NewPolygonFlat(g.layout, g.flatCoords[offset:g.endss[i][len(g.endss[i])-1]], ends).SetSRID(g.srid)
+}
+
+// PolygonAreas returns the area of each Polygon, as returned by its Area
+// method.
+func (g *MultiPolygon) PolygonAreas() []float64 {
+	areas := make([]float64, len(g.endss))
+	offset := 0
+	for i, ends := range g.endss {
+		areas[i] = doubleArea2(g.flatCoords, offset, ends, g.stride) / 2
+		if len(ends) > 0 {
+			offset = ends[len(ends)-1]
+		}
+	}
+	return areas
+}
+
+// PolygonLengths returns the perimeter of each Polygon.
+func (g *MultiPolygon) PolygonLengths() []float64 {
+	lengths := make([]float64, len(g.endss))
+	offset := 0
+	for i, ends := range g.endss {
+		lengths[i] = length2(g.flatCoords, offset, ends, g.stride)
+		if len(ends) > 0 {
+			offset = ends[len(ends)-1]
+		}
+	}
+	return lengths
 }
 
 // Push appends a Polygon.
@@ -88,6 +135,20 @@ func (g *MultiPolygon) Push(p *Polygon) error {
 	return nil
 }
 
+// RingAreas returns the signed area of each LinearRing of each Polygon, which
+// is positive for counterclockwise rings and negative for clockwise rings.
+func (g *MultiPolygon) RingAreas() [][]float64 {
+	areass := make([][]float64, len(g.endss))
+	offset := 0
+	for i, ends := range g.endss {
+		areass[i] = ringAreas2(g.flatCoords, offset, ends, g.stride)
+		if len(ends) > 0 {
+			offset = ends[len(ends)-1]
+		}
+	}
+	return areass
+}
+
 // SetCoords sets the coordinates.
 func (g *MultiPolygon) SetCoords(coords [][][]Coord) (*MultiPolygon, error) {
 	if err := g.setCoords(coords); err != nil {
@@ -96,6 +157,22 @@ func (g *MultiPolygon) SetCoords(coords [][][]Coord) (*MultiPolygon, error) {
 	return g, nil
 }
 
+// SetCoordsChecked sets the coordinates like SetCoords, but first closes
+// any unclosed rings, and returns an ErrInvalidRing if a ring has fewer than
+// four coordinates or if a hole is wound the same way as the exterior ring of
+// its Polygon. coords are not modified, and g is not modified if an error is
+// returned.
+func (g *MultiPolygon) SetCoordsChecked(coords [][][]Coord) (*MultiPolygon, error) {
+	checkedCoords := make([][][]Coord, len(coords))
+	for i, polygonCoords := range coords {
+		var err error
+		if checkedCoords[i], err = checkRings(g.layout, polygonCoords, Path{i}); err != nil {
+			return nil, err
+		}
+	}
+	return g.SetCoords(checkedCoords)
+}
+
 // SetSRID sets the SRID of g.
 func (g *MultiPolygon) SetSRID(srid int) *MultiPolygon {
 	g.srid = srid
@@ -1,6 +1,7 @@
 package geom
 
 import (
+	"math"
 	"reflect"
 	"testing"
 )
@@ -118,3 +119,57 @@ func TestMultiPolygonStrideMismatch(t *testing.T) {
 		}
 	}
 }
+
+func TestMultiPolygonAreasAndLengths(t *testing.T) {
+	mp := NewMultiPolygon(XY).MustSetCoords([][][]Coord{
+		{
+			{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}},
+			{{1, 1}, {3, 1}, {3, 3}, {1, 3}, {1, 1}},
+		},
+		{},
+		{
+			{{20, 0}, {20, 1}, {21, 0}, {20, 0}},
+		},
+	})
+	if got, want := mp.PolygonAreas(), []float64{96, 0, -0.5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("mp.PolygonAreas() == %v, want %v", got, want)
+	}
+	if got, want := mp.PolygonLengths(), []float64{48, 0, 2 + math.Sqrt2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("mp.PolygonLengths() == %v, want %v", got, want)
+	}
+	if got, want := mp.RingAreas(), [][]float64{{100, 4}, {}, {-0.5}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("mp.RingAreas() == %v, want %v", got, want)
+	}
+}
+
+func TestMultiPolygonSetCoordsChecked(t *testing.T) {
+	mp, err := NewMultiPolygon(XYZ).SetCoordsChecked([][][]Coord{
+		{{{0, 0, 1}, {1, 0, 1}, {1, 1, 1}}},
+		{{{2, 2, 1}, {3, 2, 1}, {3, 3, 1}, {2, 2, 2}}},
+	})
+	if err != nil {
+		t.Fatalf("mp.SetCoordsChecked(...) == %v, %v, want ..., nil", mp, err)
+	}
+	want := [][][]Coord{
+		{{{0, 0, 1}, {1, 0, 1}, {1, 1, 1}, {0, 0, 1}}},
+		{{{2, 2, 1}, {3, 2, 1}, {3, 3, 1}, {2, 2, 2}, {2, 2, 1}}},
+	}
+	if !reflect.DeepEqual(mp.Coords(), want) {
+		t.Errorf("mp.Coords() == %v, want %v", mp.Coords(), want)
+	}
+
+	coords := [][][]Coord{
+		{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}},
+		{
+			{{0, 0}, {10, 0}, {10, 10}, {0, 0}},
+			{{1, 1}, {2, 1}, {2, 2}},
+		},
+	}
+	wantErr := ErrInvalidRing{Path: Path{1, 1}, Reason: "same orientation as exterior ring"}
+	if _, err := NewMultiPolygon(XY).SetCoordsChecked(coords); !reflect.DeepEqual(err, wantErr) {
+		t.Errorf("mp.SetCoordsChecked(%v) == ..., %v, want ..., %v", coords, err, wantErr)
+	}
+	if got, want := wantErr.Error(), "geom: invalid ring 1/1: same orientation as exterior ring"; got != want {
+		t.Errorf("wantErr.Error() == %q, want %q", got, want)
+	}
+}
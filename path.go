@@ -0,0 +1,448 @@
+package geom
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// A Path addresses a component of a geometry by its index at each level of
+// nesting, so that parts of geometries can be referenced and edited without
+// juggling indexes. For example, Path{2, 0, 17} addresses vertex 17 of ring 0
+// of polygon 2 of a MultiPolygon.
+//
+// The components of each type of geometry are:
+//
+//   - LineString, LinearRing: [vertex]
+//   - Polygon: [ring] or [ring, vertex]
+//   - MultiPoint: [point]
+//   - MultiLineString: [line] or [line, vertex]
+//   - MultiPolygon: [polygon], [polygon, ring], or [polygon, ring, vertex]
+//   - GeometryCollection: [geometry], followed by a path into that geometry
+//
+// Points have no components. Vertices are represented by Points. The empty
+// path addresses the geometry itself. Paths into curves and surfaces, whose
+// vertices are constrained by their arcs, segments, and faces, are not
+// supported and return an ErrUnsupportedType.
+type Path []int
+
+// An ErrInvalidPath is returned when a path does not address a component of
+// a geometry.
+type ErrInvalidPath struct {
+	Path   Path
+	Reason string
+}
+
+func (e ErrInvalidPath) Error() string {
+	return fmt.Sprintf("geom: invalid path %s: %s", e.Path, e.Reason)
+}
+
+// ParsePath parses a path in the format returned by Path.String.
+func ParsePath(s string) (Path, error) {
+	if s == "" {
+		return Path{}, nil
+	}
+	fields := strings.Split(s, "/")
+	p := make(Path, len(fields))
+	for i, field := range fields {
+		index, err := strconv.Atoi(field)
+		if err != nil || index < 0 {
+			return nil, fmt.Errorf("geom: invalid path %q", s)
+		}
+		p[i] = index
+	}
+	return p, nil
+}
+
+// String returns p as its indexes separated by slashes, for example
+// "2/0/17".
+func (p Path) String() string {
+	var sb strings.Builder
+	for i, index := range p {
+		if i > 0 {
+			sb.WriteByte('/')
+		}
+		sb.WriteString(strconv.Itoa(index))
+	}
+	return sb.String()
+}
+
+// Get returns the component of g at p. Members of GeometryCollections are
+// returned as is. Other components are returned as new geometries, with the
+// layout and SRID of g, that do not share coordinates with g.
+func (p Path) Get(g T) (T, error) {
+	g, q, err := p.descend(g)
+	if err != nil {
+		return nil, err
+	}
+	if len(q) == 0 {
+		return g, nil
+	}
+	if err := p.checkIndexes(g, q); err != nil {
+		return nil, err
+	}
+	switch g := g.(type) {
+	case *GeometryCollection:
+		return g.geoms[q[0]], nil
+	case *MultiPoint:
+		return NewPointFlat(g.layout, copyFloats(g.Coord(q[0]))).SetSRID(g.srid), nil
+	case *Polygon:
+		if len(q) == 1 {
+			offset, end := g.offset(q[0]), g.ends[q[0]]
+			return NewLinearRingFlat(g.layout, copyFloats(g.flatCoords[offset:end])).SetSRID(g.srid), nil
+		}
+	case *MultiLineString:
+		if len(q) == 1 {
+			offset, end := g.offset(q[0]), g.ends[q[0]]
+			return NewLineStringFlat(g.layout, copyFloats(g.flatCoords[offset:end])).SetSRID(g.srid), nil
+		}
+	case *MultiPolygon:
+		switch len(q) {
+		case 1:
+			offset, end := g.polygonRange(q[0])
+			ends := make([]int, len(g.endss[q[0]]))
+			for j, e := range g.endss[q[0]] {
+				ends[j] = e - offset
+			}
+			return NewPolygonFlat(g.layout, copyFloats(g.flatCoords[offset:end]), ends).SetSRID(g.srid), nil
+		case 2:
+			offset, end := g.ringRange(q[0], q[1])
+			return NewLinearRingFlat(g.layout, copyFloats(g.flatCoords[offset:end])).SetSRID(g.srid), nil
+		}
+	}
+	offset, _, _ := lineRange(g, q[:len(q)-1])
+	offset += q[len(q)-1] * g.Stride()
+	return NewPointFlat(g.Layout(), copyFloats(g.FlatCoords()[offset:offset+g.Stride()])).SetSRID(g.SRID()), nil
+}
+
+// Set replaces the component of g at p with v, which must be a geometry of
+// the type returned by Get with the layout of g. Setting a vertex of a ring
+// keeps the ring closed. The geometry itself cannot be replaced.
+func (p Path) Set(g, v T) error {
+	g, q, err := p.descend(g)
+	if err != nil {
+		return err
+	}
+	if len(q) == 0 {
+		return ErrInvalidPath{Path: p, Reason: "cannot replace the geometry itself"}
+	}
+	if err := p.checkIndexes(g, q); err != nil {
+		return err
+	}
+	if gc, ok := g.(*GeometryCollection); ok {
+		if v == nil {
+			return ErrUnsupportedType{Value: v}
+		}
+		gc.geoms[q[0]] = v
+		return nil
+	}
+	if v == nil {
+		return ErrUnsupportedType{Value: v}
+	}
+	if v.Layout() != g.Layout() {
+		return ErrLayoutMismatch{Got: v.Layout(), Want: g.Layout()}
+	}
+	switch g := g.(type) {
+	case *MultiPoint:
+		point, ok := v.(*Point)
+		if !ok {
+			return ErrUnsupportedType{Value: v}
+		}
+		if len(point.flatCoords) != g.stride {
+			return ErrStrideMismatch{Got: len(point.flatCoords), Want: g.stride}
+		}
+		copy(g.flatCoords[q[0]*g.stride:], point.flatCoords)
+		return nil
+	case *Polygon:
+		if len(q) == 1 {
+			ring, ok := v.(*LinearRing)
+			if !ok {
+				return ErrUnsupportedType{Value: v}
+			}
+			g.replaceLine(q[0], ring.flatCoords)
+			return nil
+		}
+	case *MultiLineString:
+		if len(q) == 1 {
+			line, ok := v.(*LineString)
+			if !ok {
+				return ErrUnsupportedType{Value: v}
+			}
+			g.replaceLine(q[0], line.flatCoords)
+			return nil
+		}
+	case *MultiPolygon:
+		switch len(q) {
+		case 1:
+			polygon, ok := v.(*Polygon)
+			if !ok {
+				return ErrUnsupportedType{Value: v}
+			}
+			g.replacePolygon(q[0], polygon.flatCoords, polygon.ends)
+			return nil
+		case 2:
+			ring, ok := v.(*LinearRing)
+			if !ok {
+				return ErrUnsupportedType{Value: v}
+			}
+			g.replaceRing(q[0], q[1], ring.flatCoords)
+			return nil
+		}
+	}
+	point, ok := v.(*Point)
+	if !ok {
+		return ErrUnsupportedType{Value: v}
+	}
+	offset, end, ring := lineRange(g, q[:len(q)-1])
+	return moveVertex(g.FlatCoords(), offset, end, g.Stride(), q[len(q)-1], point.flatCoords, ring)
+}
+
+// Delete deletes the component of g at p. Deleting a vertex returns an
+// ErrInvalidVertex if it would leave too few coordinates, as DeleteVertex
+// does. The exterior ring of a polygon with holes and the geometry itself
+// cannot be deleted.
+func (p Path) Delete(g T) error {
+	g, q, err := p.descend(g)
+	if err != nil {
+		return err
+	}
+	if len(q) == 0 {
+		return ErrInvalidPath{Path: p, Reason: "cannot delete the geometry itself"}
+	}
+	if err := p.checkIndexes(g, q); err != nil {
+		return err
+	}
+	switch g := g.(type) {
+	case *GeometryCollection:
+		g.geoms = append(g.geoms[:q[0]], g.geoms[q[0]+1:]...)
+		return nil
+	case *MultiPoint:
+		g.flatCoords, _ = replaceFlatCoords(g.flatCoords, q[0]*g.stride, (q[0]+1)*g.stride, nil)
+		return nil
+	case *LineString:
+		return g.DeleteVertex(q[0])
+	case *LinearRing:
+		return g.DeleteVertex(q[0])
+	case *Polygon:
+		if len(q) == 2 {
+			return g.DeleteVertex(q[0], q[1])
+		}
+		if q[0] == 0 && len(g.ends) > 1 {
+			return ErrInvalidPath{Path: p, Reason: "cannot delete the exterior ring of a polygon with holes"}
+		}
+		g.deleteLine(q[0])
+		return nil
+	case *MultiLineString:
+		if len(q) == 2 {
+			return g.DeleteVertex(q[0], q[1])
+		}
+		g.deleteLine(q[0])
+		return nil
+	case *MultiPolygon:
+		switch len(q) {
+		case 1:
+			g.deletePolygon(q[0])
+		case 2:
+			if q[1] == 0 && len(g.endss[q[0]]) > 1 {
+				return ErrInvalidPath{Path: p, Reason: "cannot delete the exterior ring of a polygon with holes"}
+			}
+			g.deleteRing(q[0], q[1])
+		default:
+			return g.DeleteVertex(q[0], q[1], q[2])
+		}
+		return nil
+	default:
+		return ErrUnsupportedType{Value: g}
+	}
+}
+
+// descend returns the geometry in g whose component is addressed by p,
+// descending into GeometryCollections, and the path to the component
+// relative to it.
+func (p Path) descend(g T) (T, Path, error) {
+	q := p
+	for {
+		gc, ok := g.(*GeometryCollection)
+		if !ok || len(q) <= 1 {
+			return g, q, nil
+		}
+		if q[0] < 0 || q[0] >= len(gc.geoms) {
+			return nil, nil, p.outOfRange(q[0], len(gc.geoms))
+		}
+		g, q = gc.geoms[q[0]], q[1:]
+	}
+}
+
+// checkIndexes returns an error if q, a path relative to g, does not address
+// a component of g.
+func (p Path) checkIndexes(g T, q Path) error {
+	var counts func(level int) int
+	switch g := g.(type) {
+	case *GeometryCollection:
+		counts = func(int) int { return len(g.geoms) }
+	case *MultiPoint:
+		counts = func(int) int { return g.NumCoords() }
+	case *LineString, *LinearRing:
+		counts = func(int) int { return len(g.FlatCoords()) / g.Stride() }
+	case *Polygon:
+		counts = func(level int) int {
+			if level == 0 {
+				return len(g.ends)
+			}
+			return (g.ends[q[0]] - g.offset(q[0])) / g.stride
+		}
+	case *MultiLineString:
+		counts = func(level int) int {
+			if level == 0 {
+				return len(g.ends)
+			}
+			return (g.ends[q[0]] - g.offset(q[0])) / g.stride
+		}
+	case *MultiPolygon:
+		counts = func(level int) int {
+			switch level {
+			case 0:
+				return len(g.endss)
+			case 1:
+				return len(g.endss[q[0]])
+			default:
+				offset, end := g.ringRange(q[0], q[1])
+				return (end - offset) / g.stride
+			}
+		}
+	case *Point:
+		return ErrInvalidPath{Path: p, Reason: "points have no components"}
+	default:
+		return ErrUnsupportedType{Value: g}
+	}
+	if depth := pathDepth(g); len(q) > depth {
+		return ErrInvalidPath{Path: p, Reason: fmt.Sprintf("%T has %d levels of components", g, depth)}
+	}
+	for level, index := range q {
+		if n := counts(level); index < 0 || index >= n {
+			return p.outOfRange(index, n)
+		}
+	}
+	return nil
+}
+
+func (p Path) outOfRange(index, n int) error {
+	return ErrInvalidPath{Path: p, Reason: fmt.Sprintf("index %d out of range [0:%d]", index, n)}
+}
+
+// pathDepth returns the maximum length of a path relative to g.
+func pathDepth(g T) int {
+	switch g.(type) {
+	case *Polygon, *MultiLineString:
+		return 2
+	case *MultiPolygon:
+		return 3
+	default:
+		return 1
+	}
+}
+
+// lineRange returns the offset and end of the line or ring of g addressed by
+// q, and whether it is a ring.
+func lineRange(g T, q Path) (int, int, bool) {
+	switch g := g.(type) {
+	case *Polygon:
+		return g.offset(q[0]), g.ends[q[0]], true
+	case *MultiLineString:
+		return g.offset(q[0]), g.ends[q[0]], false
+	case *MultiPolygon:
+		offset, end := g.ringRange(q[0], q[1])
+		return offset, end, true
+	case *LinearRing:
+		return 0, len(g.flatCoords), true
+	default:
+		return 0, len(g.FlatCoords()), false
+	}
+}
+
+// replaceLine replaces the ith line or ring of g with flatCoords.
+func (g *geom2) replaceLine(i int, flatCoords []float64) {
+	var delta int
+	g.flatCoords, delta = replaceFlatCoords(g.flatCoords, g.offset(i), g.ends[i], flatCoords)
+	shiftEnds(g.ends, i, delta)
+}
+
+// deleteLine deletes the ith line or ring of g.
+func (g *geom2) deleteLine(i int) {
+	var delta int
+	g.flatCoords, delta = replaceFlatCoords(g.flatCoords, g.offset(i), g.ends[i], nil)
+	g.ends = append(g.ends[:i], g.ends[i+1:]...)
+	shiftEnds(g.ends, i, delta)
+}
+
+// polygonRange returns the offset and end of the ith polygon of g.
+func (g *geom3) polygonRange(i int) (int, int) {
+	offset := 0
+	for k := i - 1; k >= 0; k-- {
+		if len(g.endss[k]) > 0 {
+			offset = g.endss[k][len(g.endss[k])-1]
+			break
+		}
+	}
+	if ends := g.endss[i]; len(ends) > 0 {
+		return offset, ends[len(ends)-1]
+	}
+	return offset, offset
+}
+
+// replacePolygon replaces the ith polygon of g with the polygon with
+// flatCoords and ends.
+func (g *geom3) replacePolygon(i int, flatCoords []float64, ends []int) {
+	offset, end := g.polygonRange(i)
+	var delta int
+	g.flatCoords, delta = replaceFlatCoords(g.flatCoords, offset, end, flatCoords)
+	g.endss[i] = make([]int, len(ends))
+	for j, e := range ends {
+		g.endss[i][j] = offset + e
+	}
+	for k := i + 1; k < len(g.endss); k++ {
+		shiftEnds(g.endss[k], 0, delta)
+	}
+}
+
+// deletePolygon deletes the ith polygon of g.
+func (g *geom3) deletePolygon(i int) {
+	offset, end := g.polygonRange(i)
+	var delta int
+	g.flatCoords, delta = replaceFlatCoords(g.flatCoords, offset, end, nil)
+	g.endss = append(g.endss[:i], g.endss[i+1:]...)
+	for k := i; k < len(g.endss); k++ {
+		shiftEnds(g.endss[k], 0, delta)
+	}
+}
+
+// replaceRing replaces the jth ring of the ith polygon of g with flatCoords.
+func (g *geom3) replaceRing(i, j int, flatCoords []float64) {
+	offset, end := g.ringRange(i, j)
+	var delta int
+	g.flatCoords, delta = replaceFlatCoords(g.flatCoords, offset, end, flatCoords)
+	g.shiftEndss(i, j, delta)
+}
+
+// deleteRing deletes the jth ring of the ith polygon of g.
+func (g *geom3) deleteRing(i, j int) {
+	offset, end := g.ringRange(i, j)
+	var delta int
+	g.flatCoords, delta = replaceFlatCoords(g.flatCoords, offset, end, nil)
+	g.endss[i] = append(g.endss[i][:j], g.endss[i][j+1:]...)
+	g.shiftEndss(i, j, delta)
+}
+
+// replaceFlatCoords returns a copy of flatCoords with flatCoords[offset:end]
+// replaced by replacement, and the change in length.
+func replaceFlatCoords(flatCoords []float64, offset, end int, replacement []float64) ([]float64, int) {
+	result := make([]float64, 0, len(flatCoords)-(end-offset)+len(replacement))
+	result = append(result, flatCoords[:offset]...)
+	result = append(result, replacement...)
+	result = append(result, flatCoords[end:]...)
+	return result, len(replacement) - (end - offset)
+}
+
+func copyFloats(fs []float64) []float64 {
+	return append([]float64(nil), fs...)
+}
@@ -0,0 +1,399 @@
+package geom
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPathString(t *testing.T) {
+	for _, tc := range []struct {
+		p Path
+		s string
+	}{
+		{p: Path{}, s: ""},
+		{p: Path{3}, s: "3"},
+		{p: Path{2, 0, 17}, s: "2/0/17"},
+	} {
+		if got := tc.p.String(); got != tc.s {
+			t.Errorf("%#v.String() == %q, want %q", tc.p, got, tc.s)
+		}
+		if got, err := ParsePath(tc.s); err != nil || !reflect.DeepEqual(got, tc.p) {
+			t.Errorf("ParsePath(%q) == %#v, %v, want %#v, <nil>", tc.s, got, err, tc.p)
+		}
+	}
+	for _, s := range []string{"/", "1/", "a", "1/-2"} {
+		if _, err := ParsePath(s); err == nil {
+			t.Errorf("ParsePath(%q) == _, <nil>, want an error", s)
+		}
+	}
+}
+
+func newPathTestMultiPolygon() *MultiPolygon {
+	return NewMultiPolygonFlat(XY, []float64{
+		0, 0, 4, 0, 4, 4, 0, 4, 0, 0,
+		1, 1, 1, 2, 2, 2, 1, 1,
+		10, 10, 11, 10, 11, 11, 10, 10,
+	}, [][]int{{10, 18}, {}, {26}}).SetSRID(4326)
+}
+
+func TestPathGet(t *testing.T) {
+	mp := newPathTestMultiPolygon()
+	point := NewPointFlat(XY, []float64{5, 6})
+	gc := NewGeometryCollection().MustPush(
+		point,
+		NewLineStringFlat(XYZ, []float64{1, 2, 3, 4, 5, 6}),
+		NewGeometryCollection().MustPush(mp),
+	)
+	for _, tc := range []struct {
+		g    T
+		p    Path
+		want T
+	}{
+		{g: mp, p: Path{}, want: mp},
+		{
+			g:    mp,
+			p:    Path{0},
+			want: NewPolygonFlat(XY, []float64{0, 0, 4, 0, 4, 4, 0, 4, 0, 0, 1, 1, 1, 2, 2, 2, 1, 1}, []int{10, 18}).SetSRID(4326),
+		},
+		{
+			g:    mp,
+			p:    Path{1},
+			want: NewPolygonFlat(XY, nil, []int{}).SetSRID(4326),
+		},
+		{
+			g:    mp,
+			p:    Path{2},
+			want: NewPolygonFlat(XY, []float64{10, 10, 11, 10, 11, 11, 10, 10}, []int{8}).SetSRID(4326),
+		},
+		{
+			g:    mp,
+			p:    Path{0, 1},
+			want: NewLinearRingFlat(XY, []float64{1, 1, 1, 2, 2, 2, 1, 1}).SetSRID(4326),
+		},
+		{
+			g:    mp,
+			p:    Path{2, 0, 1},
+			want: NewPointFlat(XY, []float64{11, 10}).SetSRID(4326),
+		},
+		{
+			g:    NewPolygonFlat(XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}, []int{8}),
+			p:    Path{0, 2},
+			want: NewPointFlat(XY, []float64{1, 1}),
+		},
+		{
+			g:    NewMultiLineStringFlat(XY, []float64{0, 0, 1, 1, 2, 2, 3, 3}, []int{4, 8}),
+			p:    Path{1},
+			want: NewLineStringFlat(XY, []float64{2, 2, 3, 3}),
+		},
+		{
+			g:    NewMultiPointFlat(XY, []float64{0, 0, 1, 1}),
+			p:    Path{1},
+			want: NewPointFlat(XY, []float64{1, 1}),
+		},
+		{
+			g:    NewLinearRingFlat(XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}),
+			p:    Path{3},
+			want: NewPointFlat(XY, []float64{0, 0}),
+		},
+		{g: gc, p: Path{0}, want: point},
+		{g: gc, p: Path{1, 1}, want: NewPointFlat(XYZ, []float64{4, 5, 6})},
+		{g: gc, p: Path{2, 0, 0, 0, 3}, want: NewPointFlat(XY, []float64{0, 4}).SetSRID(4326)},
+	} {
+		got, err := tc.p.Get(tc.g)
+		if err != nil {
+			t.Errorf("Path%v.Get(...) == _, %v, want _, <nil>", tc.p, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Path%v.Get(...) == %#v, want %#v", tc.p, got, tc.want)
+		}
+	}
+}
+
+func TestPathGetDoesNotShare(t *testing.T) {
+	mp := newPathTestMultiPolygon()
+	ring, err := Path{0, 1}.Get(mp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ring.FlatCoords()[0] = 100
+	if mp.FlatCoords()[10] != 1 {
+		t.Errorf("modifying the result of Get modified the geometry")
+	}
+}
+
+func TestPathErrors(t *testing.T) {
+	mp := newPathTestMultiPolygon()
+	gc := NewGeometryCollection().MustPush(NewPointFlat(XY, []float64{1, 2}))
+	for _, tc := range []struct {
+		g T
+		p Path
+	}{
+		{g: mp, p: Path{3}},
+		{g: mp, p: Path{-1}},
+		{g: mp, p: Path{1, 0}},
+		{g: mp, p: Path{0, 2}},
+		{g: mp, p: Path{0, 1, 4}},
+		{g: mp, p: Path{0, 0, 0, 0}},
+		{g: NewPointFlat(XY, []float64{1, 2}), p: Path{0}},
+		{g: gc, p: Path{1}},
+		{g: gc, p: Path{1, 0}},
+		{g: gc, p: Path{0, 0}},
+	} {
+		if _, err := tc.p.Get(tc.g); err == nil {
+			t.Errorf("Path%v.Get(...) == _, <nil>, want an error", tc.p)
+		} else if _, ok := err.(ErrInvalidPath); !ok {
+			t.Errorf("Path%v.Get(...) == _, %v, want an ErrInvalidPath", tc.p, err)
+		}
+	}
+	for _, g := range []T{
+		NewCircularStringFlat(XY, []float64{0, 0, 1, 1, 2, 0}),
+		NewCompoundCurve(XY).MustPush(NewLineStringFlat(XY, []float64{0, 0, 1, 1})),
+		NewCurvePolygon(XY).MustPush(NewCircularStringFlat(XY, []float64{0, 0, 1, 1, 0, 0})),
+		NewTriangleFlat(XY, []float64{0, 0, 1, 0, 0, 1, 0, 0}, []int{8}),
+		NewPolyhedralSurfaceFlat(XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}, [][]int{{8}}),
+		NewTINFlat(XY, []float64{0, 0, 1, 0, 0, 1, 0, 0}, [][]int{{8}}),
+	} {
+		want := ErrUnsupportedType{Value: g}
+		if _, err := (Path{0}).Get(g); err != want {
+			t.Errorf("Path{0}.Get(%T) == _, %v, want _, %v", g, err, want)
+		}
+		if err := (Path{0}).Set(g, NewPointFlat(XY, []float64{1, 2})); err != want {
+			t.Errorf("Path{0}.Set(%T, ...) == %v, want %v", g, err, want)
+		}
+		if err := (Path{0}).Delete(g); err != want {
+			t.Errorf("Path{0}.Delete(%T) == %v, want %v", g, err, want)
+		}
+	}
+}
+
+func TestPathSet(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		g    T
+		p    Path
+		v    T
+		want T
+	}{
+		{
+			name: "line_string_vertex",
+			g:    NewLineStringFlat(XY, []float64{0, 0, 1, 1}),
+			p:    Path{1},
+			v:    NewPointFlat(XY, []float64{2, 2}),
+			want: NewLineStringFlat(XY, []float64{0, 0, 2, 2}),
+		},
+		{
+			name: "ring_first_vertex",
+			g:    NewPolygonFlat(XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}, []int{8}),
+			p:    Path{0, 0},
+			v:    NewPointFlat(XY, []float64{0, 1}),
+			want: NewPolygonFlat(XY, []float64{0, 1, 1, 0, 1, 1, 0, 1}, []int{8}),
+		},
+		{
+			name: "multi_point_point",
+			g:    NewMultiPointFlat(XY, []float64{0, 0, 1, 1}),
+			p:    Path{0},
+			v:    NewPointFlat(XY, []float64{2, 2}),
+			want: NewMultiPointFlat(XY, []float64{2, 2, 1, 1}),
+		},
+		{
+			name: "multi_line_string_line",
+			g:    NewMultiLineStringFlat(XY, []float64{0, 0, 1, 1, 2, 2, 3, 3}, []int{4, 8}),
+			p:    Path{0},
+			v:    NewLineStringFlat(XY, []float64{5, 5, 6, 6, 7, 7}),
+			want: NewMultiLineStringFlat(XY, []float64{5, 5, 6, 6, 7, 7, 2, 2, 3, 3}, []int{6, 10}),
+		},
+		{
+			name: "multi_polygon_polygon",
+			g:    newPathTestMultiPolygon(),
+			p:    Path{0},
+			v:    NewPolygonFlat(XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}, []int{8}),
+			want: NewMultiPolygonFlat(XY, []float64{
+				0, 0, 1, 0, 1, 1, 0, 0,
+				10, 10, 11, 10, 11, 11, 10, 10,
+			}, [][]int{{8}, {}, {16}}).SetSRID(4326),
+		},
+		{
+			name: "multi_polygon_empty_polygon",
+			g:    newPathTestMultiPolygon(),
+			p:    Path{1},
+			v:    NewPolygonFlat(XY, []float64{5, 5, 6, 5, 6, 6, 5, 5}, []int{8}),
+			want: NewMultiPolygonFlat(XY, []float64{
+				0, 0, 4, 0, 4, 4, 0, 4, 0, 0,
+				1, 1, 1, 2, 2, 2, 1, 1,
+				5, 5, 6, 5, 6, 6, 5, 5,
+				10, 10, 11, 10, 11, 11, 10, 10,
+			}, [][]int{{10, 18}, {26}, {34}}).SetSRID(4326),
+		},
+		{
+			name: "multi_polygon_ring",
+			g:    newPathTestMultiPolygon(),
+			p:    Path{0, 1},
+			v:    NewLinearRingFlat(XY, []float64{1, 1, 1, 3, 3, 3, 3, 1, 1, 1}),
+			want: NewMultiPolygonFlat(XY, []float64{
+				0, 0, 4, 0, 4, 4, 0, 4, 0, 0,
+				1, 1, 1, 3, 3, 3, 3, 1, 1, 1,
+				10, 10, 11, 10, 11, 11, 10, 10,
+			}, [][]int{{10, 20}, {}, {28}}).SetSRID(4326),
+		},
+		{
+			name: "geometry_collection_member",
+			g:    NewGeometryCollection().MustPush(NewPointFlat(XY, []float64{1, 2})),
+			p:    Path{0},
+			v:    NewLineStringFlat(XYZ, []float64{1, 2, 3, 4, 5, 6}),
+			want: NewGeometryCollection().MustPush(NewLineStringFlat(XYZ, []float64{1, 2, 3, 4, 5, 6})),
+		},
+		{
+			name: "geometry_collection_nested",
+			g:    NewGeometryCollection().MustPush(NewPolygonFlat(XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}, []int{8})),
+			p:    Path{0, 0, 1},
+			v:    NewPointFlat(XY, []float64{2, 0}),
+			want: NewGeometryCollection().MustPush(NewPolygonFlat(XY, []float64{0, 0, 2, 0, 1, 1, 0, 0}, []int{8})),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.p.Set(tc.g, tc.v); err != nil {
+				t.Fatalf("Path%v.Set(...) == %v, want <nil>", tc.p, err)
+			}
+			if !reflect.DeepEqual(tc.g, tc.want) {
+				t.Errorf("Path%v.Set(...) gives %#v, want %#v", tc.p, tc.g, tc.want)
+			}
+		})
+	}
+}
+
+func TestPathSetErrors(t *testing.T) {
+	mp := newPathTestMultiPolygon()
+	for _, tc := range []struct {
+		p       Path
+		v       T
+		wantErr error
+	}{
+		{
+			p:       Path{},
+			v:       mp,
+			wantErr: ErrInvalidPath{Path: Path{}, Reason: "cannot replace the geometry itself"},
+		},
+		{
+			p:       Path{0},
+			v:       NewLinearRingFlat(XY, []float64{0, 0, 1, 0, 1, 1, 0, 0}),
+			wantErr: ErrUnsupportedType{Value: NewLinearRingFlat(XY, []float64{0, 0, 1, 0, 1, 1, 0, 0})},
+		},
+		{
+			p:       Path{0, 0, 0},
+			v:       NewPointFlat(XYZ, []float64{1, 2, 3}),
+			wantErr: ErrLayoutMismatch{Got: XYZ, Want: XY},
+		},
+	} {
+		if err := tc.p.Set(mp, tc.v); !reflect.DeepEqual(err, tc.wantErr) {
+			t.Errorf("Path%v.Set(...) == %v, want %v", tc.p, err, tc.wantErr)
+		}
+	}
+	if !reflect.DeepEqual(mp, newPathTestMultiPolygon()) {
+		t.Errorf("failed Set modified the geometry")
+	}
+}
+
+func TestPathDelete(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		g       T
+		p       Path
+		want    T
+		wantErr error
+	}{
+		{
+			name: "line_string_vertex",
+			g:    NewLineStringFlat(XY, []float64{0, 0, 1, 1, 2, 2}),
+			p:    Path{1},
+			want: NewLineStringFlat(XY, []float64{0, 0, 2, 2}),
+		},
+		{
+			name:    "line_string_too_few_vertices",
+			g:       NewLineStringFlat(XY, []float64{0, 0, 1, 1}),
+			p:       Path{1},
+			want:    NewLineStringFlat(XY, []float64{0, 0, 1, 1}),
+			wantErr: ErrInvalidVertex{Index: 1, Reason: "too few coordinates"},
+		},
+		{
+			name: "multi_point_point",
+			g:    NewMultiPointFlat(XY, []float64{0, 0, 1, 1, 2, 2}),
+			p:    Path{1},
+			want: NewMultiPointFlat(XY, []float64{0, 0, 2, 2}),
+		},
+		{
+			name: "polygon_hole",
+			g:    NewPolygonFlat(XY, []float64{0, 0, 4, 0, 4, 4, 0, 0, 1, 1, 2, 2, 2, 1, 1, 1}, []int{8, 16}),
+			p:    Path{1},
+			want: NewPolygonFlat(XY, []float64{0, 0, 4, 0, 4, 4, 0, 0}, []int{8}),
+		},
+		{
+			name:    "polygon_exterior_with_holes",
+			g:       NewPolygonFlat(XY, []float64{0, 0, 4, 0, 4, 4, 0, 0, 1, 1, 2, 2, 2, 1, 1, 1}, []int{8, 16}),
+			p:       Path{0},
+			want:    NewPolygonFlat(XY, []float64{0, 0, 4, 0, 4, 4, 0, 0, 1, 1, 2, 2, 2, 1, 1, 1}, []int{8, 16}),
+			wantErr: ErrInvalidPath{Path: Path{0}, Reason: "cannot delete the exterior ring of a polygon with holes"},
+		},
+		{
+			name: "multi_line_string_line",
+			g:    NewMultiLineStringFlat(XY, []float64{0, 0, 1, 1, 2, 2, 3, 3, 4, 4}, []int{4, 6, 10}),
+			p:    Path{1},
+			want: NewMultiLineStringFlat(XY, []float64{0, 0, 1, 1, 3, 3, 4, 4}, []int{4, 8}),
+		},
+		{
+			name: "multi_line_string_vertex",
+			g:    NewMultiLineStringFlat(XY, []float64{0, 0, 1, 1, 2, 2, 3, 3, 4, 4}, []int{6, 10}),
+			p:    Path{0, 0},
+			want: NewMultiLineStringFlat(XY, []float64{1, 1, 2, 2, 3, 3, 4, 4}, []int{4, 8}),
+		},
+		{
+			name: "multi_polygon_polygon",
+			g:    newPathTestMultiPolygon(),
+			p:    Path{0},
+			want: NewMultiPolygonFlat(XY, []float64{10, 10, 11, 10, 11, 11, 10, 10}, [][]int{{}, {8}}).SetSRID(4326),
+		},
+		{
+			name: "multi_polygon_ring",
+			g:    newPathTestMultiPolygon(),
+			p:    Path{0, 1},
+			want: NewMultiPolygonFlat(XY, []float64{
+				0, 0, 4, 0, 4, 4, 0, 4, 0, 0,
+				10, 10, 11, 10, 11, 11, 10, 10,
+			}, [][]int{{10}, {}, {18}}).SetSRID(4326),
+		},
+		{
+			name: "multi_polygon_vertex",
+			g:    newPathTestMultiPolygon(),
+			p:    Path{0, 0, 3},
+			want: NewMultiPolygonFlat(XY, []float64{
+				0, 0, 4, 0, 4, 4, 0, 0,
+				1, 1, 1, 2, 2, 2, 1, 1,
+				10, 10, 11, 10, 11, 11, 10, 10,
+			}, [][]int{{8, 16}, {}, {24}}).SetSRID(4326),
+		},
+		{
+			name: "geometry_collection_member",
+			g: NewGeometryCollection().MustPush(
+				NewPointFlat(XY, []float64{1, 2}),
+				NewPointFlat(XY, []float64{3, 4}),
+			),
+			p:    Path{0},
+			want: NewGeometryCollection().MustPush(NewPointFlat(XY, []float64{3, 4})),
+		},
+		{
+			name: "geometry_collection_nested",
+			g:    NewGeometryCollection().MustPush(NewMultiPointFlat(XY, []float64{1, 2, 3, 4})),
+			p:    Path{0, 1},
+			want: NewGeometryCollection().MustPush(NewMultiPointFlat(XY, []float64{1, 2})),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.p.Delete(tc.g); !reflect.DeepEqual(err, tc.wantErr) {
+				t.Fatalf("Path%v.Delete(...) == %v, want %v", tc.p, err, tc.wantErr)
+			}
+			if !reflect.DeepEqual(tc.g, tc.want) {
+				t.Errorf("Path%v.Delete(...) gives %#v, want %#v", tc.p, tc.g, tc.want)
+			}
+		})
+	}
+}
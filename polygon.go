@@ -1,5 +1,7 @@
 package geom
 
+import "fmt"
+
 // A Polygon represents a polygon as a collection of LinearRings. The first
 // LinearRing is the outer boundary. Subsequent LinearRings are inner
 // boundaries (holes).
@@ -22,6 +24,22 @@ func NewPolygonFlat(layout Layout, flatCoords []float64, ends []int) *Polygon {
 	return g
 }
 
+// AddHole appends lr as a hole of g after checking that it is inside g's
+// exterior ring and does not overlap any of g's existing holes. Holes may
+// touch the exterior ring and each other at points.
+func (g *Polygon) AddHole(lr *LinearRing) error {
+	if lr.layout != g.layout {
+		return ErrLayoutMismatch{Got: lr.layout, Want: g.layout}
+	}
+	if len(g.ends) == 0 {
+		return ErrInvalidHole{Index: 0, Reason: "polygon has no exterior ring"}
+	}
+	if err := g.validateHole(len(g.ends), lr.flatCoords); err != nil {
+		return err
+	}
+	return g.Push(lr)
+}
+
 // Area returns the area.
 func (g *Polygon) Area() float64 {
 	return doubleArea2(g.flatCoords, 0, g.ends, g.stride) / 2
@@ -32,11 +50,24 @@ func (g *Polygon) Clone() *Polygon {
 	return deriveClonePolygon(g)
 }
 
+// DeleteVertex deletes the jth vertex of the ith LinearRing, keeping the ring
+// closed. It returns an ErrInvalidVertex if fewer than four coordinates would
+// remain.
+func (g *Polygon) DeleteVertex(i, j int) error {
+	return g.deleteVertex(i, j, true)
+}
+
 // Empty returns true if the geometry has no coordinate.
 func (g *Polygon) Empty() bool {
 	return len(g.FlatCoords()) == 0
 }
 
+// InsertVertex inserts c before the jth vertex of the ith LinearRing, keeping
+// the ring closed.
+func (g *Polygon) InsertVertex(i, j int, c Coord) error {
+	return g.insertVertex(i, j, c, true)
+}
+
 // Length returns the perimter.
 func (g *Polygon) Length() float64 {
 	return length2(g.flatCoords, 0, g.ends, g.stride)
@@ -48,7 +79,13 @@ func (g *Polygon) LinearRing(i int) *LinearRing {
 	if i > 0 {
 		offset = g.ends[i-1]
 	}
-	return NewLinearRingFlat(g.layout, g.flatCoords[offset:g.ends[i]])
+	return NewLinearRingFlat(g.layout, g.flatCoords[offset:g.ends[i]]).SetSRID(g.srid)
+}
+
+// MoveVertex sets the jth vertex of the ith LinearRing to c, keeping the ring
+// closed.
+func (g *Polygon) MoveVertex(i, j int, c Coord) error {
+	return moveVertex(g.flatCoords, g.offset(i), g.ends[i], g.stride, j, c, true)
 }
 
 // MustSetCoords sets the coordinates and panics on any error.
@@ -57,6 +94,14 @@ func (g *Polygon) MustSetCoords(coords [][]Coord) *Polygon {
 	return g
 }
 
+// NumHoles returns the number of holes.
+func (g *Polygon) NumHoles() int {
+	if len(g.ends) == 0 {
+		return 0
+	}
+	return len(g.ends) - 1
+}
+
 // NumLinearRings returns the number of LinearRings.
 func (g *Polygon) NumLinearRings() int {
 	return len(g.ends)
@@ -72,6 +117,28 @@ func (g *Polygon) Push(lr *LinearRing) error {
 	return nil
 }
 
+// RemoveHole removes the ith hole, i.e. the (i+1)th LinearRing, from g.
+func (g *Polygon) RemoveHole(i int) {
+	ring := i + 1
+	start, end := g.ends[ring-1], g.ends[ring]
+	g.flatCoords = append(g.flatCoords[:start], g.flatCoords[end:]...)
+	for j := ring + 1; j < len(g.ends); j++ {
+		g.ends[j] -= end - start
+	}
+	g.ends = append(g.ends[:ring], g.ends[ring+1:]...)
+}
+
+// RingAreas returns the signed area of each LinearRing, which is positive for
+// counterclockwise rings and negative for clockwise rings.
+func (g *Polygon) RingAreas() []float64 {
+	return ringAreas2(g.flatCoords, 0, g.ends, g.stride)
+}
+
+// RingLengths returns the length of each LinearRing.
+func (g *Polygon) RingLengths() []float64 {
+	return lengths2(g.flatCoords, 0, g.ends, g.stride)
+}
+
 // SetCoords sets the coordinates.
 func (g *Polygon) SetCoords(coords [][]Coord) (*Polygon, error) {
 	if err := g.setCoords(coords); err != nil {
@@ -80,6 +147,18 @@ func (g *Polygon) SetCoords(coords [][]Coord) (*Polygon, error) {
 	return g, nil
 }
 
+// SetCoordsChecked sets the coordinates like SetCoords, but first closes
+// any unclosed rings, and returns an ErrInvalidRing if a ring has fewer than
+// four coordinates or if a hole is wound the same way as the exterior ring.
+// coords are not modified, and g is not modified if an error is returned.
+func (g *Polygon) SetCoordsChecked(coords [][]Coord) (*Polygon, error) {
+	coords, err := checkRings(g.layout, coords, nil)
+	if err != nil {
+		return nil, err
+	}
+	return g.SetCoords(coords)
+}
+
 // SetSRID sets the SRID of g.
 func (g *Polygon) SetSRID(srid int) *Polygon {
 	g.srid = srid
@@ -90,3 +169,35 @@ func (g *Polygon) SetSRID(srid int) *Polygon {
 func (g *Polygon) Swap(g2 *Polygon) {
 	*g, *g2 = *g2, *g
 }
+
+// ValidateHoles returns an ErrInvalidHole if any of g's holes is not inside
+// its exterior ring or overlaps another hole.
+func (g *Polygon) ValidateHoles() error {
+	for i := 1; i < len(g.ends); i++ {
+		if err := g.validateHole(i, g.flatCoords[g.ends[i-1]:g.ends[i]]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateHole checks that hole is inside g's exterior ring and does not
+// overlap g's holes before the ith LinearRing.
+func (g *Polygon) validateHole(i int, hole []float64) error {
+	if len(hole) < 4*g.stride {
+		return ErrInvalidHole{Index: i, Reason: "too few coordinates"}
+	}
+	shell := g.flatCoords[:g.ends[0]]
+	if ringsOverlap(hole, shell, g.stride) || ringInteriorLocation(hole, shell, g.stride) != inside {
+		return ErrInvalidHole{Index: i, Reason: "not inside exterior ring"}
+	}
+	for j := 1; j < i; j++ {
+		other := g.flatCoords[g.ends[j-1]:g.ends[j]]
+		if ringsOverlap(hole, other, g.stride) ||
+			ringInteriorLocation(hole, other, g.stride) != outside ||
+			ringInteriorLocation(other, hole, g.stride) != outside {
+			return ErrInvalidHole{Index: i, Reason: fmt.Sprintf("overlaps hole %d", j)}
+		}
+	}
+	return nil
+}
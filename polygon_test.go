@@ -127,3 +127,236 @@ func TestPolygonStrideMismatch(t *testing.T) {
 		}
 	}
 }
+
+func TestPolygonAddHole(t *testing.T) {
+	newRing := func(flatCoords ...float64) *LinearRing {
+		return NewLinearRingFlat(XY, flatCoords)
+	}
+	square := func(x0, y0, x1, y1 float64) *LinearRing {
+		return newRing(x0, y0, x1, y0, x1, y1, x0, y1, x0, y0)
+	}
+	for _, tc := range []struct {
+		name  string
+		holes []*LinearRing
+		hole  *LinearRing
+		err   error
+	}{
+		{
+			name: "inside",
+			hole: square(1, 1, 2, 2),
+		},
+		{
+			name: "touching_shell_at_point",
+			hole: newRing(0, 5, 2, 4, 2, 6, 0, 5),
+		},
+		{
+			name: "outside",
+			hole: square(11, 11, 12, 12),
+			err:  ErrInvalidHole{Index: 1, Reason: "not inside exterior ring"},
+		},
+		{
+			name: "crossing_shell",
+			hole: square(9, 9, 11, 11),
+			err:  ErrInvalidHole{Index: 1, Reason: "not inside exterior ring"},
+		},
+		{
+			name: "sharing_shell_edge",
+			hole: square(0, 0, 1, 1),
+			err:  ErrInvalidHole{Index: 1, Reason: "not inside exterior ring"},
+		},
+		{
+			name: "equal_to_shell",
+			hole: square(0, 0, 10, 10),
+			err:  ErrInvalidHole{Index: 1, Reason: "not inside exterior ring"},
+		},
+		{
+			name: "too_few_coordinates",
+			hole: newRing(1, 1, 2, 2, 1, 1),
+			err:  ErrInvalidHole{Index: 1, Reason: "too few coordinates"},
+		},
+		{
+			name:  "disjoint_from_hole",
+			holes: []*LinearRing{square(1, 1, 2, 2)},
+			hole:  square(3, 3, 4, 4),
+		},
+		{
+			name:  "touching_hole_at_point",
+			holes: []*LinearRing{square(1, 1, 2, 2)},
+			hole:  square(2, 2, 3, 3),
+		},
+		{
+			name:  "crossing_hole",
+			holes: []*LinearRing{square(1, 1, 3, 3)},
+			hole:  square(2, 2, 4, 4),
+			err:   ErrInvalidHole{Index: 2, Reason: "overlaps hole 1"},
+		},
+		{
+			name:  "inside_hole",
+			holes: []*LinearRing{square(1, 1, 5, 5)},
+			hole:  square(2, 2, 3, 3),
+			err:   ErrInvalidHole{Index: 2, Reason: "overlaps hole 1"},
+		},
+		{
+			name:  "containing_hole",
+			holes: []*LinearRing{square(2, 2, 3, 3)},
+			hole:  square(1, 1, 5, 5),
+			err:   ErrInvalidHole{Index: 2, Reason: "overlaps hole 1"},
+		},
+		{
+			name: "layout_mismatch",
+			hole: NewLinearRingFlat(XYZ, []float64{1, 1, 0, 2, 1, 0, 2, 2, 0, 1, 1, 0}),
+			err:  ErrLayoutMismatch{Got: XYZ, Want: XY},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			p := NewPolygon(XY)
+			if err := p.Push(square(0, 0, 10, 10)); err != nil {
+				t.Fatal(err)
+			}
+			for _, hole := range tc.holes {
+				if err := p.AddHole(hole); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := p.AddHole(tc.hole); !reflect.DeepEqual(err, tc.err) {
+				t.Errorf("p.AddHole(%v) == %v, want %v", tc.hole.FlatCoords(), err, tc.err)
+			}
+			wantNumHoles := len(tc.holes)
+			if tc.err == nil {
+				wantNumHoles++
+			}
+			if got := p.NumHoles(); got != wantNumHoles {
+				t.Errorf("p.NumHoles() == %d, want %d", got, wantNumHoles)
+			}
+			if err := p.ValidateHoles(); err != nil {
+				t.Errorf("p.ValidateHoles() == %v, want <nil>", err)
+			}
+		})
+	}
+
+	if err := NewPolygon(XY).AddHole(square(0, 0, 1, 1)); err == nil {
+		t.Errorf("NewPolygon(XY).AddHole(...) == <nil>, want !<nil>")
+	}
+}
+
+func TestPolygonRemoveHole(t *testing.T) {
+	p := NewPolygon(XY).MustSetCoords([][]Coord{
+		{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}},
+		{{1, 1}, {2, 1}, {2, 2}, {1, 1}},
+		{{3, 3}, {4, 3}, {4, 4}, {3, 3}},
+		{{5, 5}, {6, 5}, {6, 6}, {5, 5}},
+	})
+	p.RemoveHole(1)
+	testPolygonEquals(t, p, &testPolygon{
+		layout: XY,
+		stride: 2,
+		coords: [][]Coord{
+			{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}},
+			{{1, 1}, {2, 1}, {2, 2}, {1, 1}},
+			{{5, 5}, {6, 5}, {6, 6}, {5, 5}},
+		},
+		ends:       []int{10, 18, 26},
+		flatCoords: []float64{0, 0, 10, 0, 10, 10, 0, 10, 0, 0, 1, 1, 2, 1, 2, 2, 1, 1, 5, 5, 6, 5, 6, 6, 5, 5},
+		bounds:     NewBounds(XY).Set(0, 0, 10, 10),
+	})
+	p.RemoveHole(1)
+	p.RemoveHole(0)
+	if got := p.NumHoles(); got != 0 {
+		t.Errorf("p.NumHoles() == %d, want 0", got)
+	}
+
+	invalid := NewPolygon(XY).MustSetCoords([][]Coord{
+		{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}},
+		{{1, 1}, {5, 1}, {5, 5}, {1, 1}},
+		{{2, 1.5}, {4, 1.5}, {4, 3}, {2, 1.5}},
+	})
+	if err, want := invalid.ValidateHoles(), (ErrInvalidHole{Index: 2, Reason: "overlaps hole 1"}); !reflect.DeepEqual(err, want) {
+		t.Errorf("invalid.ValidateHoles() == %v, want %v", err, want)
+	}
+}
+
+func TestPolygonRingAreasAndLengths(t *testing.T) {
+	p := NewPolygon(XY).MustSetCoords([][]Coord{
+		{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}},
+		{{1, 1}, {1, 3}, {3, 3}, {3, 1}, {1, 1}},
+		{{5, 5}, {8, 5}, {5, 9}, {5, 5}},
+	})
+	if got, want := p.RingAreas(), []float64{100, -4, 6}; !reflect.DeepEqual(got, want) {
+		t.Errorf("p.RingAreas() == %v, want %v", got, want)
+	}
+	if got, want := p.RingLengths(), []float64{40, 8, 12}; !reflect.DeepEqual(got, want) {
+		t.Errorf("p.RingLengths() == %v, want %v", got, want)
+	}
+	if got := NewPolygon(XY).RingAreas(); len(got) != 0 {
+		t.Errorf("NewPolygon(XY).RingAreas() == %v, want []", got)
+	}
+}
+
+func TestPolygonSetCoordsChecked(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		coords [][]Coord
+		want   [][]Coord
+		err    error
+	}{
+		{
+			name:   "closed",
+			coords: [][]Coord{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}},
+			want:   [][]Coord{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}},
+		},
+		{
+			name: "unclosed",
+			coords: [][]Coord{
+				{{0, 0}, {10, 0}, {10, 10}, {0, 10}},
+				{{1, 1}, {1, 2}, {2, 2}, {2, 1}},
+			},
+			want: [][]Coord{
+				{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}},
+				{{1, 1}, {1, 2}, {2, 2}, {2, 1}, {1, 1}},
+			},
+		},
+		{
+			name:   "too_few_coordinates",
+			coords: [][]Coord{{{0, 0}, {1, 0}, {0, 0}}},
+			err:    ErrInvalidRing{Path: Path{0}, Reason: "too few coordinates"},
+		},
+		{
+			name: "hole_too_few_coordinates",
+			coords: [][]Coord{
+				{{0, 0}, {10, 0}, {10, 10}, {0, 0}},
+				{},
+			},
+			err: ErrInvalidRing{Path: Path{1}, Reason: "too few coordinates"},
+		},
+		{
+			name: "hole_orientation",
+			coords: [][]Coord{
+				{{0, 0}, {10, 0}, {10, 10}, {0, 10}},
+				{{1, 1}, {2, 1}, {2, 2}, {1, 2}},
+			},
+			err: ErrInvalidRing{Path: Path{1}, Reason: "same orientation as exterior ring"},
+		},
+		{
+			name:   "stride_mismatch",
+			coords: [][]Coord{{{0, 0}, {1, 0, 0}, {1, 1}, {0, 0}}},
+			err:    ErrStrideMismatch{Got: 3, Want: 2},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			p := NewPolygon(XY)
+			got, err := p.SetCoordsChecked(tc.coords)
+			if !reflect.DeepEqual(err, tc.err) {
+				t.Fatalf("p.SetCoordsChecked(%v) == %v, %v, want ..., %v", tc.coords, got, err, tc.err)
+			}
+			if err != nil {
+				if !p.Empty() {
+					t.Errorf("p.SetCoordsChecked(%v) modified p", tc.coords)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got.Coords(), tc.want) {
+				t.Errorf("p.SetCoordsChecked(%v).Coords() == %v, want %v", tc.coords, got.Coords(), tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package geom
+
+// A PolyhedralSurface is a surface composed of Polygon patches that share
+// their edges, such as the faces of a solid.
+type PolyhedralSurface struct {
+	geom3
+}
+
+// NewPolyhedralSurface returns a new PolyhedralSurface with no patches.
+func NewPolyhedralSurface(layout Layout) *PolyhedralSurface {
+	return NewPolyhedralSurfaceFlat(layout, nil, nil)
+}
+
+// NewPolyhedralSurfaceFlat returns a new PolyhedralSurface with the given
+// flat coordinates.
+func NewPolyhedralSurfaceFlat(layout Layout, flatCoords []float64, endss [][]int) *PolyhedralSurface {
+	g := new(PolyhedralSurface)
+	g.layout = layout
+	g.stride = layout.Stride()
+	g.flatCoords = flatCoords
+	g.endss = endss
+	return g
+}
+
+// Area returns the sum of the signed areas of the projections of the patches
+// onto the XY plane.
+func (g *PolyhedralSurface) Area() float64 {
+	return doubleArea3(g.flatCoords, 0, g.endss, g.stride) / 2
+}
+
+// Clone returns a deep copy.
+func (g *PolyhedralSurface) Clone() *PolyhedralSurface {
+	return NewPolyhedralSurfaceFlat(g.layout, append([]float64(nil), g.flatCoords...), cloneEndss(g.endss)).SetSRID(g.srid)
+}
+
+// Empty returns true if g has no patches.
+func (g *PolyhedralSurface) Empty() bool {
+	return g.NumPatches() == 0
+}
+
+// Length returns the sum of the perimeters of the patches.
+func (g *PolyhedralSurface) Length() float64 {
+	return length3(g.flatCoords, 0, g.endss, g.stride)
+}
+
+// MustSetCoords sets the coordinates and panics on any error.
+func (g *PolyhedralSurface) MustSetCoords(coords [][][]Coord) *PolyhedralSurface {
+	Must(g.SetCoords(coords))
+	return g
+}
+
+// NumPatches returns the number of patches.
+func (g *PolyhedralSurface) NumPatches() int {
+	return len(g.endss)
+}
+
+// Patch returns the ith patch.
+func (g *PolyhedralSurface) Patch(i int) *Polygon {
+	flatCoords, ends := g.polygon(i)
+	return NewPolygonFlat(g.layout, flatCoords, ends).SetSRID(g.srid)
+}
+
+// Push appends a patch.
+func (g *PolyhedralSurface) Push(p *Polygon) error {
+	if p.layout != g.layout {
+		return ErrLayoutMismatch{Got: p.layout, Want: g.layout}
+	}
+	g.pushPolygon(p.flatCoords, p.ends)
+	return nil
+}
+
+// SetCoords sets the coordinates.
+func (g *PolyhedralSurface) SetCoords(coords [][][]Coord) (*PolyhedralSurface, error) {
+	if err := g.setCoords(coords); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// SetSRID sets the SRID of g.
+func (g *PolyhedralSurface) SetSRID(srid int) *PolyhedralSurface {
+	g.srid = srid
+	return g
+}
+
+// SurfaceArea returns the sum of the areas of the patches in three
+// dimensions, using their Z values if they have any.
+func (g *PolyhedralSurface) SurfaceArea() float64 {
+	return surfaceArea3(g.flatCoords, 0, g.endss, g.stride, g.layout.ZIndex())
+}
+
+// Swap swaps the values of g and g2.
+func (g *PolyhedralSurface) Swap(g2 *PolyhedralSurface) {
+	*g, *g2 = *g2, *g
+}
+
+// polygon returns the flat coordinates and ends of the ith polygon of g,
+// which alias g.
+func (g *geom3) polygon(i int) ([]float64, []int) {
+	offset := 0
+	if i > 0 {
+		ends := g.endss[i-1]
+		offset = ends[len(ends)-1]
+	}
+	ends := make([]int, len(g.endss[i]))
+	for j, end := range g.endss[i] {
+		ends[j] = end - offset
+	}
+	return g.flatCoords[offset:g.endss[i][len(g.endss[i])-1]], ends
+}
+
+// pushPolygon appends the polygon with flatCoords and ends to g.
+func (g *geom3) pushPolygon(flatCoords []float64, ends []int) {
+	offset := len(g.flatCoords)
+	newEnds := make([]int, len(ends))
+	for i, end := range ends {
+		newEnds[i] = end + offset
+	}
+	g.flatCoords = append(g.flatCoords, flatCoords...)
+	g.endss = append(g.endss, newEnds)
+}
+
+// cloneEndss returns a deep copy of endss.
+func cloneEndss(endss [][]int) [][]int {
+	if endss == nil {
+		return nil
+	}
+	clone := make([][]int, len(endss))
+	for i, ends := range endss {
+		clone[i] = append([]int(nil), ends...)
+	}
+	return clone
+}
@@ -0,0 +1,71 @@
+package geom
+
+import (
+	"reflect"
+	"testing"
+)
+
+// unitCubeCoords are the coordinates of the faces of the unit cube, wound
+// counterclockwise when seen from outside.
+var unitCubeCoords = [][][]Coord{
+	{{{0, 0, 0}, {0, 1, 0}, {1, 1, 0}, {1, 0, 0}, {0, 0, 0}}},
+	{{{0, 0, 1}, {1, 0, 1}, {1, 1, 1}, {0, 1, 1}, {0, 0, 1}}},
+	{{{0, 0, 0}, {1, 0, 0}, {1, 0, 1}, {0, 0, 1}, {0, 0, 0}}},
+	{{{0, 1, 0}, {0, 1, 1}, {1, 1, 1}, {1, 1, 0}, {0, 1, 0}}},
+	{{{0, 0, 0}, {0, 0, 1}, {0, 1, 1}, {0, 1, 0}, {0, 0, 0}}},
+	{{{1, 0, 0}, {1, 1, 0}, {1, 1, 1}, {1, 0, 1}, {1, 0, 0}}},
+}
+
+func TestPolyhedralSurface(t *testing.T) {
+	ps := NewPolyhedralSurface(XYZ).MustSetCoords(unitCubeCoords).SetSRID(4979)
+	if err := ps.verify(); err != nil {
+		t.Error(err)
+	}
+	if got, want := ps.NumPatches(), 6; got != want {
+		t.Errorf("NumPatches() == %d, want %d", got, want)
+	}
+	if got, want := ps.Bounds(), NewBounds(XYZ).Set(0, 0, 0, 1, 1, 1); !reflect.DeepEqual(got, want) {
+		t.Errorf("Bounds() == %v, want %v", got, want)
+	}
+	if got, want := ps.Area(), 0.0; got != want {
+		t.Errorf("Area() == %v, want %v", got, want)
+	}
+	if got, want := ps.SurfaceArea(), 6.0; got != want {
+		t.Errorf("SurfaceArea() == %v, want %v", got, want)
+	}
+	if got, want := ps.Length(), 16.0; got != want {
+		t.Errorf("Length() == %v, want %v", got, want)
+	}
+	for i, coords := range unitCubeCoords {
+		want := NewPolygon(XYZ).MustSetCoords(coords).SetSRID(4979)
+		if got := ps.Patch(i); !reflect.DeepEqual(got, want) {
+			t.Errorf("Patch(%d) == %v, want %v", i, got, want)
+		}
+	}
+	clone := ps.Clone()
+	if !reflect.DeepEqual(clone, ps) {
+		t.Errorf("Clone() == %v, want %v", clone, ps)
+	}
+	clone.Endss()[0][0] = 0
+	if ps.Endss()[0][0] != 15 {
+		t.Error("Clone() aliases the original")
+	}
+}
+
+func TestPolyhedralSurfacePush(t *testing.T) {
+	ps := NewPolyhedralSurface(XYZ)
+	for _, coords := range unitCubeCoords {
+		if err := ps.Push(NewPolygon(XYZ).MustSetCoords(coords)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if want := NewPolyhedralSurface(XYZ).MustSetCoords(unitCubeCoords); !reflect.DeepEqual(ps, want) {
+		t.Errorf("Push(...) == %v, want %v", ps, want)
+	}
+	if err, want := ps.Push(NewPolygon(XY)), (ErrLayoutMismatch{Got: XY, Want: XYZ}); !reflect.DeepEqual(err, want) {
+		t.Errorf("Push(NewPolygon(XY)) == %v, want %v", err, want)
+	}
+	if got, want := NewPolyhedralSurface(XYZ).SurfaceArea(), 0.0; got != want {
+		t.Errorf("SurfaceArea() == %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,141 @@
+package geom
+
+import "math"
+
+// Ring predicates used to validate the holes of Polygons. Rings are given as
+// flat coordinates with the first coordinate repeated at the end. Only the X
+// and Y ordinates are considered.
+
+const (
+	outside  = -1
+	boundary = 0
+	inside   = 1
+)
+
+// orient returns twice the signed area of the triangle (a, b, c), which is
+// positive if the triangle is counter-clockwise.
+func orient(ax, ay, bx, by, cx, cy float64) float64 {
+	return (bx-ax)*(cy-ay) - (by-ay)*(cx-ax)
+}
+
+// ringLocation returns whether (x, y) is inside, on the boundary of, or
+// outside the ring flatCoords.
+func ringLocation(flatCoords []float64, stride int, x, y float64) int {
+	crossings := 0
+	for i := stride; i < len(flatCoords); i += stride {
+		ax, ay := flatCoords[i-stride], flatCoords[i-stride+1]
+		bx, by := flatCoords[i], flatCoords[i+1]
+		if orient(ax, ay, bx, by, x, y) == 0 &&
+			math.Min(ax, bx) <= x && x <= math.Max(ax, bx) && math.Min(ay, by) <= y && y <= math.Max(ay, by) {
+			return boundary
+		}
+		if (ay > y) != (by > y) {
+			if ax+(y-ay)*(bx-ax)/(by-ay) > x {
+				crossings++
+			}
+		}
+	}
+	if crossings%2 == 1 {
+		return inside
+	}
+	return outside
+}
+
+// segmentsOverlap returns true if the segments (a, b) and (c, d) cross at a
+// point interior to both or overlap along a non-zero length. Segments that
+// only touch are not considered to overlap.
+func segmentsOverlap(ax, ay, bx, by, cx, cy, dx, dy float64) bool {
+	d1 := orient(cx, cy, dx, dy, ax, ay)
+	d2 := orient(cx, cy, dx, dy, bx, by)
+	d3 := orient(ax, ay, bx, by, cx, cy)
+	d4 := orient(ax, ay, bx, by, dx, dy)
+	if (d1 > 0 && d2 < 0 || d1 < 0 && d2 > 0) && (d3 > 0 && d4 < 0 || d3 < 0 && d4 > 0) {
+		return true
+	}
+	if d1 != 0 || d2 != 0 || d3 != 0 || d4 != 0 {
+		return false
+	}
+	// The segments are collinear, so project them onto the axis along which
+	// they are longest and compare the intervals.
+	if math.Abs(bx-ax) < math.Abs(by-ay) {
+		ax, bx, cx, dx = ay, by, cy, dy
+	}
+	return math.Min(math.Max(ax, bx), math.Max(cx, dx)) > math.Max(math.Min(ax, bx), math.Min(cx, dx))
+}
+
+// ringsOverlap returns true if any segment of ring1 overlaps any segment of
+// ring2.
+func ringsOverlap(ring1, ring2 []float64, stride int) bool {
+	for i := stride; i < len(ring1); i += stride {
+		for j := stride; j < len(ring2); j += stride {
+			if segmentsOverlap(
+				ring1[i-stride], ring1[i-stride+1], ring1[i], ring1[i+1],
+				ring2[j-stride], ring2[j-stride+1], ring2[j], ring2[j+1],
+			) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ringInteriorLocation returns the location relative to ring2 of ring1, given
+// that their boundaries do not overlap. It tests the vertices and segment
+// midpoints of ring1 in turn until it finds one that is not on the boundary of
+// ring2. If there is none, it returns boundary.
+func ringInteriorLocation(ring1, ring2 []float64, stride int) int {
+	for i := 0; i < len(ring1); i += stride {
+		if location := ringLocation(ring2, stride, ring1[i], ring1[i+1]); location != boundary {
+			return location
+		}
+		if i >= stride {
+			x := (ring1[i-stride] + ring1[i]) / 2
+			y := (ring1[i-stride+1] + ring1[i+1]) / 2
+			if location := ringLocation(ring2, stride, x, y); location != boundary {
+				return location
+			}
+		}
+	}
+	return boundary
+}
+
+// checkRings returns rings with each ring closed by repeating its first
+// coordinate, if needed. It returns an ErrInvalidRing, addressing the ring by
+// path followed by its index, if a closed ring has fewer than four
+// coordinates or if a hole is wound the same way as the exterior ring. rings
+// are not modified. Coordinates must match layout.
+func checkRings(layout Layout, rings [][]Coord, path Path) ([][]Coord, error) {
+	closedRings := make([][]Coord, len(rings))
+	var exteriorArea float64
+	for i, ring := range rings {
+		for _, c := range ring {
+			if len(c) != layout.Stride() {
+				return nil, ErrStrideMismatch{Got: len(c), Want: layout.Stride()}
+			}
+		}
+		if n := len(ring); n > 0 && !ring[0].Equal(layout, ring[n-1]) {
+			ring = append(ring[:n:n], ring[0])
+		}
+		if len(ring) < 4 {
+			return nil, ErrInvalidRing{Path: append(path[:len(path):len(path)], i), Reason: "too few coordinates"}
+		}
+		area := coordsDoubleArea(ring)
+		if i == 0 {
+			exteriorArea = area
+		} else if area != 0 && exteriorArea != 0 && (area > 0) == (exteriorArea > 0) {
+			return nil, ErrInvalidRing{Path: append(path[:len(path):len(path)], i), Reason: "same orientation as exterior ring"}
+		}
+		closedRings[i] = ring
+	}
+	return closedRings, nil
+}
+
+// coordsDoubleArea returns twice the signed area of the closed ring, which is
+// positive if ring is counter-clockwise.
+func coordsDoubleArea(ring []Coord) float64 {
+	var doubleArea float64
+	for i := 1; i < len(ring); i++ {
+		doubleArea += ring[i-1][0]*ring[i][1] - ring[i][0]*ring[i-1][1]
+	}
+	return doubleArea
+}
@@ -0,0 +1,195 @@
+//go:build go1.23
+// +build go1.23
+
+package geom
+
+import "iter"
+
+// Sequences over coordinates and sub-geometries, for use with range-over-func.
+// Like the iterators in coordsiter.go, they do not build the nested slices
+// that Coords does. The coordinates that they yield are views of the
+// geometry's flat coordinates: they are only valid until the geometry is
+// modified and must not be modified themselves.
+
+// Coords returns a sequence of all the coordinates of g, in order, including
+// those of the members of nested GeometryCollections. The members of a
+// GeometryCollection may have different layouts, so coordinates may have
+// different lengths.
+func Coords(g T) iter.Seq[Coord] {
+	return func(yield func(Coord) bool) {
+		var it CoordsIter
+		if gc, ok := g.(*GeometryCollection); ok {
+			it = gc.CoordsIter()
+		} else {
+			it = newCoordsIter(g.FlatCoords(), g.Stride())
+		}
+		for it.Next() {
+			if !yield(it.Coord()) {
+				return
+			}
+		}
+	}
+}
+
+// allCoords returns a sequence of the indexes and coordinates in flatCoords.
+func allCoords(flatCoords []float64, stride int) iter.Seq2[int, Coord] {
+	return func(yield func(int, Coord) bool) {
+		if stride == 0 {
+			return
+		}
+		for i, offset := 0, 0; offset < len(flatCoords); i, offset = i+1, offset+stride {
+			if !yield(i, flatCoords[offset:offset+stride:offset+stride]) {
+				return
+			}
+		}
+	}
+}
+
+// All returns a sequence of the index and value of the coordinate of g, if g
+// is not empty.
+func (g *Point) All() iter.Seq2[int, Coord] {
+	return allCoords(g.flatCoords, g.stride)
+}
+
+// All returns a sequence of the indexes and values of the coordinates of g.
+func (g *LineString) All() iter.Seq2[int, Coord] {
+	return allCoords(g.flatCoords, g.stride)
+}
+
+// All returns a sequence of the indexes and values of the coordinates of g.
+func (g *LinearRing) All() iter.Seq2[int, Coord] {
+	return allCoords(g.flatCoords, g.stride)
+}
+
+// All returns a sequence of the indexes and values of the control points of
+// g.
+func (g *CircularString) All() iter.Seq2[int, Coord] {
+	return allCoords(g.flatCoords, g.stride)
+}
+
+// All returns a sequence of the indexes and values of the Points of g.
+func (g *MultiPoint) All() iter.Seq2[int, *Point] {
+	return func(yield func(int, *Point) bool) {
+		for i, n := 0, g.NumPoints(); i < n; i++ {
+			if !yield(i, g.Point(i)) {
+				return
+			}
+		}
+	}
+}
+
+// All returns a sequence of the indexes and values of the LinearRings of g.
+func (g *Polygon) All() iter.Seq2[int, *LinearRing] {
+	return func(yield func(int, *LinearRing) bool) {
+		for i := range g.ends {
+			if !yield(i, g.LinearRing(i)) {
+				return
+			}
+		}
+	}
+}
+
+// All returns a sequence of the indexes and values of the LineStrings of g.
+func (g *MultiLineString) All() iter.Seq2[int, *LineString] {
+	return func(yield func(int, *LineString) bool) {
+		for i := range g.ends {
+			if !yield(i, g.LineString(i)) {
+				return
+			}
+		}
+	}
+}
+
+// All returns a sequence of the indexes and values of the Polygons of g.
+func (g *MultiPolygon) All() iter.Seq2[int, *Polygon] {
+	return func(yield func(int, *Polygon) bool) {
+		g.eachPolygon(func(i int, flatCoords []float64, ends []int) bool {
+			return yield(i, NewPolygonFlat(g.layout, flatCoords, ends).SetSRID(g.srid))
+		})
+	}
+}
+
+// All returns a sequence of the indexes and values of the geometries of g.
+func (g *GeometryCollection) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i, member := range g.geoms {
+			if !yield(i, member) {
+				return
+			}
+		}
+	}
+}
+
+// All returns a sequence of the indexes and values of the segments of g.
+func (g *CompoundCurve) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i := range g.ends {
+			if !yield(i, g.Segment(i)) {
+				return
+			}
+		}
+	}
+}
+
+// All returns a sequence of the indexes and values of the rings of g.
+func (g *CurvePolygon) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i := range g.endss {
+			if !yield(i, g.Ring(i)) {
+				return
+			}
+		}
+	}
+}
+
+// All returns a sequence of the index and value of the ring of g, if g is not
+// empty.
+func (g *Triangle) All() iter.Seq2[int, *LinearRing] {
+	return func(yield func(int, *LinearRing) bool) {
+		offset := 0
+		for i, end := range g.ends {
+			if !yield(i, NewLinearRingFlat(g.layout, g.flatCoords[offset:end]).SetSRID(g.srid)) {
+				return
+			}
+			offset = end
+		}
+	}
+}
+
+// All returns a sequence of the indexes and values of the patches of g.
+func (g *PolyhedralSurface) All() iter.Seq2[int, *Polygon] {
+	return func(yield func(int, *Polygon) bool) {
+		g.eachPolygon(func(i int, flatCoords []float64, ends []int) bool {
+			return yield(i, NewPolygonFlat(g.layout, flatCoords, ends).SetSRID(g.srid))
+		})
+	}
+}
+
+// All returns a sequence of the indexes and values of the Triangles of g.
+func (g *TIN) All() iter.Seq2[int, *Triangle] {
+	return func(yield func(int, *Triangle) bool) {
+		g.eachPolygon(func(i int, flatCoords []float64, ends []int) bool {
+			return yield(i, NewTriangleFlat(g.layout, flatCoords, ends).SetSRID(g.srid))
+		})
+	}
+}
+
+// eachPolygon calls f with the index, flat coordinates, and ends of each
+// polygon of g, including empty ones, until f returns false.
+func (g *geom3) eachPolygon(f func(int, []float64, []int) bool) {
+	offset := 0
+	for i, ends := range g.endss {
+		end := offset
+		if len(ends) > 0 {
+			end = ends[len(ends)-1]
+		}
+		polygonEnds := make([]int, len(ends))
+		for j, e := range ends {
+			polygonEnds[j] = e - offset
+		}
+		if !f(i, g.flatCoords[offset:end], polygonEnds) {
+			return
+		}
+		offset = end
+	}
+}
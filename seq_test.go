@@ -0,0 +1,121 @@
+//go:build go1.23
+// +build go1.23
+
+package geom
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCoords(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		g    T
+		want []Coord
+	}{
+		{
+			name: "empty_point",
+			g:    NewPointEmpty(XY),
+		},
+		{
+			name: "linestring",
+			g:    NewLineStringFlat(XYZ, []float64{1, 2, 3, 4, 5, 6}),
+			want: []Coord{{1, 2, 3}, {4, 5, 6}},
+		},
+		{
+			name: "multipolygon",
+			g:    NewMultiPolygonFlat(XY, []float64{0, 0, 1, 0, 0, 1, 0, 0}, [][]int{{8}, {}}),
+			want: []Coord{{0, 0}, {1, 0}, {0, 1}, {0, 0}},
+		},
+		{
+			name: "geometrycollection",
+			g: NewGeometryCollection().MustPush(
+				NewPointFlat(XY, []float64{1, 2}),
+				NewGeometryCollection().MustPush(
+					NewLineStringFlat(XYM, []float64{3, 4, 5, 6, 7, 8}),
+				),
+			),
+			want: []Coord{{1, 2}, {3, 4, 5}, {6, 7, 8}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []Coord
+			for c := range Coords(tc.g) {
+				got = append(got, c)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Coords(%v) yielded %v, want %v", tc.g, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCoordsBreak(t *testing.T) {
+	n := 0
+	for range Coords(NewLineStringFlat(XY, []float64{1, 2, 3, 4, 5, 6})) {
+		n++
+		break
+	}
+	if n != 1 {
+		t.Errorf("got %d coordinates, want 1", n)
+	}
+}
+
+func TestLineStringAll(t *testing.T) {
+	ls := NewLineStringFlat(XY, []float64{1, 2, 3, 4})
+	var got []Coord
+	for i, c := range ls.All() {
+		if i != len(got) {
+			t.Errorf("got index %d, want %d", i, len(got))
+		}
+		got = append(got, c)
+	}
+	if want := ls.Coords(); !reflect.DeepEqual(got, want) {
+		t.Errorf("All() yielded %v, want %v", got, want)
+	}
+}
+
+func TestMultiPolygonAll(t *testing.T) {
+	mp := NewMultiPolygonFlat(XY, []float64{0, 0, 1, 0, 0, 1, 0, 0, 5, 5, 6, 5, 5, 6, 5, 5}, [][]int{{8}, {}, {16}})
+	want := []*Polygon{
+		NewPolygonFlat(XY, []float64{0, 0, 1, 0, 0, 1, 0, 0}, []int{8}),
+		NewPolygonFlat(XY, []float64{}, []int{}),
+		NewPolygonFlat(XY, []float64{5, 5, 6, 5, 5, 6, 5, 5}, []int{8}),
+	}
+	var got []*Polygon
+	for _, p := range mp.All() {
+		got = append(got, p)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("All() yielded %v, want %v", got, want)
+	}
+}
+
+func TestPolygonAll(t *testing.T) {
+	p := NewPolygon(XY).MustSetCoords([][]Coord{
+		{{0, 0}, {3, 0}, {3, 3}, {0, 3}, {0, 0}},
+		{{1, 1}, {1, 2}, {2, 2}, {2, 1}, {1, 1}},
+	})
+	n := 0
+	for i, lr := range p.All() {
+		if want := p.LinearRing(i); !reflect.DeepEqual(lr, want) {
+			t.Errorf("All() yielded %v at %d, want %v", lr, i, want)
+		}
+		n++
+	}
+	if n != p.NumLinearRings() {
+		t.Errorf("All() yielded %d LinearRings, want %d", n, p.NumLinearRings())
+	}
+}
+
+func TestGeometryCollectionAll(t *testing.T) {
+	gc := NewGeometryCollection().MustPush(NewPointFlat(XY, []float64{1, 2}), NewLineString(XY))
+	var got []T
+	for _, g := range gc.All() {
+		got = append(got, g)
+	}
+	if !reflect.DeepEqual(got, gc.Geoms()) {
+		t.Errorf("All() yielded %v, want %v", got, gc.Geoms())
+	}
+}
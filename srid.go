@@ -0,0 +1,25 @@
+package geom
+
+// SetSRIDRecursive sets the SRID of g and of all the members of g and of any
+// nested GeometryCollections, and returns g. The sub-geometries of Multi*
+// geometries, Polygons, and surfaces share the SRID of their parent, so
+// setting the SRID of their parent is sufficient. It panics if g is not one of
+// this package's geometry types.
+func SetSRIDRecursive(g T, srid int) T {
+	switch g := g.(type) {
+	case *GeometryCollection:
+		g.srid = srid
+		for _, member := range g.geoms {
+			SetSRIDRecursive(member, srid)
+		}
+	case interface{ setSRID(int) }:
+		g.setSRID(srid)
+	default:
+		panic(ErrUnsupportedType{Value: g})
+	}
+	return g
+}
+
+func (g *geom0) setSRID(srid int) {
+	g.srid = srid
+}
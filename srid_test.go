@@ -0,0 +1,33 @@
+package geom
+
+import "testing"
+
+func TestSetSRIDRecursive(t *testing.T) {
+	p := NewPointFlat(XY, []float64{1, 2}).SetSRID(4326)
+	ls := NewLineStringFlat(XY, []float64{1, 2, 3, 4})
+	inner := NewGeometryCollection().MustPush(ls)
+	gc := NewGeometryCollection().MustPush(p, inner)
+	if got := SetSRIDRecursive(gc, 3857); got != gc {
+		t.Errorf("SetSRIDRecursive(...) == %v, want %v", got, gc)
+	}
+	for _, g := range []T{gc, p, inner, ls} {
+		if got := g.SRID(); got != 3857 {
+			t.Errorf("%v.SRID() == %d, want 3857", g, got)
+		}
+	}
+}
+
+func TestChildSRIDs(t *testing.T) {
+	mp := NewMultiPolygonFlat(XY, []float64{0, 0, 1, 0, 0, 1, 0, 0}, [][]int{{8}}).SetSRID(4326)
+	for _, g := range []T{
+		mp.Polygon(0),
+		mp.Polygon(0).LinearRing(0),
+		NewMultiPointFlat(XY, []float64{1, 2}).SetSRID(4326).Point(0),
+		NewMultiLineStringFlat(XY, []float64{1, 2, 3, 4}, []int{4}).SetSRID(4326).LineString(0),
+		NewTINFlat(XY, []float64{0, 0, 1, 0, 0, 1, 0, 0}, [][]int{{8}}).SetSRID(4326).Triangle(0),
+	} {
+		if got := g.SRID(); got != 4326 {
+			t.Errorf("%v.SRID() == %d, want 4326", g, got)
+		}
+	}
+}
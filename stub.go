@@ -0,0 +1,78 @@
+package geom
+
+// A Stub describes a geometry without its coordinates. It records the
+// geometry's type, layout, SRID, bounds, and number of vertices, which is
+// enough to build catalogs and search indexes over large collections of
+// geometries without keeping their coordinates in memory. Stubs are returned
+// by NewStub and by the UnmarshalStub functions of the encoding packages.
+type Stub struct {
+	Type        string // for example "Polygon"
+	Layout      Layout
+	SRID        int
+	Bounds      *Bounds
+	NumVertices int
+}
+
+// NewStub returns the Stub of g. The Bounds and NumVertices of a
+// GeometryCollection include those of all its members, including the members
+// of nested GeometryCollections. The Bounds of a curve include the parts of
+// its arcs that extend beyond its control points, and its NumVertices is its
+// number of control points.
+func NewStub(g T) (*Stub, error) {
+	var typ string
+	switch g.(type) {
+	case *Point:
+		typ = "Point"
+	case *LineString:
+		typ = "LineString"
+	case *LinearRing:
+		typ = "LinearRing"
+	case *Polygon:
+		typ = "Polygon"
+	case *MultiPoint:
+		typ = "MultiPoint"
+	case *MultiLineString:
+		typ = "MultiLineString"
+	case *MultiPolygon:
+		typ = "MultiPolygon"
+	case *GeometryCollection:
+		typ = "GeometryCollection"
+	case *CircularString:
+		typ = "CircularString"
+	case *CompoundCurve:
+		typ = "CompoundCurve"
+	case *CurvePolygon:
+		typ = "CurvePolygon"
+	case *Triangle:
+		typ = "Triangle"
+	case *PolyhedralSurface:
+		typ = "PolyhedralSurface"
+	case *TIN:
+		typ = "TIN"
+	default:
+		return nil, ErrUnsupportedType{Value: g}
+	}
+	s := &Stub{
+		Type:   typ,
+		Layout: g.Layout(),
+		SRID:   g.SRID(),
+		Bounds: NewBounds(g.Layout()),
+	}
+	s.extend(g)
+	return s, nil
+}
+
+// extend extends the bounds and number of vertices of s to include g.
+func (s *Stub) extend(g T) {
+	if gc, ok := g.(*GeometryCollection); ok {
+		for _, member := range gc.geoms {
+			s.extend(member)
+		}
+		return
+	}
+	if g.Stride() == 0 {
+		return
+	}
+	s.Bounds.Extend(g)
+	s.NumVertices += len(g.FlatCoords()) / g.Stride()
+}
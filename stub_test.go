@@ -0,0 +1,154 @@
+package geom
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewStub(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		g    T
+		want *Stub
+	}{
+		{
+			name: "point",
+			g:    NewPoint(XY).MustSetCoords(Coord{1, 2}).SetSRID(4326),
+			want: &Stub{
+				Type:        "Point",
+				Layout:      XY,
+				SRID:        4326,
+				Bounds:      NewBounds(XY).Set(1, 2, 1, 2),
+				NumVertices: 1,
+			},
+		},
+		{
+			name: "empty_line_string",
+			g:    NewLineString(XYZ),
+			want: &Stub{
+				Type:   "LineString",
+				Layout: XYZ,
+				Bounds: NewBounds(XYZ),
+			},
+		},
+		{
+			name: "multi_polygon",
+			g: NewMultiPolygon(XYM).MustSetCoords([][][]Coord{
+				{{{0, 0, 1}, {4, 0, 2}, {4, 4, 3}, {0, 0, 1}}},
+				{{{5, 5, 1}, {6, 5, 1}, {6, 7, 1}, {5, 5, 1}}},
+			}),
+			want: &Stub{
+				Type:        "MultiPolygon",
+				Layout:      XYM,
+				Bounds:      NewBounds(XYM).Set(0, 0, 1, 6, 7, 3),
+				NumVertices: 8,
+			},
+		},
+		{
+			name: "nested_geometry_collection",
+			g: NewGeometryCollection().MustPush(
+				NewPoint(XY).MustSetCoords(Coord{1, 2}),
+				NewGeometryCollection().MustPush(
+					NewLineString(XYZ).MustSetCoords([]Coord{{3, 4, 5}, {6, 7, 8}}),
+				),
+			).SetSRID(3857),
+			want: &Stub{
+				Type:        "GeometryCollection",
+				Layout:      XYZ,
+				SRID:        3857,
+				Bounds:      NewBounds(XYZ).Set(1, 2, 5, 6, 7, 8),
+				NumVertices: 3,
+			},
+		},
+		{
+			name: "circular_string",
+			g:    NewCircularStringFlat(XY, []float64{0, 0, 1, 1, 2, 0}).SetSRID(4326),
+			want: &Stub{
+				Type:        "CircularString",
+				Layout:      XY,
+				SRID:        4326,
+				Bounds:      NewBounds(XY).Set(0, 0, 2, 1),
+				NumVertices: 3,
+			},
+		},
+		{
+			name: "compound_curve",
+			g: NewCompoundCurve(XY).MustPush(
+				NewCircularStringFlat(XY, []float64{0, 0, 1, -1, 2, 0}),
+				NewLineStringFlat(XY, []float64{2, 0, 3, 0}),
+			),
+			want: &Stub{
+				Type:        "CompoundCurve",
+				Layout:      XY,
+				Bounds:      NewBounds(XY).Set(0, -1, 3, 0),
+				NumVertices: 5,
+			},
+		},
+		{
+			name: "curve_polygon",
+			g: NewCurvePolygon(XY).MustPush(NewCompoundCurve(XY).MustPush(
+				NewCircularStringFlat(XY, []float64{0, 0, 1, 1, 2, 0}),
+				NewLineStringFlat(XY, []float64{2, 0, 0, 0}),
+			)),
+			want: &Stub{
+				Type:        "CurvePolygon",
+				Layout:      XY,
+				Bounds:      NewBounds(XY).Set(0, 0, 2, 1),
+				NumVertices: 5,
+			},
+		},
+		{
+			name: "triangle",
+			g:    NewTriangleFlat(XYZ, []float64{0, 0, 0, 1, 0, 1, 0, 1, 2, 0, 0, 0}, []int{12}),
+			want: &Stub{
+				Type:        "Triangle",
+				Layout:      XYZ,
+				Bounds:      NewBounds(XYZ).Set(0, 0, 0, 1, 1, 2),
+				NumVertices: 4,
+			},
+		},
+		{
+			name: "polyhedral_surface",
+			g: NewPolyhedralSurfaceFlat(XY, []float64{
+				0, 0, 1, 0, 1, 1, 0, 0,
+				1, 0, 2, 0, 2, 1, 1, 0,
+			}, [][]int{{8}, {16}}).SetSRID(4326),
+			want: &Stub{
+				Type:        "PolyhedralSurface",
+				Layout:      XY,
+				SRID:        4326,
+				Bounds:      NewBounds(XY).Set(0, 0, 2, 1),
+				NumVertices: 8,
+			},
+		},
+		{
+			name: "tin",
+			g:    NewTINFlat(XY, []float64{0, 0, 1, 0, 0, 1, 0, 0}, [][]int{{8}}),
+			want: &Stub{
+				Type:        "TIN",
+				Layout:      XY,
+				Bounds:      NewBounds(XY).Set(0, 0, 1, 1),
+				NumVertices: 4,
+			},
+		},
+		{
+			name: "empty_geometry_collection",
+			g:    NewGeometryCollection(),
+			want: &Stub{
+				Type:   "GeometryCollection",
+				Layout: NoLayout,
+				Bounds: NewBounds(NoLayout),
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := NewStub(tc.g)
+			if err != nil {
+				t.Fatalf("NewStub(%v) == %v, %v, want ..., nil", tc.g, got, err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("NewStub(%v) == %+v, nil, want %+v, nil", tc.g, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,242 @@
+package tile
+
+import "github.com/twpayne/go-geom"
+
+// Clip returns g clipped to bounds, which typically are the bounds of a tile
+// plus a buffer. Points outside bounds are dropped. Lines are cut where they
+// cross the edges of bounds, so a LineString that leaves and re-enters bounds
+// becomes a MultiLineString. Polygon rings are clipped with the
+// Sutherland-Hodgman algorithm, which may leave zero-width slivers along the
+// edges of bounds that Snap later removes. Ordinates after X and Y are
+// interpolated linearly. If nothing is left of g, Clip returns nil.
+func Clip(g geom.T, bounds *geom.Bounds) (geom.T, error) {
+	if bounds.IsEmpty() {
+		return nil, ErrEmptyBounds
+	}
+	c := &clipper{
+		min: [2]float64{bounds.Min(0), bounds.Min(1)},
+		max: [2]float64{bounds.Max(0), bounds.Max(1)},
+	}
+	return c.clip(g)
+}
+
+// A clipper clips geometries to a rectangle.
+type clipper struct {
+	min, max [2]float64
+}
+
+func (c *clipper) clip(g geom.T) (geom.T, error) {
+	switch g := g.(type) {
+	case *geom.Point:
+		if g.Empty() || !c.contains(g.FlatCoords()) {
+			return nil, nil
+		}
+		return g, nil
+	case *geom.MultiPoint:
+		var flatCoords []float64
+		for i, stride := 0, g.Stride(); i < len(g.FlatCoords()); i += stride {
+			if coord := g.FlatCoords()[i : i+stride]; c.contains(coord) {
+				flatCoords = append(flatCoords, coord...)
+			}
+		}
+		if len(flatCoords) == 0 {
+			return nil, nil
+		}
+		return geom.NewMultiPointFlat(g.Layout(), flatCoords).SetSRID(g.SRID()), nil
+	case *geom.LineString:
+		flatCoords, ends := c.clipLine(nil, nil, g.FlatCoords(), g.Stride())
+		switch len(ends) {
+		case 0:
+			return nil, nil
+		case 1:
+			return geom.NewLineStringFlat(g.Layout(), flatCoords).SetSRID(g.SRID()), nil
+		default:
+			return geom.NewMultiLineStringFlat(g.Layout(), flatCoords, ends).SetSRID(g.SRID()), nil
+		}
+	case *geom.MultiLineString:
+		var flatCoords []float64
+		var ends []int
+		for it := g.EndsIter(); it.Next(); {
+			flatCoords, ends = c.clipLine(flatCoords, ends, it.FlatCoords(), g.Stride())
+		}
+		if len(ends) == 0 {
+			return nil, nil
+		}
+		return geom.NewMultiLineStringFlat(g.Layout(), flatCoords, ends).SetSRID(g.SRID()), nil
+	case *geom.Polygon:
+		flatCoords, ends := c.clipPolygon(nil, g.EndsIter(), g.Stride())
+		if len(ends) == 0 {
+			return nil, nil
+		}
+		return geom.NewPolygonFlat(g.Layout(), flatCoords, ends).SetSRID(g.SRID()), nil
+	case *geom.MultiPolygon:
+		var flatCoords []float64
+		var endss [][]int
+		for it := g.EndssIter(); it.Next(); {
+			var ends []int
+			if flatCoords, ends = c.clipPolygon(flatCoords, it.EndsIter(), g.Stride()); len(ends) > 0 {
+				endss = append(endss, ends)
+			}
+		}
+		if len(endss) == 0 {
+			return nil, nil
+		}
+		return geom.NewMultiPolygonFlat(g.Layout(), flatCoords, endss).SetSRID(g.SRID()), nil
+	case *geom.GeometryCollection:
+		gc := geom.NewGeometryCollection()
+		for _, member := range g.Geoms() {
+			clipped, err := c.clip(member)
+			if err != nil {
+				return nil, err
+			}
+			if clipped != nil {
+				gc.MustPush(clipped)
+			}
+		}
+		if gc.Empty() {
+			return nil, nil
+		}
+		return gc.SetSRID(g.SRID()), nil
+	default:
+		return nil, geom.ErrUnsupportedType{Value: g}
+	}
+}
+
+// contains returns whether coord is inside the rectangle, including on its
+// edges.
+func (c *clipper) contains(coord []float64) bool {
+	return c.min[0] <= coord[0] && coord[0] <= c.max[0] && c.min[1] <= coord[1] && coord[1] <= c.max[1]
+}
+
+// clipLine appends the parts of the line flatCoords inside the rectangle to
+// dst and their ends to ends.
+func (c *clipper) clipLine(dst []float64, ends []int, flatCoords []float64, stride int) ([]float64, []int) {
+	start := len(dst)
+	for i := stride; i < len(flatCoords); i += stride {
+		a, b := flatCoords[i-stride:i], flatCoords[i:i+stride]
+		t0, t1, ok := c.clipSegment(a, b)
+		if !ok || t0 == t1 && len(dst) == start {
+			continue
+		}
+		if len(dst) == start {
+			dst = appendInterpolated(dst, a, b, t0)
+		}
+		if t1 > t0 {
+			dst = appendInterpolated(dst, a, b, t1)
+		}
+		if t1 < 1 {
+			// The segment leaves the rectangle, so the part ends here.
+			ends = append(ends, len(dst))
+			start = len(dst)
+		}
+	}
+	if len(dst) > start {
+		ends = append(ends, len(dst))
+	}
+	return dst, ends
+}
+
+// clipSegment returns the parameters t0 and t1 of the part of the segment
+// from a to b inside the rectangle, using the Liang-Barsky algorithm. ok is
+// false if the segment is entirely outside.
+func (c *clipper) clipSegment(a, b []float64) (t0, t1 float64, ok bool) {
+	t0, t1 = 0, 1
+	for dim := 0; dim < 2; dim++ {
+		d := b[dim] - a[dim]
+		for _, pq := range [2][2]float64{
+			{-d, a[dim] - c.min[dim]},
+			{d, c.max[dim] - a[dim]},
+		} {
+			p, q := pq[0], pq[1]
+			switch {
+			case p == 0:
+				if q < 0 {
+					return 0, 0, false
+				}
+			case p < 0:
+				r := q / p
+				if r > t1 {
+					return 0, 0, false
+				}
+				if r > t0 {
+					t0 = r
+				}
+			default:
+				r := q / p
+				if r < t0 {
+					return 0, 0, false
+				}
+				if r < t1 {
+					t1 = r
+				}
+			}
+		}
+	}
+	return t0, t1, true
+}
+
+// clipPolygon appends the clipped rings of a polygon to dst and returns them
+// with their ends. Rings that are entirely outside the rectangle are dropped
+// and, if the exterior ring is dropped, so is the whole polygon.
+func (c *clipper) clipPolygon(dst []float64, it geom.EndsIter, stride int) ([]float64, []int) {
+	start := len(dst)
+	var ends []int
+	for it.Next() {
+		ringFlatCoords := it.FlatCoords()
+		if len(ringFlatCoords) < stride {
+			continue
+		}
+		ring := ringFlatCoords[:len(ringFlatCoords)-stride]
+		for dim := 0; dim < 2; dim++ {
+			ring = clipHalfPlane(ring, stride, dim, c.min[dim], 1)
+			ring = clipHalfPlane(ring, stride, dim, c.max[dim], -1)
+		}
+		if len(ring) < 3*stride {
+			if it.Index() == 0 {
+				return dst[:start], nil
+			}
+			continue
+		}
+		dst = append(dst, ring...)
+		dst = append(dst, ring[:stride]...)
+		ends = append(ends, len(dst))
+	}
+	return dst, ends
+}
+
+// clipHalfPlane returns ring, an open ring without a closing coordinate,
+// clipped to the half plane sign*(ring[dim]-value) >= 0.
+func clipHalfPlane(ring []float64, stride, dim int, value, sign float64) []float64 {
+	var result []float64
+	n := len(ring)
+	for i := 0; i < n; i += stride {
+		j := (i + n - stride) % n
+		a, b := ring[j:j+stride], ring[i:i+stride]
+		aInside := sign*(a[dim]-value) >= 0
+		bInside := sign*(b[dim]-value) >= 0
+		if aInside != bInside {
+			result = appendInterpolated(result, a, b, (value-a[dim])/(b[dim]-a[dim]))
+			// Avoid rounding errors putting the intersection just outside.
+			result[len(result)-stride+dim] = value
+		}
+		if bInside {
+			result = append(result, b...)
+		}
+	}
+	return result
+}
+
+// appendInterpolated appends the coordinate at t along the segment from a
+// to b to dst.
+func appendInterpolated(dst, a, b []float64, t float64) []float64 {
+	switch t {
+	case 0:
+		return append(dst, a...)
+	case 1:
+		return append(dst, b...)
+	}
+	for i := range a {
+		dst = append(dst, a[i]+t*(b[i]-a[i]))
+	}
+	return dst
+}
@@ -0,0 +1,254 @@
+// Package tile contains functions for preparing geometries for vector tiles.
+//
+// Vector tiles, such as Mapbox Vector Tiles, store coordinates as integers
+// on a grid of extent×extent cells covering the tile, with the origin at the
+// top left and Y increasing downwards. Snapping geometries to this grid can
+// create zero-length segments, collinear vertices, and geometries that
+// collapse entirely, which Snap removes. Geometries are first clipped to the
+// tile, plus a buffer, with Clip.
+package tile
+
+import (
+	"errors"
+	"math"
+
+	"github.com/twpayne/go-geom"
+)
+
+// DefaultExtent is the default extent of a tile, as used by Mapbox Vector
+// Tiles.
+const DefaultExtent = 4096
+
+// ErrEmptyBounds is returned when the bounds of a tile have no area.
+var ErrEmptyBounds = errors.New("tile: empty bounds")
+
+// Snap returns g scaled from bounds to the integer grid of a tile with
+// extent×extent cells, with Y flipped so that the maximum Y of bounds maps
+// to zero. Coordinates outside bounds map outside the tile, so geometries
+// should be clipped to bounds, plus any buffer, first.
+//
+// Consecutive duplicate coordinates and vertices on straight lines between
+// their neighbors are removed, as are zero-area spikes in polygon rings.
+// Lines that collapse to a point and rings that collapse to zero area are
+// dropped, as are polygons whose exterior ring is dropped. If nothing is left
+// of g, Snap returns nil. Only the X and Y coordinates of g are kept, and the
+// result has no SRID.
+func Snap(g geom.T, bounds *geom.Bounds, extent int) (geom.T, error) {
+	s, err := newSnapper(bounds, extent)
+	if err != nil {
+		return nil, err
+	}
+	return s.snap(g)
+}
+
+// A snapper snaps coordinates to the grid of a tile.
+type snapper struct {
+	minX, maxY     float64
+	scaleX, scaleY float64
+}
+
+func newSnapper(bounds *geom.Bounds, extent int) (*snapper, error) {
+	if bounds.IsEmpty() {
+		return nil, ErrEmptyBounds
+	}
+	width, height := bounds.Max(0)-bounds.Min(0), bounds.Max(1)-bounds.Min(1)
+	if width <= 0 || height <= 0 {
+		return nil, ErrEmptyBounds
+	}
+	return &snapper{
+		minX:   bounds.Min(0),
+		maxY:   bounds.Max(1),
+		scaleX: float64(extent) / width,
+		scaleY: float64(extent) / height,
+	}, nil
+}
+
+func (s *snapper) snap(g geom.T) (geom.T, error) {
+	switch g := g.(type) {
+	case *geom.Point:
+		if g.Empty() {
+			return nil, nil
+		}
+		return geom.NewPointFlat(geom.XY, s.snapCoords(nil, g.FlatCoords(), g.Stride())), nil
+	case *geom.MultiPoint:
+		flatCoords := s.snapCoords(nil, g.FlatCoords(), g.Stride())
+		if len(flatCoords) == 0 {
+			return nil, nil
+		}
+		return geom.NewMultiPointFlat(geom.XY, flatCoords), nil
+	case *geom.LineString:
+		flatCoords := s.snapLine(nil, g.FlatCoords(), g.Stride())
+		if flatCoords == nil {
+			return nil, nil
+		}
+		return geom.NewLineStringFlat(geom.XY, flatCoords), nil
+	case *geom.MultiLineString:
+		var flatCoords []float64
+		var ends []int
+		for it := g.EndsIter(); it.Next(); {
+			if fcs := s.snapLine(flatCoords, it.FlatCoords(), g.Stride()); fcs != nil {
+				flatCoords = fcs
+				ends = append(ends, len(flatCoords))
+			}
+		}
+		if len(ends) == 0 {
+			return nil, nil
+		}
+		return geom.NewMultiLineStringFlat(geom.XY, flatCoords, ends), nil
+	case *geom.Polygon:
+		flatCoords, ends := s.snapPolygon(nil, g.EndsIter(), g.Stride())
+		if len(ends) == 0 {
+			return nil, nil
+		}
+		return geom.NewPolygonFlat(geom.XY, flatCoords, ends), nil
+	case *geom.MultiPolygon:
+		var flatCoords []float64
+		var endss [][]int
+		for it := g.EndssIter(); it.Next(); {
+			var ends []int
+			if flatCoords, ends = s.snapPolygon(flatCoords, it.EndsIter(), g.Stride()); len(ends) > 0 {
+				endss = append(endss, ends)
+			}
+		}
+		if len(endss) == 0 {
+			return nil, nil
+		}
+		return geom.NewMultiPolygonFlat(geom.XY, flatCoords, endss), nil
+	case *geom.GeometryCollection:
+		gc := geom.NewGeometryCollection()
+		for _, member := range g.Geoms() {
+			snapped, err := s.snap(member)
+			if err != nil {
+				return nil, err
+			}
+			if snapped != nil {
+				gc.MustPush(snapped)
+			}
+		}
+		if gc.Empty() {
+			return nil, nil
+		}
+		return gc, nil
+	default:
+		return nil, geom.ErrUnsupportedType{Value: g}
+	}
+}
+
+// snapCoords appends the snapped X and Y coordinates of flatCoords to dst.
+func (s *snapper) snapCoords(dst, flatCoords []float64, stride int) []float64 {
+	for i := 0; i < len(flatCoords); i += stride {
+		dst = append(dst,
+			math.Round((flatCoords[i]-s.minX)*s.scaleX),
+			math.Round((s.maxY-flatCoords[i+1])*s.scaleY),
+		)
+	}
+	return dst
+}
+
+// snapLine appends the snapped line flatCoords, with duplicate and collinear
+// vertices removed, to dst. It returns nil if the line collapses to a point.
+func (s *snapper) snapLine(dst, flatCoords []float64, stride int) []float64 {
+	start := len(dst)
+	for i := 0; i < len(flatCoords); i += stride {
+		x := math.Round((flatCoords[i] - s.minX) * s.scaleX)
+		y := math.Round((s.maxY - flatCoords[i+1]) * s.scaleY)
+		dst = appendVertex(dst, start, x, y, false)
+	}
+	if len(dst)-start < 4 {
+		return nil
+	}
+	return dst
+}
+
+// snapPolygon appends the snapped rings of a polygon to dst and returns them
+// with their ends. Rings that collapse are dropped and, if the exterior ring
+// collapses, so is the whole polygon.
+func (s *snapper) snapPolygon(dst []float64, it geom.EndsIter, stride int) ([]float64, []int) {
+	start := len(dst)
+	var ends []int
+	for it.Next() {
+		ringStart := len(dst)
+		ringFlatCoords := it.FlatCoords()
+		// Skip the closing coordinate, which is added back once the ring
+		// has been cleaned up.
+		for i := 0; i < len(ringFlatCoords)-stride; i += stride {
+			x := math.Round((ringFlatCoords[i] - s.minX) * s.scaleX)
+			y := math.Round((s.maxY - ringFlatCoords[i+1]) * s.scaleY)
+			dst = appendVertex(dst, ringStart, x, y, true)
+		}
+		dst = cleanRingStart(dst, ringStart)
+		if len(dst)-ringStart < 6 || ringDoubleArea(dst[ringStart:]) == 0 {
+			if it.Index() == 0 {
+				return dst[:start], nil
+			}
+			dst = dst[:ringStart]
+			continue
+		}
+		dst = append(dst, dst[ringStart], dst[ringStart+1])
+		ends = append(ends, len(dst))
+	}
+	return dst, ends
+}
+
+// appendVertex appends (x, y) to the vertices of the line or open ring
+// starting at start in dst, unless it duplicates the last vertex. Vertices
+// that become collinear with their neighbors are removed. In lines, only
+// vertices between their neighbors are removed, so the extent of the line
+// is preserved, whereas in rings zero-area spikes are also removed.
+func appendVertex(dst []float64, start int, x, y float64, ring bool) []float64 {
+	if n := len(dst); n-start >= 2 && dst[n-2] == x && dst[n-1] == y {
+		return dst
+	}
+	for n := len(dst); n-start >= 4; n = len(dst) {
+		ax, ay, bx, by := dst[n-4], dst[n-3], dst[n-2], dst[n-1]
+		if !collinear(ax, ay, bx, by, x, y, ring) {
+			break
+		}
+		dst = dst[:n-2]
+		if n-start-2 >= 2 && ax == x && ay == y {
+			// Removing a spike brought the ring back to (x, y).
+			return dst
+		}
+	}
+	return append(dst, x, y)
+}
+
+// cleanRingStart removes the collinear vertices of the open ring starting at
+// start in dst that span its end and its start, which appendVertex cannot
+// see.
+func cleanRingStart(dst []float64, start int) []float64 {
+	for {
+		n := len(dst)
+		if n-start < 6 {
+			return dst
+		}
+		switch {
+		case collinear(dst[n-4], dst[n-3], dst[n-2], dst[n-1], dst[start], dst[start+1], true):
+			dst = dst[:n-2]
+		case collinear(dst[n-2], dst[n-1], dst[start], dst[start+1], dst[start+2], dst[start+3], true):
+			dst = append(dst[:start], dst[start+2:]...)
+		default:
+			return dst
+		}
+	}
+}
+
+// collinear returns whether b is on the straight line through a and c. If
+// spikes is false, b must also be between a and c.
+func collinear(ax, ay, bx, by, cx, cy float64, spikes bool) bool {
+	if (bx-ax)*(cy-ay)-(by-ay)*(cx-ax) != 0 {
+		return false
+	}
+	return spikes || (bx-ax)*(cx-bx)+(by-ay)*(cy-by) > 0
+}
+
+// ringDoubleArea returns twice the signed area of the open ring flatCoords.
+func ringDoubleArea(flatCoords []float64) float64 {
+	area := 0.0
+	n := len(flatCoords)
+	for i := 0; i < n; i += 2 {
+		j := (i + 2) % n
+		area += flatCoords[i]*flatCoords[j+1] - flatCoords[j]*flatCoords[i+1]
+	}
+	return area
+}
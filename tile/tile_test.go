@@ -0,0 +1,222 @@
+package tile
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+)
+
+func TestSnap(t *testing.T) {
+	bounds := geom.NewBounds(geom.XY).Set(0, 0, 1, 1)
+	for _, tc := range []struct {
+		name string
+		g    geom.T
+		want geom.T
+	}{
+		{
+			name: "point",
+			g:    geom.NewPointFlat(geom.XYZ, []float64{0.26, 0.74, 100}).SetSRID(4326),
+			want: geom.NewPointFlat(geom.XY, []float64{3, 3}),
+		},
+		{
+			name: "empty_point",
+			g:    geom.NewPointEmpty(geom.XY),
+		},
+		{
+			name: "multi_point",
+			g:    geom.NewMultiPointFlat(geom.XY, []float64{0, 0, 1, 1}),
+			want: geom.NewMultiPointFlat(geom.XY, []float64{0, 10, 10, 0}),
+		},
+		{
+			name: "line_string",
+			g:    geom.NewLineStringFlat(geom.XY, []float64{0, 0, 0.01, 0, 0.5, 0, 1, 0, 1, 0.5}),
+			want: geom.NewLineStringFlat(geom.XY, []float64{0, 10, 10, 10, 10, 5}),
+		},
+		{
+			name: "line_string_backtrack",
+			g:    geom.NewLineStringFlat(geom.XY, []float64{0, 0, 1, 0, 0.5, 0}),
+			want: geom.NewLineStringFlat(geom.XY, []float64{0, 10, 10, 10, 5, 10}),
+		},
+		{
+			name: "line_string_collapse",
+			g:    geom.NewLineStringFlat(geom.XY, []float64{0, 0, 0.01, 0.01}),
+		},
+		{
+			name: "multi_line_string",
+			g: geom.NewMultiLineStringFlat(geom.XY, []float64{
+				0, 0, 0.01, 0.01,
+				0, 0, 1, 1,
+			}, []int{4, 8}),
+			want: geom.NewMultiLineStringFlat(geom.XY, []float64{0, 10, 10, 0}, []int{4}),
+		},
+		{
+			name: "polygon",
+			g: geom.NewPolygonFlat(geom.XY, []float64{
+				0, 0, 0.5, 0, 1, 0, 1, 1, 0, 1, 0, 0,
+				0.4, 0.4, 0.41, 0.4, 0.41, 0.41, 0.4, 0.4,
+			}, []int{12, 20}),
+			want: geom.NewPolygonFlat(geom.XY, []float64{0, 10, 10, 10, 10, 0, 0, 0, 0, 10}, []int{10}),
+		},
+		{
+			name: "polygon_collinear_start",
+			g:    geom.NewPolygonFlat(geom.XY, []float64{0.5, 0, 1, 0, 1, 1, 0, 1, 0, 0, 0.5, 0}, []int{12}),
+			want: geom.NewPolygonFlat(geom.XY, []float64{10, 10, 10, 0, 0, 0, 0, 10, 10, 10}, []int{10}),
+		},
+		{
+			name: "polygon_spike",
+			g:    geom.NewPolygonFlat(geom.XY, []float64{0, 0, 1, 0, 1, 1, 0.5, 1, 0.5, 0.5, 0.5, 1, 0, 1, 0, 0}, []int{16}),
+			want: geom.NewPolygonFlat(geom.XY, []float64{0, 10, 10, 10, 10, 0, 0, 0, 0, 10}, []int{10}),
+		},
+		{
+			name: "polygon_collapse",
+			g:    geom.NewPolygonFlat(geom.XY, []float64{0, 0, 1, 0, 1, 0.01, 0, 0}, []int{8}),
+		},
+		{
+			name: "multi_polygon",
+			g: geom.NewMultiPolygonFlat(geom.XYM, []float64{
+				0, 0, 1, 1, 0, 1, 1, 1, 1, 0, 0, 1,
+				0, 0, 1, 0.5, 0, 1, 1, 0, 1, 0, 0, 1,
+			}, [][]int{{12}, {24}}),
+			want: geom.NewMultiPolygonFlat(geom.XY, []float64{0, 10, 10, 10, 10, 0, 0, 10}, [][]int{{8}}),
+		},
+		{
+			name: "geometry_collection",
+			g: geom.NewGeometryCollection().MustPush(
+				geom.NewLineStringFlat(geom.XY, []float64{0, 0, 0.01, 0.01}),
+				geom.NewPointFlat(geom.XY, []float64{1, 1}),
+			),
+			want: geom.NewGeometryCollection().MustPush(
+				geom.NewPointFlat(geom.XY, []float64{10, 0}),
+			),
+		},
+		{
+			name: "geometry_collection_collapse",
+			g: geom.NewGeometryCollection().MustPush(
+				geom.NewLineStringFlat(geom.XY, []float64{0, 0, 0.01, 0.01}),
+			),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Snap(tc.g, bounds, 10)
+			if err != nil {
+				t.Fatalf("Snap(...) == _, %v, want _, <nil>", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Snap(...) == %#v, want %#v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSnapEmptyBounds(t *testing.T) {
+	g := geom.NewPointFlat(geom.XY, []float64{0, 0})
+	for _, bounds := range []*geom.Bounds{
+		geom.NewBounds(geom.XY),
+		geom.NewBounds(geom.XY).Set(0, 0, 0, 1),
+	} {
+		if _, err := Snap(g, bounds, DefaultExtent); err != ErrEmptyBounds {
+			t.Errorf("Snap(g, %v, DefaultExtent) == _, %v, want _, %v", bounds, err, ErrEmptyBounds)
+		}
+	}
+}
+
+func TestClip(t *testing.T) {
+	bounds := geom.NewBounds(geom.XY).Set(0, 0, 10, 10)
+	for _, tc := range []struct {
+		name string
+		g    geom.T
+		want geom.T
+	}{
+		{
+			name: "point_inside",
+			g:    geom.NewPointFlat(geom.XY, []float64{10, 5}).SetSRID(4326),
+			want: geom.NewPointFlat(geom.XY, []float64{10, 5}).SetSRID(4326),
+		},
+		{
+			name: "point_outside",
+			g:    geom.NewPointFlat(geom.XY, []float64{11, 5}),
+		},
+		{
+			name: "multi_point",
+			g:    geom.NewMultiPointFlat(geom.XYZ, []float64{1, 2, 3, -1, 2, 3, 4, 5, 6}),
+			want: geom.NewMultiPointFlat(geom.XYZ, []float64{1, 2, 3, 4, 5, 6}),
+		},
+		{
+			name: "line_string_inside",
+			g:    geom.NewLineStringFlat(geom.XY, []float64{1, 1, 9, 9}),
+			want: geom.NewLineStringFlat(geom.XY, []float64{1, 1, 9, 9}),
+		},
+		{
+			name: "line_string_crossing",
+			g:    geom.NewLineStringFlat(geom.XYM, []float64{-5, 5, 0, 5, 5, 10, 15, 5, 20}),
+			want: geom.NewLineStringFlat(geom.XYM, []float64{0, 5, 5, 5, 5, 10, 10, 5, 15}),
+		},
+		{
+			name: "line_string_reentering",
+			g:    geom.NewLineStringFlat(geom.XY, []float64{2, 5, 12, 5, 12, 8, 8, 8}),
+			want: geom.NewMultiLineStringFlat(geom.XY, []float64{2, 5, 10, 5, 10, 8, 8, 8}, []int{4, 8}),
+		},
+		{
+			name: "line_string_outside",
+			g:    geom.NewLineStringFlat(geom.XY, []float64{-5, -5, -1, 20}),
+		},
+		{
+			name: "line_string_touching_corner",
+			g:    geom.NewLineStringFlat(geom.XY, []float64{-5, 5, 5, -5}),
+		},
+		{
+			name: "multi_line_string",
+			g: geom.NewMultiLineStringFlat(geom.XY, []float64{
+				-5, -5, -1, -1,
+				-5, 5, 15, 5,
+			}, []int{4, 8}),
+			want: geom.NewMultiLineStringFlat(geom.XY, []float64{0, 5, 10, 5}, []int{4}),
+		},
+		{
+			name: "polygon_inside",
+			g:    geom.NewPolygonFlat(geom.XY, []float64{1, 1, 9, 1, 9, 9, 1, 1}, []int{8}),
+			want: geom.NewPolygonFlat(geom.XY, []float64{1, 1, 9, 1, 9, 9, 1, 1}, []int{8}),
+		},
+		{
+			name: "polygon_crossing",
+			g: geom.NewPolygonFlat(geom.XY, []float64{
+				5, 5, 15, 5, 15, 15, 5, 15, 5, 5,
+				20, 20, 21, 20, 21, 21, 20, 20,
+			}, []int{10, 18}),
+			want: geom.NewPolygonFlat(geom.XY, []float64{5, 10, 5, 5, 10, 5, 10, 10, 5, 10}, []int{10}),
+		},
+		{
+			name: "polygon_outside",
+			g:    geom.NewPolygonFlat(geom.XY, []float64{11, 11, 12, 11, 12, 12, 11, 11}, []int{8}),
+		},
+		{
+			name: "multi_polygon",
+			g: geom.NewMultiPolygonFlat(geom.XY, []float64{
+				11, 11, 12, 11, 12, 12, 11, 11,
+				-1, -1, 1, -1, 1, 1, -1, 1, -1, -1,
+			}, [][]int{{8}, {18}}),
+			want: geom.NewMultiPolygonFlat(geom.XY, []float64{0, 0, 1, 0, 1, 1, 0, 1, 0, 0}, [][]int{{10}}),
+		},
+		{
+			name: "geometry_collection",
+			g: geom.NewGeometryCollection().MustPush(
+				geom.NewPointFlat(geom.XY, []float64{11, 11}),
+				geom.NewPointFlat(geom.XY, []float64{1, 1}),
+			),
+			want: geom.NewGeometryCollection().MustPush(
+				geom.NewPointFlat(geom.XY, []float64{1, 1}),
+			),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Clip(tc.g, bounds)
+			if err != nil {
+				t.Fatalf("Clip(...) == _, %v, want _, <nil>", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Clip(...) == %#v, want %#v", got, tc.want)
+			}
+		})
+	}
+}
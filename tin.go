@@ -0,0 +1,106 @@
+package geom
+
+// A TIN is a triangulated irregular network, a PolyhedralSurface whose
+// patches are all Triangles.
+type TIN struct {
+	geom3
+}
+
+// NewTIN returns a new TIN with no Triangles.
+func NewTIN(layout Layout) *TIN {
+	return NewTINFlat(layout, nil, nil)
+}
+
+// NewTINFlat returns a new TIN with the given flat coordinates.
+func NewTINFlat(layout Layout, flatCoords []float64, endss [][]int) *TIN {
+	g := new(TIN)
+	g.layout = layout
+	g.stride = layout.Stride()
+	g.flatCoords = flatCoords
+	g.endss = endss
+	return g
+}
+
+// Area returns the sum of the signed areas of the projections of the
+// Triangles onto the XY plane.
+func (g *TIN) Area() float64 {
+	return doubleArea3(g.flatCoords, 0, g.endss, g.stride) / 2
+}
+
+// Clone returns a deep copy.
+func (g *TIN) Clone() *TIN {
+	return NewTINFlat(g.layout, append([]float64(nil), g.flatCoords...), cloneEndss(g.endss)).SetSRID(g.srid)
+}
+
+// Empty returns true if g has no Triangles.
+func (g *TIN) Empty() bool {
+	return g.NumTriangles() == 0
+}
+
+// Length returns the sum of the perimeters of the Triangles.
+func (g *TIN) Length() float64 {
+	return length3(g.flatCoords, 0, g.endss, g.stride)
+}
+
+// MustSetCoords sets the coordinates and panics on any error.
+func (g *TIN) MustSetCoords(coords [][][]Coord) *TIN {
+	Must(g.SetCoords(coords))
+	return g
+}
+
+// NumTriangles returns the number of Triangles.
+func (g *TIN) NumTriangles() int {
+	return len(g.endss)
+}
+
+// Push appends a Triangle.
+func (g *TIN) Push(t *Triangle) error {
+	if t.layout != g.layout {
+		return ErrLayoutMismatch{Got: t.layout, Want: g.layout}
+	}
+	g.pushPolygon(t.flatCoords, t.ends)
+	return nil
+}
+
+// SetCoords sets the coordinates. Each element of coords must contain a
+// single closed ring of four coordinates, otherwise an ErrInvalidRing is
+// returned.
+func (g *TIN) SetCoords(coords [][][]Coord) (*TIN, error) {
+	for i, triangleCoords := range coords {
+		if len(triangleCoords) == 0 {
+			return nil, ErrInvalidRing{Path: Path{i, 0}, Reason: "empty"}
+		}
+		if err := checkTriangle(g.layout, triangleCoords); err != nil {
+			e := err.(ErrInvalidRing)
+			e.Path = append(Path{i}, e.Path...)
+			return nil, e
+		}
+	}
+	if err := g.setCoords(coords); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// SetSRID sets the SRID of g.
+func (g *TIN) SetSRID(srid int) *TIN {
+	g.srid = srid
+	return g
+}
+
+// SurfaceArea returns the sum of the areas of the Triangles in three
+// dimensions, using their Z values if they have any.
+func (g *TIN) SurfaceArea() float64 {
+	return surfaceArea3(g.flatCoords, 0, g.endss, g.stride, g.layout.ZIndex())
+}
+
+// Swap swaps the values of g and g2.
+func (g *TIN) Swap(g2 *TIN) {
+	*g, *g2 = *g2, *g
+}
+
+// Triangle returns the ith Triangle.
+func (g *TIN) Triangle(i int) *Triangle {
+	flatCoords, ends := g.polygon(i)
+	return NewTriangleFlat(g.layout, flatCoords, ends).SetSRID(g.srid)
+}
@@ -0,0 +1,76 @@
+package geom
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestTIN(t *testing.T) {
+	coords := [][][]Coord{
+		{{{0, 0, 0}, {1, 0, 0}, {0, 1, 1}, {0, 0, 0}}},
+		{{{1, 0, 0}, {1, 1, 1}, {0, 1, 1}, {1, 0, 0}}},
+	}
+	tin := NewTIN(XYZ).MustSetCoords(coords)
+	if err := tin.verify(); err != nil {
+		t.Error(err)
+	}
+	if got, want := tin.NumTriangles(), 2; got != want {
+		t.Errorf("NumTriangles() == %d, want %d", got, want)
+	}
+	if got, want := tin.Bounds(), NewBounds(XYZ).Set(0, 0, 0, 1, 1, 1); !reflect.DeepEqual(got, want) {
+		t.Errorf("Bounds() == %v, want %v", got, want)
+	}
+	if got, want := tin.Area(), 1.0; got != want {
+		t.Errorf("Area() == %v, want %v", got, want)
+	}
+	if got, want := tin.SurfaceArea(), math.Sqrt2; math.Abs(got-want) > 1e-15 {
+		t.Errorf("SurfaceArea() == %v, want %v", got, want)
+	}
+	for i, c := range coords {
+		want := NewTriangle(XYZ).MustSetCoords(c)
+		if got := tin.Triangle(i); !reflect.DeepEqual(got, want) {
+			t.Errorf("Triangle(%d) == %v, want %v", i, got, want)
+		}
+	}
+	tin2 := NewTIN(XYZ)
+	for i := 0; i < tin.NumTriangles(); i++ {
+		if err := tin2.Push(tin.Triangle(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !reflect.DeepEqual(tin2, tin) {
+		t.Errorf("Push(...) == %v, want %v", tin2, tin)
+	}
+	if clone := tin.Clone(); !reflect.DeepEqual(clone, tin) {
+		t.Errorf("Clone() == %v, want %v", clone, tin)
+	}
+}
+
+func TestTINSetCoordsErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		coords [][][]Coord
+		want   error
+	}{
+		{
+			name:   "empty",
+			coords: [][][]Coord{{}},
+			want:   ErrInvalidRing{Path: Path{0, 0}, Reason: "empty"},
+		},
+		{
+			name: "square",
+			coords: [][][]Coord{
+				{{{0, 0}, {1, 0}, {0, 1}, {0, 0}}},
+				{{{0, 0}, {1, 0}, {1, 1}, {0, 1}, {0, 0}}},
+			},
+			want: ErrInvalidRing{Path: Path{1, 0}, Reason: "not a closed ring of four coordinates"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := NewTIN(XY).SetCoords(tc.coords); !reflect.DeepEqual(err, tc.want) {
+				t.Errorf("SetCoords(%v) == _, %v, want _, %v", tc.coords, err, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+// Package track contains functions for GPS tracks, represented as
+// LineStrings whose coordinates are longitudes and latitudes in degrees and
+// whose M ordinates are the times of the fixes.
+//
+// Times may be in any unit, for example seconds since the Unix epoch, and
+// speeds are in meters per unit of time.
+package track
+
+import (
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/geo"
+)
+
+// Thresholds control where Split splits a track. Zero values disable the
+// corresponding threshold.
+type Thresholds struct {
+	// MaxGap is the maximum time between consecutive fixes.
+	MaxGap float64
+	// MaxSpeed is the maximum speed implied by the distance and time between
+	// consecutive fixes.
+	MaxSpeed float64
+	// Distance measures the distance between fixes. If it is nil,
+	// geo.Distance is used.
+	Distance geo.DistanceFunc
+}
+
+// Split splits ls wherever the time gap or the implied speed between
+// consecutive fixes exceeds thresholds, and wherever time goes backwards. It
+// returns the resulting tracks as a MultiLineString with the layout and SRID
+// of ls. Tracks with a single fix are dropped, as a LineString needs at least
+// two coordinates. Split returns a geom.ErrUnsupportedLayout if ls has no M
+// ordinate.
+func Split(ls *geom.LineString, thresholds Thresholds) (*geom.MultiLineString, error) {
+	layout := ls.Layout()
+	mIndex := layout.MIndex()
+	if mIndex == -1 {
+		return nil, geom.ErrUnsupportedLayout(layout)
+	}
+	distance := thresholds.Distance
+	if distance == nil {
+		distance = geo.Distance
+	}
+	flatCoords := ls.FlatCoords()
+	stride := ls.Stride()
+
+	mls := geom.NewMultiLineString(layout).SetSRID(ls.SRID())
+	start := 0
+	push := func(end int) error {
+		if end-start < 2*stride {
+			return nil
+		}
+		part := append([]float64(nil), flatCoords[start:end]...)
+		return mls.Push(geom.NewLineStringFlat(layout, part))
+	}
+	for i := stride; i < len(flatCoords); i += stride {
+		prev, curr := flatCoords[i-stride:i], flatCoords[i:i+stride]
+		if !exceeds(prev, curr, mIndex, thresholds, distance) {
+			continue
+		}
+		if err := push(i); err != nil {
+			return nil, err
+		}
+		start = i
+	}
+	if err := push(len(flatCoords)); err != nil {
+		return nil, err
+	}
+	return mls, nil
+}
+
+// exceeds reports whether the step from fix a to fix b exceeds thresholds.
+func exceeds(a, b geom.Coord, mIndex int, thresholds Thresholds, distance geo.DistanceFunc) bool {
+	dt := b[mIndex] - a[mIndex]
+	switch {
+	case dt < 0:
+		return true
+	case thresholds.MaxGap > 0 && dt > thresholds.MaxGap:
+		return true
+	case thresholds.MaxSpeed > 0:
+		return distance(a, b) > thresholds.MaxSpeed*dt
+	default:
+		return false
+	}
+}
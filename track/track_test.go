@@ -0,0 +1,81 @@
+package track
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+)
+
+func planarDistance(a, b geom.Coord) float64 {
+	return math.Hypot(b.X()-a.X(), b.Y()-a.Y())
+}
+
+func TestSplit(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		ls         *geom.LineString
+		thresholds Thresholds
+		want       [][]float64
+	}{
+		{
+			name: "no_thresholds",
+			ls:   geom.NewLineStringFlat(geom.XYM, []float64{0, 0, 0, 1, 0, 1, 100, 0, 100}),
+			want: [][]float64{{0, 0, 0, 1, 0, 1, 100, 0, 100}},
+		},
+		{
+			name:       "gap",
+			ls:         geom.NewLineStringFlat(geom.XYM, []float64{0, 0, 0, 1, 0, 1, 2, 0, 61, 3, 0, 62, 4, 0, 63}),
+			thresholds: Thresholds{MaxGap: 30},
+			want:       [][]float64{{0, 0, 0, 1, 0, 1}, {2, 0, 61, 3, 0, 62, 4, 0, 63}},
+		},
+		{
+			name:       "speed",
+			ls:         geom.NewLineStringFlat(geom.XYM, []float64{0, 0, 0, 1, 0, 1, 2, 0, 2, 50, 0, 3, 51, 0, 4, 52, 0, 5}),
+			thresholds: Thresholds{MaxSpeed: 10, Distance: planarDistance},
+			want:       [][]float64{{0, 0, 0, 1, 0, 1, 2, 0, 2}, {50, 0, 3, 51, 0, 4, 52, 0, 5}},
+		},
+		{
+			name:       "single_fixes_dropped",
+			ls:         geom.NewLineStringFlat(geom.XYM, []float64{0, 0, 0, 1, 0, 100, 2, 0, 200, 3, 0, 201}),
+			thresholds: Thresholds{MaxGap: 30},
+			want:       [][]float64{{2, 0, 200, 3, 0, 201}},
+		},
+		{
+			name: "time_backwards",
+			ls:   geom.NewLineStringFlat(geom.XYM, []float64{0, 0, 10, 1, 0, 11, 2, 0, 5, 3, 0, 6}),
+			want: [][]float64{{0, 0, 10, 1, 0, 11}, {2, 0, 5, 3, 0, 6}},
+		},
+		{
+			name:       "xyzm",
+			ls:         geom.NewLineStringFlat(geom.XYZM, []float64{0, 0, 5, 0, 0, 0.001, 5, 1, 0, 0.1, 5, 2}),
+			thresholds: Thresholds{MaxSpeed: 1000},
+			want:       [][]float64{{0, 0, 5, 0, 0, 0.001, 5, 1}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Split(tc.ls.SetSRID(4326), tc.thresholds)
+			if err != nil {
+				t.Fatalf("Split(...) == _, %v, want _, <nil>", err)
+			}
+			if got.Layout() != tc.ls.Layout() || got.SRID() != 4326 {
+				t.Errorf("Split(...) has layout %v and SRID %d, want %v and 4326", got.Layout(), got.SRID(), tc.ls.Layout())
+			}
+			var gotParts [][]float64
+			for i := 0; i < got.NumLineStrings(); i++ {
+				gotParts = append(gotParts, got.LineString(i).FlatCoords())
+			}
+			if !reflect.DeepEqual(gotParts, tc.want) {
+				t.Errorf("Split(...) == %v, want %v", gotParts, tc.want)
+			}
+		})
+	}
+}
+
+func TestSplitUnsupportedLayout(t *testing.T) {
+	ls := geom.NewLineStringFlat(geom.XYZ, []float64{0, 0, 0, 1, 1, 1})
+	if _, err := Split(ls, Thresholds{}); err != geom.ErrUnsupportedLayout(geom.XYZ) {
+		t.Errorf("Split(ls, Thresholds{}) == _, %v, want _, %v", err, geom.ErrUnsupportedLayout(geom.XYZ))
+	}
+}
@@ -0,0 +1,226 @@
+package track
+
+import (
+	"math"
+
+	"github.com/twpayne/go-geom"
+)
+
+// indexChunkSize is the number of segments covered by each box of an
+// indexed track.
+const indexChunkSize = 32
+
+// Window returns the part of ls between times start and end, inclusive, with
+// the layout and SRID of ls. Fixes at start and end are interpolated linearly
+// between the neighboring fixes, if needed. The bounds of the part of the
+// track in the window are the Bounds of the result. Times must not decrease
+// along ls. If ls is not in the window then the result is empty, and if it
+// is in the window at a single instant then the result has a single
+// coordinate. Window returns a geom.ErrUnsupportedLayout if ls has no M
+// ordinate.
+func Window(ls *geom.LineString, start, end float64) (*geom.LineString, error) {
+	layout := ls.Layout()
+	mIndex := layout.MIndex()
+	if mIndex == -1 {
+		return nil, geom.ErrUnsupportedLayout(layout)
+	}
+	flatCoords := ls.FlatCoords()
+	stride := ls.Stride()
+	var window []float64
+	for i := 0; i < len(flatCoords); i += stride {
+		curr := flatCoords[i : i+stride]
+		m := curr[mIndex]
+		var prev []float64
+		if i > 0 {
+			prev = flatCoords[i-stride : i]
+		}
+		if prev != nil && prev[mIndex] < start && start < m {
+			window = appendInterpolated(window, prev, curr, mIndex, start)
+		}
+		if start <= m && m <= end {
+			window = append(window, curr...)
+		}
+		if prev != nil && prev[mIndex] < end && end < m {
+			window = appendInterpolated(window, prev, curr, mIndex, end)
+		}
+	}
+	return geom.NewLineStringFlat(layout, window).SetSRID(ls.SRID()), nil
+}
+
+// appendInterpolated appends the fix at time m between fixes a and b to
+// flatCoords.
+func appendInterpolated(flatCoords []float64, a, b []float64, mIndex int, m float64) []float64 {
+	f := (m - a[mIndex]) / (b[mIndex] - a[mIndex])
+	for i := range a {
+		flatCoords = append(flatCoords, a[i]+f*(b[i]-a[i]))
+	}
+	flatCoords[len(flatCoords)-len(a)+mIndex] = m
+	return flatCoords
+}
+
+// A box is a bounding box in X, Y, and time.
+type box struct {
+	minX, minY, minM float64
+	maxX, maxY, maxM float64
+}
+
+// newBox returns the box of the fixes in flatCoords.
+func newBox(flatCoords []float64, stride, mIndex int) box {
+	b := box{
+		minX: math.Inf(1), minY: math.Inf(1), minM: math.Inf(1),
+		maxX: math.Inf(-1), maxY: math.Inf(-1), maxM: math.Inf(-1),
+	}
+	for i := 0; i < len(flatCoords); i += stride {
+		x, y, m := flatCoords[i], flatCoords[i+1], flatCoords[i+mIndex]
+		b.minX, b.maxX = math.Min(b.minX, x), math.Max(b.maxX, x)
+		b.minY, b.maxY = math.Min(b.minY, y), math.Max(b.maxY, y)
+		b.minM, b.maxM = math.Min(b.minM, m), math.Max(b.maxM, m)
+	}
+	return b
+}
+
+// overlaps returns whether b overlaps q.
+func (b box) overlaps(q box) bool {
+	return b.minX <= q.maxX && q.minX <= b.maxX &&
+		b.minY <= q.maxY && q.minY <= b.maxY &&
+		b.minM <= q.maxM && q.minM <= b.maxM
+}
+
+// An indexedTrack is a track and the boxes of its chunks, which together
+// form a tube around the track in space and time.
+type indexedTrack struct {
+	flatCoords []float64
+	stride     int
+	mIndex     int
+	box        box
+	chunks     []box
+}
+
+// An Index indexes tracks by space and time, to find the tracks that were in
+// an area during a time window.
+type Index struct {
+	tracks []indexedTrack
+}
+
+// NewIndex returns a new, empty Index.
+func NewIndex() *Index {
+	return &Index{}
+}
+
+// Add adds ls to idx and returns its index, which is the number of tracks
+// added before it. Times must not decrease along ls. ls must not be modified
+// while idx is in use. Add returns a geom.ErrUnsupportedLayout if ls has no M
+// ordinate.
+func (idx *Index) Add(ls *geom.LineString) (int, error) {
+	layout := ls.Layout()
+	mIndex := layout.MIndex()
+	if mIndex == -1 {
+		return 0, geom.ErrUnsupportedLayout(layout)
+	}
+	flatCoords := ls.FlatCoords()
+	stride := ls.Stride()
+	t := indexedTrack{
+		flatCoords: flatCoords,
+		stride:     stride,
+		mIndex:     mIndex,
+		box:        newBox(flatCoords, stride, mIndex),
+	}
+	// Consecutive chunks share a fix so that every segment is covered by a
+	// chunk.
+	for i := 0; i < len(flatCoords); i += indexChunkSize * stride {
+		end := i + (indexChunkSize+1)*stride
+		if end > len(flatCoords) {
+			end = len(flatCoords)
+		}
+		t.chunks = append(t.chunks, newBox(flatCoords[i:end], stride, mIndex))
+		if end == len(flatCoords) {
+			break
+		}
+	}
+	idx.tracks = append(idx.tracks, t)
+	return len(idx.tracks) - 1, nil
+}
+
+// Query returns the indexes, in increasing order, of the tracks that were
+// inside or on the boundary of bounds, in X and Y, at any time between start
+// and end, inclusive. Tracks are interpolated linearly between fixes.
+func (idx *Index) Query(bounds *geom.Bounds, start, end float64) []int {
+	q := box{
+		minX: bounds.Min(0), minY: bounds.Min(1), minM: start,
+		maxX: bounds.Max(0), maxY: bounds.Max(1), maxM: end,
+	}
+	var result []int
+	for i := range idx.tracks {
+		if idx.tracks[i].intersects(q) {
+			result = append(result, i)
+		}
+	}
+	return result
+}
+
+// intersects returns whether t passes through q.
+func (t *indexedTrack) intersects(q box) bool {
+	if !t.box.overlaps(q) {
+		return false
+	}
+	n := len(t.flatCoords) / t.stride
+	if n == 1 {
+		return true // The box of a single fix is the fix itself.
+	}
+	for i, chunk := range t.chunks {
+		if !chunk.overlaps(q) {
+			continue
+		}
+		for j := i * indexChunkSize; j < (i+1)*indexChunkSize && j+1 < n; j++ {
+			a := t.flatCoords[j*t.stride : (j+1)*t.stride]
+			b := t.flatCoords[(j+1)*t.stride : (j+2)*t.stride]
+			if segmentIntersects(a, b, t.mIndex, q) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// segmentIntersects returns whether the segment from fix a to fix b passes
+// through q. The segment is first clipped to the time window of q, and then
+// to its X and Y extent using the Liang-Barsky algorithm.
+func segmentIntersects(a, b []float64, mIndex int, q box) bool {
+	am, bm := a[mIndex], b[mIndex]
+	if bm < q.minM || am > q.maxM {
+		return false
+	}
+	t0, t1 := 0.0, 1.0
+	if dm := bm - am; dm > 0 {
+		t0 = math.Max(t0, (q.minM-am)/dm)
+		t1 = math.Min(t1, (q.maxM-am)/dm)
+	}
+	dx, dy := b[0]-a[0], b[1]-a[1]
+	for _, pq := range [4][2]float64{
+		{-dx, a[0] - q.minX},
+		{dx, q.maxX - a[0]},
+		{-dy, a[1] - q.minY},
+		{dy, q.maxY - a[1]},
+	} {
+		p, q := pq[0], pq[1]
+		switch {
+		case p == 0:
+			if q < 0 {
+				return false
+			}
+		case p < 0:
+			if r := q / p; r > t1 {
+				return false
+			} else if r > t0 {
+				t0 = r
+			}
+		default:
+			if r := q / p; r < t0 {
+				return false
+			} else if r < t1 {
+				t1 = r
+			}
+		}
+	}
+	return t0 <= t1
+}
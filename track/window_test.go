@@ -0,0 +1,118 @@
+package track
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+)
+
+func TestWindow(t *testing.T) {
+	ls := geom.NewLineStringFlat(geom.XYZM, []float64{
+		0, 0, 0, 0,
+		10, 0, 100, 10,
+		10, 10, 200, 20,
+	}).SetSRID(4326)
+	for _, tc := range []struct {
+		name       string
+		start, end float64
+		want       []float64
+	}{
+		{
+			name:  "all",
+			start: -1,
+			end:   21,
+			want:  []float64{0, 0, 0, 0, 10, 0, 100, 10, 10, 10, 200, 20},
+		},
+		{
+			name:  "interpolated",
+			start: 5,
+			end:   15,
+			want:  []float64{5, 0, 50, 5, 10, 0, 100, 10, 10, 5, 150, 15},
+		},
+		{
+			name:  "within_segment",
+			start: 2,
+			end:   4,
+			want:  []float64{2, 0, 20, 2, 4, 0, 40, 4},
+		},
+		{
+			name:  "exact_fixes",
+			start: 10,
+			end:   20,
+			want:  []float64{10, 0, 100, 10, 10, 10, 200, 20},
+		},
+		{
+			name:  "instant",
+			start: 20,
+			end:   30,
+			want:  []float64{10, 10, 200, 20},
+		},
+		{
+			name:  "outside",
+			start: 30,
+			end:   40,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Window(ls, tc.start, tc.end)
+			if err != nil {
+				t.Fatalf("Window(...) == %v, %v, want ..., nil", got, err)
+			}
+			if got.Layout() != geom.XYZM || got.SRID() != 4326 {
+				t.Errorf("Window(...) has layout %v and SRID %d, want XYZM and 4326", got.Layout(), got.SRID())
+			}
+			if !reflect.DeepEqual(got.FlatCoords(), tc.want) {
+				t.Errorf("Window(...).FlatCoords() == %v, want %v", got.FlatCoords(), tc.want)
+			}
+		})
+	}
+	if _, err := Window(geom.NewLineString(geom.XYZ), 0, 1); err != geom.ErrUnsupportedLayout(geom.XYZ) {
+		t.Errorf("Window(...) == ..., %v, want ..., %v", err, geom.ErrUnsupportedLayout(geom.XYZ))
+	}
+}
+
+func TestIndex(t *testing.T) {
+	// A long track that heads east along y = 0 at one unit per unit of time,
+	// so that it spans several chunks.
+	longFlatCoords := make([]float64, 0, 3*100)
+	for i := 0; i < 100; i++ {
+		longFlatCoords = append(longFlatCoords, float64(i), 0, float64(i))
+	}
+	idx := NewIndex()
+	for _, ls := range []*geom.LineString{
+		geom.NewLineStringFlat(geom.XYM, longFlatCoords),
+		// A track that crosses the box [40, 50]x[-5, 5] diagonally between
+		// fixes, at times 100 to 200.
+		geom.NewLineStringFlat(geom.XYM, []float64{30, -10, 100, 60, 20, 200}),
+		// A track that passes near, but not through, the box's corner.
+		geom.NewLineStringFlat(geom.XYM, []float64{30, 0, 0, 40, 10, 10}),
+		// A single fix.
+		geom.NewLineStringFlat(geom.XYM, []float64{45, 0, 500}),
+		geom.NewLineStringFlat(geom.XYM, nil),
+	} {
+		if _, err := idx.Add(ls); err != nil {
+			t.Fatal(err)
+		}
+	}
+	bounds := geom.NewBounds(geom.XY).Set(40, -5, 50, 5)
+	for _, tc := range []struct {
+		name       string
+		start, end float64
+		want       []int
+	}{
+		{name: "all_time", start: 0, end: 1000, want: []int{0, 1, 3}},
+		{name: "long_track", start: 42, end: 43, want: []int{0}},
+		{name: "long_track_between_fixes", start: 49.5, end: 49.75, want: []int{0}},
+		{name: "long_track_before", start: 0, end: 39.9},
+		{name: "crossing", start: 140, end: 150, want: []int{1}},
+		{name: "crossing_before", start: 100, end: 120},
+		{name: "single_fix", start: 500, end: 500, want: []int{3}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := idx.Query(bounds, tc.start, tc.end); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("idx.Query(bounds, %v, %v) == %v, want %v", tc.start, tc.end, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package geom
+
+// A Triangle represents a polygon with a single closed ring of four
+// coordinates and no holes, such as a face of a TIN.
+type Triangle struct {
+	geom2
+}
+
+// NewTriangle returns a new, empty, Triangle.
+func NewTriangle(layout Layout) *Triangle {
+	return NewTriangleFlat(layout, nil, nil)
+}
+
+// NewTriangleFlat returns a new Triangle with the given flat coordinates.
+func NewTriangleFlat(layout Layout, flatCoords []float64, ends []int) *Triangle {
+	g := new(Triangle)
+	g.layout = layout
+	g.stride = layout.Stride()
+	g.flatCoords = flatCoords
+	g.ends = ends
+	return g
+}
+
+// Area returns the signed area of the projection of g onto the XY plane,
+// which is positive if g is counterclockwise.
+func (g *Triangle) Area() float64 {
+	return doubleArea2(g.flatCoords, 0, g.ends, g.stride) / 2
+}
+
+// Clone returns a deep copy.
+func (g *Triangle) Clone() *Triangle {
+	return NewTriangleFlat(g.layout, append([]float64(nil), g.flatCoords...), append([]int(nil), g.ends...)).SetSRID(g.srid)
+}
+
+// Empty returns true if the geometry has no coordinate.
+func (g *Triangle) Empty() bool {
+	return len(g.FlatCoords()) == 0
+}
+
+// Length returns the perimeter.
+func (g *Triangle) Length() float64 {
+	return length2(g.flatCoords, 0, g.ends, g.stride)
+}
+
+// MustSetCoords sets the coordinates and panics on any error.
+func (g *Triangle) MustSetCoords(coords [][]Coord) *Triangle {
+	Must(g.SetCoords(coords))
+	return g
+}
+
+// Polygon returns g as a Polygon.
+func (g *Triangle) Polygon() *Polygon {
+	return NewPolygonFlat(g.layout, g.flatCoords, g.ends).SetSRID(g.srid)
+}
+
+// SetCoords sets the coordinates. coords must be empty or contain a single
+// closed ring of four coordinates, otherwise an ErrInvalidRing is returned.
+func (g *Triangle) SetCoords(coords [][]Coord) (*Triangle, error) {
+	if err := checkTriangle(g.layout, coords); err != nil {
+		return nil, err
+	}
+	if err := g.setCoords(coords); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// SetSRID sets the SRID of g.
+func (g *Triangle) SetSRID(srid int) *Triangle {
+	g.srid = srid
+	return g
+}
+
+// SurfaceArea returns the area of g in three dimensions, using its Z values
+// if it has any.
+func (g *Triangle) SurfaceArea() float64 {
+	return surfaceArea2(g.flatCoords, 0, g.ends, g.stride, g.layout.ZIndex())
+}
+
+// Swap swaps the values of g and g2.
+func (g *Triangle) Swap(g2 *Triangle) {
+	*g, *g2 = *g2, *g
+}
+
+// checkTriangle returns an ErrInvalidRing if coords are not the coordinates
+// of a Triangle.
+func checkTriangle(layout Layout, coords [][]Coord) error {
+	if len(coords) > 1 {
+		return ErrInvalidRing{Path: Path{1}, Reason: "triangle has more than one ring"}
+	}
+	for _, ring := range coords {
+		if len(ring) != 4 || !ring[0].Equal(layout, ring[3]) {
+			return ErrInvalidRing{Path: Path{0}, Reason: "not a closed ring of four coordinates"}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package geom
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestTriangle(t *testing.T) {
+	tr := NewTriangle(XYZ).MustSetCoords([][]Coord{
+		{{0, 0, 0}, {1, 0, 0}, {0, 1, 1}, {0, 0, 0}},
+	})
+	if err := tr.verify(); err != nil {
+		t.Error(err)
+	}
+	if got, want := tr.Bounds(), NewBounds(XYZ).Set(0, 0, 0, 1, 1, 1); !reflect.DeepEqual(got, want) {
+		t.Errorf("Bounds() == %v, want %v", got, want)
+	}
+	if got, want := tr.Area(), 0.5; got != want {
+		t.Errorf("Area() == %v, want %v", got, want)
+	}
+	if got, want := tr.SurfaceArea(), math.Sqrt2/2; math.Abs(got-want) > 1e-15 {
+		t.Errorf("SurfaceArea() == %v, want %v", got, want)
+	}
+	if got, want := tr.Length(), 2+math.Sqrt2; math.Abs(got-want) > 1e-15 {
+		t.Errorf("Length() == %v, want %v", got, want)
+	}
+	if got, want := tr.Polygon(), NewPolygonFlat(XYZ, tr.FlatCoords(), tr.Ends()); !reflect.DeepEqual(got, want) {
+		t.Errorf("Polygon() == %v, want %v", got, want)
+	}
+	clone := tr.Clone()
+	if !reflect.DeepEqual(clone, tr) {
+		t.Errorf("Clone() == %v, want %v", clone, tr)
+	}
+	clone.FlatCoords()[0] = 1
+	if tr.FlatCoords()[0] != 0 {
+		t.Error("Clone() aliases the original")
+	}
+}
+
+func TestTriangleSetCoordsErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		coords [][]Coord
+		want   error
+	}{
+		{
+			name:   "not_closed",
+			coords: [][]Coord{{{0, 0}, {1, 0}, {0, 1}, {1, 1}}},
+			want:   ErrInvalidRing{Path: Path{0}, Reason: "not a closed ring of four coordinates"},
+		},
+		{
+			name:   "too_many_coordinates",
+			coords: [][]Coord{{{0, 0}, {1, 0}, {1, 1}, {0, 1}, {0, 0}}},
+			want:   ErrInvalidRing{Path: Path{0}, Reason: "not a closed ring of four coordinates"},
+		},
+		{
+			name:   "hole",
+			coords: [][]Coord{{{0, 0}, {1, 0}, {0, 1}, {0, 0}}, {{0, 0}, {1, 0}, {0, 1}, {0, 0}}},
+			want:   ErrInvalidRing{Path: Path{1}, Reason: "triangle has more than one ring"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := NewTriangle(XY).SetCoords(tc.coords); !reflect.DeepEqual(err, tc.want) {
+				t.Errorf("SetCoords(%v) == _, %v, want _, %v", tc.coords, err, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package geom
+
+import "fmt"
+
+// Vertex editing. Lines and rings are given as the flat coordinates
+// flatCoords[offset:end]. The closing coordinate of a ring is kept equal to
+// its first coordinate, so vertex n-1 of a ring with n coordinates is the same
+// vertex as vertex 0. Out of range indexes cause a panic, as they do
+// elsewhere in this package; edits that would break the structure of the
+// geometry return an ErrInvalidVertex.
+
+const (
+	minLineStringCoords = 2
+	minRingCoords       = 4
+)
+
+// deleteVertex deletes the ith vertex of the line or ring in
+// flatCoords[offset:end].
+func deleteVertex(flatCoords []float64, offset, end, stride, i int, ring bool) ([]float64, error) {
+	n := (end - offset) / stride
+	checkVertexIndex(i, n)
+	minCoords := minLineStringCoords
+	if ring {
+		minCoords = minRingCoords
+	}
+	if n <= minCoords {
+		return nil, ErrInvalidVertex{Index: i, Reason: "too few coordinates"}
+	}
+	if ring && i == n-1 {
+		i = 0
+	}
+	at := offset + i*stride
+	flatCoords = append(flatCoords[:at], flatCoords[at+stride:]...)
+	if ring && i == 0 {
+		copy(flatCoords[end-2*stride:end-stride], flatCoords[offset:offset+stride])
+	}
+	return flatCoords, nil
+}
+
+// insertVertex inserts c before the ith vertex of the line or ring in
+// flatCoords[offset:end]. i may be equal to the number of coordinates to
+// append c to a line. Inserting into an empty ring creates a ring containing
+// c twice, so that the ring is closed.
+func insertVertex(flatCoords []float64, offset, end, stride, i int, c Coord, ring bool) ([]float64, error) {
+	if len(c) != stride {
+		return nil, ErrStrideMismatch{Got: len(c), Want: stride}
+	}
+	n := (end - offset) / stride
+	if ring && n != 0 {
+		checkVertexIndex(i, n)
+	} else {
+		checkVertexIndex(i, n+1)
+	}
+	// c may alias flatCoords, for example if it was returned by Coord, so
+	// copy it before shifting flatCoords.
+	c = append(Coord(nil), c...)
+	at := offset + i*stride
+	flatCoords = insertCoord(flatCoords, at, c)
+	if ring {
+		switch {
+		case n == 0:
+			flatCoords = insertCoord(flatCoords, at, c)
+		case i == 0:
+			copy(flatCoords[end:end+stride], c)
+		}
+	}
+	return flatCoords, nil
+}
+
+// moveVertex sets the ith vertex of the line or ring in
+// flatCoords[offset:end] to c.
+func moveVertex(flatCoords []float64, offset, end, stride, i int, c Coord, ring bool) error {
+	if len(c) != stride {
+		return ErrStrideMismatch{Got: len(c), Want: stride}
+	}
+	n := (end - offset) / stride
+	checkVertexIndex(i, n)
+	copy(flatCoords[offset+i*stride:], c)
+	if ring && (i == 0 || i == n-1) {
+		copy(flatCoords[offset:], c)
+		copy(flatCoords[end-stride:], c)
+	}
+	return nil
+}
+
+// checkVertexIndex panics if i is not a valid index into n vertices.
+func checkVertexIndex(i, n int) {
+	if i < 0 || i >= n {
+		panic(fmt.Sprintf("geom: vertex index %d out of range [0:%d]", i, n))
+	}
+}
+
+// insertCoord inserts c at flatCoords[at].
+func insertCoord(flatCoords []float64, at int, c Coord) []float64 {
+	flatCoords = append(flatCoords, c...)
+	copy(flatCoords[at+len(c):], flatCoords[at:len(flatCoords)-len(c)])
+	copy(flatCoords[at:], c)
+	return flatCoords
+}
+
+// shiftEnds adds delta to ends[i:].
+func shiftEnds(ends []int, i, delta int) {
+	for ; i < len(ends); i++ {
+		ends[i] += delta
+	}
+}
+
+// ringRange returns the offset and end of the jth ring of the ith polygon.
+func (g *geom3) ringRange(i, j int) (int, int) {
+	ends := g.endss[i]
+	end := ends[j]
+	if j > 0 {
+		return ends[j-1], end
+	}
+	for k := i - 1; k >= 0; k-- {
+		if len(g.endss[k]) > 0 {
+			return g.endss[k][len(g.endss[k])-1], end
+		}
+	}
+	return 0, end
+}
+
+// shiftEndss adds delta to all ends from the jth end of the ith polygon.
+func (g *geom3) shiftEndss(i, j, delta int) {
+	shiftEnds(g.endss[i], j, delta)
+	for k := i + 1; k < len(g.endss); k++ {
+		shiftEnds(g.endss[k], 0, delta)
+	}
+}
+
+// offset returns the offset of the ith sub-structure of g.
+func (g *geom2) offset(i int) int {
+	if i == 0 {
+		return 0
+	}
+	return g.ends[i-1]
+}
+
+// deleteVertex deletes the jth vertex of the ith line or ring of g.
+func (g *geom2) deleteVertex(i, j int, ring bool) error {
+	flatCoords, err := deleteVertex(g.flatCoords, g.offset(i), g.ends[i], g.stride, j, ring)
+	if err != nil {
+		return err
+	}
+	g.flatCoords = flatCoords
+	shiftEnds(g.ends, i, -g.stride)
+	return nil
+}
+
+// insertVertex inserts c before the jth vertex of the ith line or ring of g.
+func (g *geom2) insertVertex(i, j int, c Coord, ring bool) error {
+	n := len(g.flatCoords)
+	flatCoords, err := insertVertex(g.flatCoords, g.offset(i), g.ends[i], g.stride, j, c, ring)
+	if err != nil {
+		return err
+	}
+	g.flatCoords = flatCoords
+	shiftEnds(g.ends, i, len(flatCoords)-n)
+	return nil
+}
+
+// deleteVertex deletes the kth vertex of the jth ring of the ith polygon of g.
+func (g *geom3) deleteVertex(i, j, k int) error {
+	offset, end := g.ringRange(i, j)
+	flatCoords, err := deleteVertex(g.flatCoords, offset, end, g.stride, k, true)
+	if err != nil {
+		return err
+	}
+	g.flatCoords = flatCoords
+	g.shiftEndss(i, j, -g.stride)
+	return nil
+}
+
+// insertVertex inserts c before the kth vertex of the jth ring of the ith
+// polygon of g.
+func (g *geom3) insertVertex(i, j, k int, c Coord) error {
+	n := len(g.flatCoords)
+	offset, end := g.ringRange(i, j)
+	flatCoords, err := insertVertex(g.flatCoords, offset, end, g.stride, k, c, true)
+	if err != nil {
+		return err
+	}
+	g.flatCoords = flatCoords
+	g.shiftEndss(i, j, len(flatCoords)-n)
+	return nil
+}
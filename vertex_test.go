@@ -0,0 +1,230 @@
+package geom
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLineStringVertexEditing(t *testing.T) {
+	g := NewLineStringFlat(XY, []float64{0, 0, 1, 1})
+	if err := g.InsertVertex(1, Coord{2, 2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.InsertVertex(3, Coord{3, 3}); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.MoveVertex(0, Coord{-1, -1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.DeleteVertex(2); err != nil {
+		t.Fatal(err)
+	}
+	if want := []float64{-1, -1, 2, 2, 3, 3}; !reflect.DeepEqual(g.FlatCoords(), want) {
+		t.Errorf("g.FlatCoords() == %v, want %v", g.FlatCoords(), want)
+	}
+	if err := g.InsertVertex(0, Coord{1, 2, 3}); !reflect.DeepEqual(err, ErrStrideMismatch{Got: 3, Want: 2}) {
+		t.Errorf("g.InsertVertex(0, ...) == %v, want %v", err, ErrStrideMismatch{Got: 3, Want: 2})
+	}
+	if err := g.DeleteVertex(0); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.DeleteVertex(0); !reflect.DeepEqual(err, ErrInvalidVertex{Index: 0, Reason: "too few coordinates"}) {
+		t.Errorf("g.DeleteVertex(0) == %v, want %v", err, ErrInvalidVertex{Index: 0, Reason: "too few coordinates"})
+	}
+}
+
+func TestInsertVertexAliasing(t *testing.T) {
+	flatCoords := make([]float64, 6, 8)
+	copy(flatCoords, []float64{0, 0, 1, 1, 2, 2})
+	g := NewLineStringFlat(XY, flatCoords)
+	if err := g.InsertVertex(0, g.Coord(1)); err != nil {
+		t.Fatal(err)
+	}
+	if want := []float64{1, 1, 0, 0, 1, 1, 2, 2}; !reflect.DeepEqual(g.FlatCoords(), want) {
+		t.Errorf("g.FlatCoords() == %v, want %v", g.FlatCoords(), want)
+	}
+
+	r := NewLinearRingFlat(XY, append(make([]float64, 0, 16), 0, 0, 1, 0, 1, 1, 0, 0))
+	if err := r.InsertVertex(0, r.Coord(2)); err != nil {
+		t.Fatal(err)
+	}
+	if want := []float64{1, 1, 0, 0, 1, 0, 1, 1, 1, 1}; !reflect.DeepEqual(r.FlatCoords(), want) {
+		t.Errorf("r.FlatCoords() == %v, want %v", r.FlatCoords(), want)
+	}
+}
+
+func TestLinearRingVertexEditing(t *testing.T) {
+	for _, tc := range []struct {
+		name           string
+		flatCoords     []float64
+		edit           func(*LinearRing) error
+		wantFlatCoords []float64
+		wantErr        error
+	}{
+		{
+			name:           "insert_empty",
+			edit:           func(g *LinearRing) error { return g.InsertVertex(0, Coord{1, 2}) },
+			wantFlatCoords: []float64{1, 2, 1, 2},
+		},
+		{
+			name:           "insert_first",
+			flatCoords:     []float64{0, 0, 1, 0, 1, 1, 0, 0},
+			edit:           func(g *LinearRing) error { return g.InsertVertex(0, Coord{0, 1}) },
+			wantFlatCoords: []float64{0, 1, 0, 0, 1, 0, 1, 1, 0, 1},
+		},
+		{
+			name:           "insert_last",
+			flatCoords:     []float64{0, 0, 1, 0, 1, 1, 0, 0},
+			edit:           func(g *LinearRing) error { return g.InsertVertex(3, Coord{0, 1}) },
+			wantFlatCoords: []float64{0, 0, 1, 0, 1, 1, 0, 1, 0, 0},
+		},
+		{
+			name:           "move_first",
+			flatCoords:     []float64{0, 0, 1, 0, 1, 1, 0, 0},
+			edit:           func(g *LinearRing) error { return g.MoveVertex(0, Coord{0, 1}) },
+			wantFlatCoords: []float64{0, 1, 1, 0, 1, 1, 0, 1},
+		},
+		{
+			name:           "move_closing",
+			flatCoords:     []float64{0, 0, 1, 0, 1, 1, 0, 0},
+			edit:           func(g *LinearRing) error { return g.MoveVertex(3, Coord{0, 1}) },
+			wantFlatCoords: []float64{0, 1, 1, 0, 1, 1, 0, 1},
+		},
+		{
+			name:           "move_middle",
+			flatCoords:     []float64{0, 0, 1, 0, 1, 1, 0, 0},
+			edit:           func(g *LinearRing) error { return g.MoveVertex(2, Coord{2, 2}) },
+			wantFlatCoords: []float64{0, 0, 1, 0, 2, 2, 0, 0},
+		},
+		{
+			name:           "delete_first",
+			flatCoords:     []float64{0, 0, 1, 0, 1, 1, 0, 1, 0, 0},
+			edit:           func(g *LinearRing) error { return g.DeleteVertex(0) },
+			wantFlatCoords: []float64{1, 0, 1, 1, 0, 1, 1, 0},
+		},
+		{
+			name:           "delete_closing",
+			flatCoords:     []float64{0, 0, 1, 0, 1, 1, 0, 1, 0, 0},
+			edit:           func(g *LinearRing) error { return g.DeleteVertex(4) },
+			wantFlatCoords: []float64{1, 0, 1, 1, 0, 1, 1, 0},
+		},
+		{
+			name:           "delete_middle",
+			flatCoords:     []float64{0, 0, 1, 0, 1, 1, 0, 1, 0, 0},
+			edit:           func(g *LinearRing) error { return g.DeleteVertex(2) },
+			wantFlatCoords: []float64{0, 0, 1, 0, 0, 1, 0, 0},
+		},
+		{
+			name:           "delete_triangle",
+			flatCoords:     []float64{0, 0, 1, 0, 1, 1, 0, 0},
+			edit:           func(g *LinearRing) error { return g.DeleteVertex(1) },
+			wantFlatCoords: []float64{0, 0, 1, 0, 1, 1, 0, 0},
+			wantErr:        ErrInvalidVertex{Index: 1, Reason: "too few coordinates"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewLinearRingFlat(XY, tc.flatCoords)
+			if err := tc.edit(g); !reflect.DeepEqual(err, tc.wantErr) {
+				t.Errorf("got error %v, want %v", err, tc.wantErr)
+			}
+			if !reflect.DeepEqual(g.FlatCoords(), tc.wantFlatCoords) {
+				t.Errorf("g.FlatCoords() == %v, want %v", g.FlatCoords(), tc.wantFlatCoords)
+			}
+		})
+	}
+}
+
+func TestPolygonVertexEditing(t *testing.T) {
+	g := NewPolygon(XY).MustSetCoords([][]Coord{
+		{{0, 0}, {10, 0}, {10, 10}, {0, 0}},
+		{{1, 1}, {2, 1}, {2, 2}, {1, 1}},
+	})
+	if err := g.InsertVertex(0, 3, Coord{0, 10}); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.MoveVertex(1, 0, Coord{3, 1}); err != nil {
+		t.Fatal(err)
+	}
+	testPolygonEquals(t, g, &testPolygon{
+		layout: XY,
+		stride: 2,
+		coords: [][]Coord{
+			{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}},
+			{{3, 1}, {2, 1}, {2, 2}, {3, 1}},
+		},
+		ends:       []int{10, 18},
+		flatCoords: []float64{0, 0, 10, 0, 10, 10, 0, 10, 0, 0, 3, 1, 2, 1, 2, 2, 3, 1},
+		bounds:     NewBounds(XY).Set(0, 0, 10, 10),
+	})
+	if err := g.DeleteVertex(0, 3); err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{8, 16}; !reflect.DeepEqual(g.Ends(), want) {
+		t.Errorf("g.Ends() == %v, want %v", g.Ends(), want)
+	}
+	if err := g.ValidateHoles(); err != nil {
+		t.Errorf("g.ValidateHoles() == %v, want <nil>", err)
+	}
+}
+
+func TestMultiLineStringVertexEditing(t *testing.T) {
+	g := NewMultiLineStringFlat(XY, []float64{0, 0, 1, 1, 2, 2, 3, 3}, []int{4, 8})
+	if err := g.InsertVertex(0, 2, Coord{5, 5}); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.MoveVertex(1, 1, Coord{4, 4}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []float64{0, 0, 1, 1, 5, 5, 2, 2, 4, 4}; !reflect.DeepEqual(g.FlatCoords(), want) {
+		t.Errorf("g.FlatCoords() == %v, want %v", g.FlatCoords(), want)
+	}
+	if want := []int{6, 10}; !reflect.DeepEqual(g.Ends(), want) {
+		t.Errorf("g.Ends() == %v, want %v", g.Ends(), want)
+	}
+	if err := g.DeleteVertex(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{4, 8}; !reflect.DeepEqual(g.Ends(), want) {
+		t.Errorf("g.Ends() == %v, want %v", g.Ends(), want)
+	}
+}
+
+func TestMultiPolygonVertexEditing(t *testing.T) {
+	g := NewMultiPolygon(XY).MustSetCoords([][][]Coord{
+		{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}},
+		{},
+		{{{2, 2}, {3, 2}, {3, 3}, {2, 2}}},
+	})
+	if err := g.InsertVertex(2, 0, 0, Coord{2, 3}); err != nil {
+		t.Fatal(err)
+	}
+	if want := [][]int{{8}, nil, {18}}; !reflect.DeepEqual(g.Endss(), want) {
+		t.Errorf("g.Endss() == %v, want %v", g.Endss(), want)
+	}
+	if err := g.MoveVertex(0, 0, 3, Coord{-1, -1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.DeleteVertex(0, 0, 1); !reflect.DeepEqual(err, ErrInvalidVertex{Index: 1, Reason: "too few coordinates"}) {
+		t.Errorf("g.DeleteVertex(0, 0, 1) == %v, want %v", err, ErrInvalidVertex{Index: 1, Reason: "too few coordinates"})
+	}
+	if err := g.DeleteVertex(2, 0, 2); err != nil {
+		t.Fatal(err)
+	}
+	want := []float64{-1, -1, 1, 0, 1, 1, -1, -1, 2, 3, 2, 2, 3, 3, 2, 3}
+	if !reflect.DeepEqual(g.FlatCoords(), want) {
+		t.Errorf("g.FlatCoords() == %v, want %v", g.FlatCoords(), want)
+	}
+	if want := [][]int{{8}, nil, {16}}; !reflect.DeepEqual(g.Endss(), want) {
+		t.Errorf("g.Endss() == %v, want %v", g.Endss(), want)
+	}
+}
+
+func TestVertexIndexOutOfRange(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("g.MoveVertex(2, ...) did not panic")
+		}
+	}()
+	g := NewLineStringFlat(XY, []float64{0, 0, 1, 1})
+	_ = g.MoveVertex(2, Coord{0, 0})
+}
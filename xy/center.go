@@ -0,0 +1,118 @@
+package xy
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/twpayne/go-geom"
+)
+
+// medianCenterMaxIterations limits the number of iterations of Weiszfeld's
+// algorithm, which converges linearly.
+const medianCenterMaxIterations = 1000
+
+// An ErrWeightsMismatch is returned when the number of weights differs from
+// the number of points.
+type ErrWeightsMismatch struct {
+	Got  int
+	Want int
+}
+
+func (e ErrWeightsMismatch) Error() string {
+	return fmt.Sprintf("xy: weights mismatch, got %d, want %d", e.Got, e.Want)
+}
+
+// WeightedCentroid returns the centroid of points, with each point weighted
+// by the corresponding element of weights, for example its population or
+// demand. It returns nil if the weights sum to zero, including when points is
+// empty.
+func WeightedCentroid(points *geom.MultiPoint, weights []float64) (geom.Coord, error) {
+	if n := points.NumPoints(); len(weights) != n {
+		return nil, ErrWeightsMismatch{Got: len(weights), Want: n}
+	}
+	flatCoords, stride := points.FlatCoords(), points.Stride()
+	var sumX, sumY, sumW float64
+	for i, w := range weights {
+		sumX += w * flatCoords[i*stride]
+		sumY += w * flatCoords[i*stride+1]
+		sumW += w
+	}
+	if sumW == 0 {
+		return nil, nil
+	}
+	return geom.Coord{sumX / sumW, sumY / sumW}, nil
+}
+
+// MedianCenter returns the median center of points, also known as the
+// geometric median or the solution of the Fermat-Weber problem: the location
+// that minimizes the sum of the Euclidean distances to points. Unlike the
+// centroid, it is robust to outliers, and it is the optimal location of a
+// single facility serving points. It is computed iteratively with the
+// Vardi-Zhang modification of Weiszfeld's algorithm, which handles estimates
+// that coincide with a point. It returns nil if points is empty.
+func MedianCenter(points *geom.MultiPoint) geom.Coord {
+	flatCoords, stride := points.FlatCoords(), points.Stride()
+	if len(flatCoords) == 0 {
+		return nil
+	}
+	n := len(flatCoords) / stride
+
+	// Start from the centroid, and stop when the estimate moves by less than
+	// a small fraction of the extent of points.
+	var x, y float64
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for i := 0; i < len(flatCoords); i += stride {
+		px, py := flatCoords[i], flatCoords[i+1]
+		x += px
+		y += py
+		minX, minY = math.Min(minX, px), math.Min(minY, py)
+		maxX, maxY = math.Max(maxX, px), math.Max(maxY, py)
+	}
+	x /= float64(n)
+	y /= float64(n)
+	tolerance := 1e-12 * math.Hypot(maxX-minX, maxY-minY)
+
+	for iteration := 0; iteration < medianCenterMaxIterations; iteration++ {
+		// Compute the Weiszfeld step over the points that do not coincide
+		// with the estimate, and count those that do.
+		var sumX, sumY, sumInvD, rx, ry float64
+		coincident := 0
+		for i := 0; i < len(flatCoords); i += stride {
+			dx, dy := flatCoords[i]-x, flatCoords[i+1]-y
+			d := math.Hypot(dx, dy)
+			if d == 0 {
+				coincident++
+				continue
+			}
+			sumX += flatCoords[i] / d
+			sumY += flatCoords[i+1] / d
+			sumInvD += 1 / d
+			rx += dx / d
+			ry += dy / d
+		}
+		if sumInvD == 0 {
+			// All points coincide with the estimate.
+			break
+		}
+		nextX, nextY := sumX/sumInvD, sumY/sumInvD
+		if coincident > 0 {
+			// The estimate is a point. It is optimal if the pull of the other
+			// points does not exceed its weight, otherwise step only part of
+			// the way towards the Weiszfeld estimate.
+			r := math.Hypot(rx, ry)
+			if r <= float64(coincident) {
+				break
+			}
+			gamma := float64(coincident) / r
+			nextX = (1-gamma)*nextX + gamma*x
+			nextY = (1-gamma)*nextY + gamma*y
+		}
+		moved := math.Hypot(nextX-x, nextY-y)
+		x, y = nextX, nextY
+		if moved <= tolerance {
+			break
+		}
+	}
+	return geom.Coord{x, y}
+}
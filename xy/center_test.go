@@ -0,0 +1,101 @@
+package xy
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+)
+
+func TestWeightedCentroid(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		points  *geom.MultiPoint
+		weights []float64
+		want    geom.Coord
+		wantErr error
+	}{
+		{
+			name:   "empty",
+			points: geom.NewMultiPoint(geom.XY),
+		},
+		{
+			name:    "equal_weights",
+			points:  geom.NewMultiPointFlat(geom.XY, []float64{0, 0, 2, 0, 2, 4}),
+			weights: []float64{1, 1, 1},
+			want:    geom.Coord{4.0 / 3, 4.0 / 3},
+		},
+		{
+			name:    "weighted",
+			points:  geom.NewMultiPointFlat(geom.XYM, []float64{0, 0, 5, 4, 8, 6}),
+			weights: []float64{3, 1},
+			want:    geom.Coord{1, 2},
+		},
+		{
+			name:    "zero_weights",
+			points:  geom.NewMultiPointFlat(geom.XY, []float64{0, 0, 4, 8}),
+			weights: []float64{0, 0},
+		},
+		{
+			name:    "mismatch",
+			points:  geom.NewMultiPointFlat(geom.XY, []float64{0, 0, 4, 8}),
+			weights: []float64{1},
+			wantErr: ErrWeightsMismatch{Got: 1, Want: 2},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := WeightedCentroid(tc.points, tc.weights)
+			if err != tc.wantErr || !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("WeightedCentroid(...) == %v, %v, want %v, %v", got, err, tc.want, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestMedianCenter(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		points *geom.MultiPoint
+		want   geom.Coord
+	}{
+		{
+			name:   "empty",
+			points: geom.NewMultiPoint(geom.XY),
+		},
+		{
+			name:   "single",
+			points: geom.NewMultiPointFlat(geom.XY, []float64{3, 4}),
+			want:   geom.Coord{3, 4},
+		},
+		{
+			name:   "square",
+			points: geom.NewMultiPointFlat(geom.XY, []float64{0, 0, 2, 0, 2, 2, 0, 2}),
+			want:   geom.Coord{1, 1},
+		},
+		{
+			name:   "outlier",
+			points: geom.NewMultiPointFlat(geom.XY, []float64{0, 0, 1, 0, 2, 0, 3, 0, 1000, 0}),
+			want:   geom.Coord{2, 0},
+		},
+		{
+			name:   "dominant_point",
+			points: geom.NewMultiPointFlat(geom.XYZ, []float64{0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 1, 0}),
+			want:   geom.Coord{0, 0},
+		},
+		{
+			// The Fermat point of a triangle with all angles less than 120°
+			// sees each side at 120°.
+			name:   "fermat_point",
+			points: geom.NewMultiPointFlat(geom.XY, []float64{-1, 0, 1, 0, 0, math.Sqrt(3)}),
+			want:   geom.Coord{0, 1 / math.Sqrt(3)},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := MedianCenter(tc.points)
+			if (got == nil) != (tc.want == nil) || got != nil && math.Hypot(got[0]-tc.want[0], got[1]-tc.want[1]) > 1e-6 {
+				t.Errorf("MedianCenter(...) == %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -237,14 +237,10 @@ func (calc *convexHullCalculator) computeOctRing(inputPts []float64) []float64 {
 	}
 
 	copyTo += stride
-	octPts = octPts[0 : copyTo+stride]
 
-	// close ring
-	for j := 0; j < stride; j++ {
-		octPts[copyTo+j] = octPts[j]
-	}
-
-	return octPts
+	// close ring, which needs an extra coordinate if all eight points are
+	// distinct
+	return append(octPts[:copyTo], octPts[:stride]...)
 }
 
 func (calc *convexHullCalculator) computeOctPts(inputPts []float64) []float64 {
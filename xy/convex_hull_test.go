@@ -203,3 +203,21 @@ func TestGrahamScan(t *testing.T) {
 		t.Fatalf("calc.grahamScan(...) mutated the input coords.  Expected \n\t%v\nbut was\n\t%v", internal.RING.FlatCoords(), coords)
 	}
 }
+
+func TestConvexHullOctagon(t *testing.T) {
+	// All eight extreme points of the octagon are distinct, so the reduced
+	// ring needs all eight points and a closing point.
+	flatCoords := []float64{2, 0, 1.5, 1.5, 0, 2, -1.5, 1.5, -2, 0, -1.5, -1.5, 0, -2, 1.5, -1.5}
+	for x := -0.9; x < 1; x += 0.3 {
+		for y := -0.9; y < 1; y += 0.3 {
+			flatCoords = append(flatCoords, x, y)
+		}
+	}
+	convexHull, ok := ConvexHullFlat(geom.XY, flatCoords).(*geom.Polygon)
+	if !ok {
+		t.Fatalf("ConvexHullFlat(...) == %v, want a Polygon", convexHull)
+	}
+	if got, want := convexHull.NumCoords(), 9; got != want {
+		t.Errorf("ConvexHullFlat(...).NumCoords() == %d, want %d", got, want)
+	}
+}
@@ -0,0 +1,268 @@
+package xy
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/twpayne/go-geom"
+)
+
+// SimplifyCoverage simplifies a coverage of Polygons and MultiPolygons, such
+// as a tiling of administrative areas, with the Douglas-Peucker algorithm and
+// threshold, so that boundaries shared between polygons are simplified
+// identically.
+//
+// The boundaries are split into chains between nodes, the vertices at which
+// three or more chains meet, and each chain is simplified once, with its
+// end points preserved, and used by every ring that contains it. Rings with
+// no nodes are split at their lowest vertex. Collapsed rings and polygons are
+// removed as by Simplify. The shared boundaries remain identical, but
+// simplification may still introduce intersections between chains.
+//
+// All geometries must have the same layout.
+func SimplifyCoverage(gs []geom.T, threshold float64) ([]geom.T, error) {
+	if len(gs) == 0 {
+		return nil, nil
+	}
+	layout := gs[0].Layout()
+	stride := layout.Stride()
+	for _, g := range gs {
+		switch g.(type) {
+		case *geom.Polygon, *geom.MultiPolygon:
+		default:
+			return nil, geom.ErrUnsupportedType{Value: g}
+		}
+		if g.Layout() != layout {
+			return nil, geom.ErrLayoutMismatch{Got: g.Layout(), Want: layout}
+		}
+	}
+
+	c := &coverageSimplifier{
+		stride:    stride,
+		threshold: threshold,
+		neighbors: make(map[[2]float64]*vertexNeighbors),
+		chains:    make(map[string][]float64),
+	}
+	for _, g := range gs {
+		forEachPolygon(g, func(flatCoords []float64, start int, ends []int) {
+			for _, end := range ends {
+				c.addRing(flatCoords[start:end])
+				start = end
+			}
+		})
+	}
+
+	simplified := make([]geom.T, len(gs))
+	for i, g := range gs {
+		var flatCoords []float64
+		var endss [][]int
+		forEachPolygon(g, func(polygonFlatCoords []float64, start int, polygonEnds []int) {
+			var ends []int
+			for j, end := range polygonEnds {
+				ring := c.simplifyRing(polygonFlatCoords[start:end])
+				start = end
+				if ring == nil {
+					// If the exterior ring collapses then the whole
+					// polygon is removed.
+					if j == 0 {
+						break
+					}
+					continue
+				}
+				flatCoords = append(flatCoords, ring...)
+				ends = append(ends, len(flatCoords))
+			}
+			if ends != nil {
+				endss = append(endss, ends)
+			}
+		})
+		switch g := g.(type) {
+		case *geom.Polygon:
+			var ends []int
+			if len(endss) > 0 {
+				ends = endss[0]
+			}
+			simplified[i] = geom.NewPolygonFlat(layout, flatCoords, ends).SetSRID(g.SRID())
+		case *geom.MultiPolygon:
+			simplified[i] = geom.NewMultiPolygonFlat(layout, flatCoords, endss).SetSRID(g.SRID())
+		}
+	}
+	return simplified, nil
+}
+
+// A coverageSimplifier simplifies the chains of a coverage.
+type coverageSimplifier struct {
+	stride    int
+	threshold float64
+	neighbors map[[2]float64]*vertexNeighbors
+	chains    map[string][]float64 // simplified chains by canonical key
+}
+
+// vertexNeighbors records up to three distinct neighbors of a vertex, which is
+// enough to determine whether the vertex is a node.
+type vertexNeighbors struct {
+	n         int
+	neighbors [3][2]float64
+}
+
+// forEachPolygon calls fn with the flat coordinates, offset, and ring ends of
+// each polygon in g.
+func forEachPolygon(g geom.T, fn func(flatCoords []float64, start int, ends []int)) {
+	switch g := g.(type) {
+	case *geom.Polygon:
+		fn(g.FlatCoords(), 0, g.Ends())
+	case *geom.MultiPolygon:
+		start := 0
+		for _, ends := range g.Endss() {
+			fn(g.FlatCoords(), start, ends)
+			if len(ends) > 0 {
+				start = ends[len(ends)-1]
+			}
+		}
+	}
+}
+
+func (v *vertexNeighbors) add(neighbor [2]float64) {
+	for i := 0; i < v.n; i++ {
+		if v.neighbors[i] == neighbor {
+			return
+		}
+	}
+	if v.n < len(v.neighbors) {
+		v.neighbors[v.n] = neighbor
+		v.n++
+	}
+}
+
+// addRing records the neighbors of each vertex of ring.
+func (c *coverageSimplifier) addRing(ring []float64) {
+	m := len(ring)/c.stride - 1
+	for i := 0; i < m; i++ {
+		v := c.vertex(ring, i)
+		neighbors := c.neighbors[v]
+		if neighbors == nil {
+			neighbors = &vertexNeighbors{}
+			c.neighbors[v] = neighbors
+		}
+		neighbors.add(c.vertex(ring, (i+m-1)%m))
+		neighbors.add(c.vertex(ring, (i+1)%m))
+	}
+}
+
+// isNode returns true if v is a node.
+func (c *coverageSimplifier) isNode(v [2]float64) bool {
+	return c.neighbors[v].n != 2
+}
+
+// simplifyRing returns the simplified ring, or nil if it collapses.
+func (c *coverageSimplifier) simplifyRing(ring []float64) []float64 {
+	stride := c.stride
+	m := len(ring)/stride - 1
+	if m < 3 {
+		return nil
+	}
+
+	// Find the nodes, or the lowest vertex if there are none.
+	var nodes []int
+	for i := 0; i < m; i++ {
+		if c.isNode(c.vertex(ring, i)) {
+			nodes = append(nodes, i)
+		}
+	}
+	if len(nodes) == 0 {
+		lowest := 0
+		for i := 1; i < m; i++ {
+			if lessXY(c.vertex(ring, i), c.vertex(ring, lowest)) {
+				lowest = i
+			}
+		}
+		nodes = []int{lowest}
+	}
+
+	// Unroll the ring so that it starts and ends at the first node.
+	unrolled := make([]float64, 0, (m+1)*stride)
+	unrolled = append(unrolled, ring[nodes[0]*stride:m*stride]...)
+	unrolled = append(unrolled, ring[:(nodes[0]+1)*stride]...)
+
+	simplified := make([]float64, 0, len(unrolled))
+	simplified = append(simplified, unrolled[:stride]...)
+	for k := range nodes {
+		start := nodes[k] - nodes[0]
+		end := m
+		if k+1 < len(nodes) {
+			end = nodes[k+1] - nodes[0]
+		}
+		chain := c.simplifyChain(unrolled[start*stride : (end+1)*stride])
+		simplified = append(simplified, chain[stride:]...)
+	}
+	if len(simplified) < 4*stride {
+		return nil
+	}
+	return simplified
+}
+
+// simplifyChain returns the simplified chain, simplifying each chain and its
+// reverse identically.
+func (c *coverageSimplifier) simplifyChain(chain []float64) []float64 {
+	reversed := c.reverse(chain)
+	canonical, isReversed := chain, false
+	if c.lessChain(reversed, chain) {
+		canonical, isReversed = reversed, true
+	}
+	key := c.chainKey(canonical)
+	simplified, ok := c.chains[key]
+	if !ok {
+		simplified = simplifyFlatCoords(canonical, c.threshold, c.stride)
+		c.chains[key] = simplified
+	}
+	if isReversed {
+		return c.reverse(simplified)
+	}
+	return simplified
+}
+
+// chainKey returns a key that identifies chain by its XY coordinates.
+func (c *coverageSimplifier) chainKey(chain []float64) string {
+	key := make([]byte, 0, 16*len(chain)/c.stride)
+	var buf [8]byte
+	for i := 0; i < len(chain); i += c.stride {
+		binary.LittleEndian.PutUint64(buf[:], math.Float64bits(chain[i]))
+		key = append(key, buf[:]...)
+		binary.LittleEndian.PutUint64(buf[:], math.Float64bits(chain[i+1]))
+		key = append(key, buf[:]...)
+	}
+	return string(key)
+}
+
+// lessChain returns true if the XY coordinates of chain1 are
+// lexicographically less than those of chain2.
+func (c *coverageSimplifier) lessChain(chain1, chain2 []float64) bool {
+	for i := 0; i < len(chain1) && i < len(chain2); i += c.stride {
+		v1, v2 := c.vertex(chain1, i/c.stride), c.vertex(chain2, i/c.stride)
+		if v1 != v2 {
+			return lessXY(v1, v2)
+		}
+	}
+	return len(chain1) < len(chain2)
+}
+
+// reverse returns a reversed copy of chain.
+func (c *coverageSimplifier) reverse(chain []float64) []float64 {
+	reversed := make([]float64, 0, len(chain))
+	for i := len(chain) - c.stride; i >= 0; i -= c.stride {
+		reversed = append(reversed, chain[i:i+c.stride]...)
+	}
+	return reversed
+}
+
+// vertex returns the XY coordinates of the ith vertex of flatCoords.
+func (c *coverageSimplifier) vertex(flatCoords []float64, i int) [2]float64 {
+	return [2]float64{flatCoords[i*c.stride], flatCoords[i*c.stride+1]}
+}
+
+func lessXY(v1, v2 [2]float64) bool {
+	if v1[0] != v2[0] {
+		return v1[0] < v2[0]
+	}
+	return v1[1] < v2[1]
+}
@@ -0,0 +1,48 @@
+package xy
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+)
+
+func TestSimplifyCoverage(t *testing.T) {
+	a := geom.NewPolygonFlat(geom.XY, []float64{
+		0, 0, 5, 0, 5.1, 2, 4.9, 4, 5.1, 6, 4.9, 8, 5, 10, 0, 10, 0, 5.05, 0, 0,
+	}, []int{20})
+	b := geom.NewPolygonFlat(geom.XY, []float64{
+		5, 0, 10, 0, 10, 10, 5, 10, 4.9, 8, 5.1, 6, 4.9, 4, 5.1, 2, 5, 0,
+	}, []int{18}).SetSRID(4326)
+	island := []float64{20, 20, 22, 20.1, 24, 20, 24, 24, 20, 24, 20, 20}
+	c := geom.NewMultiPolygonFlat(geom.XY, append([]float64{
+		15, 15, 30, 15, 30, 30, 15, 30, 15, 15,
+		20, 20, 20, 24, 24, 24, 24, 20, 22, 20.1, 20, 20,
+	}, island...), [][]int{{10, 22}, {34}})
+	tiny := geom.NewPolygonFlat(geom.XY, []float64{40, 40, 40.1, 40, 40.1, 40.1, 40, 40}, []int{8})
+
+	got, err := SimplifyCoverage([]geom.T{a, b, c, tiny}, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []geom.T{
+		geom.NewPolygonFlat(geom.XY, []float64{5, 0, 5, 10, 0, 10, 0, 0, 5, 0}, []int{10}),
+		geom.NewPolygonFlat(geom.XY, []float64{5, 0, 10, 0, 10, 10, 5, 10, 5, 0}, []int{10}).SetSRID(4326),
+		geom.NewMultiPolygonFlat(geom.XY, []float64{
+			15, 15, 30, 15, 30, 30, 15, 30, 15, 15,
+			20, 20, 20, 24, 24, 24, 24, 20, 20, 20,
+			20, 20, 24, 20, 24, 24, 20, 24, 20, 20,
+		}, [][]int{{10, 20}, {30}}),
+		geom.NewPolygonFlat(geom.XY, nil, nil),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SimplifyCoverage(...) == %v, want %v", got, want)
+	}
+
+	if _, err := SimplifyCoverage([]geom.T{a, geom.NewPolygon(geom.XYZ)}, 1); err == nil {
+		t.Errorf("SimplifyCoverage(mixed layouts, ...) == _, <nil>, want _, !<nil>")
+	}
+	if _, err := SimplifyCoverage([]geom.T{geom.NewLineString(geom.XY)}, 1); err == nil {
+		t.Errorf("SimplifyCoverage(linestring, ...) == _, <nil>, want _, !<nil>")
+	}
+}
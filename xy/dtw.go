@@ -0,0 +1,63 @@
+package xy
+
+import (
+	"math"
+
+	"github.com/twpayne/go-geom"
+)
+
+// DTWDistance returns the dynamic time warping distance between ls1 and ls2,
+// the smallest sum of the distances between pairs of their coordinates over
+// all monotonic alignments that pair every coordinate of each with at least
+// one coordinate of the other. Unlike the Fréchet and Hausdorff distances, it
+// measures the similarity of whole traces, and it is not sensitive to
+// different sampling rates, which makes it suitable for clustering GPS
+// traces.
+//
+// Distances between coordinates are measured with distance, or with Distance
+// if distance is nil. For longitudes and latitudes, geo.Distance measures
+// them in meters. DTWDistance returns +Inf if exactly one of ls1 and ls2 is
+// empty. It takes time proportional to the product of the numbers of
+// coordinates and memory proportional to the smaller.
+func DTWDistance(ls1, ls2 *geom.LineString, distance func(a, b geom.Coord) float64) float64 {
+	if distance == nil {
+		distance = Distance
+	}
+	n1, n2 := ls1.NumCoords(), ls2.NumCoords()
+	switch {
+	case n1 == 0 && n2 == 0:
+		return 0
+	case n1 == 0 || n2 == 0:
+		return math.Inf(1)
+	}
+	if n2 > n1 {
+		ls1, ls2, n1, n2 = ls2, ls1, n2, n1
+		d := distance
+		distance = func(a, b geom.Coord) float64 { return d(b, a) }
+	}
+	flatCoords1, stride1 := ls1.FlatCoords(), ls1.Stride()
+	flatCoords2, stride2 := ls2.FlatCoords(), ls2.Stride()
+
+	// prev and curr are the costs of aligning the coordinates of ls1 up to
+	// rows i-1 and i with the coordinates of ls2 up to each column.
+	prev := make([]float64, n2)
+	curr := make([]float64, n2)
+	for i := 0; i < n1; i++ {
+		a := geom.Coord(flatCoords1[i*stride1 : (i+1)*stride1])
+		for j := 0; j < n2; j++ {
+			cost := distance(a, geom.Coord(flatCoords2[j*stride2:(j+1)*stride2]))
+			switch {
+			case i == 0 && j == 0:
+				curr[j] = cost
+			case i == 0:
+				curr[j] = cost + curr[j-1]
+			case j == 0:
+				curr[j] = cost + prev[j]
+			default:
+				curr[j] = cost + math.Min(prev[j-1], math.Min(prev[j], curr[j-1]))
+			}
+		}
+		prev, curr = curr, prev
+	}
+	return prev[n2-1]
+}
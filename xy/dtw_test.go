@@ -0,0 +1,73 @@
+package xy
+
+import (
+	"math"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+)
+
+func TestDTWDistance(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		ls1      *geom.LineString
+		ls2      *geom.LineString
+		distance func(a, b geom.Coord) float64
+		want     float64
+	}{
+		{
+			name: "identical",
+			ls1:  geom.NewLineStringFlat(geom.XY, []float64{0, 0, 1, 0, 2, 0}),
+			ls2:  geom.NewLineStringFlat(geom.XY, []float64{0, 0, 1, 0, 2, 0}),
+			want: 0,
+		},
+		{
+			name: "resampled",
+			ls1:  geom.NewLineStringFlat(geom.XY, []float64{0, 0, 2, 0}),
+			ls2:  geom.NewLineStringFlat(geom.XY, []float64{0, 0, 0, 0, 0, 0, 2, 0, 2, 0}),
+			want: 0,
+		},
+		{
+			name: "offset",
+			ls1:  geom.NewLineStringFlat(geom.XY, []float64{0, 0, 1, 0, 2, 0}),
+			ls2:  geom.NewLineStringFlat(geom.XYZ, []float64{0, 1, 5, 2, 1, 5}),
+			want: 1 + math.Sqrt2 + 1,
+		},
+		{
+			name: "warped",
+			ls1:  geom.NewLineStringFlat(geom.XY, []float64{0, 0, 1, 0, 2, 0, 3, 0}),
+			ls2:  geom.NewLineStringFlat(geom.XY, []float64{0, 0, 3, 0}),
+			want: 1 + 1,
+		},
+		{
+			name: "distance_func",
+			ls1:  geom.NewLineStringFlat(geom.XY, []float64{0, 0, 1, 0}),
+			ls2:  geom.NewLineStringFlat(geom.XY, []float64{0, 3, 1, 3}),
+			distance: func(a, b geom.Coord) float64 {
+				return math.Abs(a.X()-b.X()) + math.Abs(a.Y()-b.Y())
+			},
+			want: 6,
+		},
+		{
+			name: "empty",
+			ls1:  geom.NewLineString(geom.XY),
+			ls2:  geom.NewLineString(geom.XY),
+			want: 0,
+		},
+		{
+			name: "one_empty",
+			ls1:  geom.NewLineStringFlat(geom.XY, []float64{0, 0, 1, 0}),
+			ls2:  geom.NewLineString(geom.XY),
+			want: math.Inf(1),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := DTWDistance(tc.ls1, tc.ls2, tc.distance); math.Abs(got-tc.want) > 1e-9 && got != tc.want {
+				t.Errorf("DTWDistance(ls1, ls2, _) == %v, want %v", got, tc.want)
+			}
+			if got := DTWDistance(tc.ls2, tc.ls1, tc.distance); math.Abs(got-tc.want) > 1e-9 && got != tc.want {
+				t.Errorf("DTWDistance(ls2, ls1, _) == %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package xy
+
+import (
+	"math"
+
+	"github.com/twpayne/go-geom"
+)
+
+// An Ellipse is an ellipse in the XY plane.
+type Ellipse struct {
+	Center        geom.Coord
+	SemiMajorAxis float64
+	SemiMinorAxis float64
+	// Rotation is the angle of the major axis counterclockwise from the X
+	// axis, in radians, in the range [0, π).
+	Rotation float64
+}
+
+// StandardDeviationalEllipse returns the standard deviational ellipse of
+// points, which summarizes their central tendency, dispersion, and
+// directional trend. Its center is the mean center of points, its axes are
+// the principal axes of their covariance, and its semi-axes are the
+// population standard deviations of the points along them. Multiplying the
+// semi-axes by √2 gives the ellipse that some tools report, and by 2 or 3
+// gives ellipses that contain most points of normally distributed data. It
+// returns an Ellipse with a nil Center if points is empty.
+func StandardDeviationalEllipse(points *geom.MultiPoint) Ellipse {
+	flatCoords, stride := points.FlatCoords(), points.Stride()
+	if len(flatCoords) == 0 {
+		return Ellipse{}
+	}
+	n := len(flatCoords) / stride
+	var meanX, meanY float64
+	for i := 0; i < len(flatCoords); i += stride {
+		meanX += flatCoords[i]
+		meanY += flatCoords[i+1]
+	}
+	meanX /= float64(n)
+	meanY /= float64(n)
+	var sxx, syy, sxy float64
+	for i := 0; i < len(flatCoords); i += stride {
+		dx, dy := flatCoords[i]-meanX, flatCoords[i+1]-meanY
+		sxx += dx * dx
+		syy += dy * dy
+		sxy += dx * dy
+	}
+	sxx /= float64(n)
+	syy /= float64(n)
+	sxy /= float64(n)
+
+	// The eigenvalues of the covariance matrix are the variances along the
+	// principal axes.
+	mean := (sxx + syy) / 2
+	diff := math.Hypot((sxx-syy)/2, sxy)
+	rotation := math.Atan2(2*sxy, sxx-syy) / 2
+	if rotation < 0 {
+		rotation += math.Pi
+	}
+	return Ellipse{
+		Center:        geom.Coord{meanX, meanY},
+		SemiMajorAxis: math.Sqrt(mean + diff),
+		SemiMinorAxis: math.Sqrt(math.Max(mean-diff, 0)),
+		Rotation:      rotation,
+	}
+}
+
+// Polygon returns a counterclockwise polygon approximating e with n
+// vertices, where n is at least three, starting at the end of the major axis in the direction of
+// Rotation.
+func (e Ellipse) Polygon(n int) *geom.Polygon {
+	sin, cos := math.Sincos(e.Rotation)
+	flatCoords := make([]float64, 0, 2*(n+1))
+	for i := 0; i < n; i++ {
+		a, b := math.Sincos(2 * math.Pi * float64(i) / float64(n))
+		u, v := e.SemiMajorAxis*b, e.SemiMinorAxis*a
+		flatCoords = append(flatCoords, e.Center.X()+u*cos-v*sin, e.Center.Y()+u*sin+v*cos)
+	}
+	flatCoords = append(flatCoords, flatCoords[0], flatCoords[1])
+	return geom.NewPolygonFlat(geom.XY, flatCoords, []int{len(flatCoords)})
+}
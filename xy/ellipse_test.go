@@ -0,0 +1,93 @@
+package xy
+
+import (
+	"math"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+)
+
+func TestStandardDeviationalEllipse(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		points *geom.MultiPoint
+		want   Ellipse
+	}{
+		{
+			name:   "empty",
+			points: geom.NewMultiPoint(geom.XY),
+			want:   Ellipse{},
+		},
+		{
+			name:   "single",
+			points: geom.NewMultiPointFlat(geom.XY, []float64{1, 2}),
+			want:   Ellipse{Center: geom.Coord{1, 2}},
+		},
+		{
+			name:   "x_axis",
+			points: geom.NewMultiPointFlat(geom.XY, []float64{-2, 5, 2, 5, 0, 6, 0, 4}),
+			want: Ellipse{
+				Center:        geom.Coord{0, 5},
+				SemiMajorAxis: math.Sqrt2,
+				SemiMinorAxis: math.Sqrt(0.5),
+			},
+		},
+		{
+			name:   "y_axis",
+			points: geom.NewMultiPointFlat(geom.XYZ, []float64{0, -2, 9, 0, 2, 9, 1, 0, 9, -1, 0, 9}),
+			want: Ellipse{
+				Center:        geom.Coord{0, 0},
+				SemiMajorAxis: math.Sqrt2,
+				SemiMinorAxis: math.Sqrt(0.5),
+				Rotation:      math.Pi / 2,
+			},
+		},
+		{
+			name:   "diagonal_line",
+			points: geom.NewMultiPointFlat(geom.XY, []float64{-1, 1, 0, 0, 1, -1}),
+			want: Ellipse{
+				Center:        geom.Coord{0, 0},
+				SemiMajorAxis: 2 / math.Sqrt(3),
+				Rotation:      3 * math.Pi / 4,
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := StandardDeviationalEllipse(tc.points)
+			if !ellipsesAlmostEqual(got, tc.want) {
+				t.Errorf("StandardDeviationalEllipse(...) == %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func ellipsesAlmostEqual(e1, e2 Ellipse) bool {
+	const epsilon = 1e-9
+	if (e1.Center == nil) != (e2.Center == nil) {
+		return false
+	}
+	if e1.Center != nil && (math.Abs(e1.Center.X()-e2.Center.X()) > epsilon || math.Abs(e1.Center.Y()-e2.Center.Y()) > epsilon) {
+		return false
+	}
+	return math.Abs(e1.SemiMajorAxis-e2.SemiMajorAxis) < epsilon &&
+		math.Abs(e1.SemiMinorAxis-e2.SemiMinorAxis) < epsilon &&
+		math.Abs(e1.Rotation-e2.Rotation) < epsilon
+}
+
+func TestEllipsePolygon(t *testing.T) {
+	e := Ellipse{Center: geom.Coord{10, 20}, SemiMajorAxis: 2, SemiMinorAxis: 1, Rotation: math.Pi / 2}
+	p := e.Polygon(4)
+	want := []float64{10, 22, 9, 20, 10, 18, 11, 20, 10, 22}
+	got := p.FlatCoords()
+	if len(got) != len(want) {
+		t.Fatalf("e.Polygon(4).FlatCoords() == %v, want %v", got, want)
+	}
+	for i := range want {
+		if math.Abs(got[i]-want[i]) > 1e-9 {
+			t.Fatalf("e.Polygon(4).FlatCoords() == %v, want %v", got, want)
+		}
+	}
+	if area := e.Polygon(3600).Area(); math.Abs(area-2*math.Pi) > 1e-3 {
+		t.Errorf("e.Polygon(3600).Area() == %v, want %v", area, 2*math.Pi)
+	}
+}
@@ -0,0 +1,201 @@
+package xy
+
+import (
+	"math"
+	"sort"
+
+	"github.com/twpayne/go-geom"
+)
+
+// A Grid is a raster of nx by ny cells covering bounds, whose values are
+// looked up with a callback so that any raster source, such as an elevation
+// model, can be sampled. Cell (i, j) covers the half-open rectangle from
+// (minX+i*dx, minY+j*dy) to (minX+(i+1)*dx, minY+(j+1)*dy), where dx and dy
+// are the width and height of a cell, so row 0 is the southernmost row.
+type Grid struct {
+	Bounds *geom.Bounds
+	NX, NY int
+	// Value returns the value of cell (i, j), or false if the cell has no
+	// data.
+	Value func(i, j int) (float64, bool)
+}
+
+// A ProfileSample is a value sampled along a LineString.
+type ProfileSample struct {
+	Distance float64 // distance along the LineString
+	X, Y     float64
+	Value    float64
+	OK       bool // false if the sample is outside the grid or has no data
+}
+
+// ZonalStats are statistics of the values of the cells of a Grid inside a
+// polygon.
+type ZonalStats struct {
+	Count int // number of cells with data
+	Sum   float64
+	Min   float64
+	Max   float64
+}
+
+// Cell returns the indexes of the cell containing (x, y), or false if (x, y)
+// is outside g.
+func (g *Grid) Cell(x, y float64) (int, int, bool) {
+	dx, dy := g.cellSize()
+	i := int(math.Floor((x - g.Bounds.Min(0)) / dx))
+	j := int(math.Floor((y - g.Bounds.Min(1)) / dy))
+	// Points on the maximum edges belong to the last cells.
+	if i == g.NX && x == g.Bounds.Max(0) {
+		i--
+	}
+	if j == g.NY && y == g.Bounds.Max(1) {
+		j--
+	}
+	if i < 0 || i >= g.NX || j < 0 || j >= g.NY {
+		return 0, 0, false
+	}
+	return i, j, true
+}
+
+// Sample returns the value of the cell containing (x, y), or false if (x, y)
+// is outside g or its cell has no data.
+func (g *Grid) Sample(x, y float64) (float64, bool) {
+	i, j, ok := g.Cell(x, y)
+	if !ok {
+		return 0, false
+	}
+	return g.Value(i, j)
+}
+
+// Profile samples grid along ls every spacing units of distance, starting at
+// the first coordinate of ls and always including its last coordinate.
+func Profile(ls *geom.LineString, grid *Grid, spacing float64) []ProfileSample {
+	flatCoords, stride := ls.FlatCoords(), ls.Stride()
+	if len(flatCoords) == 0 {
+		return nil
+	}
+	var samples []ProfileSample
+	sample := func(distance, x, y float64) {
+		value, ok := grid.Sample(x, y)
+		samples = append(samples, ProfileSample{
+			Distance: distance,
+			X:        x,
+			Y:        y,
+			Value:    value,
+			OK:       ok,
+		})
+	}
+	sample(0, flatCoords[0], flatCoords[1])
+	next := spacing
+	distance := 0.0
+	for i := stride; i < len(flatCoords); i += stride {
+		x0, y0 := flatCoords[i-stride], flatCoords[i-stride+1]
+		x1, y1 := flatCoords[i], flatCoords[i+1]
+		segmentLength := math.Hypot(x1-x0, y1-y0)
+		for spacing > 0 && next < distance+segmentLength {
+			t := (next - distance) / segmentLength
+			sample(next, x0+t*(x1-x0), y0+t*(y1-y0))
+			next += spacing
+		}
+		distance += segmentLength
+	}
+	if len(flatCoords) > stride {
+		sample(distance, flatCoords[len(flatCoords)-stride], flatCoords[len(flatCoords)-stride+1])
+	}
+	return samples
+}
+
+// Zonal returns statistics of the values of the cells of grid whose centers
+// are inside g, which must be a Polygon or a MultiPolygon.
+func Zonal(g geom.T, grid *Grid) (ZonalStats, error) {
+	var stats ZonalStats
+	err := scanCells(g, grid, func(i, j int) {
+		value, ok := grid.Value(i, j)
+		if !ok {
+			return
+		}
+		if stats.Count == 0 || value < stats.Min {
+			stats.Min = value
+		}
+		if stats.Count == 0 || value > stats.Max {
+			stats.Max = value
+		}
+		stats.Count++
+		stats.Sum += value
+	})
+	return stats, err
+}
+
+// Mean returns the mean value, or NaN if s has no values.
+func (s ZonalStats) Mean() float64 {
+	if s.Count == 0 {
+		return math.NaN()
+	}
+	return s.Sum / float64(s.Count)
+}
+
+func (g *Grid) cellSize() (float64, float64) {
+	return (g.Bounds.Max(0) - g.Bounds.Min(0)) / float64(g.NX), (g.Bounds.Max(1) - g.Bounds.Min(1)) / float64(g.NY)
+}
+
+// polygonRings returns the flat coordinates and ring ends of g, which must be
+// a Polygon or a MultiPolygon.
+func polygonRings(g geom.T) ([]float64, []int, error) {
+	switch g := g.(type) {
+	case *geom.Polygon:
+		return g.FlatCoords(), g.Ends(), nil
+	case *geom.MultiPolygon:
+		var ends []int
+		for _, polygonEnds := range g.Endss() {
+			ends = append(ends, polygonEnds...)
+		}
+		return g.FlatCoords(), ends, nil
+	default:
+		return nil, nil, geom.ErrUnsupportedType{Value: g}
+	}
+}
+
+// scanCells calls fn for each cell of grid whose center is inside g, which
+// must be a Polygon or a MultiPolygon, using a scanline with the even-odd
+// rule so that holes are excluded.
+func scanCells(g geom.T, grid *Grid, fn func(i, j int)) error {
+	flatCoords, ends, err := polygonRings(g)
+	if err != nil {
+		return err
+	}
+	stride := g.Stride()
+	minX, minY := grid.Bounds.Min(0), grid.Bounds.Min(1)
+	dx, dy := grid.cellSize()
+	var xs []float64
+	for j := 0; j < grid.NY; j++ {
+		y := minY + (float64(j)+0.5)*dy
+		xs = xs[:0]
+		start := 0
+		for _, end := range ends {
+			for k := start + stride; k < end; k += stride {
+				ax, ay := flatCoords[k-stride], flatCoords[k-stride+1]
+				bx, by := flatCoords[k], flatCoords[k+1]
+				if (ay > y) != (by > y) {
+					xs = append(xs, ax+(y-ay)*(bx-ax)/(by-ay))
+				}
+			}
+			start = end
+		}
+		sort.Float64s(xs)
+		for k := 0; k+1 < len(xs); k += 2 {
+			// Cell i's center is inside [xs[k], xs[k+1]) if
+			// xs[k] <= minX+(i+0.5)*dx < xs[k+1].
+			i0 := int(math.Ceil((xs[k]-minX)/dx - 0.5))
+			i1 := int(math.Ceil((xs[k+1]-minX)/dx - 0.5))
+			if i0 < 0 {
+				i0 = 0
+			}
+			if i1 > grid.NX {
+				i1 = grid.NX
+			}
+			for i := i0; i < i1; i++ {
+				fn(i, j)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package xy
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+)
+
+func newTestGrid() *Grid {
+	return &Grid{
+		Bounds: geom.NewBounds(geom.XY).Set(0, 0, 10, 10),
+		NX:     10,
+		NY:     10,
+		Value: func(i, j int) (float64, bool) {
+			return float64(i + 10*j), j != 9
+		},
+	}
+}
+
+func TestGridSample(t *testing.T) {
+	grid := newTestGrid()
+	for _, tc := range []struct {
+		x, y  float64
+		value float64
+		ok    bool
+	}{
+		{x: 0.5, y: 0.5, value: 0, ok: true},
+		{x: 3, y: 2.5, value: 23, ok: true},
+		{x: 10, y: 0, value: 9, ok: true},
+		{x: 5, y: 9.5, value: 95, ok: false},
+		{x: -1, y: 0, ok: false},
+		{x: 10.5, y: 0, ok: false},
+	} {
+		if value, ok := grid.Sample(tc.x, tc.y); value != tc.value || ok != tc.ok {
+			t.Errorf("grid.Sample(%f, %f) == %f, %t, want %f, %t", tc.x, tc.y, value, ok, tc.value, tc.ok)
+		}
+	}
+}
+
+func TestProfile(t *testing.T) {
+	ls := geom.NewLineStringFlat(geom.XY, []float64{0, 0.5, 6, 0.5, 6, 4.5})
+	want := []ProfileSample{
+		{Distance: 0, X: 0, Y: 0.5, Value: 0, OK: true},
+		{Distance: 2.5, X: 2.5, Y: 0.5, Value: 2, OK: true},
+		{Distance: 5, X: 5, Y: 0.5, Value: 5, OK: true},
+		{Distance: 7.5, X: 6, Y: 2, Value: 26, OK: true},
+		{Distance: 10, X: 6, Y: 4.5, Value: 46, OK: true},
+	}
+	if got := Profile(ls, newTestGrid(), 2.5); !reflect.DeepEqual(got, want) {
+		t.Errorf("Profile(...) == %v, want %v", got, want)
+	}
+	if got := Profile(geom.NewLineString(geom.XY), newTestGrid(), 1); got != nil {
+		t.Errorf("Profile(empty, ...) == %v, want nil", got)
+	}
+}
+
+func TestZonal(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		g    geom.T
+		want ZonalStats
+	}{
+		{
+			name: "square",
+			g:    geom.NewPolygonFlat(geom.XY, []float64{2, 2, 5, 2, 5, 5, 2, 5, 2, 2}, []int{10}),
+			want: ZonalStats{Count: 9, Sum: 297, Min: 22, Max: 44},
+		},
+		{
+			name: "square_with_hole",
+			g: geom.NewPolygonFlat(geom.XY, []float64{
+				2, 2, 5, 2, 5, 5, 2, 5, 2, 2,
+				3, 3, 4, 3, 4, 4, 3, 4, 3, 3,
+			}, []int{10, 20}),
+			want: ZonalStats{Count: 8, Sum: 264, Min: 22, Max: 44},
+		},
+		{
+			name: "multipolygon_partly_outside",
+			g: geom.NewMultiPolygonFlat(geom.XY, []float64{
+				-5, -5, 1, -5, 1, 1, -5, 1, -5, -5,
+				8, 8, 15, 8, 15, 15, 8, 15, 8, 8,
+			}, [][]int{{10}, {20}}),
+			want: ZonalStats{Count: 3, Sum: 0 + 88 + 89, Min: 0, Max: 89},
+		},
+		{
+			name: "outside",
+			g:    geom.NewPolygonFlat(geom.XY, []float64{20, 20, 21, 20, 21, 21, 20, 20}, []int{8}),
+			want: ZonalStats{},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Zonal(tc.g, newTestGrid())
+			if err != nil || got != tc.want {
+				t.Errorf("Zonal(...) == %v, %v, want %v, <nil>", got, err, tc.want)
+			}
+		})
+	}
+	if _, err := Zonal(geom.NewPointFlat(geom.XY, []float64{1, 2}), newTestGrid()); err == nil {
+		t.Errorf("Zonal(point, ...) == _, <nil>, want _, !<nil>")
+	}
+	if mean := (ZonalStats{}).Mean(); !math.IsNaN(mean) {
+		t.Errorf("ZonalStats{}.Mean() == %f, want NaN", mean)
+	}
+}
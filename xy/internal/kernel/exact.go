@@ -0,0 +1,102 @@
+package kernel
+
+import (
+	"math"
+	"math/big"
+)
+
+// sqrtPrec is the precision, in bits, with which square roots of exact values
+// are computed before rounding to a float64.
+const sqrtPrec = 128
+
+type exactKernel struct{}
+
+func (exactKernel) Distance(x1, y1, x2, y2 float64) float64 {
+	if !finite(x1, y1, x2, y2) {
+		return Float64.Distance(x1, y1, x2, y2)
+	}
+	dx, dy := sub(x2, x1), sub(y2, y1)
+	return sqrt(dx.Add(dx.Mul(dx, dx), new(big.Rat).Mul(dy, dy)))
+}
+
+func (k exactKernel) DistanceToSegment(px, py, ax, ay, bx, by float64) float64 {
+	if !finite(px, py, ax, ay, bx, by) {
+		return Float64.DistanceToSegment(px, py, ax, ay, bx, by)
+	}
+	if ax == bx && ay == by {
+		return k.Distance(px, py, ax, ay)
+	}
+	dx, dy := sub(bx, ax), sub(by, ay)
+	apx, apy := sub(px, ax), sub(py, ay)
+	dot := new(big.Rat).Mul(apx, dx)
+	dot.Add(dot, new(big.Rat).Mul(apy, dy))
+	if dot.Sign() <= 0 {
+		return k.Distance(px, py, ax, ay)
+	}
+	len2 := new(big.Rat).Mul(dx, dx)
+	len2.Add(len2, new(big.Rat).Mul(dy, dy))
+	if dot.Cmp(len2) >= 0 {
+		return k.Distance(px, py, bx, by)
+	}
+	// The distance is |AP x AB| / |AB|.
+	cross := new(big.Rat).Mul(apx, dy)
+	cross.Sub(cross, new(big.Rat).Mul(apy, dx))
+	cross.Mul(cross, cross)
+	return sqrt(cross.Quo(cross, len2))
+}
+
+func (exactKernel) Orientation(ax, ay, bx, by, cx, cy float64) int {
+	if !finite(ax, ay, bx, by, cx, cy) {
+		return Float64.Orientation(ax, ay, bx, by, cx, cy)
+	}
+	det := new(big.Rat).Mul(sub(bx, ax), sub(cy, by))
+	return det.Sub(det, new(big.Rat).Mul(sub(by, ay), sub(cx, bx))).Sign()
+}
+
+func (exactKernel) SignedArea(flatCoords []float64, stride int) float64 {
+	if len(flatCoords) < 3*stride {
+		return 0
+	}
+	for i := 0; i < len(flatCoords); i += stride {
+		if !finite(flatCoords[i], flatCoords[i+1]) {
+			return Float64.SignedArea(flatCoords, stride)
+		}
+	}
+	sum := new(big.Rat)
+	term := new(big.Rat)
+	x0 := flatCoords[0]
+	for i := stride; i < len(flatCoords)-stride; i += stride {
+		term.Mul(sub(flatCoords[i], x0), sub(flatCoords[i-stride+1], flatCoords[i+stride+1]))
+		sum.Add(sum, term)
+	}
+	area, _ := sum.Quo(sum, big.NewRat(2, 1)).Float64()
+	return area
+}
+
+// sub returns a - b exactly.
+func sub(a, b float64) *big.Rat {
+	r := new(big.Rat).SetFloat64(a)
+	return r.Sub(r, new(big.Rat).SetFloat64(b))
+}
+
+// sqrt returns the square root of the non-negative r rounded to a float64.
+func sqrt(r *big.Rat) float64 {
+	if r.Sign() == 0 {
+		return 0
+	}
+	f := new(big.Float).SetPrec(sqrtPrec).SetRat(r)
+	result, _ := f.Sqrt(f).Float64()
+	return result
+}
+
+// finite returns true if all of fs are finite. Exact arithmetic is not
+// possible with infinities or NaNs, so the exact kernel falls back to the
+// Float64 kernel for them.
+func finite(fs ...float64) bool {
+	for _, f := range fs {
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return false
+		}
+	}
+	return true
+}
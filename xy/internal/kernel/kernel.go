@@ -0,0 +1,138 @@
+// Package kernel contains the arithmetic kernels used by the xy package.
+//
+// Each kernel implements the same primitive operations on x and y ordinates
+// with a different trade-off between speed and robustness.
+package kernel
+
+import "math"
+
+// A Kernel implements primitive planar computations.
+type Kernel interface {
+	// Distance returns the distance between (x1, y1) and (x2, y2).
+	Distance(x1, y1, x2, y2 float64) float64
+	// DistanceToSegment returns the distance from (px, py) to the segment
+	// from (ax, ay) to (bx, by).
+	DistanceToSegment(px, py, ax, ay, bx, by float64) float64
+	// Orientation returns 1 if (cx, cy) is to the left of the vector from
+	// (ax, ay) to (bx, by), -1 if it is to the right, and 0 if the points are
+	// collinear.
+	Orientation(ax, ay, bx, by, cx, cy float64) int
+	// SignedArea returns the signed area of the ring in flatCoords, which is
+	// positive if the ring is clockwise.
+	SignedArea(flatCoords []float64, stride int) float64
+}
+
+var (
+	// Float32 computes in single precision. It is faster on some platforms,
+	// notably WebAssembly and embedded targets without double precision
+	// hardware, at the cost of accuracy.
+	Float32 Kernel = float32Kernel{}
+	// Float64 computes in double precision.
+	Float64 Kernel = float64Kernel{}
+	// Exact computes exactly with arbitrary precision arithmetic, rounding
+	// only the final result. Orientations are always correct.
+	Exact Kernel = exactKernel{}
+)
+
+type float64Kernel struct{}
+
+func (float64Kernel) Distance(x1, y1, x2, y2 float64) float64 {
+	dx, dy := x2-x1, y2-y1
+	return math.Sqrt(dx*dx + dy*dy)
+}
+
+func (k float64Kernel) DistanceToSegment(px, py, ax, ay, bx, by float64) float64 {
+	if ax == bx && ay == by {
+		return k.Distance(px, py, ax, ay)
+	}
+	dx, dy := bx-ax, by-ay
+	len2 := dx*dx + dy*dy
+	r := ((px-ax)*dx + (py-ay)*dy) / len2
+	switch {
+	case r <= 0:
+		return k.Distance(px, py, ax, ay)
+	case r >= 1:
+		return k.Distance(px, py, bx, by)
+	}
+	s := ((ay-py)*dx - (ax-px)*dy) / len2
+	return math.Abs(s) * math.Sqrt(len2)
+}
+
+func (float64Kernel) Orientation(ax, ay, bx, by, cx, cy float64) int {
+	return sign((bx-ax)*(cy-by) - (by-ay)*(cx-bx))
+}
+
+func (float64Kernel) SignedArea(flatCoords []float64, stride int) float64 {
+	if len(flatCoords) < 3*stride {
+		return 0
+	}
+	sum := 0.0
+	x0 := flatCoords[0]
+	for i := stride; i < len(flatCoords)-stride; i += stride {
+		sum += (flatCoords[i] - x0) * (flatCoords[i-stride+1] - flatCoords[i+stride+1])
+	}
+	return sum / 2
+}
+
+type float32Kernel struct{}
+
+func (float32Kernel) Distance(x1, y1, x2, y2 float64) float64 {
+	return float64(distance32(float32(x1), float32(y1), float32(x2), float32(y2)))
+}
+
+func (float32Kernel) DistanceToSegment(px, py, ax, ay, bx, by float64) float64 {
+	px32, py32 := float32(px), float32(py)
+	ax32, ay32 := float32(ax), float32(ay)
+	bx32, by32 := float32(bx), float32(by)
+	if ax32 == bx32 && ay32 == by32 {
+		return float64(distance32(px32, py32, ax32, ay32))
+	}
+	dx, dy := bx32-ax32, by32-ay32
+	len2 := float32(dx*dx) + float32(dy*dy)
+	r := (float32((px32-ax32)*dx) + float32((py32-ay32)*dy)) / len2
+	switch {
+	case r <= 0:
+		return float64(distance32(px32, py32, ax32, ay32))
+	case r >= 1:
+		return float64(distance32(px32, py32, bx32, by32))
+	}
+	cross := float32((ay32-py32)*dx) - float32((ax32-px32)*dy)
+	return math.Abs(float64(cross / float32(math.Sqrt(float64(len2)))))
+}
+
+func (float32Kernel) Orientation(ax, ay, bx, by, cx, cy float64) int {
+	ax32, ay32 := float32(ax), float32(ay)
+	bx32, by32 := float32(bx), float32(by)
+	cx32, cy32 := float32(cx), float32(cy)
+	return sign(float64(float32((bx32-ax32)*(cy32-by32)) - float32((by32-ay32)*(cx32-bx32))))
+}
+
+func (float32Kernel) SignedArea(flatCoords []float64, stride int) float64 {
+	if len(flatCoords) < 3*stride {
+		return 0
+	}
+	var sum float32
+	x0 := float32(flatCoords[0])
+	for i := stride; i < len(flatCoords)-stride; i += stride {
+		sum += float32((float32(flatCoords[i]) - x0) * (float32(flatCoords[i-stride+1]) - float32(flatCoords[i+stride+1])))
+	}
+	return float64(sum / 2)
+}
+
+// distance32 returns the distance between (x1, y1) and (x2, y2) computed in
+// single precision.
+func distance32(x1, y1, x2, y2 float32) float32 {
+	dx, dy := x2-x1, y2-y1
+	return float32(math.Sqrt(float64(float32(dx*dx) + float32(dy*dy))))
+}
+
+func sign(f float64) int {
+	switch {
+	case f > 0:
+		return 1
+	case f < 0:
+		return -1
+	default:
+		return 0
+	}
+}
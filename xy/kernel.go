@@ -0,0 +1,99 @@
+package xy
+
+import (
+	"fmt"
+
+	geom "github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/xy/internal/kernel"
+	"github.com/twpayne/go-geom/xy/orientation"
+)
+
+// A Kernel selects the arithmetic used for distance, area, and orientation
+// computations. The same functions are available for every Kernel, so the
+// trade-off between speed and robustness can be chosen at call time.
+type Kernel int
+
+const (
+	// Float64Kernel computes in double precision. It is the arithmetic used
+	// by the package-level functions, except for OrientationIndex which is
+	// always robust.
+	Float64Kernel Kernel = iota
+	// Float32Kernel computes in single precision, which is smaller and faster
+	// on some embedded and WebAssembly targets at the cost of accuracy.
+	Float32Kernel
+	// ExactKernel computes with exact arbitrary precision arithmetic and
+	// rounds only the final result. Orientations are always correct. It is
+	// much slower than the other kernels.
+	ExactKernel
+)
+
+var kernelNames = [...]string{
+	Float64Kernel: "Float64Kernel",
+	Float32Kernel: "Float32Kernel",
+	ExactKernel:   "ExactKernel",
+}
+
+func (k Kernel) String() string {
+	if k < 0 || int(k) >= len(kernelNames) {
+		return fmt.Sprintf("Kernel(%d)", int(k))
+	}
+	return kernelNames[k]
+}
+
+// impl returns the implementation of k. Unknown kernels use Float64Kernel.
+func (k Kernel) impl() kernel.Kernel {
+	switch k {
+	case Float32Kernel:
+		return kernel.Float32
+	case ExactKernel:
+		return kernel.Exact
+	default:
+		return kernel.Float64
+	}
+}
+
+// Distance returns the 2D distance between c1 and c2.
+func (k Kernel) Distance(c1, c2 geom.Coord) float64 {
+	return k.impl().Distance(c1[0], c1[1], c2[0], c2[1])
+}
+
+// DistanceFromPointToLine returns the distance from p to the line segment
+// from lineStart to lineEnd.
+func (k Kernel) DistanceFromPointToLine(p, lineStart, lineEnd geom.Coord) float64 {
+	return k.impl().DistanceToSegment(p[0], p[1], lineStart[0], lineStart[1], lineEnd[0], lineEnd[1])
+}
+
+// DistanceFromPointToLineString returns the distance from p to the line
+// string line.
+func (k Kernel) DistanceFromPointToLineString(layout geom.Layout, p geom.Coord, line []float64) float64 {
+	stride := layout.Stride()
+	if len(line) < stride {
+		panic(fmt.Sprintf("Line array must contain at least one vertex: %v", line))
+	}
+	impl := k.impl()
+	minDistance := impl.Distance(p[0], p[1], line[0], line[1])
+	for i := 0; i < len(line)-stride; i += stride {
+		if d := impl.DistanceToSegment(p[0], p[1], line[i], line[i+1], line[i+stride], line[i+stride+1]); d < minDistance {
+			minDistance = d
+		}
+	}
+	return minDistance
+}
+
+// OrientationIndex returns the orientation of point relative to the vector
+// from vectorOrigin to vectorEnd.
+func (k Kernel) OrientationIndex(vectorOrigin, vectorEnd, point geom.Coord) orientation.Type {
+	return orientation.Type(k.impl().Orientation(vectorOrigin[0], vectorOrigin[1], vectorEnd[0], vectorEnd[1], point[0], point[1]))
+}
+
+// SignedArea returns the signed area of ring, which is positive if ring is
+// clockwise, as with SignedArea.
+func (k Kernel) SignedArea(layout geom.Layout, ring []float64) float64 {
+	return k.impl().SignedArea(ring, layout.Stride())
+}
+
+// IsRingCounterClockwise returns true if ring is counterclockwise according
+// to the sign of its area. Degenerate rings are not counterclockwise.
+func (k Kernel) IsRingCounterClockwise(layout geom.Layout, ring []float64) bool {
+	return k.impl().SignedArea(ring, layout.Stride()) < 0
+}
@@ -0,0 +1,65 @@
+package xy
+
+import (
+	"math"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/xy/orientation"
+)
+
+func TestKernels(t *testing.T) {
+	ring := []float64{0, 0, 0, 3, 4, 3, 4, 0, 0, 0}
+	line := []float64{0, 0, 10, 0, 10, 10}
+	for _, k := range []Kernel{Float64Kernel, Float32Kernel, ExactKernel} {
+		t.Run(k.String(), func(t *testing.T) {
+			if got := k.Distance(geom.Coord{0, 0}, geom.Coord{3, 4}); got != 5 {
+				t.Errorf("k.Distance(...) == %v, want 5", got)
+			}
+			if got := k.DistanceFromPointToLine(geom.Coord{5, 2}, geom.Coord{0, 0}, geom.Coord{10, 0}); got != 2 {
+				t.Errorf("k.DistanceFromPointToLine(...) == %v, want 2", got)
+			}
+			if got := k.DistanceFromPointToLine(geom.Coord{-3, 4}, geom.Coord{0, 0}, geom.Coord{10, 0}); got != 5 {
+				t.Errorf("k.DistanceFromPointToLine(...) == %v, want 5", got)
+			}
+			if got := k.DistanceFromPointToLineString(geom.XY, geom.Coord{12, 5}, line); got != 2 {
+				t.Errorf("k.DistanceFromPointToLineString(...) == %v, want 2", got)
+			}
+			if got := k.OrientationIndex(geom.Coord{0, 0}, geom.Coord{1, 0}, geom.Coord{1, 1}); got != orientation.CounterClockwise {
+				t.Errorf("k.OrientationIndex(...) == %v, want %v", got, orientation.CounterClockwise)
+			}
+			if got, want := k.SignedArea(geom.XY, ring), SignedArea(geom.XY, ring); got != want {
+				t.Errorf("k.SignedArea(...) == %v, want %v", got, want)
+			}
+			if got, want := k.IsRingCounterClockwise(geom.XY, ring), IsRingCounterClockwise(geom.XY, ring); got != want {
+				t.Errorf("k.IsRingCounterClockwise(...) == %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestKernelPrecision(t *testing.T) {
+	// The point is a tiny distance to the left of the line, which double
+	// precision arithmetic considers collinear.
+	a, b, c := geom.Coord{12, 12}, geom.Coord{24, 24}, geom.Coord{0.5, 0.5000000000000001}
+	if got := Float64Kernel.OrientationIndex(a, b, c); got != orientation.Collinear {
+		t.Errorf("Float64Kernel.OrientationIndex(...) == %v, want %v", got, orientation.Collinear)
+	}
+	if got := ExactKernel.OrientationIndex(a, b, c); got != orientation.CounterClockwise {
+		t.Errorf("ExactKernel.OrientationIndex(...) == %v, want %v", got, orientation.CounterClockwise)
+	}
+
+	// Single precision cannot represent the difference between these
+	// coordinates.
+	p1, p2 := geom.Coord{1e8, 0}, geom.Coord{1e8 + 1, 0}
+	if got := Float32Kernel.Distance(p1, p2); got != 0 {
+		t.Errorf("Float32Kernel.Distance(...) == %v, want 0", got)
+	}
+	if got := ExactKernel.Distance(p1, p2); got != 1 {
+		t.Errorf("ExactKernel.Distance(...) == %v, want 1", got)
+	}
+
+	if got := ExactKernel.Distance(geom.Coord{0, 0}, geom.Coord{math.Inf(1), 0}); !math.IsInf(got, 1) {
+		t.Errorf("ExactKernel.Distance(...) == %v, want +Inf", got)
+	}
+}
@@ -0,0 +1,140 @@
+package xy
+
+import (
+	"math"
+
+	"github.com/twpayne/go-geom"
+)
+
+// Rasterize returns a mask of the nx by ny cells covering bounds, in which a
+// cell is true if its center is inside g, which must be a Polygon or a
+// MultiPolygon. The cells are laid out as for a Grid, and cell (i, j) is
+// element j*nx+i of the mask.
+func Rasterize(g geom.T, bounds *geom.Bounds, nx, ny int) ([]bool, error) {
+	mask := make([]bool, nx*ny)
+	grid := &Grid{
+		Bounds: bounds,
+		NX:     nx,
+		NY:     ny,
+	}
+	if err := scanCells(g, grid, func(i, j int) {
+		mask[j*nx+i] = true
+	}); err != nil {
+		return nil, err
+	}
+	return mask, nil
+}
+
+// RasterizeFractions is like Rasterize but returns the fraction of the area
+// of each cell that is covered by g, between 0 and 1.
+func RasterizeFractions(g geom.T, bounds *geom.Bounds, nx, ny int) ([]float64, error) {
+	var ringss [][]int
+	switch g := g.(type) {
+	case *geom.Polygon:
+		ringss = [][]int{g.Ends()}
+	case *geom.MultiPolygon:
+		ringss = g.Endss()
+	default:
+		return nil, geom.ErrUnsupportedType{Value: g}
+	}
+	fractions := make([]float64, nx*ny)
+	grid := &Grid{
+		Bounds: bounds,
+		NX:     nx,
+		NY:     ny,
+	}
+	minX, minY := bounds.Min(0), bounds.Min(1)
+	dx, dy := grid.cellSize()
+	flatCoords, stride := g.FlatCoords(), g.Stride()
+	start := 0
+	for _, ends := range ringss {
+		for k, end := range ends {
+			// Holes are subtracted from the area covered by the exterior
+			// ring.
+			sign := 1.0
+			if k > 0 {
+				sign = -1
+			}
+			ring := make([]float64, 0, 2*(end-start)/stride)
+			for l := start; l < end-stride; l += stride {
+				ring = append(ring, flatCoords[l], flatCoords[l+1])
+			}
+			start = end
+			if len(ring) < 6 {
+				continue
+			}
+			b := geom.NewBounds(geom.XY).Extend(geom.NewLinearRingFlat(geom.XY, ring))
+			i0, i1 := cellRange(b.Min(0), b.Max(0), minX, dx, nx)
+			j0, j1 := cellRange(b.Min(1), b.Max(1), minY, dy, ny)
+			for j := j0; j < j1; j++ {
+				row := clipRing(ring, 1, minY+float64(j)*dy, minY+float64(j+1)*dy)
+				if len(row) < 6 {
+					continue
+				}
+				for i := i0; i < i1; i++ {
+					cell := clipRing(row, 0, minX+float64(i)*dx, minX+float64(i+1)*dx)
+					if len(cell) < 6 {
+						continue
+					}
+					fractions[j*nx+i] += sign * math.Abs(ringArea(cell)) / (dx * dy)
+				}
+			}
+		}
+	}
+	for i, fraction := range fractions {
+		fractions[i] = math.Max(0, math.Min(fraction, 1))
+	}
+	return fractions, nil
+}
+
+// cellRange returns the range of indexes of the n cells of size d starting at
+// origin that overlap [min, max].
+func cellRange(min, max, origin, d float64, n int) (int, int) {
+	i0 := int(math.Floor((min - origin) / d))
+	i1 := int(math.Floor((max-origin)/d)) + 1
+	if i0 < 0 {
+		i0 = 0
+	}
+	if i1 > n {
+		i1 = n
+	}
+	return i0, i1
+}
+
+// clipRing clips ring, a list of XY coordinates without a closing coordinate,
+// to the slab lo <= ring[dim] <= hi with the Sutherland-Hodgman algorithm.
+func clipRing(ring []float64, dim int, lo, hi float64) []float64 {
+	ring = clipHalfPlane(ring, dim, lo, 1)
+	return clipHalfPlane(ring, dim, hi, -1)
+}
+
+// clipHalfPlane clips ring to the half plane sign*(ring[dim]-value) >= 0.
+func clipHalfPlane(ring []float64, dim int, value, sign float64) []float64 {
+	var result []float64
+	n := len(ring)
+	for i := 0; i < n; i += 2 {
+		a, b := ring[(i+n-2)%n:(i+n-2)%n+2], ring[i:i+2]
+		aInside := sign*(a[dim]-value) >= 0
+		bInside := sign*(b[dim]-value) >= 0
+		if aInside != bInside {
+			t := (value - a[dim]) / (b[dim] - a[dim])
+			result = append(result, a[0]+t*(b[0]-a[0]), a[1]+t*(b[1]-a[1]))
+		}
+		if bInside {
+			result = append(result, b[0], b[1])
+		}
+	}
+	return result
+}
+
+// ringArea returns the signed area of ring, a list of XY coordinates without
+// a closing coordinate.
+func ringArea(ring []float64) float64 {
+	var doubleArea float64
+	n := len(ring)
+	for i := 0; i < n; i += 2 {
+		j := (i + 2) % n
+		doubleArea += ring[i]*ring[j+1] - ring[j]*ring[i+1]
+	}
+	return doubleArea / 2
+}
@@ -0,0 +1,105 @@
+package xy
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+)
+
+func TestRasterize(t *testing.T) {
+	bounds := geom.NewBounds(geom.XY).Set(0, 0, 4, 4)
+	square := geom.NewPolygonFlat(geom.XY, []float64{0.5, 0.5, 2.5, 0.5, 2.5, 2.5, 0.5, 2.5, 0.5, 0.5}, []int{10})
+	for _, tc := range []struct {
+		name          string
+		g             geom.T
+		wantMask      []bool
+		wantFractions []float64
+	}{
+		{
+			name: "square",
+			g:    square,
+			wantMask: []bool{
+				true, true, false, false,
+				true, true, false, false,
+				false, false, false, false,
+				false, false, false, false,
+			},
+			wantFractions: []float64{
+				0.25, 0.5, 0.25, 0,
+				0.5, 1, 0.5, 0,
+				0.25, 0.5, 0.25, 0,
+				0, 0, 0, 0,
+			},
+		},
+		{
+			name: "square_with_hole",
+			g: geom.NewPolygonFlat(geom.XY, []float64{
+				0, 0, 4, 0, 4, 4, 0, 4, 0, 0,
+				1, 1, 3, 1, 3, 3, 1, 3, 1, 1,
+			}, []int{10, 20}),
+			wantMask: []bool{
+				true, true, true, true,
+				true, false, false, true,
+				true, false, false, true,
+				true, true, true, true,
+			},
+			wantFractions: []float64{
+				1, 1, 1, 1,
+				1, 0, 0, 1,
+				1, 0, 0, 1,
+				1, 1, 1, 1,
+			},
+		},
+		{
+			name: "multipolygon_partly_outside",
+			g: geom.NewMultiPolygonFlat(geom.XY, []float64{
+				-1, -1, 1, -1, 1, 1, -1, 1, -1, -1,
+				3.5, 3.5, 5, 3.5, 5, 5, 3.5, 5, 3.5, 3.5,
+			}, [][]int{{10}, {20}}),
+			wantMask: []bool{
+				true, false, false, false,
+				false, false, false, false,
+				false, false, false, false,
+				false, false, false, true,
+			},
+			wantFractions: []float64{
+				1, 0, 0, 0,
+				0, 0, 0, 0,
+				0, 0, 0, 0,
+				0, 0, 0, 0.25,
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mask, err := Rasterize(tc.g, bounds, 4, 4)
+			if err != nil || !reflect.DeepEqual(mask, tc.wantMask) {
+				t.Errorf("Rasterize(...) == %v, %v, want %v, <nil>", mask, err, tc.wantMask)
+			}
+			fractions, err := RasterizeFractions(tc.g, bounds, 4, 4)
+			if err != nil || !reflect.DeepEqual(fractions, tc.wantFractions) {
+				t.Errorf("RasterizeFractions(...) == %v, %v, want %v, <nil>", fractions, err, tc.wantFractions)
+			}
+		})
+	}
+}
+
+func TestRasterizeFractionsArea(t *testing.T) {
+	g := geom.NewPolygonFlat(geom.XY, []float64{0.3, 0.2, 9.7, 1.1, 4.4, 8.9, 0.3, 0.2}, []int{8})
+	fractions, err := RasterizeFractions(g, geom.NewBounds(geom.XY).Set(0, 0, 10, 10), 7, 9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cellArea := 10.0 / 7 * 10.0 / 9
+	var area float64
+	for _, fraction := range fractions {
+		area += fraction * cellArea
+	}
+	if want := g.Area(); math.Abs(area-want) > 1e-9 {
+		t.Errorf("sum of fractions times cell area == %f, want %f", area, want)
+	}
+	if _, err := RasterizeFractions(geom.NewPointFlat(geom.XY, []float64{1, 2}), geom.NewBounds(geom.XY).Set(0, 0, 1, 1), 1, 1); err == nil {
+		t.Errorf("RasterizeFractions(point, ...) == _, <nil>, want _, !<nil>")
+	}
+}
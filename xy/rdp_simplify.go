@@ -19,6 +19,143 @@ package xy
 // WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR
 // IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
 
+import "github.com/twpayne/go-geom"
+
+// Simplify returns a copy of g simplified with the Douglas-Peucker algorithm
+// and threshold. Rings that collapse to fewer than four points are removed,
+// as are the polygons whose exterior rings collapse and the empty parts of
+// multi-geometries, so the result may be empty. Points and MultiPoints are
+// returned unchanged.
+func Simplify(g geom.T, threshold float64) (geom.T, error) {
+	return simplifier{threshold: threshold}.simplify(g)
+}
+
+// SimplifyWeighted is like Simplify, but measures distances using the Z and
+// M ordinates of g, when it has them, as well as X and Y, so that elevation
+// profiles and timed tracks keep the points where their elevation or speed
+// changes. Z and M are multiplied by zWeight and mWeight to convert them to
+// the units of X and Y, and are ignored if their weight is zero.
+func SimplifyWeighted(g geom.T, threshold, zWeight, mWeight float64) (geom.T, error) {
+	return simplifier{threshold: threshold, zWeight: zWeight, mWeight: mWeight}.simplify(g)
+}
+
+// A simplifier simplifies geometries with the Douglas-Peucker algorithm.
+type simplifier struct {
+	threshold float64
+	zWeight   float64
+	mWeight   float64
+}
+
+func (s simplifier) simplify(g geom.T) (geom.T, error) {
+	switch g := g.(type) {
+	case *geom.Point, *geom.MultiPoint:
+		return g, nil
+	case *geom.LineString:
+		return geom.NewLineStringFlat(g.Layout(), s.flatCoords(g.Layout(), g.FlatCoords())).SetSRID(g.SRID()), nil
+	case *geom.LinearRing:
+		return geom.NewLinearRingFlat(g.Layout(), s.ring(g.Layout(), g.FlatCoords())).SetSRID(g.SRID()), nil
+	case *geom.Polygon:
+		flatCoords, ends := s.rings(g.Layout(), g.FlatCoords(), 0, g.Ends(), nil, nil)
+		return geom.NewPolygonFlat(g.Layout(), flatCoords, ends).SetSRID(g.SRID()), nil
+	case *geom.MultiLineString:
+		var flatCoords []float64
+		var ends []int
+		start := 0
+		for _, end := range g.Ends() {
+			lineString := s.flatCoords(g.Layout(), g.FlatCoords()[start:end])
+			start = end
+			if len(lineString) == 0 {
+				continue
+			}
+			flatCoords = append(flatCoords, lineString...)
+			ends = append(ends, len(flatCoords))
+		}
+		return geom.NewMultiLineStringFlat(g.Layout(), flatCoords, ends).SetSRID(g.SRID()), nil
+	case *geom.MultiPolygon:
+		var flatCoords []float64
+		var endss [][]int
+		start := 0
+		for _, ends := range g.Endss() {
+			var simplifiedEnds []int
+			flatCoords, simplifiedEnds = s.rings(g.Layout(), g.FlatCoords(), start, ends, flatCoords, nil)
+			if len(simplifiedEnds) > 0 {
+				endss = append(endss, simplifiedEnds)
+			}
+			if len(ends) > 0 {
+				start = ends[len(ends)-1]
+			}
+		}
+		return geom.NewMultiPolygonFlat(g.Layout(), flatCoords, endss).SetSRID(g.SRID()), nil
+	case *geom.GeometryCollection:
+		simplified := geom.NewGeometryCollection()
+		for _, g := range g.Geoms() {
+			g, err := s.simplify(g)
+			if err != nil {
+				return nil, err
+			}
+			if err := simplified.Push(g); err != nil {
+				return nil, err
+			}
+		}
+		return simplified.SetSRID(g.SRID()), nil
+	default:
+		return nil, geom.ErrUnsupportedType{Value: g}
+	}
+}
+
+// indexes returns the indexes of the points of flatCoords retained by the
+// Douglas-Peucker algorithm.
+func (s simplifier) indexes(layout geom.Layout, flatCoords []float64) []int {
+	stride := layout.Stride()
+	if s.zWeight == 0 && s.mWeight == 0 {
+		return SimplifyFlatCoords(flatCoords, s.threshold, stride)
+	}
+	weights := make([]float64, stride)
+	weights[0], weights[1] = 1, 1
+	if zIndex := layout.ZIndex(); zIndex != -1 {
+		weights[zIndex] = s.zWeight
+	}
+	if mIndex := layout.MIndex(); mIndex != -1 {
+		weights[mIndex] = s.mWeight
+	}
+	return dpIndexes(flatCoords, s.threshold, stride, weightedDistanceFromSegmentSquared(weights))
+}
+
+// flatCoords returns the points of flatCoords retained by the Douglas-Peucker
+// algorithm.
+func (s simplifier) flatCoords(layout geom.Layout, flatCoords []float64) []float64 {
+	return pointsAt(flatCoords, s.indexes(layout, flatCoords), layout.Stride())
+}
+
+// ring simplifies the ring flatCoords, returning nil if it collapses to fewer
+// than four points.
+func (s simplifier) ring(layout geom.Layout, flatCoords []float64) []float64 {
+	simplified := s.flatCoords(layout, flatCoords)
+	if len(simplified) < 4*layout.Stride() {
+		return nil
+	}
+	return simplified
+}
+
+// rings simplifies the rings of a polygon, appending them to flatCoords and
+// their ends to ends. Collapsed holes are removed. If the exterior ring
+// collapses then the whole polygon is removed.
+func (s simplifier) rings(layout geom.Layout, polygonFlatCoords []float64, start int, polygonEnds []int, flatCoords []float64, ends []int) ([]float64, []int) {
+	for i, end := range polygonEnds {
+		ring := s.ring(layout, polygonFlatCoords[start:end])
+		start = end
+		if ring == nil {
+			if i == 0 {
+				return flatCoords, nil
+			}
+			continue
+		}
+		flatCoords = append(flatCoords, ring...)
+		ends = append(ends, len(flatCoords))
+	}
+	return flatCoords, ends
+}
+
 // SimplifyFlatCoords uses the Douglas-Peucker algorithm to simplify a 2D
 // flatCoords. It returns the indexes of the points. Note that the indexes are
 // based on points, So acesss to x, y pair should be:
@@ -29,6 +166,37 @@ package xy
 // Threshold is the distance between a point and the selected start
 // and end line segment. It returns the indexes of the points.
 func SimplifyFlatCoords(flatCoords []float64, threshold float64, stride int) []int {
+	return dpIndexes(flatCoords, threshold, stride, distanceFromSegmentSquared)
+}
+
+// SimplifyFlatCoordsWeighted is like SimplifyFlatCoords, but measures
+// distances using Z and M as well as X and Y, like SimplifyWeighted.
+func SimplifyFlatCoordsWeighted(layout geom.Layout, flatCoords []float64, threshold, zWeight, mWeight float64) []int {
+	return simplifier{threshold: threshold, zWeight: zWeight, mWeight: mWeight}.indexes(layout, flatCoords)
+}
+
+// A segmentDistanceSquaredFunc returns point's squared distance from the
+// segment [a, b].
+type segmentDistanceSquaredFunc func(a, b, point []float64) float64
+
+// simplifyFlatCoords returns the points of flatCoords retained by
+// SimplifyFlatCoords.
+func simplifyFlatCoords(flatCoords []float64, threshold float64, stride int) []float64 {
+	return pointsAt(flatCoords, SimplifyFlatCoords(flatCoords, threshold, stride), stride)
+}
+
+// pointsAt returns the points of flatCoords at indexes.
+func pointsAt(flatCoords []float64, indexes []int, stride int) []float64 {
+	points := make([]float64, 0, len(indexes)*stride)
+	for _, i := range indexes {
+		points = append(points, flatCoords[i*stride:(i+1)*stride]...)
+	}
+	return points
+}
+
+// dpIndexes returns the indexes of the points of flatCoords retained by the
+// Douglas-Peucker algorithm with distances measured by distanceSquared.
+func dpIndexes(flatCoords []float64, threshold float64, stride int, distanceSquared segmentDistanceSquaredFunc) []int {
 	size := len(flatCoords) / stride
 	if size < 3 {
 		ret := make([]int, size)
@@ -42,7 +210,7 @@ func SimplifyFlatCoords(flatCoords []float64, threshold float64, stride int) []i
 	mask[0] = 1
 	mask[len(mask)-1] = 1
 
-	found := dpWorker(flatCoords, threshold, mask, stride)
+	found := dpWorker(flatCoords, threshold, mask, stride, distanceSquared)
 	indexMap := make([]int, 0, found)
 
 	for i, v := range mask {
@@ -57,7 +225,7 @@ func SimplifyFlatCoords(flatCoords []float64, threshold float64, stride int) []i
 // dpWorker does the recursive threshold checks.
 // Using a stack array with a stackLength variable resulted in
 // 4x speed improvement over calling the function recursively.
-func dpWorker(ls []float64, threshold float64, mask []byte, stride int) int {
+func dpWorker(ls []float64, threshold float64, mask []byte, stride int, distanceSquared segmentDistanceSquaredFunc) int {
 	found := 2
 
 	var stack []int
@@ -75,7 +243,7 @@ func dpWorker(ls []float64, threshold float64, mask []byte, stride int) int {
 
 		for i := start + 1; i < end; i++ {
 			p := ls[i*stride : i*stride+stride]
-			dist := distanceFromSegmentSquared(a, b, p)
+			dist := distanceSquared(a, b, p)
 			if dist > maxDist {
 				maxDist = dist
 				maxIndex = i
@@ -121,3 +289,32 @@ func distanceFromSegmentSquared(a, b, point []float64) float64 {
 
 	return dx*dx + dy*dy
 }
+
+// weightedDistanceFromSegmentSquared returns a function that returns point's
+// squared distance from the segment [a, b] in the space where each ordinate
+// is multiplied by its weight.
+func weightedDistanceFromSegmentSquared(weights []float64) segmentDistanceSquaredFunc {
+	return func(a, b, point []float64) float64 {
+		var dot, lengthSquared float64
+		for i, w := range weights {
+			d := w * (b[i] - a[i])
+			dot += w * (point[i] - a[i]) * d
+			lengthSquared += d * d
+		}
+		t := 0.0
+		if lengthSquared != 0 {
+			t = dot / lengthSquared
+			if t < 0 {
+				t = 0
+			} else if t > 1 {
+				t = 1
+			}
+		}
+		var distanceSquared float64
+		for i, w := range weights {
+			d := w * (point[i] - a[i] - t*(b[i]-a[i]))
+			distanceSquared += d * d
+		}
+		return distanceSquared
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"reflect"
 	"testing"
+
+	"github.com/twpayne/go-geom"
 )
 
 func TestSimplify(t *testing.T) {
@@ -62,3 +64,130 @@ func ExampleSimplifyFlatCoords() {
 	// Output:
 	// []float64{0, 0, 0, 1, -1, 2, 0, 3, 0, 4, 2, 4.5, 4, 4}
 }
+
+func TestSimplifyGeometry(t *testing.T) {
+	for i, tc := range []struct {
+		g         geom.T
+		threshold float64
+		want      geom.T
+	}{
+		{
+			g:         geom.NewPointFlat(geom.XY, []float64{1, 2}),
+			threshold: 1,
+			want:      geom.NewPointFlat(geom.XY, []float64{1, 2}),
+		},
+		{
+			g:         geom.NewLineStringFlat(geom.XYM, []float64{0, 0, 1, 1, 0.1, 2, 2, 0, 3}).SetSRID(4326),
+			threshold: 0.5,
+			want:      geom.NewLineStringFlat(geom.XYM, []float64{0, 0, 1, 2, 0, 3}).SetSRID(4326),
+		},
+		{
+			g: geom.NewPolygonFlat(geom.XY, []float64{
+				0, 0, 5, 0.1, 10, 0, 10, 10, 0, 10, 0, 0,
+				4, 4, 4.1, 4.05, 4.2, 4, 4, 4,
+			}, []int{12, 20}),
+			threshold: 0.5,
+			want:      geom.NewPolygonFlat(geom.XY, []float64{0, 0, 10, 0, 10, 10, 0, 10, 0, 0}, []int{10}),
+		},
+		{
+			g: geom.NewMultiPolygonFlat(geom.XY, []float64{
+				0, 0, 0.1, 0, 0.1, 0.1, 0, 0,
+				0, 0, 10, 0, 10, 10, 0, 0,
+			}, [][]int{{8}, {16}}),
+			threshold: 0.5,
+			want:      geom.NewMultiPolygonFlat(geom.XY, []float64{0, 0, 10, 0, 10, 10, 0, 0}, [][]int{{8}}),
+		},
+		{
+			g:         geom.NewMultiLineStringFlat(geom.XY, []float64{0, 0, 1, 0.1, 2, 0, 5, 5, 6, 6}, []int{6, 10}),
+			threshold: 0.5,
+			want:      geom.NewMultiLineStringFlat(geom.XY, []float64{0, 0, 2, 0, 5, 5, 6, 6}, []int{4, 8}),
+		},
+		{
+			g: geom.NewGeometryCollection().MustPush(
+				geom.NewLineStringFlat(geom.XY, []float64{0, 0, 1, 0.1, 2, 0}),
+			),
+			threshold: 0.5,
+			want: geom.NewGeometryCollection().MustPush(
+				geom.NewLineStringFlat(geom.XY, []float64{0, 0, 2, 0}),
+			),
+		},
+	} {
+		if got, err := Simplify(tc.g, tc.threshold); err != nil || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%d: Simplify(%v, %v) == %v, %v, want %v, <nil>", i, tc.g, tc.threshold, got, err, tc.want)
+		}
+	}
+}
+
+func TestSimplifyWeighted(t *testing.T) {
+	// A straight line in plan with a peak in elevation and a stop in time.
+	xyzm := []float64{
+		0, 0, 0, 0,
+		1, 0, 0, 1,
+		2, 0, 10, 2,
+		3, 0, 0, 3,
+		4, 0, 0, 100,
+		5, 0, 0, 101,
+		6, 0, 0, 102,
+	}
+	for _, tc := range []struct {
+		name    string
+		layout  geom.Layout
+		zWeight float64
+		mWeight float64
+		want    []int
+	}{
+		{
+			name:   "planar",
+			layout: geom.XYZM,
+			want:   []int{0, 6},
+		},
+		{
+			name:    "z",
+			layout:  geom.XYZM,
+			zWeight: 1,
+			want:    []int{0, 1, 2, 3, 6},
+		},
+		{
+			name:    "m",
+			layout:  geom.XYZM,
+			mWeight: 1,
+			want:    []int{0, 3, 4, 6},
+		},
+		{
+			name:    "z_and_m",
+			layout:  geom.XYZM,
+			zWeight: 1,
+			mWeight: 1,
+			want:    []int{0, 1, 2, 3, 4, 6},
+		},
+		{
+			name:    "xyz_ignores_m_weight",
+			layout:  geom.XYZ,
+			mWeight: 1,
+			want:    []int{0, 6},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			flatCoords := xyzm
+			if tc.layout == geom.XYZ {
+				flatCoords = nil
+				for i := 0; i < len(xyzm); i += 4 {
+					flatCoords = append(flatCoords, xyzm[i:i+3]...)
+				}
+			}
+			if got := SimplifyFlatCoordsWeighted(tc.layout, flatCoords, 0.5, tc.zWeight, tc.mWeight); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("SimplifyFlatCoordsWeighted(...) == %v, want %v", got, tc.want)
+			}
+		})
+	}
+
+	g := geom.NewLineStringFlat(geom.XYZ, []float64{0, 0, 0, 1, 0, 5, 2, 0, 0}).SetSRID(4326)
+	want := geom.NewLineStringFlat(geom.XYZ, []float64{0, 0, 0, 1, 0, 5, 2, 0, 0}).SetSRID(4326)
+	if got, err := SimplifyWeighted(g, 1, 1, 0); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("SimplifyWeighted(...) == %v, %v, want %v, <nil>", got, err, want)
+	}
+	want = geom.NewLineStringFlat(geom.XYZ, []float64{0, 0, 0, 2, 0, 0}).SetSRID(4326)
+	if got, err := SimplifyWeighted(g, 1, 0.1, 0); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("SimplifyWeighted(...) == %v, %v, want %v, <nil>", got, err, want)
+	}
+}
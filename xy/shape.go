@@ -0,0 +1,223 @@
+package xy
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/twpayne/go-geom"
+)
+
+// Shape metrics measure how compact a Polygon or MultiPolygon is, for example
+// to detect gerrymandered districts or slivers. Each metric is between zero
+// and one, where one is the most compact, except Elongation, where zero is
+// the least elongated. Degenerate geometries with zero area or perimeter have
+// metrics of zero. Only X and Y are considered.
+
+// PolsbyPopper returns the Polsby-Popper score of g, the ratio of its area to
+// the area of a circle with the same perimeter, 4πA/P². It is one for a
+// circle.
+func PolsbyPopper(g geom.T) (float64, error) {
+	area, perimeter, err := areaAndPerimeter(g)
+	if err != nil || perimeter == 0 {
+		return 0, err
+	}
+	return 4 * math.Pi * area / (perimeter * perimeter), nil
+}
+
+// Reock returns the Reock score of g, the ratio of its area to the area of
+// its minimum bounding circle. It is one for a circle.
+func Reock(g geom.T) (float64, error) {
+	area, _, err := areaAndPerimeter(g)
+	if err != nil || area == 0 {
+		return 0, err
+	}
+	_, radius := MinimumBoundingCircle(g)
+	return area / (math.Pi * radius * radius), nil
+}
+
+// ConvexityRatio returns the ratio of the area of g to the area of its convex
+// hull. It is one for a convex polygon.
+func ConvexityRatio(g geom.T) (float64, error) {
+	area, _, err := areaAndPerimeter(g)
+	if err != nil || area == 0 {
+		return 0, err
+	}
+	hull, ok := ConvexHull(g).(*geom.Polygon)
+	if !ok {
+		return 0, nil
+	}
+	return area / math.Abs(hull.Area()), nil
+}
+
+// Elongation returns one minus the ratio of the width to the length of the
+// minimum area rectangle enclosing g. It is zero for a square or a circle and
+// approaches one for long, thin shapes.
+func Elongation(g geom.T) (float64, error) {
+	area, _, err := areaAndPerimeter(g)
+	if err != nil {
+		return 0, err
+	}
+	rectangle := MinimumAreaRectangle(g)
+	if area == 0 || rectangle == nil {
+		return 0, nil
+	}
+	c := rectangle.FlatCoords()
+	stride := rectangle.Stride()
+	side1 := math.Hypot(c[stride]-c[0], c[stride+1]-c[1])
+	side2 := math.Hypot(c[2*stride]-c[stride], c[2*stride+1]-c[stride+1])
+	width, length := math.Min(side1, side2), math.Max(side1, side2)
+	if length == 0 {
+		return 0, nil
+	}
+	return 1 - width/length, nil
+}
+
+// MinimumBoundingCircle returns the center and radius of the smallest circle
+// that contains g. It returns a nil center if g is empty.
+func MinimumBoundingCircle(g geom.T) (center geom.Coord, radius float64) {
+	points := hullPoints(g)
+	if len(points) == 0 {
+		return nil, 0
+	}
+	// Welzl's algorithm in its iterative form, with the points shuffled
+	// deterministically for expected linear time.
+	r := rand.New(rand.NewSource(1))
+	r.Shuffle(len(points), func(i, j int) {
+		points[i], points[j] = points[j], points[i]
+	})
+	c := circle{x: points[0][0], y: points[0][1]}
+	for i := 1; i < len(points); i++ {
+		if c.contains(points[i]) {
+			continue
+		}
+		c = circle{x: points[i][0], y: points[i][1]}
+		for j := 0; j < i; j++ {
+			if c.contains(points[j]) {
+				continue
+			}
+			c = diametralCircle(points[i], points[j])
+			for k := 0; k < j; k++ {
+				if !c.contains(points[k]) {
+					c = circumcircle(points[i], points[j], points[k])
+				}
+			}
+		}
+	}
+	return geom.Coord{c.x, c.y}, c.r
+}
+
+// MinimumAreaRectangle returns the rectangle with the smallest area that
+// contains g, as an XY Polygon. One of its sides is collinear with an edge of
+// the convex hull of g. It returns nil if g is empty.
+func MinimumAreaRectangle(g geom.T) *geom.Polygon {
+	points := hullPoints(g)
+	if len(points) == 0 {
+		return nil
+	}
+	bestArea := math.Inf(1)
+	var best [8]float64
+	for i := range points {
+		p, q := points[i], points[(i+1)%len(points)]
+		ux, uy := q[0]-p[0], q[1]-p[1]
+		norm := math.Hypot(ux, uy)
+		if norm == 0 {
+			if len(points) > 1 {
+				continue
+			}
+			ux, uy, norm = 1, 0, 1
+		}
+		ux, uy = ux/norm, uy/norm
+		minU, maxU := math.Inf(1), math.Inf(-1)
+		minV, maxV := math.Inf(1), math.Inf(-1)
+		for _, point := range points {
+			u := point[0]*ux + point[1]*uy
+			v := -point[0]*uy + point[1]*ux
+			minU, maxU = math.Min(minU, u), math.Max(maxU, u)
+			minV, maxV = math.Min(minV, v), math.Max(maxV, v)
+		}
+		if area := (maxU - minU) * (maxV - minV); area < bestArea {
+			bestArea = area
+			corner := func(u, v float64) (float64, float64) {
+				return u*ux - v*uy, u*uy + v*ux
+			}
+			best[0], best[1] = corner(minU, minV)
+			best[2], best[3] = corner(maxU, minV)
+			best[4], best[5] = corner(maxU, maxV)
+			best[6], best[7] = corner(minU, maxV)
+		}
+	}
+	flatCoords := append(best[:], best[0], best[1])
+	return geom.NewPolygonFlat(geom.XY, flatCoords, []int{len(flatCoords)})
+}
+
+// areaAndPerimeter returns the area and perimeter of g, which must be a
+// Polygon or a MultiPolygon, whatever the orientation of its rings.
+func areaAndPerimeter(g geom.T) (float64, float64, error) {
+	switch g := g.(type) {
+	case *geom.Polygon:
+		return math.Abs(g.Area()), g.Length(), nil
+	case *geom.MultiPolygon:
+		area := 0.0
+		for i := 0; i < g.NumPolygons(); i++ {
+			area += math.Abs(g.Polygon(i).Area())
+		}
+		return area, g.Length(), nil
+	default:
+		return 0, 0, geom.ErrUnsupportedType{Value: g}
+	}
+}
+
+// hullPoints returns the distinct XY vertices of the convex hull of g.
+func hullPoints(g geom.T) [][2]float64 {
+	if len(g.FlatCoords()) == 0 {
+		return nil
+	}
+	hull := ConvexHull(g)
+	flatCoords, stride := hull.FlatCoords(), hull.Stride()
+	if _, ok := hull.(*geom.Polygon); ok {
+		// Drop the closing coordinate of the ring.
+		flatCoords = flatCoords[:len(flatCoords)-stride]
+	}
+	points := make([][2]float64, 0, len(flatCoords)/stride)
+	for i := 0; i < len(flatCoords); i += stride {
+		points = append(points, [2]float64{flatCoords[i], flatCoords[i+1]})
+	}
+	return points
+}
+
+type circle struct {
+	x, y, r float64
+}
+
+func (c circle) contains(p [2]float64) bool {
+	return math.Hypot(p[0]-c.x, p[1]-c.y) <= c.r*(1+1e-12)
+}
+
+func diametralCircle(a, b [2]float64) circle {
+	return circle{
+		x: (a[0] + b[0]) / 2,
+		y: (a[1] + b[1]) / 2,
+		r: math.Hypot(a[0]-b[0], a[1]-b[1]) / 2,
+	}
+}
+
+// circumcircle returns the circle through a, b, and c, or the diametral
+// circle of the two farthest points if they are collinear.
+func circumcircle(a, b, c [2]float64) circle {
+	bx, by := b[0]-a[0], b[1]-a[1]
+	cx, cy := c[0]-a[0], c[1]-a[1]
+	d := 2 * (bx*cy - by*cx)
+	if d == 0 {
+		result := diametralCircle(a, b)
+		for _, candidate := range []circle{diametralCircle(a, c), diametralCircle(b, c)} {
+			if candidate.r > result.r {
+				result = candidate
+			}
+		}
+		return result
+	}
+	b2, c2 := bx*bx+by*by, cx*cx+cy*cy
+	ux := (cy*b2 - by*c2) / d
+	uy := (bx*c2 - cx*b2) / d
+	return circle{x: a[0] + ux, y: a[1] + uy, r: math.Hypot(ux, uy)}
+}
@@ -0,0 +1,166 @@
+package xy
+
+import (
+	"math"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+)
+
+func regularPolygon(n int, r float64) *geom.Polygon {
+	flatCoords := make([]float64, 0, 2*(n+1))
+	for i := 0; i <= n; i++ {
+		theta := 2 * math.Pi * float64(i%n) / float64(n)
+		flatCoords = append(flatCoords, r*math.Cos(theta), r*math.Sin(theta))
+	}
+	return geom.NewPolygonFlat(geom.XY, flatCoords, []int{len(flatCoords)})
+}
+
+func TestShapeMetrics(t *testing.T) {
+	// A 4 by 1 rectangle rotated by 30 degrees.
+	sin, cos := math.Sin(math.Pi/6), math.Cos(math.Pi/6)
+	rotated := geom.NewPolygonFlat(geom.XY, []float64{
+		0, 0,
+		4 * cos, 4 * sin,
+		4*cos - sin, 4*sin + cos,
+		-sin, cos,
+		0, 0,
+	}, []int{10})
+	for _, tc := range []struct {
+		name                                       string
+		g                                          geom.T
+		polsbyPopper, reock, convexity, elongation float64
+	}{
+		{
+			name:         "square",
+			g:            geom.NewPolygonFlat(geom.XY, []float64{0, 0, 2, 0, 2, 2, 0, 2, 0, 0}, []int{10}),
+			polsbyPopper: math.Pi / 4,
+			reock:        2 / math.Pi,
+			convexity:    1,
+			elongation:   0,
+		},
+		{
+			name:         "rotated_rectangle",
+			g:            rotated,
+			polsbyPopper: 4 * math.Pi * 4 / 100,
+			reock:        4 / (math.Pi * 17 / 4),
+			convexity:    1,
+			elongation:   0.75,
+		},
+		{
+			name:         "l_shape",
+			g:            geom.NewPolygonFlat(geom.XYZ, []float64{0, 0, 0, 2, 0, 0, 2, 1, 0, 1, 1, 0, 1, 2, 0, 0, 2, 0, 0, 0, 0}, []int{21}),
+			polsbyPopper: 4 * math.Pi * 3 / 64,
+			reock:        3 / (2 * math.Pi),
+			convexity:    3 / 3.5,
+			elongation:   0,
+		},
+		{
+			name:         "circle",
+			g:            regularPolygon(360, 10),
+			polsbyPopper: 1,
+			reock:        1,
+			convexity:    1,
+			elongation:   0,
+		},
+		{
+			name: "multipolygon",
+			g: geom.NewMultiPolygonFlat(geom.XY, []float64{
+				0, 0, 1, 0, 1, 1, 0, 1, 0, 0,
+				3, 0, 4, 0, 4, 1, 3, 1, 3, 0,
+			}, [][]int{{10}, {20}}),
+			polsbyPopper: 4 * math.Pi * 2 / 64,
+			reock:        2 / (math.Pi * 17 / 4),
+			convexity:    0.5,
+			elongation:   0.75,
+		},
+		{
+			name: "degenerate",
+			g:    geom.NewPolygonFlat(geom.XY, []float64{0, 0, 1, 0, 0, 0}, []int{6}),
+		},
+		{
+			name: "empty",
+			g:    geom.NewPolygon(geom.XY),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, metric := range []struct {
+				name string
+				f    func(geom.T) (float64, error)
+				want float64
+			}{
+				{name: "PolsbyPopper", f: PolsbyPopper, want: tc.polsbyPopper},
+				{name: "Reock", f: Reock, want: tc.reock},
+				{name: "ConvexityRatio", f: ConvexityRatio, want: tc.convexity},
+				{name: "Elongation", f: Elongation, want: tc.elongation},
+			} {
+				if got, err := metric.f(tc.g); err != nil || math.Abs(got-metric.want) > 1e-3 {
+					t.Errorf("%s(...) == %f, %v, want %f, <nil>", metric.name, got, err, metric.want)
+				}
+			}
+		})
+	}
+}
+
+func TestShapeMetricsUnsupportedType(t *testing.T) {
+	g := geom.NewLineStringFlat(geom.XY, []float64{0, 0, 1, 1})
+	for _, f := range []func(geom.T) (float64, error){PolsbyPopper, Reock, ConvexityRatio, Elongation} {
+		if _, err := f(g); err == nil {
+			t.Errorf("got <nil>, want error")
+		}
+	}
+}
+
+func TestMinimumBoundingCircle(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		g      geom.T
+		center geom.Coord
+		radius float64
+	}{
+		{
+			name:   "point",
+			g:      geom.NewPointFlat(geom.XY, []float64{1, 2}),
+			center: geom.Coord{1, 2},
+		},
+		{
+			name:   "collinear",
+			g:      geom.NewMultiPointFlat(geom.XY, []float64{0, 0, 1, 1, 4, 4}),
+			center: geom.Coord{2, 2},
+			radius: math.Sqrt(8),
+		},
+		{
+			name:   "obtuse_triangle",
+			g:      geom.NewMultiPointFlat(geom.XY, []float64{0, 0, 10, 0, 5, 1}),
+			center: geom.Coord{5, 0},
+			radius: 5,
+		},
+		{
+			name:   "equilateral_triangle",
+			g:      geom.NewMultiPointFlat(geom.XY, []float64{0, 0, 2, 0, 1, math.Sqrt(3)}),
+			center: geom.Coord{1, 1 / math.Sqrt(3)},
+			radius: 2 / math.Sqrt(3),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			center, radius := MinimumBoundingCircle(tc.g)
+			if math.Abs(center[0]-tc.center[0]) > 1e-9 || math.Abs(center[1]-tc.center[1]) > 1e-9 || math.Abs(radius-tc.radius) > 1e-9 {
+				t.Errorf("MinimumBoundingCircle(...) == %v, %f, want %v, %f", center, radius, tc.center, tc.radius)
+			}
+		})
+	}
+	if center, radius := MinimumBoundingCircle(geom.NewMultiPoint(geom.XY)); center != nil || radius != 0 {
+		t.Errorf("MinimumBoundingCircle(empty) == %v, %f, want <nil>, 0", center, radius)
+	}
+}
+
+func TestMinimumAreaRectangle(t *testing.T) {
+	g := geom.NewMultiPointFlat(geom.XY, []float64{0, 0, 2, 2, 3, 1, 1, -1})
+	rectangle := MinimumAreaRectangle(g)
+	if area := rectangle.Area(); math.Abs(area-4) > 1e-9 {
+		t.Errorf("MinimumAreaRectangle(...).Area() == %f, want 4", area)
+	}
+	if MinimumAreaRectangle(geom.NewMultiPoint(geom.XY)) != nil {
+		t.Errorf("MinimumAreaRectangle(empty) != <nil>")
+	}
+}
@@ -0,0 +1,241 @@
+// Package stats contains statistics of two-dimensional point patterns, such
+// as the nearest neighbor index and Ripley's K and L functions, which test
+// whether points are clustered, random, or dispersed within a study area.
+//
+// Points are indexed with an STR-tree, so the statistics can be computed for
+// large patterns.
+package stats
+
+import (
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/index/strtree"
+	"github.com/twpayne/go-geom/xy"
+	"github.com/twpayne/go-geom/xy/location"
+)
+
+// nearestNeighborStandardErrorConstant is the constant in the standard error
+// of the mean nearest neighbor distance under complete spatial randomness,
+// √((4-π)/4π).
+const nearestNeighborStandardErrorConstant = 0.26136
+
+// Errors returned by NewPattern.
+var (
+	ErrTooFewPoints   = errors.New("stats: too few points")
+	ErrEmptyStudyArea = errors.New("stats: empty study area")
+)
+
+// An EdgeCorrection is a method of correcting for points near the boundary of
+// the study area, whose neighbors outside it are not observed.
+type EdgeCorrection int
+
+// Edge corrections.
+const (
+	// NoEdgeCorrection counts the neighbors of all points, which biases K
+	// downwards at larger distances.
+	NoEdgeCorrection EdgeCorrection = iota
+	// BorderEdgeCorrection, also known as the reduced sample estimator,
+	// only counts the neighbors of points that are at least the distance
+	// from the boundary of the study area. At distances greater than that
+	// of every point from the boundary, K is NaN.
+	BorderEdgeCorrection
+)
+
+// A NearestNeighborIndex is the result of the average nearest neighbor
+// analysis of a point pattern.
+type NearestNeighborIndex struct {
+	// ObservedMeanDistance is the mean distance from each point to its
+	// nearest neighbor.
+	ObservedMeanDistance float64
+	// ExpectedMeanDistance is the mean nearest neighbor distance expected
+	// under complete spatial randomness.
+	ExpectedMeanDistance float64
+	// Ratio is the ratio of the observed to the expected mean distance. It
+	// is less than one for clustered patterns and greater than one for
+	// dispersed patterns.
+	Ratio float64
+	// ZScore is the number of standard errors by which the observed mean
+	// distance differs from the expected mean distance.
+	ZScore float64
+}
+
+// A Pattern is a point pattern in a study area, indexed so that statistics
+// can be computed repeatedly, for example for different distances.
+type Pattern struct {
+	coords []float64 // X and Y of each point
+	area   float64
+	tree   *strtree.Tree
+	study  *geom.Polygon
+	border []float64 // distance from each point to the boundary of study, lazily computed
+}
+
+// NewPattern returns a new Pattern of points within study, whose area is the
+// area over which points could have occurred. Points should be inside study.
+// NewPattern returns ErrTooFewPoints if there are fewer than two points and
+// ErrEmptyStudyArea if study has no area.
+func NewPattern(points *geom.MultiPoint, study *geom.Polygon) (*Pattern, error) {
+	n := points.NumPoints()
+	if n < 2 {
+		return nil, ErrTooFewPoints
+	}
+	area := studyArea(study)
+	if area == 0 {
+		return nil, ErrEmptyStudyArea
+	}
+	coords := make([]float64, 0, 2*n)
+	geoms := make([]geom.T, 0, n)
+	for i := 0; i < n; i++ {
+		c := points.Coord(i)
+		coords = append(coords, c[0], c[1])
+		geoms = append(geoms, geom.NewPointFlat(geom.XY, coords[2*i:2*i+2:2*i+2]))
+	}
+	return &Pattern{
+		coords: coords,
+		area:   area,
+		tree:   strtree.New(geoms),
+		study:  study,
+	}, nil
+}
+
+// NearestNeighbor returns the average nearest neighbor index of p, comparing
+// the mean distance from each point to its nearest neighbor with that
+// expected if the points were randomly distributed over the study area.
+// Coincident points are each other's nearest neighbors at distance zero.
+func (p *Pattern) NearestNeighbor() NearestNeighborIndex {
+	n := len(p.coords) / 2
+	sum := 0.0
+	for i := 0; i < n; i++ {
+		cursor := p.tree.Nearest(p.coords[2*i : 2*i+2])
+		for {
+			j, distance, ok := cursor.Next()
+			if !ok {
+				break
+			}
+			// The distance to the bounds of a point is the distance to
+			// the point.
+			if j != i {
+				sum += distance
+				break
+			}
+		}
+	}
+	density := float64(n) / p.area
+	observed := sum / float64(n)
+	expected := 0.5 / math.Sqrt(density)
+	standardError := nearestNeighborStandardErrorConstant / math.Sqrt(float64(n)*density)
+	return NearestNeighborIndex{
+		ObservedMeanDistance: observed,
+		ExpectedMeanDistance: expected,
+		Ratio:                observed / expected,
+		ZScore:               (observed - expected) / standardError,
+	}
+}
+
+// K returns Ripley's K function of p at each of radii. K(r) is the expected
+// number of other points within distance r of a point, divided by the
+// density of points. Under complete spatial randomness it is πr², and larger
+// values indicate clustering at distance r.
+func (p *Pattern) K(radii []float64, correction EdgeCorrection) []float64 {
+	n := len(p.coords) / 2
+	maxRadius := 0.0
+	for _, r := range radii {
+		maxRadius = math.Max(maxRadius, r)
+	}
+	if correction == BorderEdgeCorrection {
+		p.computeBorder()
+	}
+
+	// For each point, find the sorted distances to the other points within
+	// the largest radius, and count those within each radius.
+	counts := make([]float64, len(radii))
+	centers := make([]int, len(radii))
+	var distances []float64
+	bounds := geom.NewBounds(geom.XY)
+	for i := 0; i < n; i++ {
+		x, y := p.coords[2*i], p.coords[2*i+1]
+		distances = distances[:0]
+		bounds.Set(x-maxRadius, y-maxRadius, x+maxRadius, y+maxRadius)
+		p.tree.Query(bounds, func(j int) bool {
+			if j != i {
+				if d := math.Hypot(p.coords[2*j]-x, p.coords[2*j+1]-y); d <= maxRadius {
+					distances = append(distances, d)
+				}
+			}
+			return true
+		})
+		sort.Float64s(distances)
+		for k, r := range radii {
+			if correction == BorderEdgeCorrection && p.border[i] < r {
+				continue
+			}
+			counts[k] += float64(sort.Search(len(distances), func(l int) bool {
+				return distances[l] > r
+			}))
+			centers[k]++
+		}
+	}
+
+	ks := make([]float64, len(radii))
+	for k := range radii {
+		if centers[k] == 0 {
+			ks[k] = math.NaN()
+			continue
+		}
+		ks[k] = p.area / float64(n-1) * counts[k] / float64(centers[k])
+	}
+	return ks
+}
+
+// L returns Besag's L function of p at each of radii, √(K(r)/π), which
+// stabilizes the variance of K and is r under complete spatial randomness.
+func (p *Pattern) L(radii []float64, correction EdgeCorrection) []float64 {
+	ls := p.K(radii, correction)
+	for i, k := range ls {
+		ls[i] = math.Sqrt(k / math.Pi)
+	}
+	return ls
+}
+
+// studyArea returns the area of study, whatever the orientation of its rings.
+func studyArea(study *geom.Polygon) float64 {
+	area := 0.0
+	for i := 0; i < study.NumLinearRings(); i++ {
+		if ringArea := math.Abs(study.LinearRing(i).Area()); i == 0 {
+			area = ringArea
+		} else {
+			area -= ringArea
+		}
+	}
+	return area
+}
+
+// computeBorder computes the distance from each point to the boundary of the
+// study area, or -1 for points outside it.
+func (p *Pattern) computeBorder() {
+	if p.border != nil {
+		return
+	}
+	n := len(p.coords) / 2
+	layout, stride := p.study.Layout(), p.study.Stride()
+	p.border = make([]float64, n)
+	for i := range p.border {
+		c := geom.Coord(p.coords[2*i : 2*i+2])
+		border := math.Inf(1)
+		for j := 0; j < p.study.NumLinearRings(); j++ {
+			ring := p.study.LinearRing(j).FlatCoords()
+			if len(ring) < stride {
+				continue
+			}
+			loc := xy.LocatePointInRing(layout, c, ring)
+			if j == 0 && loc == location.Exterior || j > 0 && loc == location.Interior {
+				border = -1
+				break
+			}
+			border = math.Min(border, xy.DistanceFromPointToLineString(layout, c, ring))
+		}
+		p.border[i] = border
+	}
+}
@@ -0,0 +1,176 @@
+package stats
+
+import (
+	"math"
+	"testing"
+
+	"github.com/twpayne/go-geom"
+)
+
+func square(minX, minY, maxX, maxY float64) *geom.Polygon {
+	return geom.NewPolygonFlat(geom.XY, []float64{minX, minY, maxX, minY, maxX, maxY, minX, maxY, minX, minY}, []int{10})
+}
+
+// grid returns an n×n grid of points with unit spacing.
+func grid(n int) *geom.MultiPoint {
+	var flatCoords []float64
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			flatCoords = append(flatCoords, float64(i), float64(j))
+		}
+	}
+	return geom.NewMultiPointFlat(geom.XY, flatCoords)
+}
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9 || math.IsNaN(a) && math.IsNaN(b)
+}
+
+func TestNewPattern(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		points *geom.MultiPoint
+		study  *geom.Polygon
+		want   error
+	}{
+		{
+			name:   "too_few_points",
+			points: geom.NewMultiPointFlat(geom.XY, []float64{0, 0}),
+			study:  square(0, 0, 1, 1),
+			want:   ErrTooFewPoints,
+		},
+		{
+			name:   "empty_study_area",
+			points: geom.NewMultiPointFlat(geom.XY, []float64{0, 0, 1, 1}),
+			study:  geom.NewPolygon(geom.XY),
+			want:   ErrEmptyStudyArea,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := NewPattern(tc.points, tc.study); err != tc.want {
+				t.Errorf("NewPattern(...) == _, %v, want _, %v", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestNearestNeighbor(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		points *geom.MultiPoint
+		study  *geom.Polygon
+		want   NearestNeighborIndex
+	}{
+		{
+			name:   "dispersed",
+			points: grid(10),
+			study:  square(-0.5, -0.5, 9.5, 9.5),
+			want: NearestNeighborIndex{
+				ObservedMeanDistance: 1,
+				ExpectedMeanDistance: 0.5,
+				Ratio:                2,
+				ZScore:               0.5 / 0.026136,
+			},
+		},
+		{
+			name:   "clustered",
+			points: geom.NewMultiPointFlat(geom.XYZ, []float64{0, 0, 1, 0, 0, 2, 3, 4, 5, 3, 4, 6}),
+			study:  square(0, 0, 4, 4),
+			want: NearestNeighborIndex{
+				ObservedMeanDistance: 0,
+				ExpectedMeanDistance: 1,
+				Ratio:                0,
+				ZScore:               -1 / 0.26136,
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := NewPattern(tc.points, tc.study)
+			if err != nil {
+				t.Fatalf("NewPattern(...) == _, %v, want _, <nil>", err)
+			}
+			got := p.NearestNeighbor()
+			if !almostEqual(got.ObservedMeanDistance, tc.want.ObservedMeanDistance) ||
+				!almostEqual(got.ExpectedMeanDistance, tc.want.ExpectedMeanDistance) ||
+				!almostEqual(got.Ratio, tc.want.Ratio) ||
+				!almostEqual(got.ZScore, tc.want.ZScore) {
+				t.Errorf("p.NearestNeighbor() == %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestK(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		points     *geom.MultiPoint
+		study      *geom.Polygon
+		radii      []float64
+		correction EdgeCorrection
+		want       []float64
+	}{
+		{
+			name:   "pair",
+			points: geom.NewMultiPointFlat(geom.XY, []float64{0.5, 1, 1.5, 1}),
+			study:  square(0, 0, 2, 2),
+			radii:  []float64{0.4, 1, 1.5},
+			want:   []float64{0, 4, 4},
+		},
+		{
+			name:       "pair_border",
+			points:     geom.NewMultiPointFlat(geom.XY, []float64{0.5, 1, 1.5, 1}),
+			study:      square(0, 0, 2, 2),
+			radii:      []float64{0.4, 0.5, 1},
+			correction: BorderEdgeCorrection,
+			want:       []float64{0, 0, math.NaN()},
+		},
+		{
+			// There are 180 pairs of points at distance 1 and 162 at
+			// distance √2.
+			name:   "grid",
+			points: grid(10),
+			study:  square(-0.5, -0.5, 9.5, 9.5),
+			radii:  []float64{0.5, 1, 1.5},
+			want:   []float64{0, 100.0 / 99 * 360 / 100, 100.0 / 99 * 684 / 100},
+		},
+		{
+			// Only the 8×8 interior points are at least 1 from the
+			// boundary, and each has four neighbors at distance 1.
+			name:       "grid_border",
+			points:     grid(10),
+			study:      square(-0.5, -0.5, 9.5, 9.5),
+			radii:      []float64{1},
+			correction: BorderEdgeCorrection,
+			want:       []float64{100.0 / 99 * 4},
+		},
+		{
+			// The points in the hole are not used as centers.
+			name:       "hole",
+			points:     geom.NewMultiPointFlat(geom.XY, []float64{1, 1, 1, 2, 5, 5, 5, 6}),
+			study:      geom.NewPolygonFlat(geom.XY, []float64{0, 0, 10, 0, 10, 10, 0, 10, 0, 0, 4, 4, 4, 8, 8, 8, 8, 4, 4, 4}, []int{10, 20}),
+			radii:      []float64{1},
+			correction: BorderEdgeCorrection,
+			want:       []float64{84.0 / 3},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := NewPattern(tc.points, tc.study)
+			if err != nil {
+				t.Fatalf("NewPattern(...) == _, %v, want _, <nil>", err)
+			}
+			got := p.K(tc.radii, tc.correction)
+			for i := range tc.want {
+				if !almostEqual(got[i], tc.want[i]) {
+					t.Errorf("p.K(%v, %d) == %v, want %v", tc.radii, tc.correction, got, tc.want)
+					break
+				}
+			}
+			gotL := p.L(tc.radii, tc.correction)
+			for i := range tc.want {
+				if !almostEqual(gotL[i], math.Sqrt(tc.want[i]/math.Pi)) {
+					t.Errorf("p.L(%v, %d)[%d] == %v, want %v", tc.radii, tc.correction, i, gotL[i], math.Sqrt(tc.want[i]/math.Pi))
+				}
+			}
+		})
+	}
+}